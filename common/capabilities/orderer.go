@@ -23,6 +23,19 @@ const (
 	// consensus-type migration commands. Migration is supported from Kafka to Raft only.
 	// If not present, these config updates will be rejected.
 	OrdererV2_0 = "V2_0"
+
+	// OrdererV2_1 is the capabilities string that defines new Fabric v2.1 orderer capabilities.
+	//
+	// In particular, it defines whether the orderer deprecates the system channel: channel creation
+	// transactions (of type ORDERER_TRANSACTION) are rejected, and channels are instead created by
+	// joining the orderers to them with their genesis block.
+	OrdererV2_1 = "V2_1"
+
+	// OrdererV2_2 is the capabilities string that defines new Fabric v2.2 orderer capabilities.
+	//
+	// In particular, it defines whether raft snapshots may reference an external archive of
+	// the blocks they are taken at, which orderers lacking it cannot read.
+	OrdererV2_2 = "V2_2"
 )
 
 // OrdererProvider provides capabilities information for orderer level config.
@@ -30,6 +43,8 @@ type OrdererProvider struct {
 	*registry
 	v11BugFixes   bool
 	kafka2RaftMig bool
+	noSysChannel  bool
+	snapArchives  bool
 }
 
 // NewOrdererProvider creates an orderer capabilities provider.
@@ -38,6 +53,8 @@ func NewOrdererProvider(capabilities map[string]*cb.Capability) *OrdererProvider
 	cp.registry = newRegistry(cp, capabilities)
 	_, cp.v11BugFixes = capabilities[OrdererV1_1]
 	_, cp.kafka2RaftMig = capabilities[OrdererV2_0]
+	_, cp.noSysChannel = capabilities[OrdererV2_1]
+	_, cp.snapArchives = capabilities[OrdererV2_2]
 	return cp
 }
 
@@ -54,6 +71,10 @@ func (cp *OrdererProvider) HasCapability(capability string) bool {
 		return true
 	case OrdererV2_0:
 		return true
+	case OrdererV2_1:
+		return true
+	case OrdererV2_2:
+		return true
 	default:
 		return false
 	}
@@ -81,3 +102,15 @@ func (cp *OrdererProvider) ExpirationCheck() bool {
 func (cp *OrdererProvider) Kafka2RaftMigration() bool {
 	return cp.kafka2RaftMig
 }
+
+// SystemChannelDeprecation checks whether the orderer rejects channel creation transactions,
+// and creates channels from genesis blocks it is joined with instead of from the system channel.
+func (cp *OrdererProvider) SystemChannelDeprecation() bool {
+	return cp.noSysChannel
+}
+
+// SnapshotArchives checks whether raft snapshots may reference an external archive
+// of the blocks they are taken at.
+func (cp *OrdererProvider) SnapshotArchives() bool {
+	return cp.snapArchives
+}
@@ -21,6 +21,8 @@ func TestOrdererV10(t *testing.T) {
 	assert.False(t, op.Resubmission())
 	assert.False(t, op.ExpirationCheck())
 	assert.False(t, op.Kafka2RaftMigration())
+	assert.False(t, op.SystemChannelDeprecation())
+	assert.False(t, op.SnapshotArchives())
 }
 
 func TestOrdererV11(t *testing.T) {
@@ -32,6 +34,8 @@ func TestOrdererV11(t *testing.T) {
 	assert.True(t, op.Resubmission())
 	assert.True(t, op.ExpirationCheck())
 	assert.False(t, op.Kafka2RaftMigration())
+	assert.False(t, op.SystemChannelDeprecation())
+	assert.False(t, op.SnapshotArchives())
 }
 
 func TestOrdererV20(t *testing.T) {
@@ -43,6 +47,27 @@ func TestOrdererV20(t *testing.T) {
 	assert.True(t, op.Resubmission())
 	assert.True(t, op.ExpirationCheck())
 	assert.True(t, op.Kafka2RaftMigration())
+	assert.False(t, op.SystemChannelDeprecation())
+	assert.False(t, op.SnapshotArchives())
+}
+
+func TestOrdererV21(t *testing.T) {
+	op := NewOrdererProvider(map[string]*cb.Capability{
+		OrdererV1_1: {}, OrdererV2_0: {}, OrdererV2_1: {},
+	})
+	assert.NoError(t, op.Supported())
+	assert.True(t, op.Kafka2RaftMigration())
+	assert.True(t, op.SystemChannelDeprecation())
+	assert.False(t, op.SnapshotArchives())
+}
+
+func TestOrdererV22(t *testing.T) {
+	op := NewOrdererProvider(map[string]*cb.Capability{
+		OrdererV1_1: {}, OrdererV2_0: {}, OrdererV2_1: {}, OrdererV2_2: {},
+	})
+	assert.NoError(t, op.Supported())
+	assert.True(t, op.SystemChannelDeprecation())
+	assert.True(t, op.SnapshotArchives())
 }
 
 func TestNotSuported(t *testing.T) {
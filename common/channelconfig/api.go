@@ -190,6 +190,14 @@ type OrdererCapabilities interface {
 
 	// Kafka2RaftMigration checks whether the orderer permits a Kafka to Raft migration.
 	Kafka2RaftMigration() bool
+
+	// SystemChannelDeprecation checks whether the orderer rejects channel creation transactions,
+	// and creates channels from genesis blocks it is joined with instead of from the system channel.
+	SystemChannelDeprecation() bool
+
+	// SnapshotArchives checks whether raft snapshots may reference an external archive
+	// of the blocks they are taken at.
+	SnapshotArchives() bool
 }
 
 // PolicyMapper is an interface for
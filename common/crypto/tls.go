@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package crypto
+
+import (
+	"crypto/tls"
+
+	"github.com/pkg/errors"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion returns the TLS version of the given name, 1.2 or 1.3.
+func ParseTLSVersion(name string) (uint16, error) {
+	version, exists := tlsVersions[name]
+	if !exists {
+		return 0, errors.Errorf("unsupported TLS version: %s, expected 1.2 or 1.3", name)
+	}
+	return version, nil
+}
+
+// TLSVersionName returns the name of the given TLS version, such as 1.2.
+func TLSVersionName(version uint16) string {
+	for name, v := range tlsVersions {
+		if v == version {
+			return name
+		}
+	}
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	}
+	return "unknown"
+}
+
+// ParseCipherSuites returns the TLS cipher suites of the given standard names,
+// such as TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384. Only the cipher suites
+// which are considered secure are known.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	var suites []uint16
+	for _, name := range names {
+		suite, exists := cipherSuiteByName(name)
+		if !exists {
+			return nil, errors.Errorf("unknown or insecure TLS cipher suite: %s", name)
+		}
+		suites = append(suites, suite)
+	}
+	return suites, nil
+}
+
+func cipherSuiteByName(name string) (uint16, bool) {
+	for _, suite := range tls.CipherSuites() {
+		if suite.Name == name {
+			return suite.ID, true
+		}
+	}
+	return 0, false
+}
@@ -189,7 +189,7 @@ func syncCPInfoFromFS(rootDir string, cpInfo *checkpointInfo) {
 		return
 	}
 	//Scan the file system to verify that the checkpoint info stored in db is correct
-	_, endOffsetLastBlock, numBlocks, err := scanForLastCompleteBlock(
+	lastBlockBytes, endOffsetLastBlock, numBlocks, err := scanForLastCompleteBlock(
 		rootDir, cpInfo.latestFileChunkSuffixNum, int64(cpInfo.latestFileChunksize))
 	if err != nil {
 		panic(fmt.Sprintf("Could not open current file for detecting last block in the file: %s", err))
@@ -200,7 +200,12 @@ func syncCPInfoFromFS(rootDir string, cpInfo *checkpointInfo) {
 	}
 	//Updates the checkpoint info for the actual last block number stored and it's end location
 	if cpInfo.isChainEmpty {
-		cpInfo.lastBlockNumber = uint64(numBlocks - 1)
+		// The first block is not the genesis block if the chain was bootstrapped from a block
+		lastBlock, err := deserializeBlock(lastBlockBytes)
+		if err != nil {
+			panic(fmt.Sprintf("Could not deserialize the last block in the file: %s", err))
+		}
+		cpInfo.lastBlockNumber = lastBlock.Header.Number
 	} else {
 		cpInfo.lastBlockNumber += uint64(numBlocks)
 	}
@@ -325,6 +330,25 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	return nil
 }
 
+// bootstrapFromBlock adds the given block to an empty chain as its first block,
+// so that the chain starts at the block instead of the genesis block. The blocks
+// preceding it are not part of the chain, and cannot be retrieved.
+func (mgr *blockfileMgr) bootstrapFromBlock(block *common.Block) error {
+	bcInfo := mgr.getBlockchainInfo()
+	if bcInfo.Height != 0 {
+		return errors.Errorf("cannot bootstrap from block %d, chain is at height %d", block.Header.Number, bcInfo.Height)
+	}
+	mgr.bcInfo.Store(&common.BlockchainInfo{
+		Height:           block.Header.Number,
+		CurrentBlockHash: block.Header.PreviousHash,
+	})
+	if err := mgr.addBlock(block); err != nil {
+		mgr.bcInfo.Store(bcInfo)
+		return err
+	}
+	return nil
+}
+
 func (mgr *blockfileMgr) syncIndex() error {
 	var lastBlockIndexed uint64
 	var indexEmpty bool
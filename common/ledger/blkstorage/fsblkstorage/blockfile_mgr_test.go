@@ -290,6 +290,47 @@ func TestBlockfileMgrRestart(t *testing.T) {
 	assert.Equal(t, expectedHeight, blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height)
 }
 
+func TestBlockfileMgrBootstrapFromBlock(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	assert.NoError(t, blkfileMgrWrapper.blockfileMgr.bootstrapFromBlock(blocks[5]))
+	blkfileMgrWrapper.addBlocks(blocks[6:])
+	assert.Equal(t, uint64(10), blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height)
+	blkfileMgrWrapper.testGetBlockByNumber(blocks[5:], 5)
+	_, err := blkfileMgrWrapper.blockfileMgr.retrieveBlockByNumber(4)
+	assert.Error(t, err)
+
+	err = blkfileMgrWrapper.blockfileMgr.bootstrapFromBlock(blocks[5])
+	assert.EqualError(t, err, "cannot bootstrap from block 5, chain is at height 10")
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	assert.Equal(t, uint64(10), blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height)
+	blkfileMgrWrapper.testGetBlockByHash(blocks[5:])
+}
+
+func TestBlockfileMgrBootstrapFromBlockCrashDuringWriting(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+	ledgerid := "testLedger"
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, ledgerid)
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	cpInfo := blkfileMgrWrapper.blockfileMgr.cpInfo
+	assert.NoError(t, blkfileMgrWrapper.blockfileMgr.bootstrapFromBlock(blocks[5]))
+	// simulate a crash before the checkpoint info of the block was saved
+	assert.NoError(t, blkfileMgrWrapper.blockfileMgr.saveCurrentInfo(cpInfo, true))
+	blkfileMgrWrapper.close()
+
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, ledgerid)
+	defer blkfileMgrWrapper.close()
+	assert.Equal(t, uint64(5), blkfileMgrWrapper.blockfileMgr.cpInfo.lastBlockNumber)
+	assert.Equal(t, uint64(6), blkfileMgrWrapper.blockfileMgr.getBlockchainInfo().Height)
+}
+
 func TestBlockfileMgrFileRolling(t *testing.T) {
 	blocks := testutil.ConstructTestBlocks(t, 200)
 	size := 0
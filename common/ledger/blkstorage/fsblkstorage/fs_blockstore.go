@@ -42,6 +42,12 @@ func (store *fsBlockStore) AddBlock(block *common.Block) error {
 	return store.fileMgr.addBlock(block)
 }
 
+// BootstrapFromBlock adds the given block as the first block of an empty
+// block store, which does not hold the blocks preceding it
+func (store *fsBlockStore) BootstrapFromBlock(block *common.Block) error {
+	return store.fileMgr.bootstrapFromBlock(block)
+}
+
 // GetBlockchainInfo returns the current info about blockchain
 func (store *fsBlockStore) GetBlockchainInfo() (*common.BlockchainInfo, error) {
 	return store.fileMgr.getBlockchainInfo(), nil
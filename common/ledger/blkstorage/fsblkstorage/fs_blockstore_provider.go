@@ -17,9 +17,12 @@ limitations under the License.
 package fsblkstorage
 
 import (
+	"os"
+
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/pkg/errors"
 )
 
 // FsBlockstoreProvider provides handle to block storage - this is not thread-safe
@@ -59,6 +62,26 @@ func (p *FsBlockstoreProvider) List() ([]string, error) {
 	return util.ListSubdirs(p.conf.getChainsDir())
 }
 
+// Remove removes the block files and the index entries of the given ledgerid.
+// The block store of the ledger must be shut down before it is removed.
+func (p *FsBlockstoreProvider) Remove(ledgerid string) error {
+	indexStoreHandle := p.leveldbProvider.GetDBHandle(ledgerid)
+	itr := indexStoreHandle.GetIterator(nil, nil)
+	batch := leveldbhelper.NewUpdateBatch()
+	for itr.Next() {
+		batch.Delete(itr.Key())
+	}
+	err := itr.Error()
+	itr.Release()
+	if err != nil {
+		return errors.Wrapf(err, "failed iterating over the index of ledger %s", ledgerid)
+	}
+	if err := indexStoreHandle.WriteBatch(batch, true); err != nil {
+		return errors.Wrapf(err, "failed removing the index of ledger %s", ledgerid)
+	}
+	return os.RemoveAll(p.conf.getLedgerBlockDir(ledgerid))
+}
+
 // Close closes the FsBlockstoreProvider
 func (p *FsBlockstoreProvider) Close() {
 	p.leveldbProvider.Close()
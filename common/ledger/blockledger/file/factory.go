@@ -22,6 +22,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	"github.com/pkg/errors"
 )
 
 type fileLedgerFactory struct {
@@ -60,6 +61,30 @@ func (flf *fileLedgerFactory) ChainIDs() []string {
 	return chainIDs
 }
 
+// blockStoreRemover is implemented by block storage providers which can remove block stores
+type blockStoreRemover interface {
+	Remove(ledgerid string) error
+}
+
+// Remove shuts down the block store of the ledger of the given chain, and removes it
+func (flf *fileLedgerFactory) Remove(chainID string) error {
+	remover, ok := flf.blkstorageProvider.(blockStoreRemover)
+	if !ok {
+		return errors.New("block storage provider does not support removing ledgers")
+	}
+
+	flf.mutex.Lock()
+	defer flf.mutex.Unlock()
+
+	if ledger, ok := flf.ledgers[chainID]; ok {
+		if store, ok := ledger.(*FileLedger).blockStore.(interface{ Shutdown() }); ok {
+			store.Shutdown()
+		}
+		delete(flf.ledgers, chainID)
+	}
+	return remover.Remove(chainID)
+}
+
 // Close releases all resources acquired by the factory
 func (flf *fileLedgerFactory) Close() {
 	flf.blkstorageProvider.Close()
@@ -19,6 +19,7 @@ package fileledger
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -90,3 +91,30 @@ func TestMultiReinitialization(t *testing.T) {
 	assert.Equal(t, 3, len(flf.ChainIDs()), "Expected chain to be recovered")
 	flf.Close()
 }
+
+func TestRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.NoError(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(dir)
+
+	flf := New(dir)
+	defer flf.Close()
+	ledger, err := flf.GetOrCreate("foo")
+	assert.NoError(t, err, "Error GetOrCreate chain")
+	assert.NoError(t, ledger.Append(genesisBlock))
+	_, err = flf.GetOrCreate("bar")
+	assert.NoError(t, err, "Error GetOrCreate chain")
+
+	assert.NoError(t, flf.(blockledger.Remover).Remove("foo"))
+	assert.Equal(t, []string{"bar"}, flf.ChainIDs())
+
+	ledger, err = flf.GetOrCreate("foo")
+	assert.NoError(t, err, "Error GetOrCreate chain")
+	assert.Equal(t, uint64(0), ledger.Height(), "Expected removed chain to start afresh")
+
+	flf = &fileLedgerFactory{
+		blkstorageProvider: &mockBlockStoreProvider{},
+		ledgers:            make(map[string]blockledger.ReadWriter),
+	}
+	assert.EqualError(t, flf.(blockledger.Remover).Remove("foo"), "block storage provider does not support removing ledgers")
+}
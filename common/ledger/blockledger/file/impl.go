@@ -12,6 +12,7 @@ import (
 	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/pkg/errors"
 )
 
 var logger = flogging.MustGetLogger("common.ledger.blockledger.file")
@@ -102,6 +103,22 @@ func (fl *FileLedger) Height() uint64 {
 	return info.Height
 }
 
+// BootstrapFromBlock appends the given block to an empty ledger as its
+// first block, if the block store supports starting at a block other
+// than the genesis block
+func (fl *FileLedger) BootstrapFromBlock(block *cb.Block) error {
+	bootstrapper, ok := fl.blockStore.(blockledger.Bootstrapper)
+	if !ok {
+		return errors.New("block store does not support bootstrapping from a block")
+	}
+	err := bootstrapper.BootstrapFromBlock(block)
+	if err == nil {
+		close(fl.signal)
+		fl.signal = make(chan struct{})
+	}
+	return err
+}
+
 // Append a new block to the ledger
 func (fl *FileLedger) Append(block *cb.Block) error {
 	err := fl.blockStore.AddBlock(block)
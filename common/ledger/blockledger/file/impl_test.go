@@ -185,6 +185,34 @@ func TestAddition(t *testing.T) {
 	assert.Equal(t, prevHash, block.Header.PreviousHash, "Block hashes did no match")
 }
 
+func TestBootstrapFromBlock(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.NoError(t, err, "Error creating temp dir: %s", err)
+	tev := &testEnv{location: name, t: t, flf: New(name)}
+	defer tev.tearDown()
+	fl, err := tev.flf.GetOrCreate(genesisconfig.TestChainID)
+	assert.NoError(t, err, "Error GetOrCreate chain")
+
+	block := cb.NewBlock(5, []byte("previous hash"))
+	assert.NoError(t, fl.(blockledger.Bootstrapper).BootstrapFromBlock(block))
+	assert.Equal(t, uint64(6), fl.Height(), "Block height should be 6")
+	assert.NoError(t, fl.Append(blockledger.CreateNextBlock(fl, []*cb.Envelope{{Payload: []byte("My Data")}})))
+	assert.Equal(t, uint64(7), fl.Height(), "Block height should be 7")
+
+	it, num := fl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 5}}})
+	defer it.Close()
+	assert.Equal(t, uint64(5), num)
+	retrieved, status := it.Next()
+	assert.Equal(t, cb.Status_SUCCESS, status, "Expected to successfully read the bootstrap block")
+	assert.Equal(t, uint64(5), retrieved.Header.Number)
+
+	err = fl.(blockledger.Bootstrapper).BootstrapFromBlock(block)
+	assert.EqualError(t, err, "cannot bootstrap from block 5, chain is at height 7")
+
+	err = (&FileLedger{blockStore: &mockBlockStore{}}).BootstrapFromBlock(block)
+	assert.EqualError(t, err, "block store does not support bootstrapping from a block")
+}
+
 func TestRetrieval(t *testing.T) {
 	tev, fl := initialize(t)
 	defer tev.tearDown()
@@ -23,6 +23,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -114,3 +115,21 @@ func TestClose(t *testing.T) {
 	jlf := New(name)
 	assert.NotPanics(t, func() { jlf.Close() }, "Noop should not pannic")
 }
+
+func TestRemove(t *testing.T) {
+	name, err := ioutil.TempDir("", "hyperledger_fabric")
+	assert.Nil(t, err, "Error creating temp dir: %s", err)
+	defer os.RemoveAll(name)
+
+	jlf := New(name)
+	_, err = jlf.GetOrCreate("foo")
+	assert.NoError(t, err)
+	_, err = jlf.GetOrCreate("bar")
+	assert.NoError(t, err)
+
+	assert.NoError(t, jlf.(blockledger.Remover).Remove("foo"))
+	assert.Equal(t, []string{"bar"}, jlf.ChainIDs())
+	_, err = os.Stat(path.Join(name, fmt.Sprintf(chainDirectoryFormatString, "foo")))
+	assert.True(t, os.IsNotExist(err), "Expected directory of removed chain to be deleted")
+	assert.Equal(t, []string{"bar"}, New(name).ChainIDs(), "Expected removed chain not to be recovered")
+}
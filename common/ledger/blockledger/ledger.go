@@ -24,6 +24,12 @@ type Factory interface {
 	Close()
 }
 
+// Remover is implemented by ledger factories which can remove the ledgers of chains
+type Remover interface {
+	// Remove removes the ledger of the given chain, along with all of its blocks
+	Remove(chainID string) error
+}
+
 // Iterator is useful for a chain Reader to stream blocks as they are created
 type Iterator interface {
 	// Next blocks until there is a new block available, or returns an error if
@@ -48,6 +54,13 @@ type Writer interface {
 	Append(block *cb.Block) error
 }
 
+// Bootstrapper is implemented by ledgers which can start at a block
+// other than the genesis block, without holding the blocks preceding it
+type Bootstrapper interface {
+	// BootstrapFromBlock appends the given block to an empty ledger
+	BootstrapFromBlock(block *cb.Block) error
+}
+
 //go:generate mockery -dir . -name ReadWriter -case underscore  -output mocks/
 
 // ReadWriter encapsulates the read/write functions of the ledger
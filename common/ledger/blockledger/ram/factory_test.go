@@ -19,6 +19,7 @@ package ramledger
 import (
 	"testing"
 
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 )
 
@@ -47,3 +48,15 @@ func TestChainIDs(t *testing.T) {
 	}
 	rlf.Close()
 }
+
+func TestRemove(t *testing.T) {
+	rlf := New(3)
+	rlf.GetOrCreate("channel1")
+	rlf.GetOrCreate("channel2")
+	if err := rlf.(blockledger.Remover).Remove("channel1"); err != nil {
+		t.Fatalf("Expecting channel to be removed: %s", err)
+	}
+	if chainIDs := rlf.ChainIDs(); len(chainIDs) != 1 || chainIDs[0] != "channel2" {
+		t.Fatalf("Expecting only channel2 to remain, got %v", chainIDs)
+	}
+}
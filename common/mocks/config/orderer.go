@@ -103,6 +103,10 @@ type OrdererCapabilities struct {
 	ExpirationVal bool
 
 	Kafka2RaftMigVal bool
+
+	SystemChannelDeprecationVal bool
+
+	SnapshotArchivesVal bool
 }
 
 // Supported returns SupportedErr
@@ -130,3 +134,13 @@ func (oc *OrdererCapabilities) ExpirationCheck() bool {
 func (oc *OrdererCapabilities) Kafka2RaftMigration() bool {
 	return oc.Kafka2RaftMigVal
 }
+
+// SystemChannelDeprecation returns SystemChannelDeprecationVal
+func (oc *OrdererCapabilities) SystemChannelDeprecation() bool {
+	return oc.SystemChannelDeprecationVal
+}
+
+// SnapshotArchives returns SnapshotArchivesVal
+func (oc *OrdererCapabilities) SnapshotArchives() bool {
+	return oc.SnapshotArchivesVal
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	pb "github.com/hyperledger/fabric/protos/peer"
@@ -221,6 +222,14 @@ func NewOrdererGroup(conf *genesisconfig.Orderer) (*cb.ConfigGroup, error) {
 		if consensusMetadata, err = etcdraft.Marshal(conf.EtcdRaft); err != nil {
 			return nil, errors.Errorf("cannot marshal metadata for orderer type %s: %s", etcdraft.TypeKey, err)
 		}
+		// The metadata is validated as marshaled, i.e. with the TLS certificates read from their files
+		md := &etcdraft.ConfigMetadata{}
+		if err = proto.Unmarshal(consensusMetadata, md); err != nil {
+			return nil, errors.Errorf("cannot unmarshal metadata for orderer type %s: %s", etcdraft.TypeKey, err)
+		}
+		if err = raftconfig.ValidateConfigMetadata(md, true); err != nil {
+			return nil, errors.Errorf("invalid metadata for orderer type %s: %s", etcdraft.TypeKey, err)
+		}
 	default:
 		return nil, errors.Errorf("unknown orderer type: %s", conf.OrdererType)
 	}
@@ -229,7 +229,11 @@ var _ = Describe("Encoder", func() {
 				conf.OrdererType = "etcdraft"
 				conf.EtcdRaft = &etcdraft.ConfigMetadata{
 					Options: &etcdraft.Options{
-						TickInterval: "500ms",
+						TickInterval:    "500ms",
+						ElectionTick:    10,
+						HeartbeatTick:   1,
+						MaxInflightMsgs: 5,
+						MaxSizePerMsg:   1024 * 1024,
 					},
 				}
 			})
@@ -262,6 +266,17 @@ var _ = Describe("Encoder", func() {
 					Expect(err).To(MatchError("cannot marshal metadata for orderer type etcdraft: cannot load client cert for consenter :0: open : no such file or directory"))
 				})
 			})
+
+			Context("when the raft options are invalid", func() {
+				BeforeEach(func() {
+					conf.EtcdRaft.Options.HeartbeatTick = 10
+				})
+
+				It("returns the validation error", func() {
+					_, err := encoder.NewOrdererGroup(conf)
+					Expect(err).To(MatchError("invalid metadata for orderer type etcdraft: invalid options: election tick must be greater than heartbeat tick"))
+				})
+			})
 		})
 
 		Context("when the consensus type is unknown", func() {
@@ -408,6 +408,10 @@ loop:
 				logger.Infof("Orderer.EtcdRaft.Options.TickInterval unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.TickInterval)
 				ord.EtcdRaft.Options.TickInterval = genesisDefaults.Orderer.EtcdRaft.Options.TickInterval
 
+			case ord.EtcdRaft.Options.ElectionTick == 0 && len(ord.EtcdRaft.Consenters) > etcdraft.LargeClusterSize:
+				logger.Infof("Orderer.EtcdRaft.Options.ElectionTick unset, setting to %v for a large cluster", etcdraft.LargeClusterElectionTick)
+				ord.EtcdRaft.Options.ElectionTick = etcdraft.LargeClusterElectionTick
+
 			case ord.EtcdRaft.Options.ElectionTick == 0:
 				logger.Infof("Orderer.EtcdRaft.Options.ElectionTick unset, setting to %v", genesisDefaults.Orderer.EtcdRaft.Options.ElectionTick)
 				ord.EtcdRaft.Options.ElectionTick = genesisDefaults.Orderer.EtcdRaft.Options.ElectionTick
@@ -444,8 +448,21 @@ loop:
 		if ord.EtcdRaft.Options.ElectionTick <= ord.EtcdRaft.Options.HeartbeatTick {
 			logger.Panicf("election tick must be greater than heartbeat tick")
 		}
+		if ord.EtcdRaft.Options.DisablePreVote && ord.EtcdRaft.Options.DisableCheckQuorum {
+			logger.Panicf("pre-vote and check quorum cannot be both disabled")
+		}
+
+		if consenters := len(ord.EtcdRaft.Consenters); consenters > etcdraft.MaxClusterSize {
+			logger.Panicf("%s configuration specifies %d consenters, which exceed the maximum cluster size of %d", etcdraft.TypeKey, consenters, etcdraft.MaxClusterSize)
+		} else if consenters > etcdraft.LargeClusterSize {
+			logger.Warningf("%s configuration specifies %d consenters, which form a large cluster where ordering takes longer, "+
+				"as the leader sends every block to each follower and waits for a majority of them to persist it", etcdraft.TypeKey, consenters)
+			if ord.EtcdRaft.Options.ElectionTick < etcdraft.LargeClusterElectionTick {
+				logger.Warningf("Election tick %d is lower than the %d recommended for large clusters", ord.EtcdRaft.Options.ElectionTick, etcdraft.LargeClusterElectionTick)
+			}
+		}
 
-		for _, c := range ord.EtcdRaft.GetConsenters() {
+		for _, c := range append(ord.EtcdRaft.GetConsenters(), ord.EtcdRaft.GetStandbyConsenters()...) {
 			if c.Host == "" {
 				logger.Panicf("consenter info in %s configuration did not specify host", etcdraft.TypeKey)
 			}
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package localconfig
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hyperledger/fabric/core/config/configtest"
@@ -268,6 +269,75 @@ func TestConsensusSpecificInit(t *testing.T) {
 					profile.completeInitialization(devConfigDir)
 				})
 			})
+
+			t.Run("panic on disabled PreVote and CheckQuorum", func(t *testing.T) {
+				options := &etcdraft.Options{
+					DisablePreVote:     true,
+					DisableCheckQuorum: true,
+				}
+				profile := makeProfile(consenters, options)
+
+				assert.Panics(t, func() {
+					profile.completeInitialization(devConfigDir)
+				})
+			})
+
+			t.Run("standby consenters", func(t *testing.T) {
+				profile := makeProfile(consenters, nil)
+				profile.Orderer.EtcdRaft.StandbyConsenters = []*etcdraft.Consenter{
+					{
+						Host:          "node-2.example.com",
+						Port:          7050,
+						ClientTlsCert: []byte("path/to/client/cert"),
+						ServerTlsCert: []byte("path/to/server/cert"),
+					},
+				}
+				profile.completeInitialization(devConfigDir)
+				assert.Equal(t, profile.Orderer.EtcdRaft.Consenters[0].ClientTlsCert, profile.Orderer.EtcdRaft.StandbyConsenters[0].ClientTlsCert,
+					"standby consenter cert paths should be translated like the ones of consenters")
+
+				profile = makeProfile(consenters, nil)
+				profile.Orderer.EtcdRaft.StandbyConsenters = []*etcdraft.Consenter{
+					{ // missing Host
+						Port:          7050,
+						ClientTlsCert: []byte("path/to/client/cert"),
+						ServerTlsCert: []byte("path/to/server/cert"),
+					},
+				}
+				assert.Panics(t, func() {
+					profile.completeInitialization(devConfigDir)
+				})
+			})
+		})
+
+		t.Run("large clusters", func(t *testing.T) {
+			makeConsenters := func(n int) []*etcdraft.Consenter {
+				var consenters []*etcdraft.Consenter
+				for i := 1; i <= n; i++ {
+					consenters = append(consenters, &etcdraft.Consenter{
+						Host:          fmt.Sprintf("node-%d.example.com", i),
+						Port:          7050,
+						ClientTlsCert: []byte("path/to/client/cert"),
+						ServerTlsCert: []byte("path/to/server/cert"),
+					})
+				}
+				return consenters
+			}
+
+			profile := makeProfile(makeConsenters(etcdraft.MaxClusterSize), &etcdraft.Options{})
+			profile.completeInitialization(devConfigDir)
+			assert.Equal(t, uint32(etcdraft.LargeClusterElectionTick), profile.Orderer.EtcdRaft.Options.ElectionTick,
+				"ElectionTick of a large cluster should be set to the default value for large clusters")
+
+			profile = makeProfile(makeConsenters(etcdraft.LargeClusterSize), &etcdraft.Options{})
+			profile.completeInitialization(devConfigDir)
+			assert.Equal(t, genesisDefaults.Orderer.EtcdRaft.Options.ElectionTick, profile.Orderer.EtcdRaft.Options.ElectionTick,
+				"ElectionTick should be set to the default value")
+
+			profile = makeProfile(makeConsenters(etcdraft.MaxClusterSize+1), &etcdraft.Options{})
+			assert.Panics(t, func() {
+				profile.completeInitialization(devConfigDir)
+			})
 		})
 	})
 }
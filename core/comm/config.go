@@ -100,6 +100,8 @@ type SecureOptions struct {
 	RequireClientCert bool
 	// CipherSuites is a list of supported cipher suites for TLS
 	CipherSuites []uint16
+	// MaxVersion is the maximum TLS version servers negotiate, TLS 1.2 if not set
+	MaxVersion uint16
 }
 
 // KeepaliveOptions is used to set the gRPC keepalive settings for both
@@ -37,9 +37,11 @@ func NewServerTransportCredentials(
 	// NOTE: unlike the default grpc/credentials implementation, we do not
 	// clone the tls.Config which allows us to update it dynamically
 	serverConfig.NextProtos = alpnProtoStr
-	// override TLS version and ensure it is 1.2
+	// override TLS version and ensure it is 1.2, unless a later one is allowed
 	serverConfig.MinVersion = tls.VersionTLS12
-	serverConfig.MaxVersion = tls.VersionTLS12
+	if serverConfig.MaxVersion < tls.VersionTLS12 {
+		serverConfig.MaxVersion = tls.VersionTLS12
+	}
 	return &serverCreds{
 		serverConfig: serverConfig,
 		logger:       logger}
@@ -190,6 +190,19 @@ func GetLocalMspConfig(dir string, bccspConfig *factory.FactoryOpts, ID string)
 	return getMspConfig(dir, ID, sigid)
 }
 
+// GetLocalVerifyingMspConfig returns a local MSP configuration for the MSP in
+// the specified directory, with the specified ID, without a signing identity.
+// It is meant for nodes whose signing key is held by an external signing
+// service, and hence neither the signer certificate nor the key are loaded.
+func GetLocalVerifyingMspConfig(dir string, bccspConfig *factory.FactoryOpts, ID string) (*msp.MSPConfig, error) {
+	err := factory.InitFactories(bccspConfig)
+	if err != nil {
+		return nil, errors.WithMessage(err, "could not initialize BCCSP Factories")
+	}
+
+	return getMspConfig(dir, ID, nil)
+}
+
 // GetVerifyingMspConfig returns an MSP config given directory, ID and type
 func GetVerifyingMspConfig(dir, ID, mspType string) (*msp.MSPConfig, error) {
 	switch mspType {
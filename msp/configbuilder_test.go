@@ -12,7 +12,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/protos/msp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +30,23 @@ func TestGetLocalMspConfigFails(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestGetLocalVerifyingMspConfig(t *testing.T) {
+	mspDir, err := configtest.GetDevMspDir()
+	assert.NoError(t, err)
+	conf, err := GetLocalVerifyingMspConfig(mspDir, nil, "SampleOrg")
+	assert.NoError(t, err)
+
+	mspConf := &msp.FabricMSPConfig{}
+	assert.NoError(t, proto.Unmarshal(conf.Config, mspConf))
+	assert.Nil(t, mspConf.SigningIdentity)
+
+	localMsp, err := New(&BCCSPNewOpts{NewBaseOpts{Version: MSPv1_0}})
+	assert.NoError(t, err)
+	assert.NoError(t, localMsp.Setup(conf))
+	_, err = localMsp.GetDefaultSigningIdentity()
+	assert.Error(t, err)
+}
+
 func TestGetPemMaterialFromDirWithFile(t *testing.T) {
 	tempFile, err := ioutil.TempFile("", "fabric-msp-test")
 	assert.NoError(t, err)
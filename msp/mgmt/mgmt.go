@@ -46,6 +46,21 @@ func LoadLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) er
 	return GetLocalMSP().Setup(conf)
 }
 
+// LoadVerifyingLocalMsp loads the local MSP from the specified directory without
+// a signing identity, for nodes whose signing key is held by an external signing service
+func LoadVerifyingLocalMsp(dir string, bccspConfig *factory.FactoryOpts, mspID string) error {
+	if mspID == "" {
+		return errors.New("the local MSP must have an ID")
+	}
+
+	conf, err := msp.GetLocalVerifyingMspConfig(dir, bccspConfig, mspID)
+	if err != nil {
+		return err
+	}
+
+	return GetLocalMSP().Setup(conf)
+}
+
 // FIXME: AS SOON AS THE CHAIN MANAGEMENT CODE IS COMPLETE,
 // THESE MAPS AND HELPSER FUNCTIONS SHOULD DISAPPEAR BECAUSE
 // OWNERSHIP OF PER-CHAIN MSP MANAGERS WILL BE HANDLED BY IT;
@@ -29,6 +29,16 @@ type Receiver interface {
 
 	// Cut returns the current batch and starts a new one
 	Cut() []*cb.Envelope
+
+	// PendingBatch describes the current batch, which is not yet cut
+	PendingBatch() PendingBatch
+}
+
+// PendingBatch describes the messages enqueued in a receiver and not yet cut into a batch.
+type PendingBatch struct {
+	Messages  int
+	SizeBytes uint32
+	StartTime time.Time // when the first of the messages was enqueued, zero if there are none
 }
 
 type receiver struct {
@@ -133,6 +143,18 @@ func (r *receiver) Cut() []*cb.Envelope {
 	return batch
 }
 
+// PendingBatch describes the current batch, which is not yet cut
+func (r *receiver) PendingBatch() PendingBatch {
+	if len(r.pendingBatch) == 0 {
+		return PendingBatch{}
+	}
+	return PendingBatch{
+		Messages:  len(r.pendingBatch),
+		SizeBytes: r.pendingBatchSizeBytes,
+		StartTime: r.PendingBatchStartTime,
+	}
+}
+
 func messageSizeBytes(message *cb.Envelope) uint32 {
 	return uint32(len(message.Payload) + len(message.Signature))
 }
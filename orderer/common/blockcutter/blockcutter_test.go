@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package blockcutter_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -173,6 +175,30 @@ var _ = Describe("Blockcutter", func() {
 			})
 		})
 
+		Context("when messages are pending", func() {
+			BeforeEach(func() {
+				fakeConfig.BatchSizeReturns(&ab.BatchSize{
+					MaxMessageCount:   3,
+					PreferredMaxBytes: 100,
+				})
+			})
+
+			It("describes the pending batch until it is cut", func() {
+				Expect(bc.PendingBatch()).To(Equal(blockcutter.PendingBatch{}))
+
+				start := time.Now()
+				bc.Ordered(message)
+				bc.Ordered(message)
+				pendingBatch := bc.PendingBatch()
+				Expect(pendingBatch.Messages).To(Equal(2))
+				Expect(pendingBatch.SizeBytes).To(Equal(uint32(80)))
+				Expect(pendingBatch.StartTime).To(BeTemporally("~", start, time.Second))
+
+				bc.Cut()
+				Expect(bc.PendingBatch()).To(Equal(blockcutter.PendingBatch{}))
+			})
+		})
+
 		Context("when the orderer config cannot be retrieved", func() {
 			BeforeEach(func() {
 				fakeConfigFetcher.OrdererConfigReturns(nil, false)
@@ -56,6 +56,13 @@ type Consenter interface {
 	WaitReady() error
 }
 
+// AdmissionController is optionally implemented by a ChannelSupport
+// which can tell whether the channel has the capacity to accept new messages.
+type AdmissionController interface {
+	// Admit returns an error if the channel is saturated.
+	Admit() error
+}
+
 // Handler is designed to handle connections from Broadcast AB gRPC service
 type Handler struct {
 	SupportRegistrar ChannelSupportRegistrar
@@ -168,6 +175,13 @@ func (bh *Handler) ProcessMessage(msg *cb.Envelope, addr string) (resp *ab.Broad
 		tracker.EndValidate()
 
 		tracker.BeginEnqueue()
+		if ac, ok := processor.(AdmissionController); ok {
+			if err = ac.Admit(); err != nil {
+				logger.Warningf("[channel: %s] Rejecting broadcast of normal message from %s with SERVICE_UNAVAILABLE: rejected by admission control: %s", chdr.ChannelId, addr, err)
+				return &ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()}
+			}
+		}
+
 		if err = processor.WaitReady(); err != nil {
 			logger.Warningf("[channel: %s] Rejecting broadcast of message from %s with SERVICE_UNAVAILABLE: rejected by Consenter: %s", chdr.ChannelId, addr, err)
 			return &ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: err.Error()}
@@ -197,6 +197,31 @@ var _ = Describe("Broadcast", func() {
 			})
 		})
 
+		Context("when the channel is saturated", func() {
+			BeforeEach(func() {
+				fakeSupportRegistrar.BroadcastChannelSupportReturns(&cb.ChannelHeader{
+					Type:      3,
+					ChannelId: "fake-channel",
+				}, false, &admissionControlledSupport{
+					ChannelSupport: fakeSupport,
+					err:            fmt.Errorf("saturated"),
+				}, nil)
+			})
+
+			It("returns the error to the client with a service unavailable status without enqueueing the message", func() {
+				err := handler.Handle(fakeABServer)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeSupport.WaitReadyCallCount()).To(Equal(0))
+				Expect(fakeSupport.OrderCallCount()).To(Equal(0))
+				Expect(fakeABServer.SendCallCount()).To(Equal(1))
+				Expect(proto.Equal(
+					fakeABServer.SendArgsForCall(0),
+					&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE, Info: "saturated"}),
+				).To(BeTrue())
+			})
+		})
+
 		Context("when the send to the client fails", func() {
 			BeforeEach(func() {
 				fakeABServer.SendReturns(fmt.Errorf("send-error"))
@@ -399,3 +424,12 @@ var _ = Describe("Broadcast", func() {
 		})
 	})
 })
+
+type admissionControlledSupport struct {
+	*mock.ChannelSupport
+	err error
+}
+
+func (acs *admissionControlledSupport) Admit() error {
+	return acs.err
+}
@@ -10,6 +10,8 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -54,6 +56,8 @@ type RemoteNode struct {
 	ServerTLSCert []byte
 	// ClientTLSCert is the DER encoded TLS client certificate of the node
 	ClientTLSCert []byte
+	// TLSPolicy restricts the TLS connections with the node for the channel
+	TLSPolicy TLSPolicy
 }
 
 // String returns a string representation of this RemoteNode
@@ -94,6 +98,23 @@ type Comm struct {
 	Connections    *ConnectionStore
 	Chan2Members   MembersByChannel
 	Metrics        *Metrics
+	// Revocation, if set, rejects remote nodes whose
+	// TLS certificates were revoked.
+	Revocation RevocationChecker
+	// ResolutionTTL is the minimum time between two re-resolutions of the
+	// endpoint of a remote node. Endpoints are not re-resolved if it is 0.
+	ResolutionTTL time.Duration
+	// LookupHost resolves a host name to its addresses.
+	// net.LookupHost is used if it is nil.
+	LookupHost func(host string) ([]string, error)
+
+	resolutionLock sync.Mutex
+	resolutions    map[string]resolution // last resolution, by endpoint
+}
+
+type resolution struct {
+	addresses []string // sorted, nil if the host was never resolved
+	time      time.Time
 }
 
 type requestContext struct {
@@ -146,6 +167,12 @@ func (c *Comm) requestContext(ctx context.Context, msg proto.Message) (*requestC
 	if stub == nil {
 		return nil, errors.Errorf("certificate extracted from TLS connection isn't authorized")
 	}
+	if err := c.checkRevocation(cert); err != nil {
+		return nil, errors.Errorf("client certificate of node %d isn't authorized: %s", stub.ID, err)
+	}
+	if err := stub.RemoteNode.TLSPolicy.checkContext(ctx); err != nil {
+		return nil, errors.Errorf("connection of node %d violates the TLS policy of channel %s: %s", stub.ID, channel, err)
+	}
 	return &requestContext{
 		channel: channel,
 		sender:  stub.ID,
@@ -290,6 +317,14 @@ func (c *Comm) updateStubInMapping(channel string, mapping MemberMapping, node R
 		stub.Deactivate()
 	}
 
+	// Likewise, if the TLS policy of the channel changed, so
+	// that the streams are created under the new policy
+	if !stub.RemoteNode.TLSPolicy.Equal(node.TLSPolicy) {
+		c.Logger.Info("Deactivating node", node.ID, "in channel", channel,
+			"with endpoint of", node.Endpoint, "due to TLS policy change to", node.TLSPolicy)
+		stub.Deactivate()
+	}
+
 	// Overwrite the stub Node data with the new data
 	stub.RemoteNode = node
 
@@ -312,6 +347,11 @@ func (c *Comm) createRemoteContext(stub *Stub, channel string) func() (*RemoteCo
 	return func() (*RemoteContext, error) {
 		c.Logger.Debug("Connecting to", stub.RemoteNode, "for channel", channel)
 
+		if err := c.checkRevocation(stub.ServerTLSCert); err != nil {
+			c.Logger.Warningf("Refusing to connect to %d(%s) (channel %s): %v", stub.ID, stub.Endpoint, channel, err)
+			return nil, errors.Errorf("server certificate of node %d isn't authorized: %s", stub.ID, err)
+		}
+
 		conn, err := c.Connections.Connection(stub.Endpoint, stub.ServerTLSCert)
 		if err != nil {
 			c.Logger.Warningf("Unable to obtain connection to %d(%s) (channel %s): %v", stub.ID, stub.Endpoint, channel, err)
@@ -343,11 +383,130 @@ func (c *Comm) createRemoteContext(stub *Stub, channel string) func() (*RemoteCo
 			ProbeConn:           probeConnection,
 			conn:                conn,
 			Client:              clusterClient,
+			tlsPolicy:           stub.RemoteNode.TLSPolicy,
 		}
 		return rc, nil
 	}
 }
 
+func (c *Comm) checkRevocation(cert []byte) error {
+	if c.Revocation == nil {
+		return nil
+	}
+	return c.Revocation.CheckRevocation(cert)
+}
+
+// DisconnectRevoked deactivates the stubs of the remote nodes whose server
+// TLS certificates were revoked, and closes the connections to them. Requests
+// of remote nodes whose client TLS certificates were revoked are rejected
+// as they arrive.
+func (c *Comm) DisconnectRevoked() {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	for channel, mapping := range c.Chan2Members {
+		for _, stub := range mapping {
+			err := c.checkRevocation(stub.ServerTLSCert)
+			if err == nil {
+				continue
+			}
+			c.Logger.Warningf("Disconnecting from %d(%s) (channel %s): %v", stub.ID, stub.Endpoint, channel, err)
+			stub.Deactivate()
+			c.Connections.Disconnect(stub.ServerTLSCert)
+		}
+	}
+}
+
+// Reresolve re-resolves the host of the endpoint of the given node in the
+// context of the given channel, unless it is an IP address or it was resolved
+// less than ResolutionTTL ago. If the addresses it resolves to changed since
+// the previous resolution, or were not known, the node is disconnected in all
+// channels, so that the next message sent to it dials its endpoint again.
+// Returns whether the node was disconnected.
+func (c *Comm) Reresolve(channel string, id uint64) (bool, error) {
+	if c.ResolutionTTL <= 0 {
+		return false, nil
+	}
+
+	c.Lock.RLock()
+	var node RemoteNode
+	stub := c.Chan2Members[channel].ByID(id)
+	if stub != nil {
+		node = stub.RemoteNode
+	}
+	c.Lock.RUnlock()
+
+	if stub == nil {
+		return false, errors.Errorf("node %d doesn't exist in channel %s's membership", id, channel)
+	}
+
+	host, _, err := net.SplitHostPort(node.Endpoint)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid endpoint of node %d", id)
+	}
+	if net.ParseIP(host) != nil {
+		return false, nil
+	}
+
+	c.resolutionLock.Lock()
+	previous := c.resolutions[node.Endpoint]
+	if !previous.time.IsZero() && time.Since(previous.time) < c.ResolutionTTL {
+		c.resolutionLock.Unlock()
+		return false, nil
+	}
+	if c.resolutions == nil {
+		c.resolutions = make(map[string]resolution)
+	}
+	// Claim the resolution, so that concurrent callers don't resolve the host too
+	c.resolutions[node.Endpoint] = resolution{addresses: previous.addresses, time: time.Now()}
+	c.resolutionLock.Unlock()
+
+	lookupHost := c.LookupHost
+	if lookupHost == nil {
+		lookupHost = net.LookupHost
+	}
+	addresses, err := lookupHost(host)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed resolving %s", host)
+	}
+	sort.Strings(addresses)
+
+	c.resolutionLock.Lock()
+	c.resolutions[node.Endpoint] = resolution{addresses: addresses, time: time.Now()}
+	c.resolutionLock.Unlock()
+
+	if previous.addresses != nil && equalStrings(previous.addresses, addresses) {
+		return false, nil
+	}
+
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	c.Logger.Infof("Endpoint %s of node %d (channel %s) resolves to %v, previously %v, reconnecting",
+		node.Endpoint, id, channel, addresses, previous.addresses)
+	for _, mapping := range c.Chan2Members {
+		for _, stub := range mapping {
+			if bytes.Equal(stub.ServerTLSCert, node.ServerTLSCert) {
+				stub.Deactivate()
+			}
+		}
+	}
+	c.Connections.Disconnect(node.ServerTLSCert)
+	return true, nil
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // getOrCreateMapping creates a MemberMapping for the given channel
 // or returns the existing one.
 func (c *Comm) getOrCreateMapping(channel string) MemberMapping {
@@ -426,6 +585,7 @@ type RemoteContext struct {
 	endpoint            string
 	Client              orderer.ClusterClient
 	ProbeConn           func(conn *grpc.ClientConn) error
+	tlsPolicy           TLSPolicy
 	conn                *grpc.ClientConn
 	nextStreamID        uint64
 	streamsByID         streamsMapperReporter
@@ -603,6 +763,15 @@ func requestAsString(request *orderer.StepRequest) string {
 		}
 		return fmt.Sprintf("SubmitRequest for channel %s with payload of size %d",
 			t.SubmitRequest.Channel, len(t.SubmitRequest.Payload.Payload))
+	case *orderer.StepRequest_SubmitBatch:
+		if t.SubmitBatch == nil {
+			return "Empty SubmitBatch"
+		}
+		var size int
+		for _, req := range t.SubmitBatch.Requests {
+			size += submitMsgLength(req)
+		}
+		return fmt.Sprintf("SubmitBatch of %d requests with payload of size %d", len(t.SubmitBatch.Requests), size)
 	case *orderer.StepRequest_ConsensusRequest:
 		return fmt.Sprintf("ConsensusRequest for channel %s with payload of size %d",
 			t.ConsensusRequest.Channel, len(t.ConsensusRequest.Payload))
@@ -625,6 +794,11 @@ func (rc *RemoteContext) NewStream(timeout time.Duration) (*Stream, error) {
 		return nil, errors.WithStack(err)
 	}
 
+	if err := rc.tlsPolicy.checkContext(stream.Context()); err != nil {
+		cancel()
+		return nil, errors.Errorf("connection to %s violates the TLS policy of channel %s: %s", rc.endpoint, rc.Channel, err)
+	}
+
 	streamID := atomic.AddUint64(&rc.nextStreamID, 1)
 	nodeName := commonNameFromContext(stream.Context())
 
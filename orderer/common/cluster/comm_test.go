@@ -9,6 +9,7 @@ package cluster_test
 import (
 	"context"
 	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
@@ -1092,6 +1093,10 @@ type testMetrics struct {
 	ingressStreamsCount metricsfakes.Gauge
 	msgSendTime         metricsfakes.Histogram
 	msgDropCount        metricsfakes.Counter
+	rpcMsgsSent         metricsfakes.Counter
+	rpcSendFailures     metricsfakes.Counter
+	rpcBytesSent        metricsfakes.Counter
+	rpcSendDuration     metricsfakes.Histogram
 }
 
 func (tm *testMetrics) initialize() {
@@ -1103,6 +1108,10 @@ func (tm *testMetrics) initialize() {
 	tm.ingressStreamsCount.WithReturns(&tm.ingressStreamsCount)
 	tm.msgSendTime.WithReturns(&tm.msgSendTime)
 	tm.msgDropCount.WithReturns(&tm.msgDropCount)
+	tm.rpcMsgsSent.WithReturns(&tm.rpcMsgsSent)
+	tm.rpcSendFailures.WithReturns(&tm.rpcSendFailures)
+	tm.rpcBytesSent.WithReturns(&tm.rpcBytesSent)
+	tm.rpcSendDuration.WithReturns(&tm.rpcSendDuration)
 
 	fakeProvider := tm.fakeProvider
 	fakeProvider.On("NewGauge", cluster.IngressStreamsCountOpts).Return(&tm.ingressStreamsCount)
@@ -1113,6 +1122,10 @@ func (tm *testMetrics) initialize() {
 	fakeProvider.On("NewGauge", cluster.EgressWorkersOpts).Return(&tm.egressWorkerSize)
 	fakeProvider.On("NewCounter", cluster.MessagesDroppedCountOpts).Return(&tm.msgDropCount)
 	fakeProvider.On("NewHistogram", cluster.MessageSendTimeOpts).Return(&tm.msgSendTime)
+	fakeProvider.On("NewCounter", cluster.RPCMessagesSentOpts).Return(&tm.rpcMsgsSent)
+	fakeProvider.On("NewCounter", cluster.RPCSendFailuresOpts).Return(&tm.rpcSendFailures)
+	fakeProvider.On("NewCounter", cluster.RPCBytesSentOpts).Return(&tm.rpcBytesSent)
+	fakeProvider.On("NewHistogram", cluster.RPCSendDurationOpts).Return(&tm.rpcSendDuration)
 }
 
 func TestMetrics(t *testing.T) {
@@ -1258,6 +1271,55 @@ func TestMetrics(t *testing.T) {
 	}
 }
 
+func TestTLSPolicy(t *testing.T) {
+	t.Parallel()
+	// Scenario: node 1 and node 2 communicate over TLS 1.2, until the channel
+	// requires TLS 1.3, and then allows TLS 1.2 with the default cipher suites.
+
+	node1 := newTestNode(t)
+	defer node1.stop()
+
+	node2 := newTestNode(t)
+	defer node2.stop()
+
+	configure := func(node *clusterNode, policy cluster.TLSPolicy) {
+		config := []cluster.RemoteNode{node1.nodeInfo, node2.nodeInfo}
+		for i := range config {
+			config[i].TLSPolicy = policy
+		}
+		node.c.Configure(testChannel, config)
+	}
+
+	configure(node1, cluster.TLSPolicy{})
+	configure(node2, cluster.TLSPolicy{})
+	assertBiDiCommunication(t, node1, node2, testReq)
+
+	// Node 2 rejects the requests of node 1 over TLS 1.2
+	configure(node2, cluster.TLSPolicy{MinVersion: tls.VersionTLS13})
+	stub, err := node1.c.Remote(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	stream := assertEventualEstablishStream(t, stub)
+	stream.Send(wrapSubmitReq(testSubReq))
+	_, err = stream.Recv()
+	assert.EqualError(t, err, fmt.Sprintf("rpc error: code = Unknown desc = connection of node %d violates the TLS policy of channel %s: "+
+		"TLS 1.2 connection does not satisfy the minimum version 1.3", node1.nodeInfo.ID, testChannel))
+
+	// Node 1 refuses to send to node 2 over TLS 1.2
+	configure(node1, cluster.TLSPolicy{MinVersion: tls.VersionTLS13})
+	stub, err = node1.c.Remote(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	gt := gomega.NewGomegaWithT(t)
+	gt.Eventually(func() string {
+		_, err := stub.NewStream(time.Hour)
+		return fmt.Sprint(err)
+	}, timeout).Should(gomega.ContainSubstring("violates the TLS policy of channel test: TLS 1.2 connection does not satisfy the minimum version 1.3"))
+
+	policy := cluster.TLSPolicy{MinVersion: tls.VersionTLS12, CipherSuites: comm_utils.DefaultTLSCipherSuites}
+	configure(node1, policy)
+	configure(node2, policy)
+	assertBiDiCommunication(t, node1, node2, testReq)
+}
+
 func assertBiDiCommunicationForChannel(t *testing.T, node1, node2 *clusterNode, msgToSend *orderer.SubmitRequest, channel string) {
 	for _, tst := range []struct {
 		label    string
@@ -1326,3 +1388,169 @@ func wrapSubmitReq(req *orderer.SubmitRequest) *orderer.StepRequest {
 		},
 	}
 }
+
+type revokedCerts struct {
+	lock  sync.Mutex
+	certs map[string]struct{}
+}
+
+func (rc *revokedCerts) revoke(cert []byte) {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if rc.certs == nil {
+		rc.certs = make(map[string]struct{})
+	}
+	rc.certs[string(cert)] = struct{}{}
+}
+
+func (rc *revokedCerts) CheckRevocation(cert []byte) error {
+	rc.lock.Lock()
+	defer rc.lock.Unlock()
+	if _, revoked := rc.certs[string(cert)]; revoked {
+		return errors.New("certificate is revoked")
+	}
+	return nil
+}
+
+func TestRevokedCertificates(t *testing.T) {
+	t.Parallel()
+	// Scenario: node 1 and node 2 communicate, until node 2 revokes
+	// the client certificate of node 1, and node 1 revokes the
+	// server certificate of node 2.
+
+	node1 := newTestNode(t)
+	defer node1.stop()
+
+	node2 := newTestNode(t)
+	defer node2.stop()
+
+	revoked1, revoked2 := &revokedCerts{}, &revokedCerts{}
+	node1.c.Revocation = revoked1
+	node2.c.Revocation = revoked2
+
+	config := []cluster.RemoteNode{node1.nodeInfo, node2.nodeInfo}
+	node1.c.Configure(testChannel, config)
+	node2.c.Configure(testChannel, config)
+
+	assertBiDiCommunication(t, node1, node2, testReq)
+
+	// Node 2 rejects the requests of node 1
+	revoked2.revoke(node1.nodeInfo.ClientTLSCert)
+	stub, err := node1.c.Remote(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	stream := assertEventualEstablishStream(t, stub)
+	stream.Send(wrapSubmitReq(testSubReq))
+	_, err = stream.Recv()
+	assert.EqualError(t, err, fmt.Sprintf("rpc error: code = Unknown desc = client certificate of node %d isn't authorized: certificate is revoked", node1.nodeInfo.ID))
+
+	// Node 1 disconnects from node 2 and refuses to connect again
+	revoked1.revoke(node2.nodeInfo.ServerTLSCert)
+	node1.c.DisconnectRevoked()
+	_, err = node1.c.Remote(testChannel, node2.nodeInfo.ID)
+	assert.EqualError(t, err, fmt.Sprintf("server certificate of node %d isn't authorized: certificate is revoked", node2.nodeInfo.ID))
+
+	// Node 2 still reaches node 1
+	var wg sync.WaitGroup
+	wg.Add(1)
+	node1.handler.On("OnSubmit", testChannel, node2.nodeInfo.ID, mock.Anything).Return(nil).Once().Run(func(_ mock.Arguments) {
+		wg.Done()
+	})
+	stub, err = node2.c.Remote(testChannel, node1.nodeInfo.ID)
+	assert.NoError(t, err)
+	stream = assertEventualEstablishStream(t, stub)
+	assert.NoError(t, stream.Send(wrapSubmitReq(testSubReq)))
+	wg.Wait()
+}
+
+func TestReresolve(t *testing.T) {
+	t.Parallel()
+	// Scenario: node 1 re-resolves the endpoint of node 2, and
+	// reconnects to it whenever the addresses it resolves to change.
+
+	node1 := newTestNode(t)
+	defer node1.stop()
+
+	node2 := newTestNode(t)
+	defer node2.stop()
+
+	var lookups []string
+	addresses := []string{"10.0.0.2", "10.0.0.1"}
+	var lookupErr error
+	node1.c.LookupHost = func(host string) ([]string, error) {
+		lookups = append(lookups, host)
+		return addresses, lookupErr
+	}
+
+	_, port, err := net.SplitHostPort(node2.nodeInfo.Endpoint)
+	assert.NoError(t, err)
+	node2Info := node2.nodeInfo
+	node2Info.Endpoint = net.JoinHostPort("orderer2.example.com", port)
+	node1.c.Configure(testChannel, []cluster.RemoteNode{node1.nodeInfo, node2Info})
+
+	remote := func() *cluster.RemoteContext {
+		rc, err := node1.c.Remote(testChannel, node2.nodeInfo.ID)
+		assert.NoError(t, err)
+		return rc
+	}
+	connected := remote()
+
+	// Endpoints are not re-resolved by default
+	reconnected, err := node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.False(t, reconnected)
+	assert.Empty(t, lookups)
+
+	// The first resolution reconnects, as the addresses
+	// which the connection was established to are not known
+	node1.c.ResolutionTTL = time.Nanosecond
+	reconnected, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.True(t, reconnected)
+	assert.Equal(t, []string{"orderer2.example.com"}, lookups)
+	assert.True(t, connected != remote())
+	connected = remote()
+
+	// The same addresses, in a different order, do not reconnect
+	addresses = []string{"10.0.0.1", "10.0.0.2"}
+	time.Sleep(time.Millisecond)
+	reconnected, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.False(t, reconnected)
+	assert.Len(t, lookups, 2)
+	assert.True(t, connected == remote())
+
+	// Neither does a failed resolution
+	lookupErr = errors.New("no such host")
+	time.Sleep(time.Millisecond)
+	_, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.EqualError(t, err, "failed resolving orderer2.example.com: no such host")
+	assert.True(t, connected == remote())
+	lookupErr = nil
+
+	// Changed addresses reconnect
+	addresses = []string{"10.0.0.3"}
+	time.Sleep(time.Millisecond)
+	reconnected, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.True(t, reconnected)
+	assert.True(t, connected != remote())
+
+	// The endpoint is not re-resolved again until the TTL expires
+	node1.c.ResolutionTTL = time.Hour
+	addresses = []string{"10.0.0.4"}
+	reconnected, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.False(t, reconnected)
+	assert.Len(t, lookups, 4)
+
+	// IP addresses are never re-resolved
+	node1.c.Configure(testChannel, []cluster.RemoteNode{node1.nodeInfo, node2.nodeInfo})
+	node1.c.ResolutionTTL = time.Nanosecond
+	reconnected, err = node1.c.Reresolve(testChannel, node2.nodeInfo.ID)
+	assert.NoError(t, err)
+	assert.False(t, reconnected)
+	assert.Len(t, lookups, 4)
+
+	_, err = node1.c.Reresolve(testChannel, 100)
+	assert.EqualError(t, err, "node 100 doesn't exist in channel test's membership")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ConnectionReleaser is implemented by Dialers whose connections are shared,
+// and must be released rather than closed once they are no longer used.
+type ConnectionReleaser interface {
+	// Release releases the connection, which is closed
+	// once it is no longer used by anyone.
+	Release(conn *grpc.ClientConn)
+}
+
+// ConnectionPool shares gRPC connections among the block pullers of different
+// channels, so that catching up many channels from the same ordering nodes does not
+// open a TLS session per channel. Connections are keyed by the endpoint and the
+// TLS identity they are made with, and are closed once the last user releases them.
+type ConnectionPool struct {
+	lock   sync.Mutex
+	byKey  map[string]*pooledConn
+	byConn map[*grpc.ClientConn]*pooledConn
+}
+
+type pooledConn struct {
+	key  string
+	refs int
+	// ready is closed once the connection is dialed,
+	// after which either conn or err is set
+	ready chan struct{}
+	conn  *grpc.ClientConn
+	err   error
+}
+
+// NewConnectionPool creates an empty ConnectionPool.
+func NewConnectionPool() *ConnectionPool {
+	return &ConnectionPool{
+		byKey:  make(map[string]*pooledConn),
+		byConn: make(map[*grpc.ClientConn]*pooledConn),
+	}
+}
+
+// Dialer returns a Dialer which dials through the given PredicateDialer,
+// and shares the connections it makes through the pool.
+func (cp *ConnectionPool) Dialer(dialer *PredicateDialer) *PooledDialer {
+	return &PooledDialer{Pool: cp, Dialer: dialer}
+}
+
+// Size returns the number of connections in the pool.
+func (cp *ConnectionPool) Size() int {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+	return len(cp.byKey)
+}
+
+func (cp *ConnectionPool) acquire(key string, dial func() (*grpc.ClientConn, error)) (*grpc.ClientConn, error) {
+	cp.lock.Lock()
+	pc, exists := cp.byKey[key]
+	if exists {
+		pc.refs++
+		cp.lock.Unlock()
+		<-pc.ready
+		return pc.conn, pc.err
+	}
+	pc = &pooledConn{key: key, refs: 1, ready: make(chan struct{})}
+	cp.byKey[key] = pc
+	cp.lock.Unlock()
+
+	// dial without holding the lock, so that connecting
+	// to an unresponsive endpoint does not hold up the rest
+	conn, err := dial()
+
+	cp.lock.Lock()
+	if err != nil {
+		delete(cp.byKey, key)
+	} else {
+		cp.byConn[conn] = pc
+	}
+	pc.conn, pc.err = conn, err
+	cp.lock.Unlock()
+	close(pc.ready)
+
+	return conn, err
+}
+
+// Release releases the connection, and closes it if it is no longer used.
+// Connections which do not belong to the pool are closed right away.
+func (cp *ConnectionPool) Release(conn *grpc.ClientConn) {
+	cp.lock.Lock()
+	defer cp.lock.Unlock()
+
+	pc, exists := cp.byConn[conn]
+	if !exists {
+		conn.Close()
+		return
+	}
+
+	pc.refs--
+	if pc.refs > 0 {
+		return
+	}
+
+	delete(cp.byKey, pc.key)
+	delete(cp.byConn, conn)
+	conn.Close()
+}
+
+// PooledDialer is a Dialer which shares its connections through a ConnectionPool.
+type PooledDialer struct {
+	Pool   *ConnectionPool
+	Dialer *PredicateDialer
+}
+
+// Dial returns a connection to the given address, which is shared with the
+// other users of the pool that reach the address with the same TLS identity.
+// The connection must be released once it is no longer used.
+func (pd *PooledDialer) Dial(address string) (*grpc.ClientConn, error) {
+	cfg, err := pd.Dialer.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	key := connectionKey(address, cfg.SecOpts.Certificate, cfg.SecOpts.ServerRootCAs)
+	return pd.Pool.acquire(key, func() (*grpc.ClientConn, error) {
+		return pd.Dialer.Dial(address, nil)
+	})
+}
+
+// Release releases the connection back to the pool.
+func (pd *PooledDialer) Release(conn *grpc.ClientConn) {
+	pd.Pool.Release(conn)
+}
+
+// connectionKey identifies connections to the address that are made with the
+// given client certificate, and trust the given server root CAs.
+func connectionKey(address string, clientCert []byte, serverRootCAs [][]byte) string {
+	rootCAs := make([][]byte, len(serverRootCAs))
+	copy(rootCAs, serverRootCAs)
+	sort.Slice(rootCAs, func(i, j int) bool {
+		return bytes.Compare(rootCAs[i], rootCAs[j]) < 0
+	})
+
+	h := sha256.New()
+	for _, field := range append([][]byte{[]byte(address), clientCert}, rootCAs...) {
+		length := make([]byte, 8)
+		binary.BigEndian.PutUint64(length, uint64(len(field)))
+		h.Write(length)
+		h.Write(field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
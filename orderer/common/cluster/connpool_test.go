@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/connectivity"
+)
+
+func pooledDialerConfig(cert []byte, rootCAs ...[]byte) comm.ClientConfig {
+	return comm.ClientConfig{
+		AsyncConnect: true,
+		Timeout:      time.Second,
+		SecOpts: &comm.SecureOptions{
+			Certificate:   cert,
+			ServerRootCAs: rootCAs,
+		},
+	}
+}
+
+func TestConnectionPoolSharesConnections(t *testing.T) {
+	pool := cluster.NewConnectionPool()
+	dialer1 := pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("cert"), []byte("ca1"), []byte("ca2"))))
+	// same identity, with the root CAs in a different order
+	dialer2 := pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("cert"), []byte("ca2"), []byte("ca1"))))
+
+	conn1, err := dialer1.Dial("localhost:1")
+	require.NoError(t, err)
+	conn2, err := dialer2.Dial("localhost:1")
+	require.NoError(t, err)
+	assert.True(t, conn1 == conn2, "connections should be shared")
+	assert.Equal(t, 1, pool.Size())
+
+	dialer1.Release(conn1)
+	assert.Equal(t, 1, pool.Size())
+	assert.NotEqual(t, connectivity.Shutdown, conn1.GetState())
+
+	dialer2.Release(conn2)
+	assert.Equal(t, 0, pool.Size())
+	assert.Equal(t, connectivity.Shutdown, conn1.GetState())
+
+	// once all users released the connection, a new one is dialed
+	conn3, err := dialer1.Dial("localhost:1")
+	require.NoError(t, err)
+	assert.False(t, conn1 == conn3, "a new connection should have been dialed")
+	dialer1.Release(conn3)
+}
+
+func TestConnectionPoolSeparatesIdentities(t *testing.T) {
+	pool := cluster.NewConnectionPool()
+	dialers := []*cluster.PooledDialer{
+		pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("cert"), []byte("ca1")))),
+		pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("other cert"), []byte("ca1")))),
+		pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("cert"), []byte("ca2")))),
+	}
+
+	for _, dialer := range dialers {
+		for _, address := range []string{"localhost:1", "localhost:2"} {
+			conn, err := dialer.Dial(address)
+			require.NoError(t, err)
+			defer dialer.Release(conn)
+		}
+	}
+	assert.Equal(t, 6, pool.Size())
+}
+
+func TestConnectionPoolConcurrentDial(t *testing.T) {
+	pool := cluster.NewConnectionPool()
+	dialer := pool.Dialer(cluster.NewTLSPinningDialer(pooledDialerConfig([]byte("cert"), []byte("ca"))))
+
+	var wg sync.WaitGroup
+	conns := make(chan interface{}, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, err := dialer.Dial("localhost:1")
+			assert.NoError(t, err)
+			conns <- conn
+		}()
+	}
+	wg.Wait()
+	close(conns)
+
+	assert.Equal(t, 1, pool.Size())
+	first := <-conns
+	for conn := range conns {
+		assert.True(t, first == conn, "connections should be shared")
+	}
+}
+
+func TestConnectionPoolFailedDial(t *testing.T) {
+	pool := cluster.NewConnectionPool()
+	dialer := pool.Dialer(cluster.NewTLSPinningDialer(comm.ClientConfig{
+		Timeout: time.Second,
+		SecOpts: &comm.SecureOptions{
+			UseTLS:        true,
+			ServerRootCAs: [][]byte{[]byte("not a certificate")},
+		},
+	}))
+
+	_, err := dialer.Dial("localhost:1")
+	assert.Error(t, err)
+	assert.Equal(t, 0, pool.Size())
+}
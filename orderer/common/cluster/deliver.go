@@ -73,7 +73,7 @@ func (p *BlockPuller) Close() {
 	p.cancelStream = nil
 
 	if p.conn != nil {
-		p.conn.Close()
+		p.releaseConn(p.conn)
 	}
 	p.conn = nil
 	p.endpoint = ""
@@ -105,7 +105,7 @@ func (p *BlockPuller) HeightsByEndpoints() (map[string]uint64, error) {
 	endpointsInfo := p.probeEndpoints(0)
 	res := make(map[string]uint64)
 	for endpoint, endpointInfo := range endpointsInfo.byEndpoints() {
-		endpointInfo.conn.Close()
+		p.releaseConn(endpointInfo.conn)
 		res[endpoint] = endpointInfo.lastBlockSeq + 1
 	}
 	p.Logger.Info("Returning the heights of OSNs mapped by endpoints", res)
@@ -255,7 +255,7 @@ func (p *BlockPuller) connectToSomeEndpoint(minRequestedSequence uint64) {
 		if endpoint == chosenEndpoint {
 			continue
 		}
-		endpointInfo.conn.Close()
+		p.releaseConn(endpointInfo.conn)
 	}
 
 	p.conn = endpointsInfo[chosenEndpoint].conn
@@ -297,8 +297,9 @@ func (p *BlockPuller) probeEndpoints(minRequestedSequence uint64) *endpointInfoB
 
 	close(endpointsInfo)
 	eib := &endpointInfoBucket{
-		bucket: endpointsInfo,
-		logger: p.Logger,
+		bucket:      endpointsInfo,
+		logger:      p.Logger,
+		releaseConn: p.releaseConn,
 	}
 
 	if unavailableErr == 1 && len(endpointsInfo) == 0 {
@@ -321,13 +322,23 @@ func (p *BlockPuller) probeEndpoint(endpoint string, minRequestedSequence uint64
 
 	lastBlockSeq, err := p.fetchLastBlockSeq(minRequestedSequence, endpoint, conn)
 	if err != nil {
-		conn.Close()
+		p.releaseConn(conn)
 		return nil, err
 	}
 
 	return &endpointInfo{conn: conn, lastBlockSeq: lastBlockSeq, endpoint: endpoint}, nil
 }
 
+// releaseConn closes the connection, or releases it
+// if the Dialer shares its connections with others.
+func (p *BlockPuller) releaseConn(conn *grpc.ClientConn) {
+	if releaser, isReleaser := p.Dialer.(ConnectionReleaser); isReleaser {
+		releaser.Release(conn)
+		return
+	}
+	conn.Close()
+}
+
 // randomEndpoint returns a random endpoint of the given endpointInfo
 func randomEndpoint(endpointsToHeight map[string]*endpointInfo) string {
 	var candidates []string
@@ -476,9 +487,10 @@ type endpointInfo struct {
 }
 
 type endpointInfoBucket struct {
-	bucket <-chan *endpointInfo
-	logger *flogging.FabricLogger
-	err    error
+	bucket      <-chan *endpointInfo
+	logger      *flogging.FabricLogger
+	releaseConn func(*grpc.ClientConn)
+	err         error
 }
 
 func (eib endpointInfoBucket) byEndpoints() map[string]*endpointInfo {
@@ -486,7 +498,7 @@ func (eib endpointInfoBucket) byEndpoints() map[string]*endpointInfo {
 	for endpointInfo := range eib.bucket {
 		if _, exists := infoByEndpoints[endpointInfo.endpoint]; exists {
 			eib.logger.Warningf("Duplicate endpoint found(%s), skipping it", endpointInfo.endpoint)
-			endpointInfo.conn.Close()
+			eib.releaseConn(endpointInfo.conn)
 			continue
 		}
 		infoByEndpoints[endpointInfo.endpoint] = endpointInfo
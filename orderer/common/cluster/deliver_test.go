@@ -317,6 +317,52 @@ func TestBlockPullerBasicHappyPath(t *testing.T) {
 	dialer.assertAllConnectionsClosed(t)
 }
 
+type releasingDialer struct {
+	*countingDialer
+	released uint32
+}
+
+func (d *releasingDialer) Release(conn *grpc.ClientConn) {
+	atomic.AddUint32(&d.released, 1)
+	conn.Close()
+}
+
+func TestBlockPullerReleasesSharedConnections(t *testing.T) {
+	// Scenario: The dialer of the block puller shares its connections,
+	// so the block puller releases them instead of closing them.
+	osn1 := newClusterNode(t)
+	defer osn1.stop()
+
+	osn2 := newClusterNode(t)
+	defer osn2.stop()
+
+	dialer := &releasingDialer{countingDialer: newCountingDialer()}
+	bp := newBlockPuller(dialer.countingDialer, osn1.srv.Address(), osn2.srv.Address())
+	bp.Dialer = dialer
+
+	// Both ordering nodes are probed, and the connection
+	// to the one not chosen is released right away
+	osn1.addExpectProbeAssert()
+	osn2.addExpectProbeAssert()
+	osn1.enqueueResponse(3)
+	osn2.enqueueResponse(3)
+	osn1.addExpectPullAssert(1)
+	osn2.addExpectPullAssert(1)
+	for i := 1; i <= 3; i++ {
+		osn1.enqueueResponse(uint64(i))
+		osn2.enqueueResponse(uint64(i))
+	}
+
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, uint64(i), bp.PullBlock(uint64(i)).Header.Number)
+	}
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&dialer.released))
+
+	bp.Close()
+	assert.Equal(t, uint32(2), atomic.LoadUint32(&dialer.released))
+	dialer.assertAllConnectionsClosed(t)
+}
+
 func TestBlockPullerDuplicate(t *testing.T) {
 	// Scenario: The address of the ordering node
 	// is found twice in the configuration, but this
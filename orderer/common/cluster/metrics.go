@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/common/metrics"
+	"google.golang.org/grpc/codes"
 )
 
 var (
@@ -82,6 +83,42 @@ var (
 		LabelNames:   []string{"host", "channel"},
 		StatsdFormat: "%{#fqname}.%{host}.%{channel}",
 	}
+
+	RPCMessagesSentOpts = metrics.CounterOpts{
+		Namespace:    "cluster",
+		Subsystem:    "comm",
+		Name:         "rpc_msgs_sent",
+		Help:         "Count of messages sent by the RPC layer to other nodes",
+		LabelNames:   []string{"channel", "target", "msg_type"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{target}.%{msg_type}",
+	}
+
+	RPCSendFailuresOpts = metrics.CounterOpts{
+		Namespace:    "cluster",
+		Subsystem:    "comm",
+		Name:         "rpc_send_failures",
+		Help:         "Count of messages the RPC layer failed to send to other nodes, by gRPC status code",
+		LabelNames:   []string{"channel", "target", "msg_type", "code"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{target}.%{msg_type}.%{code}",
+	}
+
+	RPCBytesSentOpts = metrics.CounterOpts{
+		Namespace:    "cluster",
+		Subsystem:    "comm",
+		Name:         "rpc_bytes_sent",
+		Help:         "Count of payload bytes sent by the RPC layer to other nodes",
+		LabelNames:   []string{"channel", "target", "msg_type"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{target}.%{msg_type}",
+	}
+
+	RPCSendDurationOpts = metrics.HistogramOpts{
+		Namespace:    "cluster",
+		Subsystem:    "comm",
+		Name:         "rpc_send_duration",
+		Help:         "Time it takes the RPC layer to hand a message to the stream of another node, in seconds",
+		LabelNames:   []string{"channel", "target", "msg_type"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{target}.%{msg_type}",
+	}
 )
 
 // Metrics defines the metrics for the cluster.
@@ -94,6 +131,10 @@ type Metrics struct {
 	EgressTLSConnectionCount metrics.Gauge
 	MessageSendTime          metrics.Histogram
 	MessagesDroppedCount     metrics.Counter
+	RPCMessagesSent          metrics.Counter
+	RPCSendFailures          metrics.Counter
+	RPCBytesSent             metrics.Counter
+	RPCSendDuration          metrics.Histogram
 }
 
 // A MetricsProvider is an abstraction for a metrics provider. It is a factory for
@@ -120,6 +161,10 @@ func NewMetrics(provider MetricsProvider) *Metrics {
 		IngressStreamsCount:      provider.NewGauge(IngressStreamsCountOpts),
 		MessagesDroppedCount:     provider.NewCounter(MessagesDroppedCountOpts),
 		MessageSendTime:          provider.NewHistogram(MessageSendTimeOpts),
+		RPCMessagesSent:          provider.NewCounter(RPCMessagesSentOpts),
+		RPCSendFailures:          provider.NewCounter(RPCSendFailuresOpts),
+		RPCBytesSent:             provider.NewCounter(RPCBytesSentOpts),
+		RPCSendDuration:          provider.NewHistogram(RPCSendDurationOpts),
 	}
 }
 
@@ -147,3 +192,13 @@ func (m *Metrics) reportEgressStreamCount(channel string, count uint32) {
 func (m *Metrics) reportStreamCount(count uint32) {
 	m.IngressStreamsCount.Set(float64(count))
 }
+
+func (m *Metrics) reportRPCSend(channel, target, msgType string, size int, duration time.Duration, code codes.Code) {
+	m.RPCSendDuration.With("channel", channel, "target", target, "msg_type", msgType).Observe(duration.Seconds())
+	if code != codes.OK {
+		m.RPCSendFailures.With("channel", channel, "target", target, "msg_type", msgType, "code", code.String()).Add(1)
+		return
+	}
+	m.RPCMessagesSent.With("channel", channel, "target", target, "msg_type", msgType).Add(1)
+	m.RPCBytesSent.With("channel", channel, "target", target, "msg_type", msgType).Add(float64(size))
+}
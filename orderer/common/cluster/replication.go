@@ -46,6 +46,7 @@ func PullerConfigFromTopLevelConfig(systemChannel string, conf *localconfig.TopL
 		TLSKey:              tlsKey,
 		TLSCert:             tlsCert,
 		Signer:              signer,
+		EndpointStrategy:    EndpointStrategy(conf.General.Cluster.ReplicationEndpoints),
 	}
 }
 
@@ -332,6 +333,7 @@ type PullerConfig struct {
 	Signer              crypto.LocalSigner
 	Channel             string
 	MaxTotalBufferBytes int
+	EndpointStrategy    EndpointStrategy
 }
 
 //go:generate mockery -dir . -name VerifierRetriever -case underscore -output mocks/
@@ -352,6 +354,10 @@ func BlockPullerFromConfigBlock(conf PullerConfig, block *common.Block, verifier
 	if err != nil {
 		return nil, err
 	}
+	endpoints, err := endpointconfig.SelectEndpoints(conf.EndpointStrategy)
+	if err != nil {
+		return nil, err
+	}
 
 	dialer := &StandardDialer{
 		Dialer: NewTLSPinningDialer(comm.ClientConfig{
@@ -382,7 +388,7 @@ func BlockPullerFromConfigBlock(conf PullerConfig, block *common.Block, verifier
 			return VerifyBlocks(blocks, verifier)
 		},
 		MaxTotalBufferBytes: conf.MaxTotalBufferBytes,
-		Endpoints:           endpointconfig.Endpoints,
+		Endpoints:           endpoints,
 		RetryTimeout:        RetryTimeout,
 		FetchTimeout:        conf.Timeout,
 		Channel:             conf.Channel,
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+)
+
+// RevocationChecker checks whether the TLS certificates of remote nodes were revoked.
+type RevocationChecker interface {
+	// CheckRevocation returns an error if the given DER encoded certificate was revoked.
+	CheckRevocation(cert []byte) error
+}
+
+// CRLChecker checks certificates against certificate revocation lists, read from
+// PEM or DER encoded files. The files are trusted like the rest of the local
+// configuration, hence the signatures of the lists are not verified.
+type CRLChecker struct {
+	Logger *flogging.FabricLogger
+	Paths  []string
+	// OnUpdate, if set, is called after the lists are reloaded
+	// and revoke certificates which were not revoked before.
+	OnUpdate func()
+
+	lock    sync.RWMutex
+	revoked map[string]struct{} // by issuer and serial number
+}
+
+// CheckRevocation returns an error if the given DER encoded
+// certificate is revoked by any of the lists.
+func (cc *CRLChecker) CheckRevocation(cert []byte) error {
+	c, err := x509.ParseCertificate(cert)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse certificate")
+	}
+
+	cc.lock.RLock()
+	_, revoked := cc.revoked[revocationKey(c.Issuer.String(), c.SerialNumber.String())]
+	cc.lock.RUnlock()
+
+	if revoked {
+		return errors.Errorf("certificate %s issued by %s is revoked", c.SerialNumber, c.Issuer)
+	}
+	return nil
+}
+
+// Load reads the lists, and replaces the revoked certificates only
+// if all lists are read, so that a list which cannot be read does
+// not reinstate the certificates it revokes.
+func (cc *CRLChecker) Load() error {
+	revoked := make(map[string]struct{})
+	for _, path := range cc.Paths {
+		if err := cc.load(path, revoked); err != nil {
+			return errors.Wrapf(err, "failed to load certificate revocation list %s", path)
+		}
+	}
+
+	cc.lock.Lock()
+	added := 0
+	for key := range revoked {
+		if _, exists := cc.revoked[key]; !exists {
+			added++
+		}
+	}
+	cc.revoked = revoked
+	cc.lock.Unlock()
+
+	cc.Logger.Debugf("Loaded %d revoked certificates from %d revocation lists", len(revoked), len(cc.Paths))
+	if added > 0 && cc.OnUpdate != nil {
+		cc.Logger.Infof("Revocation lists revoke %d more certificates", added)
+		cc.OnUpdate()
+	}
+	return nil
+}
+
+func (cc *CRLChecker) load(path string, revoked map[string]struct{}) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	crl, err := x509.ParseCRL(raw)
+	if err != nil {
+		return err
+	}
+	if crl.HasExpired(time.Now()) {
+		cc.Logger.Warnf("Certificate revocation list %s expired at %s", path, crl.TBSCertList.NextUpdate)
+	}
+
+	var issuer pkix.Name
+	issuer.FillFromRDNSequence(&crl.TBSCertList.Issuer)
+	for _, rc := range crl.TBSCertList.RevokedCertificates {
+		revoked[revocationKey(issuer.String(), rc.SerialNumber.String())] = struct{}{}
+	}
+	return nil
+}
+
+// Run reloads the lists every interval, until stop is closed.
+func (cc *CRLChecker) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := cc.Load(); err != nil {
+				cc.Logger.Errorf("Failed to reload certificate revocation lists, keeping the previous ones: %s", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func revocationKey(issuer, serial string) string {
+	return issuer + "/" + serial
+}
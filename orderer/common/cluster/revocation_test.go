@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRLChecker(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	revokedPair, err := ca.NewServerCertKeyPair("127.0.0.1")
+	require.NoError(t, err)
+	validPair, err := ca.NewServerCertKeyPair("127.0.0.1")
+	require.NoError(t, err)
+
+	der := func(pemBytes []byte) []byte {
+		block, _ := pem.Decode(pemBytes)
+		require.NotNil(t, block)
+		return block.Bytes
+	}
+	caCert, err := x509.ParseCertificate(der(ca.CertBytes()))
+	require.NoError(t, err)
+	revokedCert, err := x509.ParseCertificate(der(revokedPair.Cert))
+	require.NoError(t, err)
+
+	// The signatures of the lists are not verified, so any key signs them.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	now := time.Now()
+	crl, err := caCert.CreateCRL(rand.Reader, key, []pkix.RevokedCertificate{
+		{SerialNumber: revokedCert.SerialNumber, RevocationTime: now},
+	}, now, now.Add(time.Hour))
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "crl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	crlPath := filepath.Join(dir, "crl.pem")
+	emptyPath := filepath.Join(dir, "empty.pem")
+	require.NoError(t, ioutil.WriteFile(crlPath, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}), 0600))
+
+	emptyCRL, err := caCert.CreateCRL(rand.Reader, key, nil, now, now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(emptyPath, emptyCRL, 0600))
+
+	var updates uint32
+	checker := &cluster.CRLChecker{
+		Logger: flogging.MustGetLogger("test"),
+		Paths:  []string{crlPath, emptyPath},
+		OnUpdate: func() {
+			atomic.AddUint32(&updates, 1)
+		},
+	}
+
+	// Nothing is revoked before the lists are loaded
+	assert.NoError(t, checker.CheckRevocation(der(revokedPair.Cert)))
+
+	require.NoError(t, checker.Load())
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&updates))
+	err = checker.CheckRevocation(der(revokedPair.Cert))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is revoked")
+	assert.NoError(t, checker.CheckRevocation(der(validPair.Cert)))
+
+	// Reloading the same lists revokes nothing new
+	require.NoError(t, checker.Load())
+	assert.Equal(t, uint32(1), atomic.LoadUint32(&updates))
+
+	// A list which cannot be read keeps the previous revocations
+	require.NoError(t, os.Remove(crlPath))
+	err = checker.Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load certificate revocation list "+crlPath)
+	assert.Error(t, checker.CheckRevocation(der(revokedPair.Cert)))
+
+	// Garbage isn't a certificate
+	err = checker.CheckRevocation([]byte{1, 2, 3})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse certificate")
+}
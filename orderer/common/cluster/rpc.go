@@ -8,6 +8,7 @@ package cluster
 
 import (
 	"context"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/pkg/errors"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 //go:generate mockery -dir . -name StepClient -case underscore -output ./mocks/
@@ -42,6 +45,7 @@ type RPC struct {
 	Timeout       time.Duration
 	Channel       string
 	Comm          Communicator
+	Metrics       *Metrics // reports the messages sent to every destination, if set
 	lock          sync.RWMutex
 	StreamsByType map[OperationType]map[uint64]*Stream
 }
@@ -64,11 +68,19 @@ const (
 	SubmitOperation
 )
 
+// Types of the messages sent by the RPC, as reported by its metrics.
+const (
+	ConsensusMsgType   = "consensus"
+	SubmitMsgType      = "submit"
+	SubmitBatchMsgType = "submit_batch"
+)
+
 // Consensus passes the given ConsensusRequest message to the raft.Node instance.
-func (s *RPC) SendConsensus(destination uint64, msg *orderer.ConsensusRequest) error {
+func (s *RPC) SendConsensus(destination uint64, msg *orderer.ConsensusRequest) (err error) {
 	if s.Logger.IsEnabledFor(zapcore.DebugLevel) {
 		defer s.consensusSent(time.Now(), destination, msg)
 	}
+	defer s.reportSent(time.Now(), destination, ConsensusMsgType, len(msg.Payload), &err)
 
 	stream, err := s.getOrCreateStream(destination, ConsensusOperation)
 	if err != nil {
@@ -93,10 +105,11 @@ func (s *RPC) SendConsensus(destination uint64, msg *orderer.ConsensusRequest) e
 }
 
 // SendSubmit sends a SubmitRequest to the given destination node.
-func (s *RPC) SendSubmit(destination uint64, request *orderer.SubmitRequest) error {
+func (s *RPC) SendSubmit(destination uint64, request *orderer.SubmitRequest) (err error) {
 	if s.Logger.IsEnabledFor(zapcore.DebugLevel) {
 		defer s.submitSent(time.Now(), destination, request)
 	}
+	defer s.reportSent(time.Now(), destination, SubmitMsgType, submitMsgLength(request), &err)
 
 	stream, err := s.getOrCreateStream(destination, SubmitOperation)
 	if err != nil {
@@ -119,6 +132,40 @@ func (s *RPC) SendSubmit(destination uint64, request *orderer.SubmitRequest) err
 	return err
 }
 
+// SendSubmitBatch sends a SubmitBatch to the given destination node, over the same
+// stream as SendSubmit, hence the requests are received in order with those sent by it.
+// The destination must support SubmitBatch messages.
+func (s *RPC) SendSubmitBatch(destination uint64, batch *orderer.SubmitBatch) (err error) {
+	if s.Logger.IsEnabledFor(zapcore.DebugLevel) {
+		defer s.submitBatchSent(time.Now(), destination, batch)
+	}
+	defer s.reportSent(time.Now(), destination, SubmitBatchMsgType, submitBatchLength(batch), &err)
+
+	stream, err := s.getOrCreateStream(destination, SubmitOperation)
+	if err != nil {
+		return err
+	}
+
+	req := &orderer.StepRequest{
+		Payload: &orderer.StepRequest_SubmitBatch{
+			SubmitBatch: batch,
+		},
+	}
+
+	s.submitLock.Lock()
+	defer s.submitLock.Unlock()
+
+	err = stream.Send(req)
+	if err != nil {
+		s.unMapStream(destination, SubmitOperation)
+	}
+	return err
+}
+
+func (s *RPC) submitBatchSent(start time.Time, to uint64, batch *orderer.SubmitBatch) {
+	s.Logger.Debugf("Sending batch of %d msgs of %d bytes to %d on channel %s took %v", len(batch.Requests), submitBatchLength(batch), to, s.Channel, time.Since(start))
+}
+
 func (s *RPC) submitSent(start time.Time, to uint64, msg *orderer.SubmitRequest) {
 	s.Logger.Debugf("Sending msg of %d bytes to %d on channel %s took %v", submitMsgLength(msg), to, s.Channel, time.Since(start))
 }
@@ -127,6 +174,32 @@ func (s *RPC) consensusSent(start time.Time, to uint64, msg *orderer.ConsensusRe
 	s.Logger.Debugf("Sending msg of %d bytes to %d on channel %s took %v", len(msg.Payload), to, s.Channel, time.Since(start))
 }
 
+// reportSent reports a message of the given type and size sent to the given
+// destination, or the code of the error it failed to be sent with.
+func (s *RPC) reportSent(start time.Time, to uint64, msgType string, size int, err *error) {
+	if s.Metrics == nil {
+		return
+	}
+	s.Metrics.reportRPCSend(s.Channel, strconv.FormatUint(to, 10), msgType, size, time.Since(start), sendErrorCode(*err))
+}
+
+// sendErrorCode returns the gRPC status code of the error a message failed
+// to be sent with. Messages fail to be sent without a gRPC status when
+// the stream to the destination cannot be created or is aborted, in
+// which case the destination is deemed unavailable.
+func sendErrorCode(err error) codes.Code {
+	if err == nil {
+		return codes.OK
+	}
+	if errors.Cause(err) == errOverflow {
+		return codes.ResourceExhausted
+	}
+	if st, isStatus := status.FromError(errors.Cause(err)); isStatus {
+		return st.Code()
+	}
+	return codes.Unavailable
+}
+
 // getProposeStream obtains a Submit stream for the given destination node
 func (s *RPC) getOrCreateStream(destination uint64, operationType OperationType) (orderer.Cluster_StepClient, error) {
 	stream := s.getStream(destination, operationType)
@@ -180,3 +253,11 @@ func submitMsgLength(request *orderer.SubmitRequest) int {
 	}
 	return len(request.Payload.Payload)
 }
+
+func submitBatchLength(batch *orderer.SubmitBatch) int {
+	var size int
+	for _, req := range batch.Requests {
+		size += submitMsgLength(req)
+	}
+	return size
+}
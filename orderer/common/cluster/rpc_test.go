@@ -15,6 +15,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/common/metrics/disabled"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/common/cluster/mocks"
 	"github.com/hyperledger/fabric/protos/common"
@@ -105,6 +106,13 @@ func TestSend(t *testing.T) {
 
 	submitReq := wrapSubmitReq(submitRequest)
 
+	submitBatch := &orderer.SubmitBatch{Requests: []*orderer.SubmitRequest{submitRequest, submitRequest}}
+	submitBatchReq := &orderer.StepRequest{
+		Payload: &orderer.StepRequest_SubmitBatch{
+			SubmitBatch: submitBatch,
+		},
+	}
+
 	consensusReq := &orderer.StepRequest{
 		Payload: &orderer.StepRequest_ConsensusRequest{
 			ConsensusRequest: consensusRequest,
@@ -135,6 +143,10 @@ func TestSend(t *testing.T) {
 		return err
 	}
 
+	submitBatchFunc := func(rpc *cluster.RPC) error {
+		return rpc.SendSubmitBatch(1, submitBatch)
+	}
+
 	step := func(rpc *cluster.RPC) error {
 		return rpc.SendConsensus(1, consensusRequest)
 	}
@@ -157,6 +169,21 @@ func TestSend(t *testing.T) {
 			receiveReturns: []interface{}{submitResponse, nil},
 			sendCalledWith: submitReq,
 		},
+		{
+			name:           "Send submit batch succeed",
+			method:         submitBatchFunc,
+			sendReturns:    nil,
+			stepReturns:    []interface{}{stream, nil},
+			sendCalledWith: submitBatchReq,
+		},
+		{
+			name:           "Send submit batch fails",
+			method:         submitBatchFunc,
+			sendReturns:    errors.New("oops"),
+			stepReturns:    []interface{}{stream, nil},
+			sendCalledWith: submitBatchReq,
+			expectedErr:    "stream is aborted",
+		},
 		{
 			name:           "Send step succeed",
 			method:         step,
@@ -327,3 +354,67 @@ func TestRPCGarbageCollection(t *testing.T) {
 	assert.Len(t, mapping[cluster.SubmitOperation], 1)
 	assert.Equal(t, uint64(2), mapping[cluster.SubmitOperation][2].ID)
 }
+
+func TestRPCMetrics(t *testing.T) {
+	// Scenario: Send a message to a remote node, and fail to send
+	// a message to another remote node which is not reachable.
+	// The messages are reported by destination and message type.
+
+	t.Parallel()
+
+	comm := &mocks.Communicator{}
+	client := &mocks.ClusterClient{}
+	stream := &mocks.StepClient{}
+
+	comm.On("Remote", "mychannel", uint64(1)).Return(&cluster.RemoteContext{
+		SendBuffSize: 10,
+		Metrics:      cluster.NewMetrics(&disabled.Provider{}),
+		Logger:       flogging.MustGetLogger("test"),
+		Client:       client,
+		ProbeConn:    func(_ *grpc.ClientConn) error { return nil },
+	}, nil)
+	comm.On("Remote", "mychannel", uint64(2)).Return(nil, errors.New("node 2 doesn't exist in channel mychannel's membership"))
+	client.On("Step", mock.Anything).Return(stream, nil)
+	stream.On("Context", mock.Anything).Return(context.Background())
+	stream.On("Send", mock.Anything).Return(nil)
+	stream.On("Recv").Return(nil, io.EOF)
+
+	msgsSent := &metricsfakes.Counter{}
+	msgsSent.WithReturns(msgsSent)
+	sendFailures := &metricsfakes.Counter{}
+	sendFailures.WithReturns(sendFailures)
+	bytesSent := &metricsfakes.Counter{}
+	bytesSent.WithReturns(bytesSent)
+	sendDuration := &metricsfakes.Histogram{}
+	sendDuration.WithReturns(sendDuration)
+
+	rpc := &cluster.RPC{
+		Logger:        flogging.MustGetLogger("test"),
+		Timeout:       time.Hour,
+		StreamsByType: cluster.NewStreamsByType(),
+		Channel:       "mychannel",
+		Comm:          comm,
+		Metrics: &cluster.Metrics{
+			RPCMessagesSent: msgsSent,
+			RPCSendFailures: sendFailures,
+			RPCBytesSent:    bytesSent,
+			RPCSendDuration: sendDuration,
+		},
+	}
+
+	err := rpc.SendConsensus(1, &orderer.ConsensusRequest{Channel: "mychannel", Payload: []byte{1, 2, 3}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, msgsSent.AddCallCount())
+	assert.Equal(t, []string{"channel", "mychannel", "target", "1", "msg_type", cluster.ConsensusMsgType}, msgsSent.WithArgsForCall(0))
+	assert.Equal(t, float64(3), bytesSent.AddArgsForCall(0))
+	assert.Equal(t, []string{"channel", "mychannel", "target", "1", "msg_type", cluster.ConsensusMsgType}, bytesSent.WithArgsForCall(0))
+
+	err = rpc.SendSubmit(2, &orderer.SubmitRequest{Channel: "mychannel"})
+	assert.EqualError(t, err, "node 2 doesn't exist in channel mychannel's membership")
+	assert.Equal(t, 1, msgsSent.AddCallCount())
+	assert.Equal(t, 1, sendFailures.AddCallCount())
+	assert.Equal(t, []string{"channel", "mychannel", "target", "2", "msg_type", cluster.SubmitMsgType, "code", "Unavailable"}, sendFailures.WithArgsForCall(0))
+
+	assert.Equal(t, 2, sendDuration.ObserveCallCount())
+	assert.Equal(t, []string{"channel", "mychannel", "target", "2", "msg_type", cluster.SubmitMsgType}, sendDuration.WithArgsForCall(1))
+}
@@ -86,6 +86,15 @@ func (s *Service) handleMessage(stream StepStream, addr string) error {
 		return s.handleSubmit(submitReq, stream, addr)
 	}
 
+	if batch := request.GetSubmitBatch(); batch != nil {
+		for _, submitReq := range batch.Requests {
+			if err := s.handleSubmit(submitReq, stream, addr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	// Else, it's a consensus message.
 	return s.Dispatcher.DispatchConsensus(stream.Context(), request.GetConsensusRequest())
 }
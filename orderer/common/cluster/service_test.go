@@ -127,6 +127,57 @@ func TestSubmitSuccess(t *testing.T) {
 	dispatcher.AssertNumberOfCalls(t, "DispatchSubmit", 2)
 }
 
+func TestSubmitBatch(t *testing.T) {
+	t.Parallel()
+	dispatcher := &mocks.Dispatcher{}
+
+	requests := []*orderer.SubmitRequest{{Channel: "mychannel", LastValidationSeq: 1}, {Channel: "mychannel", LastValidationSeq: 2}}
+	batch := &orderer.StepRequest{
+		Payload: &orderer.StepRequest_SubmitBatch{
+			SubmitBatch: &orderer.SubmitBatch{Requests: requests},
+		},
+	}
+
+	stream := &mocks.StepStream{}
+	stream.On("Context").Return(context.Background())
+	stream.On("Recv").Return(batch, nil).Once()
+	stream.On("Recv").Return(nil, io.EOF).Once()
+
+	// Ensure we pass the requests of the batch to DispatchSubmit in-order
+	var dispatched []*orderer.SubmitRequest
+	dispatcher.On("DispatchSubmit", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		dispatched = append(dispatched, args.Get(1).(*orderer.SubmitRequest))
+	}).Return(nil)
+
+	svc := &cluster.Service{
+		StreamCountReporter: &cluster.StreamCountReporter{
+			Metrics: cluster.NewMetrics(&disabled.Provider{}),
+		},
+		Logger:     flogging.MustGetLogger("test"),
+		StepLogger: flogging.MustGetLogger("test"),
+		Dispatcher: dispatcher,
+	}
+
+	err := svc.Step(stream)
+	assert.NoError(t, err)
+	assert.Equal(t, requests, dispatched)
+
+	t.Run("Failure", func(t *testing.T) {
+		dispatcher := &mocks.Dispatcher{}
+		dispatcher.On("DispatchSubmit", mock.Anything, mock.Anything).Return(errors.New("oops"))
+		svc.Dispatcher = dispatcher
+
+		stream := &mocks.StepStream{}
+		stream.On("Context").Return(context.Background())
+		stream.On("Recv").Return(batch, nil).Once()
+
+		err := svc.Step(stream)
+		assert.EqualError(t, err, "oops")
+		// the rest of the batch is not dispatched once a request fails
+		dispatcher.AssertNumberOfCalls(t, "DispatchSubmit", 1)
+	})
+}
+
 type tuple struct {
 	msg interface{}
 	err error
@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// TLSPolicy restricts the TLS connections over which the messages of a channel
+// are sent to, and received from, the remote nodes, for environments which
+// mandate a minimum TLS version or a set of cipher suites. The zero TLSPolicy
+// allows the connections the dialer and the listener of the node negotiate.
+type TLSPolicy struct {
+	// MinVersion is the minimum TLS version of the connections, if set.
+	MinVersion uint16
+	// CipherSuites are the cipher suites allowed for TLS 1.2 connections,
+	// if set. The cipher suites of TLS 1.3 are not configurable.
+	CipherSuites []uint16
+}
+
+// NewTLSPolicy returns the TLSPolicy of the given minimum TLS version, such as 1.3,
+// and cipher suites, by their standard names, such as TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384.
+func NewTLSPolicy(minVersion string, cipherSuites []string) (TLSPolicy, error) {
+	var policy TLSPolicy
+	if minVersion != "" {
+		version, err := crypto.ParseTLSVersion(minVersion)
+		if err != nil {
+			return TLSPolicy{}, err
+		}
+		policy.MinVersion = version
+	}
+	suites, err := crypto.ParseCipherSuites(cipherSuites)
+	if err != nil {
+		return TLSPolicy{}, err
+	}
+	policy.CipherSuites = suites
+	return policy, nil
+}
+
+// Equal returns whether the given TLSPolicy is the same as this one.
+func (p TLSPolicy) Equal(other TLSPolicy) bool {
+	if p.MinVersion != other.MinVersion || len(p.CipherSuites) != len(other.CipherSuites) {
+		return false
+	}
+	for i := range p.CipherSuites {
+		if p.CipherSuites[i] != other.CipherSuites[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns a representation of the TLSPolicy for logging.
+func (p TLSPolicy) String() string {
+	var parts []string
+	if p.MinVersion != 0 {
+		parts = append(parts, "min version "+crypto.TLSVersionName(p.MinVersion))
+	}
+	if len(p.CipherSuites) > 0 {
+		names := make([]string, len(p.CipherSuites))
+		for i, suite := range p.CipherSuites {
+			names[i] = tls.CipherSuiteName(suite)
+		}
+		parts = append(parts, "cipher suites "+strings.Join(names, ", "))
+	}
+	if len(parts) == 0 {
+		return "any"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Check returns an error if the given state of a TLS connection does not satisfy the TLSPolicy.
+func (p TLSPolicy) Check(state tls.ConnectionState) error {
+	if state.Version < p.MinVersion {
+		return errors.Errorf("TLS %s connection does not satisfy the minimum version %s",
+			crypto.TLSVersionName(state.Version), crypto.TLSVersionName(p.MinVersion))
+	}
+	if len(p.CipherSuites) == 0 || state.Version >= tls.VersionTLS13 {
+		return nil
+	}
+	for _, suite := range p.CipherSuites {
+		if state.CipherSuite == suite {
+			return nil
+		}
+	}
+	return errors.Errorf("TLS connection uses cipher suite %s, which is not allowed", tls.CipherSuiteName(state.CipherSuite))
+}
+
+// checkContext checks the TLS connection of the gRPC stream of the given context
+// against the TLSPolicy, if it restricts the connections.
+func (p TLSPolicy) checkContext(ctx context.Context) error {
+	if p.MinVersion == 0 && len(p.CipherSuites) == 0 {
+		return nil
+	}
+	pr, extracted := peer.FromContext(ctx)
+	if !extracted {
+		return errors.New("no TLS connection state")
+	}
+	tlsInfo, isTLSConn := pr.AuthInfo.(credentials.TLSInfo)
+	if !isTLSConn {
+		return errors.New("connection is not a TLS connection")
+	}
+	return p.Check(tlsInfo.State)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cluster_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTLSPolicy(t *testing.T) {
+	policy, err := cluster.NewTLSPolicy("", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, cluster.TLSPolicy{}, policy)
+	assert.Equal(t, "any", policy.String())
+
+	policy, err = cluster.NewTLSPolicy("1.2", []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"})
+	assert.NoError(t, err)
+	assert.Equal(t, cluster.TLSPolicy{
+		MinVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+	}, policy)
+	assert.Equal(t, "min version 1.2, cipher suites TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384", policy.String())
+
+	_, err = cluster.NewTLSPolicy("1.1", nil)
+	assert.EqualError(t, err, "unsupported TLS version: 1.1, expected 1.2 or 1.3")
+	_, err = cluster.NewTLSPolicy("1.3", []string{"TLS_RSA_WITH_RC4_128_SHA"})
+	assert.EqualError(t, err, "unknown or insecure TLS cipher suite: TLS_RSA_WITH_RC4_128_SHA")
+}
+
+func TestTLSPolicyCheck(t *testing.T) {
+	tls12 := tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+	tls13 := tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256}
+
+	assert.NoError(t, cluster.TLSPolicy{}.Check(tls12))
+
+	policy := cluster.TLSPolicy{MinVersion: tls.VersionTLS13}
+	assert.EqualError(t, policy.Check(tls12), "TLS 1.2 connection does not satisfy the minimum version 1.3")
+	assert.NoError(t, policy.Check(tls13))
+
+	policy = cluster.TLSPolicy{CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}}
+	assert.EqualError(t, policy.Check(tls12), "TLS connection uses cipher suite TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, which is not allowed")
+	assert.NoError(t, policy.Check(tls13))
+	tls12.CipherSuite = tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	assert.NoError(t, policy.Check(tls12))
+
+	assert.True(t, policy.Equal(cluster.TLSPolicy{CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384}}))
+	assert.False(t, policy.Equal(cluster.TLSPolicy{}))
+	assert.False(t, policy.Equal(cluster.TLSPolicy{MinVersion: tls.VersionTLS13, CipherSuites: policy.CipherSuites}))
+}
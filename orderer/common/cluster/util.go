@@ -10,17 +10,21 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/pem"
+	"fmt"
 	"reflect"
 	"sync/atomic"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/tools/protolator"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -379,6 +383,52 @@ func VerifyBlockSignature(block *common.Block, verifier BlockVerifier, config *c
 type EndpointConfig struct {
 	TLSRootCAs [][]byte
 	Endpoints  []string
+	// ConsenterEndpoints are the endpoints of the consenters
+	// of the channel, if its orderer type keeps track of them.
+	ConsenterEndpoints []string
+}
+
+// EndpointStrategy defines the endpoints blocks are pulled from.
+type EndpointStrategy string
+
+const (
+	// EndpointsGlobal pulls blocks from the global orderer addresses of the channel.
+	EndpointsGlobal EndpointStrategy = "global"
+	// EndpointsPreferConsenters pulls blocks from the consenters of the channel,
+	// and from the global orderer addresses if the channel has no consenters.
+	EndpointsPreferConsenters EndpointStrategy = "prefer-consenters"
+	// EndpointsAll pulls blocks from both the consenters and the global orderer addresses.
+	EndpointsAll EndpointStrategy = "all"
+)
+
+// SelectEndpoints returns the endpoints to pull blocks from, according to the given
+// strategy. An empty strategy selects the global orderer addresses.
+func (ec *EndpointConfig) SelectEndpoints(strategy EndpointStrategy) ([]string, error) {
+	switch strategy {
+	case "", EndpointsGlobal:
+		return ec.Endpoints, nil
+	case EndpointsPreferConsenters:
+		if len(ec.ConsenterEndpoints) == 0 {
+			return ec.Endpoints, nil
+		}
+		return ec.ConsenterEndpoints, nil
+	case EndpointsAll:
+		var endpoints []string
+		seen := make(map[string]struct{})
+		for _, list := range [][]string{ec.ConsenterEndpoints, ec.Endpoints} {
+			for _, endpoint := range list {
+				if _, exists := seen[endpoint]; exists {
+					continue
+				}
+				seen[endpoint] = struct{}{}
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		return endpoints, nil
+	default:
+		return nil, errors.Errorf("unknown endpoint strategy %q, expected one of %s, %s or %s",
+			strategy, EndpointsGlobal, EndpointsPreferConsenters, EndpointsAll)
+	}
 }
 
 // EndpointconfigFromConfigBlock retrieves TLS CA certificates and endpoints
@@ -411,12 +461,37 @@ func EndpointconfigFromConfigBlock(block *common.Block) (*EndpointConfig, error)
 		}
 		tlsCACerts = append(tlsCACerts, msp.GetTLSRootCerts()...)
 	}
+	consenterEndpoints, err := consenterEndpoints(ordererConfig)
+	if err != nil {
+		return nil, err
+	}
 	return &EndpointConfig{
-		Endpoints:  bundle.ChannelConfig().OrdererAddresses(),
-		TLSRootCAs: tlsCACerts,
+		Endpoints:          bundle.ChannelConfig().OrdererAddresses(),
+		ConsenterEndpoints: consenterEndpoints,
+		TLSRootCAs:         tlsCACerts,
 	}, nil
 }
 
+// consenterEndpoints returns the endpoints of the consenters and standby
+// consenters of an etcdraft channel, or nil for other orderer types.
+func consenterEndpoints(ordererConfig channelconfig.Orderer) ([]string, error) {
+	if ordererConfig.ConsensusType() != etcdraft.TypeKey {
+		return nil, nil
+	}
+	md := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(ordererConfig.ConsensusMetadata(), md); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+	var endpoints []string
+	for _, consenter := range md.Consenters {
+		endpoints = append(endpoints, fmt.Sprintf("%s:%d", consenter.Host, consenter.Port))
+	}
+	for _, consenter := range md.StandbyConsenters {
+		endpoints = append(endpoints, fmt.Sprintf("%s:%d", consenter.Host, consenter.Port))
+	}
+	return endpoints, nil
+}
+
 //go:generate mockery -dir . -name VerifierFactory -case underscore -output ./mocks/
 
 // VerifierFactory creates BlockVerifiers.
@@ -513,6 +588,18 @@ func (interceptor *LedgerInterceptor) Append(block *common.Block) error {
 	return interceptor.LedgerWriter.Append(block)
 }
 
+// BootstrapFromBlock appends the given block to an empty ledger as its first block,
+// if the ledger supports starting at a block other than the genesis block, and also
+// fires the configured callback.
+func (interceptor *LedgerInterceptor) BootstrapFromBlock(block *common.Block) error {
+	bootstrapper, ok := interceptor.LedgerWriter.(blockledger.Bootstrapper)
+	if !ok {
+		return errors.New("ledger does not support bootstrapping from a block")
+	}
+	defer interceptor.InterceptBlockCommit(block, interceptor.Channel)
+	return bootstrapper.BootstrapFromBlock(block)
+}
+
 // BlockVerifierAssembler creates a BlockVerifier out of a config envelope
 type BlockVerifierAssembler struct {
 	Logger *flogging.FabricLogger
@@ -11,6 +11,8 @@ import (
 	"encoding/pem"
 	"errors"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -18,6 +20,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/crypto/tlsgen"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/configtxgentest"
@@ -27,6 +30,7 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/common/cluster/mocks"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -468,6 +472,7 @@ func TestEndpointconfigFromConfigBlockGreenPath(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, endpointConfig.TLSRootCAs, 1)
 	assert.Equal(t, []string{"orderer.example.com:7050"}, endpointConfig.Endpoints)
+	assert.Empty(t, endpointConfig.ConsenterEndpoints)
 
 	bl, _ := pem.Decode(endpointConfig.TLSRootCAs[0])
 	cert, err := x509.ParseCertificate(bl.Bytes)
@@ -509,6 +514,113 @@ func TestEndpointconfigFromConfigBlockFailures(t *testing.T) {
 	})
 }
 
+func TestEndpointconfigFromEtcdraftConfigBlock(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	assert.NoError(t, err)
+	certDir, err := ioutil.TempDir("", "endpointconfig")
+	assert.NoError(t, err)
+	defer os.RemoveAll(certDir)
+	consenter := func(host string, port uint32) *etcdraft.Consenter {
+		certFile := func(name string) []byte {
+			keyPair, err := ca.NewServerCertKeyPair(host)
+			assert.NoError(t, err)
+			path := filepath.Join(certDir, host+"-"+name)
+			assert.NoError(t, ioutil.WriteFile(path, keyPair.Cert, 0600))
+			return []byte(path)
+		}
+		return &etcdraft.Consenter{Host: host, Port: port, ClientTlsCert: certFile("client.crt"), ServerTlsCert: certFile("server.crt")}
+	}
+
+	config := configtxgentest.Load(localconfig.SampleInsecureSoloProfile)
+	config.Orderer.OrdererType = etcdraft.TypeKey
+	config.Orderer.EtcdRaft = &etcdraft.ConfigMetadata{
+		Consenters: []*etcdraft.Consenter{
+			consenter("raft0.example.com", 7050),
+			consenter("raft1.example.com", 7051),
+		},
+		StandbyConsenters: []*etcdraft.Consenter{
+			consenter("raft2.example.com", 7052),
+		},
+	}
+	block := encoder.New(config).GenesisBlockForChannel("mychannel")
+
+	endpointConfig, err := cluster.EndpointconfigFromConfigBlock(block)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:7050"}, endpointConfig.Endpoints)
+	assert.Equal(t, []string{"raft0.example.com:7050", "raft1.example.com:7051", "raft2.example.com:7052"}, endpointConfig.ConsenterEndpoints)
+}
+
+func TestSelectEndpoints(t *testing.T) {
+	withConsenters := &cluster.EndpointConfig{
+		Endpoints:          []string{"orderer.example.com:7050", "raft0.example.com:7050"},
+		ConsenterEndpoints: []string{"raft0.example.com:7050", "raft1.example.com:7050"},
+	}
+	withoutConsenters := &cluster.EndpointConfig{
+		Endpoints: []string{"orderer.example.com:7050"},
+	}
+
+	for _, testCase := range []struct {
+		name              string
+		endpointConfig    *cluster.EndpointConfig
+		strategy          cluster.EndpointStrategy
+		expectedEndpoints []string
+		expectedError     string
+	}{
+		{
+			name:              "no strategy",
+			endpointConfig:    withConsenters,
+			expectedEndpoints: []string{"orderer.example.com:7050", "raft0.example.com:7050"},
+		},
+		{
+			name:              "global",
+			endpointConfig:    withConsenters,
+			strategy:          cluster.EndpointsGlobal,
+			expectedEndpoints: []string{"orderer.example.com:7050", "raft0.example.com:7050"},
+		},
+		{
+			name:              "prefer consenters",
+			endpointConfig:    withConsenters,
+			strategy:          cluster.EndpointsPreferConsenters,
+			expectedEndpoints: []string{"raft0.example.com:7050", "raft1.example.com:7050"},
+		},
+		{
+			name:              "prefer consenters without consenters",
+			endpointConfig:    withoutConsenters,
+			strategy:          cluster.EndpointsPreferConsenters,
+			expectedEndpoints: []string{"orderer.example.com:7050"},
+		},
+		{
+			name:              "all",
+			endpointConfig:    withConsenters,
+			strategy:          cluster.EndpointsAll,
+			expectedEndpoints: []string{"raft0.example.com:7050", "raft1.example.com:7050", "orderer.example.com:7050"},
+		},
+		{
+			name:              "all without consenters",
+			endpointConfig:    withoutConsenters,
+			strategy:          cluster.EndpointsAll,
+			expectedEndpoints: []string{"orderer.example.com:7050"},
+		},
+		{
+			name:           "unknown strategy",
+			endpointConfig: withConsenters,
+			strategy:       "consenters",
+			expectedError:  "unknown endpoint strategy \"consenters\", expected one of global, prefer-consenters or all",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			endpoints, err := testCase.endpointConfig.SelectEndpoints(testCase.strategy)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, endpoints)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedEndpoints, endpoints)
+		})
+	}
+}
+
 func TestClientConfig(t *testing.T) {
 	t.Run("Uninitialized dialer", func(t *testing.T) {
 		dialer := &cluster.PredicateDialer{}
@@ -991,4 +1103,26 @@ func TestLedgerInterceptor(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, intercepted)
 	ledger.AssertCalled(t, "Append", block)
+
+	intercepted = false
+	err = interceptedLedger.(blockledger.Bootstrapper).BootstrapFromBlock(block)
+	assert.EqualError(t, err, "ledger does not support bootstrapping from a block")
+	assert.False(t, intercepted)
+
+	bootstrapper := &bootstrappingLedger{LedgerWriter: ledger}
+	interceptedLedger.(*cluster.LedgerInterceptor).LedgerWriter = bootstrapper
+	err = interceptedLedger.(blockledger.Bootstrapper).BootstrapFromBlock(block)
+	assert.NoError(t, err)
+	assert.True(t, intercepted)
+	assert.Equal(t, block, bootstrapper.bootstrappedFrom)
+}
+
+type bootstrappingLedger struct {
+	cluster.LedgerWriter
+	bootstrappedFrom *common.Block
+}
+
+func (bl *bootstrappingLedger) BootstrapFromBlock(block *common.Block) error {
+	bl.bootstrappedFrom = block
+	return nil
 }
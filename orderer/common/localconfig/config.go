@@ -55,6 +55,8 @@ type General struct {
 	LocalMSPID     string
 	BCCSP          *bccsp.FactoryOpts
 	Authentication Authentication
+	BlockSigner    BlockSigner
+	Shutdown       Shutdown
 }
 
 type Cluster struct {
@@ -72,7 +74,12 @@ type Cluster struct {
 	ReplicationRetryTimeout              time.Duration
 	ReplicationBackgroundRefreshInterval time.Duration
 	ReplicationMaxRetries                int
+	ReplicationEndpoints                 string
 	SendBufferSize                       int
+	RevocationLists                      []string
+	RevocationRefreshInterval            time.Duration
+	ResolutionTTL                        time.Duration
+	TLSMaxVersion                        string
 }
 
 // Keepalive contains configuration for gRPC servers.
@@ -92,6 +99,25 @@ type TLS struct {
 	ClientRootCAs      []string
 }
 
+// BlockSigner contains configuration for an external signing service which signs
+// blocks, and any other message the orderer signs, with the key of the orderer.
+type BlockSigner struct {
+	Enabled          bool
+	Address          string
+	Certificate      string
+	Timeout          time.Duration
+	MaxInflight      int
+	MaxRetryInterval time.Duration
+	RetryTimeout     time.Duration
+	TLS              TLS
+}
+
+// Shutdown contains configuration for halting the chains upon process exit.
+type Shutdown struct {
+	Timeout     time.Duration
+	Parallelism int
+}
+
 // SASLPlain contains configuration for SASL/PLAIN authentication
 type SASLPlain struct {
 	Enabled  bool
@@ -229,6 +255,8 @@ var Defaults = TopLevel{
 			ReplicationBackgroundRefreshInterval: time.Minute * 5,
 			ReplicationRetryTimeout:              time.Second * 5,
 			ReplicationPullTimeout:               time.Second * 5,
+			ReplicationEndpoints:                 "global",
+			RevocationRefreshInterval:            time.Minute * 5,
 		},
 		LocalMSPDir: "msp",
 		LocalMSPID:  "SampleOrg",
@@ -236,6 +264,10 @@ var Defaults = TopLevel{
 		Authentication: Authentication{
 			TimeWindow: time.Duration(15 * time.Minute),
 		},
+		Shutdown: Shutdown{
+			Timeout:     time.Second * 10,
+			Parallelism: 16,
+		},
 	},
 	RAMLedger: RAMLedger{
 		HistorySize: 10000,
@@ -321,6 +353,7 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			coreconfig.TranslatePathInPlace(configDir, &c.General.Cluster.ClientCertificate)
 		}
 		c.General.Cluster.RootCAs = translateCAs(configDir, c.General.Cluster.RootCAs)
+		c.General.Cluster.RevocationLists = translateCAs(configDir, c.General.Cluster.RevocationLists)
 		// Translate any paths for general TLS configuration
 		c.General.TLS.RootCAs = translateCAs(configDir, c.General.TLS.RootCAs)
 		c.General.TLS.ClientRootCAs = translateCAs(configDir, c.General.TLS.ClientRootCAs)
@@ -328,6 +361,17 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		coreconfig.TranslatePathInPlace(configDir, &c.General.TLS.Certificate)
 		coreconfig.TranslatePathInPlace(configDir, &c.General.GenesisFile)
 		coreconfig.TranslatePathInPlace(configDir, &c.General.LocalMSPDir)
+		// Translate any paths for the block signer if applicable
+		if c.General.BlockSigner.Enabled {
+			coreconfig.TranslatePathInPlace(configDir, &c.General.BlockSigner.Certificate)
+			c.General.BlockSigner.TLS.RootCAs = translateCAs(configDir, c.General.BlockSigner.TLS.RootCAs)
+			if c.General.BlockSigner.TLS.PrivateKey != "" {
+				coreconfig.TranslatePathInPlace(configDir, &c.General.BlockSigner.TLS.PrivateKey)
+			}
+			if c.General.BlockSigner.TLS.Certificate != "" {
+				coreconfig.TranslatePathInPlace(configDir, &c.General.BlockSigner.TLS.Certificate)
+			}
+		}
 	}()
 
 	for {
@@ -367,6 +411,14 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			c.General.Cluster.ReplicationRetryTimeout = Defaults.General.Cluster.ReplicationRetryTimeout
 		case c.General.Cluster.ReplicationBackgroundRefreshInterval == 0:
 			c.General.Cluster.ReplicationBackgroundRefreshInterval = Defaults.General.Cluster.ReplicationBackgroundRefreshInterval
+		case c.General.Cluster.ReplicationEndpoints == "":
+			c.General.Cluster.ReplicationEndpoints = Defaults.General.Cluster.ReplicationEndpoints
+		case c.General.Cluster.RevocationRefreshInterval == 0:
+			c.General.Cluster.RevocationRefreshInterval = Defaults.General.Cluster.RevocationRefreshInterval
+		case c.General.Shutdown.Timeout == 0:
+			c.General.Shutdown.Timeout = Defaults.General.Shutdown.Timeout
+		case c.General.Shutdown.Parallelism == 0:
+			c.General.Shutdown.Parallelism = Defaults.General.Shutdown.Parallelism
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.Certificate == "":
 			logger.Panicf("General.Kafka.TLS.Certificate must be set if General.Kafka.TLS.Enabled is set to true.")
 		case c.Kafka.TLS.Enabled && c.Kafka.TLS.PrivateKey == "":
@@ -379,6 +431,11 @@ func (c *TopLevel) completeInitialization(configDir string) {
 		case c.Kafka.SASLPlain.Enabled && c.Kafka.SASLPlain.Password == "":
 			logger.Panic("General.Kafka.SASLPlain.Password must be set if General.Kafka.SASLPlain.Enabled is set to true.")
 
+		case c.General.BlockSigner.Enabled && c.General.BlockSigner.Address == "":
+			logger.Panic("General.BlockSigner.Address must be set if General.BlockSigner.Enabled is set to true.")
+		case c.General.BlockSigner.Enabled && c.General.BlockSigner.Certificate == "":
+			logger.Panic("General.BlockSigner.Certificate must be set if General.BlockSigner.Enabled is set to true.")
+
 		case c.General.Profile.Enabled && c.General.Profile.Address == "":
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", Defaults.General.Profile.Address)
 			c.General.Profile.Address = Defaults.General.Profile.Address
@@ -132,6 +132,39 @@ func TestKafkaSASLPlain(t *testing.T) {
 	}
 }
 
+func TestBlockSignerConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		signer      BlockSigner
+		shouldPanic bool
+	}{
+		{"Disabled", BlockSigner{Enabled: false}, false},
+		{"Enabled", BlockSigner{Enabled: true, Address: "signer:7060", Certificate: "cert.pem"}, false},
+		{"EnabledNoAddress", BlockSigner{Enabled: true, Certificate: "cert.pem"}, true},
+		{"EnabledNoCertificate", BlockSigner{Enabled: true, Address: "signer:7060"}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			uconf := &TopLevel{General: General{BlockSigner: tc.signer}}
+			if tc.shouldPanic {
+				assert.Panics(t, func() { uconf.completeInitialization("/dummy/path") }, "Should panic")
+			} else {
+				assert.NotPanics(t, func() { uconf.completeInitialization("/dummy/path") }, "Should not panic")
+			}
+		})
+	}
+
+	uconf := &TopLevel{General: General{BlockSigner: BlockSigner{
+		Enabled:     true,
+		Address:     "signer:7060",
+		Certificate: "cert.pem",
+		TLS:         TLS{Enabled: true, RootCAs: []string{"ca.pem"}},
+	}}}
+	uconf.completeInitialization("/dummy/path")
+	assert.Equal(t, filepath.Join("/dummy/path", "cert.pem"), uconf.General.BlockSigner.Certificate)
+	assert.Equal(t, []string{filepath.Join("/dummy/path", "ca.pem")}, uconf.General.BlockSigner.TLS.RootCAs)
+}
+
 func TestClusterDefaults(t *testing.T) {
 	cleanup := configtest.SetDevFabricConfigPath(t)
 	defer cleanup()
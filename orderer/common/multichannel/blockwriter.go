@@ -17,6 +17,7 @@ import (
 	"github.com/hyperledger/fabric/common/util"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
 )
 
 type blockWriterSupport interface {
@@ -38,6 +39,9 @@ type BlockWriter struct {
 	lastConfigSeq      uint64
 	lastBlock          *cb.Block
 	committingBlock    sync.Mutex
+	committed          func(block *cb.Block) // called once a block is committed, if set
+	halt               func()                // halts the chain of the channel, if set, once a block cannot be signed
+	signingErr         error                 // the error a block could not be signed with, after which no block is committed
 }
 
 func newBlockWriter(lastBlock *cb.Block, r *Registrar, support blockWriterSupport) *BlockWriter {
@@ -149,7 +153,8 @@ func (bw *BlockWriter) WriteConfigBlock(block *cb.Block, encodedMetadataValue []
 // Before returning, it acquires the committing lock, and spawns a go routine which will
 // annotate the block with metadata and signatures, and write the block to the ledger
 // then release the lock.  This allows the calling thread to begin assembling the next block
-// before the commit phase is complete.
+// before the commit phase is complete. If the block cannot be signed, neither it nor the
+// blocks following it are written, and the chain of the channel is halted.
 func (bw *BlockWriter) WriteBlock(block *cb.Block, encodedMetadataValue []byte) {
 	bw.committingBlock.Lock()
 	bw.lastBlock = block
@@ -163,21 +168,61 @@ func (bw *BlockWriter) WriteBlock(block *cb.Block, encodedMetadataValue []byte)
 // commitBlock should only ever be invoked with the bw.committingBlock held
 // this ensures that the encoded config sequence numbers stay in sync
 func (bw *BlockWriter) commitBlock(encodedMetadataValue []byte) {
+	// Once a block could not be signed, the blocks following it are not committed,
+	// lest the ledger have a gap, until the chain is halted and started over
+	if bw.signingErr != nil {
+		logger.Errorf("[channel: %s] Not writing block %d, since a preceding block could not be signed: %s",
+			bw.support.ChainID(), bw.lastBlock.GetHeader().Number, bw.signingErr)
+		return
+	}
+
 	// Set the orderer-related metadata field
 	if encodedMetadataValue != nil {
 		bw.lastBlock.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&cb.Metadata{Value: encodedMetadataValue})
 	}
-	bw.addBlockSignature(bw.lastBlock)
-	bw.addLastConfigSignature(bw.lastBlock)
+	// The signatures are made concurrently, as each of them may take a round trip to an external signer
+	var signed sync.WaitGroup
+	var blockSignatureErr error
+	signed.Add(1)
+	go func() {
+		defer signed.Done()
+		blockSignatureErr = bw.addBlockSignature(bw.lastBlock)
+	}()
+	err := bw.addLastConfigSignature(bw.lastBlock)
+	signed.Wait()
+	if err == nil {
+		err = blockSignatureErr
+	}
+	if err != nil {
+		logger.Errorf("[channel: %s] Could not sign block %d, halting the chain: %s", bw.support.ChainID(), bw.lastBlock.GetHeader().Number, err)
+		bw.signingErr = err
+		if bw.halt != nil {
+			// The chain may be waiting to write its next block, hence it is halted once this one is given up
+			go bw.halt()
+		}
+		return
+	}
 
-	err := bw.support.Append(bw.lastBlock)
+	err = bw.support.Append(bw.lastBlock)
 	if err != nil {
 		logger.Panicf("[channel: %s] Could not append block: %s", bw.support.ChainID(), err)
 	}
 	logger.Debugf("[channel: %s] Wrote block %d", bw.support.ChainID(), bw.lastBlock.GetHeader().Number)
+	if bw.committed != nil {
+		bw.committed(bw.lastBlock)
+	}
 }
 
-func (bw *BlockWriter) addBlockSignature(block *cb.Block) {
+// ObserveCommits registers the given function, which is called with every
+// block committed from then on, once it is signed and appended to the ledger.
+// It is called by the committing go routine, hence must not write blocks.
+func (bw *BlockWriter) ObserveCommits(committed func(block *cb.Block)) {
+	bw.committingBlock.Lock()
+	defer bw.committingBlock.Unlock()
+	bw.committed = committed
+}
+
+func (bw *BlockWriter) addBlockSignature(block *cb.Block) error {
 	blockSignature := &cb.MetadataSignature{
 		SignatureHeader: utils.MarshalOrPanic(utils.NewSignatureHeaderOrPanic(bw.support)),
 	}
@@ -186,7 +231,11 @@ func (bw *BlockWriter) addBlockSignature(block *cb.Block) {
 	// information required beyond the fact that the metadata item is signed.
 	blockSignatureValue := []byte(nil)
 
-	blockSignature.Signature = utils.SignOrPanic(bw.support, util.ConcatenateBytes(blockSignatureValue, blockSignature.SignatureHeader, block.Header.Bytes()))
+	var err error
+	blockSignature.Signature, err = bw.support.Sign(util.ConcatenateBytes(blockSignatureValue, blockSignature.SignatureHeader, block.Header.Bytes()))
+	if err != nil {
+		return errors.WithMessage(err, "failed signing block")
+	}
 
 	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{
 		Value: blockSignatureValue,
@@ -194,9 +243,10 @@ func (bw *BlockWriter) addBlockSignature(block *cb.Block) {
 			blockSignature,
 		},
 	})
+	return nil
 }
 
-func (bw *BlockWriter) addLastConfigSignature(block *cb.Block) {
+func (bw *BlockWriter) addLastConfigSignature(block *cb.Block) error {
 	configSeq := bw.support.Sequence()
 	if configSeq > bw.lastConfigSeq {
 		logger.Debugf("[channel: %s] Detected lastConfigSeq transitioning from %d to %d, setting lastConfigBlockNum from %d to %d", bw.support.ChainID(), bw.lastConfigSeq, configSeq, bw.lastConfigBlockNum, block.Header.Number)
@@ -211,7 +261,11 @@ func (bw *BlockWriter) addLastConfigSignature(block *cb.Block) {
 	lastConfigValue := utils.MarshalOrPanic(&cb.LastConfig{Index: bw.lastConfigBlockNum})
 	logger.Debugf("[channel: %s] About to write block, setting its LAST_CONFIG to %d", bw.support.ChainID(), bw.lastConfigBlockNum)
 
-	lastConfigSignature.Signature = utils.SignOrPanic(bw.support, util.ConcatenateBytes(lastConfigValue, lastConfigSignature.SignatureHeader, block.Header.Bytes()))
+	var err error
+	lastConfigSignature.Signature, err = bw.support.Sign(util.ConcatenateBytes(lastConfigValue, lastConfigSignature.SignatureHeader, block.Header.Bytes()))
+	if err != nil {
+		return errors.WithMessage(err, "failed signing last config")
+	}
 
 	block.Metadata.Metadata[cb.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&cb.Metadata{
 		Value: lastConfigValue,
@@ -219,4 +273,5 @@ func (bw *BlockWriter) addLastConfigSignature(block *cb.Block) {
 			lastConfigSignature,
 		},
 	})
+	return nil
 }
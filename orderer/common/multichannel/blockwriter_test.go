@@ -18,6 +18,7 @@ import (
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,7 +58,7 @@ func TestBlockSignature(t *testing.T) {
 	}
 
 	block := cb.NewBlock(7, []byte("foo"))
-	bw.addBlockSignature(block)
+	assert.NoError(t, bw.addBlockSignature(block))
 
 	md := utils.GetMetadataFromBlockOrPanic(block, cb.BlockMetadataIndex_SIGNATURES)
 	assert.Nil(t, md.Value, "Value is empty in this case")
@@ -80,7 +81,7 @@ func TestBlockLastConfig(t *testing.T) {
 	}
 
 	block := cb.NewBlock(newBlockNum, []byte("foo"))
-	bw.addLastConfigSignature(block)
+	assert.NoError(t, bw.addLastConfigSignature(block))
 
 	assert.Equal(t, newBlockNum, bw.lastConfigBlockNum)
 	assert.Equal(t, newConfigSeq, bw.lastConfigSeq)
@@ -234,3 +235,84 @@ func TestRaceWriteConfig(t *testing.T) {
 	omd := utils.GetMetadataFromBlockOrPanic(block1, cb.BlockMetadataIndex_ORDERER)
 	assert.Equal(t, consenterMetadata1, omd.Value)
 }
+
+func TestObserveCommits(t *testing.T) {
+	confSys := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	genesisBlockSys := encoder.New(confSys).GenesisBlock()
+	_, l := newRAMLedgerAndFactory(10, genesisconfig.TestChainID, genesisBlockSys)
+
+	bw := &BlockWriter{
+		support: &mockBlockWriterSupport{
+			LocalSigner: mockCrypto(),
+			ReadWriter:  l,
+			Validator:   &mockconfigtx.Validator{},
+		},
+	}
+
+	var committed []*cb.Block
+	bw.ObserveCommits(func(block *cb.Block) {
+		// the block is observed once it is appended
+		assert.Equal(t, block.Header.Number+1, l.Height())
+		committed = append(committed, block)
+	})
+
+	block1 := cb.NewBlock(1, genesisBlockSys.Header.Hash())
+	bw.WriteBlock(block1, []byte("foo"))
+	block2 := cb.NewBlock(2, block1.Header.Hash())
+	bw.WriteBlock(block2, []byte("bar"))
+
+	// Wait for the commit to complete
+	bw.committingBlock.Lock()
+	bw.committingBlock.Unlock()
+
+	assert.Equal(t, []*cb.Block{block1, block2}, committed)
+	md := utils.GetMetadataFromBlockOrPanic(committed[1], cb.BlockMetadataIndex_SIGNATURES)
+	assert.NotEmpty(t, md.Signatures)
+}
+
+type failingSigner struct {
+	crypto.LocalSigner
+}
+
+func (failingSigner) Sign([]byte) ([]byte, error) {
+	return nil, errors.New("signing service is unreachable")
+}
+
+func TestWriteBlockSigningFailure(t *testing.T) {
+	confSys := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	genesisBlockSys := encoder.New(confSys).GenesisBlock()
+	_, l := newRAMLedgerAndFactory(10, genesisconfig.TestChainID, genesisBlockSys)
+
+	halted := make(chan struct{}, 2)
+	bw := &BlockWriter{
+		support: &mockBlockWriterSupport{
+			LocalSigner: failingSigner{LocalSigner: mockCrypto()},
+			ReadWriter:  l,
+			Validator:   &mockconfigtx.Validator{},
+		},
+		halt: func() { halted <- struct{}{} },
+	}
+
+	block1 := cb.NewBlock(1, genesisBlockSys.Header.Hash())
+	bw.WriteBlock(block1, []byte("foo"))
+	<-halted
+	bw.committingBlock.Lock()
+	bw.committingBlock.Unlock()
+
+	// the blocks following the block which was not signed are not written either
+	bw.support = &mockBlockWriterSupport{
+		LocalSigner: mockCrypto(),
+		ReadWriter:  l,
+		Validator:   &mockconfigtx.Validator{},
+	}
+	block2 := cb.NewBlock(2, block1.Header.Hash())
+	bw.WriteBlock(block2, []byte("bar"))
+
+	// Wait for the commit to complete
+	bw.committingBlock.Lock()
+	bw.committingBlock.Unlock()
+
+	assert.Equal(t, uint64(1), l.Height())
+	assert.EqualError(t, bw.signingErr, "failed signing last config: signing service is unreachable")
+	assert.Len(t, halted, 0)
+}
@@ -7,6 +7,8 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"context"
+
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/ledger/blockledger"
@@ -88,6 +90,7 @@ func newChainSupport(
 	if err != nil {
 		logger.Panicf("[channel: %s] Error creating consenter: %s", cs.ChainID(), err)
 	}
+	cs.BlockWriter.halt = cs.Chain.Halt
 
 	logger.Debugf("[channel: %s] Done creating channel support resources", cs.ChainID())
 
@@ -144,6 +147,9 @@ func (cs *ChainSupport) detectMigration(lastBlock *cb.Block) bool {
 // Block returns a block with the following number,
 // or nil if such a block doesn't exist.
 func (cs *ChainSupport) Block(number uint64) *cb.Block {
+	if block, _ := cs.RecentBlock(number); block != nil {
+		return block
+	}
 	if cs.Height() <= number {
 		return nil
 	}
@@ -192,6 +198,50 @@ func (cs *ChainSupport) ProposeConfigUpdate(configtx *cb.Envelope) (*cb.ConfigEn
 	return env, cs.ValidateNew(bundle)
 }
 
+// Admit passes through to the underlying chain if it is a consensus.AdmissionController,
+// otherwise messages are always admitted.
+func (cs *ChainSupport) Admit() error {
+	if ac, ok := cs.Chain.(consensus.AdmissionController); ok {
+		return ac.Admit()
+	}
+	return nil
+}
+
+// HealthTransitions passes through to the underlying chain if it is a consensus.HealthReporter,
+// otherwise no transitions are recorded and the returned channel is nil, hence never closed.
+func (cs *ChainSupport) HealthTransitions(after uint64) ([]consensus.HealthTransition, <-chan struct{}) {
+	if hr, ok := cs.Chain.(consensus.HealthReporter); ok {
+		return hr.HealthTransitions(after)
+	}
+	return nil, nil
+}
+
+// RecentBlock passes through to the underlying chain if it is a consensus.RecentBlockCache,
+// otherwise no blocks are cached and the returned channel is nil, hence never closed.
+func (cs *ChainSupport) RecentBlock(number uint64) (*cb.Block, <-chan struct{}) {
+	if rbc, ok := cs.Chain.(consensus.RecentBlockCache); ok {
+		return rbc.RecentBlock(number)
+	}
+	return nil, nil
+}
+
+// HasDuties passes through to the underlying chain if it is a consensus.GracefulHalter,
+// otherwise the chain has no duties to hand over.
+func (cs *ChainSupport) HasDuties() bool {
+	if gh, ok := cs.Chain.(consensus.GracefulHalter); ok {
+		return gh.HasDuties()
+	}
+	return false
+}
+
+// HandOver passes through to the underlying chain if it is a consensus.GracefulHalter,
+// otherwise it returns right away.
+func (cs *ChainSupport) HandOver(ctx context.Context) {
+	if gh, ok := cs.Chain.(consensus.GracefulHalter); ok {
+		gh.HandOver(ctx)
+	}
+}
+
 // ChainID passes through to the underlying configtx.Validator
 func (cs *ChainSupport) ChainID() string {
 	return cs.ConfigtxValidator().ChainID()
@@ -19,6 +19,7 @@ import (
 	"github.com/hyperledger/fabric/common/tools/configtxgen/configtxgentest"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/encoder"
 	"github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
+	"github.com/hyperledger/fabric/orderer/consensus"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/pkg/errors"
@@ -95,6 +96,86 @@ func TestVerifyBlockSignature(t *testing.T) {
 
 }
 
+type admissionControlledChain struct {
+	*mockChain
+	err error
+}
+
+func (acc *admissionControlledChain) Admit() error {
+	return acc.err
+}
+
+func TestChainSupportAdmit(t *testing.T) {
+	cs := &ChainSupport{Chain: &mockChain{}}
+	assert.NoError(t, cs.Admit())
+
+	cs = &ChainSupport{Chain: &admissionControlledChain{mockChain: &mockChain{}, err: errors.New("saturated")}}
+	assert.EqualError(t, cs.Admit(), "saturated")
+}
+
+type healthReportingChain struct {
+	*mockChain
+	transitions []consensus.HealthTransition
+	changedC    chan struct{}
+}
+
+func (hrc *healthReportingChain) HealthTransitions(after uint64) ([]consensus.HealthTransition, <-chan struct{}) {
+	return hrc.transitions[after:], hrc.changedC
+}
+
+func TestChainSupportHealthTransitions(t *testing.T) {
+	cs := &ChainSupport{Chain: &mockChain{}}
+	transitions, changedC := cs.HealthTransitions(0)
+	assert.Empty(t, transitions)
+	assert.Nil(t, changedC)
+
+	chain := &healthReportingChain{
+		mockChain: &mockChain{},
+		transitions: []consensus.HealthTransition{
+			{Sequence: 1, Errored: true},
+			{Sequence: 2, Errored: false},
+		},
+		changedC: make(chan struct{}),
+	}
+	cs = &ChainSupport{Chain: chain}
+	transitions, changedC = cs.HealthTransitions(1)
+	assert.Equal(t, []consensus.HealthTransition{{Sequence: 2, Errored: false}}, transitions)
+	assert.Equal(t, (<-chan struct{})(chain.changedC), changedC)
+}
+
+type recentBlockCachingChain struct {
+	*mockChain
+	blocks   map[uint64]*common.Block
+	changedC chan struct{}
+}
+
+func (rbc *recentBlockCachingChain) RecentBlock(number uint64) (*common.Block, <-chan struct{}) {
+	return rbc.blocks[number], rbc.changedC
+}
+
+func TestChainSupportRecentBlock(t *testing.T) {
+	cs := &ChainSupport{Chain: &mockChain{}}
+	block, changedC := cs.RecentBlock(0)
+	assert.Nil(t, block)
+	assert.Nil(t, changedC)
+
+	ledger := &mocks.ReadWriter{}
+	ledger.On("Height").Return(uint64(100))
+	chain := &recentBlockCachingChain{
+		mockChain: &mockChain{},
+		blocks:    map[uint64]*common.Block{99: {Header: &common.BlockHeader{Number: 99}}},
+		changedC:  make(chan struct{}),
+	}
+	cs = &ChainSupport{ledgerResources: &ledgerResources{ReadWriter: ledger}, Chain: chain}
+	block, changedC = cs.RecentBlock(99)
+	assert.Equal(t, chain.blocks[99], block)
+	assert.Equal(t, (<-chan struct{})(chain.changedC), changedC)
+
+	// cached blocks are not read from the ledger, whose Iterator is not mocked
+	assert.Equal(t, chain.blocks[99], cs.Block(99))
+	assert.Nil(t, cs.Block(100))
+}
+
 func testConfigEnvelope(t *testing.T) *common.ConfigEnvelope {
 	config := configtxgentest.Load(localconfig.SampleInsecureSoloProfile)
 	group, err := encoder.NewChannelGroup(config)
@@ -11,6 +11,7 @@ package multichannel
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/hyperledger/fabric/common/channelconfig"
@@ -146,6 +147,8 @@ func (r *Registrar) Initialize(consenters map[string]consensus.Consenter) {
 	// determining the correct consensus-type and the state of the migration. This is needed for recovery, in case the
 	// migration process crashes before it is committed.
 
+	// Without a system channel, the orderer may only operate if all of its channels deprecate it.
+	sysChannelDeprecated := len(existingChains) > 0
 	for _, chainID := range existingChains {
 		rl, err := r.ledgerFactory.GetOrCreate(chainID)
 		if err != nil {
@@ -202,12 +205,18 @@ func (r *Registrar) Initialize(consenters map[string]consensus.Consenter) {
 			)
 			r.chains[chainID] = chain
 			chain.start()
+			if !chain.SharedConfig().Capabilities().SystemChannelDeprecation() {
+				sysChannelDeprecated = false
+			}
 		}
 
 	}
 
 	if r.systemChannelID == "" {
-		logger.Panicf("No system chain found.  If bootstrapping, does your system channel contain a consortiums group definition?")
+		if !sysChannelDeprecated {
+			logger.Panicf("No system chain found.  If bootstrapping, does your system channel contain a consortiums group definition?")
+		}
+		logger.Infof("No system chain found, operating without a system channel as all %d channels deprecate it", len(r.chains))
 	}
 }
 
@@ -228,6 +237,9 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 	cs := r.GetChain(chdr.ChannelId)
 	// New channel creation
 	if cs == nil {
+		if r.systemChannel == nil {
+			return chdr, false, nil, errors.Errorf("channel %s does not exist, and channels cannot be created without a system channel", chdr.ChannelId)
+		}
 		// Prevent channel creation during consensus-type migration
 		if r.ConsensusMigrationPending() {
 			return chdr, true, nil, errors.New("cannot create channel because consensus-type migration is pending")
@@ -249,6 +261,9 @@ func (r *Registrar) BroadcastChannelSupport(msg *cb.Envelope) (*cb.ChannelHeader
 
 // ConsensusMigrationPending checks whether consensus-type migration is started on the system channel.
 func (r *Registrar) ConsensusMigrationPending() bool {
+	if r.systemChannel == nil {
+		return false
+	}
 	//Note: systemChannel.MigrationStatus().IsPending() is thread safe, takes a mutex
 	return r.systemChannel.MigrationStatus().IsPending()
 }
@@ -372,10 +387,105 @@ func (r *Registrar) CreateChain(chainName string) {
 	r.newChain(configTx(lf))
 }
 
+// JoinChain creates the chain of the channel of the given config block, which the ledger
+// of the channel is started with: the ledger holds the genesis block as is, and is seeded
+// with a later config block without the blocks preceding it. It creates channels without
+// a system channel, and is expected to be called only once the block is validated by the
+// consenter.
+func (r *Registrar) JoinChain(configBlock *cb.Block) error {
+	chainID, err := utils.GetChainIDFromBlock(configBlock)
+	if err != nil {
+		return err
+	}
+
+	// The lock is held until the chain is created, so that a concurrent join
+	// of the same channel cannot append to the ledger being created
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.chains[chainID] != nil {
+		return errors.Errorf("channel %s already exists", chainID)
+	}
+	ledger, err := r.ledgerFactory.GetOrCreate(chainID)
+	if err != nil {
+		return errors.Wrapf(err, "failed obtaining ledger of channel %s", chainID)
+	}
+	if ledger.Height() != 0 {
+		return errors.Errorf("ledger of channel %s already has %d blocks", chainID, ledger.Height())
+	}
+	if configBlock.Header.Number == 0 {
+		err = ledger.Append(configBlock)
+	} else if bootstrapper, ok := ledger.(blockledger.Bootstrapper); ok {
+		err = bootstrapper.BootstrapFromBlock(configBlock)
+	} else {
+		err = errors.New("ledger cannot be seeded with a config block other than the genesis block")
+	}
+	if err != nil {
+		// Remove the empty ledger, as chains are not created out of ledgers without blocks
+		if remover, ok := r.ledgerFactory.(blockledger.Remover); ok {
+			remover.Remove(chainID)
+		}
+		return errors.Wrapf(err, "failed starting ledger of channel %s with config block %d", chainID, configBlock.Header.Number)
+	}
+	logger.Infof("Joined channel %s with config block %d of hash %x", chainID, configBlock.Header.Number, configBlock.Header.Hash())
+	r.newChainLocked(configTx(ledger))
+	return nil
+}
+
+// RemoveChain halts the chain of the given channel, stops serving
+// the channel, and removes its ledger along with all of its blocks.
+func (r *Registrar) RemoveChain(chainID string) error {
+	cs, err := r.untrackChain(chainID)
+	if err != nil {
+		return err
+	}
+
+	// The chain is halted without holding the lock, as it might look up chains while halting
+	cs.Halt()
+	if err := r.ledgerFactory.(blockledger.Remover).Remove(chainID); err != nil {
+		return errors.Wrapf(err, "failed removing ledger of channel %s", chainID)
+	}
+	logger.Infof("Removed channel %s", chainID)
+	return nil
+}
+
+// untrackChain stops serving the given channel, if its ledger can be removed.
+func (r *Registrar) untrackChain(chainID string) (*ChainSupport, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	cs, exists := r.chains[chainID]
+	if !exists {
+		return nil, errors.Errorf("channel %s does not exist", chainID)
+	}
+	if chainID == r.systemChannelID {
+		return nil, errors.Errorf("channel %s is the system channel, and cannot be removed", chainID)
+	}
+	if _, ok := r.ledgerFactory.(blockledger.Remover); !ok {
+		return nil, errors.Errorf("ledger of channel %s cannot be removed", chainID)
+	}
+
+	// Copy the map to allow concurrent reads from broadcast/deliver while the chain is removed
+	newChains := make(map[string]*ChainSupport, len(r.chains)-1)
+	for key, value := range r.chains {
+		if key != chainID {
+			newChains[key] = value
+		}
+	}
+	r.chains = newChains
+	return cs, nil
+}
+
 func (r *Registrar) newChain(configtx *cb.Envelope) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
+	r.newChainLocked(configtx)
+}
+
+// newChainLocked creates and starts the chain of the config transaction.
+// It is called with the lock held.
+func (r *Registrar) newChainLocked(configtx *cb.Envelope) {
 	ledgerResources := r.newLedgerResources(configtx)
 	// If we have no blocks, we need to create the genesis block ourselves.
 	if ledgerResources.Height() == 0 {
@@ -407,6 +517,19 @@ func (r *Registrar) ChannelsCount() int {
 	return len(r.chains)
 }
 
+// ChainIDs returns the IDs of all channels served by this orderer, in sorted order.
+func (r *Registrar) ChainIDs() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	chainIDs := make([]string, 0, len(r.chains))
+	for chainID := range r.chains {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Strings(chainIDs)
+	return chainIDs
+}
+
 // NewChannelConfig produces a new template channel configuration based on the system channel's current config.
 func (r *Registrar) NewChannelConfig(envConfigUpdate *cb.Envelope) (channelconfig.Resources, error) {
 	return r.templator.NewChannelConfig(envConfigUpdate)
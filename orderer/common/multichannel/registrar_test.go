@@ -7,9 +7,11 @@ SPDX-License-Identifier: Apache-2.0
 package multichannel
 
 import (
+	"sync"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/capabilities"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/ledger/blockledger"
 	"github.com/hyperledger/fabric/common/ledger/blockledger/ram"
@@ -156,6 +158,26 @@ func TestNewRegistrar(t *testing.T) {
 		}, "Should have panicked when starting without a system chain")
 	})
 
+	// This test checks to make sure the orderer comes up without a system channel if all of its channels deprecate it
+	t.Run("No system chain - deprecated", func(t *testing.T) {
+		lf := ramledger.New(10)
+		rl, err := lf.GetOrCreate("mychannel")
+		require.NoError(t, err)
+		require.NoError(t, rl.Append(deprecatingGenesisBlock("mychannel")))
+
+		consenters := make(map[string]consensus.Consenter)
+		consenters[confSys.Orderer.OrdererType] = &mockConsenter{}
+
+		manager := NewRegistrar(lf, mockCrypto(), &disabled.Provider{})
+		manager.Initialize(consenters)
+		assert.Empty(t, manager.SystemChannelID())
+		assert.NotNil(t, manager.GetChain("mychannel"))
+		assert.False(t, manager.ConsensusMigrationPending())
+
+		_, _, _, err = manager.BroadcastChannelSupport(makeConfigTx("newchannel", 1))
+		assert.EqualError(t, err, "channel newchannel does not exist, and channels cannot be created without a system channel")
+	})
+
 	// This test checks to make sure that the orderer refuses to come up if there are multiple system channels
 	t.Run("Multiple system chains - failure", func(t *testing.T) {
 		lf := ramledger.New(10)
@@ -371,10 +393,12 @@ func TestCreateChain(t *testing.T) {
 
 		// Before creating the chain, it doesn't exist
 		assert.Nil(t, manager.GetChain("mychannel"))
+		assert.Equal(t, []string{genesisconfig.TestChainID}, manager.ChainIDs())
 		// After creating the chain, it exists
 		manager.CreateChain("mychannel")
 		chain := manager.GetChain("mychannel")
 		assert.NotNil(t, chain)
+		assert.Equal(t, []string{"mychannel", genesisconfig.TestChainID}, manager.ChainIDs())
 		// A subsequent creation, replaces the chain.
 		manager.CreateChain("mychannel")
 		chain2 := manager.GetChain("mychannel")
@@ -553,6 +577,121 @@ func TestResourcesCheck(t *testing.T) {
 	})
 }
 
+// deprecatingGenesisBlock returns the genesis block of a channel which
+// deprecates the system channel, and is hence created by joining it.
+func deprecatingGenesisBlock(chainID string) *cb.Block {
+	conf := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	conf.Consortiums = nil
+	conf.Orderer.Capabilities = map[string]bool{capabilities.OrdererV1_1: true, capabilities.OrdererV2_1: true}
+	return encoder.New(conf).GenesisBlockForChannel(chainID)
+}
+
+func TestJoinChain(t *testing.T) {
+	confSys := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	lf, _ := newRAMLedgerAndFactory(10, genesisconfig.TestChainID, encoder.New(confSys).GenesisBlock())
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[confSys.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, mockCrypto(), &disabled.Provider{})
+	manager.Initialize(consenters)
+
+	genesisBlock := deprecatingGenesisBlock("mychannel")
+	require.NoError(t, manager.JoinChain(genesisBlock))
+	chain := manager.GetChain("mychannel")
+	require.NotNil(t, chain)
+	assert.Equal(t, uint64(1), chain.Height())
+	assert.True(t, proto.Equal(genesisBlock, blockledger.GetBlock(chain, 0)))
+	assert.True(t, chain.SharedConfig().Capabilities().SystemChannelDeprecation())
+
+	err := manager.JoinChain(genesisBlock)
+	assert.EqualError(t, err, "channel mychannel already exists")
+
+	rl, err := lf.GetOrCreate("otherchannel")
+	require.NoError(t, err)
+	require.NoError(t, rl.Append(deprecatingGenesisBlock("otherchannel")))
+	err = manager.JoinChain(deprecatingGenesisBlock("otherchannel"))
+	assert.EqualError(t, err, "ledger of channel otherchannel already has 1 blocks")
+
+	configBlock := deprecatingGenesisBlock("thirdchannel")
+	configBlock.Header.Number = 5
+	err = manager.JoinChain(configBlock)
+	assert.EqualError(t, err, "failed starting ledger of channel thirdchannel with config block 5: "+
+		"ledger cannot be seeded with a config block other than the genesis block")
+	assert.NotContains(t, lf.ChainIDs(), "thirdchannel")
+
+	err = manager.JoinChain(&cb.Block{})
+	assert.Error(t, err)
+}
+
+func TestJoinChainConcurrently(t *testing.T) {
+	confSys := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	lf, _ := newRAMLedgerAndFactory(10, genesisconfig.TestChainID, encoder.New(confSys).GenesisBlock())
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[confSys.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, mockCrypto(), &disabled.Provider{})
+	manager.Initialize(consenters)
+
+	genesisBlock := deprecatingGenesisBlock("mychannel")
+	joins := 10
+	errs := make(chan error, joins)
+	var wg sync.WaitGroup
+	wg.Add(joins)
+	for i := 0; i < joins; i++ {
+		go func() {
+			defer wg.Done()
+			errs <- manager.JoinChain(genesisBlock)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	var joined int
+	for err := range errs {
+		if err == nil {
+			joined++
+			continue
+		}
+		assert.EqualError(t, err, "channel mychannel already exists")
+	}
+	assert.Equal(t, 1, joined)
+	assert.Equal(t, uint64(1), manager.GetChain("mychannel").Height())
+}
+
+func TestRemoveChain(t *testing.T) {
+	confSys := configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)
+	lf, _ := newRAMLedgerAndFactory(10, genesisconfig.TestChainID, encoder.New(confSys).GenesisBlock())
+
+	consenters := make(map[string]consensus.Consenter)
+	consenters[confSys.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewRegistrar(lf, mockCrypto(), &disabled.Provider{})
+	manager.Initialize(consenters)
+	require.NoError(t, manager.JoinChain(deprecatingGenesisBlock("mychannel")))
+	chain := manager.GetChain("mychannel")
+	require.NotNil(t, chain)
+
+	require.NoError(t, manager.RemoveChain("mychannel"))
+	assert.Nil(t, manager.GetChain("mychannel"))
+	assert.Equal(t, []string{genesisconfig.TestChainID}, manager.ChainIDs())
+	assert.Equal(t, []string{genesisconfig.TestChainID}, lf.ChainIDs())
+	// The removed chain is halted
+	_, ok := <-chain.Chain.(*mockChain).queue
+	assert.False(t, ok)
+
+	err := manager.RemoveChain("mychannel")
+	assert.EqualError(t, err, "channel mychannel does not exist")
+	err = manager.RemoveChain(genesisconfig.TestChainID)
+	assert.EqualError(t, err, "channel "+genesisconfig.TestChainID+" is the system channel, and cannot be removed")
+
+	// The channel can be joined once again
+	require.NoError(t, manager.JoinChain(deprecatingGenesisBlock("mychannel")))
+	assert.Equal(t, uint64(1), manager.GetChain("mychannel").Height())
+	close(manager.GetChain("mychannel").Chain.(*mockChain).queue)
+}
+
 // The registrar's BroadcastChannelSupport implementation should reject message types which should not be processed directly.
 func TestBroadcastChannelSupportRejection(t *testing.T) {
 	// system channel
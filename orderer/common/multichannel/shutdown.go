@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package multichannel
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultShutdownParallelism is the number of chains halted at a time
+// upon shutdown, unless specified otherwise.
+const DefaultShutdownParallelism = 16
+
+// Shutdown halts the chains of all channels upon process exit, at most the given
+// number of them at a time, within the given timeout. The chains with duties to
+// hand over, such as the chains whose leadership this node holds, are halted
+// first, once they handed over their duties, so that they get the most of the
+// timeout to do so. The chain of the system channel is halted last, as channels
+// are created through it. Shutdown returns once all chains are halted, or fails
+// once the timeout expires, leaving the chains not halted by then to the exit
+// of the process.
+func (r *Registrar) Shutdown(parallelism int, timeout time.Duration) error {
+	if parallelism <= 0 {
+		parallelism = DefaultShutdownParallelism
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	r.lock.RLock()
+	chains := make([]*ChainSupport, 0, len(r.chains))
+	for _, cs := range r.chains {
+		chains = append(chains, cs)
+	}
+	systemChannel := r.systemChannel
+	r.lock.RUnlock()
+
+	priorities := make(map[*ChainSupport]int, len(chains))
+	for _, cs := range chains {
+		switch {
+		case cs == systemChannel:
+			priorities[cs] = 2
+		case cs.HasDuties():
+			priorities[cs] = 0
+		default:
+			priorities[cs] = 1
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool {
+		if priorities[chains[i]] != priorities[chains[j]] {
+			return priorities[chains[i]] < priorities[chains[j]]
+		}
+		return chains[i].ChainID() < chains[j].ChainID()
+	})
+
+	logger.Infof("Halting %d chains, %d at a time, within %s", len(chains), parallelism, timeout)
+	start := time.Now()
+
+	var lock sync.Mutex
+	remaining := make(map[string]struct{}, len(chains))
+	for _, cs := range chains {
+		remaining[cs.ChainID()] = struct{}{}
+	}
+
+	chainsC := make(chan *ChainSupport)
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for cs := range chainsC {
+				if !haltGracefully(ctx, cs) {
+					return
+				}
+				lock.Lock()
+				delete(remaining, cs.ChainID())
+				lock.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, cs := range chains {
+		select {
+		case chainsC <- cs:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(chainsC)
+	wg.Wait()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(remaining) > 0 {
+		channels := make([]string, 0, len(remaining))
+		for channel := range remaining {
+			channels = append(channels, channel)
+		}
+		sort.Strings(channels)
+		return errors.Errorf("chains of channels %s were not halted within %s", strings.Join(channels, ", "), timeout)
+	}
+	logger.Infof("Halted %d chains in %s", len(chains), time.Since(start))
+	return nil
+}
+
+// haltGracefully halts the given chain once it handed over its duties, and
+// returns whether it is halted before the given context is done.
+func haltGracefully(ctx context.Context, cs *ChainSupport) bool {
+	cs.HandOver(ctx)
+
+	haltedC := make(chan struct{})
+	go func() {
+		cs.Halt()
+		close(haltedC)
+	}()
+
+	select {
+	case <-haltedC:
+		return true
+	case <-ctx.Done():
+		logger.Warnf("Chain of channel %s was not halted in time", cs.ChainID())
+		return false
+	}
+}
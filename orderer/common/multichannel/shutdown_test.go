@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package multichannel
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/common/mocks/configtx"
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownRecorder struct {
+	lock     sync.Mutex
+	halted   []string
+	inflight int
+	peak     int
+}
+
+type gracefullyHaltedChain struct {
+	*mockChain
+	channelID  string
+	duties     bool
+	handedOver bool
+	haltC      chan struct{} // Halt blocks until it is closed, if set
+	recorder   *shutdownRecorder
+}
+
+func (ghc *gracefullyHaltedChain) HasDuties() bool {
+	return ghc.duties
+}
+
+func (ghc *gracefullyHaltedChain) HandOver(ctx context.Context) {
+	ghc.handedOver = ghc.duties
+}
+
+func (ghc *gracefullyHaltedChain) Halt() {
+	r := ghc.recorder
+	r.lock.Lock()
+	r.inflight++
+	if r.inflight > r.peak {
+		r.peak = r.inflight
+	}
+	r.lock.Unlock()
+
+	if ghc.haltC != nil {
+		<-ghc.haltC
+	} else {
+		time.Sleep(time.Millisecond)
+	}
+
+	r.lock.Lock()
+	r.inflight--
+	r.halted = append(r.halted, ghc.channelID)
+	r.lock.Unlock()
+}
+
+func newShutdownChainSupport(chain *gracefullyHaltedChain) *ChainSupport {
+	return &ChainSupport{
+		ledgerResources: &ledgerResources{
+			configResources: &configResources{
+				mutableResources: &mutableResourcesMock{
+					Resources: config.Resources{
+						ConfigtxValidatorVal: &configtx.Validator{ChainIDVal: chain.channelID},
+					},
+				},
+			},
+		},
+		Chain: chain,
+	}
+}
+
+func TestRegistrarShutdown(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	chains := map[string]*gracefullyHaltedChain{
+		"system": {channelID: "system", duties: true, recorder: recorder},
+		"led1":   {channelID: "led1", duties: true, recorder: recorder},
+		"led2":   {channelID: "led2", duties: true, recorder: recorder},
+		"a":      {channelID: "a", recorder: recorder},
+		"b":      {channelID: "b", recorder: recorder},
+		"c":      {channelID: "c", recorder: recorder},
+	}
+	r := &Registrar{chains: make(map[string]*ChainSupport)}
+	for channelID, chain := range chains {
+		r.chains[channelID] = newShutdownChainSupport(chain)
+	}
+	r.systemChannel = r.chains["system"]
+
+	assert.NoError(t, r.Shutdown(1, time.Minute))
+	assert.Equal(t, []string{"led1", "led2", "a", "b", "c", "system"}, recorder.halted)
+	assert.Equal(t, 1, recorder.peak)
+	assert.True(t, chains["led1"].handedOver)
+	assert.True(t, chains["system"].handedOver)
+	assert.False(t, chains["a"].handedOver)
+}
+
+func TestRegistrarShutdownParallelism(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	haltC := make(chan struct{})
+	r := &Registrar{chains: make(map[string]*ChainSupport)}
+	for _, channelID := range []string{"a", "b", "c", "d", "e", "f"} {
+		r.chains[channelID] = newShutdownChainSupport(&gracefullyHaltedChain{channelID: channelID, haltC: haltC, recorder: recorder})
+	}
+
+	errC := make(chan error)
+	go func() {
+		errC <- r.Shutdown(3, time.Minute)
+	}()
+
+	inflight := func() int {
+		recorder.lock.Lock()
+		defer recorder.lock.Unlock()
+		return recorder.inflight
+	}
+	for deadline := time.Now().Add(time.Second); inflight() < 3 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 3, inflight())
+	close(haltC)
+
+	assert.NoError(t, <-errC)
+	assert.Len(t, recorder.halted, 6)
+	assert.Equal(t, 3, recorder.peak)
+}
+
+func TestRegistrarShutdownTimeout(t *testing.T) {
+	recorder := &shutdownRecorder{}
+	haltC := make(chan struct{})
+	defer close(haltC)
+	r := &Registrar{chains: map[string]*ChainSupport{
+		"a":     newShutdownChainSupport(&gracefullyHaltedChain{channelID: "a", recorder: recorder}),
+		"stuck": newShutdownChainSupport(&gracefullyHaltedChain{channelID: "stuck", haltC: haltC, recorder: recorder}),
+		"z":     newShutdownChainSupport(&gracefullyHaltedChain{channelID: "z", recorder: recorder}),
+	}}
+
+	start := time.Now()
+	err := r.Shutdown(1, 100*time.Millisecond)
+	assert.EqualError(t, err, "chains of channels stuck, z were not halted within 100ms")
+	assert.True(t, time.Since(start) < time.Second)
+	recorder.lock.Lock()
+	assert.Equal(t, []string{"a"}, recorder.halted)
+	recorder.lock.Unlock()
+}
@@ -0,0 +1,190 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/flogging"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var logger = flogging.MustGetLogger("orderer.common.remotesigner")
+
+const (
+	// DefaultTimeout bounds each attempt to sign a message if Timeout is not set.
+	DefaultTimeout = 5 * time.Second
+
+	// DefaultMaxInflight is the number of messages signed concurrently if MaxInflight is not set.
+	DefaultMaxInflight = 10
+
+	// DefaultMaxRetryInterval caps the interval between the attempts
+	// to sign a message if MaxRetryInterval is not set.
+	DefaultMaxRetryInterval = 10 * time.Second
+
+	// DefaultRetryTimeout bounds the time a message is retried for if RetryTimeout is not set.
+	DefaultRetryTimeout = 2 * time.Minute
+
+	initialRetryInterval = 100 * time.Millisecond
+)
+
+// Identity is the identity of the orderer, whose key is held by the signing service.
+type Identity interface {
+	// Serialize converts the identity to bytes.
+	Serialize() ([]byte, error)
+
+	// Verify checks a signature over a message against the identity.
+	Verify(msg []byte, sig []byte) error
+}
+
+// Config configures a Signer.
+type Config struct {
+	// Timeout bounds each attempt to sign a message.
+	Timeout time.Duration
+	// MaxInflight is the number of messages signed concurrently, across all
+	// channels. Further messages wait for the ones in flight to be signed.
+	MaxInflight int
+	// MaxRetryInterval caps the interval between the attempts to sign a message,
+	// which doubles after each failed attempt.
+	MaxRetryInterval time.Duration
+	// RetryTimeout bounds the time a message is retried for, after which
+	// the error of its last attempt is returned.
+	RetryTimeout time.Duration
+}
+
+// Signer is a crypto.LocalSigner which has messages signed by an external
+// signing service, so that the signing key of the orderer does not reside
+// on its host. The signature headers it makes carry the identity of the
+// orderer, and the signatures the signing service makes are checked
+// against it before they are used.
+//
+// Messages are signed concurrently, up to MaxInflight of them, so that the
+// signatures of a block, and the blocks of different channels, are signed
+// in a pipeline rather than one round trip to the signing service after
+// the other.
+type Signer struct {
+	identity Identity
+	creator  []byte
+	client   ab.SignerClient
+	config   Config
+	inflight chan struct{}
+}
+
+// NewSigner returns a Signer which has messages signed by the given signing service client.
+func NewSigner(identity Identity, client ab.SignerClient, config Config) (*Signer, error) {
+	creator, err := identity.Serialize()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed serializing identity")
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxInflight <= 0 {
+		config.MaxInflight = DefaultMaxInflight
+	}
+	if config.MaxRetryInterval <= 0 {
+		config.MaxRetryInterval = DefaultMaxRetryInterval
+	}
+	if config.RetryTimeout <= 0 {
+		config.RetryTimeout = DefaultRetryTimeout
+	}
+
+	return &Signer{
+		identity: identity,
+		creator:  creator,
+		client:   client,
+		config:   config,
+		inflight: make(chan struct{}, config.MaxInflight),
+	}, nil
+}
+
+// NewSignatureHeader creates a SignatureHeader with the identity of the orderer and a valid nonce.
+func (s *Signer) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	nonce, err := crypto.GetRandomNonce()
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed creating nonce")
+	}
+
+	return &cb.SignatureHeader{
+		Creator: s.creator,
+		Nonce:   nonce,
+	}, nil
+}
+
+// Sign has the message signed by the signing service. Attempts which fail
+// with an error the signing service may recover from, such as it being
+// unavailable or not responding in time, are retried for up to RetryTimeout.
+// The caller, e.g. the writer of the blocks of a channel, is held up
+// meanwhile, as a block cannot be written without its signatures, but
+// other messages are signed while this one waits to be retried.
+// An error is returned if the signing service rejects the request, returns
+// a signature which does not check out, or does not recover in time.
+func (s *Signer) Sign(message []byte) ([]byte, error) {
+	deadline := time.Now().Add(s.config.RetryTimeout)
+	interval := initialRetryInterval
+	for attempt := 1; ; attempt++ {
+		signature, err := s.signInflight(message)
+		if err == nil {
+			return signature, nil
+		}
+		if !retryable(err) {
+			return nil, err
+		}
+
+		if interval > s.config.MaxRetryInterval {
+			interval = s.config.MaxRetryInterval
+		}
+		if time.Now().Add(interval).After(deadline) {
+			return nil, errors.WithMessage(err, fmt.Sprintf("gave up signing message after %d attempts in %s", attempt, s.config.RetryTimeout))
+		}
+		logger.Warnf("Attempt %d to sign message failed, retrying in %s: %s", attempt, interval, err)
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// signInflight makes an attempt to sign the message, once fewer than MaxInflight messages are being signed.
+func (s *Signer) signInflight(message []byte) ([]byte, error) {
+	s.inflight <- struct{}{}
+	defer func() { <-s.inflight }()
+	return s.sign(message)
+}
+
+func (s *Signer) sign(message []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	resp, err := s.client.Sign(ctx, &ab.SignRequest{
+		Creator: s.creator,
+		Message: message,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "signing service failed")
+	}
+
+	if err := s.identity.Verify(message, resp.Signature); err != nil {
+		return nil, errors.WithMessage(err, "signing service returned an invalid signature")
+	}
+	return resp.Signature, nil
+}
+
+// retryable returns whether the signing service may recover from the given error.
+func retryable(err error) bool {
+	switch status.Code(errors.Cause(err)) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
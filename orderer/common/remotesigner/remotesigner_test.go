@@ -0,0 +1,196 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type identity struct{}
+
+func (identity) Serialize() ([]byte, error) {
+	return []byte("orderer"), nil
+}
+
+func (identity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(sig, append([]byte("signed:"), msg...)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+type signerClient struct {
+	sign func(*ab.SignRequest) (*ab.SignResponse, error)
+}
+
+func (c *signerClient) Sign(ctx context.Context, req *ab.SignRequest, _ ...grpc.CallOption) (*ab.SignResponse, error) {
+	return c.sign(req)
+}
+
+func sign(req *ab.SignRequest) (*ab.SignResponse, error) {
+	return &ab.SignResponse{Signature: append([]byte("signed:"), req.Message...)}, nil
+}
+
+func TestSigner(t *testing.T) {
+	var attempts int32
+	client := &signerClient{}
+	s, err := NewSigner(identity{}, client, Config{MaxRetryInterval: time.Millisecond})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTimeout, s.config.Timeout)
+	assert.Equal(t, DefaultMaxInflight, s.config.MaxInflight)
+	assert.Equal(t, DefaultRetryTimeout, s.config.RetryTimeout)
+
+	sh, err := s.NewSignatureHeader()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("orderer"), sh.Creator)
+	assert.NotEmpty(t, sh.Nonce)
+
+	t.Run("Signed", func(t *testing.T) {
+		client.sign = func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			assert.Equal(t, []byte("orderer"), req.Creator)
+			return sign(req)
+		}
+		sig, err := s.Sign([]byte("block"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("signed:block"), sig)
+	})
+
+	t.Run("Retried", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		client.sign = func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			switch atomic.AddInt32(&attempts, 1) {
+			case 1:
+				return nil, status.Error(codes.Unavailable, "connection refused")
+			case 2:
+				return nil, status.Error(codes.DeadlineExceeded, "timed out")
+			default:
+				return sign(req)
+			}
+		}
+		sig, err := s.Sign([]byte("block"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte("signed:block"), sig)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("GaveUp", func(t *testing.T) {
+		s, err := NewSigner(identity{}, client, Config{MaxRetryInterval: time.Millisecond, RetryTimeout: 50 * time.Millisecond})
+		require.NoError(t, err)
+		atomic.StoreInt32(&attempts, 0)
+		client.sign = func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		}
+		_, err = s.Sign([]byte("block"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "attempts in 50ms: signing service failed: rpc error: code = Unavailable desc = connection refused")
+		assert.True(t, atomic.LoadInt32(&attempts) > 1)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		client.sign = func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			atomic.AddInt32(&attempts, 1)
+			return nil, status.Error(codes.PermissionDenied, "unknown key")
+		}
+		_, err := s.Sign([]byte("block"))
+		assert.EqualError(t, err, "signing service failed: rpc error: code = PermissionDenied desc = unknown key")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	})
+
+	t.Run("InvalidSignature", func(t *testing.T) {
+		client.sign = func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			return &ab.SignResponse{Signature: []byte("forged")}, nil
+		}
+		_, err := s.Sign([]byte("block"))
+		assert.EqualError(t, err, "signing service returned an invalid signature: signature mismatch")
+	})
+}
+
+func TestSignerMaxInflight(t *testing.T) {
+	var inflight, maxInflight int32
+	release := make(chan struct{})
+	client := &signerClient{
+		sign: func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			n := atomic.AddInt32(&inflight, 1)
+			defer atomic.AddInt32(&inflight, -1)
+			for {
+				max := atomic.LoadInt32(&maxInflight)
+				if n <= max || atomic.CompareAndSwapInt32(&maxInflight, max, n) {
+					break
+				}
+			}
+			<-release
+			return sign(req)
+		},
+	}
+	s, err := NewSigner(identity{}, client, Config{MaxInflight: 2})
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.Sign([]byte("block"))
+			assert.NoError(t, err)
+		}()
+	}
+
+	// the first two messages are signed concurrently, and the rest wait for them
+	for atomic.LoadInt32(&inflight) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxInflight))
+}
+
+func TestSignerRetryReleasesInflight(t *testing.T) {
+	unavailable := make(chan struct{})
+	client := &signerClient{
+		sign: func(req *ab.SignRequest) (*ab.SignResponse, error) {
+			if bytes.Equal(req.Message, []byte("retried")) {
+				select {
+				case <-unavailable:
+					return sign(req)
+				default:
+					return nil, status.Error(codes.Unavailable, "connection refused")
+				}
+			}
+			return sign(req)
+		},
+	}
+	s, err := NewSigner(identity{}, client, Config{MaxInflight: 1, MaxRetryInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+
+	retried := make(chan error, 1)
+	go func() {
+		_, err := s.Sign([]byte("retried"))
+		retried <- err
+	}()
+
+	// another message is signed while the first one waits to be retried
+	_, err = s.Sign([]byte("block"))
+	assert.NoError(t, err)
+	close(unavailable)
+	assert.NoError(t, <-retried)
+}
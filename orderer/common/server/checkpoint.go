@@ -0,0 +1,336 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// Checkpoint is a trusted starting point of a channel, from which a new consenter
+// joins the channel without replicating its blocks: the last config block of the
+// channel, which adds the consenter, along with the height of the ledger and the
+// hash of the last block at that point, signed by a quorum of the consenters of the
+// channel. Checkpoints are taken at config blocks, hence the last block is the
+// config block.
+type Checkpoint struct {
+	ChannelID   string                `json:"channel_id"`
+	Height      uint64                `json:"height"`
+	Hash        []byte                `json:"hash"`
+	ConfigBlock []byte                `json:"config_block"`
+	Signatures  []CheckpointSignature `json:"signatures"`
+}
+
+// CheckpointSignature is a signature of a checkpoint by a consenter,
+// made with the private key of its cluster TLS certificate.
+type CheckpointSignature struct {
+	Signer    []byte `json:"signer"` // PEM encoded TLS certificate
+	Signature []byte `json:"signature"`
+}
+
+// NewCheckpoint creates an unsigned checkpoint at the given config block of a channel.
+func NewCheckpoint(configBlock *common.Block) (*Checkpoint, error) {
+	channelID, err := utils.GetChainIDFromBlock(configBlock)
+	if err != nil {
+		return nil, err
+	}
+	return &Checkpoint{
+		ChannelID:   channelID,
+		Height:      configBlock.Header.Number + 1,
+		Hash:        configBlock.Header.Hash(),
+		ConfigBlock: utils.MarshalOrPanic(configBlock),
+	}, nil
+}
+
+// signedBytes returns the bytes signed by the consenters signing the checkpoint.
+func (cp *Checkpoint) signedBytes() []byte {
+	return []byte(fmt.Sprintf("checkpoint:%s:%d:%x", cp.ChannelID, cp.Height, cp.Hash))
+}
+
+// Sign adds the signature of the consenter with the given PEM encoded TLS certificate
+// and private key to the checkpoint, replacing its previous signature if it signed the
+// checkpoint already.
+func (cp *Checkpoint) Sign(cert, key []byte) error {
+	keyPair, err := tls.X509KeyPair(cert, key)
+	if err != nil {
+		return errors.Wrap(err, "failed loading TLS key pair")
+	}
+	signer, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return errors.Errorf("TLS private key of type %T cannot sign", keyPair.PrivateKey)
+	}
+	digest := sha256.Sum256(cp.signedBytes())
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return errors.Wrap(err, "failed signing checkpoint")
+	}
+
+	for i, sig := range cp.Signatures {
+		if bytes.Equal(sig.Signer, cert) {
+			cp.Signatures[i].Signature = signature
+			return nil
+		}
+	}
+	cp.Signatures = append(cp.Signatures, CheckpointSignature{Signer: cert, Signature: signature})
+	return nil
+}
+
+// Encode encodes the checkpoint in the format parsed by ParseCheckpoint.
+func (cp *Checkpoint) Encode() (string, error) {
+	raw, err := json.Marshal(cp)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
+
+// ParseCheckpoint decodes a checkpoint, and returns it along with the
+// config block it carries. The signatures of the checkpoint are not verified.
+func ParseCheckpoint(checkpoint string) (*Checkpoint, *common.Block, error) {
+	raw, err := base64.URLEncoding.DecodeString(strings.TrimSpace(checkpoint))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "checkpoint is not base64 encoded")
+	}
+	cp := &Checkpoint{}
+	if err := json.Unmarshal(raw, cp); err != nil {
+		return nil, nil, errors.Wrap(err, "malformed checkpoint")
+	}
+
+	block, err := parseConfigBlock(cp.ConfigBlock, cp.ChannelID, "checkpoint")
+	if err != nil {
+		return nil, nil, err
+	}
+	if cp.Height != block.Header.Number+1 || !bytes.Equal(cp.Hash, block.Header.Hash()) {
+		return nil, nil, errors.Errorf("checkpoint at height %d is not taken at its config block %d", cp.Height, block.Header.Number)
+	}
+	return cp, block, nil
+}
+
+// VerifyCheckpoint verifies that the checkpoint taken at the given config block is signed
+// by a quorum of the consenters of the channel, which is a majority of the consenters in
+// the config block. Each signature must be made by a consenter, with the key of the TLS
+// certificate of the consenter in the config block. Since whoever makes the config block
+// chooses its consenters, their certificates must also be issued by the given root CAs,
+// which this node trusts regardless of the config block.
+func VerifyCheckpoint(cp *Checkpoint, configBlock *common.Block, rootCAs [][]byte) error {
+	if len(rootCAs) == 0 {
+		return errors.New("no TLS root CAs to verify the signatures of the checkpoint with")
+	}
+	roots := x509.NewCertPool()
+	for _, rootCA := range rootCAs {
+		if !roots.AppendCertsFromPEM(rootCA) {
+			return errors.New("invalid TLS root CA")
+		}
+	}
+
+	env, err := utils.ExtractEnvelope(configBlock, 0)
+	if err != nil {
+		return errors.Wrapf(err, "failed extracting envelope of config block %d", configBlock.Header.Number)
+	}
+	bundle, err := channelconfig.NewBundleFromEnvelope(env)
+	if err != nil {
+		return errors.Wrapf(err, "failed creating bundle of config block %d", configBlock.Header.Number)
+	}
+	ordererConfig, exists := bundle.OrdererConfig()
+	if !exists {
+		return errors.Errorf("config block %d has no orderer config", configBlock.Header.Number)
+	}
+	metadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(ordererConfig.ConsensusMetadata(), metadata); err != nil {
+		return errors.Wrapf(err, "failed reading consenters of config block %d", configBlock.Header.Number)
+	}
+	quorum := len(metadata.Consenters)/2 + 1
+
+	signed := cp.signedBytes()
+	signers := make(map[int]struct{})
+	for i, sig := range cp.Signatures {
+		cert, err := parseCertificate(sig.Signer)
+		if err != nil {
+			return errors.Wrapf(err, "signature %d of checkpoint has an invalid signer", i)
+		}
+		consenter := consenterOf(metadata.Consenters, cert)
+		if consenter < 0 {
+			return errors.Errorf("signature %d of checkpoint is made by %s, which is not a consenter of channel %s",
+				i, cert.Subject, cp.ChannelID)
+		}
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+			return errors.Wrapf(err, "signature %d of checkpoint has an untrusted signer", i)
+		}
+		if err := verifySignature(cert, signed, sig.Signature); err != nil {
+			return errors.Wrapf(err, "signature %d of checkpoint is invalid", i)
+		}
+		signers[consenter] = struct{}{}
+	}
+
+	if len(signers) < quorum {
+		return errors.Errorf("checkpoint is signed by %d consenters, but a quorum of %d of the %d consenters of channel %s is required",
+			len(signers), quorum, len(metadata.Consenters), cp.ChannelID)
+	}
+	return nil
+}
+
+// parseCertificate parses a PEM encoded certificate.
+func parseCertificate(raw []byte) (*x509.Certificate, error) {
+	bl, _ := pem.Decode(raw)
+	if bl == nil {
+		return nil, errors.New("certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(bl.Bytes)
+}
+
+// consenterOf returns the index of the consenter with the given TLS certificate, or -1.
+func consenterOf(consenters []*etcdraft.Consenter, cert *x509.Certificate) int {
+	for i, consenter := range consenters {
+		for _, tlsCert := range [][]byte{consenter.ClientTlsCert, consenter.ServerTlsCert} {
+			if c, err := parseCertificate(tlsCert); err == nil && c.Equal(cert) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// verifySignature verifies a signature made by Checkpoint.Sign with the key of the given certificate.
+func verifySignature(cert *x509.Certificate, signed, signature []byte) error {
+	switch cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		return cert.CheckSignature(x509.ECDSAWithSHA256, signed, signature)
+	case *rsa.PublicKey:
+		return cert.CheckSignature(x509.SHA256WithRSA, signed, signature)
+	default:
+		return errors.Errorf("unsupported public key of type %T", cert.PublicKey)
+	}
+}
+
+// checkpointHandler issues checkpoints at the last config block of the channel
+// given in the query of GET requests of the form ?channel=<channel ID>, signed
+// by this orderer, and co-signs the checkpoints in the body of POST requests,
+// if they are taken at the last config block of the channel. Checkpoints are
+// signed with the cluster TLS key pair of this orderer.
+type checkpointHandler struct {
+	// lastConfigBlock returns the last config block of the channel, or nil if it does not exist.
+	lastConfigBlock func(channel string) *common.Block
+	cert            []byte
+	key             []byte
+}
+
+func (h *checkpointHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	var cp *Checkpoint
+	switch req.Method {
+	case http.MethodGet:
+		channel := req.URL.Query().Get("channel")
+		if channel == "" {
+			sendJSONError(resp, http.StatusBadRequest, "missing channel")
+			return
+		}
+		configBlock := h.lastConfigBlock(channel)
+		if configBlock == nil {
+			sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s does not exist", channel))
+			return
+		}
+		var err error
+		if cp, err = NewCheckpoint(configBlock); err != nil {
+			sendJSONError(resp, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+	case http.MethodPost:
+		raw, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading checkpoint: %s", err))
+			return
+		}
+		var block *common.Block
+		if cp, block, err = ParseCheckpoint(string(raw)); err != nil {
+			sendJSONError(resp, http.StatusBadRequest, err.Error())
+			return
+		}
+		configBlock := h.lastConfigBlock(cp.ChannelID)
+		if configBlock == nil {
+			sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s does not exist", cp.ChannelID))
+			return
+		}
+		if !bytes.Equal(configBlock.Header.Hash(), cp.Hash) {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("checkpoint is taken at block %d, but the last config block of channel %s is block %d",
+				block.Header.Number, cp.ChannelID, configBlock.Header.Number))
+			return
+		}
+
+	default:
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	if err := cp.Sign(h.cert, h.key); err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	checkpoint, err := cp.Encode()
+	if err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sendJSON(resp, http.StatusOK, map[string]string{"checkpoint": checkpoint})
+}
+
+// seedJoinedChannel starts the ledger of the given channel at the given config block,
+// which is trusted since it is taken from a checkpoint. The blocks preceding the config
+// block are not replicated, and the chain catches up with the blocks following it through
+// consensus. It returns the height of the ledger of the channel.
+func (ri *replicationInitiator) seedJoinedChannel(channel string, configBlock *common.Block) (uint64, error) {
+	ledger, err := ri.lf.GetOrCreate(channel)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed creating ledger of channel %s", channel)
+	}
+	height := ledger.Height()
+	if height > configBlock.Header.Number {
+		ri.logger.Infof("Ledger of channel %s is at height %d, there is nothing to seed up to config block %d",
+			channel, height, configBlock.Header.Number)
+		return height, nil
+	}
+	if height == configBlock.Header.Number {
+		if err := ledger.Append(configBlock); err != nil {
+			return height, errors.Wrapf(err, "failed appending config block %d", configBlock.Header.Number)
+		}
+		return height + 1, nil
+	}
+	if height > 0 {
+		return height, errors.Errorf("ledger of channel %s is at height %d, and cannot be seeded with config block %d",
+			channel, height, configBlock.Header.Number)
+	}
+
+	bootstrapper, ok := ledger.(blockledger.Bootstrapper)
+	if !ok {
+		return 0, errors.Errorf("ledger of channel %s cannot be seeded with a config block", channel)
+	}
+	if err := bootstrapper.BootstrapFromBlock(configBlock); err != nil {
+		return 0, errors.Wrapf(err, "failed seeding ledger of channel %s with config block %d", channel, configBlock.Header.Number)
+	}
+	ri.logger.Infof("Seeded ledger of channel %s with config block %d", channel, configBlock.Header.Number)
+	return ledger.Height(), nil
+}
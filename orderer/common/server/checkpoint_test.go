@@ -0,0 +1,356 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/encoder"
+	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
+	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/orderer/common/cluster/mocks"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// checkpointConfigBlock returns the genesis block of an etcdraft channel with the given
+// number of consenters, along with the CA which issued their TLS certificates and the
+// client TLS key pairs of the consenters.
+func checkpointConfigBlock(t *testing.T, consenters int) (*common.Block, tlsgen.CA, []*tlsgen.CertKeyPair) {
+	configDir, err := configtest.GetDevConfigDir()
+	require.NoError(t, err)
+	profile := genesisconfig.Load(genesisconfig.SampleDevModeEtcdRaftProfile, configDir)
+
+	// Consenters may not share TLS certificates, hence each is issued its own
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	certDir, err := ioutil.TempDir("", "checkpoint")
+	require.NoError(t, err)
+	defer os.RemoveAll(certDir)
+	certFile := func(name string, keyPair *tlsgen.CertKeyPair) []byte {
+		path := filepath.Join(certDir, name)
+		require.NoError(t, ioutil.WriteFile(path, keyPair.Cert, 0600))
+		return []byte(path)
+	}
+
+	var clientKeyPairs []*tlsgen.CertKeyPair
+	profile.Orderer.EtcdRaft.Consenters = nil
+	for i := 0; i < consenters; i++ {
+		clientKeyPair, err := ca.NewClientCertKeyPair()
+		require.NoError(t, err)
+		serverKeyPair, err := ca.NewServerCertKeyPair("localhost")
+		require.NoError(t, err)
+		clientKeyPairs = append(clientKeyPairs, clientKeyPair)
+		profile.Orderer.EtcdRaft.Consenters = append(profile.Orderer.EtcdRaft.Consenters, &etcdraft.Consenter{
+			Host:          "localhost",
+			Port:          uint32(7050 + i),
+			ClientTlsCert: certFile(fmt.Sprintf("client%d.crt", i), clientKeyPair),
+			ServerTlsCert: certFile(fmt.Sprintf("server%d.crt", i), serverKeyPair),
+		})
+	}
+	return encoder.New(profile).GenesisBlockForChannel("mychannel"), ca, clientKeyPairs
+}
+
+func TestCheckpoint(t *testing.T) {
+	block, ca, keyPairs := checkpointConfigBlock(t, 1)
+	rootCAs := [][]byte{ca.CertBytes()}
+
+	cp, err := NewCheckpoint(block)
+	require.NoError(t, err)
+	assert.Equal(t, "mychannel", cp.ChannelID)
+	assert.Equal(t, uint64(1), cp.Height)
+	assert.Equal(t, block.Header.Hash(), cp.Hash)
+
+	err = VerifyCheckpoint(cp, block, rootCAs)
+	assert.EqualError(t, err, "checkpoint is signed by 0 consenters, but a quorum of 1 of the 1 consenters of channel mychannel is required")
+
+	// signing again replaces the signature
+	require.NoError(t, cp.Sign(keyPairs[0].Cert, keyPairs[0].Key))
+	require.NoError(t, cp.Sign(keyPairs[0].Cert, keyPairs[0].Key))
+	assert.Len(t, cp.Signatures, 1)
+
+	encoded, err := cp.Encode()
+	require.NoError(t, err)
+	parsed, parsedBlock, err := ParseCheckpoint(encoded + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, cp, parsed)
+	assert.True(t, proto.Equal(block, parsedBlock))
+	assert.NoError(t, VerifyCheckpoint(parsed, parsedBlock, rootCAs))
+
+	t.Run("no trusted root CAs", func(t *testing.T) {
+		err := VerifyCheckpoint(cp, block, nil)
+		assert.EqualError(t, err, "no TLS root CAs to verify the signatures of the checkpoint with")
+	})
+
+	t.Run("no quorum", func(t *testing.T) {
+		block, ca, keyPairs := checkpointConfigBlock(t, 3)
+		cp, err := NewCheckpoint(block)
+		require.NoError(t, err)
+		require.NoError(t, cp.Sign(keyPairs[0].Cert, keyPairs[0].Key))
+		require.NoError(t, cp.Sign(keyPairs[0].Cert, keyPairs[0].Key))
+		err = VerifyCheckpoint(cp, block, [][]byte{ca.CertBytes()})
+		assert.EqualError(t, err, "checkpoint is signed by 1 consenters, but a quorum of 2 of the 3 consenters of channel mychannel is required")
+
+		require.NoError(t, cp.Sign(keyPairs[2].Cert, keyPairs[2].Key))
+		assert.NoError(t, VerifyCheckpoint(cp, block, [][]byte{ca.CertBytes()}))
+	})
+
+	t.Run("not a consenter", func(t *testing.T) {
+		// an identity issued by the same CA, which is not a consenter of the channel
+		keyPair, err := ca.NewClientCertKeyPair()
+		require.NoError(t, err)
+		cp, err := NewCheckpoint(block)
+		require.NoError(t, err)
+		require.NoError(t, cp.Sign(keyPair.Cert, keyPair.Key))
+		err = VerifyCheckpoint(cp, block, rootCAs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signature 0 of checkpoint is made by")
+		assert.Contains(t, err.Error(), "which is not a consenter of channel mychannel")
+	})
+
+	t.Run("consenters issued by an untrusted CA", func(t *testing.T) {
+		// whoever makes the config block chooses its consenters, but not the CAs this node trusts
+		otherCA, err := tlsgen.NewCA()
+		require.NoError(t, err)
+		err = VerifyCheckpoint(cp, block, [][]byte{otherCA.CertBytes()})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signature 0 of checkpoint has an untrusted signer")
+	})
+
+	t.Run("invalid signature", func(t *testing.T) {
+		forged := *cp
+		forged.Signatures = []CheckpointSignature{{Signer: cp.Signatures[0].Signer, Signature: []byte{1, 2, 3}}}
+		err := VerifyCheckpoint(&forged, block, rootCAs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signature 0 of checkpoint is invalid")
+
+		forged.Signatures = []CheckpointSignature{{Signer: []byte{1, 2, 3}, Signature: cp.Signatures[0].Signature}}
+		err = VerifyCheckpoint(&forged, block, rootCAs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "signature 0 of checkpoint has an invalid signer")
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		encode := func(cp *Checkpoint) string {
+			raw, err := json.Marshal(cp)
+			require.NoError(t, err)
+			return base64.URLEncoding.EncodeToString(raw)
+		}
+		otherHeight := *cp
+		otherHeight.Height = 5
+
+		for _, testCase := range []struct {
+			name          string
+			checkpoint    string
+			expectedError string
+		}{
+			{
+				name:          "not base64",
+				checkpoint:    "%%%",
+				expectedError: "checkpoint is not base64 encoded",
+			},
+			{
+				name:          "not JSON",
+				checkpoint:    base64.URLEncoding.EncodeToString([]byte("{")),
+				expectedError: "malformed checkpoint",
+			},
+			{
+				name:          "another channel",
+				checkpoint:    encode(&Checkpoint{ChannelID: "foo", ConfigBlock: cp.ConfigBlock}),
+				expectedError: "checkpoint is for channel foo, but its config block is of channel mychannel",
+			},
+			{
+				name:          "not at its config block",
+				checkpoint:    encode(&otherHeight),
+				expectedError: "checkpoint at height 5 is not taken at its config block 0",
+			},
+		} {
+			t.Run(testCase.name, func(t *testing.T) {
+				_, _, err := ParseCheckpoint(testCase.checkpoint)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.expectedError)
+			})
+		}
+	})
+}
+
+func TestCheckpointHandler(t *testing.T) {
+	block, ca, keyPairs := checkpointConfigBlock(t, 1)
+	h := &checkpointHandler{
+		lastConfigBlock: func(channel string) *common.Block {
+			if channel != "mychannel" {
+				return nil
+			}
+			return block
+		},
+		cert: keyPairs[0].Cert,
+		key:  keyPairs[0].Key,
+	}
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		h.ServeHTTP(resp, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return resp
+	}
+	checkpointOf := func(resp *httptest.ResponseRecorder) *Checkpoint {
+		body := map[string]string{}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		cp, _, err := ParseCheckpoint(body["checkpoint"])
+		require.NoError(t, err)
+		return cp
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/checkpoint?channel=mychannel", "")
+	require.Equal(t, http.StatusOK, resp.Code)
+	cp := checkpointOf(resp)
+	assert.Len(t, cp.Signatures, 1)
+	assert.NoError(t, VerifyCheckpoint(cp, block, [][]byte{ca.CertBytes()}))
+
+	// co-signing by the same orderer keeps a single signature
+	encoded, err := cp.Encode()
+	require.NoError(t, err)
+	resp = serve(http.MethodPost, "/etcdraft/checkpoint", encoded)
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, checkpointOf(resp).Signatures, 1)
+
+	// the checkpoint is not taken at the last config block
+	lastConfigBlock := proto.Clone(block).(*common.Block)
+	lastConfigBlock.Header.Number = 3
+	h.lastConfigBlock = func(string) *common.Block { return lastConfigBlock }
+	resp = serve(http.MethodPost, "/etcdraft/checkpoint", encoded)
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Contains(t, resp.Body.String(), "checkpoint is taken at block 0, but the last config block of channel mychannel is block 3")
+
+	h.lastConfigBlock = func(string) *common.Block { return nil }
+	resp = serve(http.MethodPost, "/etcdraft/checkpoint", encoded)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = serve(http.MethodGet, "/etcdraft/checkpoint?channel=foo", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	resp = serve(http.MethodGet, "/etcdraft/checkpoint", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodPost, "/etcdraft/checkpoint", "garbage")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodDelete, "/etcdraft/checkpoint", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
+
+type bootstrappingLedger struct {
+	*mocks.LedgerWriter
+	bootstrapped *common.Block
+}
+
+func (l *bootstrappingLedger) BootstrapFromBlock(block *common.Block) error {
+	l.bootstrapped = block
+	return nil
+}
+
+func TestSeedJoinedChannel(t *testing.T) {
+	block := joinConfigBlock(t)
+	block.Header.Number = 5
+
+	ledger := &mocks.LedgerWriter{}
+	lf := &mocks.LedgerFactory{}
+	lf.On("GetOrCreate", "mychannel").Return(ledger, nil)
+	ri := &replicationInitiator{logger: flogging.MustGetLogger("test"), lf: lf}
+
+	// the ledger is already past the config block
+	ledger.On("Height").Return(uint64(7)).Once()
+	height, err := ri.seedJoinedChannel("mychannel", block)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), height)
+	ledger.AssertNotCalled(t, "Append", mock.Anything)
+
+	// the config block is the next block of the ledger
+	ledger.On("Height").Return(uint64(5)).Once()
+	ledger.On("Append", block).Return(nil).Once()
+	height, err = ri.seedJoinedChannel("mychannel", block)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), height)
+
+	// the ledger has blocks preceding the config block
+	ledger.On("Height").Return(uint64(2)).Once()
+	_, err = ri.seedJoinedChannel("mychannel", block)
+	assert.EqualError(t, err, "ledger of channel mychannel is at height 2, and cannot be seeded with config block 5")
+
+	ledger.On("Height").Return(uint64(0)).Once()
+	_, err = ri.seedJoinedChannel("mychannel", block)
+	assert.EqualError(t, err, "ledger of channel mychannel cannot be seeded with a config block")
+
+	bootstrapping := &bootstrappingLedger{LedgerWriter: &mocks.LedgerWriter{}}
+	bootstrapping.On("Height").Return(uint64(0)).Once()
+	bootstrapping.On("Height").Return(uint64(6)).Once()
+	lf = &mocks.LedgerFactory{}
+	lf.On("GetOrCreate", "mychannel").Return(bootstrapping, nil)
+	ri.lf = lf
+	height, err = ri.seedJoinedChannel("mychannel", block)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), height)
+	assert.Equal(t, block, bootstrapping.bootstrapped)
+
+	lf = &mocks.LedgerFactory{}
+	lf.On("GetOrCreate", "mychannel").Return(nil, errors.New("no space left on device"))
+	ri.lf = lf
+	_, err = ri.seedJoinedChannel("mychannel", block)
+	assert.EqualError(t, err, "failed creating ledger of channel mychannel: no space left on device")
+}
+
+func TestChannelJoinerFromCheckpoint(t *testing.T) {
+	block, ca, keyPairs := checkpointConfigBlock(t, 1)
+	cp, err := NewCheckpoint(block)
+	require.NoError(t, err)
+
+	var seeded []uint64
+	cj := &channelJoiner{
+		logger:      flogging.MustGetLogger("test"),
+		registrar:   &fakeChainRegistrar{},
+		isConsenter: func(*common.Block) error { return nil },
+		seed: func(channel string, configBlock *common.Block) (uint64, error) {
+			assert.Equal(t, "mychannel", channel)
+			seeded = append(seeded, configBlock.Header.Number)
+			return configBlock.Header.Number + 1, nil
+		},
+		untrack:    func(string) {},
+		tlsRootCAs: [][]byte{ca.CertBytes()},
+		joins:      make(map[string]*JoinStatus),
+	}
+
+	unsigned, err := cp.Encode()
+	require.NoError(t, err)
+	_, err = cj.JoinFromCheckpoint(unsigned)
+	assert.EqualError(t, err, "checkpoint is signed by 0 consenters, but a quorum of 1 of the 1 consenters of channel mychannel is required")
+	assert.Empty(t, seeded)
+
+	require.NoError(t, cp.Sign(keyPairs[0].Cert, keyPairs[0].Key))
+	signed, err := cp.Encode()
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+	cj.serveJoinFromCheckpoint(resp, httptest.NewRequest(http.MethodPost, "/etcdraft/join/checkpoint", strings.NewReader(signed)))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	status := waitForJoin(t, cj, "mychannel", JoinReady)
+	assert.Equal(t, uint64(1), status.Height)
+	assert.Equal(t, []uint64{0}, seeded)
+
+	resp = httptest.NewRecorder()
+	cj.serveJoinFromCheckpoint(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/join/checkpoint", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	cb "github.com/hyperledger/fabric/protos/common"
+	raftprotos "github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// eventSubscription is a subscription to the consensus events of a chain.
+type eventSubscription interface {
+	Events() <-chan *raftprotos.ConsensusEvent
+	Err() error
+	Cancel()
+}
+
+// eventsChain is a chain which streams its consensus events.
+type eventsChain interface {
+	streamChain
+	SubscribeEvents() (eventSubscription, error)
+}
+
+// eventsSupport looks up the chains of channels for the events server.
+type eventsSupport interface {
+	// EventsChain returns the chain of the channel, or nil if there is no such channel.
+	// It returns an error if the chain of the channel does not stream its events.
+	EventsChain(channelID string) (eventsChain, error)
+}
+
+type eventsRegistrar struct {
+	*multichannel.Registrar
+}
+
+func (er eventsRegistrar) EventsChain(channelID string) (eventsChain, error) {
+	cs := er.Registrar.GetChain(channelID)
+	if cs == nil {
+		return nil, nil
+	}
+	raftChain, ok := cs.Chain.(*etcdraft.Chain)
+	if !ok {
+		return nil, etcdraft.ErrEventStreamDisabled
+	}
+	return &etcdraftEventsChain{ChainSupport: cs, chain: raftChain}, nil
+}
+
+type etcdraftEventsChain struct {
+	*multichannel.ChainSupport
+	chain *etcdraft.Chain
+}
+
+func (ec *etcdraftEventsChain) SubscribeEvents() (eventSubscription, error) {
+	sub, err := ec.chain.SubscribeEvents()
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+type eventsServer struct {
+	support    eventsSupport
+	timeWindow time.Duration
+}
+
+// NewConsensusEventsServer creates a ConsensusEventsServer streaming
+// the consensus events of the etcdraft chains of the registrar.
+func NewConsensusEventsServer(r *multichannel.Registrar, timeWindow time.Duration) raftprotos.ConsensusEventsServer {
+	return &eventsServer{
+		support:    eventsRegistrar{Registrar: r},
+		timeWindow: timeWindow,
+	}
+}
+
+// Events streams the consensus events of the channel of the envelope, as they
+// are observed, until the client cancels the stream. The envelope must be signed
+// by a reader of the channel, which is re-checked upon each event.
+func (es *eventsServer) Events(env *cb.Envelope, srv raftprotos.ConsensusEvents_EventsServer) error {
+	_, chdr, err := parseStreamEnvelope(env, es.timeWindow)
+	if err != nil {
+		return err
+	}
+
+	chain, err := es.support.EventsChain(chdr.ChannelId)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "channel %s: %s", chdr.ChannelId, err)
+	}
+	if chain == nil {
+		return status.Errorf(codes.NotFound, "channel %s not found", chdr.ChannelId)
+	}
+
+	accessControl, err := authorizeStream(chain, env, chdr.ChannelId, "events")
+	if err != nil {
+		return err
+	}
+
+	sub, err := chain.SubscribeEvents()
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "channel %s: %s", chdr.ChannelId, err)
+	}
+	defer sub.Cancel()
+
+	logger.Debugf("[channel: %s] Streaming consensus events", chdr.ChannelId)
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return eventsClosedError(chdr.ChannelId, sub.Err())
+			}
+			if err := accessControl.Evaluate(); err != nil {
+				return status.Errorf(codes.PermissionDenied, "events of channel %s: %s", chdr.ChannelId, err)
+			}
+			if err := srv.Send(event); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			logger.Debugf("[channel: %s] Events stream closed by client", chdr.ChannelId)
+			return nil
+		}
+	}
+}
+
+func eventsClosedError(channelID string, err error) error {
+	if err == nil {
+		err = errors.New("subscription cancelled")
+	}
+	code := codes.Unavailable
+	if err == etcdraft.ErrEventsOverflow {
+		code = codes.ResourceExhausted
+	}
+	return status.Errorf(code, "events of channel %s: %s", channelID, err)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	cb "github.com/hyperledger/fabric/protos/common"
+	raftprotos "github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockEventSubscription struct {
+	events    chan *raftprotos.ConsensusEvent
+	err       error
+	cancelled bool
+}
+
+func (m *mockEventSubscription) Events() <-chan *raftprotos.ConsensusEvent { return m.events }
+func (m *mockEventSubscription) Err() error                                { return m.err }
+func (m *mockEventSubscription) Cancel()                                   { m.cancelled = true }
+
+type mockEventsChain struct {
+	policyManager *mockpolicies.Manager
+	sub           *mockEventSubscription
+	subErr        error
+}
+
+func (m *mockEventsChain) Sequence() uint64                { return 0 }
+func (m *mockEventsChain) PolicyManager() policies.Manager { return m.policyManager }
+func (m *mockEventsChain) SubscribeEvents() (eventSubscription, error) {
+	if m.subErr != nil {
+		return nil, m.subErr
+	}
+	return m.sub, nil
+}
+
+type mockEventsSupport struct {
+	chain *mockEventsChain
+	err   error
+}
+
+func (m *mockEventsSupport) EventsChain(channelID string) (eventsChain, error) {
+	if m.err != nil || m.chain == nil {
+		return nil, m.err
+	}
+	return m.chain, nil
+}
+
+type mockEventsStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*raftprotos.ConsensusEvent
+}
+
+func (m *mockEventsStream) Context() context.Context { return m.ctx }
+func (m *mockEventsStream) Send(event *raftprotos.ConsensusEvent) error {
+	m.sent = append(m.sent, event)
+	return nil
+}
+
+func eventsEnvelope(t *testing.T) *cb.Envelope {
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, "mychannel", mockcrypto.FakeLocalSigner, &cb.Envelope{}, 0, 0)
+	require.NoError(t, err)
+	return env
+}
+
+func TestEvents(t *testing.T) {
+	events := make(chan *raftprotos.ConsensusEvent, 2)
+	events <- &raftprotos.ConsensusEvent{Type: "leader_change", Channel: "mychannel", Leader: 2}
+	events <- &raftprotos.ConsensusEvent{Type: "eviction", Channel: "mychannel"}
+	close(events)
+	sub := &mockEventSubscription{events: events, err: etcdraft.ErrEventsOverflow}
+	chain := &mockEventsChain{policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}, sub: sub}
+	es := &eventsServer{support: &mockEventsSupport{chain: chain}, timeWindow: time.Minute}
+
+	stream := &mockEventsStream{ctx: context.Background()}
+	err := es.Events(eventsEnvelope(t), stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []*raftprotos.ConsensusEvent{
+		{Type: "leader_change", Channel: "mychannel", Leader: 2},
+		{Type: "eviction", Channel: "mychannel"},
+	}, stream.sent)
+	assert.True(t, sub.cancelled)
+
+	// the stream ends without an error once the client goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sub = &mockEventSubscription{events: make(chan *raftprotos.ConsensusEvent)}
+	chain.sub = sub
+	assert.NoError(t, es.Events(eventsEnvelope(t), &mockEventsStream{ctx: ctx}))
+	assert.True(t, sub.cancelled)
+}
+
+func TestEventsRejected(t *testing.T) {
+	for _, testCase := range []struct {
+		name    string
+		env     *cb.Envelope
+		support *mockEventsSupport
+		code    codes.Code
+	}{
+		{
+			name: "malformed envelope",
+			env:  &cb.Envelope{Payload: []byte{1, 2, 3}},
+			code: codes.InvalidArgument,
+		},
+		{
+			name:    "unknown channel",
+			support: &mockEventsSupport{},
+			code:    codes.NotFound,
+		},
+		{
+			name:    "chain without events",
+			support: &mockEventsSupport{err: etcdraft.ErrEventStreamDisabled},
+			code:    codes.FailedPrecondition,
+		},
+		{
+			name: "not a reader",
+			support: &mockEventsSupport{chain: &mockEventsChain{
+				policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: errors.New("not a reader")}},
+			}},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "event stream disabled",
+			support: &mockEventsSupport{chain: &mockEventsChain{
+				policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+				subErr:        etcdraft.ErrEventStreamDisabled,
+			}},
+			code: codes.FailedPrecondition,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			env := testCase.env
+			if env == nil {
+				env = eventsEnvelope(t)
+			}
+			es := &eventsServer{support: testCase.support, timeWindow: time.Minute}
+			err := es.Events(env, &mockEventsStream{ctx: context.Background()})
+			assert.Equal(t, testCase.code, status.Code(err), "%v", err)
+		})
+	}
+}
@@ -0,0 +1,394 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus/inactive"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// JoinToken carries what a new consenter needs in order to join a channel:
+// the last config block of the channel, which adds the consenter and which the
+// replicated blocks must lead to, and optionally the endpoints to replicate the
+// channel from, instead of the orderer endpoints in the config block.
+type JoinToken struct {
+	ChannelID   string   `json:"channel_id"`
+	ConfigBlock []byte   `json:"config_block"`
+	Endpoints   []string `json:"endpoints,omitempty"`
+}
+
+// NewJoinToken creates an encoded join token out of the given
+// config block of a channel and the given endpoints.
+func NewJoinToken(configBlock *common.Block, endpoints ...string) (string, error) {
+	channelID, err := utils.GetChainIDFromBlock(configBlock)
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(&JoinToken{
+		ChannelID:   channelID,
+		ConfigBlock: utils.MarshalOrPanic(configBlock),
+		Endpoints:   endpoints,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(raw), nil
+}
+
+// ParseJoinToken decodes a join token, and returns it
+// along with the config block it carries.
+func ParseJoinToken(token string) (*JoinToken, *common.Block, error) {
+	raw, err := base64.URLEncoding.DecodeString(strings.TrimSpace(token))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "join token is not base64 encoded")
+	}
+	jt := &JoinToken{}
+	if err := json.Unmarshal(raw, jt); err != nil {
+		return nil, nil, errors.Wrap(err, "malformed join token")
+	}
+
+	block, err := parseConfigBlock(jt.ConfigBlock, jt.ChannelID, "join token")
+	if err != nil {
+		return nil, nil, err
+	}
+	return jt, block, nil
+}
+
+// parseConfigBlock parses the config block of the given channel
+// carried by a join token or a checkpoint, as given by carrier.
+func parseConfigBlock(raw []byte, channel string, carrier string) (*common.Block, error) {
+	block, err := utils.UnmarshalBlock(raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s does not carry a block", carrier)
+	}
+	if block.Header == nil || block.Data == nil || !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return nil, errors.Errorf("block of %s is malformed", carrier)
+	}
+	if !utils.IsConfigBlock(block) {
+		return nil, errors.Errorf("block %d of %s is not a config block", block.Header.Number, carrier)
+	}
+	channelID, err := utils.GetChainIDFromBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	if channelID != channel {
+		return nil, errors.Errorf("%s is for channel %s, but its config block is of channel %s", carrier, channel, channelID)
+	}
+	return block, nil
+}
+
+// Join states of a channel.
+const (
+	JoinReplicating = "replicating"
+	JoinReady       = "ready"
+	JoinFailed      = "failed"
+)
+
+// JoinStatus describes the progress of a join of a channel.
+type JoinStatus struct {
+	Channel string `json:"channel"`
+	State   string `json:"state"`
+	Height  uint64 `json:"height"`
+	Error   string `json:"error,omitempty"`
+}
+
+// chainRegistrar creates chains of channels with replicated ledgers.
+type chainRegistrar interface {
+	GetChain(chainID string) *multichannel.ChainSupport
+	CreateChain(chainName string)
+}
+
+// channelJoiner joins channels given join tokens: it replicates the ledger of
+// the channel up to the config block of the token, and then creates the chain,
+// which creates the WAL and snapshot directories and catches up with the rest
+// of the channel through consensus. Given checkpoints, it seeds the ledger of
+// the channel with the config block of the checkpoint instead.
+type channelJoiner struct {
+	logger    *flogging.FabricLogger
+	registrar chainRegistrar
+	// isConsenter returns an error if this node is not a consenter in the config block.
+	isConsenter func(configBlock *common.Block) error
+	// replicate pulls the blocks of the channel up to and including the config block.
+	replicate func(channel string, configBlock *common.Block, endpoints []string) (height uint64, err error)
+	// seed starts the ledger of the channel at the config block, without the blocks preceding it.
+	seed func(channel string, configBlock *common.Block) (height uint64, err error)
+	// untrack makes the inactive chain registry stop tracking the channel,
+	// so that it does not create the chain once again.
+	untrack func(channel string)
+	// tlsRootCAs issue the TLS certificates of the consenters which sign checkpoints.
+	tlsRootCAs [][]byte
+
+	lock  sync.Mutex
+	joins map[string]*JoinStatus
+}
+
+// Join starts joining the channel of the token, and returns once the join is started.
+// The channel must be one of the channels of the system channel, whose ledger this node
+// has the genesis block of, since the blocks replicated are verified starting with it.
+func (cj *channelJoiner) Join(token string) (JoinStatus, error) {
+	jt, block, err := ParseJoinToken(token)
+	if err != nil {
+		return JoinStatus{}, err
+	}
+	if cj.registrar.GetChain(jt.ChannelID) == nil {
+		return JoinStatus{}, errors.Errorf("channel %s is not a channel of the system channel", jt.ChannelID)
+	}
+	return cj.start(jt.ChannelID, block, func() (uint64, error) {
+		return cj.replicate(jt.ChannelID, block, jt.Endpoints)
+	})
+}
+
+// JoinFromCheckpoint starts joining the channel of the checkpoint, and returns once the
+// join is started. The checkpoint must be signed by a quorum of the consenters of the
+// channel, as its config block is trusted without replicating the blocks preceding it.
+func (cj *channelJoiner) JoinFromCheckpoint(checkpoint string) (JoinStatus, error) {
+	cp, block, err := ParseCheckpoint(checkpoint)
+	if err != nil {
+		return JoinStatus{}, err
+	}
+	if err := VerifyCheckpoint(cp, block, cj.tlsRootCAs); err != nil {
+		return JoinStatus{}, err
+	}
+	return cj.start(cp.ChannelID, block, func() (uint64, error) {
+		return cj.seed(cp.ChannelID, block)
+	})
+}
+
+// start starts joining the given channel by setting up its ledger with the given
+// function, and creating the chain once the ledger reaches the config block.
+func (cj *channelJoiner) start(channel string, block *common.Block, setUpLedger func() (uint64, error)) (JoinStatus, error) {
+	if err := cj.isConsenter(block); err != nil {
+		return JoinStatus{}, errors.Wrapf(err, "config block %d does not make this node a consenter of channel %s", block.Header.Number, channel)
+	}
+
+	cj.lock.Lock()
+	defer cj.lock.Unlock()
+
+	if status, exists := cj.joins[channel]; exists && status.State != JoinFailed {
+		return JoinStatus{}, errors.Errorf("channel %s is already being joined", channel)
+	}
+	if cs := cj.registrar.GetChain(channel); cs != nil {
+		if _, isInactive := cs.Chain.(*inactive.Chain); !isInactive {
+			return JoinStatus{}, errors.Errorf("channel %s is already serviced by this node", channel)
+		}
+	}
+
+	status := &JoinStatus{Channel: channel, State: JoinReplicating}
+	cj.joins[channel] = status
+	cj.logger.Infof("Joining channel %s with config block %d", channel, block.Header.Number)
+	go cj.join(channel, setUpLedger)
+	return *status, nil
+}
+
+func (cj *channelJoiner) join(channel string, setUpLedger func() (uint64, error)) {
+	height, err := setUpLedger()
+
+	cj.lock.Lock()
+	defer cj.lock.Unlock()
+
+	status := cj.joins[channel]
+	status.Height = height
+	if err != nil {
+		cj.logger.Errorf("Failed joining channel %s: %s", channel, err)
+		status.State = JoinFailed
+		status.Error = err.Error()
+		return
+	}
+
+	cj.untrack(channel)
+	cj.registrar.CreateChain(channel)
+	status.State = JoinReady
+	cj.logger.Infof("Joined channel %s, ledger is at height %d", channel, height)
+}
+
+// Status returns the status of the join of the given channel, if it was joined.
+func (cj *channelJoiner) Status(channel string) (JoinStatus, bool) {
+	cj.lock.Lock()
+	defer cj.lock.Unlock()
+
+	status, exists := cj.joins[channel]
+	if !exists {
+		return JoinStatus{}, false
+	}
+	return *status, true
+}
+
+// ServeHTTP joins the channel of the join token in the body of POST requests,
+// and serves the join status of the channel given in the query of GET requests
+// of the form ?channel=<channel ID>.
+func (cj *channelJoiner) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		token, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading join token: %s", err))
+			return
+		}
+		status, err := cj.Join(string(token))
+		if err != nil {
+			sendJSONError(resp, http.StatusBadRequest, err.Error())
+			return
+		}
+		sendJSON(resp, http.StatusAccepted, status)
+
+	case http.MethodGet:
+		channel := req.URL.Query().Get("channel")
+		if channel == "" {
+			sendJSONError(resp, http.StatusBadRequest, "missing channel")
+			return
+		}
+		status, exists := cj.Status(channel)
+		if !exists {
+			sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s was not joined", channel))
+			return
+		}
+		sendJSON(resp, http.StatusOK, status)
+
+	default:
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+	}
+}
+
+// serveJoinFromCheckpoint joins the channel of the checkpoint in the body of
+// POST requests. The status of the join is served like that of join tokens.
+func (cj *channelJoiner) serveJoinFromCheckpoint(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+	checkpoint, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading checkpoint: %s", err))
+		return
+	}
+	status, err := cj.JoinFromCheckpoint(string(checkpoint))
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, err.Error())
+		return
+	}
+	sendJSON(resp, http.StatusAccepted, status)
+}
+
+// replicateJoinedChannel replicates the blocks of the given channel up to and including
+// the given config block. The pulled blocks are verified with the config of the channel,
+// starting with that of the genesis block its ledger was created with out of the system
+// channel, and the block pulled at the config block must be the config block.
+// It returns the height of the ledger of the channel.
+func (ri *replicationInitiator) replicateJoinedChannel(channel string, configBlock *common.Block, endpoints []string) (uint64, error) {
+	ledger, err := ri.lf.GetOrCreate(channel)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed creating ledger of channel %s", channel)
+	}
+	height := ledger.Height()
+	if height > configBlock.Header.Number {
+		ri.logger.Infof("Ledger of channel %s is at height %d, there is nothing to replicate up to config block %d",
+			channel, height, configBlock.Header.Number)
+		return height, nil
+	}
+	if height == 0 {
+		return height, errors.Errorf("ledger of channel %s has no genesis block to verify the blocks of the channel with", channel)
+	}
+
+	ri.registerChain(channel)
+	pullerConfig := cluster.PullerConfigFromTopLevelConfig(channel, ri.conf, ri.secOpts.Key, ri.secOpts.Certificate, ri.signer)
+	puller, err := cluster.BlockPullerFromConfigBlock(pullerConfig, configBlock, ri.verifierRetriever)
+	if err != nil {
+		return height, errors.Wrap(err, "failed creating block puller")
+	}
+	defer puller.Close()
+	puller.MaxPullBlockRetries = uint64(ri.conf.General.Cluster.ReplicationMaxRetries)
+	puller.RetryTimeout = ri.conf.General.Cluster.ReplicationRetryTimeout
+	if len(endpoints) > 0 {
+		puller.Endpoints = endpoints
+	}
+
+	// The replicator panics if the block it stops at differs from the config block,
+	// so it is compared in advance. Its signatures are verified once it is replicated,
+	// since the config it is verified with may change up to it.
+	configPuller := puller.Clone()
+	defer configPuller.Close()
+	configPuller.VerifyBlockSequence = func(blocks []*common.Block, _ string) error {
+		return cluster.VerifyBlocks(blocks, &cluster.NoopBlockVerifier{})
+	}
+	block := configPuller.PullBlock(configBlock.Header.Number)
+	if block == nil {
+		return height, errors.Errorf("failed pulling block %d of channel %s", configBlock.Header.Number, channel)
+	}
+	if !bytes.Equal(block.Data.Hash(), configBlock.Header.DataHash) || !bytes.Equal(block.Header.Hash(), configBlock.Header.Hash()) {
+		return height, errors.Errorf("block %d of channel %s differs from the config block of the join token", block.Header.Number, channel)
+	}
+	configPuller.Close()
+
+	replicator := &cluster.Replicator{
+		Filter:        cluster.AnyChannel,
+		SystemChannel: channel,
+		BootBlock:     configBlock,
+		Logger:        ri.logger,
+		Puller:        puller,
+		LedgerFactory: ri.lf,
+	}
+	if err := replicator.PullChannel(channel); err != nil {
+		return ledger.Height(), errors.Wrapf(err, "failed replicating channel %s", channel)
+	}
+	return ledger.Height(), nil
+}
+
+// joinTokenHandler issues join tokens out of the last config
+// block of the channel given in the query of GET requests of
+// the form ?channel=<channel ID>&endpoint=<endpoint>..., for
+// the new consenters the config block adds.
+type joinTokenHandler struct {
+	registrar chainRegistrar
+}
+
+func (h *joinTokenHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+	channel := req.URL.Query().Get("channel")
+	if channel == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+	cs := h.registrar.GetChain(channel)
+	if cs == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s does not exist", channel))
+		return
+	}
+
+	token, err := NewJoinToken(multichannel.ConfigBlock(cs), req.URL.Query()["endpoint"]...)
+	if err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sendJSON(resp, http.StatusOK, map[string]string{"token": token})
+}
+
+func sendJSON(resp http.ResponseWriter, code int, v interface{}) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	json.NewEncoder(resp).Encode(v)
+}
+
+func sendJSONError(resp http.ResponseWriter, code int, msg string) {
+	sendJSON(resp, code, map[string]string{"error": msg})
+}
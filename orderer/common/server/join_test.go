@@ -0,0 +1,279 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster/mocks"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus/inactive"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func joinConfigBlock(t *testing.T) *common.Block {
+	blockBytes, err := ioutil.ReadFile(filepath.Join("testdata", "genesis.block"))
+	require.NoError(t, err)
+	block := &common.Block{}
+	require.NoError(t, proto.Unmarshal(blockBytes, block))
+	return block
+}
+
+func TestJoinToken(t *testing.T) {
+	block := joinConfigBlock(t)
+	channelID, err := utils.GetChainIDFromBlock(block)
+	require.NoError(t, err)
+
+	token, err := NewJoinToken(block, "orderer1:7050", "orderer2:7050")
+	require.NoError(t, err)
+	jt, parsed, err := ParseJoinToken(token + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, channelID, jt.ChannelID)
+	assert.Equal(t, []string{"orderer1:7050", "orderer2:7050"}, jt.Endpoints)
+	assert.True(t, proto.Equal(block, parsed))
+
+	encode := func(jt *JoinToken) string {
+		raw, err := json.Marshal(jt)
+		require.NoError(t, err)
+		return base64.URLEncoding.EncodeToString(raw)
+	}
+	tampered := joinConfigBlock(t)
+	tampered.Data.Data = append(tampered.Data.Data, []byte{1, 2, 3})
+	notConfig := &common.Block{Header: &common.BlockHeader{Number: 5}, Data: &common.BlockData{Data: [][]byte{{1, 2, 3}}}}
+	notConfig.Header.DataHash = notConfig.Data.Hash()
+
+	for _, testCase := range []struct {
+		name          string
+		token         string
+		expectedError string
+	}{
+		{
+			name:          "not base64",
+			token:         "%%%",
+			expectedError: "join token is not base64 encoded",
+		},
+		{
+			name:          "not JSON",
+			token:         base64.URLEncoding.EncodeToString([]byte("{")),
+			expectedError: "malformed join token",
+		},
+		{
+			name:          "tampered block",
+			token:         encode(&JoinToken{ChannelID: channelID, ConfigBlock: utils.MarshalOrPanic(tampered)}),
+			expectedError: "block of join token is malformed",
+		},
+		{
+			name:          "not a config block",
+			token:         encode(&JoinToken{ChannelID: channelID, ConfigBlock: utils.MarshalOrPanic(notConfig)}),
+			expectedError: "block 5 of join token is not a config block",
+		},
+		{
+			name:          "another channel",
+			token:         encode(&JoinToken{ChannelID: "foo", ConfigBlock: utils.MarshalOrPanic(block)}),
+			expectedError: "join token is for channel foo, but its config block is of channel " + channelID,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, _, err := ParseJoinToken(testCase.token)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
+
+type fakeChainRegistrar struct {
+	lock    sync.Mutex
+	chains  map[string]*multichannel.ChainSupport
+	created []string
+}
+
+func (r *fakeChainRegistrar) GetChain(chainID string) *multichannel.ChainSupport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.chains[chainID]
+}
+
+func (r *fakeChainRegistrar) CreateChain(chainName string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.created = append(r.created, chainName)
+}
+
+func (r *fakeChainRegistrar) createdChains() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.created...)
+}
+
+func waitForJoin(t *testing.T, cj *channelJoiner, channel, state string) JoinStatus {
+	deadline := time.Now().Add(time.Minute)
+	for time.Now().Before(deadline) {
+		if status, _ := cj.Status(channel); status.State == state {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("join of channel %s did not reach state %s", channel, state)
+	return JoinStatus{}
+}
+
+func TestChannelJoiner(t *testing.T) {
+	block := joinConfigBlock(t)
+	channelID, err := utils.GetChainIDFromBlock(block)
+	require.NoError(t, err)
+	token, err := NewJoinToken(block, "orderer1:7050")
+	require.NoError(t, err)
+
+	registrar := &fakeChainRegistrar{chains: map[string]*multichannel.ChainSupport{
+		channelID: {Chain: &inactive.Chain{}},
+	}}
+	replicated := make(chan error)
+	var untracked []string
+	cj := &channelJoiner{
+		logger:      flogging.MustGetLogger("test"),
+		registrar:   registrar,
+		isConsenter: func(*common.Block) error { return nil },
+		replicate: func(channel string, configBlock *common.Block, endpoints []string) (uint64, error) {
+			assert.Equal(t, channelID, channel)
+			assert.Equal(t, []string{"orderer1:7050"}, endpoints)
+			return configBlock.Header.Number + 1, <-replicated
+		},
+		untrack: func(channel string) { untracked = append(untracked, channel) },
+		joins:   make(map[string]*JoinStatus),
+	}
+
+	status, err := cj.Join(token)
+	require.NoError(t, err)
+	assert.Equal(t, JoinStatus{Channel: channelID, State: JoinReplicating}, status)
+
+	_, err = cj.Join(token)
+	assert.EqualError(t, err, "channel "+channelID+" is already being joined")
+
+	// a failed join can be retried
+	replicated <- errors.New("no endpoint is reachable")
+	status = waitForJoin(t, cj, channelID, JoinFailed)
+	assert.Equal(t, "no endpoint is reachable", status.Error)
+	assert.Empty(t, registrar.createdChains())
+
+	_, err = cj.Join(token)
+	require.NoError(t, err)
+	replicated <- nil
+	status = waitForJoin(t, cj, channelID, JoinReady)
+	assert.Equal(t, block.Header.Number+1, status.Height)
+	assert.Equal(t, []string{channelID}, registrar.createdChains())
+	assert.Equal(t, []string{channelID}, untracked)
+
+	// the channel is now serviced
+	registrar.chains[channelID] = &multichannel.ChainSupport{}
+	delete(cj.joins, channelID)
+	_, err = cj.Join(token)
+	assert.EqualError(t, err, "channel "+channelID+" is already serviced by this node")
+
+	cj.isConsenter = func(*common.Block) error { return errors.New("not a consenter") }
+	_, err = cj.Join(token)
+	assert.EqualError(t, err, "config block 0 does not make this node a consenter of channel "+channelID+": not a consenter")
+
+	// the system channel does not have the channel
+	delete(registrar.chains, channelID)
+	_, err = cj.Join(token)
+	assert.EqualError(t, err, "channel "+channelID+" is not a channel of the system channel")
+
+	_, exists := cj.Status("foo")
+	assert.False(t, exists)
+}
+
+func TestChannelJoinerHTTP(t *testing.T) {
+	block := joinConfigBlock(t)
+	channelID, err := utils.GetChainIDFromBlock(block)
+	require.NoError(t, err)
+	token, err := NewJoinToken(block)
+	require.NoError(t, err)
+
+	cj := &channelJoiner{
+		logger: flogging.MustGetLogger("test"),
+		registrar: &fakeChainRegistrar{chains: map[string]*multichannel.ChainSupport{
+			channelID: {Chain: &inactive.Chain{}},
+		}},
+		isConsenter: func(*common.Block) error { return nil },
+		replicate: func(string, *common.Block, []string) (uint64, error) {
+			return 1, nil
+		},
+		untrack: func(string) {},
+		joins:   make(map[string]*JoinStatus),
+	}
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		cj.ServeHTTP(resp, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return resp
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/join?channel="+channelID, "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodPost, "/etcdraft/join", "garbage")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+
+	resp = serve(http.MethodPost, "/etcdraft/join", token)
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	waitForJoin(t, cj, channelID, JoinReady)
+
+	resp = serve(http.MethodGet, "/etcdraft/join?channel="+channelID, "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	status := JoinStatus{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&status))
+	assert.Equal(t, JoinStatus{Channel: channelID, State: JoinReady, Height: 1}, status)
+
+	resp = serve(http.MethodGet, "/etcdraft/join", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodDelete, "/etcdraft/join", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+}
+
+func TestReplicateJoinedChannel(t *testing.T) {
+	block := joinConfigBlock(t)
+	block.Header.Number = 5
+
+	ledger := &mocks.LedgerWriter{}
+	lf := &mocks.LedgerFactory{}
+	lf.On("GetOrCreate", "mychannel").Return(ledger, nil)
+	ri := &replicationInitiator{logger: flogging.MustGetLogger("test"), lf: lf}
+
+	// the ledger is already past the config block
+	ledger.On("Height").Return(uint64(7)).Once()
+	height, err := ri.replicateJoinedChannel("mychannel", block, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), height)
+	ledger.AssertNotCalled(t, "Append", mock.Anything)
+
+	// the ledger has no genesis block to verify the replicated blocks with
+	ledger.On("Height").Return(uint64(0)).Once()
+	_, err = ri.replicateJoinedChannel("mychannel", block, nil)
+	assert.EqualError(t, err, "ledger of channel mychannel has no genesis block to verify the blocks of the channel with")
+
+	lf = &mocks.LedgerFactory{}
+	lf.On("GetOrCreate", "mychannel").Return(nil, errors.New("no space left on device"))
+	ri.lf = lf
+	_, err = ri.replicateJoinedChannel("mychannel", block, nil)
+	assert.EqualError(t, err, "failed creating ledger of channel mychannel: no space left on device")
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/hyperledger/fabric/common/tools/protolator"
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/middleware"
 	"github.com/hyperledger/fabric/core/operations"
 	"github.com/hyperledger/fabric/msp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
@@ -44,12 +45,14 @@ import (
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/common/metadata"
 	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/common/remotesigner"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
 	"github.com/hyperledger/fabric/orderer/consensus/kafka"
 	"github.com/hyperledger/fabric/orderer/consensus/solo"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	raftprotos "github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
@@ -99,7 +102,7 @@ func Start(cmd string, conf *localconfig.TopLevel) {
 	}
 
 	clusterType := isClusterType(bootstrapBlock)
-	signer := localmsp.NewSigner()
+	signer := initializeSigner(conf)
 
 	lf, _ := createLedgerFactory(conf)
 
@@ -155,13 +158,18 @@ func Start(cmd string, conf *localconfig.TopLevel) {
 		}
 	}
 
-	manager := initializeMultichannelRegistrar(bootstrapBlock, r, clusterDialer, clusterServerConfig, clusterGRPCServer, conf, signer, metricsProvider, opsSystem, lf, tlsCallback)
+	manager := initializeMultichannelRegistrar(bootstrapBlock, r, clusterDialer, clusterServerConfig, clusterGRPCServer, conf, signer, metricsProvider, opsSystem, opsSystem, lf, tlsCallback)
 	mutualTLS := serverConfig.SecOpts.UseTLS && serverConfig.SecOpts.RequireClientCert
 	server := NewServer(manager, metricsProvider, &conf.Debug, conf.General.Authentication.TimeWindow, mutualTLS)
 
 	logger.Infof("Starting %s", metadata.GetVersionInfo())
 	go handleSignals(addPlatformSignals(map[os.Signal]func(){
 		syscall.SIGTERM: func() {
+			// The chains are halted while the cluster service still serves,
+			// so that they can hand over the leadership of their channels.
+			if err := manager.Shutdown(conf.General.Shutdown.Parallelism, conf.General.Shutdown.Timeout); err != nil {
+				logger.Warningf("Failed to halt all chains: %s", err)
+			}
 			grpcServer.Stop()
 			if clusterGRPCServer != grpcServer {
 				clusterGRPCServer.Stop()
@@ -176,6 +184,8 @@ func Start(cmd string, conf *localconfig.TopLevel) {
 
 	initializeProfilingService(conf)
 	ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
+	ab.RegisterReceiptsServer(grpcServer.Server(), NewReceiptsServer(manager, conf.General.Authentication.TimeWindow))
+	raftprotos.RegisterConsensusEventsServer(grpcServer.Server(), NewConsensusEventsServer(manager, conf.General.Authentication.TimeWindow))
 	logger.Info("Beginning to serve requests")
 	grpcServer.Start()
 }
@@ -277,6 +287,9 @@ func configureClusterListener(conf *localconfig.TopLevel, generalConf comm.Serve
 	// it means we use the general listener of the node.
 	if clusterConf.ListenPort == 0 && clusterConf.ServerCertificate == "" && clusterConf.ListenAddress == "" && clusterConf.ServerPrivateKey == "" {
 		logger.Info("Cluster listener is not configured, defaulting to use the general listener on port", conf.General.ListenPort)
+		if clusterConf.TLSMaxVersion != "" {
+			logger.Warningf("General.Cluster.TLSMaxVersion (%s) is ignored, as the cluster listener is not configured", clusterConf.TLSMaxVersion)
+		}
 		return generalConf, generalSrv
 	}
 
@@ -296,6 +309,14 @@ func configureClusterListener(conf *localconfig.TopLevel, generalConf comm.Serve
 		logger.Panicf("Failed to load cluster server key from '%s' (%s)", clusterConf.ServerPrivateKey, err)
 	}
 
+	var maxVersion uint16
+	if clusterConf.TLSMaxVersion != "" {
+		maxVersion, err = crypto.ParseTLSVersion(clusterConf.TLSMaxVersion)
+		if err != nil {
+			logger.Panicf("Invalid General.Cluster.TLSMaxVersion: %s", err)
+		}
+	}
+
 	port := fmt.Sprintf("%d", clusterConf.ListenPort)
 	bindAddr := net.JoinHostPort(clusterConf.ListenAddress, port)
 
@@ -323,6 +344,7 @@ func configureClusterListener(conf *localconfig.TopLevel, generalConf comm.Serve
 			Certificate:       cert,
 			UseTLS:            true,
 			Key:               key,
+			MaxVersion:        maxVersion,
 		},
 	}
 
@@ -534,13 +556,93 @@ func initializeGrpcServer(conf *localconfig.TopLevel, serverConfig comm.ServerCo
 }
 
 func initializeLocalMsp(conf *localconfig.TopLevel) {
+	load := mspmgmt.LoadLocalMsp
+	if conf.General.BlockSigner.Enabled {
+		// The signing key is held by the block signer
+		load = mspmgmt.LoadVerifyingLocalMsp
+	}
 	// Load local MSP
-	err := mspmgmt.LoadLocalMsp(conf.General.LocalMSPDir, conf.General.BCCSP, conf.General.LocalMSPID)
+	err := load(conf.General.LocalMSPDir, conf.General.BCCSP, conf.General.LocalMSPID)
 	if err != nil { // Handle errors reading the config file
 		logger.Fatal("Failed to initialize local MSP:", err)
 	}
 }
 
+// initializeSigner returns the signer the orderer signs blocks and other messages
+// with, which is the local MSP unless an external block signer is configured.
+func initializeSigner(conf *localconfig.TopLevel) crypto.LocalSigner {
+	bs := conf.General.BlockSigner
+	if !bs.Enabled {
+		return localmsp.NewSigner()
+	}
+
+	certBytes, err := ioutil.ReadFile(bs.Certificate)
+	if err != nil {
+		logger.Panicf("Failed to load block signer certificate file '%s' (%s)", bs.Certificate, err)
+	}
+	serializedIdentity, err := msp.NewSerializedIdentity(conf.General.LocalMSPID, certBytes)
+	if err != nil {
+		logger.Panicf("Failed serializing the identity of the block signer: %s", err)
+	}
+	identity, err := mspmgmt.GetLocalMSP().DeserializeIdentity(serializedIdentity)
+	if err != nil {
+		logger.Panicf("Failed deserializing the identity of the block signer: %s", err)
+	}
+
+	cc := comm.ClientConfig{
+		AsyncConnect: true,
+		KaOpts:       comm.DefaultKeepaliveOptions,
+		Timeout:      bs.Timeout,
+		SecOpts:      &comm.SecureOptions{},
+	}
+	if cc.Timeout == 0 {
+		cc.Timeout = remotesigner.DefaultTimeout
+	}
+	if bs.TLS.Enabled {
+		cc.SecOpts = &comm.SecureOptions{
+			UseTLS:       true,
+			CipherSuites: comm.DefaultTLSCipherSuites,
+		}
+		for _, serverRoot := range bs.TLS.RootCAs {
+			rootCACert, err := ioutil.ReadFile(serverRoot)
+			if err != nil {
+				logger.Panicf("Failed to load block signer RootCAs file '%s' (%s)", serverRoot, err)
+			}
+			cc.SecOpts.ServerRootCAs = append(cc.SecOpts.ServerRootCAs, rootCACert)
+		}
+		if bs.TLS.Certificate != "" {
+			cc.SecOpts.RequireClientCert = true
+			if cc.SecOpts.Certificate, err = ioutil.ReadFile(bs.TLS.Certificate); err != nil {
+				logger.Panicf("Failed to load block signer client TLS certificate file '%s' (%s)", bs.TLS.Certificate, err)
+			}
+			if cc.SecOpts.Key, err = ioutil.ReadFile(bs.TLS.PrivateKey); err != nil {
+				logger.Panicf("Failed to load block signer client TLS key file '%s' (%s)", bs.TLS.PrivateKey, err)
+			}
+		}
+	}
+
+	client, err := comm.NewGRPCClient(cc)
+	if err != nil {
+		logger.Panicf("Failed creating block signer client: %s", err)
+	}
+	conn, err := client.NewConnection(bs.Address, "")
+	if err != nil {
+		logger.Panicf("Failed connecting to block signer at %s: %s", bs.Address, err)
+	}
+	signer, err := remotesigner.NewSigner(identity, ab.NewSignerClient(conn), remotesigner.Config{
+		Timeout:          bs.Timeout,
+		MaxInflight:      bs.MaxInflight,
+		MaxRetryInterval: bs.MaxRetryInterval,
+		RetryTimeout:     bs.RetryTimeout,
+	})
+	if err != nil {
+		logger.Panicf("Failed creating block signer: %s", err)
+	}
+
+	logger.Infof("Signing with the block signer at %s", bs.Address)
+	return signer
+}
+
 //go:generate counterfeiter -o mocks/health_checker.go -fake-name HealthChecker . healthChecker
 
 // HealthChecker defines the contract for health checker
@@ -548,6 +650,13 @@ type healthChecker interface {
 	RegisterChecker(component string, checker healthz.HealthChecker) error
 }
 
+//go:generate counterfeiter -o mocks/handler_registrar.go -fake-name HandlerRegistrar . handlerRegistrar
+
+// handlerRegistrar registers HTTP handlers with the operations server
+type handlerRegistrar interface {
+	RegisterHandler(pattern string, handler http.Handler)
+}
+
 func initializeMultichannelRegistrar(
 	bootstrapBlock *cb.Block,
 	ri *replicationInitiator,
@@ -558,6 +667,7 @@ func initializeMultichannelRegistrar(
 	signer crypto.LocalSigner,
 	metricsProvider metrics.Provider,
 	healthChecker healthChecker,
+	handlers handlerRegistrar,
 	lf blockledger.Factory,
 	callbacks ...channelconfig.BundleActor,
 ) *multichannel.Registrar {
@@ -580,7 +690,7 @@ func initializeMultichannelRegistrar(
 	// closes if we wished to cleanup this routine on exit.
 	go kafkaMetrics.PollGoMetricsUntilStop(time.Minute, nil)
 	if isClusterType(bootstrapBlock) {
-		initializeEtcdraftConsenter(consenters, conf, lf, clusterDialer, bootstrapBlock, ri, srvConf, srv, registrar, metricsProvider)
+		initializeEtcdraftConsenter(consenters, conf, lf, clusterDialer, bootstrapBlock, ri, srvConf, srv, registrar, metricsProvider, handlers)
 	}
 	registrar.Initialize(consenters)
 	return registrar
@@ -597,6 +707,7 @@ func initializeEtcdraftConsenter(
 	srv *comm.GRPCServer,
 	registrar *multichannel.Registrar,
 	metricsProvider metrics.Provider,
+	handlers handlerRegistrar,
 ) {
 	replicationRefreshInterval := conf.General.Cluster.ReplicationBackgroundRefreshInterval
 	if replicationRefreshInterval == 0 {
@@ -637,6 +748,49 @@ func initializeEtcdraftConsenter(
 	go icr.run()
 	raftConsenter := etcdraft.New(clusterDialer, conf, srvConf, srv, registrar, icr, metricsProvider)
 	consenters["etcdraft"] = raftConsenter
+	handlers.RegisterHandler("/etcdraft/chains", raftConsenter)
+	handlers.RegisterHandler("/etcdraft/bundle", raftConsenter.SupportBundleHandler())
+	handlers.RegisterHandler("/etcdraft/membership", raftConsenter.MembershipHandler())
+	handlers.RegisterHandler("/etcdraft/marker", raftConsenter.MarkerHandler())
+	handlers.RegisterHandler("/etcdraft/consenters/dryrun", raftConsenter.ConsentersDryRunHandler())
+	handlers.RegisterHandler("/etcdraft/participation", raftConsenter.ParticipationHandler())
+	handlers.RegisterHandler("/etcdraft/join/genesis", raftConsenter.GenesisJoinHandler())
+	handlers.RegisterHandler("/etcdraft/estimate", raftConsenter.EstimateHandler())
+	handlers.RegisterHandler("/etcdraft/selftest", raftConsenter.SelfTestHandler())
+	handlers.RegisterHandler("/etcdraft/restartslot", raftConsenter.RestartSlotHandler())
+	handlers.RegisterHandler("/etcdraft/snapshot", raftConsenter.SnapshotHandler())
+	handlers.RegisterHandler("/etcdraft/raftlog", raftConsenter.RaftLogHandler())
+	handlers.RegisterHandler("/etcdraft/dr/promote", raftConsenter.DRPromotionHandler())
+	drReceiver := middleware.RequireCert()(raftConsenter.DRReceiverHandler())
+	handlers.RegisterHandler("/etcdraft/dr/blocks", drReceiver)
+	handlers.RegisterHandler("/etcdraft/dr/snapshot", drReceiver)
+
+	joiner := &channelJoiner{
+		logger:    ri.logger,
+		registrar: registrar,
+		isConsenter: func(configBlock *cb.Block) error {
+			return etcdraft.ConsenterCertificate(ri.secOpts.Certificate).IsConsenterOfChannel(configBlock)
+		},
+		replicate:  ri.replicateJoinedChannel,
+		seed:       ri.seedJoinedChannel,
+		untrack:    icr.UntrackChain,
+		tlsRootCAs: ri.secOpts.ServerRootCAs,
+		joins:      make(map[string]*JoinStatus),
+	}
+	handlers.RegisterHandler("/etcdraft/join", middleware.RequireCert()(joiner))
+	handlers.RegisterHandler("/etcdraft/join/token", middleware.RequireCert()(&joinTokenHandler{registrar: registrar}))
+	handlers.RegisterHandler("/etcdraft/join/checkpoint", middleware.RequireCert()(http.HandlerFunc(joiner.serveJoinFromCheckpoint)))
+	handlers.RegisterHandler("/etcdraft/checkpoint", &checkpointHandler{
+		lastConfigBlock: func(channel string) *cb.Block {
+			cs := registrar.GetChain(channel)
+			if cs == nil {
+				return nil
+			}
+			return multichannel.ConfigBlock(cs)
+		},
+		cert: ri.secOpts.Certificate,
+		key:  ri.secOpts.Key,
+	})
 }
 
 func newOperationsSystem(ops localconfig.Operations, metrics localconfig.Metrics) *operations.System {
@@ -4,10 +4,12 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -32,13 +34,17 @@ import (
 	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/config/configtest"
+	"github.com/hyperledger/fabric/msp"
+	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/common/remotesigner"
 	"github.com/hyperledger/fabric/orderer/common/server/mocks"
 	server_mocks "github.com/hyperledger/fabric/orderer/common/server/mocks"
 	"github.com/hyperledger/fabric/orderer/consensus"
 	"github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -270,6 +276,77 @@ func TestInitializeLocalMsp(t *testing.T) {
 	})
 }
 
+type signerServer struct {
+	identity msp.SigningIdentity
+}
+
+func (s *signerServer) Sign(_ context.Context, req *ab.SignRequest) (*ab.SignResponse, error) {
+	signature, err := s.identity.Sign(req.Message)
+	if err != nil {
+		return nil, err
+	}
+	return &ab.SignResponse{Signature: signature}, nil
+}
+
+func TestInitializeSigner(t *testing.T) {
+	localMSPDir, _ := configtest.GetDevMspDir()
+	conf := &localconfig.TopLevel{
+		General: localconfig.General{
+			LocalMSPDir: localMSPDir,
+			LocalMSPID:  "SampleOrg",
+			BCCSP: &factory.FactoryOpts{
+				ProviderName: "SW",
+				SwOpts: &factory.SwOpts{
+					HashFamily: "SHA2",
+					SecLevel:   256,
+					Ephemeral:  true,
+				},
+			},
+		},
+	}
+	initializeLocalMsp(conf)
+	assert.IsType(t, localmsp.NewSigner(), initializeSigner(conf))
+
+	// The block signer signs with the key of the orderer, which the local MSP does not load
+	signingIdentity, err := mspmgmt.GetLocalMSP().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	creator, err := signingIdentity.Serialize()
+	assert.NoError(t, err)
+	srv, err := comm.NewGRPCServer("127.0.0.1:0", comm.ServerConfig{})
+	assert.NoError(t, err)
+	ab.RegisterSignerServer(srv.Server(), &signerServer{identity: signingIdentity})
+	go srv.Start()
+	defer srv.Stop()
+
+	conf.General.BlockSigner = localconfig.BlockSigner{
+		Enabled:     true,
+		Address:     srv.Address(),
+		Certificate: filepath.Join(localMSPDir, "signcerts", "peer.pem"),
+	}
+	initializeLocalMsp(conf)
+	defer func() {
+		conf.General.BlockSigner.Enabled = false
+		initializeLocalMsp(conf)
+	}()
+	signer := initializeSigner(conf)
+	assert.IsType(t, &remotesigner.Signer{}, signer)
+	sh, err := signer.NewSignatureHeader()
+	assert.NoError(t, err)
+	assert.Equal(t, creator, sh.Creator)
+	signature, err := signer.Sign([]byte("block"))
+	assert.NoError(t, err)
+	assert.NoError(t, signingIdentity.Verify([]byte("block"), signature))
+
+	t.Run("Error", func(t *testing.T) {
+		oldLogger := logger
+		defer func() { logger = oldLogger }()
+		logger, _ = floggingtest.NewTestLogger(t)
+
+		conf.General.BlockSigner.Certificate = "/nonexistent/cert.pem"
+		assert.Panics(t, func() { initializeSigner(conf) })
+	})
+}
+
 func TestInitializeMultiChainManager(t *testing.T) {
 	cleanup := configtest.SetDevFabricConfigPath(t)
 	defer cleanup()
@@ -278,7 +355,7 @@ func TestInitializeMultiChainManager(t *testing.T) {
 		initializeLocalMsp(conf)
 		lf, _ := createLedgerFactory(conf)
 		bootBlock := encoder.New(genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile)).GenesisBlockForChannel("system")
-		initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, conf, localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, lf)
+		initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, conf, localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, &mocks.HandlerRegistrar{}, lf)
 	})
 }
 
@@ -341,7 +418,7 @@ func TestUpdateTrustedRoots(t *testing.T) {
 	}
 	lf, _ := createLedgerFactory(conf)
 	bootBlock := encoder.New(genesisconfig.Load(genesisconfig.SampleDevModeSoloProfile)).GenesisBlockForChannel("system")
-	initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, genesisConfig(t), localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, lf, callback)
+	initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, genesisConfig(t), localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, &mocks.HandlerRegistrar{}, lf, callback)
 	t.Logf("# app CAs: %d", len(caSupport.AppRootCAsByChain[genesisconfig.TestChainID]))
 	t.Logf("# orderer CAs: %d", len(caSupport.OrdererRootCAsByChain[genesisconfig.TestChainID]))
 	// mutual TLS not required so no updates should have occurred
@@ -378,7 +455,7 @@ func TestUpdateTrustedRoots(t *testing.T) {
 			updateClusterDialer(caSupport, predDialer, clusterConf.SecOpts.ServerRootCAs)
 		}
 	}
-	initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, genesisConfig(t), localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, lf, callback)
+	initializeMultichannelRegistrar(bootBlock, &replicationInitiator{}, &cluster.PredicateDialer{}, comm.ServerConfig{}, nil, genesisConfig(t), localmsp.NewSigner(), &disabled.Provider{}, &mocks.HealthChecker{}, &mocks.HandlerRegistrar{}, lf, callback)
 	t.Logf("# app CAs: %d", len(caSupport.AppRootCAsByChain[genesisconfig.TestChainID]))
 	t.Logf("# orderer CAs: %d", len(caSupport.OrdererRootCAsByChain[genesisconfig.TestChainID]))
 	// mutual TLS is required so updates should have occurred
@@ -595,19 +672,66 @@ func TestInitializeEtcdraftConsenter(t *testing.T) {
 	srv, err := comm.NewGRPCServer("127.0.0.1:0", comm.ServerConfig{})
 	assert.NoError(t, err)
 
+	handlers := &mocks.HandlerRegistrar{}
 	initializeEtcdraftConsenter(consenters,
 		&localconfig.TopLevel{},
 		rlf,
 		&cluster.PredicateDialer{},
-		genesisBlock, &replicationInitiator{},
+		genesisBlock, &replicationInitiator{secOpts: &comm.SecureOptions{}},
 		comm.ServerConfig{
 			SecOpts: &comm.SecureOptions{
 				Certificate: crt.Cert,
 				Key:         crt.Key,
 				UseTLS:      true,
 			},
-		}, srv, &multichannel.Registrar{}, &disabled.Provider{})
+		}, srv, &multichannel.Registrar{}, &disabled.Provider{}, handlers)
 	assert.NotNil(t, consenters["etcdraft"])
+	assert.Equal(t, 19, handlers.RegisterHandlerCallCount())
+	pattern, handler := handlers.RegisterHandlerArgsForCall(0)
+	assert.Equal(t, "/etcdraft/chains", pattern)
+	assert.Equal(t, consenters["etcdraft"], handler)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(1)
+	assert.Equal(t, "/etcdraft/bundle", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(2)
+	assert.Equal(t, "/etcdraft/membership", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(3)
+	assert.Equal(t, "/etcdraft/marker", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(4)
+	assert.Equal(t, "/etcdraft/consenters/dryrun", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(5)
+	assert.Equal(t, "/etcdraft/participation", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(6)
+	assert.Equal(t, "/etcdraft/join/genesis", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(7)
+	assert.Equal(t, "/etcdraft/estimate", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(8)
+	assert.Equal(t, "/etcdraft/selftest", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(9)
+	assert.Equal(t, "/etcdraft/restartslot", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(10)
+	assert.Equal(t, "/etcdraft/snapshot", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(11)
+	assert.Equal(t, "/etcdraft/raftlog", pattern)
+	pattern, _ = handlers.RegisterHandlerArgsForCall(12)
+	assert.Equal(t, "/etcdraft/dr/promote", pattern)
+	for i, target := range []string{"/etcdraft/dr/blocks", "/etcdraft/dr/snapshot"} {
+		pattern, handler = handlers.RegisterHandlerArgsForCall(13 + i)
+		assert.Equal(t, target, pattern)
+		// blocks of DR consenters are sent only by clients with verified certificates
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, target, nil))
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	}
+	for i, target := range []string{"/etcdraft/join", "/etcdraft/join/token", "/etcdraft/join/checkpoint"} {
+		pattern, handler = handlers.RegisterHandlerArgsForCall(15 + i)
+		assert.Equal(t, target, pattern)
+		// channels are joined only by clients with verified certificates
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, target, nil))
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	}
+	pattern, _ = handlers.RegisterHandlerArgsForCall(18)
+	assert.Equal(t, "/etcdraft/checkpoint", pattern)
 }
 
 func genesisConfig(t *testing.T) *localconfig.TopLevel {
@@ -195,6 +195,17 @@ func (dc *inactiveChainReplicator) TrackChain(chain string, genesisBlock *common
 	}
 }
 
+// UntrackChain stops tracking the chain with the given name, so that
+// it is not created once again after it was replicated by other means.
+func (dc *inactiveChainReplicator) UntrackChain(chain string) {
+	dc.lock.Lock()
+	defer dc.lock.Unlock()
+	if _, exists := dc.chains2CreationCallbacks[chain]; exists {
+		dc.logger.Infof("Removing %s from the set of chains to track", chain)
+		delete(dc.chains2CreationCallbacks, chain)
+	}
+}
+
 func (dc *inactiveChainReplicator) run() {
 	for {
 		select {
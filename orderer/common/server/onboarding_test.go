@@ -851,6 +851,19 @@ func TestInactiveChainReplicatorChannels(t *testing.T) {
 	icr.Close()
 }
 
+func TestInactiveChainReplicatorUntrackChain(t *testing.T) {
+	icr := &inactiveChainReplicator{
+		logger:                   flogging.MustGetLogger("test"),
+		chains2CreationCallbacks: make(map[string]chainCreation),
+	}
+	icr.TrackChain("foo", &common.Block{}, func() {})
+	icr.TrackChain("bar", &common.Block{}, func() {})
+
+	icr.UntrackChain("foo")
+	icr.UntrackChain("baz")
+	assert.Equal(t, []string{"bar"}, icr.listInactiveChains())
+}
+
 func TestTrackChainNilGenesisBlock(t *testing.T) {
 	icr := &inactiveChainReplicator{
 		logger: flogging.MustGetLogger("test"),
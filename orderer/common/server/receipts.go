@@ -0,0 +1,211 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"math"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/deliver"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/common/msgprocessor"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// receiptSubscription is a subscription to the receipts of a chain.
+type receiptSubscription interface {
+	Receipts() <-chan *ab.TxReceipt
+	Err() error
+	Cancel()
+}
+
+// streamChain is a chain along with the policies
+// the subscribers of its streams are checked against.
+type streamChain interface {
+	deliver.ConfigSequencer
+	msgprocessor.SigFilterSupport
+}
+
+// receiptsChain is a chain which streams receipts.
+type receiptsChain interface {
+	streamChain
+	SubscribeReceipts(txIDs []string) (receiptSubscription, error)
+}
+
+// receiptsSupport looks up the chains of channels for the receipts server.
+type receiptsSupport interface {
+	// ReceiptsChain returns the chain of the channel, or nil if there is no such channel.
+	// It returns an error if the chain of the channel does not stream receipts.
+	ReceiptsChain(channelID string) (receiptsChain, error)
+}
+
+type receiptsRegistrar struct {
+	*multichannel.Registrar
+}
+
+func (rr receiptsRegistrar) ReceiptsChain(channelID string) (receiptsChain, error) {
+	cs := rr.Registrar.GetChain(channelID)
+	if cs == nil {
+		return nil, nil
+	}
+	raftChain, ok := cs.Chain.(*etcdraft.Chain)
+	if !ok {
+		return nil, etcdraft.ErrReceiptsDisabled
+	}
+	return &etcdraftReceiptsChain{ChainSupport: cs, chain: raftChain}, nil
+}
+
+type etcdraftReceiptsChain struct {
+	*multichannel.ChainSupport
+	chain *etcdraft.Chain
+}
+
+func (ec *etcdraftReceiptsChain) SubscribeReceipts(txIDs []string) (receiptSubscription, error) {
+	sub, err := ec.chain.SubscribeReceipts(txIDs)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+type receiptsServer struct {
+	support    receiptsSupport
+	timeWindow time.Duration
+}
+
+// NewReceiptsServer creates an ab.ReceiptsServer streaming the receipts
+// of the transactions ordered by the etcdraft chains of the registrar.
+func NewReceiptsServer(r *multichannel.Registrar, timeWindow time.Duration) ab.ReceiptsServer {
+	return &receiptsServer{
+		support:    receiptsRegistrar{Registrar: r},
+		timeWindow: timeWindow,
+	}
+}
+
+// Receipts streams the receipts of the transactions selected by the ReceiptsRequest
+// in the envelope, as they are ordered, until the client cancels the stream. The
+// envelope must be signed by a reader of the channel, which is re-checked upon each
+// receipt, as with deliver streams.
+func (rs *receiptsServer) Receipts(env *cb.Envelope, srv ab.Receipts_ReceiptsServer) error {
+	payload, chdr, err := parseStreamEnvelope(env, rs.timeWindow)
+	if err != nil {
+		return err
+	}
+
+	chain, err := rs.support.ReceiptsChain(chdr.ChannelId)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "channel %s: %s", chdr.ChannelId, err)
+	}
+	if chain == nil {
+		return status.Errorf(codes.NotFound, "channel %s not found", chdr.ChannelId)
+	}
+
+	accessControl, err := authorizeStream(chain, env, chdr.ChannelId, "receipts")
+	if err != nil {
+		return err
+	}
+
+	request := &ab.ReceiptsRequest{}
+	if err := proto.Unmarshal(payload.Data, request); err != nil {
+		return status.Errorf(codes.InvalidArgument, "failed to unmarshal receipts request: %s", err)
+	}
+
+	sub, err := chain.SubscribeReceipts(request.TxIds)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "channel %s: %s", chdr.ChannelId, err)
+	}
+	defer sub.Cancel()
+
+	logger.Debugf("[channel: %s] Streaming receipts of %d transactions", chdr.ChannelId, len(request.TxIds))
+	for {
+		select {
+		case receipt, ok := <-sub.Receipts():
+			if !ok {
+				return receiptsClosedError(chdr.ChannelId, sub.Err())
+			}
+			if err := accessControl.Evaluate(); err != nil {
+				return status.Errorf(codes.PermissionDenied, "receipts of channel %s: %s", chdr.ChannelId, err)
+			}
+			if err := srv.Send(receipt); err != nil {
+				return err
+			}
+		case <-srv.Context().Done():
+			logger.Debugf("[channel: %s] Receipts stream closed by client", chdr.ChannelId)
+			return nil
+		}
+	}
+}
+
+// parseStreamEnvelope extracts the payload and the channel header of an envelope
+// requesting a stream, whose timestamp must be within the time window of the server.
+func parseStreamEnvelope(env *cb.Envelope, timeWindow time.Duration) (*cb.Payload, *cb.ChannelHeader, error) {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "failed to unmarshal payload: %s", err)
+	}
+	if payload.Header == nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "envelope has no header")
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return nil, nil, status.Errorf(codes.InvalidArgument, "failed to unmarshal channel header: %s", err)
+	}
+	if err := validateTimestamp(chdr, timeWindow); err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return payload, chdr, nil
+}
+
+// authorizeStream checks that the envelope requesting a stream of the chain is
+// signed by a reader of the channel, and returns the access control the stream
+// is re-checked against.
+func authorizeStream(chain streamChain, env *cb.Envelope, channelID, stream string) (*deliver.SessionAccessControl, error) {
+	checkPolicy := func(env *cb.Envelope, channelID string) error {
+		return msgprocessor.NewSigFilter(policies.ChannelReaders, chain).Apply(env)
+	}
+	accessControl, err := deliver.NewSessionAC(chain, env, deliver.PolicyCheckerFunc(checkPolicy), channelID, crypto.ExpiresAt)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := accessControl.Evaluate(); err != nil {
+		return nil, status.Errorf(codes.PermissionDenied, "%s of channel %s: %s", stream, channelID, err)
+	}
+	return accessControl, nil
+}
+
+func validateTimestamp(chdr *cb.ChannelHeader, timeWindow time.Duration) error {
+	if chdr.GetTimestamp() == nil {
+		return errors.New("channel header in envelope must contain timestamp")
+	}
+
+	envTime := time.Unix(chdr.GetTimestamp().Seconds, int64(chdr.GetTimestamp().Nanos)).UTC()
+	serverTime := time.Now()
+	if math.Abs(float64(serverTime.UnixNano()-envTime.UnixNano())) > float64(timeWindow.Nanoseconds()) {
+		return errors.Errorf("envelope timestamp %s is more than %s apart from current server time %s", envTime, timeWindow, serverTime)
+	}
+	return nil
+}
+
+func receiptsClosedError(channelID string, err error) error {
+	if err == nil {
+		err = errors.New("subscription cancelled")
+	}
+	code := codes.Unavailable
+	if err == etcdraft.ErrReceiptsOverflow {
+		code = codes.ResourceExhausted
+	}
+	return status.Errorf(code, "receipts of channel %s: %s", channelID, err)
+}
@@ -0,0 +1,173 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	mockpolicies "github.com/hyperledger/fabric/common/mocks/policies"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type mockReceiptSubscription struct {
+	receipts  chan *ab.TxReceipt
+	err       error
+	cancelled bool
+}
+
+func (m *mockReceiptSubscription) Receipts() <-chan *ab.TxReceipt { return m.receipts }
+func (m *mockReceiptSubscription) Err() error                     { return m.err }
+func (m *mockReceiptSubscription) Cancel()                        { m.cancelled = true }
+
+type mockReceiptsChain struct {
+	policyManager *mockpolicies.Manager
+	sub           *mockReceiptSubscription
+	subErr        error
+	txIDs         []string
+}
+
+func (m *mockReceiptsChain) Sequence() uint64                { return 0 }
+func (m *mockReceiptsChain) PolicyManager() policies.Manager { return m.policyManager }
+func (m *mockReceiptsChain) SubscribeReceipts(txIDs []string) (receiptSubscription, error) {
+	m.txIDs = txIDs
+	if m.subErr != nil {
+		return nil, m.subErr
+	}
+	return m.sub, nil
+}
+
+type mockReceiptsSupport struct {
+	chain *mockReceiptsChain
+	err   error
+}
+
+func (m *mockReceiptsSupport) ReceiptsChain(channelID string) (receiptsChain, error) {
+	if m.err != nil || m.chain == nil {
+		return nil, m.err
+	}
+	return m.chain, nil
+}
+
+type mockReceiptsStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*ab.TxReceipt
+}
+
+func (m *mockReceiptsStream) Context() context.Context { return m.ctx }
+func (m *mockReceiptsStream) Send(receipt *ab.TxReceipt) error {
+	m.sent = append(m.sent, receipt)
+	return nil
+}
+
+func receiptsEnvelope(t *testing.T, txIDs ...string) *cb.Envelope {
+	env, err := utils.CreateSignedEnvelope(cb.HeaderType_DELIVER_SEEK_INFO, "mychannel", mockcrypto.FakeLocalSigner, &ab.ReceiptsRequest{TxIds: txIDs}, 0, 0)
+	require.NoError(t, err)
+	return env
+}
+
+func TestReceipts(t *testing.T) {
+	receipts := make(chan *ab.TxReceipt, 2)
+	receipts <- &ab.TxReceipt{TxId: "a", BlockNumber: 1}
+	receipts <- &ab.TxReceipt{TxId: "b", BlockNumber: 2}
+	close(receipts)
+	sub := &mockReceiptSubscription{receipts: receipts, err: etcdraft.ErrReceiptsOverflow}
+	chain := &mockReceiptsChain{policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}}, sub: sub}
+	rs := &receiptsServer{support: &mockReceiptsSupport{chain: chain}, timeWindow: time.Minute}
+
+	stream := &mockReceiptsStream{ctx: context.Background()}
+	err := rs.Receipts(receiptsEnvelope(t, "a", "b"), stream)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, []string{"a", "b"}, chain.txIDs)
+	assert.Equal(t, []*ab.TxReceipt{{TxId: "a", BlockNumber: 1}, {TxId: "b", BlockNumber: 2}}, stream.sent)
+	assert.True(t, sub.cancelled)
+
+	// the stream ends without an error once the client goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sub = &mockReceiptSubscription{receipts: make(chan *ab.TxReceipt)}
+	chain.sub = sub
+	assert.NoError(t, rs.Receipts(receiptsEnvelope(t), &mockReceiptsStream{ctx: ctx}))
+	assert.True(t, sub.cancelled)
+}
+
+func TestReceiptsRejected(t *testing.T) {
+	stale := receiptsEnvelope(t)
+	payload := utils.UnmarshalPayloadOrPanic(stale.Payload)
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	require.NoError(t, err)
+	chdr.Timestamp = &timestamp.Timestamp{Seconds: time.Now().Add(-time.Hour).Unix()}
+	payload.Header.ChannelHeader = utils.MarshalOrPanic(chdr)
+	stale.Payload = utils.MarshalOrPanic(payload)
+
+	for _, testCase := range []struct {
+		name    string
+		env     *cb.Envelope
+		support *mockReceiptsSupport
+		code    codes.Code
+	}{
+		{
+			name: "malformed envelope",
+			env:  &cb.Envelope{Payload: []byte{1, 2, 3}},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "stale envelope",
+			env:  stale,
+			code: codes.InvalidArgument,
+		},
+		{
+			name:    "unknown channel",
+			support: &mockReceiptsSupport{},
+			code:    codes.NotFound,
+		},
+		{
+			name:    "chain without receipts",
+			support: &mockReceiptsSupport{err: etcdraft.ErrReceiptsDisabled},
+			code:    codes.FailedPrecondition,
+		},
+		{
+			name: "not a reader",
+			support: &mockReceiptsSupport{chain: &mockReceiptsChain{
+				policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{Err: errors.New("not a reader")}},
+			}},
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "receipt stream disabled",
+			support: &mockReceiptsSupport{chain: &mockReceiptsChain{
+				policyManager: &mockpolicies.Manager{Policy: &mockpolicies.Policy{}},
+				subErr:        etcdraft.ErrReceiptsDisabled,
+			}},
+			code: codes.FailedPrecondition,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			env := testCase.env
+			if env == nil {
+				env = receiptsEnvelope(t)
+			}
+			rs := &receiptsServer{support: testCase.support, timeWindow: time.Minute}
+			err := rs.Receipts(env, &mockReceiptsStream{ctx: context.Background()})
+			assert.Equal(t, testCase.code, status.Code(err), "%v", err)
+		})
+	}
+}
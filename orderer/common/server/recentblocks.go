@@ -0,0 +1,130 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+)
+
+// recentBlocksChain is a chain whose deliver clients are served
+// the blocks the chain keeps in memory, if any, from memory.
+type recentBlocksChain struct {
+	*multichannel.ChainSupport
+}
+
+func (rbc recentBlocksChain) Reader() blockledger.Reader {
+	return &recentBlocksReader{
+		Reader: rbc.ChainSupport.Reader(),
+		cache:  rbc.ChainSupport,
+	}
+}
+
+// recentBlocksReader is a blockledger.Reader whose iterators return
+// the blocks kept in memory by the cache, and read the others, which
+// are older than the cached blocks, from the ledger.
+type recentBlocksReader struct {
+	blockledger.Reader
+	cache consensus.RecentBlockCache
+}
+
+func (r *recentBlocksReader) Iterator(startPosition *ab.SeekPosition) (blockledger.Iterator, uint64) {
+	iterator, number := r.Reader.Iterator(startPosition)
+	if _, notFound := iterator.(*blockledger.NotFoundErrorIterator); notFound {
+		return iterator, number
+	}
+	if _, cachedC := r.cache.RecentBlock(number); cachedC == nil {
+		return iterator, number
+	}
+	return &recentBlocksIterator{
+		ledger:       r.Reader,
+		cache:        r.cache,
+		next:         number,
+		iterator:     iterator,
+		iteratorNext: number,
+		closeC:       make(chan struct{}),
+	}, number
+}
+
+// recentBlocksIterator iterates over the blocks of a chain, returning the
+// blocks kept in memory by the cache, and reading the others from the ledger.
+// Once it reaches the height of the ledger, it waits for the next block to be
+// cached rather than appended to the ledger.
+type recentBlocksIterator struct {
+	ledger blockledger.Reader
+	cache  consensus.RecentBlockCache
+	next   uint64 // number of the block returned by the next call to Next
+
+	lock         sync.Mutex
+	closed       bool
+	closeC       chan struct{}
+	iterator     blockledger.Iterator // ledger iterator, positioned at iteratorNext
+	iteratorNext uint64
+}
+
+// Next blocks until the next block is available, or the iterator is closed.
+func (it *recentBlocksIterator) Next() (*cb.Block, cb.Status) {
+	for {
+		// The block is cached once it is appended to the ledger, hence if it is
+		// neither cached nor appended, it is cached once cachedC is closed.
+		block, cachedC := it.cache.RecentBlock(it.next)
+		if block != nil {
+			it.next++
+			return block, cb.Status_SUCCESS
+		}
+		if it.next < it.ledger.Height() {
+			return it.nextFromLedger()
+		}
+		select {
+		case <-cachedC:
+		case <-it.closeC:
+			return nil, cb.Status_SERVICE_UNAVAILABLE
+		}
+	}
+}
+
+func (it *recentBlocksIterator) nextFromLedger() (*cb.Block, cb.Status) {
+	it.lock.Lock()
+	if it.closed {
+		it.lock.Unlock()
+		return nil, cb.Status_SERVICE_UNAVAILABLE
+	}
+	if it.iteratorNext != it.next {
+		// blocks were returned from the cache since the ledger was last read
+		it.iterator.Close()
+		it.iterator, it.iteratorNext = it.ledger.Iterator(&ab.SeekPosition{
+			Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: it.next}},
+		})
+	}
+	iterator := it.iterator
+	it.lock.Unlock()
+
+	block, status := iterator.Next()
+	if status == cb.Status_SUCCESS {
+		it.iteratorNext++
+		it.next++
+	}
+	return block, status
+}
+
+// Close releases the ledger iterator, and unblocks Next.
+func (it *recentBlocksIterator) Close() {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	if it.closed {
+		return
+	}
+	it.closed = true
+	close(it.closeC)
+	it.iterator.Close()
+}
@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	ramledger "github.com/hyperledger/fabric/common/ledger/blockledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecentBlockCache struct {
+	lock     sync.Mutex
+	blocks   map[uint64]*cb.Block
+	changedC chan struct{}
+}
+
+func (c *fakeRecentBlockCache) RecentBlock(number uint64) (*cb.Block, <-chan struct{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.changedC == nil {
+		return nil, nil
+	}
+	return c.blocks[number], c.changedC
+}
+
+func (c *fakeRecentBlockCache) put(block *cb.Block) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.blocks[block.Header.Number] = block
+	close(c.changedC)
+	c.changedC = make(chan struct{})
+}
+
+func TestRecentBlocksReader(t *testing.T) {
+	ledger, err := ramledger.New(10).GetOrCreate("mychannel")
+	require.NoError(t, err)
+	cache := &fakeRecentBlockCache{blocks: map[uint64]*cb.Block{}}
+
+	// the genesis block is written before the cache is enabled
+	require.NoError(t, ledger.Append(blockledger.CreateNextBlock(ledger, nil)))
+	reader := &recentBlocksReader{Reader: ledger, cache: cache}
+	iterator, number := reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+	assert.Equal(t, uint64(0), number)
+	_, isRecentBlocksIterator := iterator.(*recentBlocksIterator)
+	assert.False(t, isRecentBlocksIterator)
+	iterator.Close()
+
+	cache.changedC = make(chan struct{})
+	for i := 0; i < 3; i++ {
+		block := blockledger.CreateNextBlock(ledger, nil)
+		require.NoError(t, ledger.Append(block))
+		cache.put(block)
+	}
+
+	iterator, number = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Oldest{Oldest: &ab.SeekOldest{}}})
+	assert.Equal(t, uint64(0), number)
+	defer iterator.Close()
+
+	// the genesis block is read from the ledger, the others from the cache
+	for number := uint64(0); number < 4; number++ {
+		block, status := iterator.Next()
+		require.Equal(t, cb.Status_SUCCESS, status)
+		assert.Equal(t, number, block.Header.Number)
+		if number > 0 {
+			assert.True(t, block == cache.blocks[number], "block %d is not from the cache", number)
+		}
+	}
+
+	// the iterator waits for the next block to be cached
+	type result struct {
+		block  *cb.Block
+		status cb.Status
+	}
+	resultC := make(chan result, 1)
+	go func() {
+		block, status := iterator.Next()
+		resultC <- result{block: block, status: status}
+	}()
+	select {
+	case <-resultC:
+		t.Fatal("the iterator returned a block which was not committed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	block := blockledger.CreateNextBlock(ledger, nil)
+	require.NoError(t, ledger.Append(block))
+	cache.put(block)
+	res := <-resultC
+	assert.Equal(t, cb.Status_SUCCESS, res.status)
+	assert.True(t, res.block == block)
+
+	// blocks evicted from the cache are read from the ledger
+	delete(cache.blocks, 2)
+	iterator, _ = reader.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
+	for number := uint64(1); number < 5; number++ {
+		block, status := iterator.Next()
+		require.Equal(t, cb.Status_SUCCESS, status)
+		assert.Equal(t, number, block.Header.Number)
+		assert.Equal(t, number != 2, block == cache.blocks[number], "block %d", number)
+	}
+
+	// closing the iterator unblocks it
+	go func() {
+		block, status := iterator.Next()
+		resultC <- result{block: block, status: status}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	iterator.Close()
+	res = <-resultC
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, res.status)
+	assert.Nil(t, res.block)
+}
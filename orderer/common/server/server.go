@@ -43,7 +43,7 @@ func (ds deliverSupport) GetChain(chainID string) deliver.Chain {
 	if chain == nil {
 		return nil
 	}
-	return chain
+	return recentBlocksChain{ChainSupport: chain}
 }
 
 type server struct {
@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package consensus
 
 import (
+	"context"
+	"time"
+
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
@@ -71,6 +74,67 @@ type Chain interface {
 	MigrationStatus() migration.Status
 }
 
+// AdmissionController is implemented by chains which can tell whether they
+// have the capacity to accept new messages.
+type AdmissionController interface {
+	// Admit returns an error if the chain is saturated, in which case
+	// the message should be rejected instead of passed to Order.
+	Admit() error
+}
+
+// GracefulHalter is implemented by chains which hand over their duties to
+// other nodes before they are halted upon shutdown, such as the leadership
+// of the channel, so that the channel does not stall until the other nodes
+// notice that this node is gone.
+type GracefulHalter interface {
+	// HasDuties returns whether the chain has duties to hand over.
+	HasDuties() bool
+	// HandOver hands over the duties of the chain, and returns once they are
+	// handed over, the context is done, or the chain has none to hand over.
+	HandOver(ctx context.Context)
+}
+
+// HealthTransition is a transition of a chain into or out of the errored
+// state signalled by Errored.
+type HealthTransition struct {
+	// Sequence numbers the transitions of the chain from 1, without gaps.
+	Sequence uint64
+	// Errored is whether the chain errored, or recovered from an error.
+	Errored bool
+	Time    time.Time
+}
+
+// HealthReporter is implemented by chains which record their transitions
+// into and out of the errored state, so that deliver front-ends which
+// subscribe late, or miss a recovery followed by another error while
+// waiting on Errored, can replay them in order.
+type HealthReporter interface {
+	// HealthTransitions returns the recent transitions with a sequence greater
+	// than the given one, oldest first, along with a channel which is closed
+	// once a later transition is recorded. Older transitions are discarded,
+	// which subscribers detect by a gap in the sequence.
+	HealthTransitions(after uint64) ([]HealthTransition, <-chan struct{})
+}
+
+// CommitObserver is implemented by ConsenterSupports which notify chains
+// of the blocks committed to the ledger, once they are signed and appended,
+// as blocks are committed asynchronously after they are written.
+type CommitObserver interface {
+	// ObserveCommits registers the given function, which is called
+	// with every block committed from then on, in order.
+	ObserveCommits(committed func(block *cb.Block))
+}
+
+// RecentBlockCache is implemented by chains which keep the blocks they most
+// recently committed in memory, so that deliver services and the replication
+// of other nodes can serve the tip of the chain without reading the ledger.
+type RecentBlockCache interface {
+	// RecentBlock returns the committed block with the given number, or nil
+	// if it is not cached, along with a channel which is closed once a later
+	// block is cached. The channel is nil if the chain caches no blocks.
+	RecentBlock(number uint64) (*cb.Block, <-chan struct{})
+}
+
 //go:generate counterfeiter -o mocks/mock_consenter_support.go . ConsenterSupport
 
 // ConsenterSupport provides the resources available to a Consenter implementation.
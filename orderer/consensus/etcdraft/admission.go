@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// commitRateWeight is the weight of the most recent commit
+// in the moving average of the commit rate.
+const commitRateWeight = 0.2
+
+// admissionController is a token bucket which admits envelopes into the chain.
+// The bucket holds up to the in-flight capacity of the chain, in envelopes,
+// and is refilled at the rate envelopes were recently committed, so that
+// a saturated chain rejects envelopes early rather than queueing them up.
+//
+// Every consenter admits the envelopes submitted to it, hence the capacity
+// and the commit rate are split evenly across the consenters of the chain,
+// so that the cluster as a whole admits envelopes at the commit rate.
+type admissionController struct {
+	clock    clock.Clock
+	capacity func() float64 // in-flight capacity of the chain, in envelopes
+	shares   func() int     // number of consenters the capacity and commit rate are split across
+
+	lock       sync.Mutex
+	tokens     float64
+	rate       float64   // moving average of committed envelopes per second
+	lastRefill time.Time // zero until the first admission, when the bucket starts full
+	lastCommit time.Time
+}
+
+// committed records that a block of the given number of envelopes was committed.
+func (ac *admissionController) committed(envelopes int) {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+
+	now := ac.clock.Now()
+	defer func() { ac.lastCommit = now }()
+
+	if ac.lastCommit.IsZero() {
+		return
+	}
+	elapsed := now.Sub(ac.lastCommit).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	rate := float64(envelopes) / elapsed
+	if ac.rate == 0 {
+		ac.rate = rate
+		return
+	}
+	ac.rate = commitRateWeight*rate + (1-commitRateWeight)*ac.rate
+}
+
+// admit takes a token from the bucket and returns whether
+// it was available. Envelopes are always admitted until
+// the commit rate is known.
+func (ac *admissionController) admit() bool {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+
+	now := ac.clock.Now()
+	shares := float64(ac.shareCount())
+	capacity := ac.capacity() / shares
+	if capacity < 1 {
+		capacity = 1
+	}
+	if ac.lastRefill.IsZero() {
+		ac.tokens = capacity
+	} else {
+		ac.tokens += now.Sub(ac.lastRefill).Seconds() * ac.rate / shares
+	}
+	ac.lastRefill = now
+	if ac.tokens > capacity {
+		ac.tokens = capacity
+	}
+
+	if ac.rate == 0 {
+		return true
+	}
+	if ac.tokens < 1 {
+		return false
+	}
+	ac.tokens--
+	return true
+}
+
+// commitRate returns the moving average of committed envelopes per second.
+func (ac *admissionController) commitRate() float64 {
+	ac.lock.Lock()
+	defer ac.lock.Unlock()
+	return ac.rate
+}
+
+// admissionRate returns the share of the commit rate this node admits envelopes at.
+func (ac *admissionController) admissionRate() float64 {
+	return ac.commitRate() / float64(ac.shareCount())
+}
+
+func (ac *admissionController) shareCount() int {
+	if ac.shares == nil {
+		return 1
+	}
+	if n := ac.shares(); n > 1 {
+		return n
+	}
+	return 1
+}
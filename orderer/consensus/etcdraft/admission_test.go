@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmissionController(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	ac := &admissionController{
+		clock:    clock,
+		capacity: func() float64 { return 10 },
+	}
+
+	// everything is admitted until the commit rate is known
+	for i := 0; i < 100; i++ {
+		assert.True(t, ac.admit())
+	}
+
+	ac.committed(10)
+	clock.Increment(time.Second)
+	ac.committed(10)
+	assert.Equal(t, float64(10), ac.commitRate())
+
+	// the commit rate is a moving average
+	clock.Increment(time.Second)
+	ac.committed(60)
+	assert.Equal(t, float64(20), ac.commitRate())
+
+	// the bucket holds at most the in-flight capacity
+	clock.Increment(time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.True(t, ac.admit())
+	}
+	assert.False(t, ac.admit())
+
+	// and is refilled at the commit rate
+	clock.Increment(100 * time.Millisecond)
+	assert.True(t, ac.admit())
+	assert.True(t, ac.admit())
+	assert.False(t, ac.admit())
+}
+
+func TestAdmissionControllerShares(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	ac := &admissionController{
+		clock:    clock,
+		capacity: func() float64 { return 10 },
+		shares:   func() int { return 5 },
+	}
+
+	ac.committed(10)
+	clock.Increment(time.Second)
+	ac.committed(10)
+	assert.Equal(t, float64(10), ac.commitRate())
+	assert.Equal(t, float64(2), ac.admissionRate())
+
+	// the bucket holds this node's share of the in-flight capacity
+	clock.Increment(time.Minute)
+	assert.True(t, ac.admit())
+	assert.True(t, ac.admit())
+	assert.False(t, ac.admit())
+
+	// and is refilled at its share of the commit rate
+	clock.Increment(500 * time.Millisecond)
+	assert.True(t, ac.admit())
+	assert.False(t, ac.admit())
+}
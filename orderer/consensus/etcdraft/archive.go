@@ -0,0 +1,259 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// archiveSuffix is the suffix of the files holding archived blocks.
+const archiveSuffix = ".blocks"
+
+// WriteArchive writes the given blocks to w in the format read by DirArchive,
+// that is each block marshaled and prefixed with its size as a uvarint.
+func WriteArchive(w io.Writer, blocks ...*common.Block) error {
+	for _, block := range blocks {
+		data, err := proto.Marshal(block)
+		if err != nil {
+			return errors.Wrapf(err, "failed to marshal block %d", block.Header.Number)
+		}
+		var size [binary.MaxVarintLen64]byte
+		if _, err := w.Write(size[:binary.PutUvarint(size[:], uint64(len(data)))]); err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DirArchive is an archive of the blocks of channels kept in a directory,
+// e.g. a mounted object storage bucket, with a subdirectory per channel.
+// Archives of a channel are written by external tooling with WriteArchive,
+// in files named after the last block they hold, e.g. mychannel/1000.blocks.
+//
+// DirArchive references the archives in the snapshots taken by the node,
+// and serves them to the nodes catching up with these snapshots.
+type DirArchive struct {
+	Dir    string
+	Logger *flogging.FabricLogger
+
+	lock   sync.Mutex
+	hashes map[string]archiveHash // by archive path
+}
+
+type archiveHash struct {
+	size    int64
+	modTime time.Time
+	hash    []byte
+}
+
+// Reference returns a reference to the smallest archive of the channel holding
+// the blocks up to (and including) the given block, or nil if there is none.
+func (a *DirArchive) Reference(channel string, blockNum uint64) *etcdraft.ArchiveReference {
+	dir := filepath.Join(a.Dir, channel)
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.Logger.Warnf("Failed to list archives of channel %s: %s", channel, err)
+		}
+		return nil
+	}
+
+	var name string
+	var last uint64
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), archiveSuffix) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), archiveSuffix), 10, 64)
+		if err != nil || n < blockNum {
+			continue
+		}
+		if name == "" || n < last {
+			name, last = f.Name(), n
+		}
+	}
+	if name == "" {
+		return nil
+	}
+
+	path := filepath.Join(dir, name)
+	hash, err := a.hash(path)
+	if err != nil {
+		a.Logger.Warnf("Failed to hash archive %s: %s", path, err)
+		return nil
+	}
+	return &etcdraft.ArchiveReference{Uri: (&url.URL{Scheme: "file", Path: path}).String(), Hash: hash}
+}
+
+// Fetch returns a BlockPuller over the blocks of the referenced archive,
+// along with the SHA256 hash of the archive content.
+func (a *DirArchive) Fetch(ref *etcdraft.ArchiveReference) (BlockPuller, []byte, error) {
+	u, err := url.Parse(ref.Uri)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "invalid archive URI %s", ref.Uri)
+	}
+	if u.Scheme != "file" {
+		return nil, nil, errors.Errorf("unsupported scheme of archive URI %s", ref.Uri)
+	}
+	// The reference comes from the snapshot of another node,
+	// hence only archives within the directory are read.
+	rel, err := filepath.Rel(a.Dir, filepath.Clean(u.Path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, nil, errors.Errorf("archive %s is not in %s", ref.Uri, a.Dir)
+	}
+
+	// The archive is hashed and read through the same file handle,
+	// so that the blocks pulled are the ones hashed even if the
+	// archive is replaced meanwhile.
+	f, err := os.Open(filepath.Join(a.Dir, rel))
+	if err != nil {
+		return nil, nil, err
+	}
+	hash, err := hashReader(f)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return nil, nil, errors.Wrapf(err, "failed to read %s", f.Name())
+	}
+	return &archivePuller{logger: a.Logger, f: f, r: bufio.NewReader(f)}, hash, nil
+}
+
+func (a *DirArchive) hash(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if h, exists := a.hashes[path]; exists && h.size == info.Size() && h.modTime.Equal(info.ModTime()) {
+		return h.hash, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if a.hashes == nil {
+		a.hashes = make(map[string]archiveHash)
+	}
+	a.hashes[path] = archiveHash{size: info.Size(), modTime: info.ModTime(), hash: hash}
+	return hash, nil
+}
+
+func hashFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hash, err := hashReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read %s", path)
+	}
+	return hash, nil
+}
+
+func hashReader(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// archivePuller reads the blocks of an archive in order.
+type archivePuller struct {
+	logger  *flogging.FabricLogger
+	f       *os.File
+	r       *bufio.Reader
+	pending *common.Block // read ahead of the block pulled last
+}
+
+// PullBlock returns the block of the given number, which is expected to be
+// pulled after the blocks preceding it, or nil if the archive does not hold it.
+func (p *archivePuller) PullBlock(seq uint64) *common.Block {
+	for {
+		block := p.pending
+		if block == nil {
+			var err error
+			if block, err = p.read(); err != nil {
+				if err != io.EOF {
+					p.logger.Errorf("Failed to read block %d from archive %s: %s", seq, p.f.Name(), err)
+				}
+				return nil
+			}
+		}
+		p.pending = nil
+
+		switch n := block.Header.Number; {
+		case n == seq:
+			return block
+		case n > seq:
+			p.pending = block
+			return nil
+		}
+	}
+}
+
+func (p *archivePuller) read() (*common.Block, error) {
+	size, err := binary.ReadUvarint(p.r)
+	if err != nil {
+		return nil, err
+	}
+	// A block larger than a message the orderer can receive
+	// cannot be a block of the channel, hence the archive is corrupt.
+	if size > uint64(comm.MaxRecvMsgSize) {
+		return nil, errors.Errorf("block of %d bytes exceeds the maximum of %d bytes", size, comm.MaxRecvMsgSize)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(p.r, data); err != nil {
+		return nil, err
+	}
+	block := &common.Block{}
+	if err := proto.Unmarshal(data, block); err != nil {
+		return nil, err
+	}
+	if block.Header == nil {
+		return nil, errors.New("block header is nil")
+	}
+	return block, nil
+}
+
+// HeightsByEndpoints is not supported by archives.
+func (p *archivePuller) HeightsByEndpoints() (map[string]uint64, error) {
+	return nil, errors.New("not supported by archives")
+}
+
+// Close closes the archive.
+func (p *archivePuller) Close() {
+	p.f.Close()
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	var blocks []*common.Block
+	for i := uint64(0); i < 10; i++ {
+		block := common.NewBlock(i, nil)
+		if i > 0 {
+			block.Header.PreviousHash = blocks[i-1].Header.Hash()
+		}
+		blocks = append(blocks, block)
+	}
+
+	writeArchive := func(name string, blocks ...*common.Block) []byte {
+		buf := &bytes.Buffer{}
+		require.NoError(t, WriteArchive(buf, blocks...))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "mychannel", name), buf.Bytes(), 0644))
+		hash := sha256.Sum256(buf.Bytes())
+		return hash[:]
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "mychannel"), 0755))
+	hash4 := writeArchive("4.blocks", blocks[:5]...)
+	hash9 := writeArchive("9.blocks", blocks...)
+	writeArchive("notanarchive", blocks...)
+
+	archive := &DirArchive{Dir: dir, Logger: flogging.MustGetLogger("test")}
+
+	t.Run("reference", func(t *testing.T) {
+		ref := archive.Reference("mychannel", 3)
+		require.NotNil(t, ref)
+		assert.Equal(t, (&url.URL{Scheme: "file", Path: filepath.Join(dir, "mychannel", "4.blocks")}).String(), ref.Uri)
+		assert.Equal(t, hash4, ref.Hash)
+
+		ref = archive.Reference("mychannel", 5)
+		require.NotNil(t, ref)
+		assert.Equal(t, hash9, ref.Hash)
+
+		assert.Nil(t, archive.Reference("mychannel", 10))
+		assert.Nil(t, archive.Reference("otherchannel", 1))
+	})
+
+	t.Run("fetch", func(t *testing.T) {
+		puller, hash, err := archive.Fetch(archive.Reference("mychannel", 9))
+		require.NoError(t, err)
+		defer puller.Close()
+		assert.Equal(t, hash9, hash)
+
+		assert.True(t, proto.Equal(blocks[3], puller.PullBlock(3)))
+		assert.True(t, proto.Equal(blocks[4], puller.PullBlock(4)))
+		assert.True(t, proto.Equal(blocks[9], puller.PullBlock(9)))
+		assert.Nil(t, puller.PullBlock(10))
+	})
+
+	t.Run("fetch while the archive is replaced", func(t *testing.T) {
+		puller, hash, err := archive.Fetch(archive.Reference("mychannel", 9))
+		require.NoError(t, err)
+		defer puller.Close()
+
+		// the blocks pulled are the ones hashed
+		replaced := filepath.Join(dir, "replaced")
+		require.NoError(t, ioutil.WriteFile(replaced, nil, 0644))
+		require.NoError(t, os.Rename(replaced, filepath.Join(dir, "mychannel", "9.blocks")))
+		assert.Equal(t, hash9, hash)
+		assert.True(t, proto.Equal(blocks[9], puller.PullBlock(9)))
+		writeArchive("9.blocks", blocks...)
+	})
+
+	t.Run("fetch an archive of an oversized block", func(t *testing.T) {
+		var size [binary.MaxVarintLen64]byte
+		data := size[:binary.PutUvarint(size[:], uint64(comm.MaxRecvMsgSize)+1)]
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "mychannel", "20.blocks"), data, 0644))
+
+		puller, _, err := archive.Fetch(archive.Reference("mychannel", 20))
+		require.NoError(t, err)
+		defer puller.Close()
+		assert.Nil(t, puller.PullBlock(20))
+	})
+
+	t.Run("fetch outside of the archive directory", func(t *testing.T) {
+		_, _, err := archive.Fetch(&etcdraft.ArchiveReference{Uri: "file://" + filepath.Join(dir, "..", "etc", "passwd")})
+		assert.EqualError(t, err, "archive file://"+filepath.Join(dir, "..", "etc", "passwd")+" is not in "+dir)
+	})
+
+	t.Run("fetch unsupported scheme", func(t *testing.T) {
+		_, _, err := archive.Fetch(&etcdraft.ArchiveReference{Uri: "s3://bucket/mychannel/9.blocks"})
+		assert.EqualError(t, err, "unsupported scheme of archive URI s3://bucket/mychannel/9.blocks")
+	})
+}
@@ -0,0 +1,41 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// attestation returns the ConsensusRequest metadata
+// attesting the given raft ID of the sender.
+func attestation(raftID uint64) []byte {
+	return utils.MarshalOrPanic(&etcdraft.ConsensusRequestMetadata{RaftId: raftID})
+}
+
+// attestedRaftID returns the raft ID the sender of the given ConsensusRequest
+// attests in its metadata, or zero if it attests none, as nodes which preceded
+// attestations do not, and consenters are upgraded one at a time. It fails if
+// the attested raft ID is not that of the consenter whose TLS certificate the
+// sender connected with, as the sender is misconfigured with the ID of another
+// consenter, and stepping its messages would corrupt the raft state of both.
+func attestedRaftID(req *orderer.ConsensusRequest, sender uint64) (uint64, error) {
+	if len(req.Metadata) == 0 {
+		return 0, nil
+	}
+	md := &etcdraft.ConsensusRequestMetadata{}
+	if err := proto.Unmarshal(req.Metadata, md); err != nil {
+		return 0, errors.Errorf("failed to unmarshal ConsensusRequest metadata: %s", err)
+	}
+	if md.RaftId != sender {
+		return 0, errors.Errorf("sender attests raft ID %d, but its TLS certificate is that of node %d", md.RaftId, sender)
+	}
+	return md.RaftId, nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/pkg/errors"
+)
+
+// TrustedNode is a remote node the communication layer of a chain is configured
+// with, identified by the SHA256 fingerprints of its DER encoded TLS certificates.
+type TrustedNode struct {
+	ID                  uint64 `json:"id"`
+	Endpoint            string `json:"endpoint"`
+	ServerTLSCertSHA256 string `json:"server_tls_cert_sha256"`
+	ClientTLSCertSHA256 string `json:"client_tls_cert_sha256"`
+}
+
+// TrustRecord records the remote nodes a chain trusts as of the given time.
+type TrustRecord struct {
+	Channel string        `json:"channel"`
+	NodeID  uint64        `json:"node_id"`
+	Time    time.Time     `json:"time"`
+	Nodes   []TrustedNode `json:"nodes"`
+}
+
+// SignedTrustRecord is a TrustRecord signed by the orderer. The signature is
+// made over the serialized record followed by the serialized common.SignatureHeader,
+// which carries the identity of the orderer to verify the signature against.
+type SignedTrustRecord struct {
+	Record          json.RawMessage `json:"record"`
+	SignatureHeader []byte          `json:"signature_header"`
+	Signature       []byte          `json:"signature"`
+}
+
+// trustedNodes returns the remote nodes ordered by their ID, which identify
+// the trust relationships of the communication layer.
+func trustedNodes(nodes []cluster.RemoteNode) []TrustedNode {
+	trusted := make([]TrustedNode, 0, len(nodes))
+	for _, n := range nodes {
+		serverCertHash := sha256.Sum256(n.ServerTLSCert)
+		clientCertHash := sha256.Sum256(n.ClientTLSCert)
+		trusted = append(trusted, TrustedNode{
+			ID:                  n.ID,
+			Endpoint:            n.Endpoint,
+			ServerTLSCertSHA256: hex.EncodeToString(serverCertHash[:]),
+			ClientTLSCertSHA256: hex.EncodeToString(clientCertHash[:]),
+		})
+	}
+	sort.Slice(trusted, func(i, j int) bool {
+		return trusted[i].ID < trusted[j].ID
+	})
+	return trusted
+}
+
+// sameTrust returns whether both sets of trusted nodes, as returned
+// by trustedNodes, are the same.
+func sameTrust(a, b []TrustedNode) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// signTrustRecord serializes the record and signs it with the given signer.
+func signTrustRecord(record *TrustRecord, signer crypto.LocalSigner) (*SignedTrustRecord, error) {
+	recordBytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal trust record")
+	}
+
+	sigHdr, err := signer.NewSignatureHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create signature header")
+	}
+	sigHdrBytes, err := proto.Marshal(sigHdr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal signature header")
+	}
+
+	signature, err := signer.Sign(util.ConcatenateBytes(recordBytes, sigHdrBytes))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign trust record")
+	}
+
+	return &SignedTrustRecord{
+		Record:          recordBytes,
+		SignatureHeader: sigHdrBytes,
+		Signature:       signature,
+	}, nil
+}
+
+// TrustAuditLog appends the signed trust records of all chains
+// of the orderer to a file, one JSON record per line.
+type TrustAuditLog struct {
+	Path string
+
+	lock sync.Mutex
+}
+
+// Append appends the record to the audit log, and syncs it to disk.
+func (l *TrustAuditLog) Append(record *SignedTrustRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal signed trust record")
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open trust audit log %s", l.Path)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Wrapf(err, "failed to write to trust audit log %s", l.Path)
+	}
+	return f.Sync()
+}
@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedNodes(t *testing.T) {
+	nodes := []cluster.RemoteNode{
+		{ID: 3, Endpoint: "node3:7050", ServerTLSCert: []byte("server3"), ClientTLSCert: []byte("client3")},
+		{ID: 2, Endpoint: "node2:7050", ServerTLSCert: []byte("server2"), ClientTLSCert: []byte("client2")},
+	}
+
+	trusted := trustedNodes(nodes)
+	assert.Equal(t, []TrustedNode{
+		{
+			ID:                  2,
+			Endpoint:            "node2:7050",
+			ServerTLSCertSHA256: sha256Hex("server2"),
+			ClientTLSCertSHA256: sha256Hex("client2"),
+		},
+		{
+			ID:                  3,
+			Endpoint:            "node3:7050",
+			ServerTLSCertSHA256: sha256Hex("server3"),
+			ClientTLSCertSHA256: sha256Hex("client3"),
+		},
+	}, trusted)
+
+	assert.True(t, sameTrust(trusted, trustedNodes([]cluster.RemoteNode{nodes[1], nodes[0]})))
+	assert.False(t, sameTrust(trusted, trustedNodes(nodes[:1])))
+
+	rotated := []cluster.RemoteNode{nodes[0], nodes[1]}
+	rotated[1].ServerTLSCert = []byte("rotated server2")
+	assert.False(t, sameTrust(trusted, trustedNodes(rotated)))
+}
+
+func sha256Hex(s string) string {
+	return hex.EncodeToString(util.ComputeSHA256([]byte(s)))
+}
+
+func TestTrustAuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "trust-audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	auditLog := &TrustAuditLog{Path: filepath.Join(dir, "trust.log")}
+	signer := &mockcrypto.LocalSigner{Identity: []byte("orderer"), Nonce: []byte("nonce")}
+
+	for _, nodeID := range []uint64{1, 2} {
+		record, err := signTrustRecord(&TrustRecord{
+			Channel: "foo",
+			NodeID:  nodeID,
+			Time:    time.Unix(1000, 0).UTC(),
+			Nodes:   []TrustedNode{{ID: 3, Endpoint: "node3:7050"}},
+		}, signer)
+		require.NoError(t, err)
+		require.NoError(t, auditLog.Append(record))
+	}
+
+	f, err := os.Open(auditLog.Path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var nodeIDs []uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		signed := &SignedTrustRecord{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), signed))
+
+		sigHdr := &common.SignatureHeader{}
+		require.NoError(t, proto.Unmarshal(signed.SignatureHeader, sigHdr))
+		assert.Equal(t, []byte("orderer"), sigHdr.Creator)
+		// the mock signer signs messages with the message itself
+		assert.Equal(t, util.ConcatenateBytes(signed.Record, signed.SignatureHeader), signed.Signature)
+
+		record := &TrustRecord{}
+		require.NoError(t, json.Unmarshal(signed.Record, record))
+		assert.Equal(t, "foo", record.Channel)
+		assert.Equal(t, []TrustedNode{{ID: 3, Endpoint: "node3:7050"}}, record.Nodes)
+		nodeIDs = append(nodeIDs, record.NodeID)
+	}
+	assert.Equal(t, []uint64{1, 2}, nodeIDs)
+
+	auditLog = &TrustAuditLog{Path: filepath.Join(dir, "missing", "trust.log")}
+	err = auditLog.Append(&SignedTrustRecord{})
+	assert.Contains(t, err.Error(), "failed to open trust audit log")
+}
@@ -0,0 +1,228 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultSnapshotTimeout is the time snapshots requested through
+// the operations server without a timeout are waited for.
+const defaultSnapshotTimeout = 30 * time.Second
+
+// SnapshotPoint is the block and raft index a snapshot of a channel is taken at.
+type SnapshotPoint struct {
+	Channel     string `json:"channel"`
+	BlockNumber uint64 `json:"block_number"`
+	RaftIndex   uint64 `json:"raft_index"`
+}
+
+// SnapshotManifest lists the snapshots of channels taken together while the
+// channels were quiesced, hence mutually consistent.
+type SnapshotManifest struct {
+	Time     time.Time       `json:"time"`
+	Channels []SnapshotPoint `json:"channels"`
+}
+
+// snapshotRequest is a request to snapshot the chain at its last written block,
+// answered with the point the snapshot is taken at, or with an error.
+type snapshotRequest struct {
+	pointC chan SnapshotPoint
+	errC   chan error
+	doneC  chan error // receives the outcome of taking the snapshot
+}
+
+// Snapshot snapshots the chain at its last written block, regardless of the
+// snapshot interval. It returns the point the snapshot is taken at, along with
+// the channel which receives the outcome once the snapshot is taken.
+func (c *Chain) Snapshot() (SnapshotPoint, <-chan error, error) {
+	if err := c.isRunning(); err != nil {
+		return SnapshotPoint{}, nil, err
+	}
+
+	req := &snapshotRequest{
+		pointC: make(chan SnapshotPoint, 1),
+		errC:   make(chan error, 1),
+		doneC:  make(chan error, 1),
+	}
+	select {
+	case c.snapshotC <- req:
+	case <-c.doneC:
+		return SnapshotPoint{}, nil, errors.Errorf("chain is stopped")
+	}
+
+	select {
+	case point := <-req.pointC:
+		return point, req.doneC, nil
+	case err := <-req.errC:
+		return SnapshotPoint{}, nil, err
+	}
+}
+
+// TakeSnapshot snapshots the chain at its last written block, regardless of the
+// snapshot interval, and waits up to the given timeout for the snapshot to be
+// taken, once which the WAL and the snapshots preceding it are compacted, e.g.
+// ahead of disk maintenance. Unlike SnapshotChannels, it does not pause the chain.
+func (c *Chain) TakeSnapshot(timeout time.Duration) (SnapshotPoint, error) {
+	point, doneC, err := c.Snapshot()
+	if err != nil {
+		return SnapshotPoint{}, err
+	}
+
+	timer := c.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-doneC:
+		if err != nil {
+			return SnapshotPoint{}, err
+		}
+		return point, nil
+	case <-timer.C():
+		return SnapshotPoint{}, errors.Errorf("snapshot at block %d was not taken within %s", point.BlockNumber, timeout)
+	}
+}
+
+// snapshot hands the snapshot of the last written block to the garbage
+// collector, unless a snapshot is being taken already. It is called by
+// serveRequest, which owns the last written block and the applied index.
+func (c *Chain) snapshot(req *snapshotRequest) {
+	g := &gc{index: c.appliedIndex, state: c.confState, block: captureBlock(c.lastBlock), doneC: req.doneC}
+	select {
+	case c.gcC <- g:
+		c.logger.Infof("Taking snapshot at block %d (raft index %d) on request", c.lastBlock.Header.Number, c.appliedIndex)
+		c.accDataSize = 0
+		c.lastSnapBlockNum = c.lastBlock.Header.Number
+		c.Metrics.SnapshotBlockNumber.Set(float64(c.lastBlock.Header.Number))
+		req.pointC <- SnapshotPoint{Channel: c.channelID, BlockNumber: c.lastBlock.Header.Number, RaftIndex: c.appliedIndex}
+	default:
+		req.errC <- errors.Errorf("snapshotting is in progress")
+	}
+}
+
+// SnapshotChannels takes a mutually consistent snapshot of the given channels, or
+// of every etcdraft chain of this node if none are given, for a backup. It pauses
+// the chains which are not paused already, so that this node stops proposing
+// transactions to them, snapshots each chain at its last written block, resumes
+// them, and waits up to the given timeout for the snapshots to be taken. Blocks
+// already in flight may still be written while the chains are paused, hence the
+// manifest lists the block and raft index each snapshot is taken at.
+func (c *Consenter) SnapshotChannels(channels []string, timeout time.Duration) (*SnapshotManifest, error) {
+	channels = append([]string(nil), channels...)
+	if len(channels) == 0 {
+		for _, channelID := range c.Chains.ChainIDs() {
+			if c.etcdraftChain(channelID) != nil {
+				channels = append(channels, channelID)
+			}
+		}
+	}
+	sort.Strings(channels)
+
+	chains := make([]*Chain, len(channels))
+	for i, channelID := range channels {
+		if chains[i] = c.etcdraftChain(channelID); chains[i] == nil {
+			return nil, errors.Errorf("channel %s is not an etcdraft chain of this node", channelID)
+		}
+	}
+
+	manifest, outcomes, err := snapshotQuiesced(chains)
+	if err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for i, doneC := range outcomes {
+		select {
+		case err := <-doneC:
+			if err != nil {
+				return nil, errors.WithMessage(err, fmt.Sprintf("failed to snapshot channel %s", channels[i]))
+			}
+		case <-timer.C:
+			return nil, errors.Errorf("snapshot of channel %s was not taken within %s", channels[i], timeout)
+		}
+	}
+
+	c.Logger.Infof("Took consistent snapshot of channels: %+v", manifest.Channels)
+	return manifest, nil
+}
+
+// snapshotQuiesced snapshots the given chains while they are paused,
+// resuming the chains which were not paused already once done.
+func snapshotQuiesced(chains []*Chain) (*SnapshotManifest, []<-chan error, error) {
+	for _, chain := range chains {
+		if !chain.Paused() {
+			chain.Pause()
+			defer chain.Resume()
+		}
+	}
+
+	manifest := &SnapshotManifest{Time: time.Now().UTC(), Channels: []SnapshotPoint{}}
+	var outcomes []<-chan error
+	for _, chain := range chains {
+		point, doneC, err := chain.Snapshot()
+		if err != nil {
+			return nil, nil, errors.WithMessage(err, fmt.Sprintf("failed to snapshot channel %s", chain.channelID))
+		}
+		manifest.Channels = append(manifest.Channels, point)
+		outcomes = append(outcomes, doneC)
+	}
+	return manifest, outcomes, nil
+}
+
+// snapshotHandler takes consistent snapshots of
+// the etcdraft chains given in the query.
+type snapshotHandler struct {
+	consenter *Consenter
+}
+
+// SnapshotHandler returns a handler taking a mutually consistent snapshot of
+// channels for POST requests of the form ?channel=<channel ID>&channel=<channel
+// ID>&timeout=<duration>, or of every etcdraft chain of this node if no channel
+// is given, answered with the SnapshotManifest. The timeout defaults to 30s.
+func (c *Consenter) SnapshotHandler() http.Handler {
+	return &snapshotHandler{consenter: c}
+}
+
+func (h *snapshotHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	query := req.URL.Query()
+	timeout := defaultSnapshotTimeout
+	if t := query.Get("timeout"); t != "" {
+		var err error
+		if timeout, err = time.ParseDuration(t); err != nil || timeout <= 0 {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid timeout: %q", t))
+			return
+		}
+	}
+	for _, channelID := range query["channel"] {
+		if h.consenter.etcdraftChain(channelID) == nil {
+			sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+			return
+		}
+	}
+
+	manifest, err := h.consenter.SnapshotChannels(query["channel"], timeout)
+	if err != nil {
+		sendJSONError(resp, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(manifest); err != nil {
+		h.consenter.Logger.Errorw("failed to encode snapshot manifest", "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotHandler(t *testing.T) {
+	newChain := func(channelID string) *Chain {
+		return &Chain{
+			channelID: channelID,
+			logger:    flogging.MustGetLogger("test"),
+			Metrics:   &Metrics{IsPaused: &metricsfakes.Gauge{}},
+		}
+	}
+	paused, running := newChain("paused"), newChain("running")
+	paused.Pause()
+	chains := chainsByID{
+		"paused":  &multichannel.ChainSupport{Chain: paused},
+		"running": &multichannel.ChainSupport{Chain: running},
+	}
+	handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).SnapshotHandler()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(method, target, nil))
+		return resp
+	}
+	errorOf := func(resp *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return body["error"]
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/snapshot")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	resp = serve(http.MethodPost, "/etcdraft/snapshot?timeout=0s")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, `invalid timeout: "0s"`, errorOf(resp))
+	resp = serve(http.MethodPost, "/etcdraft/snapshot?channel=running&channel=nochannel")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.False(t, running.Paused())
+
+	// chains are quiesced while snapshotting, and left as found once it fails
+	resp = serve(http.MethodPost, "/etcdraft/snapshot")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "failed to snapshot channel paused: chain is not started", errorOf(resp))
+	assert.True(t, paused.Paused())
+	assert.False(t, running.Paused())
+	assert.Equal(t, 2, running.Metrics.IsPaused.(*metricsfakes.Gauge).SetCallCount())
+}
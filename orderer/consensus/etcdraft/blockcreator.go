@@ -7,6 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package etcdraft
 
 import (
+	"crypto/sha256"
+	"math"
+
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
 	cb "github.com/hyperledger/fabric/protos/common"
@@ -22,24 +25,72 @@ type blockCreator struct {
 }
 
 func (bc *blockCreator) createNextBlock(envs []*cb.Envelope) *cb.Block {
-	data := &cb.BlockData{
-		Data: make([][]byte, len(envs)),
+	return bc.nextBlock(bc.marshal(envs))
+}
+
+// createNextBlocks creates the next blocks out of envs, splitting them across as
+// many blocks as needed for each block to be at most maxSize bytes when serialized,
+// so that raft is able to replicate it. Envelopes are checked against maxBlockSize
+// before they are ordered, hence an envelope always fits in a block of its own.
+// Blocks are not split if maxSize is 0.
+func (bc *blockCreator) createNextBlocks(envs []*cb.Envelope, maxSize uint64) (blocks []*cb.Block) {
+	data := bc.marshal(envs)
+
+	var split func(from, to int)
+	split = func(from, to int) {
+		if maxSize == 0 || to-from == 1 || bc.nextBlockSize(data[from:to]) <= maxSize {
+			blocks = append(blocks, bc.nextBlock(data[from:to]))
+			return
+		}
+
+		mid := from + (to-from)/2
+		split(from, mid)
+		split(mid, to)
 	}
+	split(0, len(envs))
+
+	return blocks
+}
+
+func (bc *blockCreator) marshal(envs []*cb.Envelope) [][]byte {
+	data := make([][]byte, len(envs))
 
 	var err error
 	for i, env := range envs {
-		data.Data[i], err = proto.Marshal(env)
+		data[i], err = proto.Marshal(env)
 		if err != nil {
 			bc.logger.Panicf("Could not marshal envelope: %s", err)
 		}
 	}
 
+	return data
+}
+
+func (bc *blockCreator) nextBlock(data [][]byte) *cb.Block {
 	bc.number++
 
 	block := cb.NewBlock(bc.number, bc.hash)
-	block.Header.DataHash = data.Hash()
-	block.Data = data
+	block.Data = &cb.BlockData{Data: data}
+	block.Header.DataHash = block.Data.Hash()
 
 	bc.hash = block.Header.Hash()
 	return block
 }
+
+// nextBlockSize returns the serialized size of the next block if it were
+// created out of data, without hashing the data.
+func (bc *blockCreator) nextBlockSize(data [][]byte) uint64 {
+	block := cb.NewBlock(bc.number+1, bc.hash)
+	block.Data = &cb.BlockData{Data: data}
+	block.Header.DataHash = make([]byte, sha256.Size)
+	return uint64(proto.Size(block))
+}
+
+// maxBlockSize returns the largest serialized size of a block holding
+// only the given envelope, whatever the number and previous hash of the block.
+func maxBlockSize(env *cb.Envelope) uint64 {
+	block := cb.NewBlock(math.MaxUint64, make([]byte, sha256.Size))
+	block.Data = &cb.BlockData{Data: [][]byte{make([]byte, proto.Size(env))}}
+	block.Header.DataHash = make([]byte, sha256.Size)
+	return uint64(proto.Size(block))
+}
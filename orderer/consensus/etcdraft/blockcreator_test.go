@@ -7,10 +7,12 @@ SPDX-License-Identifier: Apache-2.0
 package etcdraft
 
 import (
+	"math"
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -39,3 +41,55 @@ func TestCreateNextBlock(t *testing.T) {
 	assert.Equal(t, third.Data.Hash(), third.Header.DataHash)
 	assert.Equal(t, second.Header.Hash(), third.Header.PreviousHash)
 }
+
+func TestCreateNextBlocks(t *testing.T) {
+	first := cb.NewBlock(0, []byte("firsthash"))
+	bc := &blockCreator{
+		hash:   first.Header.Hash(),
+		number: first.Header.Number,
+		logger: flogging.NewFabricLogger(zap.NewNop()),
+	}
+
+	small := &cb.Envelope{Payload: make([]byte, 100)}
+	big := &cb.Envelope{Payload: make([]byte, 1000)}
+	huge := &cb.Envelope{Payload: make([]byte, 5000)}
+
+	// fits in a single block
+	blocks := bc.createNextBlocks([]*cb.Envelope{small, small}, 2048)
+	assert.Len(t, blocks, 1)
+	assert.Len(t, blocks[0].Data.Data, 2)
+	assert.Equal(t, first.Header.Hash(), blocks[0].Header.PreviousHash)
+
+	// split across blocks
+	blocks = bc.createNextBlocks([]*cb.Envelope{big, small, big, big}, 2048)
+	assert.Len(t, blocks, 3)
+	previous := uint64(1)
+	var envelopes int
+	for _, block := range blocks {
+		assert.Equal(t, previous+1, block.Header.Number)
+		assert.Equal(t, block.Data.Hash(), block.Header.DataHash)
+		assert.True(t, len(utils.MarshalOrPanic(block)) <= 2048)
+		previous = block.Header.Number
+		envelopes += len(block.Data.Data)
+	}
+	assert.Equal(t, 4, envelopes)
+	assert.Equal(t, blocks[0].Header.Hash(), blocks[1].Header.PreviousHash)
+	assert.Equal(t, blocks[1].Header.Hash(), blocks[2].Header.PreviousHash)
+
+	// not split without a size limit
+	blocks = bc.createNextBlocks([]*cb.Envelope{huge, huge}, 0)
+	assert.Len(t, blocks, 1)
+	assert.Equal(t, previous+1, blocks[0].Header.Number)
+}
+
+func TestMaxBlockSize(t *testing.T) {
+	env := &cb.Envelope{Payload: make([]byte, 1000)}
+	bound := maxBlockSize(env)
+
+	for _, number := range []uint64{0, 1, 1000, math.MaxUint64} {
+		block := cb.NewBlock(number, make([]byte, 32))
+		block.Data = &cb.BlockData{Data: [][]byte{utils.MarshalOrPanic(env)}}
+		block.Header.DataHash = block.Data.Hash()
+		assert.True(t, uint64(len(utils.MarshalOrPanic(block))) <= bound)
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateRaftMetadata(t *testing.T) {
+	original := &etcdraft.BlockMetadata{
+		Consenters:      map[uint64]*etcdraft.Consenter{1: {Host: "localhost", Port: 7050}},
+		NextConsenterId: 2,
+		RaftIndex:       5,
+	}
+
+	c := &Chain{}
+	c.blockMetadata.Store(original)
+
+	block := &common.Block{Header: &common.BlockHeader{Number: 3}}
+	m := c.updateRaftMetadata(c.raftMetadata(), block, 6)
+
+	updated := c.raftMetadata()
+	assert.Equal(t, uint64(6), updated.RaftIndex)
+	assert.Len(t, updated.Consenters, 1)
+	assert.True(t, proto.Equal(original.Consenters[1], updated.Consenters[1]))
+	assert.Equal(t, original.NextConsenterId, updated.NextConsenterId)
+	assert.Empty(t, updated.StateHash)
+	assert.Equal(t, utils.MarshalOrPanic(updated), m)
+
+	// previously loaded metadata is left untouched
+	assert.Equal(t, uint64(5), original.RaftIndex)
+
+	// fields unknown to this node are carried over
+	original.XXX_unrecognized = []byte{0xf8, 0x01, 0x01} // field 31, varint 1
+	c.blockMetadata.Store(original)
+	c.updateRaftMetadata(c.raftMetadata(), block, 6)
+	assert.Equal(t, original.XXX_unrecognized, c.raftMetadata().XXX_unrecognized)
+
+	// the state hash rolls over the previous one once enabled
+	c.stateHash = true
+	c.updateRaftMetadata(c.raftMetadata(), block, 7)
+	assert.Equal(t, NextStateHash(nil, 7, block.Header), c.raftMetadata().StateHash)
+	c.updateRaftMetadata(c.raftMetadata(), block, 8)
+	assert.Equal(t, NextStateHash(NextStateHash(nil, 7, block.Header), 8, block.Header), c.raftMetadata().StateHash)
+
+	// the provenance stamped by the proposer is carried over once enabled
+	proposed := common.NewBlock(4, nil)
+	stampProvenance(proposed, &etcdraft.BlockProvenance{RaftTerm: 2, Proposer: 1})
+	c.updateRaftMetadata(c.raftMetadata(), proposed, 9)
+	assert.Nil(t, c.raftMetadata().Provenance)
+	c.blockProvenance = true
+	c.updateRaftMetadata(c.raftMetadata(), proposed, 10)
+	assert.True(t, proto.Equal(&etcdraft.BlockProvenance{RaftTerm: 2, Proposer: 1}, c.raftMetadata().Provenance))
+	c.updateRaftMetadata(c.raftMetadata(), block, 11)
+	assert.Nil(t, c.raftMetadata().Provenance)
+
+	// as is the timestamp
+	timestamp := &etcdraft.BlockTimestamp{Time: 100, Proposer: 1, Signature: []byte("signature")}
+	stampTimestamp(proposed, timestamp)
+	c.updateRaftMetadata(c.raftMetadata(), proposed, 12)
+	assert.Nil(t, c.raftMetadata().Timestamp)
+	c.blockTimestamp = true
+	c.updateRaftMetadata(c.raftMetadata(), proposed, 13)
+	assert.True(t, proto.Equal(timestamp, c.raftMetadata().Timestamp))
+	assert.NotNil(t, c.raftMetadata().Provenance)
+}
+
+// lockedBlockMetadata guards BlockMetadata with a RWMutex and updates it in
+// place, the way the chain used to. It serves as a baseline for benchmarks.
+type lockedBlockMetadata struct {
+	sync.RWMutex
+	m *etcdraft.BlockMetadata
+}
+
+func benchmarkMetadata() *etcdraft.BlockMetadata {
+	consenters := map[uint64]*etcdraft.Consenter{}
+	for i := uint64(1); i <= 5; i++ {
+		consenters[i] = &etcdraft.Consenter{
+			Host:          "orderer.example.com",
+			Port:          7050,
+			ClientTlsCert: make([]byte, 1024),
+			ServerTlsCert: make([]byte, 1024),
+		}
+	}
+	return &etcdraft.BlockMetadata{Consenters: consenters, NextConsenterId: 6}
+}
+
+var benchmarkBlock = &common.Block{Header: &common.BlockHeader{Number: 1}}
+
+// BenchmarkBlockMetadataWrite measures the cost of committing blocks while
+// concurrent readers, e.g. config validation, access the BlockMetadata.
+func BenchmarkBlockMetadataWrite(b *testing.B) {
+	for _, readers := range []int{0, 4, 16} {
+		b.Run(fmt.Sprintf("RWMutex/readers=%d", readers), func(b *testing.B) {
+			l := &lockedBlockMetadata{m: benchmarkMetadata()}
+			read := func() {
+				l.RLock()
+				_, _ = ComputeMembershipChanges(l.m, nil)
+				l.RUnlock()
+			}
+			write := func(index uint64) []byte {
+				l.Lock()
+				l.m.RaftIndex = index
+				m := utils.MarshalOrPanic(l.m)
+				l.Unlock()
+				return m
+			}
+			runMetadataBenchmark(b, readers, read, write)
+		})
+
+		b.Run(fmt.Sprintf("AtomicValue/readers=%d", readers), func(b *testing.B) {
+			c := &Chain{}
+			c.blockMetadata.Store(benchmarkMetadata())
+			read := func() {
+				_, _ = ComputeMembershipChanges(c.raftMetadata(), nil)
+			}
+			write := func(index uint64) []byte {
+				return c.updateRaftMetadata(c.raftMetadata(), benchmarkBlock, index)
+			}
+			runMetadataBenchmark(b, readers, read, write)
+		})
+	}
+}
+
+func runMetadataBenchmark(b *testing.B, readers int, read func(), write func(uint64) []byte) {
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(readers)
+	for i := 0; i < readers; i++ {
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					read()
+				}
+			}
+		}()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if m := write(uint64(i)); len(m) == 0 {
+			b.Fatal("empty block metadata")
+		}
+	}
+	b.StopTimer()
+
+	close(stop)
+	wg.Wait()
+}
+
+// BenchmarkBlockMetadataRead measures the cost of reading the BlockMetadata
+// while blocks are being committed at a high rate.
+func BenchmarkBlockMetadataRead(b *testing.B) {
+	b.Run("RWMutex", func(b *testing.B) {
+		l := &lockedBlockMetadata{m: benchmarkMetadata()}
+		stop := startWriter(func(index uint64) {
+			l.Lock()
+			l.m.RaftIndex = index
+			proto.Marshal(l.m)
+			l.Unlock()
+		})
+		defer stop()
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				l.RLock()
+				_ = len(l.m.Consenters)
+				l.RUnlock()
+			}
+		})
+	})
+
+	b.Run("AtomicValue", func(b *testing.B) {
+		c := &Chain{}
+		c.blockMetadata.Store(benchmarkMetadata())
+		stop := startWriter(func(index uint64) {
+			c.updateRaftMetadata(c.raftMetadata(), benchmarkBlock, index)
+		})
+		defer stop()
+
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				_ = len(c.raftMetadata().Consenters)
+			}
+		})
+	})
+}
+
+func startWriter(write func(uint64)) (stop func()) {
+	stopC := make(chan struct{})
+	doneC := make(chan struct{})
+	go func() {
+		defer close(doneC)
+		for i := uint64(0); ; i++ {
+			select {
+			case <-stopC:
+				return
+			default:
+				write(i)
+			}
+		}
+	}()
+	return func() {
+		close(stopC)
+		<-doneC
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/pkg/fileutil"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// stagedBlockSuffix is the suffix of the files holding staged blocks.
+const stagedBlockSuffix = ".block"
+
+// blockStager keeps the blocks of raft entries out of the WAL. When staging
+// is enabled, the block of every entry is staged in a file of its own and
+// the WAL only holds a reference to it, so that the WAL does not retain a
+// copy of every block held by the ledger. A staged block is removed once the
+// ledger holds it, after which the entries referencing it are restored from
+// the ledger.
+//
+// Entries carrying blocks and references can be mixed in the WAL, hence
+// staging can be enabled at any time. It can be disabled as long as the
+// blocks referenced by the WAL are in the ledger, as staged blocks are
+// only read from the staging directory if it is set.
+type blockStager struct {
+	logger *flogging.FabricLogger
+	dir    string                            // blocks are not staged if it is empty
+	block  func(number uint64) *common.Block // retrieves blocks from the ledger
+}
+
+func newBlockStager(logger *flogging.FabricLogger, dir string, block func(number uint64) *common.Block) (*blockStager, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, errors.Errorf("failed to mkdir '%s' for staged blocks: %s", dir, err)
+		}
+	}
+	return &blockStager{logger: logger, dir: dir, block: block}, nil
+}
+
+// entryBlockReference returns the block reference carried by the
+// data of a normal raft entry, or nil if it does not carry one.
+func entryBlockReference(data []byte) *etcdraft.BlockReference {
+	ref := &etcdraft.BlockReference{}
+	if err := proto.Unmarshal(data, ref); err != nil || len(ref.HeaderHash) == 0 {
+		return nil
+	}
+	return ref
+}
+
+// entryBlock returns the block carried by the given entry, or nil if
+// the entry carries something else, e.g. a marker or a reference.
+func entryBlock(entry raftpb.Entry) *common.Block {
+	if entry.Type != raftpb.EntryNormal || len(entry.Data) == 0 {
+		return nil
+	}
+	if entryMarker(entry.Data) != nil || entryBlockReference(entry.Data) != nil {
+		return nil
+	}
+	block, err := utils.UnmarshalBlock(entry.Data)
+	if err != nil || block.Header == nil {
+		return nil
+	}
+	return block
+}
+
+// walEntries stages the blocks of the given entries, and returns the entries
+// to be saved in the WAL in their place, which reference the staged blocks.
+// The given entries are not modified.
+func (bs *blockStager) walEntries(entries []raftpb.Entry) ([]raftpb.Entry, error) {
+	if bs == nil || bs.dir == "" {
+		return entries, nil
+	}
+
+	var walEntries []raftpb.Entry
+	for i := range entries {
+		block := entryBlock(entries[i])
+		if block == nil {
+			continue
+		}
+
+		ref := &etcdraft.BlockReference{Number: block.Header.Number, HeaderHash: block.Header.Hash()}
+		if err := bs.stage(ref, entries[i].Data); err != nil {
+			return nil, err
+		}
+
+		if walEntries == nil {
+			walEntries = make([]raftpb.Entry, len(entries))
+			copy(walEntries, entries)
+		}
+		walEntries[i].Data = utils.MarshalOrPanic(ref)
+	}
+
+	if walEntries == nil {
+		return entries, nil
+	}
+
+	dir, err := fileutil.OpenDir(bs.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open staging directory")
+	}
+	defer dir.Close()
+	if err := fileutil.Fsync(dir); err != nil {
+		return nil, errors.Wrap(err, "failed to sync staging directory")
+	}
+	return walEntries, nil
+}
+
+// stage writes the given block data to the file of the referenced block.
+func (bs *blockStager) stage(ref *etcdraft.BlockReference, data []byte) error {
+	path := bs.path(ref)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return errors.Wrapf(err, "failed to stage block %d", ref.Number)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to stage block %d", ref.Number)
+	}
+	if err := fileutil.Fsync(f); err != nil {
+		f.Close()
+		return errors.Wrapf(err, "failed to sync staged block %d", ref.Number)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close staged block %d", ref.Number)
+	}
+	return errors.Wrapf(os.Rename(tmp, path), "failed to stage block %d", ref.Number)
+}
+
+// restoreEntries replaces the references among the given entries,
+// read from the WAL, with the data of the blocks they reference.
+func (bs *blockStager) restoreEntries(entries []raftpb.Entry) error {
+	var restored int
+	for i := range entries {
+		if entries[i].Type != raftpb.EntryNormal || len(entries[i].Data) == 0 {
+			continue
+		}
+		ref := entryBlockReference(entries[i].Data)
+		if ref == nil {
+			continue
+		}
+		if bs == nil {
+			return errors.Errorf("raft entry %d references block %d, but blocks are not restored", entries[i].Index, ref.Number)
+		}
+
+		data, err := bs.load(ref)
+		if err != nil {
+			return errors.WithMessage(err, fmt.Sprintf("failed to restore block %d of raft entry %d", ref.Number, entries[i].Index))
+		}
+		entries[i].Data = data
+		restored++
+	}
+
+	if restored > 0 {
+		bs.logger.Infof("Restored %d blocks referenced by the WAL", restored)
+	}
+	return nil
+}
+
+// load returns the data of the referenced block, from the staging
+// directory if it is still staged, or from the ledger otherwise.
+func (bs *blockStager) load(ref *etcdraft.BlockReference) ([]byte, error) {
+	if bs.dir != "" {
+		data, err := ioutil.ReadFile(bs.path(ref))
+		switch {
+		case err == nil:
+			block, err := utils.UnmarshalBlock(data)
+			if err != nil || block.Header == nil || !bytes.Equal(block.Header.Hash(), ref.HeaderHash) {
+				return nil, errors.Errorf("staged block %s is corrupt", bs.path(ref))
+			}
+			return data, nil
+		case !os.IsNotExist(err):
+			return nil, errors.Wrap(err, "failed to read staged block")
+		}
+	}
+
+	block := bs.block(ref.Number)
+	if block == nil || block.Header == nil || !bytes.Equal(block.Header.Hash(), ref.HeaderHash) {
+		if bs.dir == "" {
+			return nil, errors.Errorf("block is not in the ledger, and the staging directory is not set")
+		}
+		return nil, errors.Errorf("block is neither staged in %s nor in the ledger", bs.dir)
+	}
+
+	// The block was proposed with the raft metadata stamped by the proposer,
+	// e.g. its provenance and timestamp, which the ledger block holds along
+	// with the signatures. The other metadata is only added by the ledger.
+	restored := &common.Block{
+		Header:   block.Header,
+		Data:     block.Data,
+		Metadata: common.NewBlock(0, nil).Metadata,
+	}
+	for _, i := range []common.BlockMetadataIndex{common.BlockMetadataIndex_SIGNATURES, common.BlockMetadataIndex_ORDERER} {
+		if int(i) < len(block.Metadata.GetMetadata()) {
+			restored.Metadata.Metadata[i] = block.Metadata.Metadata[i]
+		}
+	}
+	return utils.MarshalOrPanic(restored), nil
+}
+
+// prune removes the staged blocks preceding the given block number, which
+// is handed to the ledger only once the ledger holds the blocks before it.
+func (bs *blockStager) prune(number uint64) {
+	if bs == nil || bs.dir == "" {
+		return
+	}
+
+	files, err := fileutil.ReadDir(bs.dir)
+	if err != nil {
+		bs.logger.Errorf("Failed to read staging directory %s: %s", bs.dir, err)
+		return
+	}
+
+	for _, f := range files {
+		// blocks which were being staged when the node crashed are left behind as temporary files
+		if !strings.HasSuffix(strings.TrimSuffix(f, ".tmp"), stagedBlockSuffix) {
+			continue
+		}
+		var staged uint64
+		if _, err := fmt.Sscanf(f, "%016x-", &staged); err != nil || staged >= number {
+			continue
+		}
+		if err := os.Remove(filepath.Join(bs.dir, f)); err != nil {
+			bs.logger.Errorf("Failed to remove staged block %s: %s", f, err)
+		}
+	}
+}
+
+func (bs *blockStager) path(ref *etcdraft.BlockReference) string {
+	return filepath.Join(bs.dir, fmt.Sprintf("%016x-%x%s", ref.Number, ref.HeaderHash, stagedBlockSuffix))
+}
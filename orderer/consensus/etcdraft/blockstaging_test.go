@@ -0,0 +1,186 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestBlockStager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "staging-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	block := common.NewBlock(5, []byte{1, 2, 3})
+	block.Data.Data = [][]byte{{4, 5, 6}}
+	block.Header.DataHash = block.Data.Hash()
+	stampProvenance(block, &etcdraft.BlockProvenance{RaftTerm: 2, Proposer: 1})
+
+	// the ledger holds the block along with the metadata it added
+	var ledger []*common.Block
+	getBlock := func(number uint64) *common.Block {
+		if number >= uint64(len(ledger)) {
+			return nil
+		}
+		return ledger[number]
+	}
+
+	stager, err := newBlockStager(flogging.MustGetLogger("test"), filepath.Join(dir, "mychannel"), getBlock)
+	require.NoError(t, err)
+
+	entries := []raftpb.Entry{
+		{Index: 1, Type: raftpb.EntryConfChange, Data: utils.MarshalOrPanic(&raftpb.ConfChange{NodeID: 2})},
+		{Index: 2, Type: raftpb.EntryNormal},
+		{Index: 3, Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(block)},
+		{Index: 4, Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(&etcdraft.Marker{Type: etcdraft.Marker_PAUSE, Proposer: 1})},
+	}
+	original := make([]raftpb.Entry, len(entries))
+	copy(original, entries)
+
+	walEntries, err := stager.walEntries(entries)
+	require.NoError(t, err)
+	assert.Equal(t, original, entries, "the given entries must not be modified")
+	for _, i := range []int{0, 1, 3} {
+		assert.Equal(t, entries[i], walEntries[i])
+	}
+	ref := entryBlockReference(walEntries[2].Data)
+	require.NotNil(t, ref)
+	assert.Equal(t, uint64(5), ref.Number)
+	assert.Equal(t, block.Header.Hash(), ref.HeaderHash)
+	assert.Nil(t, entryBlock(walEntries[2]))
+	assert.Nil(t, entryMarker(walEntries[2].Data))
+	assert.FileExists(t, stager.path(ref))
+
+	t.Run("restored from the staging directory", func(t *testing.T) {
+		restored := make([]raftpb.Entry, len(walEntries))
+		copy(restored, walEntries)
+		require.NoError(t, stager.restoreEntries(restored))
+		assert.Equal(t, original, restored)
+	})
+
+	t.Run("not staged and not in the ledger", func(t *testing.T) {
+		noStaging := &blockStager{logger: stager.logger, block: getBlock}
+		restored := make([]raftpb.Entry, len(walEntries))
+		copy(restored, walEntries)
+		err := noStaging.restoreEntries(restored)
+		assert.EqualError(t, err, "failed to restore block 5 of raft entry 3: block is not in the ledger, and the staging directory is not set")
+
+		var nilStager *blockStager
+		err = nilStager.restoreEntries(restored)
+		assert.EqualError(t, err, "raft entry 3 references block 5, but blocks are not restored")
+	})
+
+	// the block is pruned once the next block is handed to the ledger
+	stager.prune(5)
+	assert.FileExists(t, stager.path(ref))
+	ordererMetadata := utils.MarshalOrPanic(&common.Metadata{Value: utils.MarshalOrPanic(&etcdraft.BlockMetadata{
+		RaftIndex:  3,
+		Provenance: &etcdraft.BlockProvenance{RaftTerm: 2, Proposer: 1},
+	})})
+	committed := &common.Block{Header: block.Header, Data: block.Data, Metadata: &common.BlockMetadata{Metadata: [][]byte{{1}, {2}, {3}, ordererMetadata}}}
+	ledger = []*common.Block{0: nil, 1: nil, 2: nil, 3: nil, 4: nil, 5: committed}
+	stager.prune(6)
+	_, err = os.Stat(stager.path(ref))
+	assert.True(t, os.IsNotExist(err))
+
+	t.Run("restored from the ledger", func(t *testing.T) {
+		restored := make([]raftpb.Entry, len(walEntries))
+		copy(restored, walEntries)
+		require.NoError(t, stager.restoreEntries(restored))
+		for _, i := range []int{0, 1, 3} {
+			assert.Equal(t, original[i], restored[i])
+		}
+
+		// the signatures and the raft metadata of the ledger block are kept
+		restoredBlock := entryBlock(restored[2])
+		require.NotNil(t, restoredBlock)
+		assert.True(t, proto.Equal(block.Header, restoredBlock.Header))
+		assert.True(t, proto.Equal(block.Data, restoredBlock.Data))
+		assert.Equal(t, [][]byte{{1}, {}, {}, ordererMetadata}, restoredBlock.Metadata.Metadata)
+		raftMetadata, err := blockRaftMetadata(restoredBlock)
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(&etcdraft.BlockProvenance{RaftTerm: 2, Proposer: 1}, raftMetadata.Provenance))
+	})
+
+	t.Run("a different block in the ledger", func(t *testing.T) {
+		ledger[5] = common.NewBlock(5, []byte{7})
+		defer func() { ledger[5] = committed }()
+
+		restored := make([]raftpb.Entry, len(walEntries))
+		copy(restored, walEntries)
+		err := stager.restoreEntries(restored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to restore block 5 of raft entry 3: block is neither staged in")
+	})
+
+	t.Run("corrupt staged block", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(stager.path(ref), []byte{1, 2, 3}, 0640))
+		defer os.Remove(stager.path(ref))
+
+		restored := make([]raftpb.Entry, len(walEntries))
+		copy(restored, walEntries)
+		err := stager.restoreEntries(restored)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is corrupt")
+	})
+
+	t.Run("staging disabled", func(t *testing.T) {
+		noStaging := &blockStager{logger: stager.logger, block: getBlock}
+		walEntries, err := noStaging.walEntries(entries)
+		require.NoError(t, err)
+		assert.Equal(t, entries, walEntries)
+	})
+}
+
+func TestStorageWithBlockStager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lg := flogging.MustGetLogger("test")
+	walDir, snapDir := filepath.Join(dir, "wal"), filepath.Join(dir, "snapshot")
+	stager, err := newBlockStager(lg, filepath.Join(dir, "staging"), func(uint64) *common.Block { return nil })
+	require.NoError(t, err)
+
+	s, err := CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, stager)
+	require.NoError(t, err)
+
+	var entries []raftpb.Entry
+	for i := uint64(1); i <= 3; i++ {
+		entries = append(entries, raftpb.Entry{Index: i, Term: 1, Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(common.NewBlock(i, nil))})
+	}
+	require.NoError(t, s.Store(entries, raftpb.HardState{Term: 1, Commit: 3}, raftpb.Snapshot{}))
+	inMemory, err := s.ram.Entries(1, 4, ^uint64(0))
+	require.NoError(t, err)
+	assert.Equal(t, entries, inMemory, "the memory storage holds the blocks")
+	require.NoError(t, s.Close())
+
+	// the WAL only holds references, which cannot be restored without a stager
+	_, err = CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, nil)
+	assert.EqualError(t, err, "failed to restore blocks referenced by the WAL: raft entry 1 references block 1, but blocks are not restored")
+
+	ram := raft.NewMemoryStorage()
+	s, err = CreateStorage(lg, walDir, snapDir, ram, WALReadAheadNone, stager)
+	require.NoError(t, err)
+	defer s.Close()
+	restored, err := ram.Entries(1, 4, ^uint64(0))
+	require.NoError(t, err)
+	assert.Equal(t, entries, restored)
+}
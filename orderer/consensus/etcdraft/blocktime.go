@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"time"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+)
+
+// DefaultMaxBlockTimeSkew is how far ahead of the local clock the timestamp
+// of a block may be if the MaxBlockTimeSkew of the consenter is not set.
+const DefaultMaxBlockTimeSkew = 30 * time.Second
+
+// Reasons for which the timestamp of a block fails the sanity checks.
+const (
+	// BlockTimeSignature means the timestamp is not
+	// signed by an orderer of the channel.
+	BlockTimeSignature = "signature"
+	// BlockTimeRegression means the timestamp precedes
+	// the timestamp of the previous block.
+	BlockTimeRegression = "regression"
+	// BlockTimeAhead means the timestamp is ahead of the local
+	// clock of the node by more than MaxBlockTimeSkew.
+	BlockTimeAhead = "ahead"
+)
+
+// lastBlockTime returns the time of the given block, if it carries a timestamp.
+func lastBlockTime(block *common.Block) time.Time {
+	timestamp, err := BlockTimestamp(block)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(0, timestamp.Time)
+}
+
+// stampTime records the current time in a block about to be proposed, signed by
+// the node. The time does not precede the timestamps of the blocks before it, as
+// the clock of a previous leader may have been ahead of the local clock.
+func (c *Chain) stampTime(block *common.Block) {
+	now := c.clock.Now()
+	if now.Before(c.lastBlockTime) {
+		now = c.lastBlockTime
+	}
+	timestamp, err := signBlockTimestamp(block.Header, c.raftID, now, c.support)
+	if err != nil {
+		c.logger.Errorf("Failed to timestamp block %d: %s", block.Header.Number, err)
+		return
+	}
+	c.lastBlockTime = now
+	stampTimestamp(block, timestamp)
+}
+
+// checkBlockTime checks the timestamp of a block about to be written, unless
+// the node stamped it itself: it must be signed by an orderer of the channel,
+// must not precede the timestamp of the previous block, and must not be ahead of
+// the local clock by more than MaxBlockTimeSkew. A timestamp may lag behind the
+// local clock, as blocks are written late when the node catches up. The block is
+// committed regardless, hence violations are only reported.
+func (c *Chain) checkBlockTime(block *common.Block) {
+	timestamp, err := BlockTimestamp(block)
+	if err != nil {
+		c.logger.Debugf("Not checking the timestamp of block %d: %s", block.Header.Number, err)
+		return
+	}
+
+	t := time.Unix(0, timestamp.Time)
+	var reason string
+	switch {
+	case timestamp.Proposer == c.raftID:
+		// stamped by this node
+	case c.support.VerifyBlockSignature([]*common.SignedData{BlockTimestampSignedData(block.Header, timestamp)}, nil) != nil:
+		// a timestamp not signed by an orderer is not taken into account
+		c.reportBlockTime(block, timestamp, BlockTimeSignature)
+		return
+	case t.Before(c.lastBlockTime):
+		reason = BlockTimeRegression
+	case t.Sub(c.clock.Now()) > c.opts.MaxBlockTimeSkew:
+		reason = BlockTimeAhead
+	}
+	if reason != "" {
+		c.reportBlockTime(block, timestamp, reason)
+	}
+	if t.After(c.lastBlockTime) {
+		c.lastBlockTime = t
+	}
+}
+
+func (c *Chain) reportBlockTime(block *common.Block, timestamp *etcdraft.BlockTimestamp, reason string) {
+	c.logger.Warnf("Timestamp %s of block %d proposed by node %d fails the sanity checks (%s), the timestamp of the previous block is %s",
+		time.Unix(0, timestamp.Time).UTC().Format(time.RFC3339Nano), block.Header.Number, timestamp.Proposer, reason,
+		c.lastBlockTime.UTC().Format(time.RFC3339Nano))
+	c.Metrics.BlockTimeViolations.With("reason", reason).Add(1)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/orderer/consensus/mocks"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockTime(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	clock := fakeclock.NewFakeClock(start)
+	support := &mocks.FakeConsenterSupport{}
+	support.NewSignatureHeaderReturns(&common.SignatureHeader{Creator: []byte("orderer")}, nil)
+	support.SignReturns([]byte("signature"), nil)
+	violations := &metricsfakes.Counter{}
+	violations.WithReturns(violations)
+
+	c := &Chain{
+		raftID:  1,
+		clock:   clock,
+		support: support,
+		logger:  flogging.MustGetLogger("test"),
+		opts:    Options{MaxBlockTimeSkew: time.Second},
+		Metrics: &Metrics{BlockTimeViolations: violations},
+	}
+
+	timestamped := func(number uint64, proposer uint64, at time.Time) *common.Block {
+		block := common.NewBlock(number, nil)
+		timestamp, err := signBlockTimestamp(block.Header, proposer, at, support)
+		require.NoError(t, err)
+		stampTimestamp(block, timestamp)
+		return block
+	}
+	timeOf := func(block *common.Block) time.Time {
+		timestamp, err := BlockTimestamp(block)
+		require.NoError(t, err)
+		return time.Unix(0, timestamp.Time)
+	}
+	expectViolations := func(reasons ...string) {
+		t.Helper()
+		require.Equal(t, len(reasons), violations.AddCallCount())
+		for i, reason := range reasons {
+			assert.Equal(t, []string{"reason", reason}, violations.WithArgsForCall(i))
+		}
+	}
+
+	// the leader stamps the time of its clock
+	block := common.NewBlock(1, nil)
+	c.stampTime(block)
+	assert.Equal(t, start, timeOf(block))
+	assert.Equal(t, 1, support.SignCallCount())
+	c.checkBlockTime(block)
+	assert.Equal(t, 0, support.VerifyBlockSignatureCallCount())
+
+	// timestamps of other leaders are checked
+	c.checkBlockTime(timestamped(2, 2, start.Add(time.Second)))
+	assert.Equal(t, 1, support.VerifyBlockSignatureCallCount())
+	signedData, config := support.VerifyBlockSignatureArgsForCall(0)
+	assert.Nil(t, config)
+	assert.Equal(t, []byte("orderer"), signedData[0].Identity)
+	assert.Equal(t, []byte("signature"), signedData[0].Signature)
+	expectViolations()
+
+	// a leader does not stamp a time preceding the timestamps of the previous blocks
+	block = common.NewBlock(3, nil)
+	c.stampTime(block)
+	assert.Equal(t, start.Add(time.Second), timeOf(block))
+
+	c.checkBlockTime(timestamped(4, 2, start.Add(time.Second/2)))
+	expectViolations(BlockTimeRegression)
+
+	c.checkBlockTime(timestamped(5, 2, start.Add(3*time.Second)))
+	expectViolations(BlockTimeRegression, BlockTimeAhead)
+	clock.Increment(3 * time.Second)
+	c.checkBlockTime(timestamped(6, 2, start.Add(4*time.Second)))
+	expectViolations(BlockTimeRegression, BlockTimeAhead)
+
+	// timestamps not signed by an orderer are reported, and not taken into account
+	support.VerifyBlockSignatureReturns(errors.New("not signed by an orderer"))
+	c.checkBlockTime(timestamped(7, 2, start.Add(time.Hour)))
+	expectViolations(BlockTimeRegression, BlockTimeAhead, BlockTimeSignature)
+	assert.Equal(t, start.Add(4*time.Second), c.lastBlockTime)
+
+	// blocks without timestamps are not checked
+	c.checkBlockTime(common.NewBlock(8, nil))
+	expectViolations(BlockTimeRegression, BlockTimeAhead, BlockTimeSignature)
+
+	// the timestamps of the previous blocks are read from the last block on restart
+	assert.Equal(t, time.Time{}, lastBlockTime(common.NewBlock(0, nil)))
+	assert.Equal(t, start, lastBlockTime(timestamped(0, 1, start)))
+
+	// blocks are proposed without timestamps if signing fails
+	support.SignReturns(nil, errors.New("HSM is unavailable"))
+	block = common.NewBlock(9, nil)
+	c.stampTime(block)
+	_, err := BlockTimestamp(block)
+	assert.EqualError(t, err, "block 9 carries no timestamp")
+
+}
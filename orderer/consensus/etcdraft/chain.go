@@ -7,9 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package etcdraft
 
 import (
+	"bytes"
 	"context"
 	"encoding/pem"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -18,8 +22,10 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
 	"github.com/hyperledger/fabric/orderer/consensus/migration"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
@@ -55,9 +61,36 @@ const (
 	// period of time.
 	DefaultEvictionSuspicion = time.Minute * 10
 
+	// DefaultMaxCommitBacklog is the number of raft entries committed but
+	// not yet written to the ledger, at which the chain stops accepting
+	// transactions.
+	DefaultMaxCommitBacklog = 1000
+
+	// DefaultMaxReplayEntries is the number of entries committed to the WAL
+	// but not to the ledger which a chain writes at a time when it starts.
+	DefaultMaxReplayEntries = 1000
+
 	// DefaultLeaderlessCheckInterval is the interval that a chain checks
 	// its own leadership status.
 	DefaultLeaderlessCheckInterval = time.Second * 10
+
+	// LeaderHintTTL is the time a follower forwards transactions directly
+	// to the leader it last forwarded transactions to, without asking
+	// serveRequest who the leader is.
+	LeaderHintTTL = time.Second
+
+	// DefaultStatusReportInterval is the interval that a chain polls
+	// the status of its raft node and publishes it as metrics.
+	DefaultStatusReportInterval = time.Second * 10
+
+	// CatchUpReconfigureInterval is the number of blocks pulled during
+	// catch-up, after which communication is reconfigured with the
+	// consenters of the config blocks pulled so far.
+	CatchUpReconfigureInterval = 1000
+
+	// DefaultConfChangeMaxAttempts is the number of times a ConfChange is
+	// proposed by the leader before its failure to be applied is reported.
+	DefaultConfChangeMaxAttempts = 5
 )
 
 //go:generate mockery -dir . -name Configurator -case underscore -output ./mocks/
@@ -68,12 +101,22 @@ type Configurator interface {
 	Configure(channel string, newNodes []cluster.RemoteNode)
 }
 
+// Reresolver is implemented by a Configurator which re-resolves the endpoints
+// of remote nodes, and reconnects to the nodes whose endpoints resolve to
+// other addresses, such as the DNS names of Kubernetes services.
+type Reresolver interface {
+	// Reresolve re-resolves the endpoint of the given node of the given
+	// channel, and returns whether it reconnected to the node.
+	Reresolve(channel string, id uint64) (bool, error)
+}
+
 //go:generate counterfeiter -o mocks/mock_rpc.go . RPC
 
 // RPC is used to mock the transport layer in tests.
 type RPC interface {
 	SendConsensus(dest uint64, msg *orderer.ConsensusRequest) error
 	SendSubmit(dest uint64, request *orderer.SubmitRequest) error
+	SendSubmitBatch(dest uint64, batch *orderer.SubmitBatch) error
 }
 
 //go:generate counterfeiter -o mocks/mock_blockpuller.go . BlockPuller
@@ -85,6 +128,45 @@ type BlockPuller interface {
 	Close()
 }
 
+//go:generate counterfeiter -o mocks/mock_archivefetcher.go . ArchiveFetcher
+
+// ArchiveFetcher fetches blocks from an external archive, e.g. object
+// storage, when they can no longer be pulled from other consenters.
+type ArchiveFetcher interface {
+	// Fetch returns a BlockPuller over the blocks held by the referenced archive,
+	// along with the SHA256 hash of the archive content fetched.
+	Fetch(ref *etcdraft.ArchiveReference) (BlockPuller, []byte, error)
+}
+
+//go:generate counterfeiter -o mocks/mock_faultinjector.go . FaultInjector
+
+// FaultInjector allows chaos tests to inject faults into the consensus path.
+// Each hook is invoked right before the corresponding operation takes place,
+// and may delay the operation by blocking, corrupt it by altering its arguments,
+// or crash the node by panicking. Returning an error fails the operation.
+type FaultInjector interface {
+	// Persist is invoked before raft data is written to the WAL.
+	// An error is handled like a failed write, which crashes the node.
+	Persist(entries []raftpb.Entry, hardstate *raftpb.HardState, snapshot *raftpb.Snapshot) error
+	// Send is invoked before a message is sent to another node.
+	// An error drops the message, as if the destination was unreachable.
+	Send(msg *raftpb.Message) error
+	// Apply is invoked before committed entries are applied to the chain.
+	// An error crashes the node.
+	Apply(entries []raftpb.Entry) error
+	// Snapshot is invoked before a snapshot is taken at the given raft index.
+	// An error skips the snapshot.
+	Snapshot(index uint64, data []byte) error
+}
+
+// noFaults is the FaultInjector of chains which are not under chaos testing.
+type noFaults struct{}
+
+func (noFaults) Persist([]raftpb.Entry, *raftpb.HardState, *raftpb.Snapshot) error { return nil }
+func (noFaults) Send(*raftpb.Message) error                                        { return nil }
+func (noFaults) Apply([]raftpb.Entry) error                                        { return nil }
+func (noFaults) Snapshot(uint64, []byte) error                                     { return nil }
+
 // CreateBlockPuller is a function to create BlockPuller on demand.
 // It is passed into chain initializer so that tests could mock this.
 type CreateBlockPuller func() (BlockPuller, error)
@@ -99,8 +181,37 @@ type Options struct {
 	SnapDir      string
 	SnapInterval uint32
 
+	// SnapshotRetention is the number of snapshot files retained in SnapDir,
+	// along with the WAL files they need. Older files are deleted once a
+	// snapshot is taken. MaxSnapshotFiles are retained if it is not set.
+	SnapshotRetention int
+
+	// SnapshotContent is what snapshots hold of the block they are taken at.
+	// Snapshots hold the whole block if it is not set.
+	SnapshotContent SnapshotContent
+
+	// InMemoryStorage keeps the raft data of the chain in memory only,
+	// instead of in WALDir and SnapDir, hence it is lost on restart.
+	// It is meant for development and testing, and cannot resume a chain
+	// from a ledger written by raft.
+	InMemoryStorage bool
+
+	// WALReadAhead is the way the WAL is read ahead when it is replayed.
+	// It is not read ahead if not set.
+	WALReadAhead WALReadAhead
+
+	// WALSync is the way the data appended to the WAL is synced.
+	// It is synced with fdatasync only if not set.
+	WALSync WALSync
+
+	// StagingDir is the directory in which the blocks of raft entries
+	// are staged until the ledger holds them, so that the WAL only holds
+	// references to them. Blocks are saved in the WAL if it is not set.
+	StagingDir string
+
 	// This is configurable mainly for testing purpose. Users are not
-	// expected to alter this. Instead, DefaultSnapshotCatchUpEntries is used.
+	// expected to alter this. Instead, the number of entries is adapted
+	// to the lag of followers observed by the leader.
 	SnapshotCatchUpEntries uint64
 
 	MemoryStorage MemoryStorage
@@ -116,19 +227,273 @@ type Options struct {
 	Metrics       *Metrics
 	Cert          []byte
 
-	EvictionSuspicion   time.Duration
-	LeaderCheckInterval time.Duration
+	EvictionSuspicion    time.Duration
+	LeaderCheckInterval  time.Duration
+	StatusReportInterval time.Duration
+
+	// BlockVerificationInterval is the interval at which a random committed
+	// block is compared with the blocks of the same sequence of the other
+	// consenters. Blocks are not verified if it is not set.
+	BlockVerificationInterval time.Duration
+
+	// MaxBlockInterval is the longest time the leader goes without proposing
+	// a block. If no transactions arrive within it, an empty block is cut, which
+	// gives idle channels a heartbeat. Empty blocks are not cut if it is not set.
+	MaxBlockInterval time.Duration
+
+	// MaxCommitBacklog is the number of raft entries committed but not yet
+	// written to the ledger, e.g. due to a slow disk, at which the chain stops
+	// accepting transactions until the ledger catches up. The backlog is not
+	// limited if it is not set.
+	MaxCommitBacklog uint64
+
+	// MaxInflightBytes is the size of the blocks created by the leader and not
+	// yet committed at which the chain stops accepting transactions until some
+	// of them are committed, so that channels of large transactions do not
+	// exhaust memory within the MaxInflightMsgs blocks they may hold in flight.
+	// The size of the blocks in flight is not limited if it is not set.
+	MaxInflightBytes uint64
+
+	// MaxPendingConfigs is the number of config updates pending on the chain,
+	// i.e. submitted and not yet ordered or in flight, at which further config
+	// updates are rejected with a ConfigPendingError, rather than each stalling
+	// the chain in turn. Config updates are not limited if it is not set.
+	MaxPendingConfigs int
+
+	// MaxReplayEntries is the number of entries committed to the WAL but not to
+	// the ledger which the chain writes to the ledger at a time when it starts,
+	// before the raft node runs, which otherwise hands all of them over at once.
+	// They are left to the raft node if it is not set.
+	MaxReplayEntries uint64
+
+	// ReorderWindow is how long the leader holds a transaction redirected to it,
+	// i.e. forwarded by its origin after the transaction preceding it had been
+	// forwarded to another node, for the preceding transaction to arrive, as the
+	// previous leader may forward it after stepping down. Transactions are thus
+	// ordered as their origin received them across leader changes. Redirected
+	// transactions are not held if it is not set.
+	ReorderWindow time.Duration
+
+	// MaxConsensusMessageBytes is the size of the raft messages received from
+	// the other consenters beyond which they are dropped, along with those
+	// which are malformed. Messages carrying snapshots hold a block, and are
+	// as large. The size of raft messages is not limited if it is not set.
+	MaxConsensusMessageBytes uint64
+
+	// FlightRecorderSize is the number of the most recent raft messages sent and
+	// received by the chain which are recorded, without their entries, for the
+	// postmortems of elections and replication. They are logged if the chain
+	// panics. Raft messages are not recorded if it is not set.
+	FlightRecorderSize int
+
+	// Quotas bound the resources consumed by the chain.
+	Quotas Quotas
+
+	// CommitMarkerPath is the file recording the last block handed to the
+	// ledger along with its raft index, which is reconciled with the ledger
+	// upon startup. The marker is not maintained if it is not set.
+	CommitMarkerPath string
+
+	// ArchiveReference, if set, returns a reference to an external archive
+	// holding the blocks up to (and including) the given block number.
+	// The reference is embedded into snapshots taken by this node, once
+	// the channel has the capability of snapshots referencing archives.
+	ArchiveReference func(blockNum uint64) *etcdraft.ArchiveReference
+	// ArchiveFetcher is used to catch up from an archive referenced by a
+	// snapshot, when blocks cannot be pulled from the cluster.
+	ArchiveFetcher ArchiveFetcher
+
+	// DRSink, if set, is used to stream the committed blocks and the snapshots
+	// of the chain to the DR consenters of the channel, starting with the given
+	// ones, which are updated by config blocks.
+	DRSink       DRSink
+	DRConsenters []*etcdraft.Consenter
+
+	// StateHash enables the rolling state hash in the block metadata.
+	// It is updated by the Options of config blocks.
+	StateHash bool
+
+	// BlockProvenance records the raft term and ID of the leader which
+	// proposed each block in the block metadata. It is updated by the
+	// Options of config blocks.
+	BlockProvenance bool
+
+	// BlockTimestamp records the time the leader created each block at,
+	// signed by the leader, in the block metadata. It is updated by the
+	// Options of config blocks.
+	BlockTimestamp bool
+
+	// MaxBlockTimeSkew is how far ahead of the local clock the timestamp of a
+	// block written by the node may be before it is reported as a violation.
+	MaxBlockTimeSkew time.Duration
+
+	// MaxEnvelopeBytes is the maximum size of the envelopes of normal
+	// transactions, which are rejected with an EnvelopeTooLargeError if
+	// larger. It is not bounded if not set, and is updated by the Options
+	// of config blocks.
+	MaxEnvelopeBytes uint32
+
+	// ProposalForwarding lets raft forward the blocks a leader proposes after
+	// stepping down to the new leader, instead of dropping them. Since such
+	// blocks may no longer extend the chain once committed, blocks which do
+	// not are skipped, and a leader which skips blocks re-synchronizes its
+	// block creation with the ledger.
+	ProposalForwarding bool
+
+	// DisablePreVote and DisableCheckQuorum turn off the respective
+	// raft features, which are otherwise enabled.
+	DisablePreVote     bool
+	DisableCheckQuorum bool
+
+	// TLSPolicy restricts the TLS connections with the other consenters.
+	// It is updated by the Options of config blocks.
+	TLSPolicy cluster.TLSPolicy
+
+	// ReceiptStream enables the receipt stream of the chain, which
+	// publishes the receipts of transactions as their blocks are written.
+	ReceiptStream bool
+
+	// EventStream enables the event stream of the chain, which
+	// publishes its consensus events to gRPC subscribers.
+	EventStream bool
+
+	// RecentBlocks is the number of the blocks most recently committed by the
+	// chain which it keeps in memory, along with their signatures, to serve
+	// deliver clients and the replication of other nodes. No blocks are kept
+	// if it is not set, or if the ConsenterSupport does not observe commits.
+	RecentBlocks int
+
+	// RejectSystemChannelTxs makes the chain of the system channel
+	// reject normal transactions with ErrSystemChannelTransaction, including
+	// those forwarded by other nodes, so that only config transactions, such
+	// as channel creations and config updates, are ordered on it.
+	RejectSystemChannelTxs bool
+
+	// ElectionStormThreshold is the number of elections within the
+	// ElectionStormWindow at which elections are dampened, by multiplying the
+	// election timeout of the node for a cool-down period as long as the window.
+	// Elections are not dampened if either is not set.
+	ElectionStormThreshold int
+	ElectionStormWindow    time.Duration
+
+	// WatchdogTimeout is the time the chain may go without processing any event
+	// before it is reported as wedged, along with the goroutine stacks of the
+	// chains. The chain is not watched if it is not set.
+	WatchdogTimeout time.Duration
+
+	// ConfChangeTimeout is the time a ConfChange may be in flight, during which
+	// transactions are refused, before it is reported as stalled along with the
+	// raft progress of the consenters. The ConfChange keeps being waited on.
+	// ConfChanges are not timed if it is not set.
+	ConfChangeTimeout time.Duration
+
+	// ConfChangeRetryInterval is the time a ConfChange proposed by the leader
+	// may go unapplied before the leader proposes it again, as a ConfChange
+	// proposed while the leadership changes is silently dropped. It is proposed
+	// at most ConfChangeMaxAttempts times, DefaultConfChangeMaxAttempts if it
+	// is not set, after which the failure is reported. ConfChanges are not
+	// retried if ConfChangeRetryInterval is not set.
+	ConfChangeRetryInterval time.Duration
+	ConfChangeMaxAttempts   int
+
+	// SlowConfigThreshold is the time the application of a config block, during
+	// which transactions are not ordered, may take before it is logged as slow
+	// along with the time taken by its phases. DefaultSlowConfigThreshold is used
+	// if it is not set.
+	SlowConfigThreshold time.Duration
+
+	// SnapshotDeferralLatency is the moving average of the time taken to persist
+	// raft data above which snapshots are deferred, since snapshotting contends
+	// for disk I/O with the WAL. A snapshot is deferred for up to MaxSnapshotDeferral,
+	// or DefaultMaxSnapshotDeferral if it is not set, and the compaction following
+	// it is paused in between chunks while the latency stays elevated.
+	// Snapshots are not deferred if SnapshotDeferralLatency is not set.
+	SnapshotDeferralLatency time.Duration
+	MaxSnapshotDeferral     time.Duration
+
+	// DegradedLatency is the latency above which a node, while up, is classified
+	// as degraded: the moving average of the time taken to persist raft data for
+	// this node, and of the time taken to acknowledge appends for the peers of the
+	// leader. Degraded nodes are reported until their latency drops below half of
+	// it. Gray failures are not detected if DegradedLatency is not set.
+	DegradedLatency time.Duration
+
+	// FaultInjector, if set, injects faults into the consensus path.
+	// It is meant for chaos testing only and is never set by the Consenter.
+	FaultInjector FaultInjector
+
+	// Notifier, if set, is notified of leader changes,
+	// membership changes and eviction of this node.
+	Notifier Notifier
+
+	// TrustAuditLog, if set, records the remote nodes the
+	// communication layer is configured with whenever they change.
+	TrustAuditLog *TrustAuditLog
+
+	// OnBlockCommitted, if set, is called with every block once it is handed
+	// to the ledger, so that in-process indexers and exporters need not pull
+	// the blocks of every channel. It is called from the goroutine serving the
+	// chain, hence it must not block. Since the ledger adds the metadata of the
+	// block concurrently, the block is passed without its metadata.
+	OnBlockCommitted func(channel string, block *common.Block)
+
+	// ServeStateHooks, if set, are called with the transitions of the goroutine
+	// serving the requests of the chain between the states of a ServeStateMachine.
+	ServeStateHooks []ServeStateHook
+
+	// FairOrdering orders the transactions submitted from the consenters of the
+	// channel, this node included, by weighted round-robin across them, so that
+	// a consenter receiving a burst of transactions cannot monopolize block space.
+	// IngressShares are the shares of the consenters by endpoint (host:port),
+	// which are 1 for consenters without one.
+	FairOrdering  bool
+	IngressShares map[string]int
+
+	// Features are the versions of the wire features supported by this node,
+	// by feature name, which are advertised to the other consenters. A feature
+	// is used only once every consenter of the channel supports it.
+	Features map[string]uint32
 }
 
 type submit struct {
 	req    *orderer.SubmitRequest
 	leader chan uint64
+	config bool // counted by the config queue until ordered
 }
 
 type gc struct {
-	index uint64
-	state raftpb.ConfState
-	data  []byte
+	index   uint64
+	state   raftpb.ConfState
+	data    []byte                     // of the snapshot, if already serialized
+	block   *common.Block              // of the snapshot otherwise, see captureBlock
+	archive *etcdraft.ArchiveReference // referenced by the snapshot of block, if any
+	doneC   chan<- error               // receives the outcome of the snapshot, if set
+}
+
+// snapshotData returns the data of the snapshot. The block of the snapshot is
+// serialized by the garbage collector, so that new entries are applied while
+// the snapshot is created, rather than after a large block is serialized.
+func (g *gc) snapshotData() []byte {
+	switch {
+	case g.data != nil:
+		return g.data
+	case g.archive != nil:
+		return utils.MarshalOrPanic(&etcdraft.SnapshotData{Block: g.block, Archive: g.archive})
+	default:
+		return utils.MarshalOrPanic(g.block)
+	}
+}
+
+// captureBlock returns a copy of the given written block, to be snapshotted in
+// the background. The header and data of a block are not modified once it is
+// written, hence they are shared, whereas its metadata is copied, as the block
+// writer annotates and signs the block after it is handed to it.
+func captureBlock(block *common.Block) *common.Block {
+	captured := &common.Block{Header: block.Header, Data: block.Data}
+	if block.Metadata != nil {
+		captured.Metadata = &common.BlockMetadata{Metadata: append([][]byte(nil), block.Metadata.Metadata...)}
+	}
+	return captured
 }
 
 // Chain implements consensus.Chain interface.
@@ -137,28 +502,55 @@ type Chain struct {
 
 	rpc RPC
 
-	raftID    uint64
-	channelID string
+	raftID      uint64
+	channelID   string
+	attestation []byte // ConsensusRequest metadata attesting raftID
 
 	lastKnownLeader uint64
-
-	submitC  chan *submit
-	applyC   chan apply
-	observeC chan<- raft.SoftState // Notifies external observer on leader change (passed in optionally as an argument for tests)
-	haltC    chan struct{}         // Signals to goroutines that the chain is halting
-	doneC    chan struct{}         // Closes when the chain halts
-	startC   chan struct{}         // Closes when the node is started
-	snapC    chan *raftpb.Snapshot // Signal to catch up with snapshot
-	gcC      chan *gc              // Signal to take snapshot
-
-	errorCLock sync.RWMutex
-	errorC     chan struct{} // returned by Errored()
-
-	raftMetadataLock     sync.RWMutex
+	lastCommitTime  int64        // UnixNano of the last block write, accessed atomically
+	paused          uint32       // 1 if the chain is paused, accessed atomically
+	catchingUp      uint32       // 1 while catching up with a snapshot, accessed atomically
+	wedged          uint32       // 1 if the watchdog reported the chain as wedged, accessed atomically
+	stalled         uint32       // 1 if the ConfChange in flight was reported as stalled, accessed atomically
+	leaderHint      atomic.Value // *leaderHint of the last forwarded transaction
+
+	submitC   chan *submit
+	applyC    chan apply
+	observeC  chan<- raft.SoftState // Notifies external observer on leader change (passed in optionally as an argument for tests)
+	haltC     chan struct{}         // Signals to goroutines that the chain is halting
+	haltingC  chan struct{}         // Closes when the chain is asked to halt
+	haltOnce  sync.Once             // closes haltingC
+	doneC     chan struct{}         // Closes when the chain halts
+	startC    chan struct{}         // Closes when the node is started
+	snapC     chan *raftpb.Snapshot // Signal to catch up with snapshot
+	gcC       chan *gc              // Signal to take snapshot
+	repairC   chan chan error       // Requests to repair the membership, answered with the outcome
+	markerC   chan *markerRequest   // Requests to propose a marker
+	snapshotC chan *snapshotRequest // Requests to snapshot the last written block
+	probeC    chan struct{}         // Probes of the watchdog, consumed as long as the chain is not wedged
+
+	health *healthLog // transitions signalled by Errored()
+
+	// blockMetadata holds the current *etcdraft.BlockMetadata of the chain.
+	// Stored values are never mutated, so they can be read concurrently
+	// without locking; updates store a modified copy instead.
+	blockMetadata        atomic.Value
 	confChangeInProgress *raftpb.ConfChange
-	justElected          bool // this is true when node has just been elected
-	configInflight       bool // this is true when there is config block or ConfChange in flight
-	blockInflight        int  // number of in flight blocks
+	justElected          bool     // this is true when node has just been elected
+	configInflight       bool     // this is true when there is config block or ConfChange in flight
+	blockInflight        int      // number of in flight blocks
+	envelopesInflight    int      // number of envelopes in the in flight blocks
+	bytesInflight        uint64   // size of the in flight blocks
+	blockSizesInflight   []uint64 // sizes of the in flight blocks, oldest first
+	staleBlocks          bool     // this is true when blocks not extending the chain were skipped
+
+	// pendingBatch, pendingBlocks, pendingEnvelopes and pendingBytes mirror the batch pending in the
+	// block cutter, blockInflight, envelopesInflight and bytesInflight, to be read outside of serveRequest.
+	pendingBatchLock sync.Mutex
+	pendingBatch     blockcutter.PendingBatch
+	pendingBlocks    int
+	pendingEnvelopes int
+	pendingBytes     uint64
 
 	clock clock.Clock // Tests can inject a fake clock
 
@@ -166,12 +558,52 @@ type Chain struct {
 
 	lastBlock    *common.Block
 	appliedIndex uint64
+	// writtenIndex is the appliedIndex, accessed atomically
+	// to compute the commit backlog outside of serveRequest
+	writtenIndex uint64
+	// replayTarget is the raft index the WAL was committed up to when the chain was created
+	replayTarget uint64
+
+	admission  *admissionController
+	applyQuota *quota // bounds the blocks written to the ledger
+
+	confChangeRetrier *confChangeRetrier // retries ConfChanges which are not applied, if set
+
+	drReplicator *drReplicator // streams to the DR consenters, if set
+
+	goroutines *goroutineTracker // of the chain, which exit once it halts
+
+	configQueue *configQueue // of the config updates pending on the chain
+
+	forwardSequencer forwardSequencer // numbers the transactions forwarded on behalf of clients
+	submitSequencer  *submitSequencer // holds redirected transactions, if set
+
+	evictionSuspector *evictionSuspector // checks whether the node was evicted once no leader is known
+
+	serveState *ServeStateMachine // state of serveRequest
+
+	grayFailures *grayFailureDetector // classifies slow nodes as degraded, if set
+	reresolving  sync.Map             // peers whose endpoints are being re-resolved
+
+	trusted []TrustedNode     // remote nodes the communication layer was last configured with
+	orgs    map[uint64]string // organizations of the consenters, as last reported
+
+	tlsPolicy cluster.TLSPolicy // TLS policy of the connections with the other consenters
+
+	maxEnvelopeBytes uint32 // of normal transactions if not zero, accessed atomically
 
 	// needed by snapshotting
 	sizeLimit        uint32 // SnapshotInterval in bytes
-	accDataSize      uint32 // accumulative data size since last snapshot
+	lag              *lagTracker
+	stateHash        bool      // whether the state hash is maintained
+	blockProvenance  bool      // whether the provenance of blocks is recorded
+	blockTimestamp   bool      // whether blocks are timestamped by the leader
+	lastBlockTime    time.Time // latest timestamp stamped or written, which timestamps do not precede
+	leaderTerm       uint64    // raft term this node was last elected leader in
+	accDataSize      uint32    // accumulative data size since last snapshot
 	lastSnapBlockNum uint64
 	confState        raftpb.ConfState // Etcdraft requires ConfState to be persisted within snapshot
+	confNodes        atomic.Value     // nodes of confState, for use outside of serveRequest
 
 	createPuller CreateBlockPuller // func used to create BlockPuller on demand
 
@@ -184,6 +616,27 @@ type Chain struct {
 	Metrics *Metrics
 	logger  *flogging.FabricLogger
 
+	events   eventHistory    // recent events, for support bundles
+	recorder *flightRecorder // recent raft messages, if set
+
+	receipts    *receiptStream // nil unless the receipt stream is enabled
+	eventStream *eventStream   // nil unless the event stream is enabled
+	selfTests   *selfTests     // self-test envelopes awaiting commit
+
+	restartSlot *restartSlot // consenter allowed to restart, replicated by markers
+
+	recentBlocks *recentBlocks // nil unless recent blocks are kept
+
+	rejectNormalMsgs bool // whether normal transactions are rejected, on the system channel
+
+	raftLogLevel *raftLogLevel // level of the etcd/raft logger, if set apart from the chain logger
+
+	features  *featureNegotiator
+	forwarder *submitForwarder // batches transactions forwarded to the leader
+	scheduler *fairScheduler   // nil unless FairOrdering is set
+
+	validationCache *validationCache // of envelopes revalidated in serveRequest
+
 	migrationStatus migration.Status // The consensus-type migration status
 
 	periodicChecker *PeriodicCheck
@@ -198,19 +651,41 @@ func NewChain(
 	f CreateBlockPuller,
 	observeC chan<- raft.SoftState) (*Chain, error) {
 
-	lg := opts.Logger.With("channel", support.ChainID(), "node", opts.RaftID)
+	// The logger is named after the channel, so that its level can be
+	// set for a single channel, e.g. orderer.consensus.etcdraft.<channel>=debug
+	lg := opts.Logger.Named(support.ChainID()).With("channel", support.ChainID(), "node", opts.RaftID)
 
-	fresh := !wal.Exist(opts.WALDir)
-	storage, err := CreateStorage(lg, opts.WALDir, opts.SnapDir, opts.MemoryStorage)
+	stager, err := newBlockStager(lg, opts.StagingDir, support.Block)
 	if err != nil {
-		return nil, errors.Errorf("failed to restore persisted raft data: %s", err)
+		return nil, err
 	}
 
+	fresh := true
+	var storage *RaftStorage
+	if opts.InMemoryStorage {
+		if opts.BlockMetadata.RaftIndex != 0 {
+			return nil, errors.Errorf("in-memory storage cannot resume a chain from a ledger written by raft (raft index %d), "+
+				"the ledger must be removed as well", opts.BlockMetadata.RaftIndex)
+		}
+		lg.Warnf("Raft data is kept in memory only and is lost on restart, this must not be used in production")
+		storage = CreateMemoryStorage(lg, opts.MemoryStorage)
+	} else {
+		fresh = !wal.Exist(opts.WALDir)
+		storage, err = CreateStorage(lg, opts.WALDir, opts.SnapDir, opts.MemoryStorage, opts.WALReadAhead, stager)
+		if err != nil {
+			return nil, errors.Errorf("failed to restore persisted raft data: %s", err)
+		}
+	}
+
+	lag := &lagTracker{}
 	if opts.SnapshotCatchUpEntries == 0 {
 		storage.SnapshotCatchUpEntries = DefaultSnapshotCatchUpEntries
+		storage.catchUpEntries = lag.catchUpEntries
 	} else {
 		storage.SnapshotCatchUpEntries = opts.SnapshotCatchUpEntries
 	}
+	storage.SnapshotRetention = opts.SnapshotRetention
+	storage.WALSync = opts.WALSync
 
 	sizeLimit := opts.SnapInterval
 	if sizeLimit == 0 {
@@ -221,7 +696,10 @@ func NewChain(
 	var snapBlkNum uint64
 	var cc raftpb.ConfState
 	if s := storage.Snapshot(); !raft.IsEmptySnap(s) {
-		b := utils.UnmarshalBlockOrPanic(s.Data)
+		b, _, err := SnapshotBlock(s.Data)
+		if err != nil {
+			return nil, errors.Errorf("failed to read block from snapshot: %s", err)
+		}
 		snapBlkNum = b.Header.Number
 		cc = s.Metadata.ConfState
 	}
@@ -231,25 +709,60 @@ func NewChain(
 		return nil, errors.Errorf("failed to get last block")
 	}
 
+	if opts.CommitMarkerPath != "" {
+		if err := reconcileCommitMarker(lg, opts.CommitMarkerPath, b.Header.Number, opts.BlockMetadata.RaftIndex); err != nil {
+			return nil, errors.Errorf("failed to reconcile commit marker %s with the ledger: %s", opts.CommitMarkerPath, err)
+		}
+	}
+
+	if !fresh {
+		if err := checkLedgerConsistency(lg, opts.MemoryStorage, b, opts.BlockMetadata.RaftIndex); err != nil {
+			return nil, errors.Errorf("WAL %s is inconsistent with the ledger: %s", opts.WALDir, err)
+		}
+	}
+
+	hs, _, err := storage.ram.InitialState()
+	if err != nil {
+		return nil, errors.Errorf("failed to read the hard state of the WAL: %s", err)
+	}
+
 	c := &Chain{
 		configurator:     conf,
 		rpc:              rpc,
 		channelID:        support.ChainID(),
 		raftID:           opts.RaftID,
+		attestation:      attestation(opts.RaftID),
 		submitC:          make(chan *submit),
 		applyC:           make(chan apply),
 		haltC:            make(chan struct{}),
+		haltingC:         make(chan struct{}),
 		doneC:            make(chan struct{}),
 		startC:           make(chan struct{}),
 		snapC:            make(chan *raftpb.Snapshot),
-		errorC:           make(chan struct{}),
+		repairC:          make(chan chan error),
+		markerC:          make(chan *markerRequest),
+		snapshotC:        make(chan *snapshotRequest),
+		probeC:           make(chan struct{}),
+		health:           newHealthLog(opts.Clock),
+		selfTests:        newSelfTests(),
+		restartSlot:      newRestartSlot(),
+		raftLogLevel:     newRaftLogLevel(),
 		gcC:              make(chan *gc),
 		observeC:         observeC,
 		support:          support,
 		fresh:            fresh,
 		appliedIndex:     opts.BlockMetadata.RaftIndex,
+		writtenIndex:     opts.BlockMetadata.RaftIndex,
+		replayTarget:     hs.Commit,
 		lastBlock:        b,
 		sizeLimit:        sizeLimit,
+		lag:              lag,
+		stateHash:        opts.StateHash,
+		blockProvenance:  opts.BlockProvenance,
+		blockTimestamp:   opts.BlockTimestamp,
+		lastBlockTime:    lastBlockTime(b),
+		tlsPolicy:        opts.TLSPolicy,
+		maxEnvelopeBytes: opts.MaxEnvelopeBytes,
 		lastSnapBlockNum: snapBlkNum,
 		confState:        cc,
 		createPuller:     f,
@@ -264,11 +777,112 @@ func NewChain(
 			DataPersistDuration:     opts.Metrics.DataPersistDuration.With("channel", support.ChainID()),
 			NormalProposalsReceived: opts.Metrics.NormalProposalsReceived.With("channel", support.ChainID()),
 			ConfigProposalsReceived: opts.Metrics.ConfigProposalsReceived.With("channel", support.ChainID()),
+			Term:                    opts.Metrics.Term.With("channel", support.ChainID()),
+			CommitIndex:             opts.Metrics.CommitIndex.With("channel", support.ChainID()),
+			AppliedIndex:            opts.Metrics.AppliedIndex.With("channel", support.ChainID()),
+			PeerProgressState:       opts.Metrics.PeerProgressState.With("channel", support.ChainID()),
+			ProposeQueueDepth:       opts.Metrics.ProposeQueueDepth.With("channel", support.ChainID()),
+			ProposeWaitDuration:     opts.Metrics.ProposeWaitDuration.With("channel", support.ChainID()),
+			TrustChangedTime:        opts.Metrics.TrustChangedTime.With("channel", support.ChainID()),
+			IsPaused:                opts.Metrics.IsPaused.With("channel", support.ChainID()),
+			BlockMismatches:         opts.Metrics.BlockMismatches.With("channel", support.ChainID()),
+			CommitBacklog:           opts.Metrics.CommitBacklog.With("channel", support.ChainID()),
+			PeerUnreachable:         opts.Metrics.PeerUnreachable.With("channel", support.ChainID()),
+			PeerReachable:           opts.Metrics.PeerReachable.With("channel", support.ChainID()),
+			QuotaThrottled:          opts.Metrics.QuotaThrottled.With("channel", support.ChainID()),
+			MembershipDrift:         opts.Metrics.MembershipDrift.With("channel", support.ChainID()),
+			Wedged:                  opts.Metrics.Wedged.With("channel", support.ChainID()),
+			ConfChangeStalled:       opts.Metrics.ConfChangeStalled.With("channel", support.ChainID()),
+			ConfigApplyDuration:     opts.Metrics.ConfigApplyDuration.With("channel", support.ChainID()),
+			ConsenterOrg:            opts.Metrics.ConsenterOrg.With("channel", support.ChainID()),
+			SnapshotReclaimedBytes:  opts.Metrics.SnapshotReclaimedBytes.With("channel", support.ChainID()),
+			ValidationCacheHits:     opts.Metrics.ValidationCacheHits.With("channel", support.ChainID()),
+			ValidationCacheMisses:   opts.Metrics.ValidationCacheMisses.With("channel", support.ChainID()),
+
+			WALFsyncs:            opts.Metrics.WALFsyncs.With("channel", support.ChainID()),
+			WALAppendedBytes:     opts.Metrics.WALAppendedBytes.With("channel", support.ChainID()),
+			WALSegmentsCreated:   opts.Metrics.WALSegmentsCreated.With("channel", support.ChainID()),
+			SnapshotFilesWritten: opts.Metrics.SnapshotFilesWritten.With("channel", support.ChainID()),
+			SnapshotsDeferred:    opts.Metrics.SnapshotsDeferred.With("channel", support.ChainID()),
+
+			EvictionSuspected:         opts.Metrics.EvictionSuspected.With("channel", support.ChainID()),
+			EvictionSuspicionDuration: opts.Metrics.EvictionSuspicionDuration.With("channel", support.ChainID()),
+			EvictionsConfirmed:        opts.Metrics.EvictionsConfirmed.With("channel", support.ChainID()),
+			EvictionBlocksPulled:      opts.Metrics.EvictionBlocksPulled.With("channel", support.ChainID()),
+
+			PendingBatchMessages:  opts.Metrics.PendingBatchMessages.With("channel", support.ChainID()),
+			PendingBatchBytes:     opts.Metrics.PendingBatchBytes.With("channel", support.ChainID()),
+			PendingBatchStartTime: opts.Metrics.PendingBatchStartTime.With("channel", support.ChainID()),
+			BlocksInFlight:        opts.Metrics.BlocksInFlight.With("channel", support.ChainID()),
+			BytesInFlight:         opts.Metrics.BytesInFlight.With("channel", support.ChainID()),
+			EstimatedTimeToOrder:  opts.Metrics.EstimatedTimeToOrder.With("channel", support.ChainID()),
+
+			ElectionStorms:        opts.Metrics.ElectionStorms.With("channel", support.ChainID()),
+			ElectionTimeoutFactor: opts.Metrics.ElectionTimeoutFactor.With("channel", support.ChainID()),
+
+			AppendAckLatency: opts.Metrics.AppendAckLatency.With("channel", support.ChainID()),
+			NodeDegraded:     opts.Metrics.NodeDegraded.With("channel", support.ChainID()),
+
+			ConfChangeReproposals:      opts.Metrics.ConfChangeReproposals.With("channel", support.ChainID()),
+			ConfChangeRetriesExhausted: opts.Metrics.ConfChangeRetriesExhausted.With("channel", support.ChainID()),
+
+			EnvelopesRejected: opts.Metrics.EnvelopesRejected.With("channel", support.ChainID()),
+
+			DRReplicationLag: opts.Metrics.DRReplicationLag.With("channel", support.ChainID()),
+			DRSendFailures:   opts.Metrics.DRSendFailures.With("channel", support.ChainID()),
+
+			BlockTimeViolations: opts.Metrics.BlockTimeViolations.With("channel", support.ChainID()),
+
+			Goroutines: opts.Metrics.Goroutines.With("channel", support.ChainID()),
+
+			PendingConfigUpdates: opts.Metrics.PendingConfigUpdates.With("channel", support.ChainID()),
+
+			ReorderHolds: opts.Metrics.ReorderHolds.With("channel", support.ChainID()),
+
+			ConsensusMsgsDropped: opts.Metrics.ConsensusMsgsDropped.With("channel", support.ChainID()),
 		},
 		logger:          lg,
 		opts:            opts,
 		migrationStatus: migration.NewStatusStepper(support.IsSystemChannel(), support.ChainID()), // Needed by consensus-type migration
 	}
+	c.rejectNormalMsgs = opts.RejectSystemChannelTxs && support.IsSystemChannel()
+	c.blockMetadata.Store(opts.BlockMetadata)
+	c.confNodes.Store(cc.Nodes)
+	if opts.ReceiptStream {
+		c.receipts = newReceiptStream(lg)
+	}
+	if opts.EventStream {
+		c.eventStream = newEventStream(lg)
+	}
+	if observer, isObserver := support.(consensus.CommitObserver); isObserver && opts.RecentBlocks > 0 {
+		c.recentBlocks = newRecentBlocks(opts.RecentBlocks)
+		observer.ObserveCommits(c.recentBlocks.put)
+	}
+	c.admission = &admissionController{
+		clock:    c.clock,
+		capacity: c.inflightCapacity,
+		shares:   func() int { return len(c.raftMetadata().Consenters) },
+	}
+	c.applyQuota = newQuota(QuotaAppliedBlocks, opts.Quotas.AppliedBlocksPerSecond, c.clock, c.Metrics.QuotaThrottled)
+	c.grayFailures = newGrayFailureDetector(lg, c.clock, c.raftID, opts.DegradedLatency, c.Metrics, c.notify)
+	maxAttempts := opts.ConfChangeMaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultConfChangeMaxAttempts
+	}
+	now := func() time.Time { return c.clock.Now() }
+	c.serveState = NewServeStateMachine(now, append([]ServeStateHook{c.logServeTransition}, opts.ServeStateHooks...)...)
+	c.goroutines = newGoroutineTracker(c.Metrics.Goroutines)
+	c.configQueue = newConfigQueue(opts.MaxPendingConfigs, c.Metrics.PendingConfigUpdates)
+	c.submitSequencer = newSubmitSequencer(lg, opts.ReorderWindow, c.Metrics.ReorderHolds)
+	c.recorder = newFlightRecorder(opts.FlightRecorderSize)
+	c.confChangeRetrier = newConfChangeRetrier(lg, c.clock, opts.ConfChangeRetryInterval, maxAttempts, c.reproposeConfChange, c.notify, c.Metrics)
+	storage.ReclaimedBytes = c.Metrics.SnapshotReclaimedBytes
+	storage.WALFsyncs = c.Metrics.WALFsyncs
+	storage.WALAppendedBytes = c.Metrics.WALAppendedBytes
+	storage.WALSegmentsCreated = c.Metrics.WALSegmentsCreated
+	storage.SnapshotFilesWritten = c.Metrics.SnapshotFilesWritten
+	snapshots := newSnapshotScheduler(lg, c.clock, opts.SnapshotDeferralLatency, opts.MaxSnapshotDeferral, c.Metrics.SnapshotsDeferred)
+	storage.yield = snapshots.yield
 
 	// DO NOT use Applied option in config, see https://github.com/etcd-io/etcd/issues/10217
 	// We guard against replay of written blocks in `entriesToApply` instead.
@@ -278,17 +892,60 @@ func NewChain(
 		HeartbeatTick:   c.opts.HeartbeatTick,
 		MaxSizePerMsg:   c.opts.MaxSizePerMsg,
 		MaxInflightMsgs: c.opts.MaxInflightMsgs,
-		Logger:          c.logger,
+		Logger:          newRaftLogger(c.logger, c.raftLogLevel),
 		Storage:         c.opts.MemoryStorage,
 		// PreVote prevents reconnected node from disturbing network.
 		// See etcd/raft doc for more details.
-		PreVote:                   true,
-		CheckQuorum:               true,
-		DisableProposalForwarding: true, // This prevents blocks from being accidentally proposed by followers
+		PreVote:                   !c.opts.DisablePreVote,
+		CheckQuorum:               !c.opts.DisableCheckQuorum,
+		DisableProposalForwarding: !c.opts.ProposalForwarding, // This prevents blocks from being accidentally proposed by followers
+	}
+
+	faults := opts.FaultInjector
+	if faults == nil {
+		faults = noFaults{}
+	}
+
+	leading := func() bool {
+		return atomic.LoadUint64(&c.lastKnownLeader) == c.raftID
+	}
+
+	c.drReplicator = newDRReplicator(c.channelID, c.logger, c.clock, opts.DRSink, leading, support.Height, support.Block, c.Metrics)
+	c.drReplicator.setTargets(opts.DRConsenters)
+
+	c.features = &featureNegotiator{
+		logger: c.logger,
+		clock:  c.clock,
+		local:  opts.Features,
+		expiry: featureAdvertisementsMissed * c.leaderCheckInterval(),
+		nodes:  c.remoteConsenters,
+		send: func(to uint64, payload []byte) error {
+			return c.rpc.SendConsensus(to, &orderer.ConsensusRequest{Channel: c.channelID, Payload: payload, Metadata: c.attestation})
+		},
+		goroutines: c.goroutines,
+	}
+
+	if opts.FairOrdering {
+		c.scheduler = newFairScheduler(c.ingressShare, c.submitC, c.doneC)
+	}
+	c.validationCache = newValidationCache(ValidationCacheSize, c.Metrics)
+
+	c.forwarder = &submitForwarder{
+		logger: c.logger,
+		maxBytes: func() uint32 {
+			return c.support.SharedConfig().BatchSize().PreferredMaxBytes
+		},
+		send: func(to uint64, batch *orderer.SubmitBatch) error {
+			return c.rpc.SendSubmitBatch(to, batch)
+		},
+		revalidate: c.revalidate,
+		doneC:      c.doneC,
+		goroutines: c.goroutines,
 	}
 
 	c.Node = &node{
 		chainID:      c.channelID,
+		attestation:  c.attestation,
 		chain:        c,
 		logger:       c.logger,
 		metrics:      c.Metrics,
@@ -297,8 +954,14 @@ func NewChain(
 		config:       config,
 		tickInterval: c.opts.TickInterval,
 		clock:        c.clock,
-		metadata:     c.opts.BlockMetadata,
+		metadata:     opts.BlockMetadata,
+		faults:       faults,
+		tickQuota:    newQuota(QuotaTicks, opts.Quotas.TicksPerSecond, c.clock, c.Metrics.QuotaThrottled),
+		persistQuota: newQuota(QuotaPersistedBytes, float64(opts.Quotas.PersistedBytesPerSecond), c.clock, c.Metrics.QuotaThrottled),
+		dampener:     newElectionDampener(c.logger, c.clock, opts.ElectionStormThreshold, opts.ElectionStormWindow, leading, c.Metrics),
+		snapshots:    snapshots,
 	}
+	c.evictionSuspector = c.newEvictionSuspector()
 
 	return c, nil
 }
@@ -313,9 +976,11 @@ func (c *Chain) MigrationStatus() migration.Status {
 func (c *Chain) Start() {
 	c.logger.Infof("Starting Raft node")
 
-	c.Metrics.ClusterSize.Set(float64(len(c.opts.BlockMetadata.Consenters)))
+	c.Metrics.ClusterSize.Set(float64(len(c.raftMetadata().Consenters)))
+	c.warnLargeCluster(len(c.raftMetadata().Consenters))
 	// all nodes start out as followers
 	c.Metrics.IsLeader.Set(float64(0))
+	c.Metrics.IsPaused.Set(float64(0))
 	if err := c.configureComm(); err != nil {
 		c.logger.Errorf("Failed to start chain, aborting: +%v", err)
 		close(c.doneC)
@@ -327,28 +992,96 @@ func (c *Chain) Start() {
 	if isJoin {
 		isMigration = c.detectMigration()
 	}
+
+	c.goroutines.spawn("gc", c.gc)
+	// The blocks committed to the WAL are written before the raft node starts,
+	// as it would otherwise hand them over to serveRequest all at once.
+	c.replayWAL()
 	c.Node.start(c.fresh, isJoin, isMigration)
 
 	close(c.startC)
-	close(c.errorC)
-
-	go c.gc()
-	go c.serveRequest()
+	c.health.errored()
 
-	es := c.newEvictionSuspector()
-
-	interval := DefaultLeaderlessCheckInterval
-	if c.opts.LeaderCheckInterval != 0 {
-		interval = c.opts.LeaderCheckInterval
+	c.goroutines.spawn("serve", c.serveRequest)
+	if c.drReplicator != nil {
+		c.goroutines.spawn("dr_replicator", func() { c.drReplicator.run(c.doneC) })
 	}
+	c.goroutines.spawn("status_reporter", c.newStatusReporter().run)
+
+	interval := c.leaderCheckInterval()
 
 	c.periodicChecker = &PeriodicCheck{
 		Logger:        c.logger,
-		Report:        es.confirmSuspicion,
+		Report:        c.evictionSuspector.confirmSuspicion,
+		Cleared:       c.evictionSuspector.clearSuspicion,
 		CheckInterval: interval,
 		Condition:     c.suspectEviction,
 	}
 	c.periodicChecker.Run()
+
+	driftChecker := c.newDriftChecker()
+	c.goroutines.spawn("drift_checker", func() { driftChecker.run(interval, c.doneC) })
+	c.goroutines.spawn("feature_negotiator", func() { c.features.run(interval, c.doneC) })
+
+	if c.scheduler != nil {
+		c.goroutines.spawn("fair_scheduler", c.scheduler.run)
+	}
+
+	if c.opts.BlockVerificationInterval > 0 {
+		verifier := c.newBlockVerifier()
+		c.goroutines.spawn("block_verifier", func() { verifier.run(c.opts.BlockVerificationInterval, c.doneC) })
+	}
+
+	if c.opts.WatchdogTimeout > 0 {
+		watchdog := c.newWatchdog()
+		c.goroutines.spawn("watchdog", func() { watchdog.run(c.doneC) })
+	}
+}
+
+func (c *Chain) leaderCheckInterval() time.Duration {
+	if c.opts.LeaderCheckInterval != 0 {
+		return c.opts.LeaderCheckInterval
+	}
+	return DefaultLeaderlessCheckInterval
+}
+
+func (c *Chain) newWatchdog() *watchdog {
+	return &watchdog{
+		logger:  c.logger,
+		clock:   c.clock,
+		timeout: c.opts.WatchdogTimeout,
+		probeC:  c.probeC,
+		catchingUp: func() bool {
+			return atomic.LoadUint32(&c.catchingUp) == 1
+		},
+		wedged: func(stalled time.Duration, stacks string) {
+			c.logger.Errorf("Chain has not processed any event for %s, it may be deadlocked; stacks of the etcdraft goroutines:\n%s", stalled, stacks)
+			atomic.StoreUint32(&c.wedged, 1)
+			c.Metrics.Wedged.Set(1)
+			c.notify(Event{Type: EventChainWedged, Cause: fmt.Sprintf("no event processed for %s", stalled)})
+		},
+		recovered: func() {
+			atomic.StoreUint32(&c.wedged, 0)
+			c.Metrics.Wedged.Set(0)
+		},
+	}
+}
+
+func (c *Chain) newBlockVerifier() *blockVerifier {
+	return &blockVerifier{
+		logger: c.logger,
+		clock:  c.clock,
+		height: c.support.Height,
+		block:  c.support.Block,
+		pullers: func() (map[string]BlockPuller, error) {
+			return endpointPullers(c.createPuller)
+		},
+		mismatch: func(seq uint64, endpoint string) {
+			c.Metrics.BlockMismatches.Add(1)
+			c.notify(Event{Type: EventBlockMismatch, Block: seq, Endpoint: endpoint})
+		},
+		sample: randomSample,
+	}
 }
 
 // detectMigration detects if the orderer restarts right after consensus-type migration,
@@ -391,12 +1124,27 @@ func (c *Chain) detectMigration() bool {
 // Order submits normal type transactions for ordering.
 func (c *Chain) Order(env *common.Envelope, configSeq uint64) error {
 	c.Metrics.NormalProposalsReceived.Add(1)
+	if c.Paused() {
+		return c.reject(RejectReasonPaused, ErrChainPaused)
+	}
+	if c.rejectNormalMsgs {
+		return c.reject(RejectReasonSystemChannel, ErrSystemChannelTransaction)
+	}
+	if err := c.checkEnvelopeSize(env); err != nil {
+		return c.reject(RejectReasonSize, err)
+	}
+	if err := c.checkMessageSize(env); err != nil {
+		return c.reject(RejectReasonSize, err)
+	}
 	return c.Submit(&orderer.SubmitRequest{LastValidationSeq: configSeq, Payload: env, Channel: c.channelID}, 0)
 }
 
 // Configure submits config type transactions for ordering.
 func (c *Chain) Configure(env *common.Envelope, configSeq uint64) error {
 	c.Metrics.ConfigProposalsReceived.Add(1)
+	if err := c.checkMessageSize(env); err != nil {
+		return c.reject(RejectReasonSize, err)
+	}
 	if err := c.checkConfigUpdateValidity(env); err != nil {
 		c.Metrics.ProposalFailures.Add(1)
 		return err
@@ -418,6 +1166,10 @@ func (c *Chain) checkConfigUpdateValidity(ctx *common.Envelope) error {
 
 	switch chdr.Type {
 	case int32(common.HeaderType_ORDERER_TRANSACTION):
+		if c.support.SharedConfig().Capabilities().SystemChannelDeprecation() {
+			return errors.Errorf("channel creation transactions are not supported as the system channel is deprecated, " +
+				"orderers join channels with their genesis block instead")
+		}
 		return nil
 	case int32(common.HeaderType_CONFIG):
 		configUpdate, err := configtx.UnmarshalConfigUpdateFromPayload(payload)
@@ -456,11 +1208,71 @@ func (c *Chain) WaitReady() error {
 	return nil
 }
 
+// Admit returns an error if the chain is saturated, in which case the
+// envelope should be rejected rather than submitted to the chain.
+// Envelopes are admitted at the rate they were recently committed,
+// in addition to a burst of the in-flight capacity of the chain, both
+// split evenly across the consenters of the chain.
+func (c *Chain) Admit() error {
+	if c.backlogged() {
+		return errors.Errorf("chain is behind writing blocks to the ledger, %d committed raft entries are not written yet", c.commitBacklog())
+	}
+	if !c.admission.admit() {
+		return errors.Errorf("chain is saturated, envelopes are admitted by this node at %.2f per second, its share of the commit rate of %.2f per second",
+			c.admission.admissionRate(), c.admission.commitRate())
+	}
+	return nil
+}
+
+// commitBacklog returns the number of raft entries which
+// are committed but not yet written to the ledger.
+func (c *Chain) commitBacklog() uint64 {
+	committed, written := c.Node.committed(), atomic.LoadUint64(&c.writtenIndex)
+	if committed <= written {
+		return 0
+	}
+	return committed - written
+}
+
+// backlogged returns whether the commit backlog reached MaxCommitBacklog.
+func (c *Chain) backlogged() bool {
+	return c.opts.MaxCommitBacklog > 0 && c.commitBacklog() >= c.opts.MaxCommitBacklog
+}
+
+// inflightBytesExceeded returns whether the size of the blocks in flight reached MaxInflightBytes.
+func (c *Chain) inflightBytesExceeded() bool {
+	return c.opts.MaxInflightBytes > 0 && c.bytesInflight >= c.opts.MaxInflightBytes
+}
+
+// inflightCapacity returns the number of envelopes that fit in the in-flight blocks.
+func (c *Chain) inflightCapacity() float64 {
+	maxMessageCount := uint32(1)
+	if batchSize := c.support.SharedConfig().BatchSize(); batchSize != nil && batchSize.MaxMessageCount > 0 {
+		maxMessageCount = batchSize.MaxMessageCount
+	}
+	return float64(c.opts.MaxInflightMsgs) * float64(maxMessageCount)
+}
+
 // Errored returns a channel that closes when the chain stops.
 func (c *Chain) Errored() <-chan struct{} {
-	c.errorCLock.RLock()
-	defer c.errorCLock.RUnlock()
-	return c.errorC
+	return c.health.erroredC()
+}
+
+// HealthTransitions returns the recent transitions of the chain into and out
+// of the errored state signalled by Errored, with a sequence greater than the
+// given one, along with a channel which is closed on the next transition.
+func (c *Chain) HealthTransitions(after uint64) ([]consensus.HealthTransition, <-chan struct{}) {
+	return c.health.since(after)
+}
+
+// RecentBlock returns the committed block with the given number if the chain
+// keeps it in memory, along with a channel which is closed once a later block
+// is kept. The channel is nil if the chain keeps no blocks.
+func (c *Chain) RecentBlock(number uint64) (*common.Block, <-chan struct{}) {
+	if c.recentBlocks == nil {
+		return nil, nil
+	}
+	return c.recentBlocks.get(number)
 }
 
 // Halt stops the chain.
@@ -472,6 +1284,8 @@ func (c *Chain) Halt() {
 		return
 	}
 
+	// Goroutines blocked on a quota give way to the one receiving from haltC
+	c.haltOnce.Do(func() { close(c.haltingC) })
 	select {
 	case c.haltC <- struct{}{}:
 	case <-c.doneC:
@@ -480,6 +1294,278 @@ func (c *Chain) Halt() {
 	<-c.doneC
 }
 
+// ErrChainPaused is returned when transactions are submitted to a paused chain.
+var ErrChainPaused = errors.New("chain is paused")
+
+// ErrSystemChannelTransaction is returned when normal transactions are submitted
+// to the system channel of a node which rejects them.
+var ErrSystemChannelTransaction = errors.New("normal transactions are rejected on the system channel")
+
+// EnvelopeTooLargeError is returned when a normal transaction is submitted
+// whose envelope exceeds the MaxEnvelopeBytes of the channel.
+type EnvelopeTooLargeError struct {
+	Size  int    // of the envelope, in bytes
+	Limit uint32 // MaxEnvelopeBytes of the channel
+}
+
+func (e *EnvelopeTooLargeError) Error() string {
+	return fmt.Sprintf("envelope of %d bytes exceeds the maximum of %d bytes", e.Size, e.Limit)
+}
+
+// MessageSizeExceededError is returned when a transaction is submitted whose
+// envelope does not fit in a block within the MaxSizePerMsg of the channel.
+type MessageSizeExceededError struct {
+	Size  uint64 // of a block holding only the envelope, in bytes
+	Limit uint64 // MaxSizePerMsg of the channel
+}
+
+func (e *MessageSizeExceededError) Error() string {
+	return fmt.Sprintf("a block holding the envelope would be %d bytes, exceeding the raft message size limit (MaxSizePerMsg) of %d bytes", e.Size, e.Limit)
+}
+
+// LeaderError is returned when a transaction cannot be handed to the leader,
+// as there is none or forwarding it failed, along with the endpoint of the
+// leader if it is known, so that clients retry against the leader directly.
+type LeaderError struct {
+	Leader   uint64 // raft ID of the leader, raft.None if there is none
+	Endpoint string // host:port of the leader, if known
+	Err      error
+}
+
+func (e *LeaderError) Error() string {
+	if e.Endpoint == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s; the leader is node %d at %s", e.Err, e.Leader, e.Endpoint)
+}
+
+// Cause returns the error the transaction failed with.
+func (e *LeaderError) Cause() error {
+	return e.Err
+}
+
+// leaderError returns a LeaderError with the given leader and its endpoint.
+func (c *Chain) leaderError(lead uint64, err error) error {
+	leaderErr := &LeaderError{Leader: lead, Err: err}
+	if consenter, exists := c.raftMetadata().Consenters[lead]; exists && lead != raft.None {
+		leaderErr.Endpoint = fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+	}
+	return leaderErr
+}
+
+// Reasons for which transactions are rejected before they are ordered.
+const (
+	RejectReasonPaused        = "paused"
+	RejectReasonSystemChannel = "system_channel"
+	RejectReasonSize          = "size"
+	RejectReasonConfigPending = "config_pending"
+)
+
+// reject records the rejection of a transaction for the given reason,
+// and returns the given error.
+func (c *Chain) reject(reason string, err error) error {
+	c.Metrics.ProposalFailures.Add(1)
+	c.Metrics.EnvelopesRejected.With("reason", reason).Add(1)
+	return err
+}
+
+// dequeueConfig stops counting the given request among the pending
+// config updates, if it was counted.
+func (c *Chain) dequeueConfig(s *submit) {
+	if s.config {
+		c.configQueue.dequeue()
+	}
+}
+
+// setConfigInflight records whether a config block or ConfChange is in flight.
+func (c *Chain) setConfigInflight(inflight bool) {
+	c.configInflight = inflight
+	c.configQueue.setInflight(inflight)
+}
+
+// checkEnvelopeSize returns an EnvelopeTooLargeError if the given
+// envelope exceeds the MaxEnvelopeBytes of the channel.
+func (c *Chain) checkEnvelopeSize(env *common.Envelope) error {
+	limit := atomic.LoadUint32(&c.maxEnvelopeBytes)
+	if limit == 0 {
+		return nil
+	}
+	if size := proto.Size(env); size > int(limit) {
+		return &EnvelopeTooLargeError{Size: size, Limit: limit}
+	}
+	return nil
+}
+
+// checkMessageSize returns a MessageSizeExceededError if a block holding only the
+// given envelope would exceed the MaxSizePerMsg of the channel, as raft could not
+// replicate it.
+func (c *Chain) checkMessageSize(env *common.Envelope) error {
+	if c.opts.MaxSizePerMsg == 0 {
+		return nil
+	}
+	if size := maxBlockSize(env); size > c.opts.MaxSizePerMsg {
+		return &MessageSizeExceededError{Size: size, Limit: c.opts.MaxSizePerMsg}
+	}
+	return nil
+}
+
+// Pause makes the chain reject transactions with ErrChainPaused, hence stops
+// blocks from being created out of them, while the node keeps participating in
+// raft. Config transactions are still accepted, so that the channel can be
+// reconfigured while it is paused. Pausing a paused chain has no effect.
+func (c *Chain) Pause() {
+	if atomic.CompareAndSwapUint32(&c.paused, 0, 1) {
+		c.Metrics.IsPaused.Set(1)
+		c.logger.Infof("Chain is paused, rejecting transactions")
+	}
+}
+
+// Resume makes a paused chain accept transactions again.
+// Resuming a chain which is not paused has no effect.
+func (c *Chain) Resume() {
+	if atomic.CompareAndSwapUint32(&c.paused, 1, 0) {
+		c.Metrics.IsPaused.Set(0)
+		c.logger.Infof("Chain is resumed, accepting transactions")
+	}
+}
+
+// Paused returns whether the chain is paused.
+func (c *Chain) Paused() bool {
+	return atomic.LoadUint32(&c.paused) == 1
+}
+
+// ChainInfo describes the participation of this node in a chain.
+type ChainInfo struct {
+	Channel        string       `json:"channel"`
+	RaftID         uint64       `json:"raft_id"`
+	Role           string       `json:"role"`
+	Paused         bool         `json:"paused"`
+	Height         uint64       `json:"height"`
+	LastCommitTime time.Time    `json:"last_commit_time"`
+	PendingBatch   PendingBatch `json:"pending_batch"`
+	Wedged         bool         `json:"wedged"`
+	// ConfChangeStalled is whether the ConfChange in flight was not applied within the ConfChange timeout.
+	ConfChangeStalled bool `json:"conf_change_stalled"`
+	// Features are the versions of the wire features negotiated with the other consenters.
+	Features map[string]uint32 `json:"features"`
+	// Orgs are the MSP IDs of the organizations the consenters are bound to, by raft ID.
+	Orgs map[uint64]string `json:"orgs,omitempty"`
+	// Degraded are the nodes classified as up but too slow, which only the leader tracks other than itself.
+	Degraded []DegradedNode `json:"degraded,omitempty"`
+	// RestartSlot is the consenter allowed to restart, as known to this node.
+	RestartSlot *RestartSlot `json:"restart_slot,omitempty"`
+	// ServeState is the state of the goroutine serving the requests of the chain, if it runs.
+	ServeState string `json:"serve_state,omitempty"`
+	// EvictionSuspicion is the state of the eviction suspector of the chain.
+	EvictionSuspicion *EvictionSuspicion `json:"eviction_suspicion,omitempty"`
+	// Replay is how far the chain got replaying the entries committed to its WAL.
+	Replay ReplayProgress `json:"replay"`
+}
+
+// PendingBatch describes the transactions ordered by the leader and waiting
+// to be cut into a block, which wait on the BatchTimeout unless the batch
+// fills up, along with the blocks created by the leader and not yet committed,
+// which wait on consensus.
+type PendingBatch struct {
+	Messages       int    `json:"messages"`
+	Bytes          uint32 `json:"bytes"`
+	Age            string `json:"age"` // since the oldest transaction was ordered
+	BlocksInFlight int    `json:"blocks_in_flight"`
+	BytesInFlight  uint64 `json:"bytes_in_flight"`
+}
+
+// Info returns the raft ID and the current role of this node in the chain,
+// along with the height of the chain and the time its last block was written.
+func (c *Chain) Info() ChainInfo {
+	info := ChainInfo{
+		Channel: c.channelID,
+		RaftID:  c.raftID,
+		Role:    "stopped",
+		Paused:  c.Paused(),
+		Height:  c.support.Height(),
+
+		PendingBatch: c.PendingBatch(),
+		Wedged:       atomic.LoadUint32(&c.wedged) == 1,
+		Features:     c.features.negotiate(),
+		Orgs:         consenterOrgs(c.raftMetadata().Consenters),
+		Degraded:     c.grayFailures.degraded(),
+		RestartSlot:  c.RestartSlot(),
+
+		ConfChangeStalled: atomic.LoadUint32(&c.stalled) == 1,
+		EvictionSuspicion: c.EvictionSuspicion(),
+		Replay:            c.ReplayProgress(),
+	}
+
+	if c.isRunning() == nil {
+		info.Role = raftRole(c.Node.Status().RaftState)
+		info.ServeState = c.serveState.State().String()
+	}
+
+	if t := atomic.LoadInt64(&c.lastCommitTime); t != 0 {
+		info.LastCommitTime = time.Unix(0, t).UTC()
+	}
+
+	return info
+}
+
+// FeatureVersion returns the version of the given wire feature which every consenter
+// of the channel supports, or zero if the feature is not to be used.
+func (c *Chain) FeatureVersion(feature string) uint32 {
+	return c.features.negotiate()[feature]
+}
+
+// PendingBatch describes the transactions waiting to be cut into a block,
+// and the blocks in flight, which are both empty unless this node leads.
+func (c *Chain) PendingBatch() PendingBatch {
+	c.pendingBatchLock.Lock()
+	pb, blocks, bytes := c.pendingBatch, c.pendingBlocks, c.pendingBytes
+	c.pendingBatchLock.Unlock()
+
+	var age time.Duration
+	if !pb.StartTime.IsZero() {
+		age = time.Since(pb.StartTime)
+	}
+	return PendingBatch{
+		Messages:       pb.Messages,
+		Bytes:          pb.SizeBytes,
+		Age:            age.String(),
+		BlocksInFlight: blocks,
+		BytesInFlight:  bytes,
+	}
+}
+
+// updatePendingBatch records the batch pending in the block cutter and the
+// blocks in flight, and publishes them as metrics. It must be called by
+// serveRequest whenever either of them changes.
+func (c *Chain) updatePendingBatch() {
+	pb := c.support.BlockCutter().PendingBatch()
+
+	c.pendingBatchLock.Lock()
+	c.pendingBatch, c.pendingBlocks, c.pendingEnvelopes, c.pendingBytes = pb, c.blockInflight, c.envelopesInflight, c.bytesInflight
+	c.pendingBatchLock.Unlock()
+
+	var startTime float64
+	if !pb.StartTime.IsZero() {
+		startTime = float64(pb.StartTime.Unix())
+	}
+	c.Metrics.PendingBatchMessages.Set(float64(pb.Messages))
+	c.Metrics.PendingBatchBytes.Set(float64(pb.SizeBytes))
+	c.Metrics.PendingBatchStartTime.Set(startTime)
+	c.Metrics.BlocksInFlight.Set(float64(c.blockInflight))
+	c.Metrics.BytesInFlight.Set(float64(c.bytesInflight))
+}
+
+func raftRole(state raft.StateType) string {
+	switch state {
+	case raft.StateLeader:
+		return "leader"
+	case raft.StateCandidate, raft.StatePreCandidate:
+		return "candidate"
+	default:
+		return "follower"
+	}
+}
+
 func (c *Chain) isRunning() error {
 	select {
 	case <-c.startC:
@@ -502,11 +1588,41 @@ func (c *Chain) Consensus(req *orderer.ConsensusRequest, sender uint64) error {
 		return err
 	}
 
+	attested, err := attestedRaftID(req, sender)
+	if err != nil {
+		c.logger.Warnf("Rejected ConsensusRequest from node %d: %s", sender, err)
+		return err
+	}
+
+	if limit := c.opts.MaxConsensusMessageBytes; sender != 0 && limit > 0 && uint64(len(req.Payload)) > limit {
+		return c.dropMessage(sender, malformed(DropReasonSize, "raft message of %d bytes exceeds the maximum of %d bytes", len(req.Payload), limit))
+	}
+
 	stepMsg := &raftpb.Message{}
 	if err := proto.Unmarshal(req.Payload, stepMsg); err != nil {
 		return fmt.Errorf("failed to unmarshal StepRequest payload to Raft Message: %s", err)
 	}
 
+	if ad := featureAdvertisement(req.Payload, stepMsg); ad != nil {
+		c.features.receive(sender, ad)
+		return nil
+	}
+
+	if attested != 0 && stepMsg.From != attested {
+		c.logger.Warnf("Rejected ConsensusRequest from node %d: raft message is from node %d", sender, stepMsg.From)
+		return errors.Errorf("raft message is from node %d, but the sender attests raft ID %d", stepMsg.From, attested)
+	}
+
+	// messages stepped with no sender come from this node
+	if sender != 0 {
+		if err := validateStepMessage(stepMsg, sender, c.raftID, c.Node.lastIndex()); err != nil {
+			return c.dropMessage(sender, err)
+		}
+	}
+
+	c.grayFailures.acknowledged(sender, stepMsg)
+	c.recorder.record(MessageReceived, stepMsg, len(req.Payload), c.clock.Now())
+
 	if err := c.Node.Step(context.TODO(), *stepMsg); err != nil {
 		return fmt.Errorf("failed to process Raft Step message: %s", err)
 	}
@@ -524,20 +1640,80 @@ func (c *Chain) Submit(req *orderer.SubmitRequest, sender uint64) error {
 		return err
 	}
 
-	leadC := make(chan uint64, 1)
+	// transactions forwarded by other nodes are rejected as well,
+	// so that a paused leader does not create blocks out of them
+	if sender != 0 && c.Paused() && !c.isConfig(req.Payload) {
+		return c.reject(RejectReasonPaused, ErrChainPaused)
+	}
+
+	s := &submit{req: req, leader: make(chan uint64, 1), config: c.isConfig(req.Payload)}
+	if s.config {
+		if err := c.configQueue.enqueue(); err != nil {
+			return c.reject(RejectReasonConfigPending, err)
+		}
+	}
+
+	// only requests of our own clients go straight to the hinted leader,
+	// those forwarded by other nodes are not bounced back if it is stale
+	if lead := c.hintedLeader(); sender == 0 && lead != raft.None {
+		err := c.forward(lead, req)
+		if err == nil {
+			// the leader orders the config update, if any
+			c.dequeueConfig(s)
+			return nil
+		}
+		c.logger.Debugf("Failed to forward request to hinted leader %d, asking for the leader: %s", lead, err)
+		c.forgetLeader()
+	}
+
+	if sender != 0 {
+		c.submitSequencer.wait(req, c.doneC)
+	}
+
+	if c.scheduler != nil {
+		// transactions are scheduled by the node which received them from
+		// their clients, so that they are ordered as that node received them
+		source := req.Origin
+		if source == 0 {
+			source = sender
+		}
+		if source == 0 {
+			source = c.raftID
+		}
+		if err := c.scheduler.submit(source, s); err != nil {
+			c.dequeueConfig(s)
+			c.Metrics.ProposalFailures.Add(1)
+			return err
+		}
+	} else {
+		select {
+		case c.submitC <- s:
+		case <-c.doneC:
+			c.dequeueConfig(s)
+			c.Metrics.ProposalFailures.Add(1)
+			return errors.Errorf("chain is stopped")
+		}
+	}
+	c.submitSequencer.submitted(req)
+
 	select {
-	case c.submitC <- &submit{req, leadC}:
-		lead := <-leadC
+	case lead := <-s.leader:
+		if lead != c.raftID {
+			// the leader orders the config update, if any
+			c.dequeueConfig(s)
+		}
+
 		if lead == raft.None {
 			c.Metrics.ProposalFailures.Add(1)
-			return errors.Errorf("no Raft leader")
+			return c.leaderError(raft.None, errors.Errorf("no Raft leader"))
 		}
 
 		if lead != c.raftID {
-			if err := c.rpc.SendSubmit(lead, req); err != nil {
+			if err := c.forward(lead, req); err != nil {
 				c.Metrics.ProposalFailures.Add(1)
-				return err
+				return c.leaderError(lead, err)
 			}
+			c.leaderHint.Store(&leaderHint{leader: lead, expires: c.clock.Now().Add(LeaderHintTTL)})
 		}
 
 	case <-c.doneC:
@@ -548,6 +1724,90 @@ func (c *Chain) Submit(req *orderer.SubmitRequest, sender uint64) error {
 	return nil
 }
 
+// ingressShare returns the share of the given consenter in the
+// transactions ordered by the fair scheduler, by its endpoint.
+func (c *Chain) ingressShare(raftID uint64) int {
+	consenter, exists := c.raftMetadata().Consenters[raftID]
+	if !exists {
+		return 1
+	}
+	if share := c.opts.IngressShares[fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)]; share > 0 {
+		return share
+	}
+	return 1
+}
+
+// forward forwards the request to the leader, in a batch with the requests
+// forwarded concurrently if every consenter supports batches, and on its own
+// otherwise, so that leaders running older versions receive it. Requests the
+// node received from its clients are numbered when they are first forwarded.
+func (c *Chain) forward(lead uint64, req *orderer.SubmitRequest) error {
+	if req.Origin == 0 {
+		c.forwardSequencer.stamp(c.raftID, lead, req)
+	}
+	if c.FeatureVersion(FeatureSubmitBatches) == 0 {
+		return c.rpc.SendSubmit(lead, req)
+	}
+	return c.forwarder.forward(lead, req)
+}
+
+// revalidate validates a request, which is about to be forwarded to the leader,
+// against the current config sequence, if it was validated against an older one,
+// as the leader would, and updates it accordingly.
+func (c *Chain) revalidate(req *orderer.SubmitRequest) error {
+	seq := c.support.Sequence()
+	if req.LastValidationSeq >= seq {
+		return nil
+	}
+
+	if c.isConfig(req.Payload) {
+		payload, _, err := c.support.ProcessConfigMsg(req.Payload)
+		if err != nil {
+			c.Metrics.ProposalFailures.Add(1)
+			return errors.Errorf("bad config message: %s", err)
+		}
+		if err := c.checkConfigUpdateValidity(payload); err != nil {
+			c.Metrics.ProposalFailures.Add(1)
+			return errors.Errorf("bad config message: %s", err)
+		}
+		req.Payload = payload
+	} else {
+		err := c.validationCache.validate(req.Payload, seq, func() error {
+			_, err := c.support.ProcessNormalMsg(req.Payload)
+			return err
+		})
+		if err != nil {
+			c.Metrics.ProposalFailures.Add(1)
+			return errors.Errorf("bad normal message: %s", err)
+		}
+	}
+	req.LastValidationSeq = seq
+	return nil
+}
+
+// leaderHint is the leader transactions were last forwarded to, which
+// followers forward transactions to until the hint expires.
+type leaderHint struct {
+	leader  uint64
+	expires time.Time
+}
+
+// hintedLeader returns the leader transactions were last forwarded
+// to, or raft.None if there is no such leader or the hint expired.
+func (c *Chain) hintedLeader() uint64 {
+	hint, _ := c.leaderHint.Load().(*leaderHint)
+	if hint == nil || !c.clock.Now().Before(hint.expires) {
+		return raft.None
+	}
+	return hint.leader
+}
+
+// forgetLeader drops the leader hint, so that the leader
+// of the next transaction is obtained from serveRequest.
+func (c *Chain) forgetLeader() {
+	c.leaderHint.Store((*leaderHint)(nil))
+}
+
 type apply struct {
 	entries []raftpb.Entry
 	soft    *raft.SoftState
@@ -558,6 +1818,8 @@ func isCandidate(state raft.StateType) bool {
 }
 
 func (c *Chain) serveRequest() {
+	defer c.dumpFlightRecordOnPanic()
+
 	ticking := false
 	timer := c.clock.NewTimer(time.Second)
 	// we need a stopped timer rather than nil,
@@ -582,72 +1844,156 @@ func (c *Chain) serveRequest() {
 		ticking = false
 	}
 
+	// heartbeat expires when the leader has not proposed
+	// any block for MaxBlockInterval
+	heartbeat := c.clock.NewTimer(time.Second)
+	if !heartbeat.Stop() {
+		<-heartbeat.C()
+	}
+
+	stopHeartbeat := func() {
+		if !heartbeat.Stop() {
+			select {
+			case <-heartbeat.C():
+			default:
+			}
+		}
+	}
+
+	resetHeartbeat := func() {
+		if c.opts.MaxBlockInterval <= 0 {
+			return
+		}
+		stopHeartbeat()
+		heartbeat.Reset(c.opts.MaxBlockInterval)
+	}
+
+	// confChangeTimer expires when the ConfChange in flight
+	// has not been applied for ConfChangeTimeout
+	confChangeTimer := c.clock.NewTimer(time.Second)
+	if !confChangeTimer.Stop() {
+		<-confChangeTimer.C()
+	}
+	var timedConfChange *raftpb.ConfChange
+
+	// timeConfChange (re)starts confChangeTimer whenever another ConfChange
+	// is in flight, and stops it once the ConfChange is applied.
+	timeConfChange := func() {
+		if c.opts.ConfChangeTimeout <= 0 || c.confChangeInProgress == timedConfChange {
+			return
+		}
+		if !confChangeTimer.Stop() {
+			select {
+			case <-confChangeTimer.C():
+			default:
+			}
+		}
+		if timedConfChange != nil {
+			c.confChangeResumed(timedConfChange)
+		}
+		timedConfChange = c.confChangeInProgress
+		if timedConfChange != nil {
+			confChangeTimer.Reset(c.opts.ConfChangeTimeout)
+		}
+	}
+
 	var soft raft.SoftState
 	submitC := c.submitC
 	var bc *blockCreator
 
-	var propC chan<- *common.Block
+	var propC chan<- *proposal
 	var cancelProp context.CancelFunc
 	cancelProp = func() {} // no-op as initial value
 
-	becomeLeader := func() (chan<- *common.Block, context.CancelFunc) {
+	becomeLeader := func() (chan<- *proposal, context.CancelFunc) {
 		c.Metrics.IsLeader.Set(1)
 
 		c.blockInflight = 0
+		c.envelopesInflight = 0
+		c.bytesInflight = 0
+		c.blockSizesInflight = nil
 		c.justElected = true
+		c.leaderTerm = c.Node.Status().Term
+		c.restartSlot.resetPending()
+		c.confChangeRetrier.lead(true)
 		submitC = nil
-		ch := make(chan *common.Block, c.opts.MaxInflightMsgs)
+		ch := make(chan *proposal, c.opts.MaxInflightMsgs)
+		c.Metrics.ProposeQueueDepth.Set(0)
 
 		// if there is unfinished ConfChange, we should resume the effort to propose it as
 		// new leader, and wait for it to be committed before start serving new requests.
 		if cc := c.getInFlightConfChange(); cc != nil {
 			// The reason `ProposeConfChange` should be called in go routine is documented in `writeConfigBlock` method.
-			go func() {
+			c.goroutines.spawn("propose_conf_change", func() {
 				if err := c.Node.ProposeConfChange(context.TODO(), *cc); err != nil {
 					c.logger.Warnf("Failed to propose configuration update to Raft node: %s", err)
 				}
-			}()
+			})
 
 			c.confChangeInProgress = cc
-			c.configInflight = true
+			c.setConfigInflight(true)
+			c.confChangeRetrier.proposed(cc)
 		}
 
 		// Leader should call Propose in go routine, because this method may be blocked
 		// if node is leaderless (this can happen when leader steps down in a heavily
 		// loaded network). We need to make sure applyC can still be consumed properly.
 		ctx, cancel := context.WithCancel(context.Background())
-		go func(ctx context.Context, ch <-chan *common.Block) {
+		c.goroutines.spawn("proposer", func() {
 			for {
 				select {
-				case b := <-ch:
-					data := utils.MarshalOrPanic(b)
-					if err := c.Node.Propose(ctx, data); err != nil {
-						c.logger.Errorf("Failed to propose block %d to raft and discard %d blocks in queue: %s", b.Header.Number, len(ch), err)
+				case p, ok := <-ch:
+					if !ok {
+						c.logger.Debugf("Stepped down, quit proposing blocks")
 						return
 					}
-					c.logger.Debugf("Proposed block %d to raft consensus", b.Header.Number)
+					c.Metrics.ProposeQueueDepth.Set(float64(len(ch)))
+					c.Metrics.ProposeWaitDuration.Observe(c.clock.Since(p.created).Seconds())
+					for _, b := range p.blocks {
+						data := utils.MarshalOrPanic(b)
+						if err := c.Node.Propose(ctx, data); err != nil {
+							c.logger.Errorf("Failed to propose block %d to raft and discard %d proposals in queue: %s", b.Header.Number, len(ch), err)
+							return
+						}
+						c.logger.Debugf("Proposed block %d to raft consensus", b.Header.Number)
+					}
 
 				case <-ctx.Done():
 					c.logger.Debugf("Quit proposing blocks, discarded %d blocks in the queue", len(ch))
 					return
 				}
 			}
-		}(ctx, ch)
+		})
 
 		return ch, cancel
 	}
 
 	becomeFollower := func() {
-		cancelProp()
+		if c.opts.ProposalForwarding {
+			// Blocks still queued are proposed anyway, and forwarded to the new leader.
+			close(propC)
+		} else {
+			cancelProp()
+		}
 		c.blockInflight = 0
+		c.envelopesInflight = 0
+		c.bytesInflight = 0
+		c.blockSizesInflight = nil
+		c.restartSlot.resetPending()
+		c.confChangeRetrier.lead(false)
 		_ = c.support.BlockCutter().Cut()
+		c.updatePendingBatch()
 		stop()
+		stopHeartbeat()
 		submitC = c.submitC
 		bc = nil
 		c.Metrics.IsLeader.Set(0)
 	}
 
 	for {
+		timeConfChange()
+		c.observeServeState(soft, ticking)
+
 		select {
 		case s := <-submitC:
 			if s == nil {
@@ -667,6 +2013,7 @@ func (c *Chain) serveRequest() {
 
 			batches, pending, err := c.ordered(s.req)
 			if err != nil {
+				c.dequeueConfig(s)
 				c.logger.Errorf("Failed to order message: %s", err)
 				continue
 			}
@@ -677,6 +2024,11 @@ func (c *Chain) serveRequest() {
 			}
 
 			c.propose(propC, bc, batches...)
+			if len(batches) > 0 {
+				resetHeartbeat()
+			}
+			// the config block, if any, is in flight by now
+			c.dequeueConfig(s)
 
 			if c.configInflight {
 				c.logger.Info("Received config block, pause accepting transaction till it is committed")
@@ -685,62 +2037,45 @@ func (c *Chain) serveRequest() {
 				c.logger.Debugf("Number of in-flight blocks (%d) reaches limit (%d), pause accepting transaction",
 					c.blockInflight, c.opts.MaxInflightMsgs)
 				submitC = nil
+			} else if c.inflightBytesExceeded() {
+				c.logger.Debugf("Size of in-flight blocks (%d bytes) reaches limit (%d bytes), pause accepting transaction",
+					c.bytesInflight, c.opts.MaxInflightBytes)
+				submitC = nil
+			} else if c.backlogged() {
+				c.logger.Warnf("Commit backlog (%d) reaches limit (%d), pause accepting transaction", c.commitBacklog(), c.opts.MaxCommitBacklog)
+				submitC = nil
 			}
 
 		case app := <-c.applyC:
 			if app.soft != nil {
 				newLeader := atomic.LoadUint64(&app.soft.Lead) // etcdraft requires atomic access
-				if newLeader != soft.Lead {
-					c.logger.Infof("Raft leader changed: %d -> %d", soft.Lead, newLeader)
-					c.Metrics.LeaderChanges.Add(1)
-
-					atomic.StoreUint64(&c.lastKnownLeader, newLeader)
-
-					if newLeader == c.raftID {
-						propC, cancelProp = becomeLeader()
-					}
-
-					if soft.Lead == c.raftID {
-						becomeFollower()
-					}
-				}
-
-				foundLeader := soft.Lead == raft.None && newLeader != raft.None
-				quitCandidate := isCandidate(soft.RaftState) && !isCandidate(app.soft.RaftState)
-
-				if foundLeader || quitCandidate {
-					c.errorCLock.Lock()
-					c.errorC = make(chan struct{})
-					c.errorCLock.Unlock()
-				}
+				if c.changeLeader(soft.Lead, newLeader) {
+					if newLeader == c.raftID {
+						propC, cancelProp = becomeLeader()
+					}
 
-				if isCandidate(app.soft.RaftState) || newLeader == raft.None {
-					atomic.StoreUint64(&c.lastKnownLeader, raft.None)
-					select {
-					case <-c.errorC:
-					default:
-						nodeCount := len(c.opts.BlockMetadata.Consenters)
-						// Only close the error channel (to signal the broadcast/deliver front-end a consensus backend error)
-						// If we are a cluster of size 3 or more, otherwise we can't expand a cluster of size 1 to 2 nodes.
-						if nodeCount > 2 {
-							close(c.errorC)
-						} else {
-							c.logger.Warningf("No leader is present, cluster size is %d", nodeCount)
-						}
+					if soft.Lead == c.raftID {
+						becomeFollower()
 					}
 				}
 
-				soft = raft.SoftState{Lead: newLeader, RaftState: app.soft.RaftState}
-
-				// notify external observer
-				select {
-				case c.observeC <- soft:
-				default:
-				}
+				soft = c.observeSoftState(soft, raft.SoftState{Lead: newLeader, RaftState: app.soft.RaftState})
 			}
 
 			c.apply(app.entries)
 
+			if c.staleBlocks && soft.Lead == c.raftID && !c.justElected {
+				c.logger.Warnf("Skipped blocks not extending block %d, re-synchronizing block creation with the ledger", c.lastBlock.Header.Number)
+				c.justElected = true
+				c.blockInflight = 0
+				c.envelopesInflight = 0
+				c.bytesInflight = 0
+				c.blockSizesInflight = nil
+				c.updatePendingBatch()
+				submitC = nil
+			}
+			c.staleBlocks = false
+
 			if c.justElected {
 				msgInflight := c.Node.lastIndex() > c.appliedIndex
 				if msgInflight {
@@ -761,10 +2096,14 @@ func (c *Chain) serveRequest() {
 				}
 				submitC = c.submitC
 				c.justElected = false
+				resetHeartbeat()
 			} else if c.configInflight {
 				c.logger.Info("Config block or ConfChange in flight, pause accepting transaction")
 				submitC = nil
-			} else if c.blockInflight < c.opts.MaxInflightMsgs {
+			} else if c.backlogged() {
+				c.logger.Debugf("Commit backlog (%d) reaches limit (%d), pause accepting transaction", c.commitBacklog(), c.opts.MaxCommitBacklog)
+				submitC = nil
+			} else if c.blockInflight < c.opts.MaxInflightMsgs && !c.inflightBytesExceeded() {
 				submitC = c.submitC
 			}
 
@@ -779,42 +2118,185 @@ func (c *Chain) serveRequest() {
 
 			c.logger.Debugf("Batch timer expired, creating block")
 			c.propose(propC, bc, batch) // we are certain this is normal block, no need to block
+			resetHeartbeat()
 
-		case sn := <-c.snapC:
-			if sn.Metadata.Index != 0 {
-				if sn.Metadata.Index <= c.appliedIndex {
-					c.logger.Debugf("Skip snapshot taken at index %d, because it is behind current applied index %d", sn.Metadata.Index, c.appliedIndex)
-					break
-				}
-
-				c.confState = sn.Metadata.ConfState
-				c.appliedIndex = sn.Metadata.Index
-			} else {
-				c.logger.Infof("Received artificial snapshot to trigger catchup")
+		case <-heartbeat.C():
+			if ticking || c.configInflight || c.blockInflight > 0 {
+				// a block is about to be cut, or is already in flight
+				resetHeartbeat()
+				continue
 			}
 
-			if err := c.catchUp(sn); err != nil {
-				c.logger.Panicf("Failed to recover from snapshot taken at Term %d and Index %d: %s",
-					sn.Metadata.Term, sn.Metadata.Index, err)
+			c.logger.Debugf("No block was proposed for %v, creating empty block", c.opts.MaxBlockInterval)
+			c.propose(propC, bc, []*common.Envelope{})
+			resetHeartbeat()
+
+		case errC := <-c.repairC:
+			cc, err := c.membershipRepair(soft)
+			if err == nil {
+				c.logger.Warnf("Proposing config change to %s node %d to repair membership", cc.Type, cc.NodeID)
+				c.goroutines.spawn("propose_conf_change", func() {
+					if err := c.Node.ProposeConfChange(context.TODO(), *cc); err != nil {
+						c.logger.Warnf("Failed to propose configuration update to Raft node: %s", err)
+					}
+				})
+				c.confChangeInProgress = cc
+				c.setConfigInflight(true)
+				c.confChangeRetrier.proposed(cc)
+				submitC = nil
 			}
+			errC <- err
+
+		case req := <-c.markerC:
+			req.errC <- c.proposeMarker(req, soft)
+
+		case req := <-c.snapshotC:
+			c.snapshot(req)
+
+		case <-c.probeC:
+			// the watchdog found the chain processing events
+
+		case <-confChangeTimer.C():
+			c.confChangeStalled(timedConfChange)
+
+		case <-c.confChangeRetrier.retryC():
+			c.confChangeRetrier.retry()
+
+		case sn := <-c.snapC:
+			c.recoverFromSnapshot(sn, func() { c.observeServeState(soft, ticking) })
 
 		case <-c.doneC:
 			cancelProp()
+			c.stopServing()
+			return
+		}
+	}
+}
 
-			select {
-			case <-c.errorC: // avoid closing closed channel
-			default:
-				close(c.errorC)
+// observeServeState moves the serve state machine of the chain to the state of
+// serveRequest, given the soft state of the raft node and whether a batch is pending.
+func (c *Chain) observeServeState(soft raft.SoftState, batchPending bool) {
+	c.serveState.Observe(ServeConditions{
+		Leading:        soft.Lead == c.raftID,
+		Candidate:      isCandidate(soft.RaftState) || soft.Lead == raft.None,
+		ConfigInflight: c.configInflight,
+		BlocksInflight: c.blockInflight,
+		BatchPending:   batchPending,
+		CatchingUp:     atomic.LoadUint32(&c.catchingUp) == 1,
+	})
+}
+
+func (c *Chain) logServeTransition(transition ServeTransition) {
+	c.logger.Debugf("Serving requests as %s, was %s", transition.To, transition.From)
+}
+
+// changeLeader records the change of the leader from the given one to the new
+// one, if it changed, and returns whether it did.
+func (c *Chain) changeLeader(leader, newLeader uint64) bool {
+	if newLeader == leader {
+		return false
+	}
+
+	c.logger.Infof("Raft leader changed: %d -> %d", leader, newLeader)
+	c.Metrics.LeaderChanges.Add(1)
+	c.notify(Event{Type: EventLeaderChange, Leader: newLeader, PreviousLeader: leader})
+
+	atomic.StoreUint64(&c.lastKnownLeader, newLeader)
+	c.forgetLeader()
+	if newLeader != raft.None {
+		c.Node.dampener.observe()
+	}
+	return true
+}
+
+// observeSoftState signals the health of the chain as the raft node moves from
+// the given soft state to the next one, notifies the external observer, if any,
+// of the next soft state, and returns it.
+func (c *Chain) observeSoftState(soft, next raft.SoftState) raft.SoftState {
+	foundLeader := soft.Lead == raft.None && next.Lead != raft.None
+	quitCandidate := isCandidate(soft.RaftState) && !isCandidate(next.RaftState)
+
+	if foundLeader || quitCandidate {
+		c.health.recovered()
+	}
+
+	if isCandidate(next.RaftState) || next.Lead == raft.None {
+		atomic.StoreUint64(&c.lastKnownLeader, raft.None)
+		select {
+		case <-c.Errored():
+		default:
+			nodeCount := len(c.raftMetadata().Consenters)
+			// Only close the error channel (to signal the broadcast/deliver front-end a consensus backend error)
+			// If we are a cluster of size 3 or more, otherwise we can't expand a cluster of size 1 to 2 nodes.
+			if nodeCount > 2 {
+				c.health.errored()
+			} else {
+				c.logger.Warningf("No leader is present, cluster size is %d", nodeCount)
 			}
+		}
+	}
+
+	// notify external observer
+	select {
+	case c.observeC <- next:
+	default:
+	}
 
-			c.logger.Infof("Stop serving requests")
-			c.periodicChecker.Stop()
+	return next
+}
+
+// recoverFromSnapshot catches up with the given snapshot, unless it is behind
+// the applied index. The given function is called once the chain is marked as
+// catching up, and once it is done.
+func (c *Chain) recoverFromSnapshot(sn *raftpb.Snapshot, observe func()) {
+	if sn.Metadata.Index != 0 {
+		if sn.Metadata.Index <= c.appliedIndex {
+			c.logger.Debugf("Skip snapshot taken at index %d, because it is behind current applied index %d", sn.Metadata.Index, c.appliedIndex)
 			return
 		}
+
+		c.setConfState(sn.Metadata.ConfState)
+		c.appliedIndex = sn.Metadata.Index
+		atomic.StoreUint64(&c.writtenIndex, c.appliedIndex)
+	} else {
+		c.logger.Infof("Received artificial snapshot to trigger catchup")
+	}
+
+	atomic.StoreUint32(&c.catchingUp, 1)
+	observe()
+	err := c.catchUp(sn)
+	atomic.StoreUint32(&c.catchingUp, 0)
+	observe()
+	if err != nil {
+		c.logger.Panicf("Failed to recover from snapshot taken at Term %d and Index %d: %s",
+			sn.Metadata.Term, sn.Metadata.Index, err)
+	}
+}
+
+// stopServing releases the resources of serveRequest once the chain halts.
+func (c *Chain) stopServing() {
+	c.health.errored()
+
+	if c.receipts != nil {
+		c.receipts.close(ErrReceiptsHalted)
+	}
+	if c.eventStream != nil {
+		c.eventStream.close(ErrEventsHalted)
 	}
+
+	c.logger.Infof("Stop serving requests")
+	c.periodicChecker.Stop()
 }
 
 func (c *Chain) writeBlock(block *common.Block, index uint64) {
+	if c.opts.ProposalForwarding && !extends(block, c.lastBlock) {
+		// Blocks forwarded from a previous leader may have been overtaken
+		// by the blocks of the current leader.
+		c.logger.Warnf("Skipping block %d which does not extend block %d", block.Header.Number, c.lastBlock.Header.Number)
+		c.staleBlocks = true
+		return
+	}
+
 	if block.Header.Number > c.lastBlock.Header.Number+1 {
 		c.logger.Panicf("Got block %d, expect block %d", block.Header.Number, c.lastBlock.Header.Number+1)
 	} else if block.Header.Number < c.lastBlock.Header.Number+1 {
@@ -824,22 +2306,101 @@ func (c *Chain) writeBlock(block *common.Block, index uint64) {
 
 	if c.blockInflight > 0 {
 		c.blockInflight-- // only reduce on leader
+		c.envelopesInflight -= len(block.Data.Data)
+		if c.envelopesInflight < 0 {
+			c.envelopesInflight = 0
+		}
+		if len(c.blockSizesInflight) > 0 {
+			c.bytesInflight -= c.blockSizesInflight[0]
+			c.blockSizesInflight = c.blockSizesInflight[1:]
+		}
+		c.updatePendingBatch()
 	}
 	c.lastBlock = block
+	atomic.StoreInt64(&c.lastCommitTime, c.clock.Now().UnixNano())
+	if c.blockTimestamp {
+		c.checkBlockTime(block)
+	}
 
 	c.logger.Debugf("Writing block %d to ledger", block.Header.Number)
 
 	if utils.IsConfigBlock(block) {
 		c.writeConfigBlock(block, index)
+		c.markCommitted(block.Header.Number, index)
+		c.Node.storage.stager.prune(block.Header.Number)
+		c.publishReceipts(block)
+		c.blockCommitted(block)
 		return
 	}
 
-	c.raftMetadataLock.Lock()
-	c.opts.BlockMetadata.RaftIndex = index
-	m := utils.MarshalOrPanic(c.opts.BlockMetadata)
-	c.raftMetadataLock.Unlock()
+	c.admission.committed(len(block.Data.Data))
 
+	applied := c.clock.Now()
+	m := c.updateRaftMetadata(c.raftMetadata(), block, index)
 	c.support.WriteBlock(block, m)
+	c.markCommitted(block.Header.Number, index)
+	c.Node.storage.stager.prune(block.Header.Number)
+	c.publishReceipts(block)
+	c.selfTests.written(block, applied, c.clock.Now())
+	c.blockCommitted(block)
+}
+
+// blockCommitted notifies the DR replicator, and calls the OnBlockCommitted hook,
+// if set, with the header and data of the given block, which the ledger does not
+// modify.
+func (c *Chain) blockCommitted(block *common.Block) {
+	c.drReplicator.committed()
+	if c.opts.OnBlockCommitted == nil {
+		return
+	}
+	c.opts.OnBlockCommitted(c.channelID, &common.Block{Header: block.Header, Data: block.Data})
+}
+
+// extends returns whether the block is the successor of the given block.
+func extends(block, last *common.Block) bool {
+	return block.Header.Number == last.Header.Number+1 && bytes.Equal(block.Header.PreviousHash, last.Header.Hash())
+}
+
+// markCommitted updates the commit marker, if maintained,
+// after the given block was handed to the ledger.
+func (c *Chain) markCommitted(blockNumber, index uint64) {
+	if c.opts.CommitMarkerPath == "" {
+		return
+	}
+	if err := WriteCommitMarker(c.opts.CommitMarkerPath, CommitMarker{BlockNumber: blockNumber, RaftIndex: index}); err != nil {
+		c.logger.Panicf("Failed to update commit marker of block %d: %s", blockNumber, err)
+	}
+}
+
+// raftMetadata returns the current BlockMetadata of the chain.
+// The returned value is shared with concurrent readers and must not be modified.
+func (c *Chain) raftMetadata() *etcdraft.BlockMetadata {
+	return c.blockMetadata.Load().(*etcdraft.BlockMetadata)
+}
+
+// updateRaftMetadata stores a copy of the given BlockMetadata for the given block,
+// with its raft index set to index, as the current BlockMetadata of the chain,
+// and returns it serialized. Consenters are shared with the given BlockMetadata,
+// since they are replaced as a whole upon membership changes rather than modified in place.
+func (c *Chain) updateRaftMetadata(m *etcdraft.BlockMetadata, block *common.Block, index uint64) []byte {
+	updated := proto.Clone(m).(*etcdraft.BlockMetadata)
+	updated.RaftIndex = index
+	// the fields describing the current block are not carried over
+	updated.StateHash, updated.Provenance, updated.Timestamp = nil, nil, nil
+	if c.stateHash {
+		updated.StateHash = NextStateHash(m.StateHash, index, block.Header)
+	}
+	if c.blockProvenance {
+		// the provenance stamped by the proposer, if any,
+		// is carried over into the metadata written
+		updated.Provenance, _ = BlockProvenance(block)
+	}
+	if c.blockTimestamp {
+		// as is the timestamp
+		updated.Timestamp, _ = BlockTimestamp(block)
+	}
+	c.blockMetadata.Store(updated)
+	return utils.MarshalOrPanic(updated)
 }
 
 // Orders the envelope in the `msg` content. SubmitRequest.
@@ -875,9 +2436,20 @@ func (c *Chain) ordered(msg *orderer.SubmitRequest) (batches [][]*common.Envelop
 		return batches, false, nil
 	}
 	// it is a normal message
+	if c.rejectNormalMsgs {
+		return nil, true, c.reject(RejectReasonSystemChannel, ErrSystemChannelTransaction)
+	}
+	// the limit may have been lowered since the node which forwarded it checked it
+	if err := c.checkEnvelopeSize(msg.Payload); err != nil {
+		return nil, true, c.reject(RejectReasonSize, err)
+	}
 	if msg.LastValidationSeq < seq {
 		c.logger.Warnf("Normal message was validated against %d, although current config seq has advanced (%d)", msg.LastValidationSeq, seq)
-		if _, err := c.support.ProcessNormalMsg(msg.Payload); err != nil {
+		err := c.validationCache.validate(msg.Payload, seq, func() error {
+			_, err := c.support.ProcessNormalMsg(msg.Payload)
+			return err
+		})
+		if err != nil {
 			c.Metrics.ProposalFailures.Add(1)
 			return nil, true, errors.Errorf("bad normal message: %s", err)
 		}
@@ -887,30 +2459,66 @@ func (c *Chain) ordered(msg *orderer.SubmitRequest) (batches [][]*common.Envelop
 
 }
 
-func (c *Chain) propose(ch chan<- *common.Block, bc *blockCreator, batches ...[]*common.Envelope) {
+// proposal is a batch of envelopes waiting in the leader's queue to be proposed
+// to raft, along with the time it was created at. A batch is usually cut into a
+// single block, unless it does not fit in a raft message.
+type proposal struct {
+	blocks  []*common.Block
+	created time.Time
+}
+
+func (c *Chain) propose(ch chan<- *proposal, bc *blockCreator, batches ...[]*common.Envelope) {
 	for _, batch := range batches {
-		b := bc.createNextBlock(batch)
-		c.logger.Debugf("Created block %d, there are %d blocks in flight", b.Header.Number, c.blockInflight)
+		blocks := bc.createNextBlocks(batch, c.opts.MaxSizePerMsg)
+		if len(blocks) > 1 {
+			c.logger.Warnf("Split batch of %d envelopes into %d blocks, as a single block would exceed the raft message size limit (MaxSizePerMsg) of %d bytes",
+				len(batch), len(blocks), c.opts.MaxSizePerMsg)
+		}
+
+		for _, b := range blocks {
+			c.logger.Debugf("Created block %d, there are %d blocks in flight", b.Header.Number, c.blockInflight)
+			if c.blockProvenance {
+				stampProvenance(b, &etcdraft.BlockProvenance{RaftTerm: c.leaderTerm, Proposer: c.raftID})
+			}
+			if c.blockTimestamp {
+				c.stampTime(b)
+			}
+		}
+
+		// the blocks are sized before they are queued, as
+		// sizing them races with marshaling them for proposal
+		sizes := make([]uint64, len(blocks))
+		for i, b := range blocks {
+			sizes[i] = uint64(proto.Size(b))
+		}
 
+		// blocks of a split batch share a single slot of the queue, so that
+		// splitting does not overflow the limit of in-flight blocks
 		select {
-		case ch <- b:
+		case ch <- &proposal{blocks: blocks, created: c.clock.Now()}:
+			c.Metrics.ProposeQueueDepth.Set(float64(len(ch)))
 		default:
 			c.logger.Panic("Programming error: limit of in-flight blocks does not properly take effect or block is proposed by follower")
 		}
 
-		// if it is config block, then we should wait for the commit of the block
-		if utils.IsConfigBlock(b) {
-			c.configInflight = true
-		}
+		for i, b := range blocks {
+			// if it is config block, then we should wait for the commit of the block
+			if utils.IsConfigBlock(b) {
+				c.setConfigInflight(true)
+			}
 
-		c.blockInflight++
+			c.blockInflight++
+			c.envelopesInflight += len(b.Data.Data)
+			c.bytesInflight += sizes[i]
+			c.blockSizesInflight = append(c.blockSizesInflight, sizes[i])
+		}
 	}
 
-	return
+	c.updatePendingBatch()
 }
 
 func (c *Chain) catchUp(snap *raftpb.Snapshot) error {
-	b, err := utils.UnmarshalBlock(snap.Data)
+	b, archive, err := SnapshotBlock(snap.Data)
 	if err != nil {
 		return errors.Errorf("failed to unmarshal snapshot data to block: %s", err)
 	}
@@ -930,39 +2538,115 @@ func (c *Chain) catchUp(snap *raftpb.Snapshot) error {
 
 	c.logger.Infof("Catching up with snapshot taken at block %d, starting from block %d", b.Header.Number, next)
 
+	// Membership changes of the config blocks pulled are accumulated, and
+	// communication is reconfigured periodically and once caught up, rather
+	// than upon each config block, which is costly over long histories.
+	comm := &catchUpComm{chain: c, consenters: c.raftMetadata().Consenters, since: next}
+
+	var archivePuller BlockPuller
+	defer func() {
+		if archivePuller != nil {
+			archivePuller.Close()
+		}
+	}()
+
 	for next <= b.Header.Number {
-		block := puller.PullBlock(next)
+		var block *common.Block
+		if archivePuller == nil {
+			block = puller.PullBlock(next)
+		}
+		if block == nil && archive != nil && c.opts.ArchiveFetcher != nil {
+			if archivePuller == nil {
+				c.logger.Warnf("Failed to fetch block %d from cluster, falling back to archive %s", next, archive.Uri)
+				var hash []byte
+				if archivePuller, hash, err = c.opts.ArchiveFetcher.Fetch(archive); err != nil {
+					return errors.Errorf("failed to fetch archive %s: %s", archive.Uri, err)
+				}
+				if !bytes.Equal(hash, archive.Hash) {
+					return errors.Errorf("hash of archive %s is %x, expected %x", archive.Uri, hash, archive.Hash)
+				}
+			}
+			block = archivePuller.PullBlock(next)
+			if block != nil && !bytes.Equal(block.Header.PreviousHash, c.lastBlock.Header.Hash()) {
+				return errors.Errorf("block %d fetched from archive %s does not extend block %d", next, archive.Uri, c.lastBlock.Header.Number)
+			}
+			if block != nil && next == b.Header.Number && !bytes.Equal(block.Header.Hash(), b.Header.Hash()) {
+				return errors.Errorf("block %d fetched from archive %s does not match the block of the snapshot", next, archive.Uri)
+			}
+		}
 		if block == nil {
 			return errors.Errorf("failed to fetch block %d from cluster", next)
 		}
 		if utils.IsConfigBlock(block) {
 			c.support.WriteConfigBlock(block, nil)
+			c.forwarder.configSequenceAdvanced()
 
 			configMembership := c.detectConfChange(block)
 
 			if configMembership != nil && configMembership.Changed() {
-				c.logger.Infof("Config block %d changes consenter set, communication should be reconfigured", block.Header.Number)
-
-				c.raftMetadataLock.Lock()
-				c.opts.BlockMetadata = configMembership.NewBlockMetadata
-				c.raftMetadataLock.Unlock()
-
-				if err := c.configureComm(); err != nil {
-					c.logger.Panicf("Failed to configure communication: %s", err)
-				}
+				c.logger.Infof("Config block %d changes consenter set, communication will be reconfigured", block.Header.Number)
+			}
+			if configMembership != nil {
+				// the consenters may also be bound to organizations
+				c.blockMetadata.Store(configMembership.NewBlockMetadata)
 			}
 		} else {
 			c.support.WriteBlock(block, nil)
 		}
+		c.markCommitted(block.Header.Number, blockRaftIndex(block))
+		c.publishReceipts(block)
 
 		c.lastBlock = block
 		next++
+
+		if next-comm.since >= CatchUpReconfigureInterval {
+			comm.reconfigure(next)
+		}
+	}
+	comm.reconfigure(next)
+
+	// Continue the state hash from the last block pulled, which carries
+	// the raft metadata written by the consenter we pulled it from.
+	if m, err := utils.GetMetadataFromBlock(c.lastBlock, common.BlockMetadataIndex_ORDERER); err == nil {
+		pulled := &etcdraft.BlockMetadata{}
+		if err := proto.Unmarshal(m.Value, pulled); err == nil {
+			current := c.raftMetadata()
+			c.blockMetadata.Store(&etcdraft.BlockMetadata{
+				Consenters:      current.Consenters,
+				NextConsenterId: current.NextConsenterId,
+				RaftIndex:       current.RaftIndex,
+				StateHash:       pulled.StateHash,
+			})
+		}
 	}
 
 	c.logger.Infof("Finished syncing with cluster up to block %d (incl.)", b.Header.Number)
 	return nil
 }
 
+// catchUpComm reconfigures communication during catch-up, once the consenters
+// of the config blocks pulled differ from those it was last configured with.
+type catchUpComm struct {
+	chain      *Chain
+	consenters map[uint64]*etcdraft.Consenter // communication is configured with
+	since      uint64                         // block pulled next when last reconfigured
+}
+
+func (cc *catchUpComm) reconfigure(next uint64) {
+	cc.since = next
+
+	consenters := cc.chain.raftMetadata().Consenters
+	if sameConsenters(cc.consenters, consenters) {
+		return
+	}
+	cc.consenters = consenters
+
+	cc.chain.logger.Infof("Reconfiguring communication with the consenters of the blocks pulled up to block %d", next-1)
+	if err := cc.chain.configureComm(); err != nil {
+		cc.chain.logger.Panicf("Failed to configure communication: %s", err)
+	}
+}
+
 func (c *Chain) detectConfChange(block *common.Block) *MembershipChanges {
 	// If config is targeting THIS channel, inspect consenter set and
 	// propose raft ConfChange if it adds/removes node.
@@ -978,7 +2662,33 @@ func (c *Chain) detectConfChange(block *common.Block) *MembershipChanges {
 		c.logger.Infof("Snapshot interval is updated to %d bytes (was %d)", c.sizeLimit, old)
 	}
 
-	changes, err := ComputeMembershipChanges(c.opts.BlockMetadata, configMetadata.Consenters)
+	if configMetadata.Options != nil && configMetadata.Options.StateHash != c.stateHash {
+		c.stateHash = configMetadata.Options.StateHash
+		c.logger.Infof("State hash is updated to %t (was %t)", c.stateHash, !c.stateHash)
+	}
+
+	if configMetadata.Options != nil && configMetadata.Options.BlockProvenance != c.blockProvenance {
+		c.blockProvenance = configMetadata.Options.BlockProvenance
+		c.logger.Infof("Block provenance is updated to %t (was %t)", c.blockProvenance, !c.blockProvenance)
+	}
+
+	if configMetadata.Options != nil && configMetadata.Options.BlockTimestamp != c.blockTimestamp {
+		c.blockTimestamp = configMetadata.Options.BlockTimestamp
+		c.logger.Infof("Block timestamps are updated to %t (was %t)", c.blockTimestamp, !c.blockTimestamp)
+	}
+
+	if configMetadata.Options != nil && configMetadata.Options.MaxEnvelopeBytes != atomic.LoadUint32(&c.maxEnvelopeBytes) {
+		old := atomic.SwapUint32(&c.maxEnvelopeBytes, configMetadata.Options.MaxEnvelopeBytes)
+		c.logger.Infof("Maximum envelope size is updated to %d bytes (was %d)", configMetadata.Options.MaxEnvelopeBytes, old)
+	}
+
+	if configMetadata.Options != nil {
+		c.updateTLSPolicy(configMetadata.Options)
+	}
+
+	c.drReplicator.setTargets(configMetadata.DrConsenters)
+
+	changes, err := ComputeMembershipChanges(c.raftMetadata(), configMetadata.Consenters)
 	if err != nil {
 		c.logger.Panicf("illegal configuration change detected: %s", err)
 	}
@@ -987,6 +2697,11 @@ func (c *Chain) detectConfChange(block *common.Block) *MembershipChanges {
 		c.logger.Infof("Config block %d rotates TLS certificate of node %d", block.Header.Number, changes.RotatedNode)
 	}
 
+	for _, nodeID := range changes.MovedNodes {
+		consenter := changes.NewBlockMetadata.Consenters[nodeID]
+		c.logger.Infof("Config block %d moves node %d to %s:%d", block.Header.Number, nodeID, consenter.Host, consenter.Port)
+	}
+
 	return changes
 }
 
@@ -999,6 +2714,10 @@ func (c *Chain) apply(ents []raftpb.Entry) {
 		c.logger.Panicf("first index of committed entry[%d] should <= appliedIndex[%d]+1", ents[0].Index, c.appliedIndex)
 	}
 
+	if err := c.Node.faults.Apply(ents); err != nil {
+		c.logger.Panicf("Failed to apply committed entries: %s", err)
+	}
+
 	var appliedb uint64
 	var position int
 	for i := range ents {
@@ -1015,7 +2734,13 @@ func (c *Chain) apply(ents []raftpb.Entry) {
 				break
 			}
 
+			if marker := entryMarker(ents[i].Data); marker != nil {
+				c.applyMarker(marker, ents[i].Index)
+				break
+			}
+
 			block := utils.UnmarshalBlockOrPanic(ents[i].Data)
+			c.applyQuota.wait(1, c.haltingC)
 			c.writeBlock(block, ents[i].Index)
 
 			appliedb = block.Header.Number
@@ -1030,7 +2755,8 @@ func (c *Chain) apply(ents []raftpb.Entry) {
 				continue
 			}
 
-			c.confState = *c.Node.ApplyConfChange(cc)
+			c.setConfState(*c.Node.ApplyConfChange(cc))
+			c.confChangeRetrier.applied(cc)
 
 			switch cc.Type {
 			case raftpb.ConfChangeAddNode:
@@ -1047,21 +2773,24 @@ func (c *Chain) apply(ents []raftpb.Entry) {
 				c.confChangeInProgress.NodeID == cc.NodeID &&
 				c.confChangeInProgress.Type == cc.Type {
 
+				start := c.clock.Now()
 				if err := c.configureComm(); err != nil {
 					c.logger.Panicf("Failed to configure communication: %s", err)
 				}
+				c.timeConfigPhase(ConfigPhaseConfigureComm, start, "conf_change", cc.Type.String(), "node", cc.NodeID)
 
 				c.confChangeInProgress = nil
-				c.configInflight = false
+				c.setConfigInflight(false)
 				// report the new cluster size
-				c.Metrics.ClusterSize.Set(float64(len(c.opts.BlockMetadata.Consenters)))
+				c.Metrics.ClusterSize.Set(float64(len(c.raftMetadata().Consenters)))
 			}
 
 			if cc.Type == raftpb.ConfChangeRemoveNode && cc.NodeID == c.raftID {
 				c.logger.Infof("Current node removed from replica set for channel %s", c.channelID)
+				c.notify(Event{Type: EventEviction, RemovedNode: c.raftID})
 				// calling goroutine, since otherwise it will be blocked
 				// trying to write into haltC
-				go c.Halt()
+				c.goroutines.spawn("halt", c.Halt)
 			}
 		}
 
@@ -1070,14 +2799,25 @@ func (c *Chain) apply(ents []raftpb.Entry) {
 		}
 	}
 
+	atomic.StoreUint64(&c.writtenIndex, c.appliedIndex)
+	c.Metrics.CommitBacklog.Set(float64(c.commitBacklog()))
+
 	if appliedb == 0 {
 		// no block has been written (appliedb == 0) in this round
 		return
 	}
 
 	if c.accDataSize >= c.sizeLimit {
+		g := &gc{index: c.appliedIndex, state: c.confState, data: ents[position].Data}
+		// Nodes lacking the capability cannot read snapshots referencing an archive.
+		if c.opts.ArchiveReference != nil && c.support.SharedConfig().Capabilities().SnapshotArchives() {
+			if ref := c.opts.ArchiveReference(appliedb); ref != nil {
+				g.data, g.block, g.archive = nil, captureBlock(c.lastBlock), ref
+			}
+		}
+
 		select {
-		case c.gcC <- &gc{index: c.appliedIndex, state: c.confState, data: ents[position].Data}:
+		case c.gcC <- g:
 			c.logger.Infof("Accumulated %d bytes since last snapshot, exceeding size limit (%d bytes), "+
 				"taking snapshot at block %d, last snapshotted block number is %d, nodes: %+v",
 				c.accDataSize, c.sizeLimit, appliedb, c.lastSnapBlockNum, c.confState.Nodes)
@@ -1096,7 +2836,23 @@ func (c *Chain) gc() {
 	for {
 		select {
 		case g := <-c.gcC:
-			c.Node.takeSnapshot(g.index, g.state, g.data)
+			if !c.Node.snapshots.wait(g.index, c.doneC) {
+				c.logger.Infof("Stop garbage collecting")
+				return
+			}
+			data := g.snapshotData
+			if c.opts.SnapshotContent == SnapshotContentHeader {
+				data = g.snapshotHeaderData
+			}
+			err := c.Node.takeSnapshot(g.index, g.state, data())
+			if err == nil {
+				if snapshot, err := c.Node.storage.ram.Snapshot(); err == nil {
+					c.drReplicator.snapshotted(snapshot)
+				}
+			}
+			if g.doneC != nil {
+				g.doneC <- err
+			}
 		case <-c.doneC:
 			c.logger.Infof("Stop garbage collecting")
 			return
@@ -1125,12 +2881,82 @@ func (c *Chain) configureComm() error {
 	}
 
 	c.configurator.Configure(c.channelID, nodes)
+	c.auditTrust(nodes)
+	c.reportOrgs()
 	return nil
 }
 
+// reportOrgs publishes the organizations the consenters are bound to,
+// whenever the consenters or their organizations change.
+func (c *Chain) reportOrgs() {
+	orgs := make(map[uint64]string)
+	for raftID, consenter := range c.raftMetadata().Consenters {
+		orgs[raftID] = consenter.MspId
+	}
+
+	for raftID, org := range c.orgs {
+		if updated, exists := orgs[raftID]; !exists || updated != org {
+			c.Metrics.ConsenterOrg.With("peer", strconv.FormatUint(raftID, 10), "org", org).Set(0)
+		}
+	}
+	for raftID, org := range orgs {
+		if reported, exists := c.orgs[raftID]; exists && reported == org {
+			continue
+		}
+		if org != "" {
+			c.logger.Infof("Consenter %d is bound to organization %s", raftID, org)
+		}
+		c.Metrics.ConsenterOrg.With("peer", strconv.FormatUint(raftID, 10), "org", org).Set(1)
+	}
+	c.orgs = orgs
+}
+
+// auditTrust records the remote nodes the communication layer is configured with,
+// along with the fingerprints of their TLS certificates, whenever they change.
+func (c *Chain) auditTrust(nodes []cluster.RemoteNode) {
+	trusted := trustedNodes(nodes)
+	if c.trusted != nil && sameTrust(c.trusted, trusted) {
+		return
+	}
+	c.trusted = trusted
+
+	now := c.clock.Now()
+	c.Metrics.TrustChangedTime.Set(float64(now.Unix()))
+	c.logger.Infof("Communication is configured with %d remote nodes", len(trusted))
+
+	if c.opts.TrustAuditLog == nil {
+		return
+	}
+
+	record, err := signTrustRecord(&TrustRecord{
+		Channel: c.channelID,
+		NodeID:  c.raftID,
+		Time:    now.UTC(),
+		Nodes:   trusted,
+	}, c.support)
+	if err != nil {
+		c.logger.Errorf("Failed to sign trust record: %s", err)
+		return
+	}
+	if err := c.opts.TrustAuditLog.Append(record); err != nil {
+		c.logger.Errorf("Failed to record trusted remote nodes: %s", err)
+	}
+}
+
+// remoteConsenters returns the raft IDs of the consenters other than this node.
+func (c *Chain) remoteConsenters() []uint64 {
+	var nodes []uint64
+	for raftID := range c.raftMetadata().Consenters {
+		if raftID != c.raftID {
+			nodes = append(nodes, raftID)
+		}
+	}
+	return nodes
+}
+
 func (c *Chain) remotePeers() ([]cluster.RemoteNode, error) {
 	var nodes []cluster.RemoteNode
-	for raftID, consenter := range c.opts.BlockMetadata.Consenters {
+	for raftID, consenter := range c.raftMetadata().Consenters {
 		// No need to know yourself
 		if raftID == c.raftID {
 			continue
@@ -1148,11 +2974,30 @@ func (c *Chain) remotePeers() ([]cluster.RemoteNode, error) {
 			Endpoint:      fmt.Sprintf("%s:%d", consenter.Host, consenter.Port),
 			ServerTLSCert: serverCertAsDER,
 			ClientTLSCert: clientCertAsDER,
+			TLSPolicy:     c.tlsPolicy,
 		})
 	}
 	return nodes, nil
 }
 
+// updateTLSPolicy applies the TLS policy of the given options to the
+// communication with the other consenters, if it changed.
+func (c *Chain) updateTLSPolicy(options *etcdraft.Options) {
+	policy, err := cluster.NewTLSPolicy(options.TlsMinVersion, options.TlsCipherSuites)
+	if err != nil {
+		c.logger.Errorf("Ignoring invalid TLS policy: %s", err)
+		return
+	}
+	if policy.Equal(c.tlsPolicy) {
+		return
+	}
+	c.logger.Infof("TLS policy of the communication with the other consenters is updated to %s (was %s)", policy, c.tlsPolicy)
+	c.tlsPolicy = policy
+	if err := c.configureComm(); err != nil {
+		c.logger.Panicf("Failed to configure communication: %s", err)
+	}
+}
+
 func (c *Chain) pemToDER(pemBytes []byte, id uint64, certType string) ([]byte, error) {
 	bl, _ := pem.Decode(pemBytes)
 	if bl == nil {
@@ -1170,15 +3015,43 @@ func (c *Chain) checkConsentersSet(configValue *common.ConfigValue) error {
 		return err
 	}
 
-	if err := MetadataHasDuplication(updatedMetadata); err != nil {
-		return err
+	if errs := c.consentersSetErrors(updatedMetadata); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// consentersSetErrors returns the reasons for which the consenters of the chain
+// cannot be updated to the given metadata, or nil if they can.
+func (c *Chain) consentersSetErrors(updatedMetadata *etcdraft.ConfigMetadata) []error {
+	v := raftconfig.MetadataValidation{ExpectHosts: true, Now: c.clock.Now()}
+	current := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(c.support.SharedConfig().ConsensusMetadata(), current); err == nil {
+		v.Current = append(append(current.Consenters, current.StandbyConsenters...), current.DrConsenters...)
+	}
+	errs := v.Errors(updatedMetadata)
+	if updatedMetadata == nil {
+		return errs
 	}
 
-	c.raftMetadataLock.RLock()
-	_, err = ComputeMembershipChanges(c.opts.BlockMetadata, updatedMetadata.Consenters)
-	c.raftMetadataLock.RUnlock()
+	if err := raftconfig.ValidateElectionOptions(updatedMetadata.Options); err != nil {
+		errs = append(errs, err)
+	}
 
-	return err
+	if err := raftconfig.ValidateTLSOptions(updatedMetadata.Options); err != nil {
+		errs = append(errs, err)
+	}
+
+	if updatedMetadata.Options != nil && updatedMetadata.Options.ProposalForwarding != c.opts.ProposalForwarding {
+		errs = append(errs, errors.Errorf("proposal forwarding cannot be changed from %t to %t, all nodes must agree on it",
+			c.opts.ProposalForwarding, updatedMetadata.Options.ProposalForwarding))
+	}
+
+	if _, err := ComputeMembershipChanges(c.raftMetadata(), updatedMetadata.Consenters); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
 }
 
 // writeConfigBlock writes configuration blocks into the ledger in
@@ -1190,65 +3063,75 @@ func (c *Chain) writeConfigBlock(block *common.Block, index uint64) {
 		c.logger.Panicf("Failed to get config header type from config block: %s", err)
 	}
 
-	c.configInflight = false
+	c.setConfigInflight(false)
 
 	switch common.HeaderType(hdr.Type) {
 	case common.HeaderType_CONFIG:
+		timer := c.newConfigTimer(block.Header.Number)
+		defer timer.done()
+
 		configMembership := c.detectConfChange(block)
+		timer.phase(ConfigPhaseDetectConfChange)
 
-		c.raftMetadataLock.Lock()
+		blockMetadata := c.raftMetadata()
 		if configMembership != nil {
-			c.opts.BlockMetadata = configMembership.NewBlockMetadata
+			blockMetadata = configMembership.NewBlockMetadata
 		}
-		c.opts.BlockMetadata.RaftIndex = index
-		c.raftMetadataLock.Unlock()
+		blockMetadataBytes := c.updateRaftMetadata(blockMetadata, block, index)
 
-		blockMetadataBytes := utils.MarshalOrPanic(c.opts.BlockMetadata)
 		// write block with metadata
 		c.support.WriteConfigBlock(block, blockMetadataBytes)
+		c.forwarder.configSequenceAdvanced()
+		c.reportOrgs()
+		timer.phase(ConfigPhaseWriteBlock)
 
 		if configMembership == nil {
 			return
 		}
+		if configMembership.Changed() {
+			c.warnLargeCluster(len(configMembership.NewBlockMetadata.Consenters))
+		}
 
 		// update membership
 		if configMembership.ConfChange != nil {
 			// We need to propose conf change in a go routine, because it may be blocked if raft node
 			// becomes leaderless, and we should not block `serveRequest` so it can keep consuming applyC,
 			// otherwise we have a deadlock.
-			go func() {
+			start := c.clock.Now()
+			c.goroutines.spawn("propose_conf_change", func() {
 				// ProposeConfChange returns error only if node being stopped.
 				// This proposal is dropped by followers because DisableProposalForwarding is enabled.
 				if err := c.Node.ProposeConfChange(context.TODO(), *configMembership.ConfChange); err != nil {
 					c.logger.Warnf("Failed to propose configuration update to Raft node: %s", err)
 				}
-			}()
+				c.timeConfigPhase(ConfigPhaseProposeConfChange, start, "block", block.Header.Number)
+			})
 
 			c.confChangeInProgress = configMembership.ConfChange
+			c.confChangeRetrier.proposed(configMembership.ConfChange)
 
 			switch configMembership.ConfChange.Type {
 			case raftpb.ConfChangeAddNode:
 				c.logger.Infof("Config block just committed adds node %d, pause accepting transactions till config change is applied", configMembership.ConfChange.NodeID)
+				c.notify(Event{Type: EventMembershipChange, AddedNode: configMembership.ConfChange.NodeID})
 			case raftpb.ConfChangeRemoveNode:
 				c.logger.Infof("Config block just committed removes node %d, pause accepting transactions till config change is applied", configMembership.ConfChange.NodeID)
+				c.notify(Event{Type: EventMembershipChange, RemovedNode: configMembership.ConfChange.NodeID})
 			default:
 				c.logger.Panic("Programming error, encountered unsupported raft config change")
 			}
 
-			c.configInflight = true
-		} else if configMembership.Rotated() {
+			c.setConfigInflight(true)
+		} else if configMembership.Rotated() || len(configMembership.MovedNodes) > 0 {
 			if err := c.configureComm(); err != nil {
 				c.logger.Panicf("Failed to configure communication: %s", err)
 			}
+			timer.phase(ConfigPhaseConfigureComm)
 		}
 
 	case common.HeaderType_ORDERER_TRANSACTION:
 		// If this config is channel creation, no extra inspection is needed
-		c.raftMetadataLock.Lock()
-		c.opts.BlockMetadata.RaftIndex = index
-		m := utils.MarshalOrPanic(c.opts.BlockMetadata)
-		c.raftMetadataLock.Unlock()
-
+		m := c.updateRaftMetadata(c.raftMetadata(), block, index)
 		c.support.WriteConfigBlock(block, m)
 
 	default:
@@ -1284,7 +3167,7 @@ func (c *Chain) getInFlightConfChange() *raftpb.ConfChange {
 	// extracting current Raft configuration state
 	confState := c.Node.ApplyConfChange(raftpb.ConfChange{})
 
-	if len(confState.Nodes) == len(c.opts.BlockMetadata.Consenters) {
+	if len(confState.Nodes) == len(c.raftMetadata().Consenters) {
 		// since configuration change could only add one node or
 		// remove one node at a time, if raft nodes state size
 		// equal to membership stored in block metadata field,
@@ -1293,7 +3176,7 @@ func (c *Chain) getInFlightConfChange() *raftpb.ConfChange {
 		return nil
 	}
 
-	return ConfChange(c.opts.BlockMetadata, confState)
+	return ConfChange(c.raftMetadata(), confState)
 }
 
 // newMetadata extract config metadata from the configuration block
@@ -1313,6 +3196,13 @@ func (c *Chain) suspectEviction() bool {
 	return atomic.LoadUint64(&c.lastKnownLeader) == uint64(0)
 }
 
+// EvictionSuspicion returns the state of the eviction suspector of the chain:
+// since when it suspects the eviction of the node, and the last decision it
+// took once the suspicion exceeded its threshold.
+func (c *Chain) EvictionSuspicion() *EvictionSuspicion {
+	return c.evictionSuspector.suspicion()
+}
+
 func (c *Chain) newEvictionSuspector() *evictionSuspector {
 	return &evictionSuspector{
 		amIInChannel:               ConsenterCertificate(c.opts.Cert).IsConsenterOfChannel,
@@ -1322,10 +3212,138 @@ func (c *Chain) newEvictionSuspector() *evictionSuspector {
 		height:                     c.support.Height,
 		triggerCatchUp:             c.triggerCatchup,
 		logger:                     c.logger,
+		metrics:                    c.Metrics,
 		halt: func() {
 			c.Halt()
 		},
+		evicted: func() {
+			c.notify(Event{Type: EventEviction, RemovedNode: c.raftID})
+		},
+	}
+}
+
+// confChangeStalled reports the ConfChange in flight, which was not applied within the
+// ConfChangeTimeout, along with the raft progress of the consenters, since the node
+// refuses transactions until it is applied.
+func (c *Chain) confChangeStalled(cc *raftpb.ConfChange) {
+	status := c.Node.Status()
+	ids := make([]uint64, 0, len(status.Progress))
+	for id := range status.Progress {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	var progress []string
+	for _, id := range ids {
+		pr := status.Progress[id]
+		progress = append(progress, fmt.Sprintf("node %d: %s", id, pr.String()))
+	}
+
+	c.logger.Errorf("%s of node %d was not applied within %s, transactions are refused until it is; "+
+		"raft state is %s, term %d, leader %d, committed index %d, applied index %d, progress: [%s]",
+		cc.Type, cc.NodeID, c.opts.ConfChangeTimeout, status.RaftState, status.Term, status.Lead,
+		status.Commit, status.Applied, strings.Join(progress, "; "))
+
+	atomic.StoreUint32(&c.stalled, 1)
+	c.Metrics.ConfChangeStalled.Set(1)
+	event := Event{Type: EventConfChangeStalled, Cause: fmt.Sprintf("not applied within %s", c.opts.ConfChangeTimeout)}
+	if cc.Type == raftpb.ConfChangeRemoveNode {
+		event.RemovedNode = cc.NodeID
+	} else {
+		event.AddedNode = cc.NodeID
 	}
+	c.notify(event)
+}
+
+// reproposeConfChange proposes the given ConfChange again, which is done in a
+// goroutine for the reason documented in the `writeConfigBlock` method.
+func (c *Chain) reproposeConfChange(cc raftpb.ConfChange) {
+	c.goroutines.spawn("propose_conf_change", func() {
+		if err := c.Node.ProposeConfChange(context.TODO(), cc); err != nil {
+			c.logger.Warnf("Failed to propose configuration update to Raft node: %s", err)
+		}
+	})
+}
+
+// confChangeResumed clears the report of the given ConfChange as stalled, if any,
+// once it is no longer in flight.
+func (c *Chain) confChangeResumed(cc *raftpb.ConfChange) {
+	if atomic.SwapUint32(&c.stalled, 0) == 0 {
+		return
+	}
+	c.logger.Infof("Stalled %s of node %d is no longer in flight", cc.Type, cc.NodeID)
+	c.Metrics.ConfChangeStalled.Set(0)
+}
+
+// notify records the event in the event history of the chain,
+// and notifies the Notifier and the event stream of the chain, if any, of it.
+func (c *Chain) notify(event Event) {
+	event.Channel = c.channelID
+	event.NodeID = c.raftID
+	event.Time = c.clock.Now()
+	c.events.record(event)
+	if c.eventStream != nil {
+		c.eventStream.Notify(event)
+	}
+	if c.opts.Notifier == nil {
+		return
+	}
+	c.opts.Notifier.Notify(event)
+}
+
+func (c *Chain) newStatusReporter() *statusReporter {
+	interval := DefaultStatusReportInterval
+	if c.opts.StatusReportInterval != 0 {
+		interval = c.opts.StatusReportInterval
+	}
+
+	return &statusReporter{
+		interval: interval,
+		clock:    c.clock,
+		status:   c.Node.Status,
+		metrics:  c.Metrics,
+		lag:      c.lag,
+		doneC:    c.doneC,
+
+		reportEstimate:   c.reportOrderingEstimate,
+		evaluateDegraded: c.evaluateDegraded,
+	}
+}
+
+// evaluateDegraded classifies this node, and the peers tracked
+// in the given raft status if it leads the chain, as degraded.
+func (c *Chain) evaluateDegraded(s raft.Status) {
+	var peers []uint64
+	for id := range s.Progress {
+		if id != s.ID {
+			peers = append(peers, id)
+		}
+	}
+	c.grayFailures.evaluate(peers, c.Node.isUnreachable)
+}
+
+// reresolve re-resolves the endpoint of the given peer in the background,
+// if the communication layer supports it, so that a peer whose endpoint
+// moved to other addresses is reconnected to without a config update.
+func (c *Chain) reresolve(id uint64) {
+	reresolver, ok := c.configurator.(Reresolver)
+	if !ok {
+		return
+	}
+	if _, inFlight := c.reresolving.LoadOrStore(id, struct{}{}); inFlight {
+		return
+	}
+
+	c.goroutines.spawn("reresolve", func() {
+		defer c.reresolving.Delete(id)
+		reconnected, err := reresolver.Reresolve(c.channelID, id)
+		if err != nil {
+			c.logger.Warnf("Failed to re-resolve the endpoint of %d: %s", id, err)
+			return
+		}
+		if reconnected {
+			c.logger.Infof("Reconnecting to %d, as its endpoint resolves to other addresses", id)
+		}
+	})
 }
 
 func (c *Chain) triggerCatchup(sn *raftpb.Snapshot) {
@@ -7,9 +7,16 @@ SPDX-License-Identifier: Apache-2.0
 package etcdraft_test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"io/ioutil"
+	"math"
+	"math/big"
 	"os"
 	"os/user"
 	"path"
@@ -21,6 +28,7 @@ import (
 	"github.com/hyperledger/fabric/bccsp/factory"
 	"github.com/hyperledger/fabric/common/crypto/tlsgen"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
@@ -33,6 +41,7 @@ import (
 	"github.com/hyperledger/fabric/protos/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/types"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/mock"
@@ -159,6 +168,7 @@ var _ = Describe("Chain", func() {
 				WALDir:          walDir,
 				SnapDir:         snapDir,
 				Metrics:         newFakeMetrics(fakeFields),
+				Features:        etcdraft.SupportedFeatures(),
 			}
 		})
 
@@ -196,6 +206,7 @@ var _ = Describe("Chain", func() {
 		AfterEach(func() {
 			chain.Halt()
 			Eventually(chain.Errored, LongEventualTimeout).Should(BeClosed())
+			Expect(chain.WaitGoroutines(LongEventualTimeout)).To(Succeed())
 			os.RemoveAll(dataDir)
 		})
 
@@ -205,6 +216,26 @@ var _ = Describe("Chain", func() {
 				configurator.AssertCalled(testingInstance, "Configure", channelID, expectedNodeConfig)
 			})
 
+			It("surfaces the goroutines it owns until halted", func() {
+				goroutines := chain.Goroutines()
+				for _, name := range []string{"gc", "serve", "raft_node", "status_reporter", "drift_checker", "feature_negotiator"} {
+					Expect(goroutines).To(HaveKeyWithValue(name, 1))
+				}
+				Expect(fakeFields.fakeGoroutines.SetCallCount()).NotTo(BeZero())
+
+				chain.Halt()
+				Expect(chain.WaitGoroutines(LongEventualTimeout)).To(Succeed())
+				Expect(chain.Goroutines()).To(BeEmpty())
+				Expect(fakeFields.fakeGoroutines.SetArgsForCall(fakeFields.fakeGoroutines.SetCallCount() - 1)).To(Equal(float64(0)))
+			})
+
+			It("surfaces the state of its eviction suspector", func() {
+				suspicion := chain.EvictionSuspicion()
+				Expect(suspicion).NotTo(BeNil())
+				Expect(suspicion.Threshold).To(Equal(opts.EvictionSuspicion.String()))
+				Expect(chain.Info().EvictionSuspicion.Threshold).To(Equal(opts.EvictionSuspicion.String()))
+			})
+
 			It("correctly sets the metrics labels and publishes requisite metrics", func() {
 				type withImplementers interface {
 					WithCallCount() int
@@ -220,6 +251,55 @@ var _ = Describe("Chain", func() {
 					fakeFields.fakeDataPersistDuration,
 					fakeFields.fakeNormalProposalsReceived,
 					fakeFields.fakeConfigProposalsReceived,
+					fakeFields.fakeTerm,
+					fakeFields.fakeCommitIndex,
+					fakeFields.fakeAppliedIndex,
+					fakeFields.fakePeerProgressState,
+					fakeFields.fakeProposeQueueDepth,
+					fakeFields.fakeProposeWaitDuration,
+					fakeFields.fakeTrustChangedTime,
+					fakeFields.fakeIsPaused,
+					fakeFields.fakeBlockMismatches,
+					fakeFields.fakeCommitBacklog,
+					fakeFields.fakePeerUnreachable,
+					fakeFields.fakePeerReachable,
+					fakeFields.fakeQuotaThrottled,
+					fakeFields.fakeMembershipDrift,
+					fakeFields.fakeWedged,
+					fakeFields.fakeConfChangeStalled,
+					fakeFields.fakeConfigApplyDuration,
+					fakeFields.fakeSnapshotReclaimedBytes,
+					fakeFields.fakeValidationCacheHits,
+					fakeFields.fakeValidationCacheMisses,
+					fakeFields.fakeWALFsyncs,
+					fakeFields.fakeWALAppendedBytes,
+					fakeFields.fakeWALSegmentsCreated,
+					fakeFields.fakeSnapshotFilesWritten,
+					fakeFields.fakeSnapshotsDeferred,
+					fakeFields.fakeEvictionSuspected,
+					fakeFields.fakeEvictionSuspicionDuration,
+					fakeFields.fakeEvictionsConfirmed,
+					fakeFields.fakeEvictionBlocksPulled,
+					fakeFields.fakePendingBatchMessages,
+					fakeFields.fakePendingBatchBytes,
+					fakeFields.fakePendingBatchStartTime,
+					fakeFields.fakeBlocksInFlight,
+					fakeFields.fakeBytesInFlight,
+					fakeFields.fakeEstimatedTimeToOrder,
+					fakeFields.fakeElectionStorms,
+					fakeFields.fakeElectionTimeoutFactor,
+					fakeFields.fakeAppendAckLatency,
+					fakeFields.fakeNodeDegraded,
+					fakeFields.fakeConfChangeReproposals,
+					fakeFields.fakeConfChangeRetriesExhausted,
+					fakeFields.fakeEnvelopesRejected,
+					fakeFields.fakeDRReplicationLag,
+					fakeFields.fakeDRSendFailures,
+					fakeFields.fakeBlockTimeViolations,
+					fakeFields.fakeGoroutines,
+					fakeFields.fakePendingConfigUpdates,
+					fakeFields.fakeReorderHolds,
+					fakeFields.fakeConsensusMsgsDropped,
 				}
 				for _, m := range metricsList {
 					Expect(m.WithCallCount()).To(Equal(1))
@@ -233,12 +313,117 @@ var _ = Describe("Chain", func() {
 				Expect(fakeFields.fakeIsLeader.SetCallCount()).To(Equal(1))
 				Expect(fakeFields.fakeIsLeader.SetArgsForCall(0)).To(Equal(float64(0)))
 			})
+
+			It("publishes the organizations of the consenters", func() {
+				Expect(fakeFields.fakeConsenterOrg.WithArgsForCall(0)).To(Equal([]string{"channel", channelID}))
+				Expect(fakeFields.fakeConsenterOrg.WithArgsForCall(1)).To(Equal([]string{"peer", "1", "org", ""}))
+				Expect(fakeFields.fakeConsenterOrg.SetArgsForCall(0)).To(Equal(float64(1)))
+				Expect(chain.Info().Orgs).To(BeNil())
+			})
+		})
+
+		Context("when the consenters are bound to organizations", func() {
+			BeforeEach(func() {
+				opts.BlockMetadata.Consenters[1].MspId = "OrdererOrg1"
+			})
+
+			It("publishes the organizations of the consenters", func() {
+				Expect(fakeFields.fakeConsenterOrg.WithArgsForCall(1)).To(Equal([]string{"peer", "1", "org", "OrdererOrg1"}))
+				Expect(fakeFields.fakeConsenterOrg.SetArgsForCall(0)).To(Equal(float64(1)))
+				Expect(chain.Info().Orgs).To(Equal(map[uint64]string{1: "OrdererOrg1"}))
+			})
+		})
+
+		Context("when a trust audit log is set", func() {
+			BeforeEach(func() {
+				opts.TrustAuditLog = &etcdraft.TrustAuditLog{Path: path.Join(dataDir, "trust.log")}
+				support.NewSignatureHeaderReturns(&common.SignatureHeader{Creator: []byte("orderer")}, nil)
+				support.SignReturns([]byte("signature"), nil)
+			})
+
+			It("records the remote nodes it trusts", func() {
+				Expect(fakeFields.fakeTrustChangedTime.SetCallCount()).To(Equal(1))
+				Expect(fakeFields.fakeTrustChangedTime.SetArgsForCall(0)).To(Equal(float64(clock.Now().Unix())))
+
+				auditLog, err := ioutil.ReadFile(opts.TrustAuditLog.Path)
+				Expect(err).NotTo(HaveOccurred())
+				signed := &etcdraft.SignedTrustRecord{}
+				Expect(json.Unmarshal(auditLog, signed)).To(Succeed())
+				Expect(signed.Signature).To(Equal([]byte("signature")))
+
+				record := &etcdraft.TrustRecord{}
+				Expect(json.Unmarshal(signed.Record, record)).To(Succeed())
+				Expect(record.Channel).To(Equal(channelID))
+				Expect(record.NodeID).To(Equal(uint64(1)))
+				Expect(record.Nodes).To(BeEmpty())
+			})
+		})
+
+		Context("when in-memory storage is set", func() {
+			BeforeEach(func() {
+				opts.InMemoryStorage = true
+				opts.WALDir = ""
+				opts.SnapDir = ""
+			})
+
+			It("orders blocks without persisting raft data", func() {
+				campaign(chain, observeC)
+				close(cutter.Block)
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				files, err := ioutil.ReadDir(dataDir)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(files).To(BeEmpty())
+			})
+
+			It("refuses to resume a chain from a ledger written by raft", func() {
+				opts.BlockMetadata.RaftIndex = 5
+				_, err := etcdraft.NewChain(support, opts, configurator, nil, noOpBlockPuller, nil)
+				Expect(err).To(MatchError("in-memory storage cannot resume a chain from a ledger written by raft (raft index 5), " +
+					"the ledger must be removed as well"))
+			})
+		})
+
+		Context("when the log level is set for the channel", func() {
+			var (
+				logging *flogging.Logging
+				buf     *gbytes.Buffer
+			)
+
+			BeforeEach(func() {
+				buf = gbytes.NewBuffer()
+				var err error
+				logging, err = flogging.New(flogging.Config{Format: "json", LogSpec: "error", Writer: buf})
+				Expect(err).NotTo(HaveOccurred())
+				opts.Logger = logging.Logger("orderer.consensus.etcdraft")
+			})
+
+			It("adjusts the level of the chain logger at runtime", func() {
+				campaign(chain, observeC)
+				Consistently(buf).ShouldNot(gbytes.Say("Raft leader changed"))
+
+				Expect(logging.ActivateSpec("error:orderer.consensus.etcdraft.another-channel=debug")).To(Succeed())
+				close(cutter.Block)
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Consistently(buf).ShouldNot(gbytes.Say("Writing block 1 to ledger"))
+
+				Expect(logging.ActivateSpec("error:orderer.consensus.etcdraft." + channelID + "=debug")).To(Succeed())
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+				Eventually(buf).Should(gbytes.Say(`"name":"orderer.consensus.etcdraft.` + channelID + `".*"msg":"Writing block 2 to ledger"`))
+			})
 		})
 
 		Context("when no Raft leader is elected", func() {
 			It("fails to order envelope", func() {
 				err := chain.Order(env, 0)
 				Expect(err).To(MatchError("no Raft leader"))
+				Expect(err).To(Equal(&etcdraft.LeaderError{Err: errors.Cause(err)}))
 				Expect(fakeFields.fakeNormalProposalsReceived.AddCallCount()).To(Equal(1))
 				Expect(fakeFields.fakeNormalProposalsReceived.AddArgsForCall(0)).To(Equal(float64(1)))
 				Expect(fakeFields.fakeConfigProposalsReceived.AddCallCount()).To(Equal(0))
@@ -255,6 +440,15 @@ var _ = Describe("Chain", func() {
 				}
 				Eventually(observeC, LongEventualTimeout).Should(Receive(StateEqual(1, raft.StateLeader)))
 			})
+
+			It("refuses to repair the membership", func() {
+				Expect(chain.RepairMembership()).To(MatchError("node 1 is not the leader, the leader is node 0"))
+			})
+
+			It("refuses to propose markers", func() {
+				Expect(chain.ProposeMarker(raftprotos.Marker_PAUSE, "maintenance")).To(MatchError("node 1 is not the leader, the leader is node 0"))
+				Expect(chain.ProposeMarker(raftprotos.Marker_UNKNOWN, "")).To(MatchError("unknown marker type 0"))
+			})
 		})
 
 		Context("when Raft leader is elected", func() {
@@ -269,6 +463,60 @@ var _ = Describe("Chain", func() {
 				Expect(fakeFields.fakeLeaderChanges.AddArgsForCall(0)).To(Equal(float64(1)))
 			})
 
+			It("reports its role and last commit time", func() {
+				info := chain.Info()
+				Expect(info.Channel).To(Equal(channelID))
+				Expect(info.RaftID).To(Equal(uint64(1)))
+				Expect(info.Role).To(Equal("leader"))
+				Expect(info.LastCommitTime.IsZero()).To(BeTrue())
+
+				close(cutter.Block)
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Expect(chain.Info().LastCommitTime.Equal(clock.Now())).To(BeTrue())
+
+				chain.Halt()
+				Expect(chain.Info().Role).To(Equal("stopped"))
+			})
+
+			Context("when serve state hooks are set", func() {
+				var transitions chan etcdraft.ServeTransition
+
+				BeforeEach(func() {
+					transitions = make(chan etcdraft.ServeTransition, 100)
+					opts.ServeStateHooks = []etcdraft.ServeStateHook{func(transition etcdraft.ServeTransition) {
+						transitions <- transition
+					}}
+				})
+
+				It("reports the transitions of the chain between serve states", func() {
+					Eventually(func() string { return chain.Info().ServeState }, LongEventualTimeout).Should(Equal("leading-idle"))
+
+					By("ordering a transaction which is cut into a block by the batch timeout")
+					close(cutter.Block)
+					timeout := time.Second
+					support.SharedConfigReturns(&mockconfig.Orderer{BatchTimeoutVal: timeout})
+					Expect(chain.Order(env, 0)).To(Succeed())
+					clock.WaitForNWatchersAndIncrement(timeout, 3)
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Eventually(func() string { return chain.Info().ServeState }, LongEventualTimeout).Should(Equal("leading-idle"))
+
+					var states []etcdraft.ServeState
+					for len(transitions) > 0 {
+						transition := <-transitions
+						Expect(transition.Sequence).To(Equal(uint64(len(states) + 1)))
+						states = append(states, transition.To)
+					}
+					Expect(states).To(Equal([]etcdraft.ServeState{
+						etcdraft.ServeStateCandidateObserved,
+						etcdraft.ServeStateLeadingIdle,
+						etcdraft.ServeStateLeadingBusy,
+						etcdraft.ServeStateLeadingIdle,
+					}))
+				})
+			})
+
 			It("fails to order envelope if chain is halted", func() {
 				chain.Halt()
 				err := chain.Order(env, 0)
@@ -312,7 +560,7 @@ var _ = Describe("Chain", func() {
 				Expect(fakeFields.fakeNormalProposalsReceived.AddCallCount()).To(Equal(2))
 				Expect(fakeFields.fakeNormalProposalsReceived.AddArgsForCall(1)).To(Equal(float64(1)))
 
-				clock.WaitForNWatchersAndIncrement(timeout, 2)
+				clock.WaitForNWatchersAndIncrement(timeout, 3)
 				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
 				Expect(fakeFields.fakeCommittedBlockNumber.SetCallCount()).Should(Equal(2))
 				Expect(fakeFields.fakeCommittedBlockNumber.SetArgsForCall(1)).Should(Equal(float64(2)))
@@ -320,6 +568,670 @@ var _ = Describe("Chain", func() {
 				Expect(fakeFields.fakeDataPersistDuration.ObserveArgsForCall(3)).Should(Equal(float64(0)))
 			})
 
+			It("publishes propose queue metrics", func() {
+				close(cutter.Block)
+
+				cutter.CutNext = true
+				err := chain.Order(env, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				// The duration being emitted is zero since we don't tick the fake clock during this time
+				Expect(fakeFields.fakeProposeWaitDuration.ObserveCallCount()).Should(Equal(1))
+				Expect(fakeFields.fakeProposeWaitDuration.ObserveArgsForCall(0)).Should(Equal(float64(0)))
+
+				// depth is reset on election, then set when the block is enqueued and dequeued
+				Expect(fakeFields.fakeProposeQueueDepth.SetCallCount()).Should(Equal(3))
+				Expect(fakeFields.fakeProposeQueueDepth.SetArgsForCall(0)).Should(Equal(float64(0)))
+			})
+
+			It("describes the pending batch", func() {
+				close(cutter.Block)
+				timeout := time.Second
+				support.SharedConfigReturns(&mockconfig.Orderer{BatchTimeoutVal: timeout})
+
+				lastSet := func(g *metricsfakes.Gauge) float64 {
+					return g.SetArgsForCall(g.SetCallCount() - 1)
+				}
+
+				Expect(chain.PendingBatch()).To(Equal(etcdraft.PendingBatch{Age: "0s"}))
+
+				err := chain.Order(env, 0)
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(func() int { return chain.PendingBatch().Messages }, LongEventualTimeout).Should(Equal(1))
+				pendingBatch := chain.Info().PendingBatch
+				Expect(pendingBatch.Bytes).To(Equal(uint32(len(env.Payload) + len(env.Signature))))
+				Expect(pendingBatch.Age).NotTo(Equal("0s"))
+				Expect(pendingBatch.BlocksInFlight).To(Equal(0))
+				Expect(lastSet(fakeFields.fakePendingBatchMessages)).To(Equal(float64(1)))
+				Expect(lastSet(fakeFields.fakePendingBatchBytes)).To(Equal(float64(pendingBatch.Bytes)))
+				Expect(lastSet(fakeFields.fakePendingBatchStartTime)).NotTo(BeZero())
+
+				clock.WaitForNWatchersAndIncrement(timeout, 3)
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Eventually(chain.PendingBatch, LongEventualTimeout).Should(Equal(etcdraft.PendingBatch{Age: "0s"}))
+				Expect(lastSet(fakeFields.fakePendingBatchMessages)).To(BeZero())
+				Expect(lastSet(fakeFields.fakePendingBatchStartTime)).To(BeZero())
+				Expect(lastSet(fakeFields.fakeBlocksInFlight)).To(BeZero())
+			})
+
+			It("rejects transactions while paused", func() {
+				close(cutter.Block)
+
+				chain.Pause()
+				Expect(chain.Info().Paused).To(BeTrue())
+				Expect(fakeFields.fakeIsPaused.SetArgsForCall(fakeFields.fakeIsPaused.SetCallCount() - 1)).To(Equal(float64(1)))
+
+				Expect(chain.Order(env, 0)).To(MatchError(etcdraft.ErrChainPaused))
+				forwarded := &orderer.SubmitRequest{LastValidationSeq: 0, Payload: env, Channel: channelID}
+				Expect(chain.Submit(forwarded, 2)).To(MatchError(etcdraft.ErrChainPaused))
+				Consistently(cutter.CurBatch).Should(BeEmpty())
+				Expect(fakeFields.fakeEnvelopesRejected.AddCallCount()).To(Equal(2))
+				Expect(fakeFields.fakeEnvelopesRejected.WithArgsForCall(1)).To(Equal([]string{"reason", etcdraft.RejectReasonPaused}))
+
+				chain.Resume()
+				Expect(chain.Info().Paused).To(BeFalse())
+				Expect(fakeFields.fakeIsPaused.SetArgsForCall(fakeFields.fakeIsPaused.SetCallCount() - 1)).To(Equal(float64(0)))
+
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+			})
+
+			Context("when normal transactions are rejected on the system channel", func() {
+				BeforeEach(func() {
+					opts.RejectSystemChannelTxs = true
+					support.IsSystemChannelReturns(true)
+				})
+
+				It("orders config transactions only", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+
+					Expect(chain.Order(env, 0)).To(MatchError(etcdraft.ErrSystemChannelTransaction))
+					forwarded := &orderer.SubmitRequest{LastValidationSeq: 0, Payload: env, Channel: channelID}
+					Expect(chain.Submit(forwarded, 2)).To(Succeed())
+					Consistently(cutter.CurBatch).Should(BeEmpty())
+					Expect(support.WriteBlockCallCount()).To(BeZero())
+					Expect(fakeFields.fakeProposalFailures.AddCallCount()).To(Equal(2))
+					Expect(fakeFields.fakeEnvelopesRejected.WithArgsForCall(2)).To(Equal([]string{"reason", etcdraft.RejectReasonSystemChannel}))
+
+					configEnv := newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, map[string]*common.ConfigValue{
+						"BatchTimeout": {
+							Version: 1,
+							Value:   marshalOrPanic(&orderer.BatchTimeout{Timeout: "3ms"}),
+						},
+					}))
+					Expect(chain.Configure(configEnv, 0)).To(Succeed())
+					Eventually(support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+
+				Context("on an application channel", func() {
+					BeforeEach(func() {
+						support.IsSystemChannelReturns(false)
+					})
+
+					It("orders normal transactions", func() {
+						close(cutter.Block)
+						cutter.CutNext = true
+						Expect(chain.Order(env, 0)).To(Succeed())
+						Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					})
+				})
+			})
+
+			Context("when the size of envelopes is bounded", func() {
+				BeforeEach(func() {
+					opts.MaxEnvelopeBytes = uint32(proto.Size(env))
+				})
+
+				It("rejects oversized envelopes", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+
+					large := &common.Envelope{Payload: env.Payload, Signature: append([]byte{'x'}, env.Signature...)}
+					err := chain.Order(large, 0)
+					Expect(err).To(Equal(&etcdraft.EnvelopeTooLargeError{Size: proto.Size(large), Limit: opts.MaxEnvelopeBytes}))
+					Expect(err).To(MatchError(fmt.Sprintf("envelope of %d bytes exceeds the maximum of %d bytes", proto.Size(large), proto.Size(env))))
+
+					// envelopes forwarded by other nodes are checked by the leader as well
+					forwarded := &orderer.SubmitRequest{LastValidationSeq: 0, Payload: large, Channel: channelID}
+					Expect(chain.Submit(forwarded, 2)).To(Succeed())
+					Eventually(fakeFields.fakeEnvelopesRejected.AddCallCount, LongEventualTimeout).Should(Equal(2))
+					Consistently(cutter.CurBatch).Should(BeEmpty())
+					Expect(support.WriteBlockCallCount()).To(BeZero())
+					Expect(fakeFields.fakeEnvelopesRejected.WithArgsForCall(1)).To(Equal([]string{"reason", etcdraft.RejectReasonSize}))
+					Expect(fakeFields.fakeEnvelopesRejected.WithArgsForCall(2)).To(Equal([]string{"reason", etcdraft.RejectReasonSize}))
+
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+			})
+
+			Context("when DR consenters are configured", func() {
+				var (
+					sink       *mocks.FakeDRSink
+					ledgerLock sync.Mutex
+					ledger     map[uint64]*common.Block
+				)
+
+				BeforeEach(func() {
+					sink = &mocks.FakeDRSink{}
+					sink.HeightReturns(1, nil)
+					opts.DRSink = sink
+					opts.DRConsenters = []*raftprotos.Consenter{{Host: "dr1", Port: 7050}}
+
+					ledger = map[uint64]*common.Block{0: getSeedBlock()}
+					support.WriteBlockStub = func(block *common.Block, _ []byte) {
+						ledgerLock.Lock()
+						defer ledgerLock.Unlock()
+						ledger[block.Header.Number] = block
+					}
+					support.HeightStub = func() uint64 {
+						ledgerLock.Lock()
+						defer ledgerLock.Unlock()
+						return uint64(len(ledger))
+					}
+					support.BlockStub = func(number uint64) *common.Block {
+						ledgerLock.Lock()
+						defer ledgerLock.Unlock()
+						return ledger[number]
+					}
+				})
+
+				It("streams committed blocks to them while leading", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(sink.SendBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					target, channel, block := sink.SendBlockArgsForCall(0)
+					Expect(proto.Equal(target, opts.DRConsenters[0])).To(BeTrue())
+					Expect(channel).To(Equal(channelID))
+					Expect(block.Header.Number).To(Equal(uint64(1)))
+					Expect(sink.HeightCallCount()).To(Equal(1))
+					Eventually(fakeFields.fakeDRReplicationLag.SetCallCount, LongEventualTimeout).Should(BeNumerically(">", 0))
+					Expect(fakeFields.fakeDRReplicationLag.SetArgsForCall(fakeFields.fakeDRReplicationLag.SetCallCount() - 1)).To(Equal(float64(0)))
+				})
+			})
+
+			Context("when the receipt stream is enabled", func() {
+				BeforeEach(func() {
+					opts.ReceiptStream = true
+				})
+
+				It("streams the receipts of written blocks until halted", func() {
+					close(cutter.Block)
+
+					sub, err := chain.SubscribeReceipts(nil)
+					Expect(err).NotTo(HaveOccurred())
+					defer sub.Cancel()
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Eventually(sub.Receipts(), LongEventualTimeout).Should(Receive(Equal(&orderer.TxReceipt{BlockNumber: 1, Position: 0})))
+
+					chain.Halt()
+					Eventually(sub.Receipts(), LongEventualTimeout).Should(BeClosed())
+					Expect(sub.Err()).To(Equal(etcdraft.ErrReceiptsHalted))
+					_, err = chain.SubscribeReceipts(nil)
+					Expect(err).To(Equal(etcdraft.ErrReceiptsHalted))
+				})
+			})
+
+			It("refuses receipt subscriptions when the receipt stream is disabled", func() {
+				_, err := chain.SubscribeReceipts(nil)
+				Expect(err).To(Equal(etcdraft.ErrReceiptsDisabled))
+			})
+
+			Context("when a batch does not fit in a raft message", func() {
+				envelopeOfSize := func(size int) *common.Envelope {
+					return &common.Envelope{
+						Payload: marshalOrPanic(&common.Payload{
+							Header: &common.Header{ChannelHeader: marshalOrPanic(&common.ChannelHeader{Type: int32(common.HeaderType_MESSAGE), ChannelId: channelID})},
+							Data:   make([]byte, size),
+						}),
+					}
+				}
+
+				BeforeEach(func() {
+					opts.MaxSizePerMsg = 2048
+				})
+
+				It("splits the batch into blocks that fit", func() {
+					close(cutter.Block)
+
+					Expect(chain.Order(envelopeOfSize(1024), 0)).To(Succeed())
+					Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
+					cutter.CutNext = true
+					Expect(chain.Order(envelopeOfSize(1024), 0)).To(Succeed())
+
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+					for i := 0; i < 2; i++ {
+						block, _ := support.WriteBlockArgsForCall(i)
+						Expect(block.Header.Number).To(Equal(uint64(i + 1)))
+						Expect(block.Data.Data).To(HaveLen(1))
+					}
+				})
+
+				It("rejects envelopes which do not fit in a block of their own", func() {
+					close(cutter.Block)
+
+					err := chain.Order(envelopeOfSize(4096), 0)
+					Expect(err).To(BeAssignableToTypeOf(&etcdraft.MessageSizeExceededError{}))
+					Expect(err.(*etcdraft.MessageSizeExceededError).Limit).To(Equal(uint64(2048)))
+					Expect(fakeFields.fakeProposalFailures.AddCallCount()).To(Equal(1))
+					Expect(chain.Configure(envelopeOfSize(4096), 0)).To(BeAssignableToTypeOf(&etcdraft.MessageSizeExceededError{}))
+					Expect(cutter.CurBatch()).To(BeEmpty())
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					block, _ := support.WriteBlockArgsForCall(0)
+					Expect(block.Header.Number).To(Equal(uint64(1)))
+				})
+			})
+
+			Context("when a max block interval is set", func() {
+				BeforeEach(func() {
+					opts.MaxBlockInterval = time.Minute
+				})
+
+				It("cuts empty blocks while no transactions arrive", func() {
+					close(cutter.Block)
+
+					for i := 1; i <= 2; i++ {
+						clock.WaitForNWatchersAndIncrement(time.Minute, 3)
+						Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(i))
+						block, _ := support.WriteBlockArgsForCall(i - 1)
+						Expect(block.Header.Number).To(Equal(uint64(i)))
+						Expect(block.Data.Data).To(BeEmpty())
+					}
+				})
+
+				It("does not cut empty blocks while transactions arrive", func() {
+					close(cutter.Block)
+
+					clock.WaitForNWatchersAndIncrement(time.Minute/2, 3)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					clock.WaitForNWatchersAndIncrement(time.Minute/2, 3)
+					Consistently(support.WriteBlockCallCount).Should(Equal(1))
+
+					clock.Increment(time.Minute / 2)
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+					block, _ := support.WriteBlockArgsForCall(1)
+					Expect(block.Data.Data).To(BeEmpty())
+				})
+			})
+
+			Context("when a max commit backlog is set", func() {
+				BeforeEach(func() {
+					opts.MaxCommitBacklog = 1
+				})
+
+				It("rejects envelopes until the ledger catches up", func() {
+					close(cutter.Block)
+
+					release := make(chan struct{})
+					support.WriteBlockStub = func(*common.Block, []byte) {
+						<-release
+					}
+
+					Expect(chain.Admit()).To(Succeed())
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Expect(chain.Admit()).To(MatchError("chain is behind writing blocks to the ledger, 1 committed raft entries are not written yet"))
+
+					close(release)
+					Eventually(chain.Admit, LongEventualTimeout).Should(Succeed())
+					Expect(fakeFields.fakeCommitBacklog.SetArgsForCall(fakeFields.fakeCommitBacklog.SetCallCount() - 1)).To(Equal(float64(0)))
+				})
+			})
+
+			Context("when a block committed hook is set", func() {
+				var committed chan *common.Block
+
+				BeforeEach(func() {
+					committed = make(chan *common.Block, 1)
+					opts.OnBlockCommitted = func(channel string, block *common.Block) {
+						Expect(channel).To(Equal(channelID))
+						committed <- block
+					}
+				})
+
+				It("calls the hook with the header and data of committed blocks", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					written, _ := support.WriteBlockArgsForCall(0)
+
+					var block *common.Block
+					Eventually(committed, LongEventualTimeout).Should(Receive(&block))
+					Expect(block.Header).To(Equal(written.Header))
+					Expect(block.Data).To(Equal(written.Data))
+					Expect(block.Metadata).To(BeNil())
+				})
+			})
+
+			Context("when a watchdog timeout is set", func() {
+				BeforeEach(func() {
+					opts.WatchdogTimeout = time.Hour
+				})
+
+				It("reports the chain as wedged while it does not process events", func() {
+					close(cutter.Block)
+
+					release := make(chan struct{})
+					support.WriteBlockStub = func(*common.Block, []byte) {
+						<-release
+					}
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Expect(chain.Info().Wedged).To(BeFalse())
+
+					Eventually(func() bool {
+						clock.Increment(time.Hour)
+						return chain.Info().Wedged
+					}, LongEventualTimeout).Should(BeTrue())
+					Expect(fakeFields.fakeWedged.SetArgsForCall(fakeFields.fakeWedged.SetCallCount() - 1)).To(Equal(float64(1)))
+
+					close(release)
+					Eventually(func() bool { return chain.Info().Wedged }, LongEventualTimeout).Should(BeFalse())
+					Expect(fakeFields.fakeWedged.SetArgsForCall(fakeFields.fakeWedged.SetCallCount() - 1)).To(Equal(float64(0)))
+				})
+			})
+
+			Context("when an applied blocks quota is set", func() {
+				BeforeEach(func() {
+					opts.Quotas.AppliedBlocksPerSecond = 1
+				})
+
+				It("throttles writing blocks to the ledger", func() {
+					close(cutter.Block)
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(fakeFields.fakeQuotaThrottled.AddCallCount, LongEventualTimeout).Should(Equal(1))
+					Consistently(support.WriteBlockCallCount).Should(Equal(1))
+					Expect(fakeFields.fakeQuotaThrottled.WithArgsForCall(1)).To(Equal([]string{"resource", etcdraft.QuotaAppliedBlocks}))
+
+					clock.Increment(time.Second)
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+				})
+
+				It("halts while throttled", func() {
+					close(cutter.Block)
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(fakeFields.fakeQuotaThrottled.AddCallCount, LongEventualTimeout).Should(Equal(1))
+
+					halted := make(chan struct{})
+					go func() {
+						chain.Halt()
+						close(halted)
+					}()
+					Eventually(halted, LongEventualTimeout).Should(BeClosed())
+					Eventually(chain.Errored, LongEventualTimeout).Should(BeClosed())
+				})
+			})
+
+			Context("when a commit marker is maintained", func() {
+				BeforeEach(func() {
+					opts.CommitMarkerPath = path.Join(dataDir, etcdraft.CommitMarkerFile)
+				})
+
+				It("records the last block written along with its raft index", func() {
+					marker, err := etcdraft.ReadCommitMarker(opts.CommitMarkerPath)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(marker).To(Equal(&etcdraft.CommitMarker{BlockNumber: 0, RaftIndex: 0}))
+
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					_, metadata := support.WriteBlockArgsForCall(0)
+					blockMetadata := &raftprotos.BlockMetadata{}
+					Expect(proto.Unmarshal(metadata, blockMetadata)).To(Succeed())
+					Eventually(func() (*etcdraft.CommitMarker, error) {
+						return etcdraft.ReadCommitMarker(opts.CommitMarkerPath)
+					}, LongEventualTimeout).Should(Equal(&etcdraft.CommitMarker{BlockNumber: 1, RaftIndex: blockMetadata.RaftIndex}))
+				})
+			})
+
+			It("reports no membership drift and has none to repair", func() {
+				Eventually(chain.MembershipDrift, LongEventualTimeout).Should(Equal(etcdraft.MembershipDrift{
+					Nodes:      []uint64{1},
+					Consenters: []uint64{1},
+				}))
+				Expect(chain.RepairMembership()).To(MatchError("raft nodes match the consenters"))
+			})
+
+			It("applies markers without writing blocks", func() {
+				Expect(chain.ProposeMarker(raftprotos.Marker_PAUSE, "maintenance")).To(Succeed())
+				Eventually(chain.Paused, LongEventualTimeout).Should(BeTrue())
+				Expect(chain.Order(env, 0)).To(MatchError(etcdraft.ErrChainPaused))
+
+				Expect(chain.ProposeMarker(raftprotos.Marker_RESUME, "done")).To(Succeed())
+				Eventually(chain.Paused, LongEventualTimeout).Should(BeFalse())
+
+				Expect(chain.ProposeMarker(raftprotos.Marker_SNAPSHOT, "backup")).To(Succeed())
+				Eventually(func() uint64 {
+					snap, _ := opts.MemoryStorage.Snapshot()
+					return snap.Metadata.Index
+				}, LongEventualTimeout).ShouldNot(BeZero())
+				Expect(support.WriteBlockCallCount()).To(BeZero())
+			})
+
+			It("snapshots the last written block on request", func() {
+				close(cutter.Block)
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				point, doneC, err := chain.Snapshot()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(point.Channel).To(Equal(channelID))
+				Expect(point.BlockNumber).To(Equal(uint64(1)))
+				Expect(point.RaftIndex).NotTo(BeZero())
+				Eventually(doneC, LongEventualTimeout).Should(Receive(BeNil()))
+
+				snap, err := opts.MemoryStorage.Snapshot()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(snap.Metadata.Index).To(Equal(point.RaftIndex))
+			})
+
+			It("takes a snapshot on request and waits for it", func() {
+				close(cutter.Block)
+				cutter.CutNext = true
+				Expect(chain.Order(env, 0)).To(Succeed())
+				Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				point, err := chain.TakeSnapshot(LongEventualTimeout)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(point.BlockNumber).To(Equal(uint64(1)))
+
+				snap, err := opts.MemoryStorage.Snapshot()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(snap.Metadata.Index).To(Equal(point.RaftIndex))
+
+				chain.Halt()
+				_, err = chain.TakeSnapshot(LongEventualTimeout)
+				Expect(err).To(MatchError("chain is stopped"))
+			})
+
+			Context("when the snapshot is not taken in time", func() {
+				var release chan struct{}
+
+				BeforeEach(func() {
+					release = make(chan struct{})
+					injector := &mocks.FakeFaultInjector{}
+					injector.SnapshotStub = func(uint64, []byte) error {
+						<-release
+						return nil
+					}
+					opts.FaultInjector = injector
+				})
+
+				AfterEach(func() {
+					close(release)
+				})
+
+				It("stops waiting for it once the timeout elapses on the chain clock", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					errC := make(chan error, 1)
+					go func() {
+						_, err := chain.TakeSnapshot(time.Minute)
+						errC <- err
+					}()
+					Consistently(errC).ShouldNot(Receive())
+
+					Eventually(func() <-chan error {
+						clock.Increment(time.Minute)
+						return errC
+					}, LongEventualTimeout).Should(Receive(MatchError("snapshot at block 1 was not taken within 1m0s")))
+				})
+			})
+
+			Context("when snapshots only hold the header of their block", func() {
+				BeforeEach(func() {
+					opts.SnapshotContent = etcdraft.SnapshotContentHeader
+				})
+
+				It("snapshots the header of the last written block", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					written, _ := support.WriteBlockArgsForCall(0)
+
+					point, err := chain.TakeSnapshot(LongEventualTimeout)
+					Expect(err).NotTo(HaveOccurred())
+
+					snap, err := opts.MemoryStorage.Snapshot()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(snap.Metadata.Index).To(Equal(point.RaftIndex))
+					b, _, err := etcdraft.SnapshotBlock(snap.Data)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(proto.Equal(b.Header, written.Header)).To(BeTrue())
+					Expect(b.Data).To(BeNil())
+				})
+			})
+
+			Context("when proposal forwarding is enabled", func() {
+				BeforeEach(func() {
+					opts.ProposalForwarding = true
+				})
+
+				It("skips blocks which do not extend the chain", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					block1, _ := support.WriteBlockArgsForCall(0)
+
+					By("proposing a block forwarded from a previous leader")
+					staleBlock := common.NewBlock(2, []byte("stale"))
+					Expect(chain.Node.Propose(context.TODO(), utils.MarshalOrPanic(staleBlock))).To(Succeed())
+					Consistently(support.WriteBlockCallCount).Should(Equal(1))
+
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+					block2, _ := support.WriteBlockArgsForCall(1)
+					Expect(block2.Header.Number).To(Equal(uint64(2)))
+					Expect(block2.Header.PreviousHash).To(Equal(block1.Header.Hash()))
+				})
+			})
+
+			Context("when fair ordering is enabled", func() {
+				BeforeEach(func() {
+					opts.FairOrdering = true
+				})
+
+				It("orders the envelopes of its clients", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+				})
+			})
+
+			Context("when block provenance is enabled", func() {
+				BeforeEach(func() {
+					opts.BlockProvenance = true
+				})
+
+				It("records the term and ID of the leader which proposed a block", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					_, m := support.WriteBlockArgsForCall(0)
+					raftMetadata := &raftprotos.BlockMetadata{}
+					Expect(proto.Unmarshal(m, raftMetadata)).To(Succeed())
+					Expect(raftMetadata.Provenance).NotTo(BeNil())
+					Expect(raftMetadata.Provenance.Proposer).To(Equal(uint64(1)))
+					Expect(raftMetadata.Provenance.RaftTerm).To(Equal(chain.Node.Status().Term))
+				})
+			})
+
+			Context("when block timestamps are enabled", func() {
+				BeforeEach(func() {
+					opts.BlockTimestamp = true
+					support.NewSignatureHeaderReturns(&common.SignatureHeader{Creator: []byte("orderer1")}, nil)
+					support.SignReturns([]byte("signature"), nil)
+				})
+
+				It("records the time the leader created a block at, signed by the leader", func() {
+					close(cutter.Block)
+					cutter.CutNext = true
+					Expect(chain.Order(env, 0)).To(Succeed())
+					Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+					block, m := support.WriteBlockArgsForCall(0)
+					raftMetadata := &raftprotos.BlockMetadata{}
+					Expect(proto.Unmarshal(m, raftMetadata)).To(Succeed())
+					Expect(raftMetadata.Timestamp).NotTo(BeNil())
+					Expect(raftMetadata.Timestamp.Time).To(Equal(clock.Now().UnixNano()))
+					Expect(raftMetadata.Timestamp.Proposer).To(Equal(uint64(1)))
+					Expect(raftMetadata.Timestamp.Signature).To(Equal([]byte("signature")))
+
+					Expect(support.SignCallCount()).To(Equal(1))
+					signedData := etcdraft.BlockTimestampSignedData(block.Header, raftMetadata.Timestamp)
+					Expect(support.SignArgsForCall(0)).To(Equal(signedData.Data))
+					Expect(signedData.Identity).To(Equal([]byte("orderer1")))
+
+					// the leader does not check the timestamps it stamped itself
+					Expect(support.VerifyBlockSignatureCallCount()).To(BeZero())
+					Expect(fakeFields.fakeBlockTimeViolations.AddCallCount()).To(BeZero())
+				})
+			})
+
 			It("does not reset timer for every envelope", func() {
 				close(cutter.Block)
 
@@ -330,7 +1242,7 @@ var _ = Describe("Chain", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-				clock.WaitForNWatchersAndIncrement(timeout/2, 2)
+				clock.WaitForNWatchersAndIncrement(timeout/2, 3)
 
 				err = chain.Order(env, 0)
 				Expect(err).NotTo(HaveOccurred())
@@ -352,7 +1264,7 @@ var _ = Describe("Chain", func() {
 				Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
 				// wait for timer to start
-				Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(2))
+				Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(3))
 
 				chain.Halt()
 				Consistently(support.WriteBlockCallCount).Should(Equal(0))
@@ -368,7 +1280,7 @@ var _ = Describe("Chain", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-				clock.WaitForNWatchersAndIncrement(timeout/2, 2)
+				clock.WaitForNWatchersAndIncrement(timeout/2, 3)
 
 				By("force a batch to be cut before timer expires")
 				cutter.CutNext = true
@@ -386,7 +1298,7 @@ var _ = Describe("Chain", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-				clock.WaitForNWatchersAndIncrement(timeout/2, 2)
+				clock.WaitForNWatchersAndIncrement(timeout/2, 3)
 				Consistently(support.WriteBlockCallCount).Should(Equal(1))
 
 				clock.Increment(timeout / 2)
@@ -428,7 +1340,7 @@ var _ = Describe("Chain", func() {
 					err := chain.Order(env, 0)
 					Expect(err).NotTo(HaveOccurred())
 					Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
-					Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(2))
+					Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(3))
 				})
 
 				It("does not enqueue if envelope is not valid", func() {
@@ -437,7 +1349,7 @@ var _ = Describe("Chain", func() {
 					err := chain.Order(env, 0)
 					Expect(err).NotTo(HaveOccurred())
 					Consistently(cutter.CurBatch).Should(HaveLen(0))
-					Consistently(clock.WatcherCount).Should(Equal(1))
+					Consistently(clock.WatcherCount).Should(Equal(2))
 				})
 			})
 
@@ -494,6 +1406,43 @@ var _ = Describe("Chain", func() {
 							configSeq = 0
 						}) // BeforeEach block
 
+						Context("when a max of pending config updates is set", func() {
+							BeforeEach(func() {
+								opts.MaxPendingConfigs = 1
+							})
+
+							It("rejects config updates submitted while another one is pending", func() {
+								release := make(chan struct{})
+								support.WriteConfigBlockStub = func(*common.Block, []byte) {
+									<-release
+								}
+
+								Expect(chain.Configure(configEnv, configSeq)).To(Succeed())
+								Eventually(support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+								// the chain is busy writing the first config block, so the second one queues up
+								errC := make(chan error, 1)
+								go func() {
+									errC <- chain.Configure(configEnv, configSeq)
+								}()
+								pending := func() float64 {
+									return fakeFields.fakePendingConfigUpdates.SetArgsForCall(fakeFields.fakePendingConfigUpdates.SetCallCount() - 1)
+								}
+								Eventually(pending, LongEventualTimeout).Should(Equal(float64(1)))
+
+								err := chain.Configure(configEnv, configSeq)
+								Expect(err).To(Equal(&etcdraft.ConfigPendingError{Pending: 1, Limit: 1}))
+								Expect(fakeFields.fakeEnvelopesRejected.WithArgsForCall(1)).To(Equal([]string{"reason", etcdraft.RejectReasonConfigPending}))
+
+								close(release)
+								Eventually(errC, LongEventualTimeout).Should(Receive(BeNil()))
+								Eventually(support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(2))
+								Eventually(pending, LongEventualTimeout).Should(Equal(float64(0)))
+								Expect(chain.Configure(configEnv, configSeq)).To(Succeed())
+								Eventually(support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(3))
+							})
+						})
+
 						Context("without revalidation (i.e. correct config sequence)", func() {
 
 							Context("without pending normal envelope", func() {
@@ -507,6 +1456,17 @@ var _ = Describe("Chain", func() {
 									Expect(fakeFields.fakeCommittedBlockNumber.SetCallCount()).Should(Equal(1))
 									Expect(fakeFields.fakeCommittedBlockNumber.SetArgsForCall(0)).Should(Equal(float64(1)))
 								})
+
+								It("should time the application of the config block", func() {
+									err := chain.Configure(configEnv, configSeq)
+									Expect(err).NotTo(HaveOccurred())
+									Eventually(fakeFields.fakeConfigApplyDuration.ObserveCallCount, LongEventualTimeout).Should(Equal(3))
+									var phases []string
+									for i := 1; i < fakeFields.fakeConfigApplyDuration.WithCallCount(); i++ {
+										phases = append(phases, fakeFields.fakeConfigApplyDuration.WithArgsForCall(i)[1])
+									}
+									Expect(phases).To(Equal([]string{etcdraft.ConfigPhaseDetectConfChange, etcdraft.ConfigPhaseWriteBlock, etcdraft.ConfigPhaseTotal}))
+								})
 							})
 
 							Context("with pending normal envelope", func() {
@@ -521,7 +1481,7 @@ var _ = Describe("Chain", func() {
 									Expect(fakeFields.fakeNormalProposalsReceived.AddArgsForCall(0)).To(Equal(float64(1)))
 									Eventually(cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-									// // clock.WaitForNWatchersAndIncrement(timeout, 2)
+									// // clock.WaitForNWatchersAndIncrement(timeout, 3)
 
 									By("adding a config envelope")
 									err = chain.Configure(configEnv, configSeq)
@@ -548,7 +1508,7 @@ var _ = Describe("Chain", func() {
 								support.ProcessConfigMsgReturns(configEnv, 1, nil) // nil implies correct revalidation
 
 								Expect(chain.Configure(configEnv, configSeq)).To(Succeed())
-								Consistently(clock.WatcherCount).Should(Equal(1))
+								Consistently(clock.WatcherCount).Should(Equal(2))
 								Eventually(support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(1))
 							})
 
@@ -556,7 +1516,7 @@ var _ = Describe("Chain", func() {
 								support.ProcessConfigMsgReturns(configEnv, 1, errors.Errorf("Invalid config envelope at changed config sequence"))
 
 								Expect(chain.Configure(configEnv, configSeq)).To(Succeed())
-								Consistently(clock.WatcherCount).Should(Equal(1))
+								Consistently(clock.WatcherCount).Should(Equal(2))
 								Consistently(support.WriteConfigBlockCallCount).Should(Equal(0)) // no call to WriteConfigBlock
 							})
 
@@ -565,13 +1525,13 @@ var _ = Describe("Chain", func() {
 								support.ProcessConfigMsgReturns(configEnv, 1, errors.Errorf("Invalid config envelope at changed config sequence"))
 
 								Expect(chain.Order(env, configSeq)).To(Succeed())
-								Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(2))
+								Eventually(clock.WatcherCount, LongEventualTimeout).Should(Equal(3))
 
 								clock.Increment(30 * time.Minute)
 								Consistently(support.WriteBlockCallCount).Should(Equal(0))
 
 								Expect(chain.Configure(configEnv, configSeq)).To(Succeed())
-								Consistently(clock.WatcherCount).Should(Equal(2))
+								Consistently(clock.WatcherCount).Should(Equal(3))
 
 								Consistently(support.WriteBlockCallCount).Should(Equal(0))
 								Consistently(support.WriteConfigBlockCallCount).Should(Equal(0))
@@ -579,6 +1539,17 @@ var _ = Describe("Chain", func() {
 								clock.Increment(30 * time.Minute)
 								Eventually(support.WriteBlockCallCount).Should(Equal(1))
 							})
+
+							It("should not revalidate retried normal envelopes against the same config sequence", func() {
+								support.ProcessNormalMsgReturns(1, errors.Errorf("Invalid envelope at changed config sequence"))
+
+								Expect(chain.Order(env, configSeq)).To(Succeed())
+								Expect(chain.Order(env, configSeq)).To(Succeed())
+								Eventually(fakeFields.fakeValidationCacheHits.AddCallCount, LongEventualTimeout).Should(Equal(1))
+								Expect(support.ProcessNormalMsgCallCount()).To(Equal(1))
+								Expect(fakeFields.fakeValidationCacheMisses.AddCallCount()).To(Equal(1))
+								Expect(fakeFields.fakeProposalFailures.AddCallCount()).To(Equal(2))
+							})
 						})
 					})
 
@@ -706,6 +1677,50 @@ var _ = Describe("Chain", func() {
 							Expect(err).NotTo(HaveOccurred())
 						})
 					})
+
+					Context("changing proposal forwarding", func() {
+						It("should fail, since all nodes must agree on it", func() {
+							metadata := proto.Clone(consenterMetadata).(*raftprotos.ConfigMetadata)
+							metadata.Options = &raftprotos.Options{ProposalForwarding: true}
+							values := map[string]*common.ConfigValue{
+								"ConsensusType": {
+									Version: 1,
+									Value: marshalOrPanic(&orderer.ConsensusType{
+										Metadata: marshalOrPanic(metadata),
+									}),
+								},
+							}
+							configEnv = newConfigEnv(channelID,
+								common.HeaderType_CONFIG,
+								newConfigUpdateEnv(channelID, values))
+							configSeq = 0
+
+							err := chain.Configure(configEnv, configSeq)
+							Expect(err).To(MatchError("proposal forwarding cannot be changed from false to true, all nodes must agree on it"))
+						})
+					})
+
+					Context("disabling both pre-vote and check quorum", func() {
+						It("should fail, since a rejoining node would disrupt the leader", func() {
+							metadata := proto.Clone(consenterMetadata).(*raftprotos.ConfigMetadata)
+							metadata.Options = &raftprotos.Options{DisablePreVote: true, DisableCheckQuorum: true}
+							values := map[string]*common.ConfigValue{
+								"ConsensusType": {
+									Version: 1,
+									Value: marshalOrPanic(&orderer.ConsensusType{
+										Metadata: marshalOrPanic(metadata),
+									}),
+								},
+							}
+							configEnv = newConfigEnv(channelID,
+								common.HeaderType_CONFIG,
+								newConfigUpdateEnv(channelID, values))
+							configSeq = 0
+
+							err := chain.Configure(configEnv, configSeq)
+							Expect(err).To(MatchError("pre-vote and check quorum cannot be both disabled"))
+						})
+					})
 				})
 			})
 
@@ -811,28 +1826,120 @@ var _ = Describe("Chain", func() {
 
 						c.cutter.CutNext = true
 
-						err := c.Order(env, uint64(0))
-						Expect(err).NotTo(HaveOccurred())
-						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(3))
-					})
+						err := c.Order(env, uint64(0))
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(3))
+					})
+
+					It("does not replay any block if already in sync", func() {
+						raftMetadata.RaftIndex = m2.RaftIndex
+						c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+						c.support.WriteBlock(support.WriteBlockArgsForCall(0))
+						c.support.WriteBlock(support.WriteBlockArgsForCall(1))
+
+						c.init()
+						c.Start()
+						defer c.Halt()
+
+						Consistently(c.support.WriteBlockCallCount).Should(Equal(2))
+
+						// chain should keep functioning
+						campaign(c.Chain, c.observe)
+
+						c.cutter.CutNext = true
+
+						err := c.Order(env, uint64(0))
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(3))
+					})
+
+					It("writes the committed blocks at a time while it starts", func() {
+						raftMetadata.RaftIndex = m1.RaftIndex
+						c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+						c.support.WriteBlock(support.WriteBlockArgsForCall(0))
+						c.opts.MaxReplayEntries = 1
+						c.init()
+						defer c.Halt()
+
+						progress := c.ReplayProgress()
+						Expect(progress.Done).To(BeFalse())
+						Expect(progress.Applied).To(Equal(m1.RaftIndex))
+						Expect(progress.Target).To(BeNumerically(">=", m2.RaftIndex))
+
+						c.Start()
+
+						// the block is written before Start returns
+						Expect(c.support.WriteBlockCallCount()).To(Equal(2))
+						_, metadata := c.support.WriteBlockArgsForCall(1)
+						m := &raftprotos.BlockMetadata{}
+						proto.Unmarshal(metadata, m)
+						Expect(m.RaftIndex).To(Equal(m2.RaftIndex))
+						Expect(c.ReplayProgress()).To(Equal(etcdraft.ReplayProgress{Done: true, Applied: progress.Target, Target: progress.Target}))
+						Expect(c.Info().Replay.Done).To(BeTrue())
+
+						// the raft node does not write the block again
+						Consistently(c.support.WriteBlockCallCount).Should(Equal(2))
+
+						campaign(c.Chain, c.observe)
+
+						c.cutter.CutNext = true
+
+						err := c.Order(env, uint64(0))
+						Expect(err).NotTo(HaveOccurred())
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(3))
+					})
+
+					It("refuses to start when the ledger and the WAL diverged", func() {
+						raftMetadata.RaftIndex = m2.RaftIndex
+						c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+
+						_, err := etcdraft.NewChain(c.support, c.opts, c.configurator, c.rpc, nil, c.observe)
+						Expect(err).To(MatchError(ContainSubstring("ledger and WAL diverged: block 0 of the ledger was written from raft index %d, which holds block 2 in the WAL", m2.RaftIndex)))
+					})
+
+					Context("when blocks are staged out of the WAL", func() {
+						BeforeEach(func() {
+							opts.StagingDir = path.Join(dataDir, "staging")
+						})
+
+						It("replays blocks restored from the ledger and the staging directory", func() {
+							// block 1 is in the ledger, hence only block 2 is still staged
+							files, err := ioutil.ReadDir(opts.StagingDir)
+							Expect(err).NotTo(HaveOccurred())
+							Expect(files).To(HaveLen(1))
+
+							raftMetadata.RaftIndex = m1.RaftIndex
+							c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+							c.opts.StagingDir = opts.StagingDir
+							c.support.WriteBlock(support.WriteBlockArgsForCall(0))
+
+							c.init()
+							c.Start()
+							defer c.Halt()
+
+							Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+							block, _ := c.support.WriteBlockArgsForCall(1)
+							expected, _ := support.WriteBlockArgsForCall(1)
+							Expect(block.Header).To(Equal(expected.Header))
 
-					It("does not replay any block if already in sync", func() {
-						raftMetadata.RaftIndex = m2.RaftIndex
-						c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
-						c.init()
-						c.Start()
-						defer c.Halt()
+							// chain should keep functioning
+							campaign(c.Chain, c.observe)
 
-						Consistently(c.support.WriteBlockCallCount).Should(Equal(0))
+							c.cutter.CutNext = true
 
-						// chain should keep functioning
-						campaign(c.Chain, c.observe)
+							err = c.Order(env, uint64(0))
+							Expect(err).NotTo(HaveOccurred())
+							Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(3))
+						})
 
-						c.cutter.CutNext = true
+						It("refuses to start without the staged blocks which are not in the ledger", func() {
+							raftMetadata.RaftIndex = m1.RaftIndex
+							c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+							c.support.WriteBlock(support.WriteBlockArgsForCall(0))
 
-						err := c.Order(env, uint64(0))
-						Expect(err).NotTo(HaveOccurred())
-						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+							_, err := etcdraft.NewChain(c.support, c.opts, c.configurator, c.rpc, nil, c.observe)
+							Expect(err).To(MatchError(ContainSubstring("failed to restore block 2 of raft entry %d: block is not in the ledger, and the staging directory is not set", m2.RaftIndex)))
+						})
 					})
 
 					Context("WAL file is not readable", func() {
@@ -928,6 +2035,47 @@ var _ = Describe("Chain", func() {
 							Expect(fakeFields.fakeSnapshotBlockNumber.SetArgsForCall(1)).To(Equal(float64(b.Header.Number)))
 						})
 
+						Context("when taking a snapshot is slow", func() {
+							var (
+								injector *mocks.FakeFaultInjector
+								release  chan struct{}
+							)
+
+							BeforeEach(func() {
+								release = make(chan struct{})
+								injector = &mocks.FakeFaultInjector{}
+								injector.SnapshotStub = func(uint64, []byte) error {
+									<-release
+									return nil
+								}
+								opts.FaultInjector = injector
+							})
+
+							It("keeps writing blocks while the snapshot is taken", func() {
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+								Eventually(injector.SnapshotCallCount, LongEventualTimeout).Should(Equal(1))
+
+								By("ordering blocks while the snapshot of the first one is stalled")
+								for i := 2; i <= 4; i++ {
+									Expect(chain.Order(env, uint64(0))).To(Succeed())
+									Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(i))
+								}
+								Expect(countFiles()).To(BeZero())
+								Expect(injector.SnapshotCallCount()).To(Equal(1))
+
+								close(release)
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(1))
+								s, _ := opts.MemoryStorage.Snapshot()
+								Expect(utils.UnmarshalBlockOrPanic(s.Data).Header.Number).To(Equal(uint64(1)))
+
+								By("taking the next snapshot once the previous one is taken")
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(5))
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(2))
+							})
+						})
+
 						It("pauses chain if sync is in progress", func() {
 							// Scenario:
 							// after a snapshot is taken, reboot chain with raftIndex = 0
@@ -986,6 +2134,82 @@ var _ = Describe("Chain", func() {
 							Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
 						})
 
+						Context("when snapshots reference an archive", func() {
+							ref := &raftprotos.ArchiveReference{Uri: "s3://archive/foo", Hash: []byte{1, 2, 3}}
+							var capabilities *mockconfig.OrdererCapabilities
+
+							BeforeEach(func() {
+								capabilities = &mockconfig.OrdererCapabilities{SnapshotArchivesVal: true}
+								support.SharedConfigReturns(&mockconfig.Orderer{
+									BatchTimeoutVal:      time.Hour,
+									ConsensusMetadataVal: marshalOrPanic(consenterMetadata),
+									CapabilitiesVal:      capabilities,
+								})
+								opts.ArchiveReference = func(uint64) *raftprotos.ArchiveReference {
+									return ref
+								}
+							})
+
+							snapshotArchive := func() *raftprotos.ArchiveReference {
+								s, err := opts.MemoryStorage.Snapshot()
+								Expect(err).NotTo(HaveOccurred())
+								_, archive, err := etcdraft.SnapshotBlock(s.Data)
+								Expect(err).NotTo(HaveOccurred())
+								return archive
+							}
+
+							It("references the archive in snapshots", func() {
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(1))
+								Expect(proto.Equal(snapshotArchive(), ref)).To(BeTrue())
+							})
+
+							It("does not reference the archive in snapshots without the capability", func() {
+								capabilities.SnapshotArchivesVal = false
+
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(1))
+								Expect(snapshotArchive()).To(BeNil())
+							})
+
+							It("catches up from the archive if blocks cannot be pulled from cluster", func() {
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(1))
+
+								Expect(chain.Order(env, uint64(0))).To(Succeed())
+								Eventually(support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+								Eventually(countFiles, LongEventualTimeout).Should(Equal(2))
+
+								chain.Halt()
+
+								archivePuller := &mocks.FakeBlockPuller{}
+								archivePuller.PullBlockStub = func(i uint64) *common.Block {
+									ledgerLock.Lock()
+									defer ledgerLock.Unlock()
+									return ledger[i]
+								}
+								fetcher := &mocks.FakeArchiveFetcher{}
+								fetcher.FetchReturns(archivePuller, ref.Hash, nil)
+
+								c := newChain(10*time.Second, channelID, dataDir, 1, raftMetadata)
+								c.opts.ArchiveFetcher = fetcher
+								c.init()
+								c.puller.PullBlockReturns(nil)
+
+								c.Start()
+								defer c.Halt()
+
+								Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+								Expect(fetcher.FetchCallCount()).To(Equal(1))
+								Expect(proto.Equal(fetcher.FetchArgsForCall(0), ref)).To(BeTrue())
+								Expect(c.puller.PullBlockCallCount()).To(Equal(1))
+								Eventually(archivePuller.CloseCallCount, LongEventualTimeout).Should(Equal(1))
+							})
+						})
+
 						It("restores snapshot w/o extra entries", func() {
 							// Scenario:
 							// after a snapshot is taken, no more entries are appended.
@@ -1344,6 +2568,175 @@ var _ = Describe("Chain", func() {
 			os.RemoveAll(dataDir)
 		})
 
+		When("consenters support different wire features", func() {
+			It("negotiates the features every consenter supports", func() {
+				c1.opts.Features = map[string]uint32{etcdraft.FeatureCompression: 2, etcdraft.FeatureChunkedSnapshots: 1}
+				c2.opts.Features = map[string]uint32{etcdraft.FeatureCompression: 1, etcdraft.FeatureChunkedSnapshots: 1}
+				c3.opts.Features = map[string]uint32{etcdraft.FeatureCompression: 3, etcdraft.FeatureConfChangeV2: 1}
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				expected := map[string]uint32{etcdraft.FeatureCompression: 1}
+				network.exec(func(c *chain) {
+					Eventually(func() map[string]uint32 { return c.Info().Features }, LongEventualTimeout).Should(Equal(expected))
+				})
+				Expect(c1.FeatureVersion(etcdraft.FeatureCompression)).To(Equal(uint32(1)))
+				Expect(c1.FeatureVersion(etcdraft.FeatureChunkedSnapshots)).To(BeZero())
+
+				By("ordering blocks as usual")
+				c1.cutter.CutNext = true
+				Expect(c1.Order(env, 0)).To(Succeed())
+				network.exec(func(c *chain) {
+					Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+
+				network.stop()
+			})
+
+			It("forwards transactions to the leader in batches once every consenter supports them", func() {
+				network.exec(func(c *chain) { c.opts.Features = etcdraft.SupportedFeatures() })
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				Eventually(func() uint32 { return c2.FeatureVersion(etcdraft.FeatureSubmitBatches) }, LongEventualTimeout).Should(Equal(uint32(1)))
+
+				c1.cutter.CutNext = true
+				Expect(c2.Order(env, 0)).To(Succeed())
+				network.exec(func(c *chain) {
+					Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+				Expect(c2.rpc.SendSubmitBatchCallCount()).To(Equal(1))
+				Expect(c2.rpc.SendSubmitCallCount()).To(BeZero())
+
+				_, batch := c2.rpc.SendSubmitBatchArgsForCall(0)
+				Expect(batch.Requests).To(HaveLen(1))
+				Expect(batch.Requests[0].Payload).To(Equal(env))
+
+				network.stop()
+			})
+
+			It("grants the restart slot to one consenter at a time once every consenter supports it", func() {
+				network.exec(func(c *chain) { c.opts.Features = etcdraft.SupportedFeatures() })
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				Eventually(func() uint32 { return c1.FeatureVersion(etcdraft.FeatureRestartSlots) }, LongEventualTimeout).Should(Equal(uint32(1)))
+
+				holder := func(c *chain) func() uint64 {
+					return func() uint64 {
+						if slot := c.RestartSlot(); slot != nil {
+							return slot.Node
+						}
+						return 0
+					}
+				}
+
+				Expect(c2.AcquireRestartSlot(3, "upgrade", LongEventualTimeout)).To(MatchError("node 2 is not the leader, the leader is node 1"))
+				Expect(c1.AcquireRestartSlot(4, "upgrade", LongEventualTimeout)).To(MatchError("node 4 is not a consenter of the channel"))
+
+				Expect(c1.AcquireRestartSlot(3, "upgrade", LongEventualTimeout)).To(Succeed())
+				network.exec(func(c *chain) {
+					Eventually(holder(c), LongEventualTimeout).Should(Equal(uint64(3)))
+				})
+				Expect(c2.RestartSlot().Reason).To(Equal("upgrade"))
+				Expect(c2.Info().RestartSlot.Node).To(Equal(uint64(3)))
+
+				By("granting the slot to a single consenter at a time")
+				Expect(c1.AcquireRestartSlot(3, "upgrade", LongEventualTimeout)).To(Succeed())
+				Expect(c1.AcquireRestartSlot(2, "upgrade", LongEventualTimeout)).To(MatchError("restart slot is held by node 3"))
+				Expect(c1.ReleaseRestartSlot(2, "")).To(MatchError("restart slot is held by node 3"))
+
+				Expect(c1.ReleaseRestartSlot(3, "restarted")).To(Succeed())
+				network.exec(func(c *chain) {
+					Eventually(holder(c), LongEventualTimeout).Should(BeZero())
+				})
+				Expect(c1.AcquireRestartSlot(2, "upgrade", LongEventualTimeout)).To(Succeed())
+				Eventually(holder(c3), LongEventualTimeout).Should(Equal(uint64(2)))
+
+				Expect(c1.ProposeMarker(raftprotos.Marker_RESTART_SLOT_RELEASE, "")).To(MatchError("RESTART_SLOT_RELEASE markers are proposed through the restart slot"))
+
+				network.stop()
+			})
+
+			It("hands over the leadership before halting", func() {
+				network.init()
+				network.start()
+				network.elect(1)
+
+				Expect(c1.HasDuties()).To(BeTrue())
+				Expect(c2.HasDuties()).To(BeFalse())
+
+				ctx, cancel := context.WithTimeout(context.Background(), LongEventualTimeout)
+				defer cancel()
+				c2.HandOver(ctx)
+				Expect(c1.HasDuties()).To(BeTrue())
+
+				c1.HandOver(ctx)
+				Expect(ctx.Err()).NotTo(HaveOccurred())
+				Expect(c1.HasDuties()).To(BeFalse())
+				Eventually(func() bool { return c2.HasDuties() || c3.HasDuties() }, LongEventualTimeout).Should(BeTrue())
+
+				network.stop()
+			})
+
+			It("drops malformed raft messages of the other consenters", func() {
+				network.init()
+				network.start()
+				network.elect(1)
+
+				consensus := func(msg *raftpb.Message, sender uint64) error {
+					return c2.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: utils.MarshalOrPanic(msg)}, sender)
+				}
+				Expect(consensus(&raftpb.Message{Type: raftpb.MsgHeartbeat, From: 3, To: 2, Term: 2}, 1)).To(MatchError("raft message is from node 3, but was sent by node 1"))
+				Expect(consensus(&raftpb.Message{Type: raftpb.MsgHup, From: 1, To: 2}, 1)).To(MatchError("raft message of type MsgHup is local to the raft node"))
+				err := consensus(&raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 2, Commit: math.MaxUint64}, 1)
+				Expect(err).To(BeAssignableToTypeOf(&etcdraft.MalformedMessageError{}))
+				Expect(err.(*etcdraft.MalformedMessageError).Reason).To(Equal(etcdraft.DropReasonIndex))
+
+				By("keeping on ordering transactions")
+				c1.cutter.CutNext = true
+				Expect(c1.Order(env, 0)).To(Succeed())
+				network.exec(func(c *chain) {
+					Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+
+				network.stop()
+			})
+
+			It("rejects consensus requests of senders which attest the raft ID of another node", func() {
+				network.init()
+				network.start()
+				network.elect(1)
+
+				attestation := func(id uint64) []byte {
+					return utils.MarshalOrPanic(&raftprotos.ConsensusRequestMetadata{RaftId: id})
+				}
+				Expect(c1.rpc.SendConsensusCallCount()).NotTo(BeZero())
+				_, req := c1.rpc.SendConsensusArgsForCall(0)
+				Expect(req.Metadata).To(Equal(attestation(1)))
+
+				heartbeatResp := utils.MarshalOrPanic(&raftpb.Message{To: 1, From: 3, Type: raftpb.MsgHeartbeatResp})
+				err := c1.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: heartbeatResp, Metadata: attestation(3)}, 2)
+				Expect(err).To(MatchError("sender attests raft ID 3, but its TLS certificate is that of node 2"))
+				err = c1.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: heartbeatResp, Metadata: attestation(2)}, 2)
+				Expect(err).To(MatchError("raft message is from node 3, but the sender attests raft ID 2"))
+				err = c1.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: heartbeatResp, Metadata: []byte{0xff}}, 3)
+				Expect(err.Error()).To(HavePrefix("failed to unmarshal ConsensusRequest metadata"))
+
+				Expect(c1.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: heartbeatResp, Metadata: attestation(3)}, 3)).To(Succeed())
+				By("accepting the requests of nodes which do not attest their raft ID")
+				Expect(c1.Consensus(&orderer.ConsensusRequest{Channel: channelID, Payload: heartbeatResp}, 3)).To(Succeed())
+
+				network.stop()
+			})
+		})
+
 		When("2/3 nodes are running", func() {
 			It("late node can catch up", func() {
 				network.init()
@@ -1413,11 +2806,205 @@ var _ = Describe("Chain", func() {
 				Eventually(func() int { return c2.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(2))
 				Eventually(func() int { return c3.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(0))
 
-				network.start(3)
+				network.start(3)
+
+				c1.clock.Increment(interval)
+				Eventually(func() int { return c3.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(2))
+
+				network.stop()
+			})
+		})
+
+		When("a fault injector is set", func() {
+			var injector *mocks.FakeFaultInjector
+
+			BeforeEach(func() {
+				injector = &mocks.FakeFaultInjector{}
+				c1.opts.FaultInjector = injector
+			})
+
+			It("drops messages the injector fails", func() {
+				var dropLock sync.Mutex
+				drop := false
+				injector.SendStub = func(msg *raftpb.Message) error {
+					dropLock.Lock()
+					defer dropLock.Unlock()
+					if drop && msg.To == 3 && msg.Type == raftpb.MsgApp {
+						return errors.New("injected fault")
+					}
+					return nil
+				}
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				dropLock.Lock()
+				drop = true
+				dropLock.Unlock()
+
+				c1.cutter.CutNext = true
+				err := c1.Order(env, 0)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(c1.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Eventually(c2.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Consistently(c3.support.WriteBlockCallCount).Should(Equal(0))
+
+				Expect(injector.PersistCallCount()).NotTo(BeZero())
+				Expect(injector.ApplyCallCount()).NotTo(BeZero())
+
+				dropLock.Lock()
+				drop = false
+				dropLock.Unlock()
+
+				Eventually(func() int {
+					c1.clock.Increment(interval)
+					return c3.support.WriteBlockCallCount()
+				}, LongEventualTimeout).Should(Equal(1))
+
+				network.stop()
+			})
+
+			It("skips snapshots the injector fails", func() {
+				c1.opts.SnapInterval = 1
+				injector.SnapshotReturns(errors.New("injected fault"))
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				c1.cutter.CutNext = true
+				err := c1.Order(env, 0)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(c1.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Eventually(injector.SnapshotCallCount, LongEventualTimeout).Should(Equal(1))
+
+				snap, err := c1.opts.MemoryStorage.Snapshot()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(raft.IsEmptySnap(snap)).To(BeTrue())
+
+				network.stop()
+			})
+		})
+
+		When("a notifier is set", func() {
+			It("notifies leader changes", func() {
+				notifier := &mocks.FakeNotifier{}
+				c2.opts.Notifier = notifier
+
+				network.init()
+				network.start()
+				network.elect(1)
+				network.elect(2)
+
+				// node 2 may observe the loss of leader 1 before it is elected
+				Eventually(func() uint64 {
+					return notifier.NotifyArgsForCall(notifier.NotifyCallCount() - 1).Leader
+				}, LongEventualTimeout).Should(Equal(uint64(2)))
+
+				first := notifier.NotifyArgsForCall(0)
+				Expect(first.Channel).To(Equal(channelID))
+				Expect(first.NodeID).To(Equal(uint64(2)))
+				Expect(first.PreviousLeader).To(Equal(uint64(0)))
+				Expect(first.Leader).To(Equal(uint64(1)))
+				for i := 0; i < notifier.NotifyCallCount(); i++ {
+					Expect(notifier.NotifyArgsForCall(i).Type).To(Equal(etcdraft.EventLeaderChange))
+				}
+
+				network.stop()
+			})
+
+			It("notifies peers becoming unreachable and reachable", func() {
+				notifier := &mocks.FakeNotifier{}
+				c1.opts.Notifier = notifier
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				events := func(eventType etcdraft.EventType) []etcdraft.Event {
+					var events []etcdraft.Event
+					for i := 0; i < notifier.NotifyCallCount(); i++ {
+						if event := notifier.NotifyArgsForCall(i); event.Type == eventType {
+							events = append(events, event)
+						}
+					}
+					return events
+				}
+
+				network.disconnect(2)
+				c1.cutter.CutNext = true
+				Expect(c1.Order(env, 0)).To(Succeed())
+				Eventually(func() []etcdraft.Event {
+					return events(etcdraft.EventPeerUnreachable)
+				}, LongEventualTimeout).Should(HaveLen(1))
+				unreachable := events(etcdraft.EventPeerUnreachable)[0]
+				Expect(unreachable.Peer).To(Equal(uint64(2)))
+				Expect(unreachable.Cause).To(Equal(etcdraft.UnreachableRPCError))
+
+				network.connect(2)
+				Eventually(func() []etcdraft.Event {
+					c1.clock.Increment(interval)
+					return events(etcdraft.EventPeerReachable)
+				}, LongEventualTimeout).Should(HaveLen(1))
+				Expect(events(etcdraft.EventPeerReachable)[0].Peer).To(Equal(uint64(2)))
+				Expect(events(etcdraft.EventPeerUnreachable)).To(HaveLen(1))
+
+				network.stop()
+			})
+		})
+
+		When("ConfChanges are retried", func() {
+			It("proposes a ConfChange dropped by raft again", func() {
+				network.exec(func(c *chain) {
+					c.opts.ConfChangeRetryInterval = ELECTION_TICK * interval
+				})
+
+				network.init()
+				network.start()
+				network.elect(1)
+
+				metadata := &raftprotos.ConfigMetadata{}
+				for _, consenter := range raftMetadata.Consenters {
+					metadata.Consenters = append(metadata.Consenters, consenter)
+				}
+				metadata.Consenters = append(metadata.Consenters, &raftprotos.Consenter{
+					Host:          "localhost",
+					Port:          7050,
+					ServerTlsCert: serverTLSCert(tlsCA),
+					ClientTlsCert: clientTLSCert(tlsCA),
+				})
+				configEnv := newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, map[string]*common.ConfigValue{
+					"ConsensusType": {
+						Version: 1,
+						Value:   marshalOrPanic(&orderer.ConsensusType{Metadata: marshalOrPanic(metadata)}),
+					},
+				}))
+
+				By("transferring the leadership to a disconnected node while the ConfChange is proposed")
+				stub := c1.support.WriteConfigBlockStub
+				c1.support.WriteConfigBlockStub = func(b *common.Block, meta []byte) {
+					network.disconnect(3)
+					c1.Node.TransferLeadership(context.TODO(), 1, 3)
+					stub(b, meta)
+				}
+
+				c1.cutter.CutNext = true
+				Expect(c1.Configure(configEnv, 0)).To(Succeed())
+				Eventually(c1.support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(1))
 
-				c1.clock.Increment(interval)
-				Eventually(func() int { return c3.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(2))
+				By("proposing it again until the transfer is aborted and it is applied")
+				Eventually(func() float64 {
+					c1.clock.Increment(interval)
+					return c2.fakeFields.fakeClusterSize.SetArgsForCall(c2.fakeFields.fakeClusterSize.SetCallCount() - 1)
+				}, LongEventualTimeout).Should(Equal(float64(4)))
+				Expect(c1.fakeFields.fakeConfChangeReproposals.AddCallCount()).NotTo(BeZero())
+				Expect(c1.Node.Status().Lead).To(Equal(uint64(1)))
+				Expect(c1.fakeFields.fakeConfChangeRetriesExhausted.SetCallCount()).To(BeZero())
 
+				network.connect(3)
 				network.stop()
 			})
 		})
@@ -1474,6 +3061,7 @@ var _ = Describe("Chain", func() {
 				network.exec(func(c *chain) {
 					c.opts.EvictionSuspicion = time.Millisecond * 100
 					c.opts.LeaderCheckInterval = time.Millisecond * 100
+					c.opts.ConfChangeTimeout = time.Minute
 				})
 
 				network.init()
@@ -1527,6 +3115,77 @@ var _ = Describe("Chain", func() {
 					Expect(err).To(MatchError("update of more than one consenter at a time is not supported, requested changes: add 0 node(s), remove 2 node(s)"))
 				})
 
+				It("provisions standby consenters without changing the consenter set", func() {
+					metadata := &raftprotos.ConfigMetadata{}
+					for _, consenter := range raftMetadata.Consenters {
+						metadata.Consenters = append(metadata.Consenters, consenter)
+					}
+					metadata.StandbyConsenters = []*raftprotos.Consenter{{
+						Host:          "localhost",
+						Port:          7050,
+						ServerTlsCert: []byte("not a certificate"),
+						ClientTlsCert: clientTLSCert(tlsCA),
+					}}
+					standbyConfigEnv := func() *common.Envelope {
+						return newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, map[string]*common.ConfigValue{
+							"ConsensusType": {
+								Version: 1,
+								Value: marshalOrPanic(&orderer.ConsensusType{
+									Metadata: marshalOrPanic(metadata),
+								}),
+							},
+						}))
+					}
+
+					By("rejecting a standby consenter with an invalid certificate")
+					err := c1.Configure(standbyConfigEnv(), 0)
+					Expect(err).To(MatchError("invalid standby consenter: invalid server TLS certificate: no PEM data found"))
+
+					By("accepting a valid standby consenter")
+					clusterSizeUpdates := c1.fakeFields.fakeClusterSize.SetCallCount()
+					metadata.StandbyConsenters[0].ServerTlsCert = serverTLSCert(tlsCA)
+					c1.cutter.CutNext = true
+					err = c1.Configure(standbyConfigEnv(), 0)
+					Expect(err).NotTo(HaveOccurred())
+
+					network.exec(func(c *chain) {
+						Eventually(c.support.WriteConfigBlockCallCount, defaultTimeout).Should(Equal(1))
+					})
+
+					By("continuing to order transactions with the same consenters")
+					err = c1.Order(env, 0)
+					Expect(err).NotTo(HaveOccurred())
+					network.exec(func(c *chain) {
+						Eventually(c.support.WriteBlockCallCount, defaultTimeout).Should(Equal(2))
+					})
+					Expect(c1.fakeFields.fakeClusterSize.SetCallCount()).To(Equal(clusterSizeUpdates))
+				})
+
+				It("rejects a consenter whose TLS certificate uses a weak key", func() {
+					metadata := &raftprotos.ConfigMetadata{}
+					for _, consenter := range raftMetadata.Consenters {
+						metadata.Consenters = append(metadata.Consenters, consenter)
+					}
+					metadata.Consenters = append(metadata.Consenters, &raftprotos.Consenter{
+						Host:          "node-4.example.com",
+						Port:          7050,
+						ServerTlsCert: weakTLSCert(),
+						ClientTlsCert: clientTLSCert(tlsCA),
+					})
+
+					configEnv := newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, map[string]*common.ConfigValue{
+						"ConsensusType": {
+							Version: 1,
+							Value: marshalOrPanic(&orderer.ConsensusType{
+								Metadata: marshalOrPanic(metadata),
+							}),
+						},
+					}))
+
+					err := c1.Configure(configEnv, 0)
+					Expect(err).To(MatchError("invalid consenter node-4.example.com:7050: invalid server TLS certificate: RSA key of 1024 bits is weaker than the required 2048 bits"))
+				})
+
 				It("can rotate certificate by adding and removing 1 node in one config update", func() {
 					updatedRaftMetadata := proto.Clone(raftMetadata).(*raftprotos.BlockMetadata)
 					// remove second consenter
@@ -1703,6 +3362,35 @@ var _ = Describe("Chain", func() {
 					})
 				})
 
+				It("rejects channel creation txs if the system channel is deprecated", func() {
+					c1.support.SharedConfigReturns(&mockconfig.Orderer{
+						BatchTimeoutVal: timeout,
+						CapabilitiesVal: &mockconfig.OrdererCapabilities{SystemChannelDeprecationVal: true},
+					})
+
+					configEnv := newConfigEnv("another-channel",
+						common.HeaderType_CONFIG,
+						newConfigUpdateEnv(channelID, removeConsenterConfigValue(2)))
+					channelCreationEnv := &common.Envelope{
+						Payload: marshalOrPanic(&common.Payload{
+							Header: &common.Header{
+								ChannelHeader: marshalOrPanic(&common.ChannelHeader{
+									Type:      int32(common.HeaderType_ORDERER_TRANSACTION),
+									ChannelId: channelID,
+								}),
+							},
+							Data: marshalOrPanic(configEnv),
+						}),
+					}
+
+					err := c1.Configure(channelCreationEnv, 0)
+					Expect(err).To(MatchError(ContainSubstring("channel creation transactions are not supported as the system channel is deprecated")))
+					Expect(c1.fakeFields.fakeProposalFailures.AddCallCount()).To(Equal(1))
+					network.exec(func(c *chain) {
+						Consistently(c.support.WriteConfigBlockCallCount).Should(Equal(0))
+					})
+				})
+
 				It("adding node to the cluster of 2/3 available nodes", func() {
 					// Scenario: disconnect one of existing nodes from the replica set
 					// add new node, reconnect the old one and choose newly added as a
@@ -2036,6 +3724,40 @@ var _ = Describe("Chain", func() {
 					Consistently(c1.support.WriteBlockCallCount).Should(Equal(1))
 				})
 
+				It("reports a ConfChange which is not applied within the timeout as stalled", func() {
+					configEnv := newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, addConsenterConfigValue()))
+					c1.cutter.CutNext = true
+
+					By("disconnecting the followers before the ConfChange is proposed")
+					stub := c1.support.WriteConfigBlockStub
+					c1.support.WriteConfigBlockStub = func(b *common.Block, meta []byte) {
+						network.disconnect(2)
+						network.disconnect(3)
+						stub(b, meta)
+					}
+
+					Expect(c1.Configure(configEnv, 0)).To(Succeed())
+					Eventually(c1.support.WriteConfigBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					Expect(c1.Info().ConfChangeStalled).To(BeFalse())
+
+					Eventually(func() bool {
+						c1.clock.Increment(time.Minute)
+						return c1.Info().ConfChangeStalled
+					}, LongEventualTimeout).Should(BeTrue())
+					Expect(c1.fakeFields.fakeConfChangeStalled.SetArgsForCall(c1.fakeFields.fakeConfChangeStalled.SetCallCount() - 1)).To(Equal(float64(1)))
+
+					By("reconnecting the followers")
+					network.connect(2)
+					network.connect(3)
+
+					Eventually(func() bool {
+						c1.clock.Increment(interval)
+						return c1.Info().ConfChangeStalled
+					}, LongEventualTimeout).Should(BeFalse())
+					Expect(c1.fakeFields.fakeConfChangeStalled.SetArgsForCall(c1.fakeFields.fakeConfChangeStalled.SetCallCount() - 1)).To(Equal(float64(0)))
+					Expect(c2.Info().ConfChangeStalled).To(BeFalse())
+				})
+
 				It("does not deadlock if leader steps down while config block is in-flight", func() {
 					configEnv := newConfigEnv(channelID, common.HeaderType_CONFIG, newConfigUpdateEnv(channelID, addConsenterConfigValue()))
 					c1.cutter.CutNext = true
@@ -2128,6 +3850,38 @@ var _ = Describe("Chain", func() {
 				Expect(c3.fakeFields.fakeIsLeader.SetArgsForCall(0)).Should(Equal(float64(0)))
 			})
 
+			It("refuses to propose markers until every consenter supports them", func() {
+				Expect(c1.ProposeMarker(raftprotos.Marker_PAUSE, "maintenance")).To(MatchError("markers are not supported by every consenter of the channel"))
+				Expect(c1.Paused()).To(BeFalse())
+			})
+
+			Context("when every consenter supports markers", func() {
+				BeforeEach(func() {
+					network.exec(func(c *chain) { c.opts.Features = etcdraft.SupportedFeatures() })
+				})
+
+				It("applies markers on every node", func() {
+					Eventually(func() uint32 { return c1.FeatureVersion(etcdraft.FeatureMarkers) }, LongEventualTimeout).Should(Equal(uint32(1)))
+
+					Expect(c1.ProposeMarker(raftprotos.Marker_PAUSE, "maintenance")).To(Succeed())
+					network.exec(func(c *chain) {
+						Eventually(c.Paused, LongEventualTimeout).Should(BeTrue())
+					})
+					Expect(c2.ProposeMarker(raftprotos.Marker_RESUME, "done")).To(MatchError("node 2 is not the leader, the leader is node 1"))
+
+					Expect(c1.ProposeMarker(raftprotos.Marker_RESUME, "done")).To(Succeed())
+					network.exec(func(c *chain) {
+						Eventually(c.Paused, LongEventualTimeout).Should(BeFalse())
+					})
+
+					c1.cutter.CutNext = true
+					Expect(c1.Order(env, 0)).To(Succeed())
+					network.exec(func(c *chain) {
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					})
+				})
+			})
+
 			It("orders envelope on leader", func() {
 				By("instructed to cut next block")
 				c1.cutter.CutNext = true
@@ -2150,7 +3904,7 @@ var _ = Describe("Chain", func() {
 				Expect(c1.fakeFields.fakeNormalProposalsReceived.AddArgsForCall(1)).To(Equal(float64(1)))
 				Eventually(c1.cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-				c1.clock.WaitForNWatchersAndIncrement(timeout, 2)
+				c1.clock.WaitForNWatchersAndIncrement(timeout, 3)
 				network.exec(
 					func(c *chain) {
 						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
@@ -2181,13 +3935,109 @@ var _ = Describe("Chain", func() {
 				Expect(c1.fakeFields.fakeNormalProposalsReceived.AddCallCount()).To(Equal(0))
 				Eventually(c1.cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
-				c1.clock.WaitForNWatchersAndIncrement(timeout, 2)
+				c1.clock.WaitForNWatchersAndIncrement(timeout, 3)
 				network.exec(
 					func(c *chain) {
 						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
 					})
 			})
 
+			It("forwards envelopes on follower to the last known leader directly", func() {
+				release := make(chan struct{})
+				c2.support.WriteBlockStub = func(*common.Block, []byte) {
+					<-release
+				}
+				defer close(release)
+
+				c1.cutter.CutNext = true
+				Expect(c2.Order(env, 0)).To(Succeed())
+				Eventually(c1.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Eventually(c2.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				// the follower is stuck writing the block, yet it forwards to the leader
+				Expect(c2.Order(env, 0)).To(Succeed())
+				Eventually(c1.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+				Expect(c2.rpc.SendSubmitCallCount()).To(Equal(2))
+
+				By("asking for the leader once the hint expires")
+				c2.clock.Increment(etcdraft.LeaderHintTTL)
+				errC := make(chan error, 1)
+				go func() {
+					errC <- c2.Order(env, 0)
+				}()
+				Consistently(errC).ShouldNot(Receive())
+				Expect(c2.rpc.SendSubmitCallCount()).To(Equal(2))
+			})
+
+			It("does not forward envelopes of other nodes to the last known leader directly", func() {
+				release := make(chan struct{})
+				c2.support.WriteBlockStub = func(*common.Block, []byte) {
+					<-release
+				}
+				defer close(release)
+
+				c1.cutter.CutNext = true
+				Expect(c2.Order(env, 0)).To(Succeed())
+				Eventually(c1.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				Eventually(c2.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+
+				// the follower is stuck writing the block, so it does not forward what node 3 sent
+				errC := make(chan error, 1)
+				go func() {
+					errC <- c2.Submit(&orderer.SubmitRequest{LastValidationSeq: 0, Payload: env, Channel: channelID}, 3)
+				}()
+				Consistently(errC).ShouldNot(Receive())
+				Expect(c2.rpc.SendSubmitCallCount()).To(Equal(1))
+			})
+
+			It("falls back to asking for the leader if the last known leader is unreachable", func() {
+				c1.cutter.CutNext = true
+				Expect(c2.Order(env, 0)).To(Succeed())
+				network.exec(
+					func(c *chain) {
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+					})
+
+				network.disconnect(1)
+				err := c2.Order(env, 0)
+				Expect(err).To(MatchError("connection lost; the leader is node 1 at localhost:7051"))
+				Expect(err).To(BeAssignableToTypeOf(&etcdraft.LeaderError{}))
+				Expect(err.(*etcdraft.LeaderError).Leader).To(Equal(uint64(1)))
+				Expect(errors.Cause(err)).To(MatchError("connection lost"))
+				Expect(c2.rpc.SendSubmitCallCount()).To(Equal(3))
+			})
+
+			It("runs a self-test which orders a diagnostic envelope through the leader", func() {
+				c1.cutter.CutNext = true
+				c2.support.NewSignatureHeaderReturns(&common.SignatureHeader{}, nil)
+
+				result, err := c2.SelfTest(LongEventualTimeout)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Channel).To(Equal(channelID))
+				Expect(result.TxID).To(HavePrefix(etcdraft.SelfTestTxIDPrefix))
+				Expect(result.BlockNumber).To(Equal(uint64(1)))
+				Expect(result.Leader).To(Equal(uint64(1)))
+
+				network.exec(func(c *chain) {
+					Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+				})
+				block, _ := c3.support.WriteBlockArgsForCall(0)
+				payload, err := utils.UnmarshalPayload(utils.UnmarshalEnvelopeOrPanic(block.Data.Data[0]).Payload)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(payload.Data)).To(Equal(etcdraft.SelfTestData))
+
+				By("failing the self-test if the envelope is not committed in time")
+				c1.cutter.CutNext = false
+				errC := make(chan error, 1)
+				go func() {
+					_, err := c2.SelfTest(time.Minute)
+					errC <- err
+				}()
+				Eventually(c1.cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
+				c2.clock.Increment(time.Minute)
+				Eventually(errC, LongEventualTimeout).Should(Receive(MatchError(ContainSubstring("was not committed within 1m0s"))))
+			})
+
 			When("MaxInflightMsgs is reached", func() {
 				BeforeEach(func() {
 					network.exec(func(c *chain) { c.opts.MaxInflightMsgs = 1 })
@@ -2258,6 +4108,42 @@ var _ = Describe("Chain", func() {
 				})
 			})
 
+			When("MaxInflightBytes is reached", func() {
+				BeforeEach(func() {
+					network.exec(func(c *chain) { c.opts.MaxInflightBytes = 1 })
+				})
+
+				It("waits for in flight blocks to be committed", func() {
+					c1.cutter.CutNext = true
+					// disconnect c1 to disrupt consensus
+					network.disconnect(1)
+
+					Expect(c1.Order(env, 0)).To(Succeed())
+					Eventually(func() uint64 { return c1.PendingBatch().BytesInFlight }, LongEventualTimeout).Should(BeNumerically(">", 1))
+
+					doneProp := make(chan struct{})
+					go func() {
+						defer GinkgoRecover()
+						Expect(c1.Order(env, 0)).To(Succeed())
+						close(doneProp)
+					}()
+					// expect second `Order` to block
+					Consistently(doneProp).ShouldNot(BeClosed())
+					network.exec(func(c *chain) {
+						Consistently(c.support.WriteBlockCallCount).Should(BeZero())
+					})
+
+					network.connect(1)
+					c1.clock.Increment(interval)
+
+					Eventually(doneProp, LongEventualTimeout).Should(BeClosed())
+					network.exec(func(c *chain) {
+						Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+					})
+					Eventually(func() uint64 { return c1.PendingBatch().BytesInFlight }, LongEventualTimeout).Should(BeZero())
+				})
+			})
+
 			When("leader is disconnected", func() {
 				It("proactively steps down to follower", func() {
 					network.disconnect(1)
@@ -2360,6 +4246,14 @@ var _ = Describe("Chain", func() {
 					Expect(errorC).To(BeClosed())
 
 					Eventually(c2.Errored).ShouldNot(BeClosed())
+
+					By("Replaying the error and the recovery missed by waiting on errorC")
+					transitions, _ := c2.HealthTransitions(0)
+					Expect(len(transitions)).To(BeNumerically(">=", 2))
+					errored, recovered := transitions[len(transitions)-2], transitions[len(transitions)-1]
+					Expect(errored.Errored).To(BeTrue())
+					Expect(recovered.Errored).To(BeFalse())
+					Expect(recovered.Sequence).To(Equal(errored.Sequence + 1))
 				})
 			})
 
@@ -2775,14 +4669,14 @@ var _ = Describe("Chain", func() {
 					Eventually(c1.cutter.CurBatch, LongEventualTimeout).Should(HaveLen(1))
 
 					// no block should be written because env is not cut into block yet
-					c1.clock.WaitForNWatchersAndIncrement(interval, 2)
+					c1.clock.WaitForNWatchersAndIncrement(interval, 3)
 					Consistently(c1.support.WriteBlockCallCount).Should(Equal(0))
 
 					network.disconnect(1)
 					network.elect(2)
 					network.join(1, true)
 
-					Eventually(c1.clock.WatcherCount, LongEventualTimeout).Should(Equal(1)) // blockcutter time is stopped
+					Eventually(c1.clock.WatcherCount, LongEventualTimeout).Should(Equal(2)) // blockcutter time is stopped
 					Eventually(c1.cutter.CurBatch, LongEventualTimeout).Should(HaveLen(0))
 					// the created block should be discarded since there is a leadership change
 					Consistently(c1.support.WriteBlockCallCount).Should(Equal(0))
@@ -2816,7 +4710,7 @@ var _ = Describe("Chain", func() {
 					//                at this point of time     it should fire
 					//                timer should not fire     at this point
 
-					c1.clock.WaitForNWatchersAndIncrement(timeout-interval, 2)
+					c1.clock.WaitForNWatchersAndIncrement(timeout-interval, 3)
 					Eventually(func() int { return c1.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(0))
 					Eventually(func() int { return c3.support.WriteBlockCallCount() }, LongEventualTimeout).Should(Equal(0))
 
@@ -2862,6 +4756,96 @@ var _ = Describe("Chain", func() {
 			})
 		})
 	})
+
+	Describe("Large Raft clusters", func() {
+		var (
+			network      *network
+			dataDir      string
+			raftMetadata *raftprotos.BlockMetadata
+		)
+
+		BeforeEach(func() {
+			var err error
+			dataDir, err = ioutil.TempDir("", "raft-test-")
+			Expect(err).NotTo(HaveOccurred())
+
+			raftMetadata = &raftprotos.BlockMetadata{
+				Consenters:      map[uint64]*raftprotos.Consenter{},
+				NextConsenterId: raftprotos.MaxClusterSize + 1,
+			}
+			for id := uint64(1); id <= raftprotos.MaxClusterSize; id++ {
+				raftMetadata.Consenters[id] = &raftprotos.Consenter{
+					Host:          "localhost",
+					Port:          7051,
+					ClientTlsCert: clientTLSCert(tlsCA),
+					ServerTlsCert: serverTLSCert(tlsCA),
+				}
+			}
+
+			network = createNetwork(10*time.Second, "large-channel", dataDir, raftMetadata)
+			network.exec(func(c *chain) { c.opts.ElectionTick = raftprotos.LargeClusterElectionTick })
+		})
+
+		AfterEach(func() {
+			network.stop()
+			os.RemoveAll(dataDir)
+		})
+
+		It("orders blocks with the maximum number of consenters and survives the loss of the leader", func() {
+			network.init()
+			network.start()
+			network.elect(1)
+
+			c1 := network.chains[1]
+			c1.cutter.CutNext = true
+			Expect(c1.Order(env, 0)).To(Succeed())
+			network.exec(func(c *chain) {
+				Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(1))
+			})
+
+			By("electing another leader once the leader is gone")
+			network.disconnect(1)
+			network.elect(2)
+
+			c2 := network.chains[2]
+			c2.cutter.CutNext = true
+			Expect(c2.Order(env, 0)).To(Succeed())
+			network.exec(func(c *chain) {
+				if c.id == 1 {
+					return
+				}
+				Eventually(c.support.WriteBlockCallCount, LongEventualTimeout).Should(Equal(2))
+			})
+		})
+
+		It("rejects adding a consenter beyond the maximum cluster size", func() {
+			network.init()
+			network.start()
+			network.elect(1)
+
+			metadata := &raftprotos.ConfigMetadata{}
+			for id := uint64(1); id <= raftprotos.MaxClusterSize; id++ {
+				metadata.Consenters = append(metadata.Consenters, raftMetadata.Consenters[id])
+			}
+			metadata.Consenters = append(metadata.Consenters, &raftprotos.Consenter{
+				Host:          "localhost",
+				Port:          7051,
+				ClientTlsCert: clientTLSCert(tlsCA),
+				ServerTlsCert: serverTLSCert(tlsCA),
+			})
+			value := map[string]*common.ConfigValue{
+				"ConsensusType": {
+					Version: 1,
+					Value: marshalOrPanic(&orderer.ConsensusType{
+						Metadata: marshalOrPanic(metadata),
+					}),
+				},
+			}
+
+			configEnv := newConfigEnv("large-channel", common.HeaderType_CONFIG, newConfigUpdateEnv("large-channel", value))
+			Expect(network.chains[1].Configure(configEnv, 0)).To(MatchError("22 consenters exceed the maximum cluster size of 21"))
+		})
+	})
 })
 
 func nodeConfigFromMetadata(consenterMetadata *raftprotos.ConfigMetadata) []cluster.RemoteNode {
@@ -2905,6 +4889,24 @@ func serverTLSCert(tlsCA tlsgen.CA) []byte {
 	return cert.Cert
 }
 
+// weakTLSCert returns a self signed certificate with a 1024 bit RSA key
+func weakTLSCert() []byte {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		panic(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		panic(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
 func clientTLSCert(tlsCA tlsgen.CA) []byte {
 	cert, err := tlsCA.NewClientCertKeyPair()
 	if err != nil {
@@ -2984,6 +4986,7 @@ func newChain(timeout time.Duration, channel string, dataDir string, id uint64,
 	support.ChainIDReturns(channel)
 	support.SharedConfigReturns(&mockconfig.Orderer{
 		BatchTimeoutVal: timeout,
+		BatchSizeVal:    &orderer.BatchSize{PreferredMaxBytes: 512 * 1024},
 		CapabilitiesVal: &mockconfig.OrdererCapabilities{
 			Kafka2RaftMigVal: false,
 		},
@@ -3224,6 +5227,27 @@ func (n *network) addChain(c *chain) {
 		return nil
 	}
 
+	c.rpc.SendSubmitBatchStub = func(dest uint64, batch *orderer.SubmitBatch) error {
+		if !n.linked(c.id, dest) {
+			return errors.Errorf("connection refused")
+		}
+
+		if !n.connected(c.id) || !n.connected(dest) {
+			return errors.Errorf("connection lost")
+		}
+
+		n.RLock()
+		target := n.chains[dest]
+		n.RUnlock()
+		go func() {
+			defer GinkgoRecover()
+			for _, msg := range batch.Requests {
+				target.Submit(msg, c.id)
+			}
+		}()
+		return nil
+	}
+
 	c.puller.PullBlockStub = func(i uint64) *common.Block {
 		n.RLock()
 		leaderChain := n.chains[n.leader]
@@ -3332,6 +5356,7 @@ func (n *network) stop(ids ...uint64) {
 		c := n.chains[id]
 		c.Halt()
 		Eventually(c.Errored).Should(BeClosed())
+		Expect(c.WaitGoroutines(LongEventualTimeout)).To(Succeed())
 		select {
 		case <-c.stopped:
 		default:
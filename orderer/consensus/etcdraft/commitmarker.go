@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/pkg/fileutil"
+)
+
+// CommitMarkerFile is the name of the commit marker
+// file in the snapshot directory of a chain.
+const CommitMarkerFile = "commit.marker"
+
+// commitMarkerSize is the size of a commit marker file: the block number
+// and the raft index, followed by the CRC of both.
+const commitMarkerSize = 8 + 8 + 4
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrCorruptCommitMarker is returned when a commit marker file
+// does not hold a commit marker, e.g. due to a partial write.
+var ErrCorruptCommitMarker = errors.New("commit marker is corrupt")
+
+// CommitMarker records the last block handed to the ledger,
+// along with the raft index of the entry it was written from.
+type CommitMarker struct {
+	BlockNumber uint64
+	RaftIndex   uint64
+}
+
+// WriteCommitMarker atomically replaces the commit marker at the given path.
+// The marker is written to a temporary file which is then renamed, so that
+// a crash leaves either the previous or the new marker in place.
+func WriteCommitMarker(path string, marker CommitMarker) error {
+	buf := make([]byte, commitMarkerSize)
+	binary.BigEndian.PutUint64(buf[0:8], marker.BlockNumber)
+	binary.BigEndian.PutUint64(buf[8:16], marker.RaftIndex)
+	binary.BigEndian.PutUint32(buf[16:20], crc32.Checksum(buf[:16], crcTable))
+
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return errors.Wrap(err, "failed to create commit marker")
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to write commit marker")
+	}
+	if err := fileutil.Fsync(f); err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to sync commit marker")
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "failed to close commit marker")
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return errors.Wrap(err, "failed to replace commit marker")
+	}
+
+	dir, err := fileutil.OpenDir(filepath.Dir(path))
+	if err != nil {
+		return errors.Wrap(err, "failed to open commit marker directory")
+	}
+	defer dir.Close()
+	return errors.Wrap(fileutil.Fsync(dir), "failed to sync commit marker directory")
+}
+
+// ReadCommitMarker reads the commit marker at the given path.
+// It returns nil if there is no commit marker, and ErrCorruptCommitMarker
+// if the file does not hold one.
+func ReadCommitMarker(path string) (*CommitMarker, error) {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read commit marker")
+	}
+
+	if len(buf) != commitMarkerSize || crc32.Checksum(buf[:16], crcTable) != binary.BigEndian.Uint32(buf[16:20]) {
+		return nil, ErrCorruptCommitMarker
+	}
+	return &CommitMarker{
+		BlockNumber: binary.BigEndian.Uint64(buf[0:8]),
+		RaftIndex:   binary.BigEndian.Uint64(buf[8:16]),
+	}, nil
+}
+
+// RecoverAppliedIndex reconciles the commit marker with the last block of
+// the ledger, which was written from the given raft index, and returns the
+// raft index up to which entries were applied to the ledger. Entries beyond
+// it are replayed from the WAL.
+//
+// Blocks are committed to the ledger asynchronously, and the marker is
+// written after a block is handed to the ledger, hence a crash leaves the
+// marker either at the last block, or one block ahead of or behind it.
+// In each of these cases the ledger is authoritative. Any other marker
+// means the ledger or the marker were tampered with, and is an error.
+func RecoverAppliedIndex(marker *CommitMarker, lastBlock, raftIndex uint64) (uint64, error) {
+	if marker == nil {
+		return raftIndex, nil
+	}
+
+	switch {
+	case marker.BlockNumber == lastBlock:
+		if marker.RaftIndex != raftIndex {
+			return 0, errors.Errorf("block %d was written from raft index %d, but the commit marker records raft index %d",
+				lastBlock, raftIndex, marker.RaftIndex)
+		}
+	case marker.BlockNumber == lastBlock+1:
+		if marker.RaftIndex < raftIndex {
+			return 0, errors.Errorf("commit marker of block %d records raft index %d, which precedes raft index %d of block %d",
+				marker.BlockNumber, marker.RaftIndex, raftIndex, lastBlock)
+		}
+	case marker.BlockNumber+1 == lastBlock:
+		if marker.RaftIndex > raftIndex {
+			return 0, errors.Errorf("commit marker of block %d records raft index %d, which follows raft index %d of block %d",
+				marker.BlockNumber, marker.RaftIndex, raftIndex, lastBlock)
+		}
+	default:
+		return 0, errors.Errorf("commit marker is at block %d, but the last block of the ledger is %d", marker.BlockNumber, lastBlock)
+	}
+
+	return raftIndex, nil
+}
+
+// reconcileCommitMarker reconciles the commit marker at the given path with
+// the last block of the ledger, and resets the marker to that block.
+// A corrupt marker is discarded, since the ledger is authoritative anyway.
+func reconcileCommitMarker(lg *flogging.FabricLogger, path string, lastBlock, raftIndex uint64) error {
+	marker, err := ReadCommitMarker(path)
+	if err == ErrCorruptCommitMarker {
+		lg.Warnf("Discarding corrupt commit marker %s", path)
+		marker = nil
+	} else if err != nil {
+		return err
+	}
+
+	appliedIndex, err := RecoverAppliedIndex(marker, lastBlock, raftIndex)
+	if err != nil {
+		return err
+	}
+	if marker != nil && marker.BlockNumber != lastBlock {
+		lg.Infof("Commit marker is at block %d, recovering from block %d at raft index %d of the ledger",
+			marker.BlockNumber, lastBlock, appliedIndex)
+	}
+
+	return WriteCommitMarker(path, CommitMarker{BlockNumber: lastBlock, RaftIndex: appliedIndex})
+}
+
+// blockRaftIndex returns the raft index the given block was written
+// from, according to its metadata, or 0 if it has none.
+func blockRaftIndex(block *common.Block) uint64 {
+	m, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_ORDERER)
+	if err != nil {
+		return 0
+	}
+	md := &etcdraft.BlockMetadata{}
+	if err := proto.Unmarshal(m.Value, md); err != nil {
+		return 0
+	}
+	return md.RaftIndex
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitMarker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commit-marker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, CommitMarkerFile)
+
+	marker, err := ReadCommitMarker(path)
+	assert.NoError(t, err)
+	assert.Nil(t, marker)
+
+	require.NoError(t, WriteCommitMarker(path, CommitMarker{BlockNumber: 10, RaftIndex: 42}))
+	require.NoError(t, WriteCommitMarker(path, CommitMarker{BlockNumber: 11, RaftIndex: 45}))
+	marker, err = ReadCommitMarker(path)
+	assert.NoError(t, err)
+	assert.Equal(t, &CommitMarker{BlockNumber: 11, RaftIndex: 45}, marker)
+
+	buf, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	// partially written marker
+	require.NoError(t, ioutil.WriteFile(path, buf[:commitMarkerSize-1], 0640))
+	_, err = ReadCommitMarker(path)
+	assert.Equal(t, ErrCorruptCommitMarker, err)
+
+	// marker with a flipped bit
+	buf[3] ^= 1
+	require.NoError(t, ioutil.WriteFile(path, buf, 0640))
+	_, err = ReadCommitMarker(path)
+	assert.Equal(t, ErrCorruptCommitMarker, err)
+}
+
+func TestRecoverAppliedIndex(t *testing.T) {
+	for _, testCase := range []struct {
+		name          string
+		marker        *CommitMarker
+		expectedError string
+	}{
+		{
+			name: "no marker",
+		},
+		{
+			name:   "marker at the last block",
+			marker: &CommitMarker{BlockNumber: 10, RaftIndex: 20},
+		},
+		{
+			name:   "block handed to the ledger but not committed",
+			marker: &CommitMarker{BlockNumber: 11, RaftIndex: 22},
+		},
+		{
+			name:   "block committed but marker not updated",
+			marker: &CommitMarker{BlockNumber: 9, RaftIndex: 18},
+		},
+		{
+			name:          "raft index mismatch",
+			marker:        &CommitMarker{BlockNumber: 10, RaftIndex: 21},
+			expectedError: "block 10 was written from raft index 20, but the commit marker records raft index 21",
+		},
+		{
+			name:          "next block at a preceding raft index",
+			marker:        &CommitMarker{BlockNumber: 11, RaftIndex: 19},
+			expectedError: "commit marker of block 11 records raft index 19, which precedes raft index 20 of block 10",
+		},
+		{
+			name:          "previous block at a following raft index",
+			marker:        &CommitMarker{BlockNumber: 9, RaftIndex: 21},
+			expectedError: "commit marker of block 9 records raft index 21, which follows raft index 20 of block 10",
+		},
+		{
+			name:          "ledger far behind the marker",
+			marker:        &CommitMarker{BlockNumber: 15, RaftIndex: 30},
+			expectedError: "commit marker is at block 15, but the last block of the ledger is 10",
+		},
+		{
+			name:          "marker far behind the ledger",
+			marker:        &CommitMarker{BlockNumber: 5, RaftIndex: 10},
+			expectedError: "commit marker is at block 5, but the last block of the ledger is 10",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			index, err := RecoverAppliedIndex(testCase.marker, 10, 20)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(20), index)
+		})
+	}
+}
+
+func TestReconcileCommitMarker(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commit-marker")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, CommitMarkerFile)
+	logger := flogging.MustGetLogger("test")
+
+	// the marker is reset to the last block of the ledger
+	require.NoError(t, WriteCommitMarker(path, CommitMarker{BlockNumber: 11, RaftIndex: 22}))
+	require.NoError(t, reconcileCommitMarker(logger, path, 10, 20))
+	marker, err := ReadCommitMarker(path)
+	require.NoError(t, err)
+	assert.Equal(t, &CommitMarker{BlockNumber: 10, RaftIndex: 20}, marker)
+
+	// a corrupt marker is discarded
+	require.NoError(t, ioutil.WriteFile(path, []byte("garbage"), 0640))
+	require.NoError(t, reconcileCommitMarker(logger, path, 12, 24))
+	marker, err = ReadCommitMarker(path)
+	require.NoError(t, err)
+	assert.Equal(t, &CommitMarker{BlockNumber: 12, RaftIndex: 24}, marker)
+
+	// an inconsistent marker is left in place
+	require.NoError(t, WriteCommitMarker(path, CommitMarker{BlockNumber: 5, RaftIndex: 10}))
+	assert.EqualError(t, reconcileCommitMarker(logger, path, 12, 24), "commit marker is at block 5, but the last block of the ledger is 12")
+	marker, err = ReadCommitMarker(path)
+	require.NoError(t, err)
+	assert.Equal(t, &CommitMarker{BlockNumber: 5, RaftIndex: 10}, marker)
+}
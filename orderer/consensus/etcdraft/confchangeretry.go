@@ -0,0 +1,180 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// confChangeRetrier proposes the ConfChange in flight again while the node
+// leads, whenever it is not applied within the retry interval. A ConfChange
+// proposed while the leadership changes is silently dropped by raft, and is
+// otherwise proposed again only once a node is elected, during which time
+// transactions are refused. Once the ConfChange was proposed the maximum
+// number of attempts, it is no longer retried and the failure is reported.
+// It is only accessed by the goroutine serving the requests of the chain.
+type confChangeRetrier struct {
+	logger      *flogging.FabricLogger
+	clock       clock.Clock
+	interval    time.Duration
+	maxAttempts int
+	propose     func(cc raftpb.ConfChange)
+	notify      func(event Event)
+	metrics     *Metrics
+
+	leading   bool
+	cc        *raftpb.ConfChange // in flight, if any
+	attempts  int                // of cc by the node while leading
+	exhausted bool               // whether cc is no longer retried
+	timer     clock.Timer        // of the next attempt, if any
+}
+
+// newConfChangeRetrier returns a confChangeRetrier,
+// or nil if ConfChanges are not to be retried.
+func newConfChangeRetrier(logger *flogging.FabricLogger, clock clock.Clock, interval time.Duration, maxAttempts int,
+	propose func(cc raftpb.ConfChange), notify func(event Event), metrics *Metrics) *confChangeRetrier {
+	if interval <= 0 || maxAttempts <= 0 {
+		return nil
+	}
+	return &confChangeRetrier{
+		logger:      logger,
+		clock:       clock,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		propose:     propose,
+		notify:      notify,
+		metrics:     metrics,
+	}
+}
+
+// proposed records that the given ConfChange was proposed by the node,
+// and schedules its next attempt if the node leads.
+func (r *confChangeRetrier) proposed(cc *raftpb.ConfChange) {
+	if r == nil {
+		return
+	}
+
+	if r.cc == nil || r.cc.NodeID != cc.NodeID || r.cc.Type != cc.Type {
+		r.resolved()
+		r.cc = cc
+	}
+	if !r.leading {
+		return
+	}
+	r.attempts++
+	r.schedule()
+}
+
+// lead records whether the node leads. The retries are stopped once the node
+// steps down, as followers drop proposals, and are resumed by the proposal
+// of the ConfChange in flight once the node is elected again.
+func (r *confChangeRetrier) lead(leading bool) {
+	if r == nil {
+		return
+	}
+
+	r.leading = leading
+	if !leading {
+		r.stop()
+	}
+}
+
+// applied records that the given ConfChange was applied,
+// and stops its retries if it is the one in flight.
+func (r *confChangeRetrier) applied(cc raftpb.ConfChange) {
+	if r == nil || r.cc == nil || r.cc.NodeID != cc.NodeID || r.cc.Type != cc.Type {
+		return
+	}
+
+	if r.attempts > 1 {
+		r.logger.Infof("%s of node %d was applied after %d attempts", cc.Type, cc.NodeID, r.attempts)
+	}
+	r.resolved()
+}
+
+// retryC returns the channel the next attempt is due on,
+// which is nil if none is scheduled.
+func (r *confChangeRetrier) retryC() <-chan time.Time {
+	if r == nil || r.timer == nil {
+		return nil
+	}
+	return r.timer.C()
+}
+
+// retry proposes the ConfChange in flight again, unless it was already
+// proposed the maximum number of attempts, in which case it is reported.
+func (r *confChangeRetrier) retry() {
+	if r == nil {
+		return
+	}
+
+	r.timer = nil
+	if r.cc == nil || !r.leading {
+		return
+	}
+	if r.attempts >= r.maxAttempts {
+		r.exhaust()
+		return
+	}
+
+	r.attempts++
+	r.logger.Warnf("%s of node %d was not applied within %s, proposing it again (attempt %d of %d)",
+		r.cc.Type, r.cc.NodeID, r.interval, r.attempts, r.maxAttempts)
+	r.metrics.ConfChangeReproposals.Add(1)
+	r.propose(*r.cc)
+	r.schedule()
+}
+
+func (r *confChangeRetrier) exhaust() {
+	if r.exhausted {
+		return
+	}
+	r.exhausted = true
+
+	r.logger.Errorf("%s of node %d was not applied after %d attempts, it is no longer retried and transactions are refused until it is applied",
+		r.cc.Type, r.cc.NodeID, r.attempts)
+	r.metrics.ConfChangeRetriesExhausted.Set(1)
+	event := Event{Type: EventConfChangeRetriesExhausted, Cause: fmt.Sprintf("not applied after %d attempts", r.attempts)}
+	if r.cc.Type == raftpb.ConfChangeRemoveNode {
+		event.RemovedNode = r.cc.NodeID
+	} else {
+		event.AddedNode = r.cc.NodeID
+	}
+	r.notify(event)
+}
+
+// resolved forgets the ConfChange in flight, along with its attempts.
+func (r *confChangeRetrier) resolved() {
+	if r.exhausted {
+		r.metrics.ConfChangeRetriesExhausted.Set(0)
+	}
+	r.cc = nil
+	r.attempts = 0
+	r.exhausted = false
+	r.stop()
+}
+
+func (r *confChangeRetrier) schedule() {
+	r.stop()
+	if r.exhausted {
+		return
+	}
+	r.timer = r.clock.NewTimer(r.interval)
+}
+
+func (r *confChangeRetrier) stop() {
+	if r.timer == nil {
+		return
+	}
+	r.timer.Stop()
+	r.timer = nil
+}
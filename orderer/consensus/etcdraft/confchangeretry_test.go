@@ -0,0 +1,106 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestConfChangeRetrier(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	reproposals := &metricsfakes.Counter{}
+	exhausted := &metricsfakes.Gauge{}
+	metrics := &Metrics{ConfChangeReproposals: reproposals, ConfChangeRetriesExhausted: exhausted}
+	var proposed []raftpb.ConfChange
+	var events []Event
+	propose := func(cc raftpb.ConfChange) { proposed = append(proposed, cc) }
+	notify := func(event Event) { events = append(events, event) }
+
+	// expire fires the timer of the next attempt, which is expected to be due
+	expire := func(r *confChangeRetrier) {
+		require.NotNil(t, r.retryC())
+		clock.WaitForWatcherAndIncrement(time.Minute)
+		<-r.retryC()
+		r.retry()
+	}
+
+	assert.Nil(t, newConfChangeRetrier(flogging.MustGetLogger("test"), clock, 0, 3, propose, notify, metrics))
+	assert.Nil(t, newConfChangeRetrier(flogging.MustGetLogger("test"), clock, time.Minute, 0, propose, notify, metrics))
+	var disabled *confChangeRetrier
+	disabled.lead(true)
+	disabled.proposed(&raftpb.ConfChange{NodeID: 4, Type: raftpb.ConfChangeAddNode})
+	assert.Nil(t, disabled.retryC())
+	disabled.retry()
+	disabled.applied(raftpb.ConfChange{NodeID: 4, Type: raftpb.ConfChangeAddNode})
+
+	r := newConfChangeRetrier(flogging.MustGetLogger("test"), clock, time.Minute, 3, propose, notify, metrics)
+	add := &raftpb.ConfChange{NodeID: 4, Type: raftpb.ConfChangeAddNode}
+
+	// followers drop proposals, hence they do not retry them
+	r.proposed(add)
+	assert.Nil(t, r.retryC())
+
+	// the leader proposes the ConfChange again until it is applied
+	r.lead(true)
+	r.proposed(add)
+	expire(r)
+	assert.Equal(t, []raftpb.ConfChange{*add}, proposed)
+	assert.Equal(t, 1, reproposals.AddCallCount())
+	r.applied(raftpb.ConfChange{NodeID: 5, Type: raftpb.ConfChangeAddNode})
+	assert.NotNil(t, r.retryC(), "another ConfChange was applied")
+	r.applied(*add)
+	assert.Nil(t, r.retryC())
+
+	// the retries stop once the node steps down, and
+	// resume once the node is elected and proposes it
+	remove := &raftpb.ConfChange{NodeID: 2, Type: raftpb.ConfChangeRemoveNode}
+	r.proposed(remove)
+	r.lead(false)
+	assert.Nil(t, r.retryC())
+	r.lead(true)
+	r.proposed(remove)
+
+	// the failure is reported once the maximum number of attempts,
+	// counted across the terms the node leads, is reached
+	proposed = nil
+	expire(r)
+	assert.Equal(t, []raftpb.ConfChange{*remove}, proposed)
+	assert.Empty(t, events)
+	expire(r)
+	assert.Len(t, proposed, 1, "the ConfChange is no longer retried")
+	assert.Nil(t, r.retryC())
+	require.Len(t, events, 1)
+	assert.Equal(t, EventConfChangeRetriesExhausted, events[0].Type)
+	assert.Equal(t, uint64(2), events[0].RemovedNode)
+	assert.Equal(t, "not applied after 3 attempts", events[0].Cause)
+	assert.Equal(t, 1, exhausted.SetCallCount())
+	assert.Equal(t, float64(1), exhausted.SetArgsForCall(0))
+
+	// a newly elected leader proposes it, but does not retry it
+	r.lead(false)
+	r.lead(true)
+	r.proposed(remove)
+	assert.Nil(t, r.retryC())
+	assert.Len(t, events, 1)
+
+	// the report is cleared once it is applied
+	r.applied(*remove)
+	assert.Equal(t, 2, exhausted.SetCallCount())
+	assert.Equal(t, float64(0), exhausted.SetArgsForCall(1))
+
+	// the next ConfChange is retried again
+	r.proposed(add)
+	assert.NotNil(t, r.retryC())
+}
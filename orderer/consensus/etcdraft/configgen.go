@@ -0,0 +1,166 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// ConsenterTemplate describes a consenter of a channel by its endpoint
+// and its PEM encoded TLS certificates.
+type ConsenterTemplate struct {
+	Host          string
+	Port          uint32
+	ClientTLSCert []byte
+	ServerTLSCert []byte
+}
+
+// ConfigTemplate describes the etcdraft configuration of a channel, from which
+// channel tooling generates the ConfigMetadata and the ConsensusType config value,
+// instead of assembling them on its own.
+type ConfigTemplate struct {
+	Consenters        []ConsenterTemplate
+	StandbyConsenters []ConsenterTemplate
+	// Options of the channel. Options which are not set
+	// default to the ones returned by DefaultOptions.
+	Options *etcdraft.Options
+}
+
+// DefaultOptions returns the options configtxgen uses for the options
+// which are not set in configtx.yaml.
+func DefaultOptions() *etcdraft.Options {
+	return &etcdraft.Options{
+		TickInterval:     "500ms",
+		ElectionTick:     10,
+		HeartbeatTick:    1,
+		MaxInflightMsgs:  5,
+		MaxSizePerMsg:    1024 * 1024,
+		SnapshotInterval: 100 * 1024 * 1024,
+	}
+}
+
+// ConfigMetadata generates the ConfigMetadata of the template,
+// and validates its consenters and options. The election tick
+// of large clusters defaults to a longer one.
+func (t ConfigTemplate) ConfigMetadata() (*etcdraft.ConfigMetadata, error) {
+	if len(t.Consenters) == 0 {
+		return nil, errors.New("no consenters in template")
+	}
+	if err := raftconfig.ValidateClusterSize(len(t.Consenters)); err != nil {
+		return nil, err
+	}
+
+	md := &etcdraft.ConfigMetadata{
+		Options: withDefaultOptions(t.Options, len(t.Consenters)),
+	}
+	if err := ValidateOptions(md.Options); err != nil {
+		return nil, errors.Wrap(err, "invalid options")
+	}
+
+	for _, ct := range t.Consenters {
+		consenter, err := ct.consenter()
+		if err != nil {
+			return nil, err
+		}
+		md.Consenters = append(md.Consenters, consenter)
+	}
+	for _, ct := range t.StandbyConsenters {
+		consenter, err := ct.consenter()
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid standby consenter")
+		}
+		md.StandbyConsenters = append(md.StandbyConsenters, consenter)
+	}
+
+	if err := ValidateConfigMetadata(md, true); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+// ConsensusTypeValue generates the ConsensusType config value of the template,
+// which is the value of the channelconfig.ConsensusTypeKey key of the orderer group.
+func (t ConfigTemplate) ConsensusTypeValue() (*common.ConfigValue, error) {
+	md, err := t.ConfigMetadata()
+	if err != nil {
+		return nil, err
+	}
+	mdBytes, err := proto.Marshal(md)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal config metadata")
+	}
+	value, err := proto.Marshal(channelconfig.ConsensusTypeValue(etcdraft.TypeKey, mdBytes).Value())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal consensus type")
+	}
+	return &common.ConfigValue{
+		Value:     value,
+		ModPolicy: channelconfig.AdminsPolicyKey,
+	}, nil
+}
+
+func (ct ConsenterTemplate) consenter() (*etcdraft.Consenter, error) {
+	consenter := &etcdraft.Consenter{
+		Host:          ct.Host,
+		Port:          ct.Port,
+		ClientTlsCert: ct.ClientTLSCert,
+		ServerTlsCert: ct.ServerTLSCert,
+	}
+	if err := ValidateConsenter(consenter); err != nil {
+		return nil, errors.Wrapf(err, "invalid consenter %s:%d", ct.Host, ct.Port)
+	}
+	return consenter, nil
+}
+
+// withDefaultOptions returns a copy of the options, where the options which
+// are not set are defaulted for a cluster of the given number of consenters.
+func withDefaultOptions(options *etcdraft.Options, consenters int) *etcdraft.Options {
+	defaults := DefaultOptions()
+	if consenters > etcdraft.LargeClusterSize {
+		defaults.ElectionTick = etcdraft.LargeClusterElectionTick
+	}
+	if options == nil {
+		return defaults
+	}
+
+	options = proto.Clone(options).(*etcdraft.Options)
+	if options.TickInterval == "" {
+		options.TickInterval = defaults.TickInterval
+	}
+	if options.ElectionTick == 0 {
+		options.ElectionTick = defaults.ElectionTick
+	}
+	if options.HeartbeatTick == 0 {
+		options.HeartbeatTick = defaults.HeartbeatTick
+	}
+	if options.MaxInflightMsgs == 0 {
+		options.MaxInflightMsgs = defaults.MaxInflightMsgs
+	}
+	if options.MaxSizePerMsg == 0 {
+		options.MaxSizePerMsg = defaults.MaxSizePerMsg
+	}
+	if options.SnapshotInterval == 0 {
+		options.SnapshotInterval = defaults.SnapshotInterval
+	}
+	return options
+}
+
+// ValidateOptions checks that the options can be used to start a chain.
+func ValidateOptions(options *etcdraft.Options) error {
+	return raftconfig.ValidateOptions(options)
+}
+
+// ValidateConfigMetadata checks that the metadata can be used to run a channel,
+// as raftconfig.ValidateConfigMetadata does.
+func ValidateConfigMetadata(md *etcdraft.ConfigMetadata, expectHosts bool) error {
+	return raftconfig.ValidateConfigMetadata(md, expectHosts)
+}
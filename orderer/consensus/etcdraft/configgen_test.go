@@ -0,0 +1,248 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConsenterTemplate(t *testing.T, ca tlsgen.CA, host string) ConsenterTemplate {
+	consenter := newConsenter(t, ca, host)
+	return ConsenterTemplate{
+		Host:          consenter.Host,
+		Port:          consenter.Port,
+		ClientTLSCert: consenter.ClientTlsCert,
+		ServerTLSCert: consenter.ServerTlsCert,
+	}
+}
+
+func TestConfigTemplate(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	template := ConfigTemplate{
+		Consenters: []ConsenterTemplate{
+			newConsenterTemplate(t, ca, "node1.example.com"),
+			newConsenterTemplate(t, ca, "node2.example.com"),
+		},
+		StandbyConsenters: []ConsenterTemplate{
+			newConsenterTemplate(t, ca, "node3.example.com"),
+		},
+		Options: &etcdraft.Options{
+			TickInterval: "100ms",
+			StateHash:    true,
+		},
+	}
+
+	md, err := template.ConfigMetadata()
+	require.NoError(t, err)
+	assert.Len(t, md.Consenters, 2)
+	assert.Equal(t, "node2.example.com", md.Consenters[1].Host)
+	assert.Equal(t, uint32(7050), md.Consenters[1].Port)
+	assert.Equal(t, template.Consenters[1].ServerTLSCert, md.Consenters[1].ServerTlsCert)
+	assert.Len(t, md.StandbyConsenters, 1)
+
+	expectedOptions := DefaultOptions()
+	expectedOptions.TickInterval = "100ms"
+	expectedOptions.StateHash = true
+	assert.True(t, proto.Equal(expectedOptions, md.Options))
+	assert.Equal(t, "100ms", template.Options.TickInterval)
+	assert.Zero(t, template.Options.ElectionTick, "options of the template should not be modified")
+
+	value, err := template.ConsensusTypeValue()
+	require.NoError(t, err)
+	assert.Equal(t, channelconfig.AdminsPolicyKey, value.ModPolicy)
+
+	consensusType := &orderer.ConsensusType{}
+	require.NoError(t, proto.Unmarshal(value.Value, consensusType))
+	assert.Equal(t, etcdraft.TypeKey, consensusType.Type)
+
+	configValueMetadata := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, configValueMetadata))
+	assert.True(t, proto.Equal(md, configValueMetadata))
+}
+
+func TestConfigTemplateValidation(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	node1 := newConsenterTemplate(t, ca, "node1.example.com")
+
+	noPort := node1
+	noPort.Port = 0
+
+	badCert := newConsenterTemplate(t, ca, "node2.example.com")
+	badCert.ServerTLSCert = []byte("not a certificate")
+
+	for _, testCase := range []struct {
+		name          string
+		template      ConfigTemplate
+		expectedError string
+	}{
+		{
+			name:          "no consenters",
+			template:      ConfigTemplate{},
+			expectedError: "no consenters in template",
+		},
+		{
+			name:          "no port",
+			template:      ConfigTemplate{Consenters: []ConsenterTemplate{noPort}},
+			expectedError: "invalid consenter node1.example.com:0: consenter has no port",
+		},
+		{
+			name:          "bad certificate",
+			template:      ConfigTemplate{Consenters: []ConsenterTemplate{node1, badCert}},
+			expectedError: "invalid consenter node2.example.com:7050: invalid server TLS certificate: no PEM data found",
+		},
+		{
+			name: "bad standby consenter",
+			template: ConfigTemplate{
+				Consenters:        []ConsenterTemplate{node1},
+				StandbyConsenters: []ConsenterTemplate{badCert},
+			},
+			expectedError: "invalid standby consenter: invalid consenter node2.example.com:7050: invalid server TLS certificate: no PEM data found",
+		},
+		{
+			name: "duplicate consenter",
+			template: ConfigTemplate{
+				Consenters:        []ConsenterTemplate{node1},
+				StandbyConsenters: []ConsenterTemplate{node1},
+			},
+			expectedError: "duplicate consenter",
+		},
+		{
+			name:          "too many consenters",
+			template:      ConfigTemplate{Consenters: make([]ConsenterTemplate, etcdraft.MaxClusterSize+1)},
+			expectedError: "22 consenters exceed the maximum cluster size of 21",
+		},
+		{
+			name: "bad tick interval",
+			template: ConfigTemplate{
+				Consenters: []ConsenterTemplate{node1},
+				Options:    &etcdraft.Options{TickInterval: "often"},
+			},
+			expectedError: "invalid options: tick interval often is not a duration",
+		},
+		{
+			name: "negative tick interval",
+			template: ConfigTemplate{
+				Consenters: []ConsenterTemplate{node1},
+				Options:    &etcdraft.Options{TickInterval: "-1s"},
+			},
+			expectedError: "invalid options: tick interval -1s is not positive",
+		},
+		{
+			name: "election tick not greater than heartbeat tick",
+			template: ConfigTemplate{
+				Consenters: []ConsenterTemplate{node1},
+				Options:    &etcdraft.Options{ElectionTick: 2, HeartbeatTick: 2},
+			},
+			expectedError: "invalid options: election tick must be greater than heartbeat tick",
+		},
+		{
+			name: "pre-vote and check quorum disabled",
+			template: ConfigTemplate{
+				Consenters: []ConsenterTemplate{node1},
+				Options:    &etcdraft.Options{DisablePreVote: true, DisableCheckQuorum: true},
+			},
+			expectedError: "invalid options: pre-vote and check quorum cannot be both disabled",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := testCase.template.ConfigMetadata()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+
+			_, err = testCase.template.ConsensusTypeValue()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
+
+func TestValidateConfigMetadata(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	node1 := newConsenter(t, ca, "node1.example.com")
+	node2 := newConsenter(t, ca, "node2.example.com")
+
+	assert.NoError(t, ValidateConfigMetadata(&etcdraft.ConfigMetadata{
+		Consenters: []*etcdraft.Consenter{node1, node2},
+		Options:    DefaultOptions(),
+	}, true))
+
+	// options are only validated if set
+	assert.NoError(t, ValidateConfigMetadata(&etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{node1}}, true))
+
+	// endpoints are only required if hosts are expected
+	noHost := proto.Clone(node2).(*etcdraft.Consenter)
+	noHost.Host, noHost.Port = "", 0
+	md := &etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{node1, noHost}}
+	assert.NoError(t, ValidateConfigMetadata(md, false))
+	assert.EqualError(t, ValidateConfigMetadata(md, true), "invalid consenter :0: consenter has no host")
+
+	badCert := proto.Clone(node2).(*etcdraft.Consenter)
+	badCert.ClientTlsCert = []byte("not a certificate")
+
+	for _, testCase := range []struct {
+		name          string
+		md            *etcdraft.ConfigMetadata
+		expectedError string
+	}{
+		{
+			name:          "nil metadata",
+			expectedError: "nil metadata",
+		},
+		{
+			name:          "duplicate consenter",
+			md:            &etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{node1, node1}},
+			expectedError: "duplicate consenter",
+		},
+		{
+			name:          "bad certificate",
+			md:            &etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{node1, badCert}},
+			expectedError: "invalid consenter node2.example.com:7050: invalid client TLS certificate: no PEM data found",
+		},
+		{
+			name: "bad standby consenter",
+			md: &etcdraft.ConfigMetadata{
+				Consenters:        []*etcdraft.Consenter{node1},
+				StandbyConsenters: []*etcdraft.Consenter{badCert},
+			},
+			expectedError: "invalid standby consenter: invalid client TLS certificate: no PEM data found",
+		},
+		{
+			name: "bad DR consenter",
+			md: &etcdraft.ConfigMetadata{
+				Consenters:   []*etcdraft.Consenter{node1},
+				DrConsenters: []*etcdraft.Consenter{badCert},
+			},
+			expectedError: "invalid DR consenter node2.example.com:7050: invalid client TLS certificate: no PEM data found",
+		},
+		{
+			name: "options out of range",
+			md: &etcdraft.ConfigMetadata{
+				Consenters: []*etcdraft.Consenter{node1},
+				Options:    &etcdraft.Options{TickInterval: "100ms", ElectionTick: 1, HeartbeatTick: 1},
+			},
+			expectedError: "invalid options: election tick must be greater than heartbeat tick",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateConfigMetadata(testCase.md, false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+}
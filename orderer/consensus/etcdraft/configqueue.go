@@ -0,0 +1,113 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/metrics"
+)
+
+// ConfigPendingError is returned when a config update is submitted while the
+// number of config updates pending on the channel reached MaxPendingConfigs.
+type ConfigPendingError struct {
+	Pending int // config updates submitted or in flight
+	Limit   int // MaxPendingConfigs of the node
+}
+
+func (e *ConfigPendingError) Error() string {
+	return fmt.Sprintf("%d config updates are pending, which reaches the maximum of %d, retry once they are committed", e.Pending, e.Limit)
+}
+
+// configQueue counts the config updates pending on the chain: those submitted to
+// serveRequest and not yet ordered, and the config block or ConfChange in flight,
+// which stalls the chain until it is committed. Config updates are rejected with
+// a ConfigPendingError once the limit is reached, instead of queueing up behind
+// each other. A nil configQueue counts nothing.
+type configQueue struct {
+	limit int           // not enforced if not set
+	gauge metrics.Gauge // of the number of pending config updates
+
+	lock     sync.Mutex
+	queued   int
+	inflight bool
+}
+
+func newConfigQueue(limit int, gauge metrics.Gauge) *configQueue {
+	return &configQueue{limit: limit, gauge: gauge}
+}
+
+// enqueue counts a config update submitted to serveRequest,
+// unless the limit of pending config updates is reached.
+func (q *configQueue) enqueue() error {
+	if q == nil {
+		return nil
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if pending := q.pending(); q.limit > 0 && pending >= q.limit {
+		return &ConfigPendingError{Pending: pending, Limit: q.limit}
+	}
+	q.queued++
+	q.report()
+	return nil
+}
+
+// dequeue stops counting a config update submitted to serveRequest,
+// once it is ordered, dropped, or forwarded to the leader.
+func (q *configQueue) dequeue() {
+	if q == nil {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.queued > 0 {
+		q.queued--
+	}
+	q.report()
+}
+
+// setInflight records whether a config block or ConfChange is in flight.
+func (q *configQueue) setInflight(inflight bool) {
+	if q == nil {
+		return
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.inflight = inflight
+	q.report()
+}
+
+// size returns the number of pending config updates.
+func (q *configQueue) size() int {
+	if q == nil {
+		return 0
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.pending()
+}
+
+func (q *configQueue) pending() int {
+	if q.inflight {
+		return q.queued + 1
+	}
+	return q.queued
+}
+
+func (q *configQueue) report() {
+	q.gauge.Set(float64(q.pending()))
+}
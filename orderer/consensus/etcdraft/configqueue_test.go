@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigQueue(t *testing.T) {
+	gauge := &metricsfakes.Gauge{}
+	q := newConfigQueue(2, gauge)
+
+	assert.NoError(t, q.enqueue())
+	q.setInflight(true)
+	q.dequeue()
+	assert.Equal(t, 1, q.size())
+
+	// the config block in flight counts towards the limit
+	assert.NoError(t, q.enqueue())
+	assert.Equal(t, 2, q.size())
+	err := q.enqueue()
+	assert.Equal(t, &ConfigPendingError{Pending: 2, Limit: 2}, err)
+	assert.EqualError(t, err, "2 config updates are pending, which reaches the maximum of 2, retry once they are committed")
+
+	q.setInflight(false)
+	assert.NoError(t, q.enqueue())
+	q.dequeue()
+	q.dequeue()
+	q.dequeue() // dequeueing an empty queue has no effect
+	assert.Equal(t, 0, q.size())
+	assert.Equal(t, float64(0), gauge.SetArgsForCall(gauge.SetCallCount()-1))
+
+	// config updates are not limited if the limit is not set
+	unlimited := newConfigQueue(0, &metricsfakes.Gauge{})
+	unlimited.setInflight(true)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, unlimited.enqueue())
+	}
+	assert.Equal(t, 11, unlimited.size())
+
+	// a nil queue counts nothing
+	var untracked *configQueue
+	assert.NoError(t, untracked.enqueue())
+	untracked.setInflight(true)
+	untracked.dequeue()
+	assert.Equal(t, 0, untracked.size())
+}
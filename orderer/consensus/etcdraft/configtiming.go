@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"time"
+)
+
+// Phases of the application of a config block, by which
+// the config_apply_duration metric is labeled.
+const (
+	ConfigPhaseDetectConfChange  = "detect_conf_change"
+	ConfigPhaseWriteBlock        = "write_block"
+	ConfigPhaseConfigureComm     = "configure_comm"
+	ConfigPhaseProposeConfChange = "propose_conf_change"
+	ConfigPhaseTotal             = "total"
+)
+
+// DefaultSlowConfigThreshold is the time the application of a config block
+// may take before it is logged as slow, if SlowConfigThreshold is not set.
+const DefaultSlowConfigThreshold = time.Second
+
+// configTimer times the phases of the application of a config block, while which
+// serveRequest does not order transactions. The durations of the phases are observed
+// by the config_apply_duration metric, and logged once they add up to more than the
+// slow config threshold.
+type configTimer struct {
+	chain *Chain
+	block uint64
+	start time.Time
+	last  time.Time
+
+	phases []interface{} // names and durations of the phases timed so far, for logging
+}
+
+func (c *Chain) newConfigTimer(block uint64) *configTimer {
+	now := c.clock.Now()
+	return &configTimer{chain: c, block: block, start: now, last: now}
+}
+
+// phase records the time since the previous phase, or the start, as the duration of the given phase.
+func (t *configTimer) phase(name string) {
+	now := t.chain.clock.Now()
+	d := now.Sub(t.last)
+	t.last = now
+	t.chain.Metrics.ConfigApplyDuration.With("phase", name).Observe(d.Seconds())
+	t.phases = append(t.phases, name, d)
+}
+
+// done records the total duration of the application of the config block,
+// and logs it along with its phases if it was slow.
+func (t *configTimer) done() {
+	total := t.chain.clock.Since(t.start)
+	t.chain.Metrics.ConfigApplyDuration.With("phase", ConfigPhaseTotal).Observe(total.Seconds())
+	if total <= t.chain.slowConfigThreshold() {
+		return
+	}
+	t.chain.logger.Warnw("Slow config block application, transactions are not ordered meanwhile",
+		append([]interface{}{"block", t.block, ConfigPhaseTotal, total}, t.phases...)...)
+}
+
+// timeConfigPhase observes the duration of a phase of the application of a config
+// block which started at the given time, and is not timed by a configTimer as it runs
+// on its own, and logs it along with the given key-value pairs if it was slow.
+func (c *Chain) timeConfigPhase(name string, start time.Time, keysAndValues ...interface{}) {
+	d := c.clock.Since(start)
+	c.Metrics.ConfigApplyDuration.With("phase", name).Observe(d.Seconds())
+	if d <= c.slowConfigThreshold() {
+		return
+	}
+	c.logger.Warnw("Slow config block application", append(keysAndValues, name, d)...)
+}
+
+func (c *Chain) slowConfigThreshold() time.Duration {
+	if c.opts.SlowConfigThreshold == 0 {
+		return DefaultSlowConfigThreshold
+	}
+	return c.opts.SlowConfigThreshold
+}
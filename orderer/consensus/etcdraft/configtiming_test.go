@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestConfigTimer(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	duration := &metricsfakes.Histogram{}
+	duration.WithReturns(duration)
+	var warnings []string
+	logger := flogging.MustGetLogger("test").WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
+		if entry.Level == zapcore.WarnLevel {
+			warnings = append(warnings, entry.Message)
+		}
+		return nil
+	}))
+	c := &Chain{
+		clock:   clock,
+		logger:  logger,
+		Metrics: &Metrics{ConfigApplyDuration: duration},
+		opts:    Options{SlowConfigThreshold: time.Second},
+	}
+
+	// observations returns the phases and durations observed since the given call
+	observations := func(since int) map[string]float64 {
+		observed := map[string]float64{}
+		for i := since; i < duration.ObserveCallCount(); i++ {
+			observed[duration.WithArgsForCall(i)[1]] = duration.ObserveArgsForCall(i)
+		}
+		return observed
+	}
+
+	timer := c.newConfigTimer(5)
+	clock.Increment(100 * time.Millisecond)
+	timer.phase(ConfigPhaseDetectConfChange)
+	clock.Increment(200 * time.Millisecond)
+	timer.phase(ConfigPhaseWriteBlock)
+	timer.done()
+	assert.Equal(t, map[string]float64{
+		ConfigPhaseDetectConfChange: 0.1,
+		ConfigPhaseWriteBlock:       0.2,
+		ConfigPhaseTotal:            0.3,
+	}, observations(0))
+	assert.Empty(t, warnings)
+
+	// a slow application is logged
+	timer = c.newConfigTimer(6)
+	timer.phase(ConfigPhaseDetectConfChange)
+	clock.Increment(2 * time.Second)
+	timer.phase(ConfigPhaseConfigureComm)
+	timer.done()
+	assert.Equal(t, map[string]float64{
+		ConfigPhaseDetectConfChange: 0,
+		ConfigPhaseConfigureComm:    2,
+		ConfigPhaseTotal:            2,
+	}, observations(3))
+	assert.Equal(t, []string{"Slow config block application, transactions are not ordered meanwhile"}, warnings)
+
+	// as are slow phases timed on their own
+	start := clock.Now()
+	clock.Increment(time.Second)
+	c.timeConfigPhase(ConfigPhaseProposeConfChange, start, "block", 6)
+	assert.Len(t, warnings, 1)
+	clock.Increment(500 * time.Millisecond)
+	c.timeConfigPhase(ConfigPhaseProposeConfChange, start, "block", 6)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, map[string]float64{ConfigPhaseProposeConfChange: 1.5}, observations(7))
+
+	c.opts.SlowConfigThreshold = 0
+	assert.Equal(t, DefaultSlowConfigThreshold, c.slowConfigThreshold())
+}
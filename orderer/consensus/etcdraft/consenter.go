@@ -9,6 +9,9 @@ package etcdraft
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
 	"path"
 	"reflect"
 	"time"
@@ -41,6 +44,9 @@ type InactiveChainRegistry interface {
 	// TrackChain tracks a chain with the given name, and calls the given callback
 	// when this chain should be created.
 	TrackChain(chainName string, genesisBlock *common.Block, createChain CreateChainCallback)
+
+	// UntrackChain stops tracking the chain with the given name.
+	UntrackChain(chainName string)
 }
 
 //go:generate mockery -dir . -name ChainGetter -case underscore -output mocks
@@ -51,18 +57,74 @@ type ChainGetter interface {
 	// Returns nil, false when the ChainSupport for the given channel
 	// isn't found.
 	GetChain(chainID string) *multichannel.ChainSupport
+
+	// ChainIDs returns the IDs of all channels served by this orderer.
+	ChainIDs() []string
 }
 
 // Config contains etcdraft configurations
 type Config struct {
-	WALDir            string // WAL data of <my-channel> is stored in WALDir/<my-channel>
-	SnapDir           string // Snapshots of <my-channel> are stored in SnapDir/<my-channel>
-	EvictionSuspicion string // Duration threshold that the node samples in order to suspect its eviction from the channel.
+	WALDir                     string   // WAL data of <my-channel> is stored in WALDir/<my-channel>
+	SnapDir                    string   // Snapshots of <my-channel> are stored in SnapDir/<my-channel>
+	StagingDir                 string   // Blocks of <my-channel> are staged in StagingDir/<my-channel> instead of the WAL, if set.
+	ArchiveDir                 string   // Archives of the blocks of <my-channel> are kept in ArchiveDir/<my-channel>, if set.
+	DRDir                      string   // Blocks and snapshots of <my-channel> streamed to or by this node are kept in DRDir/<host>:<port>/<my-channel>, if set.
+	DRPort                     int      // Port of the operations service of the DR consenters, which blocks and snapshots are sent to instead of DRDir, if set.
+	EvictionSuspicion          string   // Duration threshold that the node samples in order to suspect its eviction from the channel.
+	Webhooks                   []string // URLs notified of leader changes, membership changes and eviction, on every channel.
+	WebhookTimeout             string   // Duration a webhook has to respond to a notification.
+	WALReadAhead               string   // Way the WAL is read ahead when it is replayed: buffered (default), mmap or none.
+	WALSync                    string   // Way the WAL of every channel is synced: fdatasync (default) or fsync.
+	TrustAuditFile             string   // File recording the remote nodes trusted on every channel whenever they change.
+	BlockVerificationInterval  string   // Interval at which a random block is compared with the blocks of the other consenters.
+	MaxBlockInterval           string   // Longest time a leader goes without cutting a block, after which it cuts an empty block.
+	MaxCommitBacklog           uint64   // Number of raft entries committed but not written to the ledger, at which transactions are rejected.
+	MaxInflightBytes           uint64   // Size of the blocks created by the leader and not yet committed, at which transactions are rejected.
+	MaxPendingConfigs          int      // Number of config updates pending on a channel, at which further config updates are rejected.
+	MaxReplayEntries           uint64   // Number of entries committed to the WAL but not to the ledger written at a time when a channel starts.
+	MaxTicksPerSecond          float64  // Raft ticks processed per second by each channel, beyond which ticks are skipped.
+	MaxPersistedBytesPerSecond uint64   // Bytes of raft entries written to the WAL per second by each channel.
+	MaxAppliedBlocksPerSecond  float64  // Blocks written to the ledger per second by each channel.
+	ReceiptStream              bool     // Whether receipts of ordered transactions are streamed to clients of each channel.
+	EventStream                bool     // Whether consensus events are streamed to clients of each channel over gRPC.
+	RecentBlocks               int      // Number of the most recently committed blocks of each channel kept in memory for deliver and replication.
+	RejectSystemChannelTxs     bool     // Whether normal transactions submitted to the system channel are rejected.
+	ElectionStormThreshold     int      // Number of elections within the ElectionStormWindow at which elections are dampened.
+	ElectionStormWindow        string   // Window elections are counted in, and cool-down period of dampened elections.
+	WatchdogTimeout            string   // Time a channel may go without processing any event before it is reported as wedged.
+	InMemoryStorage            bool     // Whether raft data is kept in memory instead of WALDir and SnapDir, and lost on restart. Development only.
+	SnapshotRetention          int      // Number of snapshots retained in SnapDir of each channel, older snapshots and WAL files are deleted.
+	SnapshotContent            string   // What snapshots hold of the block they are taken at: block (default) or header.
+	RaftMemoryLimit            uint64   // Bytes of raft entries held in memory by each channel, beyond which older entries are read from the WAL.
+	ConfChangeTimeout          string   // Time a ConfChange may be in flight before it is reported as stalled.
+	ConfChangeRetryInterval    string   // Time a ConfChange proposed by the leader may go unapplied before it is proposed again.
+	ConfChangeMaxAttempts      int      // Number of times a ConfChange is proposed by the leader before its failure is reported.
+	SnapshotDeferralLatency    string   // Moving average of the time taken to persist raft data above which snapshots are deferred.
+	MaxSnapshotDeferral        string   // Longest time a snapshot is deferred while persisting raft data is slow.
+	SlowConfigThreshold        string   // Time the application of a config block may take before it is logged as slow.
+	DegradedLatency            string   // Latency of persisting raft data or acknowledging appends above which a node is classified as degraded.
+	MaxBlockTimeSkew           string   // How far ahead of the local clock the timestamp of a block may be before it is reported as a violation.
+	ReorderWindow              string   // Time the leader holds a redirected transaction for the one forwarded before it by the same node.
+	FlightRecorderSize         int      // Number of the most recent raft messages of each channel recorded for postmortems.
+	MaxConsensusMessageBytes   uint64   // Size of the raft messages received from other consenters beyond which they are dropped.
+	FairOrdering               bool     // Whether transactions are ordered by weighted round-robin across the consenters they are submitted from.
+	IngressShares              []IngressShare
+}
+
+// IngressShare is the share of a consenter in the transactions
+// ordered by weighted round-robin, if FairOrdering is set.
+type IngressShare struct {
+	Endpoint string // host:port of the consenter
+	Share    int
 }
 
 // Consenter implements etddraft consenter
 type Consenter struct {
-	CreateChain           func(chainName string)
+	CreateChain func(chainName string)
+	// JoinChain creates the chain of a channel out of a config block of it.
+	JoinChain func(configBlock *common.Block) error
+	// RemoveChain halts the chain of a channel and removes its ledger.
+	RemoveChain           func(chainName string) error
 	InactiveChainRegistry InactiveChainRegistry
 	Dialer                *cluster.PredicateDialer
 	Communication         cluster.Communicator
@@ -73,6 +135,21 @@ type Consenter struct {
 	OrdererConfig  localconfig.TopLevel
 	Cert           []byte
 	Metrics        *Metrics
+	ArchiveFetcher ArchiveFetcher
+	// ArchiveReference returns a reference to an archive holding the blocks
+	// of the channel up to the given block, embedded into snapshots, if set
+	ArchiveReference func(channel string, blockNum uint64) *etcdraft.ArchiveReference
+	Notifier         Notifier
+	// DRSink streams the channels to their DR consenters, if set
+	DRSink DRSink
+	// DRStore stores the channels this node is a DR consenter of, as they
+	// are streamed to it by HTTPDRSink, if set
+	DRStore *DirDRSink
+	// ConnectionPool shares the connections of the block pullers of all chains
+	ConnectionPool *cluster.ConnectionPool
+	TrustAuditLog  *TrustAuditLog
+	// OnBlockCommitted, if set, is called with the blocks committed on every channel.
+	OnBlockCommitted func(channel string, block *common.Block)
 }
 
 // TargetChannel extracts the channel from the given proto.Message.
@@ -105,6 +182,44 @@ func (c *Consenter) ReceiverByChain(channelID string) MessageReceiver {
 	return nil
 }
 
+// ChainsInfo returns information about every etcdraft chain this node
+// participates in, ordered by channel ID.
+func (c *Consenter) ChainsInfo() []ChainInfo {
+	var infos []ChainInfo
+	for _, channelID := range c.Chains.ChainIDs() {
+		cs := c.Chains.GetChain(channelID)
+		if cs == nil {
+			continue
+		}
+		if etcdRaftChain, isEtcdRaftChain := cs.Chain.(*Chain); isEtcdRaftChain {
+			infos = append(infos, etcdRaftChain.Info())
+		}
+	}
+	return infos
+}
+
+// ServeHTTP serves information about the etcdraft chains of this node
+// as a JSON array.
+func (c *Consenter) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		resp.Header().Set("Content-Type", "application/json")
+		resp.WriteHeader(http.StatusMethodNotAllowed)
+		json.NewEncoder(resp).Encode(map[string]string{"error": fmt.Sprintf("invalid request method: %s", req.Method)})
+		return
+	}
+
+	infos := c.ChainsInfo()
+	if infos == nil {
+		infos = []ChainInfo{}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(infos); err != nil {
+		c.Logger.Errorw("failed to encode chains info", "error", err)
+	}
+}
+
 func (c *Consenter) detectSelfID(consenters map[uint64]*etcdraft.Consenter) (uint64, error) {
 	var serverCertificates []string
 	for nodeID, cst := range consenters {
@@ -158,6 +273,11 @@ func (c *Consenter) HandleChain(support consensus.ConsenterSupport, metadata *co
 
 	id, err := c.detectSelfID(blockMetadata.Consenters)
 	if err != nil {
+		// A ledger seeded from a checkpoint has no genesis block to track the chain with.
+		if support.Block(0) == nil {
+			c.Logger.Warningf("Channel %s is not serviced by me, and its ledger has no genesis block to track it with", support.ChainID())
+			return &inactive.Chain{Err: errors.Errorf("channel %s is not serviced by me", support.ChainID())}, nil
+		}
 		c.InactiveChainRegistry.TrackChain(support.ChainID(), support.Block(0), func() {
 			c.CreateChain(support.ChainID())
 		})
@@ -175,31 +295,258 @@ func (c *Consenter) HandleChain(support consensus.ConsenterSupport, metadata *co
 		}
 	}
 
+	for _, url := range c.EtcdRaftConfig.Webhooks {
+		if err := ValidateWebhookURL(url); err != nil {
+			c.Logger.Panicf("Invalid Consensus.Webhooks: %s", err)
+		}
+	}
+
+	var maxBlockTimeSkew time.Duration
+	if c.EtcdRaftConfig.MaxBlockTimeSkew == "" {
+		maxBlockTimeSkew = DefaultMaxBlockTimeSkew
+	} else {
+		maxBlockTimeSkew, err = time.ParseDuration(c.EtcdRaftConfig.MaxBlockTimeSkew)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.MaxBlockTimeSkew: %s: %v", c.EtcdRaftConfig.MaxBlockTimeSkew, err)
+		}
+	}
+
+	var reorderWindow time.Duration
+	if c.EtcdRaftConfig.ReorderWindow == "" {
+		reorderWindow = DefaultReorderWindow
+	} else {
+		reorderWindow, err = time.ParseDuration(c.EtcdRaftConfig.ReorderWindow)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.ReorderWindow: %s: %v", c.EtcdRaftConfig.ReorderWindow, err)
+		}
+	}
+
+	maxCommitBacklog := c.EtcdRaftConfig.MaxCommitBacklog
+	if maxCommitBacklog == 0 {
+		c.Logger.Infof("MaxCommitBacklog not set, defaulting to %d", DefaultMaxCommitBacklog)
+		maxCommitBacklog = DefaultMaxCommitBacklog
+	}
+
+	maxReplayEntries := c.EtcdRaftConfig.MaxReplayEntries
+	if maxReplayEntries == 0 {
+		maxReplayEntries = DefaultMaxReplayEntries
+	}
+
+	if c.EtcdRaftConfig.MaxTicksPerSecond < 0 {
+		c.Logger.Panicf("Consensus.MaxTicksPerSecond must not be negative: %v", c.EtcdRaftConfig.MaxTicksPerSecond)
+	}
+	if c.EtcdRaftConfig.MaxAppliedBlocksPerSecond < 0 {
+		c.Logger.Panicf("Consensus.MaxAppliedBlocksPerSecond must not be negative: %v", c.EtcdRaftConfig.MaxAppliedBlocksPerSecond)
+	}
+	ingressShares := make(map[string]int, len(c.EtcdRaftConfig.IngressShares))
+	for _, is := range c.EtcdRaftConfig.IngressShares {
+		if is.Share <= 0 {
+			c.Logger.Panicf("Consensus.IngressShares: share of %s must be positive: %d", is.Endpoint, is.Share)
+		}
+		ingressShares[is.Endpoint] = is.Share
+	}
+	if c.EtcdRaftConfig.SnapshotRetention < 0 {
+		c.Logger.Panicf("Consensus.SnapshotRetention must not be negative: %v", c.EtcdRaftConfig.SnapshotRetention)
+	}
+	quotas := Quotas{
+		TicksPerSecond:          c.EtcdRaftConfig.MaxTicksPerSecond,
+		PersistedBytesPerSecond: c.EtcdRaftConfig.MaxPersistedBytesPerSecond,
+		AppliedBlocksPerSecond:  c.EtcdRaftConfig.MaxAppliedBlocksPerSecond,
+	}
+
+	walReadAhead, err := ParseWALReadAhead(c.EtcdRaftConfig.WALReadAhead)
+	if err != nil {
+		c.Logger.Panicf("Failed parsing Consensus.WALReadAhead: %s", err)
+	}
+
+	walSync, err := ParseWALSync(c.EtcdRaftConfig.WALSync)
+	if err != nil {
+		c.Logger.Panicf("Failed parsing Consensus.WALSync: %s", err)
+	}
+
+	snapshotContent, err := ParseSnapshotContent(c.EtcdRaftConfig.SnapshotContent)
+	if err != nil {
+		c.Logger.Panicf("Failed parsing Consensus.SnapshotContent: %s", err)
+	}
+
+	var blockVerificationInterval time.Duration
+	if c.EtcdRaftConfig.BlockVerificationInterval != "" {
+		blockVerificationInterval, err = time.ParseDuration(c.EtcdRaftConfig.BlockVerificationInterval)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.BlockVerificationInterval: %s: %v", c.EtcdRaftConfig.BlockVerificationInterval, err)
+		}
+	}
+
+	var maxBlockInterval time.Duration
+	if c.EtcdRaftConfig.MaxBlockInterval != "" {
+		maxBlockInterval, err = time.ParseDuration(c.EtcdRaftConfig.MaxBlockInterval)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.MaxBlockInterval: %s: %v", c.EtcdRaftConfig.MaxBlockInterval, err)
+		}
+	}
+
+	var electionStormWindow time.Duration
+	if c.EtcdRaftConfig.ElectionStormWindow != "" {
+		electionStormWindow, err = time.ParseDuration(c.EtcdRaftConfig.ElectionStormWindow)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.ElectionStormWindow: %s: %v", c.EtcdRaftConfig.ElectionStormWindow, err)
+		}
+	}
+
+	var watchdogTimeout time.Duration
+	if c.EtcdRaftConfig.WatchdogTimeout != "" {
+		watchdogTimeout, err = time.ParseDuration(c.EtcdRaftConfig.WatchdogTimeout)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.WatchdogTimeout: %s: %v", c.EtcdRaftConfig.WatchdogTimeout, err)
+		}
+	}
+
+	var confChangeTimeout time.Duration
+	if c.EtcdRaftConfig.ConfChangeTimeout != "" {
+		confChangeTimeout, err = time.ParseDuration(c.EtcdRaftConfig.ConfChangeTimeout)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.ConfChangeTimeout: %s: %v", c.EtcdRaftConfig.ConfChangeTimeout, err)
+		}
+	}
+
+	var confChangeRetryInterval time.Duration
+	if c.EtcdRaftConfig.ConfChangeRetryInterval != "" {
+		confChangeRetryInterval, err = time.ParseDuration(c.EtcdRaftConfig.ConfChangeRetryInterval)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.ConfChangeRetryInterval: %s: %v", c.EtcdRaftConfig.ConfChangeRetryInterval, err)
+		}
+	}
+
+	var snapshotDeferralLatency time.Duration
+	if c.EtcdRaftConfig.SnapshotDeferralLatency != "" {
+		snapshotDeferralLatency, err = time.ParseDuration(c.EtcdRaftConfig.SnapshotDeferralLatency)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.SnapshotDeferralLatency: %s: %v", c.EtcdRaftConfig.SnapshotDeferralLatency, err)
+		}
+	}
+
+	var maxSnapshotDeferral time.Duration
+	if c.EtcdRaftConfig.MaxSnapshotDeferral != "" {
+		maxSnapshotDeferral, err = time.ParseDuration(c.EtcdRaftConfig.MaxSnapshotDeferral)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.MaxSnapshotDeferral: %s: %v", c.EtcdRaftConfig.MaxSnapshotDeferral, err)
+		}
+	}
+
+	var slowConfigThreshold time.Duration
+	if c.EtcdRaftConfig.SlowConfigThreshold != "" {
+		slowConfigThreshold, err = time.ParseDuration(c.EtcdRaftConfig.SlowConfigThreshold)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.SlowConfigThreshold: %s: %v", c.EtcdRaftConfig.SlowConfigThreshold, err)
+		}
+	}
+
+	var degradedLatency time.Duration
+	if c.EtcdRaftConfig.DegradedLatency != "" {
+		degradedLatency, err = time.ParseDuration(c.EtcdRaftConfig.DegradedLatency)
+		if err != nil {
+			c.Logger.Panicf("Failed parsing Consensus.DegradedLatency: %s: %v", c.EtcdRaftConfig.DegradedLatency, err)
+		}
+	}
+
+	var stagingDir string
+	if c.EtcdRaftConfig.StagingDir != "" {
+		stagingDir = path.Join(c.EtcdRaftConfig.StagingDir, support.ChainID())
+	}
+
 	tickInterval, err := time.ParseDuration(m.Options.TickInterval)
 	if err != nil {
 		return nil, errors.Errorf("failed to parse TickInterval (%s) to time duration", m.Options.TickInterval)
 	}
 
+	tlsPolicy, err := cluster.NewTLSPolicy(m.Options.TlsMinVersion, m.Options.TlsCipherSuites)
+	if err != nil {
+		return nil, errors.WithMessage(err, "invalid TLS policy")
+	}
+
 	opts := Options{
 		RaftID:        id,
 		Clock:         clock.NewClock(),
 		MemoryStorage: raft.NewMemoryStorage(),
 		Logger:        c.Logger,
 
-		TickInterval:    tickInterval,
-		ElectionTick:    int(m.Options.ElectionTick),
-		HeartbeatTick:   int(m.Options.HeartbeatTick),
-		MaxInflightMsgs: int(m.Options.MaxInflightMsgs),
-		MaxSizePerMsg:   m.Options.MaxSizePerMsg,
-		SnapInterval:    m.Options.SnapshotInterval,
+		TickInterval:      tickInterval,
+		ElectionTick:      int(m.Options.ElectionTick),
+		HeartbeatTick:     int(m.Options.HeartbeatTick),
+		MaxInflightMsgs:   int(m.Options.MaxInflightMsgs),
+		MaxSizePerMsg:     m.Options.MaxSizePerMsg,
+		SnapInterval:      m.Options.SnapshotInterval,
+		SnapshotRetention: c.EtcdRaftConfig.SnapshotRetention,
+		SnapshotContent:   snapshotContent,
+		StateHash:         m.Options.StateHash,
+		BlockProvenance:   m.Options.BlockProvenance,
+		BlockTimestamp:    m.Options.BlockTimestamp,
+		MaxEnvelopeBytes:  m.Options.MaxEnvelopeBytes,
+
+		ProposalForwarding: m.Options.ProposalForwarding,
+		DisablePreVote:     m.Options.DisablePreVote,
+		DisableCheckQuorum: m.Options.DisableCheckQuorum,
+		TLSPolicy:          tlsPolicy,
 
 		BlockMetadata: blockMetadata,
 
-		WALDir:            path.Join(c.EtcdRaftConfig.WALDir, support.ChainID()),
-		SnapDir:           path.Join(c.EtcdRaftConfig.SnapDir, support.ChainID()),
+		WALReadAhead:      walReadAhead,
+		WALSync:           walSync,
+		StagingDir:        stagingDir,
 		EvictionSuspicion: evictionSuspicion,
 		Cert:              c.Cert,
 		Metrics:           c.Metrics,
+		ArchiveFetcher:    c.ArchiveFetcher,
+		DRSink:            c.DRSink,
+		DRConsenters:      m.DrConsenters,
+		Notifier:          c.Notifier,
+		TrustAuditLog:     c.TrustAuditLog,
+		OnBlockCommitted:  c.OnBlockCommitted,
+		Features:          SupportedFeatures(),
+
+		BlockVerificationInterval: blockVerificationInterval,
+		MaxBlockInterval:          maxBlockInterval,
+		MaxCommitBacklog:          maxCommitBacklog,
+		MaxInflightBytes:          c.EtcdRaftConfig.MaxInflightBytes,
+		MaxPendingConfigs:         c.EtcdRaftConfig.MaxPendingConfigs,
+		MaxReplayEntries:          maxReplayEntries,
+		Quotas:                    quotas,
+		ReceiptStream:             c.EtcdRaftConfig.ReceiptStream,
+		EventStream:               c.EtcdRaftConfig.EventStream,
+		RecentBlocks:              c.EtcdRaftConfig.RecentBlocks,
+		RejectSystemChannelTxs:    c.EtcdRaftConfig.RejectSystemChannelTxs,
+		FairOrdering:              c.EtcdRaftConfig.FairOrdering,
+		IngressShares:             ingressShares,
+		ElectionStormThreshold:    c.EtcdRaftConfig.ElectionStormThreshold,
+		ElectionStormWindow:       electionStormWindow,
+		WatchdogTimeout:           watchdogTimeout,
+		ConfChangeTimeout:         confChangeTimeout,
+		ConfChangeRetryInterval:   confChangeRetryInterval,
+		ConfChangeMaxAttempts:     c.EtcdRaftConfig.ConfChangeMaxAttempts,
+		SnapshotDeferralLatency:   snapshotDeferralLatency,
+		MaxSnapshotDeferral:       maxSnapshotDeferral,
+		SlowConfigThreshold:       slowConfigThreshold,
+		DegradedLatency:           degradedLatency,
+		MaxBlockTimeSkew:          maxBlockTimeSkew,
+		ReorderWindow:             reorderWindow,
+		FlightRecorderSize:        c.EtcdRaftConfig.FlightRecorderSize,
+		MaxConsensusMessageBytes:  c.EtcdRaftConfig.MaxConsensusMessageBytes,
+	}
+	if c.ArchiveReference != nil {
+		opts.ArchiveReference = func(blockNum uint64) *etcdraft.ArchiveReference {
+			return c.ArchiveReference(support.ChainID(), blockNum)
+		}
+	}
+	if c.EtcdRaftConfig.InMemoryStorage {
+		opts.InMemoryStorage = true
+		opts.StagingDir = ""
+	} else {
+		opts.WALDir = path.Join(c.EtcdRaftConfig.WALDir, support.ChainID())
+		opts.SnapDir = path.Join(c.EtcdRaftConfig.SnapDir, support.ChainID())
+		opts.CommitMarkerPath = path.Join(c.EtcdRaftConfig.SnapDir, support.ChainID(), CommitMarkerFile)
+		if c.EtcdRaftConfig.RaftMemoryLimit > 0 {
+			opts.MemoryStorage = NewSpillingMemoryStorage(c.EtcdRaftConfig.RaftMemoryLimit)
+		}
 	}
 
 	rpc := &cluster.RPC{
@@ -209,12 +556,17 @@ func (c *Consenter) HandleChain(support consensus.ConsenterSupport, metadata *co
 		Comm:          c.Communication,
 		StreamsByType: cluster.NewStreamsByType(),
 	}
+	if comm, isComm := c.Communication.(*cluster.Comm); isComm {
+		rpc.Metrics = comm.Metrics
+	}
 	return NewChain(
 		support,
 		opts,
 		c.Communication,
 		rpc,
-		func() (BlockPuller, error) { return newBlockPuller(support, c.Dialer, c.OrdererConfig.General.Cluster) },
+		func() (BlockPuller, error) {
+			return newBlockPuller(support, c.Dialer, c.ConnectionPool, c.OrdererConfig.General.Cluster)
+		},
 		nil,
 	)
 }
@@ -261,6 +613,8 @@ func New(
 
 	consenter := &Consenter{
 		CreateChain:           r.CreateChain,
+		JoinChain:             r.JoinChain,
+		RemoveChain:           r.RemoveChain,
 		Cert:                  srvConf.SecOpts.Certificate,
 		Logger:                logger,
 		Chains:                r,
@@ -269,14 +623,50 @@ func New(
 		Dialer:                clusterDialer,
 		Metrics:               NewMetrics(metricsProvider),
 		InactiveChainRegistry: icr,
+		ConnectionPool:        cluster.NewConnectionPool(),
+	}
+	if len(cfg.Webhooks) > 0 {
+		webhookTimeout := DefaultWebhookTimeout
+		if cfg.WebhookTimeout != "" {
+			var err error
+			webhookTimeout, err = time.ParseDuration(cfg.WebhookTimeout)
+			if err != nil {
+				logger.Panicf("Failed parsing Consensus.WebhookTimeout: %s: %v", cfg.WebhookTimeout, err)
+			}
+		}
+		consenter.Notifier = NewWebhookNotifier(cfg.Webhooks, webhookTimeout, logger)
+	}
+	if cfg.ArchiveDir != "" {
+		archive := &DirArchive{Dir: cfg.ArchiveDir, Logger: logger}
+		consenter.ArchiveFetcher = archive
+		consenter.ArchiveReference = archive.Reference
+	}
+	if cfg.DRDir != "" {
+		consenter.DRStore = &DirDRSink{Dir: cfg.DRDir}
+		consenter.DRSink = consenter.DRStore
+	}
+	if cfg.DRPort != 0 {
+		consenter.DRSink = NewHTTPDRSink(cfg.DRPort, conf.General.Cluster.RPCTimeout, clusterDialer.ClientConfig)
+	}
+	if cfg.InMemoryStorage {
+		logger.Warnf("Consensus.InMemoryStorage is set, raft data of all channels is kept in memory only and is lost on restart. " +
+			"This is meant for development and testing, and MUST NOT be used in production")
 	}
+	if cfg.TrustAuditFile != "" {
+		consenter.TrustAuditLog = &TrustAuditLog{Path: cfg.TrustAuditFile}
+	}
+
 	consenter.Dispatcher = &Dispatcher{
 		Logger:        logger,
 		ChainSelector: consenter,
 	}
 
 	comm := createComm(clusterDialer, consenter, conf.General.Cluster.SendBufferSize, metricsProvider)
+	comm.ResolutionTTL = conf.General.Cluster.ResolutionTTL
 	consenter.Communication = comm
+	if len(conf.General.Cluster.RevocationLists) > 0 {
+		comm.Revocation = newRevocationChecker(conf.General.Cluster, comm)
+	}
 	svc := &cluster.Service{
 		StreamCountReporter: &cluster.StreamCountReporter{
 			Metrics: comm.Metrics,
@@ -289,6 +679,22 @@ func New(
 	return consenter
 }
 
+// newRevocationChecker loads the certificate revocation lists of the cluster
+// configuration, and reloads them periodically, disconnecting from the
+// remote nodes of comm whose certificates become revoked.
+func newRevocationChecker(conf localconfig.Cluster, comm *cluster.Comm) *cluster.CRLChecker {
+	checker := &cluster.CRLChecker{
+		Logger:   flogging.MustGetLogger("orderer.common.cluster"),
+		Paths:    conf.RevocationLists,
+		OnUpdate: comm.DisconnectRevoked,
+	}
+	if err := checker.Load(); err != nil {
+		checker.Logger.Panicf("Failed to load certificate revocation lists: %s", err)
+	}
+	go checker.Run(conf.RevocationRefreshInterval, nil)
+	return checker
+}
+
 func createComm(clusterDialer *cluster.PredicateDialer, c *Consenter, sendBuffSize int, p metrics.Provider) *cluster.Comm {
 	metrics := cluster.NewMetrics(p)
 	comm := &cluster.Comm{
@@ -7,7 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package etcdraft_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"strings"
@@ -183,6 +186,207 @@ var _ = Describe("Consenter", func() {
 		Expect(defaultSuspicionFallback).To(BeTrue())
 	})
 
+	It("lists the etcdraft chains it participates in", func() {
+		certBytes := []byte("cert.orderer0.org0")
+		m := &etcdraftproto.ConfigMetadata{
+			Consenters: []*etcdraftproto.Consenter{
+				{ServerTlsCert: certBytes},
+			},
+			Options: &etcdraftproto.Options{
+				TickInterval:    "500ms",
+				ElectionTick:    10,
+				HeartbeatTick:   1,
+				MaxInflightMsgs: 256,
+				MaxSizePerMsg:   1048576,
+			},
+		}
+		support.ChainIDReturns("mychannel")
+		support.HeightReturns(2)
+		support.SharedConfigReturns(&mockconfig.Orderer{
+			ConsensusMetadataVal: utils.MarshalOrPanic(m),
+			CapabilitiesVal: &mockconfig.OrdererCapabilities{
+				Kafka2RaftMigVal: false,
+			},
+		})
+
+		consenter := newConsenter(chainGetter)
+		consenter.EtcdRaftConfig.WALDir = walDir
+		consenter.EtcdRaftConfig.SnapDir = snapDir
+		consenter.Metrics = newFakeMetrics(newFakeMetricsFields())
+
+		chain, err := consenter.HandleChain(support, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		chainGetter.On("ChainIDs").Return([]string{"mychannel", "notraftchain"})
+		chainGetter.On("GetChain", "mychannel").Return(&multichannel.ChainSupport{Chain: chain})
+		chainGetter.On("GetChain", "notraftchain").Return(&multichannel.ChainSupport{
+			Chain: &multichannel.ChainSupport{},
+		})
+
+		infos := consenter.ChainsInfo()
+		Expect(infos).To(HaveLen(1))
+		Expect(infos[0].Channel).To(Equal("mychannel"))
+		Expect(infos[0].RaftID).To(Equal(uint64(1)))
+		Expect(infos[0].Role).To(Equal("stopped"))
+		Expect(infos[0].Height).To(Equal(uint64(2)))
+
+		chain.Start()
+		defer chain.Halt()
+
+		Expect(consenter.ChainsInfo()[0].Role).To(Or(Equal("follower"), Equal("candidate"), Equal("leader")))
+
+		resp := httptest.NewRecorder()
+		consenter.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/chains", nil))
+		Expect(resp.Code).To(Equal(http.StatusOK))
+		var served []etcdraft.ChainInfo
+		Expect(json.Unmarshal(resp.Body.Bytes(), &served)).To(Succeed())
+		Expect(served).To(HaveLen(1))
+		Expect(served[0].Channel).To(Equal("mychannel"))
+
+		resp = httptest.NewRecorder()
+		consenter.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/etcdraft/chains", nil))
+		Expect(resp.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("serves the support bundle of its etcdraft chains", func() {
+		certBytes := []byte("cert.orderer0.org0")
+		m := &etcdraftproto.ConfigMetadata{
+			Consenters: []*etcdraftproto.Consenter{
+				{ServerTlsCert: certBytes},
+			},
+			Options: &etcdraftproto.Options{
+				TickInterval:    "500ms",
+				ElectionTick:    10,
+				HeartbeatTick:   1,
+				MaxInflightMsgs: 256,
+				MaxSizePerMsg:   1048576,
+			},
+		}
+		support.ChainIDReturns("mychannel")
+		support.HeightReturns(2)
+		support.SharedConfigReturns(&mockconfig.Orderer{
+			ConsensusMetadataVal: utils.MarshalOrPanic(m),
+			CapabilitiesVal: &mockconfig.OrdererCapabilities{
+				Kafka2RaftMigVal: false,
+			},
+		})
+
+		consenter := newConsenter(chainGetter)
+		consenter.EtcdRaftConfig.WALDir = walDir
+		consenter.EtcdRaftConfig.SnapDir = snapDir
+		consenter.Metrics = newFakeMetrics(newFakeMetricsFields())
+
+		chain, err := consenter.HandleChain(support, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		chainGetter.On("GetChain", "mychannel").Return(&multichannel.ChainSupport{Chain: chain})
+		chainGetter.On("GetChain", "notraftchain").Return(&multichannel.ChainSupport{
+			Chain: &multichannel.ChainSupport{},
+		})
+		chainGetter.On("GetChain", "nochannel").Return(nil)
+
+		chain.Start()
+		defer chain.Halt()
+
+		handler := consenter.SupportBundleHandler()
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/bundle?channel=mychannel", nil))
+		Expect(resp.Code).To(Equal(http.StatusOK))
+
+		var bundle etcdraft.SupportBundle
+		Expect(json.Unmarshal(resp.Body.Bytes(), &bundle)).To(Succeed())
+		Expect(bundle.Channel).To(Equal("mychannel"))
+		Expect(bundle.RaftID).To(Equal(uint64(1)))
+		Expect(bundle.Options.WALDir).To(Equal(path.Join(walDir, "mychannel")))
+		Expect(bundle.Options.TickInterval).To(Equal("500ms"))
+		Expect(bundle.ChannelOptions.MaxInflightMsgs).To(Equal(uint32(256)))
+		Expect(bundle.Metrics.Height).To(Equal(uint64(2)))
+		Expect(bundle.Status.Role).To(Or(Equal("follower"), Equal("candidate"), Equal("leader")))
+
+		blockMetadata, err := etcdraftproto.UnmarshalBlockMetadataJSON(bundle.BlockMetadata)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blockMetadata.Consenters).To(HaveKey(uint64(1)))
+
+		for query, code := range map[string]int{
+			"":                      http.StatusBadRequest,
+			"?channel=notraftchain": http.StatusNotFound,
+			"?channel=nochannel":    http.StatusNotFound,
+		} {
+			resp = httptest.NewRecorder()
+			handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/bundle"+query, nil))
+			Expect(resp.Code).To(Equal(code), query)
+		}
+
+		resp = httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/etcdraft/bundle?channel=mychannel", nil))
+		Expect(resp.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("serves the membership drift of its etcdraft chains", func() {
+		certBytes := []byte("cert.orderer0.org0")
+		m := &etcdraftproto.ConfigMetadata{
+			Consenters: []*etcdraftproto.Consenter{
+				{ServerTlsCert: certBytes},
+			},
+			Options: &etcdraftproto.Options{
+				TickInterval:    "500ms",
+				ElectionTick:    10,
+				HeartbeatTick:   1,
+				MaxInflightMsgs: 256,
+				MaxSizePerMsg:   1048576,
+			},
+		}
+		support.ChainIDReturns("mychannel")
+		support.HeightReturns(1)
+		support.SharedConfigReturns(&mockconfig.Orderer{
+			ConsensusMetadataVal: utils.MarshalOrPanic(m),
+			CapabilitiesVal: &mockconfig.OrdererCapabilities{
+				Kafka2RaftMigVal: false,
+			},
+		})
+
+		consenter := newConsenter(chainGetter)
+		consenter.EtcdRaftConfig.WALDir = walDir
+		consenter.EtcdRaftConfig.SnapDir = snapDir
+		consenter.Metrics = newFakeMetrics(newFakeMetricsFields())
+
+		chain, err := consenter.HandleChain(support, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		chainGetter.On("GetChain", "mychannel").Return(&multichannel.ChainSupport{Chain: chain})
+		chainGetter.On("GetChain", "nochannel").Return(nil)
+
+		chain.Start()
+		defer chain.Halt()
+
+		handler := consenter.MembershipHandler()
+		Eventually(func() etcdraft.MembershipDrift {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/membership?channel=mychannel", nil))
+			Expect(resp.Code).To(Equal(http.StatusOK))
+			var drift etcdraft.MembershipDrift
+			Expect(json.Unmarshal(resp.Body.Bytes(), &drift)).To(Succeed())
+			return drift
+		}).Should(Equal(etcdraft.MembershipDrift{Nodes: []uint64{1}, Consenters: []uint64{1}}))
+
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/etcdraft/membership?channel=mychannel", nil))
+		Expect(resp.Code).To(Equal(http.StatusConflict))
+
+		for query, code := range map[string]int{
+			"":                   http.StatusBadRequest,
+			"?channel=nochannel": http.StatusNotFound,
+		} {
+			resp = httptest.NewRecorder()
+			handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/membership"+query, nil))
+			Expect(resp.Code).To(Equal(code), query)
+		}
+
+		resp = httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(http.MethodDelete, "/etcdraft/membership?channel=mychannel", nil))
+		Expect(resp.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
 	It("fails to handle chain if no matching cert found", func() {
 		m := &etcdraftproto.ConfigMetadata{
 			Consenters: []*etcdraftproto.Consenter{
@@ -205,6 +409,7 @@ var _ = Describe("Consenter", func() {
 			},
 		})
 		support.ChainIDReturns("foo")
+		support.BlockReturns(&common.Block{Header: &common.BlockHeader{}})
 
 		consenter := newConsenter(chainGetter)
 
@@ -215,6 +420,36 @@ var _ = Describe("Consenter", func() {
 		consenter.icr.AssertNumberOfCalls(testingInstance, "TrackChain", 1)
 	})
 
+	It("does not track the chain if its ledger has no genesis block", func() {
+		m := &etcdraftproto.ConfigMetadata{
+			Consenters: []*etcdraftproto.Consenter{
+				{ServerTlsCert: []byte("cert.orderer1.org1")},
+			},
+			Options: &etcdraftproto.Options{
+				TickInterval:    "500ms",
+				ElectionTick:    10,
+				HeartbeatTick:   1,
+				MaxInflightMsgs: 256,
+				MaxSizePerMsg:   1048576,
+			},
+		}
+		support := &consensusmocks.FakeConsenterSupport{}
+		support.SharedConfigReturns(&mockconfig.Orderer{
+			ConsensusMetadataVal: utils.MarshalOrPanic(m),
+			CapabilitiesVal: &mockconfig.OrdererCapabilities{
+				Kafka2RaftMigVal: false,
+			},
+		})
+		support.ChainIDReturns("foo")
+
+		consenter := newConsenter(chainGetter)
+
+		chain, err := consenter.HandleChain(support, &common.Metadata{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chain.Order(nil, 0).Error()).To(Equal("channel foo is not serviced by me"))
+		consenter.icr.AssertNotCalled(testingInstance, "TrackChain", mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	It("fails to handle chain if etcdraft options have not been provided", func() {
 		m := &etcdraftproto.ConfigMetadata{
 			Consenters: []*etcdraftproto.Consenter{
@@ -264,6 +499,37 @@ var _ = Describe("Consenter", func() {
 		Expect(chain).To(BeNil())
 		Expect(err).To(MatchError("failed to parse TickInterval (500) to time duration"))
 	})
+
+	It("panics upon handling a chain if a webhook URL is invalid", func() {
+		m := &etcdraftproto.ConfigMetadata{
+			Consenters: []*etcdraftproto.Consenter{
+				{ServerTlsCert: []byte("cert.orderer0.org0")},
+			},
+			Options: &etcdraftproto.Options{
+				TickInterval:    "500ms",
+				ElectionTick:    10,
+				HeartbeatTick:   1,
+				MaxInflightMsgs: 256,
+				MaxSizePerMsg:   1048576,
+			},
+		}
+		support.SharedConfigReturns(&mockconfig.Orderer{
+			ConsensusMetadataVal: utils.MarshalOrPanic(m),
+			CapabilitiesVal:      &mockconfig.OrdererCapabilities{},
+		})
+
+		consenter := newConsenter(chainGetter)
+		consenter.EtcdRaftConfig.WALDir = walDir
+		consenter.EtcdRaftConfig.SnapDir = snapDir
+		consenter.EtcdRaftConfig.Webhooks = []string{"https://alerts.example.com", "alerts.example.com"}
+
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			consenter.HandleChain(support, nil)
+		}()
+		Expect(recovered).To(Equal("Invalid Consensus.Webhooks: webhook URL alerts.example.com must be http or https"))
+	})
 })
 
 type consenter struct {
@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"math"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Recovery suggestions reported along with an inconsistency between the WAL and the ledger.
+const (
+	replicateSuggestion     = "remove the WAL and snapshot directories of the channel, so that the node replicates the chain from the other consenters upon restart"
+	restoreLedgerSuggestion = "restore the ledger from a backup taken after the WAL, or " + replicateSuggestion
+)
+
+// checkLedgerConsistency cross-checks the blocks restored from the snapshot and the WAL
+// into the raft storage with the last block of the ledger, which was written from the raft
+// entry at appliedIndex, so that a chain whose WAL and ledger went out of sync refuses to
+// start, instead of panicking once the entries of the WAL are applied.
+//
+// Entries are persisted to the WAL before their blocks are written to the ledger, hence
+// the ledger may be behind the WAL only by entries which are yet to be applied, or by a
+// snapshot which the chain catches up with. The ledger may be ahead of the WAL only with
+// blocks replicated from the other consenters, when onboarding or upon suspecting an
+// eviction, which are skipped once their entries reach the WAL.
+func checkLedgerConsistency(lg *flogging.FabricLogger, ram MemoryStorage, lastBlock *common.Block, appliedIndex uint64) error {
+	lastIndex, err := ram.LastIndex()
+	if err != nil {
+		return errors.Wrap(err, "failed to read last index of raft storage")
+	}
+	if lastIndex == 0 {
+		// Nothing was persisted yet, e.g. by a node which joined the channel.
+		return nil
+	}
+	firstIndex, err := ram.FirstIndex()
+	if err != nil {
+		return errors.Wrap(err, "failed to read first index of raft storage")
+	}
+	snapshot, err := ram.Snapshot()
+	if err != nil {
+		return errors.Wrap(err, "failed to read snapshot from raft storage")
+	}
+
+	var ents []raftpb.Entry
+	if firstIndex <= lastIndex {
+		if ents, err = ram.Entries(firstIndex, lastIndex+1, math.MaxUint64); err != nil {
+			return errors.Wrap(err, "failed to read entries of raft storage")
+		}
+	}
+	blocks, err := walBlocks(ents)
+	if err != nil {
+		return err
+	}
+
+	lastNumber := lastBlock.Header.Number
+	if appliedIndex > lastIndex {
+		if len(blocks) > 0 && blocks[len(blocks)-1].number >= lastNumber {
+			last := blocks[len(blocks)-1]
+			return errors.Errorf("ledger and WAL diverged: block %d of the ledger was written from raft index %d, "+
+				"but the WAL already holds block %d at the preceding raft index %d; %s",
+				lastNumber, appliedIndex, last.number, last.index, replicateSuggestion)
+		}
+		lg.Infof("Ledger is ahead of the WAL, which ends at raft index %d: block %d was replicated "+
+			"from raft index %d, the entries in between are replicated from the leader", lastIndex, lastNumber, appliedIndex)
+		return nil
+	}
+
+	// The chain first catches up with a snapshot taken after the last block of the ledger.
+	resumeIndex, resumeNumber := appliedIndex, lastNumber
+	if !raft.IsEmptySnap(snapshot) && snapshot.Metadata.Index > appliedIndex {
+		snapBlock, _, err := SnapshotBlock(snapshot.Data)
+		if err != nil {
+			return errors.Errorf("failed to read block from snapshot: %s", err)
+		}
+		resumeIndex = snapshot.Metadata.Index
+		if snapBlock.Header.Number > resumeNumber {
+			resumeNumber = snapBlock.Header.Number
+		}
+	}
+
+	for _, b := range blocks {
+		if b.index == appliedIndex && b.number != lastNumber {
+			return errors.Errorf("ledger and WAL diverged: block %d of the ledger was written from raft index %d, "+
+				"which holds block %d in the WAL; %s", lastNumber, appliedIndex, b.number, replicateSuggestion)
+		}
+		if b.index <= resumeIndex {
+			continue
+		}
+
+		// Blocks up to the last one are skipped when applied, but a gap cannot be filled.
+		if b.number > resumeNumber+1 {
+			return errors.Errorf("ledger is behind the WAL: the WAL resumes with block %d at raft index %d, "+
+				"but the last block of the ledger is block %d written from raft index %d; %s",
+				b.number, b.index, lastNumber, appliedIndex, restoreLedgerSuggestion)
+		}
+		break
+	}
+
+	return nil
+}
+
+// walBlock is a block carried by a raft entry of the WAL.
+type walBlock struct {
+	number uint64
+	index  uint64
+}
+
+// walBlocks returns the blocks carried by the entries.
+func walBlocks(ents []raftpb.Entry) ([]walBlock, error) {
+	var blocks []walBlock
+	for _, ent := range ents {
+		if ent.Type != raftpb.EntryNormal || len(ent.Data) == 0 || entryMarker(ent.Data) != nil {
+			continue
+		}
+		block, err := utils.UnmarshalBlock(ent.Data)
+		if err != nil || block.Header == nil {
+			return nil, errors.Errorf("raft entry %d does not hold a block", ent.Index)
+		}
+		blocks = append(blocks, walBlock{number: block.Header.Number, index: ent.Index})
+	}
+	return blocks, nil
+}
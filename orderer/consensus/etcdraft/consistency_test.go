@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestCheckLedgerConsistency(t *testing.T) {
+	blockEntry := func(index, number uint64) raftpb.Entry {
+		return raftpb.Entry{Index: index, Term: 1, Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(common.NewBlock(number, nil))}
+	}
+
+	// the WAL holds a snapshot of block 4 at raft index 9, followed by a
+	// ConfChange at 10, blocks 5 to 7 at 11 to 14, an empty entry at 12
+	// and a marker at 15
+	newStorage := func(t *testing.T, snapshot *raftpb.Snapshot) *raft.MemoryStorage {
+		ram := raft.NewMemoryStorage()
+		ents := []raftpb.Entry{{Index: 10, Term: 1, Type: raftpb.EntryConfChange}, blockEntry(11, 5), {Index: 12, Term: 1}, blockEntry(13, 6), blockEntry(14, 7),
+			{Index: 15, Term: 1, Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(&etcdraft.Marker{Type: etcdraft.Marker_PAUSE, Proposer: 1})}}
+		if snapshot == nil {
+			snapshot = &raftpb.Snapshot{
+				Data:     utils.MarshalOrPanic(common.NewBlock(4, nil)),
+				Metadata: raftpb.SnapshotMetadata{Index: 9, Term: 1},
+			}
+		}
+		require.NoError(t, ram.ApplySnapshot(*snapshot))
+		require.NoError(t, ram.Append(ents[snapshot.Metadata.Index-9:]))
+		return ram
+	}
+	snapshot := &raftpb.Snapshot{
+		Data:     utils.MarshalOrPanic(common.NewBlock(5, nil)),
+		Metadata: raftpb.SnapshotMetadata{Index: 11, Term: 1},
+	}
+
+	for _, testCase := range []struct {
+		name          string
+		snapshot      *raftpb.Snapshot
+		lastBlock     uint64
+		appliedIndex  uint64
+		expectedError string
+	}{
+		{
+			name:         "in sync",
+			lastBlock:    7,
+			appliedIndex: 14,
+		},
+		{
+			name:         "entries to replay",
+			lastBlock:    5,
+			appliedIndex: 11,
+		},
+		{
+			name:         "ledger at the snapshot",
+			lastBlock:    4,
+			appliedIndex: 9,
+		},
+		{
+			name:         "ledger ahead with replicated blocks",
+			lastBlock:    9,
+			appliedIndex: 20,
+		},
+		{
+			name:         "ledger behind a snapshot",
+			snapshot:     snapshot,
+			lastBlock:    2,
+			appliedIndex: 4,
+		},
+		{
+			name:          "ledger behind with a gap",
+			lastBlock:     3,
+			appliedIndex:  10,
+			expectedError: "ledger is behind the WAL: the WAL resumes with block 5 at raft index 11, but the last block of the ledger is block 3 written from raft index 10",
+		},
+		{
+			name:          "ledger ahead with a diverged WAL",
+			lastBlock:     7,
+			appliedIndex:  20,
+			expectedError: "ledger and WAL diverged: block 7 of the ledger was written from raft index 20, but the WAL already holds block 7 at the preceding raft index 14",
+		},
+		{
+			name:          "diverged at the applied index",
+			lastBlock:     6,
+			appliedIndex:  14,
+			expectedError: "ledger and WAL diverged: block 6 of the ledger was written from raft index 14, which holds block 7 in the WAL",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			ram := newStorage(t, testCase.snapshot)
+			err := checkLedgerConsistency(flogging.MustGetLogger("test"), ram, common.NewBlock(testCase.lastBlock, nil), testCase.appliedIndex)
+			if testCase.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedError)
+		})
+	}
+
+	// nothing to cross-check in an empty WAL
+	assert.NoError(t, checkLedgerConsistency(flogging.MustGetLogger("test"), raft.NewMemoryStorage(), common.NewBlock(3, nil), 8))
+}
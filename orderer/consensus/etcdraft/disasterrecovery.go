@@ -0,0 +1,867 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/tools/configtxlator/update"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+const (
+	// drRetryInterval is the interval at which the replication of a channel
+	// to its DR consenters is resumed, after a failure or a leader change.
+	drRetryInterval = time.Second
+
+	// drMaxBlocksPerRound is the number of blocks sent to a DR consenter
+	// before the other DR consenters of the channel get their turn.
+	drMaxBlocksPerRound = 100
+)
+
+//go:generate counterfeiter -o mocks/mock_drsink.go . DRSink
+
+// DRSink transfers the committed blocks and the snapshots of channels to the
+// DR consenters, the passive orderers of a disaster recovery site, which do
+// not take part in consensus.
+type DRSink interface {
+	// Height returns the number of blocks of the channel the DR consenter holds.
+	Height(target *etcdraft.Consenter, channel string) (uint64, error)
+	// SendBlock sends the block of the channel succeeding
+	// the blocks the DR consenter holds to it.
+	SendBlock(target *etcdraft.Consenter, channel string, block *common.Block) error
+	// SendSnapshot sends a snapshot of the raft state of the channel,
+	// which the DR consenter starts from once it is promoted.
+	SendSnapshot(target *etcdraft.Consenter, channel string, snapshot raftpb.Snapshot) error
+}
+
+// drTarget is the progress of the replication of a channel to a DR consenter.
+type drTarget struct {
+	consenter *etcdraft.Consenter
+	endpoint  string
+	height    uint64 // blocks the DR consenter holds, if known
+	known     bool   // whether height is known
+	snapshot  uint64 // raft index of the last snapshot sent to it
+}
+
+// drReplicator streams the blocks a chain commits, and the snapshots it takes,
+// to the DR consenters of the channel. Only the leader streams them, as blocks
+// are read from its ledger once they are written. The progress of each DR
+// consenter is queried from it whenever the node is elected, or sending to it
+// failed, so that nothing is sent twice and leader changes lose nothing.
+type drReplicator struct {
+	channel string
+	logger  *flogging.FabricLogger
+	clock   clock.Clock
+	sink    DRSink
+	leading func() bool
+	height  func() uint64
+	block   func(number uint64) *common.Block
+	metrics *Metrics
+
+	lock     sync.Mutex
+	targets  []*drTarget
+	snapshot *raftpb.Snapshot // the last one taken, if any
+	leader   bool             // whether the node led in the last round
+
+	wakeC chan struct{}
+}
+
+// newDRReplicator returns a drReplicator,
+// or nil if there is no sink to stream to.
+func newDRReplicator(channel string, logger *flogging.FabricLogger, clock clock.Clock, sink DRSink, leading func() bool,
+	height func() uint64, block func(number uint64) *common.Block, metrics *Metrics) *drReplicator {
+	if sink == nil {
+		return nil
+	}
+	return &drReplicator{
+		channel: channel,
+		logger:  logger,
+		clock:   clock,
+		sink:    sink,
+		leading: leading,
+		height:  height,
+		block:   block,
+		metrics: metrics,
+		wakeC:   make(chan struct{}, 1),
+	}
+}
+
+// setTargets sets the DR consenters of the channel, keeping
+// the progress of those which were DR consenters already.
+func (r *drReplicator) setTargets(consenters []*etcdraft.Consenter) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var targets []*drTarget
+	for _, consenter := range consenters {
+		target := &drTarget{consenter: consenter, endpoint: fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)}
+		for _, existing := range r.targets {
+			if proto.Equal(existing.consenter, consenter) {
+				target = existing
+			}
+		}
+		targets = append(targets, target)
+	}
+	r.targets = targets
+	r.wake()
+}
+
+// committed is called once a block is written to the ledger.
+func (r *drReplicator) committed() {
+	if r == nil {
+		return
+	}
+	r.wake()
+}
+
+// snapshotted is called once a snapshot is taken.
+func (r *drReplicator) snapshotted(snapshot raftpb.Snapshot) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	r.snapshot = &snapshot
+	r.lock.Unlock()
+	r.wake()
+}
+
+func (r *drReplicator) wake() {
+	select {
+	case r.wakeC <- struct{}{}:
+	default:
+	}
+}
+
+// run streams to the DR consenters until stopC is closed.
+func (r *drReplicator) run(stopC <-chan struct{}) {
+	if r == nil {
+		return
+	}
+
+	ticker := r.clock.NewTicker(drRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.wakeC:
+		case <-ticker.C():
+		case <-stopC:
+			return
+		}
+		r.replicate()
+	}
+}
+
+// replicate runs a round of streaming to the DR consenters.
+func (r *drReplicator) replicate() {
+	r.lock.Lock()
+	targets, snapshot := r.targets, r.snapshot
+	leading := r.leading()
+	if leading && !r.leader {
+		// the DR consenters may have been streamed to by other leaders since
+		for _, target := range targets {
+			target.known = false
+		}
+	}
+	r.leader = leading
+	r.lock.Unlock()
+
+	if !leading {
+		return
+	}
+
+	height := r.height()
+	for _, target := range targets {
+		if err := r.replicateTo(target, height, snapshot); err != nil {
+			r.logger.Warnf("Failed streaming to DR consenter %s: %s", target.endpoint, err)
+			r.metrics.DRSendFailures.With("orderer", target.endpoint).Add(1)
+			target.known = false
+			continue
+		}
+		var lag uint64
+		if target.height < height {
+			lag = height - target.height
+		}
+		r.metrics.DRReplicationLag.With("orderer", target.endpoint).Set(float64(lag))
+	}
+}
+
+func (r *drReplicator) replicateTo(target *drTarget, height uint64, snapshot *raftpb.Snapshot) error {
+	if !target.known {
+		h, err := r.sink.Height(target.consenter, r.channel)
+		if err != nil {
+			return errors.Wrap(err, "failed querying its height")
+		}
+		target.height, target.known = h, true
+	}
+
+	if snapshot != nil && snapshot.Metadata.Index > target.snapshot {
+		if err := r.sink.SendSnapshot(target.consenter, r.channel, *snapshot); err != nil {
+			return errors.Wrapf(err, "failed sending snapshot at index %d", snapshot.Metadata.Index)
+		}
+		target.snapshot = snapshot.Metadata.Index
+	}
+
+	for sent := 0; target.height < height; sent++ {
+		if sent == drMaxBlocksPerRound {
+			r.wake()
+			return nil
+		}
+		block := r.block(target.height)
+		if block == nil {
+			// not written to the ledger yet, it is sent in a later round
+			return nil
+		}
+		if err := r.sink.SendBlock(target.consenter, r.channel, block); err != nil {
+			return errors.Wrapf(err, "failed sending block %d", target.height)
+		}
+		target.height++
+	}
+	return nil
+}
+
+const (
+	// drBlockSuffix is the suffix of the files holding the blocks written by DirDRSink.
+	drBlockSuffix = ".block"
+
+	// drSnapshotFile is the file holding the last snapshot written by DirDRSink.
+	drSnapshotFile = "snapshot"
+)
+
+// DirDRSink streams channels to their DR consenters by writing their blocks and
+// snapshots to a directory, e.g. a volume replicated to the DR site, which the DR
+// consenters are bootstrapped from upon failover. The directory has a subdirectory
+// per DR consenter named after its endpoint, and within it a subdirectory per channel,
+// e.g. dr1.example.com:7050/mychannel. Each block is written marshaled to a file named
+// after its number, e.g. mychannel/5.block, and only the last snapshot is kept, marshaled
+// in mychannel/snapshot. Files are synced and then renamed into place, so that they are
+// either complete or absent.
+type DirDRSink struct {
+	Dir string
+}
+
+// channelDir returns the directory of the channel of the DR consenter.
+func (s *DirDRSink) channelDir(target *etcdraft.Consenter, channel string) (string, error) {
+	if strings.ContainsAny(target.Host, `/\`) || strings.ContainsAny(channel, `/\`) {
+		return "", errors.Errorf("invalid DR consenter %s:%d of channel %s", target.Host, target.Port, channel)
+	}
+	return filepath.Join(s.Dir, fmt.Sprintf("%s:%d", target.Host, target.Port), channel), nil
+}
+
+// Height returns the number of consecutive blocks, starting from
+// the genesis block, written to the directory of the channel.
+func (s *DirDRSink) Height(target *etcdraft.Consenter, channel string) (uint64, error) {
+	dir, err := s.channelDir(target, channel)
+	if err != nil {
+		return 0, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed listing %s", dir)
+	}
+
+	written := make(map[uint64]struct{})
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), drBlockSuffix) {
+			continue
+		}
+		n, err := strconv.ParseUint(strings.TrimSuffix(f.Name(), drBlockSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		written[n] = struct{}{}
+	}
+	var height uint64
+	for {
+		if _, exists := written[height]; !exists {
+			return height, nil
+		}
+		height++
+	}
+}
+
+// SendBlock writes the block to the directory of the channel.
+func (s *DirDRSink) SendBlock(target *etcdraft.Consenter, channel string, block *common.Block) error {
+	dir, err := s.channelDir(target, channel)
+	if err != nil {
+		return err
+	}
+	data, err := proto.Marshal(block)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal block %d", block.Header.Number)
+	}
+	return writeFileAtomically(dir, strconv.FormatUint(block.Header.Number, 10)+drBlockSuffix, data)
+}
+
+// readBlock reads the block with the given number from the directory of the channel.
+func (s *DirDRSink) readBlock(target *etcdraft.Consenter, channel string, number uint64) (*common.Block, error) {
+	dir, err := s.channelDir(target, channel)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, strconv.FormatUint(number, 10)+drBlockSuffix))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed reading block %d", number)
+	}
+	block := &common.Block{}
+	if err := proto.Unmarshal(data, block); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal block %d", number)
+	}
+	return block, nil
+}
+
+// SendSnapshot writes the snapshot to the directory of
+// the channel, replacing the snapshot written before.
+func (s *DirDRSink) SendSnapshot(target *etcdraft.Consenter, channel string, snapshot raftpb.Snapshot) error {
+	dir, err := s.channelDir(target, channel)
+	if err != nil {
+		return err
+	}
+	data, err := snapshot.Marshal()
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal snapshot at index %d", snapshot.Metadata.Index)
+	}
+	return writeFileAtomically(dir, drSnapshotFile, data)
+}
+
+// writeFileAtomically writes the data to a temporary file in the directory,
+// which is created if needed, syncs it and renames it to the given name.
+func writeFileAtomically(dir, name string, data []byte) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return errors.Wrapf(err, "failed creating %s", dir)
+	}
+	f, err := ioutil.TempFile(dir, "."+name)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed writing %s", f.Name())
+	}
+	if err := os.Rename(f.Name(), filepath.Join(dir, name)); err != nil {
+		return errors.Wrapf(err, "failed writing %s", filepath.Join(dir, name))
+	}
+	return nil
+}
+
+const (
+	// drBlocksPath is the resource of the operations service of DR consenters
+	// which HTTPDRSink queries the height of a channel from and sends blocks to.
+	drBlocksPath = "/etcdraft/dr/blocks"
+
+	// drSnapshotPath is the resource of the operations service
+	// of DR consenters which HTTPDRSink sends snapshots to.
+	drSnapshotPath = "/etcdraft/dr/snapshot"
+
+	// drMaxRequestBytes is the largest block or snapshot a DR consenter receives.
+	drMaxRequestBytes = 256 * 1024 * 1024
+)
+
+// drHeight is the body of the responses of the DR consenters to HTTPDRSink.
+type drHeight struct {
+	Height uint64 `json:"height"`
+}
+
+// HTTPDRSink streams channels to their DR consenters by sending their blocks and
+// snapshots to the operations service of each DR consenter, which listens on Port
+// at the host of the DR consenter and stores them in its DRDir as DirDRSink does.
+// The connections are authenticated with the client TLS certificate of the cluster,
+// and the operations services are verified with the server root CAs of the cluster,
+// both taken from ClientConfig whenever a connection is established.
+type HTTPDRSink struct {
+	Port         int
+	ClientConfig func() (comm.ClientConfig, error)
+	Client       *http.Client
+}
+
+// NewHTTPDRSink creates an HTTPDRSink sending to the given port of the DR consenters,
+// whose requests time out after the given timeout.
+func NewHTTPDRSink(port int, timeout time.Duration, clientConfig func() (comm.ClientConfig, error)) *HTTPDRSink {
+	s := &HTTPDRSink{
+		Port:         port,
+		ClientConfig: clientConfig,
+	}
+	s.Client = &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialTLS: s.dialTLS},
+	}
+	return s
+}
+
+// dialTLS establishes a TLS connection with the current TLS configuration of the cluster.
+func (s *HTTPDRSink) dialTLS(network, addr string) (net.Conn, error) {
+	cc, err := s.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tls.X509KeyPair(cc.SecOpts.Certificate, cc.SecOpts.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load client TLS key pair")
+	}
+	rootCAs := x509.NewCertPool()
+	for _, rootCA := range cc.SecOpts.ServerRootCAs {
+		rootCAs.AppendCertsFromPEM(rootCA)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      rootCAs,
+		CipherSuites: cc.SecOpts.CipherSuites,
+		MinVersion:   tls.VersionTLS12,
+	}
+	return tls.DialWithDialer(&net.Dialer{Timeout: cc.Timeout}, network, addr, tlsConfig)
+}
+
+// url returns the URL of the resource of the operations service of the DR consenter for the channel.
+func (s *HTTPDRSink) url(target *etcdraft.Consenter, channel, resource string) (string, error) {
+	cc, err := s.ClientConfig()
+	if err != nil {
+		return "", err
+	}
+	scheme := "http"
+	if cc.SecOpts != nil && cc.SecOpts.UseTLS {
+		scheme = "https"
+	}
+	query := url.Values{}
+	query.Set("channel", channel)
+	query.Set("consenter", fmt.Sprintf("%s:%d", target.Host, target.Port))
+	u := url.URL{
+		Scheme:   scheme,
+		Host:     net.JoinHostPort(target.Host, strconv.Itoa(s.Port)),
+		Path:     resource,
+		RawQuery: query.Encode(),
+	}
+	return u.String(), nil
+}
+
+// do sends the request to the DR consenter and decodes its response.
+func (s *HTTPDRSink) do(target *etcdraft.Consenter, channel, method, resource string, body []byte) (uint64, error) {
+	u, err := s.url(target, channel, resource)
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&failure)
+		return 0, errors.Errorf("DR consenter responded with %s: %s", resp.Status, failure.Error)
+	}
+	height := &drHeight{}
+	if err := json.NewDecoder(resp.Body).Decode(height); err != nil {
+		return 0, errors.Wrap(err, "failed to decode the response of the DR consenter")
+	}
+	return height.Height, nil
+}
+
+// Height queries the number of blocks of the channel the DR consenter holds.
+func (s *HTTPDRSink) Height(target *etcdraft.Consenter, channel string) (uint64, error) {
+	return s.do(target, channel, http.MethodGet, drBlocksPath, nil)
+}
+
+// SendBlock sends the block to the DR consenter.
+func (s *HTTPDRSink) SendBlock(target *etcdraft.Consenter, channel string, block *common.Block) error {
+	data, err := proto.Marshal(block)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal block %d", block.Header.Number)
+	}
+	_, err = s.do(target, channel, http.MethodPost, drBlocksPath, data)
+	return err
+}
+
+// SendSnapshot sends the snapshot to the DR consenter.
+func (s *HTTPDRSink) SendSnapshot(target *etcdraft.Consenter, channel string, snapshot raftpb.Snapshot) error {
+	data, err := snapshot.Marshal()
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal snapshot at index %d", snapshot.Metadata.Index)
+	}
+	_, err = s.do(target, channel, http.MethodPost, drSnapshotPath, data)
+	return err
+}
+
+// drReceiver stores the blocks and snapshots sent by HTTPDRSink
+// to the DR consenter, for the channels it is a DR consenter of.
+type drReceiver struct {
+	store  *DirDRSink
+	logger *flogging.FabricLogger
+	lock   sync.Mutex
+}
+
+// DRReceiverHandler returns a handler which stores the blocks and snapshots sent to
+// this node by the leaders of the channels it is a DR consenter of, in DRDir as
+// DirDRSink does, which is to be served at both drBlocksPath and drSnapshotPath.
+// GET requests of the form ?channel=<channel ID>&consenter=<host>:<port> for the
+// blocks are responded with the number of consecutive blocks of the channel stored,
+// and POST requests of that form store the marshaled block in the body, which must
+// succeed those stored. POST requests for the snapshot store the marshaled raft
+// snapshot in the body, replacing the snapshot stored before.
+func (c *Consenter) DRReceiverHandler() http.Handler {
+	return &drReceiver{store: c.DRStore, logger: c.Logger}
+}
+
+func (r *drReceiver) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case drBlocksPath:
+		r.serveBlocks(resp, req)
+	case drSnapshotPath:
+		r.serveSnapshot(resp, req)
+	default:
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("unknown DR resource %s", req.URL.Path))
+	}
+}
+
+// target parses the DR consenter and the channel of the request,
+// and responds with an error if they are missing or invalid.
+func (r *drReceiver) target(resp http.ResponseWriter, req *http.Request) (*etcdraft.Consenter, string, bool) {
+	if r.store == nil {
+		sendJSONError(resp, http.StatusServiceUnavailable, "this node does not receive blocks as a DR consenter, Consensus.DRDir is not set")
+		return nil, "", false
+	}
+	channel := req.URL.Query().Get("channel")
+	if channel == "" || channel == "." || channel == ".." {
+		sendJSONError(resp, http.StatusBadRequest, "missing or invalid channel")
+		return nil, "", false
+	}
+	host, portString, err := net.SplitHostPort(req.URL.Query().Get("consenter"))
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid consenter: %s", err))
+		return nil, "", false
+	}
+	port, err := strconv.ParseUint(portString, 10, 32)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid consenter port %s", portString))
+		return nil, "", false
+	}
+	return &etcdraft.Consenter{Host: host, Port: uint32(port)}, channel, true
+}
+
+func (r *drReceiver) serveBlocks(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+	target, channel, ok := r.target(resp, req)
+	if !ok {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	height, err := r.store.Height(target, channel)
+	if err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if req.Method == http.MethodPost {
+		block := &common.Block{}
+		if !r.readBody(resp, req, block) {
+			return
+		}
+		if err := r.checkSuccessor(target, channel, height, block); err != nil {
+			sendJSONError(resp, http.StatusConflict, err.Error())
+			return
+		}
+		if err := r.store.SendBlock(target, channel, block); err != nil {
+			sendJSONError(resp, http.StatusInternalServerError, err.Error())
+			return
+		}
+		height++
+	}
+	r.respond(resp, height)
+}
+
+func (r *drReceiver) serveSnapshot(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+	target, channel, ok := r.target(resp, req)
+	if !ok {
+		return
+	}
+	data, err := ioutil.ReadAll(http.MaxBytesReader(resp, req.Body, drMaxRequestBytes))
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading snapshot: %s", err))
+		return
+	}
+	snapshot := raftpb.Snapshot{}
+	if err := snapshot.Unmarshal(data); err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed to unmarshal snapshot: %s", err))
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err := r.store.SendSnapshot(target, channel, snapshot); err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	height, err := r.store.Height(target, channel)
+	if err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+	r.respond(resp, height)
+}
+
+// readBody unmarshals the body of the request into the block,
+// and responds with an error if it cannot.
+func (r *drReceiver) readBody(resp http.ResponseWriter, req *http.Request, block *common.Block) bool {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(resp, req.Body, drMaxRequestBytes))
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading block: %s", err))
+		return false
+	}
+	if err := proto.Unmarshal(data, block); err != nil || block.Header == nil || block.Data == nil {
+		sendJSONError(resp, http.StatusBadRequest, "failed to unmarshal block")
+		return false
+	}
+	return true
+}
+
+// checkSuccessor returns an error if the block does not succeed
+// the blocks of the channel stored for the DR consenter.
+func (r *drReceiver) checkSuccessor(target *etcdraft.Consenter, channel string, height uint64, block *common.Block) error {
+	if block.Header.Number != height {
+		return errors.Errorf("expected block %d of channel %s, got block %d", height, channel, block.Header.Number)
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return errors.Errorf("data hash of block %d of channel %s does not match its data", height, channel)
+	}
+	if height == 0 {
+		return nil
+	}
+	previous, err := r.store.readBlock(target, channel, height-1)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(block.Header.PreviousHash, previous.Header.Hash()) {
+		return errors.Errorf("previous hash of block %d of channel %s does not match block %d", height, channel, height-1)
+	}
+	return nil
+}
+
+func (r *drReceiver) respond(resp http.ResponseWriter, height uint64) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(&drHeight{Height: height}); err != nil {
+		r.logger.Errorf("Failed to encode response to DR sink: %s", err)
+	}
+}
+
+// PromoteDRConsenters returns a copy of the given metadata which takes the next
+// step of promoting the DR consenters to the consenters, replacing those. As raft
+// changes one consenter at a time, each step either adds a DR consenter to the
+// consenters, or removes a consenter which is not a DR consenter, alternately so
+// that the cluster outgrows the DR consenters by at most one. The DR consenters
+// are listed as such until the last step, which clears them.
+func PromoteDRConsenters(md *etcdraft.ConfigMetadata) (*etcdraft.ConfigMetadata, error) {
+	if len(md.DrConsenters) == 0 {
+		return nil, errors.New("no DR consenters to promote")
+	}
+
+	isDR := func(consenter *etcdraft.Consenter) bool {
+		return raftconfig.ContainsConsenter(md.DrConsenters, consenter)
+	}
+
+	var toAdd *etcdraft.Consenter
+	for _, consenter := range md.DrConsenters {
+		if !raftconfig.ContainsConsenter(md.Consenters, consenter) {
+			toAdd = consenter
+			break
+		}
+	}
+	toRemove := -1
+	for i, consenter := range md.Consenters {
+		if !isDR(consenter) {
+			toRemove = i
+			break
+		}
+	}
+
+	updated := proto.Clone(md).(*etcdraft.ConfigMetadata)
+	switch {
+	case toAdd != nil && (toRemove == -1 || len(md.Consenters) <= len(md.DrConsenters)):
+		updated.Consenters = append(updated.Consenters, proto.Clone(toAdd).(*etcdraft.Consenter))
+	case toRemove != -1:
+		updated.Consenters = append(updated.Consenters[:toRemove], updated.Consenters[toRemove+1:]...)
+	default:
+		updated.DrConsenters = nil
+	}
+	return updated, nil
+}
+
+// drPromotionUpdate generates the config update of the channel with the given
+// config which takes the next step of promoting its DR consenters.
+func drPromotionUpdate(channelID string, config *common.Config) (*common.ConfigUpdate, error) {
+	ordererGroup, exists := config.ChannelGroup.Groups[channelconfig.OrdererGroupKey]
+	if !exists {
+		return nil, errors.New("config has no orderer group")
+	}
+	value, exists := ordererGroup.Values[channelconfig.ConsensusTypeKey]
+	if !exists {
+		return nil, errors.New("config has no ConsensusType")
+	}
+	consensusType := &orderer.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal ConsensusType")
+	}
+	if consensusType.Type != etcdraft.TypeKey {
+		return nil, errors.Errorf("consensus type %s is not %s", consensusType.Type, etcdraft.TypeKey)
+	}
+	md := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, md); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcdraft metadata")
+	}
+
+	promoted, err := PromoteDRConsenters(md)
+	if err != nil {
+		return nil, err
+	}
+	consensusType.Metadata = utils.MarshalOrPanic(promoted)
+
+	updated := proto.Clone(config).(*common.Config)
+	updated.ChannelGroup.Groups[channelconfig.OrdererGroupKey].Values[channelconfig.ConsensusTypeKey].Value = utils.MarshalOrPanic(consensusType)
+	configUpdate, err := update.Compute(config, updated)
+	if err != nil {
+		return nil, err
+	}
+	configUpdate.ChannelId = channelID
+	return configUpdate, nil
+}
+
+// DRPromotionUpdate generates the config update which takes the next step of
+// promoting the DR consenters of the channel to its consenters, as described
+// by PromoteDRConsenters, from the last config of the channel. The update is
+// to be signed according to the policies of the channel and submitted as any
+// config update, hence it needs a quorum of the consenters to be ordered.
+func (c *Chain) DRPromotionUpdate() (*common.ConfigUpdate, error) {
+	block, err := lastConfigBlockFromSupport(c.support)
+	if err != nil {
+		return nil, err
+	}
+	env, err := ConfigEnvelopeFromBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal config payload")
+	}
+	configEnv, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return nil, err
+	}
+	return drPromotionUpdate(c.channelID, configEnv.Config)
+}
+
+// drPromotionHandler generates the config updates promoting the
+// DR consenters of the etcdraft chain of the channel given in the query.
+type drPromotionHandler struct {
+	consenter *Consenter
+}
+
+// DRPromotionHandler returns a handler serving the config update which takes the
+// next step of promoting the DR consenters of a channel, for POST requests of the
+// form ?channel=<channel ID>. The update is rendered in JSON, as by configtxlator,
+// if the request accepts application/json, and is a marshaled protobuf message
+// otherwise. The request is repeated once each update is committed, until the
+// update promoting the DR consenters fails for there are none left.
+func (c *Consenter) DRPromotionHandler() http.Handler {
+	return &drPromotionHandler{consenter: c}
+}
+
+func (h *drPromotionHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+	channelID := req.URL.Query().Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	configUpdate, err := chain.DRPromotionUpdate()
+	if err != nil {
+		sendJSONError(resp, http.StatusConflict, fmt.Sprintf("cannot promote DR consenters: %s", err))
+		return
+	}
+
+	if strings.Contains(req.Header.Get("Accept"), "application/json") {
+		resp.Header().Set("Content-Type", "application/json")
+		resp.WriteHeader(http.StatusOK)
+		if err := protolator.DeepMarshalJSON(resp, configUpdate); err != nil {
+			h.consenter.Logger.Errorw("failed to encode DR promotion config update", "channel", channelID, "error", err)
+		}
+		return
+	}
+	resp.Header().Set("Content-Type", "application/octet-stream")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(utils.MarshalOrPanic(configUpdate))
+}
@@ -0,0 +1,591 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/core/middleware"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// recordingDRSink records what is streamed to each DR consenter, by host.
+type recordingDRSink struct {
+	heights   map[string]uint64
+	blocks    map[string][]uint64
+	snapshots map[string][]uint64
+	err       error
+}
+
+func (s *recordingDRSink) Height(target *etcdraft.Consenter, channel string) (uint64, error) {
+	return s.heights[target.Host], s.err
+}
+
+func (s *recordingDRSink) SendBlock(target *etcdraft.Consenter, channel string, block *common.Block) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.blocks[target.Host] = append(s.blocks[target.Host], block.Header.Number)
+	return nil
+}
+
+func (s *recordingDRSink) SendSnapshot(target *etcdraft.Consenter, channel string, snapshot raftpb.Snapshot) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.snapshots[target.Host] = append(s.snapshots[target.Host], snapshot.Metadata.Index)
+	return nil
+}
+
+func TestDRReplicator(t *testing.T) {
+	sink := &recordingDRSink{
+		heights:   map[string]uint64{"dr1": 1, "dr2": 3},
+		blocks:    map[string][]uint64{},
+		snapshots: map[string][]uint64{},
+	}
+	leading := false
+	written := uint64(5)
+	lag := &metricsfakes.Gauge{}
+	lag.WithReturns(lag)
+	failures := &metricsfakes.Counter{}
+	failures.WithReturns(failures)
+
+	assert.Nil(t, newDRReplicator("mychannel", flogging.MustGetLogger("test"), fakeclock.NewFakeClock(time.Now()), nil, nil, nil, nil, nil))
+	var disabled *drReplicator
+	disabled.setTargets([]*etcdraft.Consenter{{Host: "dr1"}})
+	disabled.committed()
+	disabled.snapshotted(raftpb.Snapshot{})
+	disabled.run(nil)
+
+	r := newDRReplicator("mychannel", flogging.MustGetLogger("test"), fakeclock.NewFakeClock(time.Now()), sink,
+		func() bool { return leading },
+		func() uint64 { return 6 },
+		func(number uint64) *common.Block {
+			if number > written {
+				return nil
+			}
+			return common.NewBlock(number, nil)
+		},
+		&Metrics{DRReplicationLag: lag, DRSendFailures: failures})
+	dr1, dr2 := &etcdraft.Consenter{Host: "dr1", Port: 7050}, &etcdraft.Consenter{Host: "dr2", Port: 7050}
+	r.setTargets([]*etcdraft.Consenter{dr1, dr2})
+
+	// followers do not stream
+	r.replicate()
+	assert.Empty(t, sink.blocks)
+
+	// the leader streams the blocks each DR consenter lacks, as they are written
+	leading = true
+	written = 4
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr1": {1, 2, 3, 4}, "dr2": {3, 4}}, sink.blocks)
+	written = 5
+	r.committed()
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr1": {1, 2, 3, 4, 5}, "dr2": {3, 4, 5}}, sink.blocks)
+	assert.Equal(t, []string{"orderer", "dr2:7050"}, lag.WithArgsForCall(lag.WithCallCount()-1))
+	assert.Equal(t, float64(0), lag.SetArgsForCall(lag.SetCallCount()-1))
+
+	// snapshots are streamed once
+	r.snapshotted(raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 10}})
+	r.replicate()
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr1": {10}, "dr2": {10}}, sink.snapshots)
+
+	// a DR consenter is queried for its height again once streaming to it fails
+	sink.err = errors.New("unreachable")
+	r.snapshotted(raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 11}})
+	r.replicate()
+	assert.Equal(t, 2, failures.AddCallCount())
+	assert.Equal(t, []string{"orderer", "dr1:7050"}, failures.WithArgsForCall(0))
+	sink.err = nil
+	sink.heights["dr1"], sink.heights["dr2"] = 4, 5
+	sink.blocks = map[string][]uint64{}
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr1": {4, 5}, "dr2": {5}}, sink.blocks)
+	assert.Equal(t, map[string][]uint64{"dr1": {10, 11}, "dr2": {10, 11}}, sink.snapshots)
+
+	// a DR consenter removed and added again starts over, unlike the ones kept
+	dr3 := &etcdraft.Consenter{Host: "dr3", Port: 7050}
+	r.setTargets([]*etcdraft.Consenter{dr1, dr3})
+	sink.blocks = map[string][]uint64{}
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr3": {0, 1, 2, 3, 4, 5}}, sink.blocks)
+	assert.Equal(t, map[string][]uint64{"dr1": {10, 11}, "dr2": {10, 11}, "dr3": {11}}, sink.snapshots)
+
+	// the progress of DR consenters is queried again once the node is elected again
+	leading = false
+	r.replicate()
+	leading = true
+	sink.heights["dr1"] = 2
+	sink.blocks = map[string][]uint64{}
+	r.replicate()
+	assert.Equal(t, map[string][]uint64{"dr1": {2, 3, 4, 5}, "dr3": {0, 1, 2, 3, 4, 5}}, sink.blocks)
+}
+
+func TestDRReplicatorRounds(t *testing.T) {
+	sink := &recordingDRSink{heights: map[string]uint64{}, blocks: map[string][]uint64{}, snapshots: map[string][]uint64{}}
+	r := newDRReplicator("mychannel", flogging.MustGetLogger("test"), fakeclock.NewFakeClock(time.Now()), sink,
+		func() bool { return true },
+		func() uint64 { return drMaxBlocksPerRound + 1 },
+		func(number uint64) *common.Block { return common.NewBlock(number, nil) },
+		&Metrics{DRReplicationLag: &metricsfakes.Gauge{}, DRSendFailures: &metricsfakes.Counter{}})
+	lag := &metricsfakes.Gauge{}
+	lag.WithReturns(lag)
+	r.metrics.DRReplicationLag = lag
+	r.setTargets([]*etcdraft.Consenter{{Host: "dr1", Port: 7050}})
+	<-r.wakeC
+
+	// blocks are sent in rounds, and the next round is due at once
+	r.replicate()
+	assert.Len(t, sink.blocks["dr1"], drMaxBlocksPerRound)
+	assert.Equal(t, float64(1), lag.SetArgsForCall(0))
+	assert.Len(t, r.wakeC, 1)
+	r.replicate()
+	assert.Len(t, sink.blocks["dr1"], drMaxBlocksPerRound+1)
+}
+
+func TestDirDRSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drsink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sink := &DirDRSink{Dir: dir}
+	dr1 := &etcdraft.Consenter{Host: "dr1", Port: 7050}
+	dr2 := &etcdraft.Consenter{Host: "dr2", Port: 7050}
+
+	height, err := sink.Height(dr1, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), height)
+
+	var blocks []*common.Block
+	for i := uint64(0); i < 3; i++ {
+		block := common.NewBlock(i, []byte{byte(i)})
+		blocks = append(blocks, block)
+		require.NoError(t, sink.SendBlock(dr1, "mychannel", block))
+	}
+	height, err = sink.Height(dr1, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), height)
+	height, err = sink.Height(dr2, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), height)
+
+	channelDir := filepath.Join(dir, "dr1:7050", "mychannel")
+	for _, block := range blocks {
+		data, err := ioutil.ReadFile(filepath.Join(channelDir, fmt.Sprintf("%d.block", block.Header.Number)))
+		require.NoError(t, err)
+		written := &common.Block{}
+		require.NoError(t, proto.Unmarshal(data, written))
+		assert.True(t, proto.Equal(block, written))
+	}
+
+	// the height only counts consecutive blocks
+	require.NoError(t, sink.SendBlock(dr1, "mychannel", common.NewBlock(4, nil)))
+	height, err = sink.Height(dr1, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), height)
+
+	// only the last snapshot is kept
+	for _, index := range []uint64{5, 10} {
+		require.NoError(t, sink.SendSnapshot(dr1, "mychannel", raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: index, Term: 1}}))
+	}
+	data, err := ioutil.ReadFile(filepath.Join(channelDir, "snapshot"))
+	require.NoError(t, err)
+	snapshot := raftpb.Snapshot{}
+	require.NoError(t, snapshot.Unmarshal(data))
+	assert.Equal(t, uint64(10), snapshot.Metadata.Index)
+
+	files, err := ioutil.ReadDir(channelDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 5, "temporary files are left behind")
+
+	err = sink.SendBlock(&etcdraft.Consenter{Host: "../dr3", Port: 7050}, "mychannel", blocks[0])
+	assert.EqualError(t, err, "invalid DR consenter ../dr3:7050 of channel mychannel")
+}
+
+func TestHTTPDRSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drsink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	serverKeyPair, err := ca.NewServerCertKeyPair("127.0.0.1")
+	require.NoError(t, err)
+	serverCert, err := tls.X509KeyPair(serverKeyPair.Cert, serverKeyPair.Key)
+	require.NoError(t, err)
+	clientKeyPair, err := ca.NewClientCertKeyPair()
+	require.NoError(t, err)
+
+	receiver := (&Consenter{DRStore: &DirDRSink{Dir: dir}, Logger: flogging.MustGetLogger("test")}).DRReceiverHandler()
+	mux := http.NewServeMux()
+	mux.Handle("/etcdraft/dr/blocks", middleware.RequireCert()(receiver))
+	mux.Handle("/etcdraft/dr/snapshot", middleware.RequireCert()(receiver))
+	server := httptest.NewUnstartedServer(mux)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(ca.CertBytes())
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	_, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portString)
+	require.NoError(t, err)
+
+	sink := NewHTTPDRSink(port, 10*time.Second, func() (comm.ClientConfig, error) {
+		return comm.ClientConfig{SecOpts: &comm.SecureOptions{
+			UseTLS:        true,
+			Certificate:   clientKeyPair.Cert,
+			Key:           clientKeyPair.Key,
+			ServerRootCAs: [][]byte{ca.CertBytes()},
+		}, Timeout: time.Second}, nil
+	})
+	dr1 := &etcdraft.Consenter{Host: "127.0.0.1", Port: 7050}
+
+	height, err := sink.Height(dr1, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), height)
+
+	var blocks []*common.Block
+	var previousHash []byte
+	for i := uint64(0); i < 3; i++ {
+		block := common.NewBlock(i, previousHash)
+		block.Data.Data = [][]byte{{byte(i)}}
+		block.Header.DataHash = block.Data.Hash()
+		previousHash = block.Header.Hash()
+		blocks = append(blocks, block)
+		require.NoError(t, sink.SendBlock(dr1, "mychannel", block))
+	}
+	height, err = sink.Height(dr1, "mychannel")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), height)
+
+	// the blocks are stored as by DirDRSink
+	stored, err := (&DirDRSink{Dir: dir}).readBlock(dr1, "mychannel", 2)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(blocks[2], stored))
+
+	err = sink.SendBlock(dr1, "mychannel", blocks[1])
+	assert.EqualError(t, err, "DR consenter responded with 409 Conflict: expected block 3 of channel mychannel, got block 1")
+
+	forged := common.NewBlock(3, []byte{1, 2, 3})
+	forged.Header.DataHash = forged.Data.Hash()
+	err = sink.SendBlock(dr1, "mychannel", forged)
+	assert.EqualError(t, err, "DR consenter responded with 409 Conflict: previous hash of block 3 of channel mychannel does not match block 2")
+
+	tampered := common.NewBlock(3, previousHash)
+	err = sink.SendBlock(dr1, "mychannel", tampered)
+	assert.EqualError(t, err, "DR consenter responded with 409 Conflict: data hash of block 3 of channel mychannel does not match its data")
+
+	require.NoError(t, sink.SendSnapshot(dr1, "mychannel", raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 5, Term: 1}}))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "127.0.0.1:7050", "mychannel", "snapshot"))
+	require.NoError(t, err)
+	snapshot := raftpb.Snapshot{}
+	require.NoError(t, snapshot.Unmarshal(data))
+	assert.Equal(t, uint64(5), snapshot.Metadata.Index)
+
+	// DR consenters are not sent to without a client certificate
+	sink = NewHTTPDRSink(port, 10*time.Second, func() (comm.ClientConfig, error) {
+		return comm.ClientConfig{SecOpts: &comm.SecureOptions{UseTLS: true, ServerRootCAs: [][]byte{ca.CertBytes()}}}, nil
+	})
+	_, err = sink.Height(dr1, "mychannel")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load client TLS key pair")
+
+	// nor if they are not verified with the root CAs of the cluster
+	otherCA, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	sink = NewHTTPDRSink(port, 10*time.Second, func() (comm.ClientConfig, error) {
+		return comm.ClientConfig{SecOpts: &comm.SecureOptions{
+			UseTLS:        true,
+			Certificate:   clientKeyPair.Cert,
+			Key:           clientKeyPair.Key,
+			ServerRootCAs: [][]byte{otherCA.CertBytes()},
+		}}, nil
+	})
+	_, err = sink.Height(dr1, "mychannel")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate signed by unknown authority")
+}
+
+func TestDRReceiverHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drsink")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	receiver := (&Consenter{DRStore: &DirDRSink{Dir: dir}, Logger: flogging.MustGetLogger("test")}).DRReceiverHandler()
+	for _, tc := range []struct {
+		name, method, target string
+		body                 []byte
+		code                 int
+		err                  string
+	}{
+		{
+			name:   "invalid method",
+			method: http.MethodDelete,
+			target: "/etcdraft/dr/blocks?channel=mychannel&consenter=dr1:7050",
+			code:   http.StatusMethodNotAllowed,
+			err:    "invalid request method: DELETE",
+		},
+		{
+			name:   "invalid snapshot method",
+			method: http.MethodGet,
+			target: "/etcdraft/dr/snapshot?channel=mychannel&consenter=dr1:7050",
+			code:   http.StatusMethodNotAllowed,
+			err:    "invalid request method: GET",
+		},
+		{
+			name:   "missing channel",
+			method: http.MethodGet,
+			target: "/etcdraft/dr/blocks?consenter=dr1:7050",
+			code:   http.StatusBadRequest,
+			err:    "missing or invalid channel",
+		},
+		{
+			name:   "invalid channel",
+			method: http.MethodGet,
+			target: "/etcdraft/dr/blocks?channel=..&consenter=dr1:7050",
+			code:   http.StatusBadRequest,
+			err:    "missing or invalid channel",
+		},
+		{
+			name:   "invalid consenter port",
+			method: http.MethodGet,
+			target: "/etcdraft/dr/blocks?channel=mychannel&consenter=dr1:port",
+			code:   http.StatusBadRequest,
+			err:    "invalid consenter port port",
+		},
+		{
+			name:   "invalid block",
+			method: http.MethodPost,
+			target: "/etcdraft/dr/blocks?channel=mychannel&consenter=dr1:7050",
+			body:   []byte("block"),
+			code:   http.StatusBadRequest,
+			err:    "failed to unmarshal block",
+		},
+		{
+			name:   "unknown resource",
+			method: http.MethodGet,
+			target: "/etcdraft/dr/wal?channel=mychannel&consenter=dr1:7050",
+			code:   http.StatusNotFound,
+			err:    "unknown DR resource /etcdraft/dr/wal",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			receiver.ServeHTTP(resp, httptest.NewRequest(tc.method, tc.target, bytes.NewReader(tc.body)))
+			assert.Equal(t, tc.code, resp.Code)
+			assert.JSONEq(t, fmt.Sprintf(`{"error": %q}`, tc.err), resp.Body.String())
+		})
+	}
+
+	// nothing is received unless DRDir is set
+	resp := httptest.NewRecorder()
+	(&Consenter{}).DRReceiverHandler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/etcdraft/dr/blocks?channel=mychannel&consenter=dr1:7050", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.JSONEq(t, `{"error": "this node does not receive blocks as a DR consenter, Consensus.DRDir is not set"}`, resp.Body.String())
+}
+
+func TestPromoteDRConsenters(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	_, err = PromoteDRConsenters(&etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{newConsenter(t, ca, "orderer1")}})
+	assert.EqualError(t, err, "no DR consenters to promote")
+
+	for _, testCase := range []struct {
+		name       string
+		consenters int
+		dr         int
+		maxSize    int
+	}{
+		{name: "as many DR consenters", consenters: 3, dr: 3, maxSize: 4},
+		{name: "fewer DR consenters", consenters: 5, dr: 3, maxSize: 5},
+		{name: "more DR consenters", consenters: 1, dr: 3, maxSize: 4},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			md := &etcdraft.ConfigMetadata{}
+			for i := 0; i < testCase.consenters; i++ {
+				md.Consenters = append(md.Consenters, newConsenter(t, ca, "orderer"))
+			}
+			for i := 0; i < testCase.dr; i++ {
+				md.DrConsenters = append(md.DrConsenters, newConsenter(t, ca, "dr"))
+			}
+			dr, first := md.DrConsenters, md
+			original := proto.Clone(md)
+
+			steps := 0
+			for md.DrConsenters != nil {
+				promoted, err := PromoteDRConsenters(md)
+				require.NoError(t, err)
+				require.NoError(t, MetadataHasDuplication(promoted))
+				assert.True(t, len(promoted.Consenters) <= testCase.maxSize, "the cluster has %d consenters", len(promoted.Consenters))
+				if promoted.DrConsenters != nil {
+					// raft changes one consenter at a time
+					changes, err := ComputeMembershipChanges(membershipOf(md), promoted.Consenters)
+					require.NoError(t, err)
+					assert.NotNil(t, changes.ConfChange)
+				}
+				md = promoted
+				steps++
+			}
+			assert.Equal(t, dr, md.Consenters)
+			assert.Equal(t, testCase.consenters+testCase.dr+1, steps)
+			assert.True(t, proto.Equal(original, first), "the given metadata is not modified")
+		})
+	}
+}
+
+// membershipOf returns the block metadata of a chain with the consenters of the given metadata.
+func membershipOf(md *etcdraft.ConfigMetadata) *etcdraft.BlockMetadata {
+	bm := &etcdraft.BlockMetadata{Consenters: map[uint64]*etcdraft.Consenter{}, NextConsenterId: 1}
+	for _, consenter := range md.Consenters {
+		bm.Consenters[bm.NextConsenterId] = consenter
+		bm.NextConsenterId++
+	}
+	return bm
+}
+
+func drChannelConfig(md *etcdraft.ConfigMetadata) *common.Config {
+	return &common.Config{
+		ChannelGroup: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				"Orderer": {
+					Values: map[string]*common.ConfigValue{
+						"ConsensusType": {
+							Value: utils.MarshalOrPanic(&orderer.ConsensusType{Type: "etcdraft", Metadata: utils.MarshalOrPanic(md)}),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDRPromotionUpdate(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	consenter, dr := newConsenter(t, ca, "orderer1"), newConsenter(t, ca, "dr1")
+
+	configUpdate, err := drPromotionUpdate("mychannel", drChannelConfig(&etcdraft.ConfigMetadata{
+		Consenters:   []*etcdraft.Consenter{consenter},
+		DrConsenters: []*etcdraft.Consenter{dr},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "mychannel", configUpdate.ChannelId)
+	value := configUpdate.WriteSet.Groups["Orderer"].Values["ConsensusType"]
+	require.NotNil(t, value)
+	assert.Equal(t, uint64(1), value.Version)
+	consensusType := &orderer.ConsensusType{}
+	require.NoError(t, proto.Unmarshal(value.Value, consensusType))
+	md := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(consensusType.Metadata, md))
+	assert.True(t, proto.Equal(&etcdraft.ConfigMetadata{
+		Consenters:   []*etcdraft.Consenter{consenter, dr},
+		DrConsenters: []*etcdraft.Consenter{dr},
+	}, md))
+
+	_, err = drPromotionUpdate("mychannel", drChannelConfig(&etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{consenter}}))
+	assert.EqualError(t, err, "no DR consenters to promote")
+	_, err = drPromotionUpdate("mychannel", &common.Config{ChannelGroup: &common.ConfigGroup{}})
+	assert.EqualError(t, err, "config has no orderer group")
+}
+
+func TestDRPromotionHandler(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	md := &etcdraft.ConfigMetadata{
+		Consenters:   []*etcdraft.Consenter{newConsenter(t, ca, "orderer1")},
+		DrConsenters: []*etcdraft.Consenter{newConsenter(t, ca, "dr1")},
+	}
+
+	configEnv, err := utils.CreateSignedEnvelope(common.HeaderType_CONFIG, "mychannel", nil, &common.ConfigEnvelope{Config: drChannelConfig(md)}, 0, 0)
+	require.NoError(t, err)
+	configBlock := common.NewBlock(0, nil)
+	configBlock.Data.Data = [][]byte{utils.MarshalOrPanic(configEnv)}
+	configBlock.Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(&common.LastConfig{Index: 0}),
+	})
+	support := &mockmultichannel.ConsenterSupport{HeightVal: 1, BlockByIndex: map[uint64]*common.Block{0: configBlock}}
+
+	chains := chainsByID{"mychannel": &multichannel.ChainSupport{Chain: &Chain{channelID: "mychannel", support: support}}}
+	handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).DRPromotionHandler()
+
+	serve := func(method, target, accept string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Accept", accept)
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/dr/promote?channel=mychannel", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	resp = serve(http.MethodPost, "/etcdraft/dr/promote", "")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	resp = serve(http.MethodPost, "/etcdraft/dr/promote?channel=nochannel", "")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodPost, "/etcdraft/dr/promote?channel=mychannel", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/octet-stream", resp.Header().Get("Content-Type"))
+	configUpdate := &common.ConfigUpdate{}
+	require.NoError(t, proto.Unmarshal(resp.Body.Bytes(), configUpdate))
+	assert.Equal(t, "mychannel", configUpdate.ChannelId)
+
+	resp = serve(http.MethodPost, "/etcdraft/dr/promote?channel=mychannel", "application/json")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+	jsonUpdate := &common.ConfigUpdate{}
+	require.NoError(t, protolator.DeepUnmarshalJSON(bytes.NewReader(resp.Body.Bytes()), jsonUpdate))
+	assert.True(t, proto.Equal(configUpdate, jsonUpdate))
+
+	md.DrConsenters = nil
+	configEnv, err = utils.CreateSignedEnvelope(common.HeaderType_CONFIG, "mychannel", nil, &common.ConfigEnvelope{Config: drChannelConfig(md)}, 0, 0)
+	require.NoError(t, err)
+	configBlock.Data.Data = [][]byte{utils.MarshalOrPanic(configEnv)}
+	resp = serve(http.MethodPost, "/etcdraft/dr/promote?channel=mychannel", "")
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, "cannot promote DR consenters: no DR consenters to promote", body["error"])
+}
@@ -0,0 +1,192 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// ConsentersDryRun describes what updating the consenters of a chain to those
+// of a proposed ConsensusType config value would do, without updating them:
+// the membership changes, the reasons the update would be rejected for, if
+// any, and the quorum of the cluster before and after the update.
+type ConsentersDryRun struct {
+	Channel string   `json:"channel"`
+	Valid   bool     `json:"valid"`
+	Errors  []string `json:"errors,omitempty"`
+
+	Added      []string `json:"added,omitempty"`   // endpoints of the consenters added
+	Removed    []uint64 `json:"removed,omitempty"` // raft IDs of the consenters removed
+	Rotated    uint64   `json:"rotated,omitempty"` // raft ID of the consenter whose certificate is rotated
+	Moved      []uint64 `json:"moved,omitempty"`   // raft IDs of the consenters whose endpoint changes
+	ConfChange string   `json:"conf_change,omitempty"`
+
+	Quorum    QuorumImplications `json:"quorum"`
+	NewQuorum QuorumImplications `json:"new_quorum"`
+}
+
+// QuorumImplications describes the quorum of a cluster of consenters. Consenters are
+// counted as reachable unless this node failed to send messages to them. Consenters added
+// to the cluster are not counted as reachable, as they have yet to catch up with it, and
+// neither are consenters whose certificate is rotated, until they reconnect with it.
+type QuorumImplications struct {
+	ClusterSize    int  `json:"cluster_size"`
+	Quorum         int  `json:"quorum"`
+	FaultTolerance int  `json:"fault_tolerance"` // number of consenters which may fail without losing quorum
+	Reachable      int  `json:"reachable"`
+	AtRisk         bool `json:"at_risk"` // fewer consenters than the quorum are reachable
+}
+
+func quorumImplications(clusterSize, reachable int) QuorumImplications {
+	quorum := clusterSize/2 + 1
+	return QuorumImplications{
+		ClusterSize:    clusterSize,
+		Quorum:         quorum,
+		FaultTolerance: clusterSize - quorum,
+		Reachable:      reachable,
+		AtRisk:         reachable < quorum,
+	}
+}
+
+// DryRunConsenters computes what updating the consenters of the chain to those of the
+// given ConsensusType config value would do, as the validation of config updates does.
+func (c *Chain) DryRunConsenters(consensusType *orderer.ConsensusType) ConsentersDryRun {
+	dryRun := ConsentersDryRun{Channel: c.channelID}
+
+	current := c.raftMetadata()
+	reachable := c.reachableConsenters(current.Consenters)
+	dryRun.Quorum = quorumImplications(len(current.Consenters), len(reachable))
+
+	if consensusType.Type != etcdraft.TypeKey {
+		dryRun.Errors = []string{fmt.Sprintf("consensus type %s is not %s", consensusType.Type, etcdraft.TypeKey)}
+		return dryRun
+	}
+	updatedMetadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(consensusType.Metadata, updatedMetadata); err != nil {
+		dryRun.Errors = []string{errors.Wrap(err, "failed to unmarshal updated (new) etcdraft metadata configuration").Error()}
+		return dryRun
+	}
+
+	for _, err := range c.consentersSetErrors(updatedMetadata) {
+		dryRun.Errors = append(dryRun.Errors, err.Error())
+	}
+	changes, err := ComputeMembershipChanges(current, updatedMetadata.Consenters)
+	if err != nil {
+		return dryRun
+	}
+	dryRun.Valid = len(dryRun.Errors) == 0
+
+	for _, consenter := range changes.AddedNodes {
+		dryRun.Added = append(dryRun.Added, fmt.Sprintf("%s:%d", consenter.Host, consenter.Port))
+	}
+	for raftID := range current.Consenters {
+		if _, exists := changes.NewBlockMetadata.Consenters[raftID]; !exists {
+			dryRun.Removed = append(dryRun.Removed, raftID)
+		}
+	}
+	sort.Slice(dryRun.Removed, func(i, j int) bool { return dryRun.Removed[i] < dryRun.Removed[j] })
+	dryRun.Rotated = changes.RotatedNode
+	dryRun.Moved = changes.MovedNodes
+	if changes.ConfChange != nil {
+		switch changes.ConfChange.Type {
+		case raftpb.ConfChangeAddNode:
+			dryRun.ConfChange = fmt.Sprintf("add node %d", changes.ConfChange.NodeID)
+		case raftpb.ConfChangeRemoveNode:
+			dryRun.ConfChange = fmt.Sprintf("remove node %d", changes.ConfChange.NodeID)
+		}
+	}
+
+	var newReachable int
+	for raftID := range changes.NewBlockMetadata.Consenters {
+		if _, isReachable := reachable[raftID]; isReachable && raftID != changes.RotatedNode {
+			newReachable++
+		}
+	}
+	dryRun.NewQuorum = quorumImplications(len(changes.NewBlockMetadata.Consenters), newReachable)
+	return dryRun
+}
+
+// reachableConsenters returns the raft IDs of the given consenters which this node is able
+// to send messages to, along with this node itself if it is one of the consenters.
+func (c *Chain) reachableConsenters(consenters map[uint64]*etcdraft.Consenter) map[uint64]struct{} {
+	reachable := make(map[uint64]struct{}, len(consenters))
+	for raftID := range consenters {
+		if !c.Node.isUnreachable(raftID) {
+			reachable[raftID] = struct{}{}
+		}
+	}
+	return reachable
+}
+
+// consentersDryRunHandler computes what updating the consenters of the
+// etcdraft chain of the channel given in the query would do.
+type consentersDryRunHandler struct {
+	consenter *Consenter
+}
+
+// ConsentersDryRunHandler returns a handler serving the ConsentersDryRun of a chain
+// as JSON, for POST requests of the form ?channel=<channel ID>, whose body is a
+// proposed ConsensusType config value. The value is in JSON, as rendered by
+// configtxlator, if the Content-Type of the request is application/json, and is
+// a marshaled protobuf message otherwise.
+func (c *Consenter) ConsentersDryRunHandler() http.Handler {
+	return &consentersDryRunHandler{consenter: c}
+}
+
+func (h *consentersDryRunHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	channelID := req.URL.Query().Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading ConsensusType: %s", err))
+		return
+	}
+	consensusType := &orderer.ConsensusType{}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		err = protolator.DeepUnmarshalJSON(bytes.NewReader(body), consensusType)
+	} else {
+		err = proto.Unmarshal(body, consensusType)
+	}
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("malformed ConsensusType: %s", err))
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(chain.DryRunConsenters(consensusType)); err != nil {
+		h.consenter.Logger.Errorw("failed to encode consenters dry run", "channel", channelID, "error", err)
+	}
+}
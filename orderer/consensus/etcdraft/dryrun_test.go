@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/common/tools/protolator"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus/mocks"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chainsByID map[string]*multichannel.ChainSupport
+
+func (c chainsByID) GetChain(chainID string) *multichannel.ChainSupport {
+	return c[chainID]
+}
+
+func (c chainsByID) ChainIDs() []string {
+	var ids []string
+	for id := range c {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestDryRunConsenters(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	consenters := map[uint64]*etcdraft.Consenter{
+		1: newConsenter(t, ca, "orderer1"),
+		2: newConsenter(t, ca, "orderer2"),
+		3: newConsenter(t, ca, "orderer3"),
+	}
+
+	support := &mocks.FakeConsenterSupport{}
+	support.SharedConfigReturns(&mockconfig.Orderer{
+		ConsensusMetadataVal: utils.MarshalOrPanic(&etcdraft.ConfigMetadata{
+			Consenters: []*etcdraft.Consenter{consenters[1], consenters[2], consenters[3]},
+		}),
+	})
+	chain := &Chain{
+		channelID: "mychannel",
+		Node:      &node{unreachable: map[uint64]struct{}{3: {}}},
+		clock:     clock.NewClock(),
+		support:   support,
+	}
+	chain.blockMetadata.Store(&etcdraft.BlockMetadata{Consenters: consenters, NextConsenterId: 4})
+
+	consensusType := func(consenters ...*etcdraft.Consenter) *orderer.ConsensusType {
+		return &orderer.ConsensusType{
+			Type:     "etcdraft",
+			Metadata: utils.MarshalOrPanic(&etcdraft.ConfigMetadata{Consenters: consenters}),
+		}
+	}
+
+	t.Run("adding a consenter", func(t *testing.T) {
+		added := newConsenter(t, ca, "orderer4")
+		dryRun := chain.DryRunConsenters(consensusType(consenters[1], consenters[2], consenters[3], added))
+		assert.Equal(t, ConsentersDryRun{
+			Channel:    "mychannel",
+			Valid:      true,
+			Added:      []string{"orderer4:7050"},
+			ConfChange: "add node 4",
+			Quorum:     QuorumImplications{ClusterSize: 3, Quorum: 2, FaultTolerance: 1, Reachable: 2},
+			NewQuorum:  QuorumImplications{ClusterSize: 4, Quorum: 3, FaultTolerance: 1, Reachable: 2, AtRisk: true},
+		}, dryRun)
+	})
+
+	t.Run("removing a consenter", func(t *testing.T) {
+		dryRun := chain.DryRunConsenters(consensusType(consenters[1], consenters[2]))
+		assert.Equal(t, ConsentersDryRun{
+			Channel:    "mychannel",
+			Valid:      true,
+			Removed:    []uint64{3},
+			ConfChange: "remove node 3",
+			Quorum:     QuorumImplications{ClusterSize: 3, Quorum: 2, FaultTolerance: 1, Reachable: 2},
+			NewQuorum:  QuorumImplications{ClusterSize: 2, Quorum: 2, FaultTolerance: 0, Reachable: 2},
+		}, dryRun)
+	})
+
+	t.Run("rotating the certificate of a consenter", func(t *testing.T) {
+		rotated := newConsenter(t, ca, "orderer2")
+		dryRun := chain.DryRunConsenters(consensusType(consenters[1], rotated, consenters[3]))
+		assert.True(t, dryRun.Valid)
+		assert.Equal(t, uint64(2), dryRun.Rotated)
+		assert.Empty(t, dryRun.Removed)
+		assert.Empty(t, dryRun.ConfChange)
+		assert.Equal(t, QuorumImplications{ClusterSize: 3, Quorum: 2, FaultTolerance: 1, Reachable: 1, AtRisk: true}, dryRun.NewQuorum)
+	})
+
+	t.Run("invalid updates", func(t *testing.T) {
+		dryRun := chain.DryRunConsenters(consensusType(consenters[1], newConsenter(t, ca, "orderer4"), newConsenter(t, ca, "orderer5")))
+		assert.False(t, dryRun.Valid)
+		assert.Equal(t, []string{"update of more than one consenter at a time is not supported, requested changes: add 2 node(s), remove 2 node(s)"}, dryRun.Errors)
+		assert.Equal(t, QuorumImplications{}, dryRun.NewQuorum)
+
+		invalid := proto.Clone(consenters[3]).(*etcdraft.Consenter)
+		invalid.Port = 0
+		dryRun = chain.DryRunConsenters(consensusType(consenters[1], consenters[2], invalid, consenters[1]))
+		assert.False(t, dryRun.Valid)
+		require.Len(t, dryRun.Errors, 2)
+		assert.Contains(t, dryRun.Errors[0], "duplicate")
+		assert.Equal(t, "invalid consenter orderer3:0: consenter has no port", dryRun.Errors[1])
+
+		dryRun = chain.DryRunConsenters(&orderer.ConsensusType{Type: "kafka"})
+		assert.Equal(t, []string{"consensus type kafka is not etcdraft"}, dryRun.Errors)
+		assert.Equal(t, QuorumImplications{ClusterSize: 3, Quorum: 2, FaultTolerance: 1, Reachable: 2}, dryRun.Quorum)
+	})
+
+	t.Run("handler", func(t *testing.T) {
+		chains := chainsByID{"mychannel": &multichannel.ChainSupport{Chain: chain}}
+		handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).ConsentersDryRunHandler()
+
+		serve := func(method, target, contentType string, body []byte) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, target, bytes.NewReader(body))
+			req.Header.Set("Content-Type", contentType)
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			return resp
+		}
+
+		removal := consensusType(consenters[1], consenters[2])
+		var jsonRemoval bytes.Buffer
+		require.NoError(t, protolator.DeepMarshalJSON(&jsonRemoval, removal))
+
+		for contentType, body := range map[string][]byte{
+			"application/octet-stream": utils.MarshalOrPanic(removal),
+			"application/json":         jsonRemoval.Bytes(),
+		} {
+			resp := serve(http.MethodPost, "/etcdraft/consenters/dryrun?channel=mychannel", contentType, body)
+			require.Equal(t, http.StatusOK, resp.Code, contentType)
+			var dryRun ConsentersDryRun
+			require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &dryRun))
+			assert.Equal(t, chain.DryRunConsenters(removal), dryRun, contentType)
+		}
+
+		resp := serve(http.MethodPost, "/etcdraft/consenters/dryrun?channel=mychannel", "application/json", []byte("{"))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serve(http.MethodPost, "/etcdraft/consenters/dryrun?channel=nochannel", "", nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = serve(http.MethodPost, "/etcdraft/consenters/dryrun", "", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serve(http.MethodGet, "/etcdraft/consenters/dryrun?channel=mychannel", "", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	})
+}
@@ -0,0 +1,124 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+)
+
+// maxElectionDampening bounds the factor the election timeout is multiplied by.
+const maxElectionDampening = 8
+
+// electionDampener dampens election storms, in which a flapping network
+// causes repeated elections that compound into unavailability. Once the
+// elections observed within the window reach the threshold, the effective
+// election timeout of the node is doubled for a cool-down period as long as
+// the window, by skipping raft ticks while the node does not lead. Storms
+// observed during the cool-down double the timeout again, up to a bound,
+// and extend the cool-down. The leader keeps ticking at the usual rate,
+// hence its heartbeats are not slowed down.
+type electionDampener struct {
+	logger    *flogging.FabricLogger
+	clock     clock.Clock
+	threshold int
+	window    time.Duration
+	leading   func() bool
+	metrics   *Metrics
+
+	lock      sync.Mutex
+	elections []time.Time // observed within the window
+	factor    int         // of the election timeout, 1 unless dampened
+	until     time.Time   // end of the cool-down
+	ticks     int         // since the last tick allowed while dampened
+}
+
+// newElectionDampener returns an electionDampener,
+// or nil if elections are not to be dampened.
+func newElectionDampener(logger *flogging.FabricLogger, clock clock.Clock, threshold int, window time.Duration, leading func() bool, metrics *Metrics) *electionDampener {
+	if threshold <= 0 || window <= 0 {
+		return nil
+	}
+	metrics.ElectionTimeoutFactor.Set(1)
+	return &electionDampener{
+		logger:    logger,
+		clock:     clock,
+		threshold: threshold,
+		window:    window,
+		leading:   leading,
+		metrics:   metrics,
+		factor:    1,
+	}
+}
+
+// observe records an election, and dampens elections
+// if it completes a storm.
+func (ed *electionDampener) observe() {
+	if ed == nil {
+		return
+	}
+
+	ed.lock.Lock()
+	defer ed.lock.Unlock()
+
+	now := ed.clock.Now()
+	elections := ed.elections[:0]
+	for _, t := range ed.elections {
+		if now.Sub(t) < ed.window {
+			elections = append(elections, t)
+		}
+	}
+	ed.elections = append(elections, now)
+	if len(ed.elections) < ed.threshold {
+		return
+	}
+
+	ed.elections = nil
+	ed.until = now.Add(ed.window)
+	if ed.factor < maxElectionDampening {
+		ed.factor *= 2
+	}
+	ed.logger.Warnf("Observed %d elections within %s, multiplying the election timeout by %d until %s",
+		ed.threshold, ed.window, ed.factor, ed.until.Format(time.RFC3339))
+	ed.metrics.ElectionStorms.Add(1)
+	ed.metrics.ElectionTimeoutFactor.Set(float64(ed.factor))
+}
+
+// allowTick returns whether a raft tick is to be processed,
+// which all ticks are, unless elections are dampened.
+func (ed *electionDampener) allowTick() bool {
+	if ed == nil {
+		return true
+	}
+
+	ed.lock.Lock()
+	defer ed.lock.Unlock()
+
+	if ed.factor == 1 {
+		return true
+	}
+	if !ed.clock.Now().Before(ed.until) {
+		ed.logger.Infof("Election storm cooled down, restoring the election timeout")
+		ed.factor = 1
+		ed.ticks = 0
+		ed.metrics.ElectionTimeoutFactor.Set(1)
+		return true
+	}
+	if ed.leading() {
+		return true
+	}
+
+	ed.ticks++
+	if ed.ticks < ed.factor {
+		return false
+	}
+	ed.ticks = 0
+	return true
+}
@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElectionDampener(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	storms := &metricsfakes.Counter{}
+	factor := &metricsfakes.Gauge{}
+	metrics := &Metrics{ElectionStorms: storms, ElectionTimeoutFactor: factor}
+	leading := false
+
+	// allowedTicks returns how many of n ticks are allowed
+	allowedTicks := func(ed *electionDampener, n int) int {
+		var allowed int
+		for i := 0; i < n; i++ {
+			if ed.allowTick() {
+				allowed++
+			}
+		}
+		return allowed
+	}
+
+	assert.Nil(t, newElectionDampener(flogging.MustGetLogger("test"), clock, 0, time.Minute, nil, metrics))
+	assert.Nil(t, newElectionDampener(flogging.MustGetLogger("test"), clock, 3, 0, nil, metrics))
+	var disabled *electionDampener
+	disabled.observe()
+	assert.True(t, disabled.allowTick())
+	assert.Equal(t, 0, factor.SetCallCount())
+
+	ed := newElectionDampener(flogging.MustGetLogger("test"), clock, 3, time.Minute, func() bool { return leading }, metrics)
+	assert.Equal(t, float64(1), factor.SetArgsForCall(0))
+
+	// elections spread beyond the window are not a storm
+	for i := 0; i < 5; i++ {
+		ed.observe()
+		clock.Increment(31 * time.Second)
+	}
+	assert.Equal(t, 0, storms.AddCallCount())
+	assert.Equal(t, 10, allowedTicks(ed, 10))
+
+	// a storm doubles the election timeout of followers
+	clock.Increment(time.Minute)
+	ed.observe()
+	ed.observe()
+	ed.observe()
+	assert.Equal(t, 1, storms.AddCallCount())
+	assert.Equal(t, float64(2), factor.SetArgsForCall(factor.SetCallCount()-1))
+	assert.Equal(t, 5, allowedTicks(ed, 10))
+
+	leading = true
+	assert.Equal(t, 10, allowedTicks(ed, 10), "the leader is not dampened")
+	leading = false
+
+	// storms during the cool-down double it again, up to a bound
+	for i := 0; i < 9; i++ {
+		ed.observe()
+	}
+	assert.Equal(t, 4, storms.AddCallCount())
+	assert.Equal(t, float64(maxElectionDampening), factor.SetArgsForCall(factor.SetCallCount()-1))
+	assert.Equal(t, 2, allowedTicks(ed, 16))
+
+	// the cool-down is extended by the last storm
+	clock.Increment(59 * time.Second)
+	assert.Equal(t, 2, allowedTicks(ed, 16))
+
+	clock.Increment(time.Second)
+	assert.Equal(t, 16, allowedTicks(ed, 16))
+	assert.Equal(t, float64(1), factor.SetArgsForCall(factor.SetCallCount()-1))
+}
@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// OrderingEstimate estimates the time it takes the leader of a channel to order
+// a transaction broadcast to it, so that load balancers in front of the orderers
+// can route Broadcast to the least loaded consenter.
+type OrderingEstimate struct {
+	Channel string `json:"channel"`
+	// EstimatedTimeToOrder is the estimated time, in seconds, until a
+	// transaction broadcast now is committed in a block.
+	EstimatedTimeToOrder float64 `json:"estimated_time_to_order"`
+	// QueuedEnvelopes are the envelopes ordered ahead of the transaction, in the
+	// batch pending in the block cutter and in the blocks in flight.
+	QueuedEnvelopes int `json:"queued_envelopes"`
+	// CommitRate is the recent rate of committed envelopes per second.
+	CommitRate   float64 `json:"commit_rate"`
+	BatchTimeout string  `json:"batch_timeout"`
+}
+
+// OrderingEstimate returns the estimated time to order a transaction broadcast
+// now to this node, along with whether it leads the chain. Followers forward
+// transactions to the leader, hence only the leader estimates it.
+func (c *Chain) OrderingEstimate() (OrderingEstimate, bool) {
+	if atomic.LoadUint64(&c.lastKnownLeader) != c.raftID {
+		return OrderingEstimate{}, false
+	}
+
+	c.pendingBatchLock.Lock()
+	pb, inflight := c.pendingBatch, c.pendingEnvelopes
+	c.pendingBatchLock.Unlock()
+
+	var age time.Duration
+	if !pb.StartTime.IsZero() {
+		age = time.Since(pb.StartTime)
+	}
+	var maxMessageCount uint32
+	if batchSize := c.support.SharedConfig().BatchSize(); batchSize != nil {
+		maxMessageCount = batchSize.MaxMessageCount
+	}
+	batchTimeout := c.support.SharedConfig().BatchTimeout()
+	rate := c.admission.commitRate()
+
+	return OrderingEstimate{
+		Channel:              c.channelID,
+		EstimatedTimeToOrder: timeToOrder(batchTimeout, maxMessageCount, pb.Messages, age, inflight, rate).Seconds(),
+		QueuedEnvelopes:      pb.Messages + inflight,
+		CommitRate:           rate,
+		BatchTimeout:         batchTimeout.String(),
+	}, true
+}
+
+// reportOrderingEstimate publishes the estimated time to order
+// as a metric, which is zero unless this node leads the chain.
+func (c *Chain) reportOrderingEstimate() {
+	estimate, _ := c.OrderingEstimate()
+	c.Metrics.EstimatedTimeToOrder.Set(estimate.EstimatedTimeToOrder)
+}
+
+// timeToOrder estimates the time to order a transaction which joins the batch
+// of the given number of envelopes pending for the given age, behind the given
+// number of envelopes in flight, at the given commit rate in envelopes per second.
+// The batch is cut once the batch timeout elapses since its first envelope, or
+// once it fills up, at the commit rate when it is known. The envelopes in flight
+// are committed meanwhile, and the batch follows them. The commit rate is unknown
+// until a couple of blocks are committed, in which case only the wait for the
+// batch to be cut is estimated.
+func timeToOrder(batchTimeout time.Duration, maxMessageCount uint32, pending int, age time.Duration, inflight int, rate float64) time.Duration {
+	cut := batchTimeout
+	if pending > 0 {
+		cut -= age
+	}
+	if cut < 0 {
+		cut = 0
+	}
+	if rate <= 0 {
+		return cut
+	}
+
+	seconds := func(envelopes int) time.Duration {
+		return time.Duration(float64(envelopes) / rate * float64(time.Second))
+	}
+	if maxMessageCount > 0 {
+		fill := seconds(int(maxMessageCount) - pending - 1)
+		if fill < 0 {
+			fill = 0
+		}
+		if fill < cut {
+			cut = fill
+		}
+	}
+	drain := seconds(inflight)
+	if drain < cut {
+		drain = cut
+	}
+	return drain + seconds(pending+1)
+}
+
+// estimateHandler serves the estimated time to order of
+// the etcdraft chains this node leads.
+type estimateHandler struct {
+	consenter *Consenter
+}
+
+// EstimateHandler returns a handler serving the estimated time to order of the
+// etcdraft chains this node leads as a JSON array for GET requests, or of the
+// chain of the channel given in a query of the form ?channel=<channel ID>,
+// which is answered with 503 Service Unavailable unless this node leads it.
+func (c *Consenter) EstimateHandler() http.Handler {
+	return &estimateHandler{consenter: c}
+}
+
+func (h *estimateHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	var body interface{}
+	if channelID := req.URL.Query().Get("channel"); channelID != "" {
+		chain := h.consenter.etcdraftChain(channelID)
+		if chain == nil {
+			sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+			return
+		}
+		estimate, leader := chain.OrderingEstimate()
+		if !leader {
+			sendJSONError(resp, http.StatusServiceUnavailable, fmt.Sprintf("this node does not lead channel %s", channelID))
+			return
+		}
+		body = estimate
+	} else {
+		estimates := []OrderingEstimate{}
+		for _, channelID := range h.consenter.Chains.ChainIDs() {
+			chain := h.consenter.etcdraftChain(channelID)
+			if chain == nil {
+				continue
+			}
+			if estimate, leader := chain.OrderingEstimate(); leader {
+				estimates = append(estimates, estimate)
+			}
+		}
+		sort.Slice(estimates, func(i, j int) bool { return estimates[i].Channel < estimates[j].Channel })
+		body = estimates
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(body); err != nil {
+		h.consenter.Logger.Errorw("failed to encode ordering estimates", "error", err)
+	}
+}
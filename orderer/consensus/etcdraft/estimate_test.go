@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	mockmultichannel "github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeToOrder(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		pending  int
+		age      time.Duration
+		inflight int
+		rate     float64
+		expected time.Duration
+	}{
+		{name: "unknown rate, empty batch", expected: 2 * time.Second},
+		{name: "unknown rate, pending batch", pending: 5, age: 500 * time.Millisecond, expected: 1500 * time.Millisecond},
+		{name: "unknown rate, expired batch", pending: 5, age: 3 * time.Second, expected: 0},
+		// the batch takes 9s to fill at 1 envelope per second, hence it is cut on timeout
+		{name: "idle", rate: 1, expected: 3 * time.Second},
+		// the batch is cut on timeout in 0.1s, after which the envelope in flight is committed
+		{name: "batch expires", pending: 1, age: 1900 * time.Millisecond, inflight: 1, rate: 10, expected: 300 * time.Millisecond},
+		// the batch fills up in 0.4s, while the envelopes in flight take 1s to be committed
+		{name: "loaded", pending: 5, inflight: 10, rate: 10, expected: 1600 * time.Millisecond},
+		{name: "full batch", pending: 10, inflight: 0, rate: 10, expected: 1100 * time.Millisecond},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := timeToOrder(2*time.Second, 10, tc.pending, tc.age, tc.inflight, tc.rate)
+			assert.InDelta(t, tc.expected.Seconds(), actual.Seconds(), 0.001)
+		})
+	}
+}
+
+func TestOrderingEstimate(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	support := &mockmultichannel.ConsenterSupport{
+		SharedConfigVal: &mockconfig.Orderer{
+			BatchTimeoutVal: 2 * time.Second,
+			BatchSizeVal:    &ab.BatchSize{MaxMessageCount: 10},
+		},
+	}
+	estimateGauge := &metricsfakes.Gauge{}
+	chain := &Chain{
+		channelID: "mychannel",
+		raftID:    1,
+		support:   support,
+		clock:     clock,
+		Metrics:   &Metrics{EstimatedTimeToOrder: estimateGauge},
+		admission: &admissionController{clock: clock, capacity: func() float64 { return 100 }},
+	}
+
+	// followers do not estimate the time to order
+	_, leader := chain.OrderingEstimate()
+	assert.False(t, leader)
+	chain.reportOrderingEstimate()
+	assert.Equal(t, float64(0), estimateGauge.SetArgsForCall(0))
+
+	chain.lastKnownLeader = 1
+	estimate, leader := chain.OrderingEstimate()
+	require.True(t, leader)
+	assert.Equal(t, OrderingEstimate{Channel: "mychannel", EstimatedTimeToOrder: 2, BatchTimeout: "2s"}, estimate)
+
+	// 10 envelopes per second are committed, while 5 are pending and 10 are in flight
+	chain.admission.committed(10)
+	clock.Increment(time.Second)
+	chain.admission.committed(10)
+	chain.pendingBatch = blockcutter.PendingBatch{Messages: 5, StartTime: time.Now()}
+	chain.pendingEnvelopes = 10
+	estimate, leader = chain.OrderingEstimate()
+	require.True(t, leader)
+	assert.Equal(t, 15, estimate.QueuedEnvelopes)
+	assert.Equal(t, float64(10), estimate.CommitRate)
+	assert.InDelta(t, 1.6, estimate.EstimatedTimeToOrder, 0.01)
+	chain.reportOrderingEstimate()
+	assert.InDelta(t, 1.6, estimateGauge.SetArgsForCall(1), 0.01)
+
+	t.Run("handler", func(t *testing.T) {
+		follower := &Chain{channelID: "follower", raftID: 2, lastKnownLeader: 1}
+		chains := chainsByID{
+			"mychannel": &multichannel.ChainSupport{Chain: chain},
+			"follower":  &multichannel.ChainSupport{Chain: follower},
+		}
+		handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).EstimateHandler()
+
+		serve := func(method, target string) *httptest.ResponseRecorder {
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, httptest.NewRequest(method, target, nil))
+			return resp
+		}
+
+		resp := serve(http.MethodGet, "/etcdraft/estimate")
+		require.Equal(t, http.StatusOK, resp.Code)
+		var estimates []OrderingEstimate
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &estimates))
+		require.Len(t, estimates, 1)
+		assert.Equal(t, "mychannel", estimates[0].Channel)
+
+		resp = serve(http.MethodGet, "/etcdraft/estimate?channel=mychannel")
+		require.Equal(t, http.StatusOK, resp.Code)
+		var estimate OrderingEstimate
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &estimate))
+		assert.Equal(t, 15, estimate.QueuedEnvelopes)
+
+		resp = serve(http.MethodGet, "/etcdraft/estimate?channel=follower")
+		assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+		resp = serve(http.MethodGet, "/etcdraft/estimate?channel=nochannel")
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = serve(http.MethodPost, "/etcdraft/estimate")
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	})
+}
@@ -0,0 +1,711 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package etcdrafttest provides an in-memory cluster of etcdraft chains,
+// which allows to exercise elections, network partitions and
+// reconfigurations without running actual orderers.
+package etcdrafttest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/disabled"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/mocks"
+	consensusmocks "github.com/hyperledger/fabric/orderer/consensus/mocks"
+	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/common/blockcutter"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	raftprotos "github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/mock"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+const (
+	// TickInterval is the interval of a raft tick. Ticks are driven
+	// by the fake clock of each node.
+	TickInterval = time.Second
+	// ElectionTick is the number of ticks without a leader after
+	// which a follower campaigns.
+	ElectionTick = 10
+	// HeartbeatTick is the number of ticks between heartbeats of a leader.
+	HeartbeatTick = 1
+
+	// Timeout bounds how long cluster operations wait for the nodes
+	// to reach the expected state.
+	Timeout = 10 * time.Second
+
+	pollInterval = 10 * time.Millisecond
+)
+
+// Node is a member of a Cluster. Its Options may be altered
+// before the node is initialized.
+type Node struct {
+	ID      uint64
+	Options etcdraft.Options
+
+	Support *consensusmocks.FakeConsenterSupport
+	Cutter  *mockblockcutter.Receiver
+	Clock   *fakeclock.FakeClock
+	Storage *raft.MemoryStorage
+
+	*etcdraft.Chain
+
+	rpc          *mocks.FakeRPC
+	configurator *mocks.Configurator
+	puller       *mocks.FakeBlockPuller
+	started      bool
+
+	// ledger holds the blocks written by the chain
+	ledgerLock            sync.RWMutex
+	ledger                map[uint64]*common.Block
+	ledgerHeight          uint64
+	lastConfigBlockNumber uint64
+}
+
+// Block returns the block with the given number from the ledger of the node,
+// or nil if the node has not written it yet.
+func (n *Node) Block(number uint64) *common.Block {
+	n.ledgerLock.RLock()
+	defer n.ledgerLock.RUnlock()
+	return n.ledger[number]
+}
+
+// Height returns the height of the ledger of the node.
+func (n *Node) Height() uint64 {
+	n.ledgerLock.RLock()
+	defer n.ledgerLock.RUnlock()
+	return n.ledgerHeight
+}
+
+func (n *Node) lastConfigBlock() uint64 {
+	n.ledgerLock.RLock()
+	defer n.ledgerLock.RUnlock()
+	return n.lastConfigBlockNumber
+}
+
+func (n *Node) writeBlock(b *common.Block, meta []byte, isConfig bool) {
+	n.ledgerLock.Lock()
+	defer n.ledgerLock.Unlock()
+
+	b = proto.Clone(b).(*common.Block)
+	b.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&common.Metadata{Value: meta})
+
+	if isConfig {
+		n.lastConfigBlockNumber = b.Header.Number
+	}
+	b.Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(&common.LastConfig{Index: n.lastConfigBlockNumber}),
+	})
+
+	n.ledger[b.Header.Number] = b
+	if n.ledgerHeight < b.Header.Number+1 {
+		n.ledgerHeight = b.Header.Number + 1
+	}
+}
+
+// Cluster is a set of etcdraft chains of the same channel that communicate
+// with each other in memory. Links between the nodes follow the consenter
+// set the chains configure the communication layer with, and nodes can be
+// disconnected to simulate network partitions.
+type Cluster struct {
+	Channel string
+
+	dataDir      string
+	batchTimeout time.Duration
+	tlsCA        tlsgen.CA
+
+	lock   sync.RWMutex
+	leader uint64
+	nodes  map[uint64]*Node
+	// links[from][to] is true if from has configured communication with to.
+	// A link must be configured on both sides to allow messages to pass.
+	links map[uint64]map[uint64]bool
+	// connectivity tells whether a node is connected to the network
+	connectivity map[uint64]bool
+}
+
+// NewCluster creates a cluster of the given size, with raft IDs
+// ranging from 1 to size. Data of the nodes is stored in dataDir.
+// The chains are created upon Init and started upon Start.
+func NewCluster(channel string, dataDir string, size int, batchTimeout time.Duration) (*Cluster, error) {
+	tlsCA, err := tlsgen.NewCA()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create TLS CA")
+	}
+
+	c := &Cluster{
+		Channel:      channel,
+		dataDir:      dataDir,
+		batchTimeout: batchTimeout,
+		tlsCA:        tlsCA,
+		nodes:        make(map[uint64]*Node),
+		links:        make(map[uint64]map[uint64]bool),
+		connectivity: make(map[uint64]bool),
+	}
+
+	metadata := &raftprotos.BlockMetadata{
+		Consenters:      make(map[uint64]*raftprotos.Consenter),
+		NextConsenterId: uint64(size) + 1,
+	}
+	for id := uint64(1); id <= uint64(size); id++ {
+		consenter, err := c.NewConsenter()
+		if err != nil {
+			return nil, err
+		}
+		metadata.Consenters[id] = consenter
+	}
+
+	for id := range metadata.Consenters {
+		genesis := &common.Block{
+			Header:   &common.BlockHeader{},
+			Data:     &common.BlockData{Data: [][]byte{[]byte("genesis")}},
+			Metadata: &common.BlockMetadata{Metadata: make([][]byte, 4)},
+		}
+		genesis.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&common.Metadata{
+			Value: utils.MarshalOrPanic(metadata),
+		})
+		n, err := c.newNode(id, proto.Clone(metadata).(*raftprotos.BlockMetadata), []*common.Block{genesis})
+		if err != nil {
+			return nil, err
+		}
+		c.addNode(n)
+	}
+
+	return c, nil
+}
+
+// NewConsenter returns a consenter with TLS certificates issued by the CA of the cluster.
+func (c *Cluster) NewConsenter() (*raftprotos.Consenter, error) {
+	serverCert, err := c.tlsCA.NewServerCertKeyPair("localhost")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create server certificate")
+	}
+	clientCert, err := c.tlsCA.NewClientCertKeyPair()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create client certificate")
+	}
+	return &raftprotos.Consenter{
+		Host:          "localhost",
+		Port:          7050,
+		ServerTlsCert: serverCert.Cert,
+		ClientTlsCert: clientCert.Cert,
+	}, nil
+}
+
+// Consenters returns the consenter set of the last config block
+// written by the leader, ordered by raft ID.
+func (c *Cluster) Consenters() ([]*raftprotos.Consenter, error) {
+	leader := c.Node(c.Leader())
+	if leader == nil {
+		return nil, errors.New("cluster has no leader")
+	}
+
+	metadata, err := etcdraft.ReadBlockMetadata(&common.Metadata{Value: raftMetadata(leader.Block(leader.lastConfigBlock()))}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read raft metadata from last config block")
+	}
+
+	var ids []uint64
+	for id := range metadata.Consenters {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var consenters []*raftprotos.Consenter
+	for _, id := range ids {
+		consenters = append(consenters, metadata.Consenters[id])
+	}
+	return consenters, nil
+}
+
+// Envelope returns a normal transaction for the channel of the cluster
+// carrying the given data.
+func (c *Cluster) Envelope(data []byte) *common.Envelope {
+	return &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+					Type:      int32(common.HeaderType_MESSAGE),
+					ChannelId: c.Channel,
+				}),
+			},
+			Data: data,
+		}),
+	}
+}
+
+// ConsenterUpdate returns a config transaction for the channel of the cluster
+// that replaces the consenter set with the given consenters.
+func (c *Cluster) ConsenterUpdate(consenters []*raftprotos.Consenter) *common.Envelope {
+	configUpdate := &common.ConfigUpdate{
+		ChannelId: c.Channel,
+		ReadSet:   &common.ConfigGroup{},
+		WriteSet: &common.ConfigGroup{
+			Groups: map[string]*common.ConfigGroup{
+				"Orderer": {
+					Values: map[string]*common.ConfigValue{
+						"ConsensusType": {
+							Version: 1,
+							Value: utils.MarshalOrPanic(&orderer.ConsensusType{
+								Metadata: utils.MarshalOrPanic(&raftprotos.ConfigMetadata{Consenters: consenters}),
+							}),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+					Type:      int32(common.HeaderType_CONFIG),
+					ChannelId: c.Channel,
+				}),
+			},
+			Data: utils.MarshalOrPanic(&common.ConfigEnvelope{
+				LastUpdate: &common.Envelope{
+					Payload: utils.MarshalOrPanic(&common.Payload{
+						Header: &common.Header{
+							ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+								Type:      int32(common.HeaderType_CONFIG_UPDATE),
+								ChannelId: c.Channel,
+							}),
+						},
+						Data: utils.MarshalOrPanic(&common.ConfigUpdateEnvelope{
+							ConfigUpdate: utils.MarshalOrPanic(configUpdate),
+						}),
+					}),
+				},
+			}),
+		}),
+	}
+}
+
+// AddNode creates a node that joins the cluster with the given raft ID. The node
+// starts off with the ledger of the current leader, whose last config block
+// must add it to the consenter set. The node still needs to be initialized and started.
+func (c *Cluster) AddNode(id uint64) (*Node, error) {
+	leader := c.Node(c.Leader())
+	if leader == nil {
+		return nil, errors.New("cluster has no leader")
+	}
+
+	var blocks []*common.Block
+	for i := uint64(0); i < leader.Height(); i++ {
+		blocks = append(blocks, leader.Block(i))
+	}
+
+	lastConfig := leader.Block(leader.lastConfigBlock())
+	metadata, err := etcdraft.ReadBlockMetadata(&common.Metadata{Value: raftMetadata(lastConfig)}, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read raft metadata from last config block")
+	}
+	if _, exists := metadata.Consenters[id]; !exists {
+		return nil, errors.Errorf("node %d is not a consenter in config block %d", id, lastConfig.Header.Number)
+	}
+
+	n, err := c.newNode(id, metadata, blocks)
+	if err != nil {
+		return nil, err
+	}
+	n.lastConfigBlockNumber = lastConfig.Header.Number
+	c.addNode(n)
+	return n, nil
+}
+
+// raftMetadata returns the raft BlockMetadata stored in the given block, serialized.
+func raftMetadata(block *common.Block) []byte {
+	m, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_ORDERER)
+	if err != nil {
+		return nil
+	}
+	return m.Value
+}
+
+func (c *Cluster) newNode(id uint64, metadata *raftprotos.BlockMetadata, blocks []*common.Block) (*Node, error) {
+	dir, err := ioutil.TempDir(c.dataDir, fmt.Sprintf("node-%d-", id))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create data directory")
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	storage := raft.NewMemoryStorage()
+
+	n := &Node{
+		ID: id,
+		Options: etcdraft.Options{
+			RaftID:          id,
+			Clock:           clock,
+			TickInterval:    TickInterval,
+			ElectionTick:    ElectionTick,
+			HeartbeatTick:   HeartbeatTick,
+			MaxSizePerMsg:   1024 * 1024,
+			MaxInflightMsgs: 256,
+			BlockMetadata:   metadata,
+			Logger:          flogging.MustGetLogger("orderer.consensus.etcdraft.etcdrafttest"),
+			MemoryStorage:   storage,
+			WALDir:          path.Join(dir, "wal"),
+			SnapDir:         path.Join(dir, "snapshot"),
+			Metrics:         etcdraft.NewMetrics(&disabled.Provider{}),
+		},
+		Support:      &consensusmocks.FakeConsenterSupport{},
+		Cutter:       mockblockcutter.NewReceiver(),
+		Clock:        clock,
+		Storage:      storage,
+		rpc:          &mocks.FakeRPC{},
+		configurator: &mocks.Configurator{},
+		puller:       &mocks.FakeBlockPuller{},
+		ledger:       make(map[uint64]*common.Block),
+	}
+
+	for _, b := range blocks {
+		n.ledger[b.Header.Number] = b
+	}
+	n.ledgerHeight = uint64(len(blocks))
+
+	close(n.Cutter.Block)
+	n.Support.ChainIDReturns(c.Channel)
+	n.Support.BlockCutterReturns(n.Cutter)
+	n.Support.SharedConfigReturns(&mockconfig.Orderer{
+		BatchTimeoutVal: c.batchTimeout,
+		CapabilitiesVal: &mockconfig.OrdererCapabilities{},
+	})
+	n.Support.WriteBlockStub = func(b *common.Block, meta []byte) { n.writeBlock(b, meta, false) }
+	n.Support.WriteConfigBlockStub = func(b *common.Block, meta []byte) { n.writeBlock(b, meta, true) }
+	n.Support.HeightStub = n.Height
+	n.Support.BlockStub = n.Block
+
+	return n, nil
+}
+
+func (c *Cluster) addNode(n *Node) {
+	n.rpc.SendConsensusStub = func(dest uint64, msg *orderer.ConsensusRequest) error {
+		target, err := c.route(n.ID, dest)
+		if err != nil {
+			return err
+		}
+		go target.Consensus(msg, n.ID)
+		return nil
+	}
+
+	n.rpc.SendSubmitStub = func(dest uint64, msg *orderer.SubmitRequest) error {
+		target, err := c.route(n.ID, dest)
+		if err != nil {
+			return err
+		}
+		go target.Submit(msg, n.ID)
+		return nil
+	}
+
+	n.rpc.SendSubmitBatchStub = func(dest uint64, batch *orderer.SubmitBatch) error {
+		target, err := c.route(n.ID, dest)
+		if err != nil {
+			return err
+		}
+		go func() {
+			for _, msg := range batch.Requests {
+				target.Submit(msg, n.ID)
+			}
+		}()
+		return nil
+	}
+
+	n.puller.PullBlockStub = func(i uint64) *common.Block {
+		leader := c.Node(c.Leader())
+		if leader == nil {
+			return nil
+		}
+		return leader.Block(i)
+	}
+
+	n.puller.HeightsByEndpointsStub = func() (map[string]uint64, error) {
+		leader := c.Node(c.Leader())
+		if leader == nil {
+			return nil, errors.New("ledger not available")
+		}
+		return map[string]uint64{"leader": leader.Height()}, nil
+	}
+
+	n.configurator.On("Configure", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		links := make(map[uint64]bool)
+		for _, node := range args.Get(1).([]cluster.RemoteNode) {
+			links[node.ID] = true
+		}
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.links[n.ID] = links
+	})
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.nodes[n.ID] = n
+	c.connectivity[n.ID] = true // nodes are connected by default
+}
+
+// route returns the node messages sent from one node to another are delivered to.
+func (c *Cluster) route(from, to uint64) (*Node, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if !c.links[from][to] || !c.links[to][from] {
+		return nil, errors.New("connection refused")
+	}
+	if !c.connectivity[from] || !c.connectivity[to] {
+		return nil, errors.New("connection lost")
+	}
+	return c.nodes[to], nil
+}
+
+// Node returns the node with the given raft ID, or nil if there is none.
+func (c *Cluster) Node(id uint64) *Node {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.nodes[id]
+}
+
+// IDs returns the raft IDs of all nodes of the cluster in ascending order.
+func (c *Cluster) IDs() []uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	var ids []uint64
+	for id := range c.nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Leader returns the raft ID of the node last elected with Elect.
+func (c *Cluster) Leader() uint64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.leader
+}
+
+// Init creates the chains of the given nodes, or of all nodes if none are given.
+func (c *Cluster) Init(ids ...uint64) error {
+	for _, n := range c.nodesOf(ids) {
+		ch, err := etcdraft.NewChain(
+			n.Support,
+			n.Options,
+			n.configurator,
+			n.rpc,
+			func() (etcdraft.BlockPuller, error) { return n.puller, nil },
+			nil,
+		)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create chain of node %d", n.ID)
+		}
+		n.Chain = ch
+	}
+	return nil
+}
+
+// Start starts the chains of the given nodes, or of all nodes if none
+// are given, and waits for them to become ready to serve requests.
+func (c *Cluster) Start(ids ...uint64) error {
+	for _, n := range c.nodesOf(ids) {
+		n.Start()
+
+		// A node of a new channel bootstraps by proposing ConfChanges to add the
+		// consenters, and refuses to campaign until they are consumed. Wait for
+		// them to be stored before the clock is ticked. Nodes joining an existing
+		// channel receive their raft log from the leader instead.
+		if n.Height() == 1 {
+			err := eventually(func() bool {
+				_, err := n.Storage.Entries(1, 1, 1)
+				return err == nil
+			})
+			if err != nil {
+				return errors.Errorf("node %d did not bootstrap", n.ID)
+			}
+		}
+		if err := eventually(func() bool { return n.WaitReady() == nil }); err != nil {
+			return errors.Errorf("node %d did not become ready", n.ID)
+		}
+
+		c.lock.Lock()
+		n.started = true
+		c.lock.Unlock()
+	}
+	return nil
+}
+
+// Stop halts the chains of the given nodes, or of all nodes if none are given.
+func (c *Cluster) Stop(ids ...uint64) {
+	for _, n := range c.nodesOf(ids) {
+		c.lock.Lock()
+		started := n.started
+		n.started = false
+		c.lock.Unlock()
+
+		if started {
+			n.Halt()
+		}
+	}
+}
+
+// Connect connects the given node to the network.
+func (c *Cluster) Connect(id uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.connectivity[id] = true
+}
+
+// Disconnect partitions the given node from the rest of the network.
+func (c *Cluster) Disconnect(id uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.connectivity[id] = false
+}
+
+// Elect deterministically elects the given node as leader, and waits
+// for the other started and reachable nodes to follow it.
+func (c *Cluster) Elect(id uint64) error {
+	n := c.Node(id)
+	if n == nil {
+		return errors.Errorf("node %d does not exist", id)
+	}
+
+	// An artificial MsgTimeoutNow emulates a leadership transfer.
+	timeoutNow := utils.MarshalOrPanic(&raftpb.Message{Type: raftpb.MsgTimeoutNow})
+	err := eventually(func() bool {
+		if leads(n, id) {
+			return true
+		}
+		n.Consensus(&orderer.ConsensusRequest{Payload: timeoutNow}, 0)
+		return false
+	})
+	if err != nil {
+		return errors.Errorf("node %d was not elected", id)
+	}
+
+	c.lock.Lock()
+	c.leader = id
+	c.lock.Unlock()
+
+	for _, follower := range c.reachable(id) {
+		if err := eventually(func() bool { return follows(follower, id) }); err != nil {
+			return errors.Errorf("node %d does not follow leader %d", follower.ID, id)
+		}
+	}
+	return nil
+}
+
+// Join connects the given node to the network and ticks the leader until the
+// node follows it and has caught up with its raft log.
+func (c *Cluster) Join(id uint64) error {
+	c.Connect(id)
+
+	n, leader := c.Node(id), c.Node(c.Leader())
+	if n == nil || leader == nil {
+		return errors.Errorf("node %d or leader does not exist", id)
+	}
+
+	err := eventually(func() bool {
+		leaderIndex, _ := leader.Storage.LastIndex()
+		index, _ := n.Storage.LastIndex()
+		if follows(n, leader.ID) && index == leaderIndex {
+			return true
+		}
+		// tick the leader so that it sends out a heartbeat
+		leader.Clock.Increment(TickInterval)
+		return false
+	})
+	if err != nil {
+		return errors.Errorf("node %d did not catch up with leader %d", id, leader.ID)
+	}
+	return nil
+}
+
+// VerifyStateHashes compares the state hashes of the block with the given
+// number across all nodes. Nodes need to have the state hash enabled.
+func (c *Cluster) VerifyStateHashes(number uint64) error {
+	blocks := make(map[uint64]*common.Block)
+	for _, n := range c.nodesOf(nil) {
+		blocks[n.ID] = n.Block(number)
+	}
+	return etcdraft.VerifyStateHashes(blocks)
+}
+
+// Tick advances the clocks of the given nodes, or of all nodes if none
+// are given, by a single tick.
+func (c *Cluster) Tick(ids ...uint64) {
+	for _, n := range c.nodesOf(ids) {
+		n.Clock.Increment(TickInterval)
+	}
+}
+
+func (c *Cluster) nodesOf(ids []uint64) []*Node {
+	if len(ids) == 0 {
+		ids = c.IDs()
+	}
+
+	var nodes []*Node
+	for _, id := range ids {
+		if n := c.Node(id); n != nil {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// reachable returns the started nodes that are connected with the given node.
+func (c *Cluster) reachable(id uint64) []*Node {
+	var nodes []*Node
+	for _, n := range c.nodesOf(nil) {
+		if n.ID == id {
+			continue
+		}
+		c.lock.RLock()
+		ok := n.started && c.connectivity[n.ID] && c.connectivity[id] && c.links[n.ID][id] && c.links[id][n.ID]
+		c.lock.RUnlock()
+		if ok {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+func leads(n *Node, id uint64) bool {
+	s := n.Node.Status()
+	return s.Lead == id && s.RaftState == raft.StateLeader
+}
+
+func follows(n *Node, id uint64) bool {
+	s := n.Node.Status()
+	return s.Lead == id && s.RaftState == raft.StateFollower
+}
+
+func eventually(condition func() bool) error {
+	deadline := time.Now().Add(Timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return errors.New("timed out")
+		}
+		time.Sleep(pollInterval)
+	}
+	return nil
+}
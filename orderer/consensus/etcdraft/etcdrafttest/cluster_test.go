@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdrafttest_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/etcdrafttest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitHeight(t *testing.T, c *etcdrafttest.Cluster, height uint64, ids ...uint64) {
+	for _, id := range ids {
+		n := c.Node(id)
+		deadline := time.Now().Add(etcdrafttest.Timeout)
+		for n.Height() != height {
+			require.True(t, time.Now().Before(deadline), "node %d did not reach height %d", id, height)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdrafttest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := etcdrafttest.NewCluster("foo", dir, 3, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2, 3}, c.IDs())
+
+	require.NoError(t, c.Init())
+	require.NoError(t, c.Start())
+	defer c.Stop()
+
+	require.NoError(t, c.Elect(1))
+	assert.Equal(t, uint64(1), c.Leader())
+
+	leader := c.Node(1)
+	leader.Cutter.CutNext = true
+	require.NoError(t, leader.Order(c.Envelope([]byte("tx-1")), 0))
+	waitHeight(t, c, 2, 1, 2, 3)
+
+	// a partitioned node catches up once it rejoins
+	c.Disconnect(3)
+	require.NoError(t, leader.Order(c.Envelope([]byte("tx-2")), 0))
+	waitHeight(t, c, 3, 1, 2)
+	assert.Equal(t, uint64(2), c.Node(3).Height())
+
+	require.NoError(t, c.Join(3))
+	waitHeight(t, c, 3, 3)
+	assert.Equal(t, leader.Block(2).Header, c.Node(3).Block(2).Header)
+
+	// add a fourth node to the cluster
+	consenters, err := c.Consenters()
+	require.NoError(t, err)
+	require.Len(t, consenters, 3)
+
+	consenter, err := c.NewConsenter()
+	require.NoError(t, err)
+	require.NoError(t, leader.Configure(c.ConsenterUpdate(append(consenters, consenter)), 0))
+	waitHeight(t, c, 4, 1, 2, 3)
+
+	_, err = c.AddNode(5)
+	assert.EqualError(t, err, "node 5 is not a consenter in config block 3")
+
+	n, err := c.AddNode(4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), n.Height())
+	require.NoError(t, c.Init(4))
+	require.NoError(t, c.Start(4))
+	require.NoError(t, c.Join(4))
+
+	require.NoError(t, leader.Order(c.Envelope([]byte("tx-3")), 0))
+	waitHeight(t, c, 5, 1, 2, 3, 4)
+
+	// the new node can take over leadership
+	require.NoError(t, c.Elect(4))
+	c.Node(4).Cutter.CutNext = true
+	require.NoError(t, c.Node(4).Order(c.Envelope([]byte("tx-4")), 0))
+	waitHeight(t, c, 6, 1, 2, 3, 4)
+}
+
+func TestClusterStateHash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdrafttest-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c, err := etcdrafttest.NewCluster("foo", dir, 3, time.Hour)
+	require.NoError(t, err)
+	for _, id := range c.IDs() {
+		c.Node(id).Options.StateHash = true
+	}
+
+	require.NoError(t, c.Init())
+	require.NoError(t, c.Start())
+	defer c.Stop()
+	require.NoError(t, c.Elect(1))
+
+	leader := c.Node(1)
+	leader.Cutter.CutNext = true
+	require.NoError(t, leader.Order(c.Envelope([]byte("tx-1")), 0))
+	waitHeight(t, c, 2, 1, 2, 3)
+
+	c.Disconnect(3)
+	require.NoError(t, leader.Order(c.Envelope([]byte("tx-2")), 0))
+	waitHeight(t, c, 3, 1, 2)
+	require.NoError(t, c.Join(3))
+	waitHeight(t, c, 3, 3)
+
+	assert.NoError(t, c.VerifyStateHashes(1))
+	assert.NoError(t, c.VerifyStateHashes(2))
+	assert.Contains(t, c.VerifyStateHashes(0).Error(), "carries no state hash")
+}
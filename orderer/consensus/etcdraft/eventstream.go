@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// eventBufferSize is the number of events buffered for a subscriber,
+// beyond which the subscription is closed with ErrEventsOverflow.
+const eventBufferSize = 100
+
+var (
+	// ErrEventStreamDisabled is returned when subscribing to the
+	// events of a chain which does not stream its events.
+	ErrEventStreamDisabled = errors.New("event stream is disabled")
+	// ErrEventsOverflow is the error of a subscription which
+	// was closed because it fell behind the events of the chain.
+	ErrEventsOverflow = errors.New("subscriber fell behind the event stream")
+	// ErrEventsHalted is the error of a subscription which
+	// was closed because the chain halted.
+	ErrEventsHalted = errors.New("chain halted")
+)
+
+// EventSubscription receives the consensus events of a chain as they are observed.
+type EventSubscription struct {
+	stream *eventStream
+	events chan *etcdraft.ConsensusEvent
+	err    error
+}
+
+// Events returns the channel the events are sent to. It is closed
+// when the subscription is cancelled or closed, after which Err returns
+// the reason the subscription was closed for, if any.
+func (s *EventSubscription) Events() <-chan *etcdraft.ConsensusEvent {
+	return s.events
+}
+
+// Err returns the error the subscription was closed with,
+// or nil if it is open or was cancelled.
+func (s *EventSubscription) Err() error {
+	s.stream.lock.Lock()
+	defer s.stream.lock.Unlock()
+	return s.err
+}
+
+// Cancel cancels the subscription.
+func (s *EventSubscription) Cancel() {
+	s.stream.lock.Lock()
+	defer s.stream.lock.Unlock()
+	s.stream.remove(s, nil)
+}
+
+// eventStream publishes the consensus events of a chain to its subscriptions.
+// It is the Notifier of the gRPC stream, as opposed to the webhooks.
+type eventStream struct {
+	logger *flogging.FabricLogger
+
+	lock          sync.Mutex
+	subscriptions map[*EventSubscription]struct{}
+	closed        error // set once the stream is closed
+}
+
+func newEventStream(logger *flogging.FabricLogger) *eventStream {
+	return &eventStream{
+		logger:        logger,
+		subscriptions: make(map[*EventSubscription]struct{}),
+	}
+}
+
+func (es *eventStream) subscribe() (*EventSubscription, error) {
+	s := &EventSubscription{
+		stream: es,
+		events: make(chan *etcdraft.ConsensusEvent, eventBufferSize),
+	}
+
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	if es.closed != nil {
+		return nil, es.closed
+	}
+	es.subscriptions[s] = struct{}{}
+	return s, nil
+}
+
+// remove closes the subscription with the given error. It is called with the lock held.
+func (es *eventStream) remove(s *EventSubscription, err error) {
+	if _, exists := es.subscriptions[s]; !exists {
+		return
+	}
+	delete(es.subscriptions, s)
+	s.err = err
+	close(s.events)
+}
+
+// Notify sends the event to the subscriptions. It never blocks,
+// subscriptions which fall behind are closed instead.
+func (es *eventStream) Notify(event Event) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	if len(es.subscriptions) == 0 {
+		return
+	}
+
+	ce := &etcdraft.ConsensusEvent{
+		Type:           string(event.Type),
+		Channel:        event.Channel,
+		NodeId:         event.NodeID,
+		Time:           &timestamp.Timestamp{Seconds: event.Time.Unix(), Nanos: int32(event.Time.Nanosecond())},
+		Leader:         event.Leader,
+		PreviousLeader: event.PreviousLeader,
+		AddedNode:      event.AddedNode,
+		RemovedNode:    event.RemovedNode,
+		Block:          event.Block,
+		Endpoint:       event.Endpoint,
+		Peer:           event.Peer,
+		Cause:          event.Cause,
+		Marker:         event.Marker,
+	}
+	for s := range es.subscriptions {
+		select {
+		case s.events <- ce:
+		default:
+			es.logger.Warnf("Closing event subscription which fell behind at %s event", event.Type)
+			es.remove(s, ErrEventsOverflow)
+		}
+	}
+}
+
+// close closes all subscriptions with the given error,
+// which is returned by further subscriptions.
+func (es *eventStream) close(err error) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+	es.closed = err
+	for s := range es.subscriptions {
+		es.remove(s, err)
+	}
+}
+
+// SubscribeEvents subscribes to the consensus events of the chain,
+// as they are observed. The subscription must be cancelled once done.
+func (c *Chain) SubscribeEvents() (*EventSubscription, error) {
+	if c.eventStream == nil {
+		return nil, ErrEventStreamDisabled
+	}
+	return c.eventStream.subscribe()
+}
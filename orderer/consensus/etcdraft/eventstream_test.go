@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	es := newEventStream(flogging.MustGetLogger("test"))
+
+	sub, err := es.subscribe()
+	require.NoError(t, err)
+
+	es.Notify(Event{
+		Type:           EventLeaderChange,
+		Channel:        "foo",
+		NodeID:         1,
+		Time:           time.Unix(1000, 5),
+		Leader:         2,
+		PreviousLeader: 3,
+	})
+	es.Notify(Event{Type: EventEviction, Channel: "foo", NodeID: 1, Time: time.Unix(1001, 0)})
+
+	assert.True(t, proto.Equal(&etcdraft.ConsensusEvent{
+		Type:           "leader_change",
+		Channel:        "foo",
+		NodeId:         1,
+		Time:           &timestamp.Timestamp{Seconds: 1000, Nanos: 5},
+		Leader:         2,
+		PreviousLeader: 3,
+	}, <-sub.Events()))
+	assert.True(t, proto.Equal(&etcdraft.ConsensusEvent{
+		Type:    "eviction",
+		Channel: "foo",
+		NodeId:  1,
+		Time:    &timestamp.Timestamp{Seconds: 1001},
+	}, <-sub.Events()))
+
+	// a cancelled subscription is closed without an error
+	cancelled, err := es.subscribe()
+	require.NoError(t, err)
+	cancelled.Cancel()
+	_, open := <-cancelled.Events()
+	assert.False(t, open)
+	assert.NoError(t, cancelled.Err())
+
+	// a subscription which falls behind is closed
+	for i := 0; i <= eventBufferSize; i++ {
+		es.Notify(Event{Type: EventPeerUnreachable})
+	}
+	for range sub.Events() {
+	}
+	assert.Equal(t, ErrEventsOverflow, sub.Err())
+
+	// closing the stream closes the remaining subscriptions and refuses new ones
+	open1, err := es.subscribe()
+	require.NoError(t, err)
+	es.close(ErrEventsHalted)
+	_, open = <-open1.Events()
+	assert.False(t, open)
+	assert.Equal(t, ErrEventsHalted, open1.Err())
+	_, err = es.subscribe()
+	assert.Equal(t, ErrEventsHalted, err)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// FairQueueSize is the number of transactions of a source node which
+// the fair scheduler queues, beyond which submitting from it blocks.
+var FairQueueSize = 1000
+
+// fairScheduler hands the transactions submitted to the chain to serveRequest
+// by weighted round-robin across the nodes they are submitted from, which are
+// this node for transactions of its own clients and the forwarding followers
+// otherwise. Each round, every node with queued transactions has as many of them
+// ordered as its share, so that a node receiving a burst of transactions cannot
+// monopolize block space. Transactions of a node keep their order.
+type fairScheduler struct {
+	share   func(source uint64) int
+	submitC chan<- *submit
+	doneC   <-chan struct{}
+
+	lock   sync.Mutex
+	queues map[uint64]chan *submit // by source node
+	readyC chan struct{}           // signaled whenever a transaction is queued
+}
+
+func newFairScheduler(share func(source uint64) int, submitC chan<- *submit, doneC <-chan struct{}) *fairScheduler {
+	return &fairScheduler{
+		share:   share,
+		submitC: submitC,
+		doneC:   doneC,
+		queues:  make(map[uint64]chan *submit),
+		readyC:  make(chan struct{}, 1),
+	}
+}
+
+// submit queues the transaction of the given source node, and blocks
+// while the source node has FairQueueSize transactions queued.
+func (fs *fairScheduler) submit(source uint64, s *submit) error {
+	select {
+	case fs.queue(source) <- s:
+	case <-fs.doneC:
+		return errors.Errorf("chain is stopped")
+	}
+
+	select {
+	case fs.readyC <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (fs *fairScheduler) queue(source uint64) chan *submit {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	q, exists := fs.queues[source]
+	if !exists {
+		q = make(chan *submit, FairQueueSize)
+		fs.queues[source] = q
+	}
+	return q
+}
+
+// sources returns the nodes transactions were submitted from, in ascending order.
+func (fs *fairScheduler) sources() []uint64 {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	sources := make([]uint64, 0, len(fs.queues))
+	for source := range fs.queues {
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	return sources
+}
+
+// run hands the queued transactions to serveRequest until doneC is closed.
+func (fs *fairScheduler) run() {
+	for {
+		scheduled := false
+		for _, source := range fs.sources() {
+			q := fs.queue(source)
+			for i := 0; i < fs.share(source); i++ {
+				var s *submit
+				select {
+				case s = <-q:
+				default:
+				}
+				if s == nil {
+					break
+				}
+
+				select {
+				case fs.submitC <- s:
+					scheduled = true
+				case <-fs.doneC:
+					return
+				}
+			}
+		}
+
+		if scheduled {
+			continue
+		}
+
+		select {
+		case <-fs.readyC:
+		case <-fs.doneC:
+			return
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFairScheduler(t *testing.T) {
+	submitted := func(source, seq uint64) *submit {
+		return &submit{req: &orderer.SubmitRequest{Channel: string('0' + rune(source)), LastValidationSeq: seq}}
+	}
+	shares := func(source uint64) int {
+		return map[uint64]int{1: 1, 2: 2}[source]
+	}
+
+	t.Run("orders the transactions of the sources by their shares", func(t *testing.T) {
+		submitC := make(chan *submit, 10)
+		doneC := make(chan struct{})
+		defer close(doneC)
+		fs := newFairScheduler(shares, submitC, doneC)
+
+		// node 1 has a burst of transactions queued before node 2 submits any
+		for seq := uint64(1); seq <= 5; seq++ {
+			assert.NoError(t, fs.submit(1, submitted(1, seq)))
+		}
+		for seq := uint64(1); seq <= 3; seq++ {
+			assert.NoError(t, fs.submit(2, submitted(2, seq)))
+		}
+		go fs.run()
+
+		var order []string
+		for i := 0; i < 8; i++ {
+			s := <-submitC
+			order = append(order, s.req.Channel+":"+string('0'+rune(s.req.LastValidationSeq)))
+		}
+		assert.Equal(t, []string{"1:1", "2:1", "2:2", "1:2", "2:3", "1:3", "1:4", "1:5"}, order)
+	})
+
+	t.Run("hands over transactions submitted while idle", func(t *testing.T) {
+		submitC := make(chan *submit)
+		doneC := make(chan struct{})
+		defer close(doneC)
+		fs := newFairScheduler(shares, submitC, doneC)
+		go fs.run()
+
+		s := submitted(2, 1)
+		assert.NoError(t, fs.submit(2, s))
+		assert.Equal(t, s, <-submitC)
+	})
+
+	t.Run("fails once the chain is stopped", func(t *testing.T) {
+		backup := FairQueueSize
+		FairQueueSize = 1
+		defer func() { FairQueueSize = backup }()
+
+		doneC := make(chan struct{})
+		fs := newFairScheduler(shares, make(chan *submit), doneC)
+		assert.NoError(t, fs.submit(1, submitted(1, 1)))
+		close(doneC)
+
+		assert.EqualError(t, fs.submit(1, submitted(1, 2)), "chain is stopped")
+	})
+}
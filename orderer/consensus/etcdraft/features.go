@@ -0,0 +1,181 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Names of the wire features negotiated between the consenters of a channel.
+const (
+	FeatureCompression      = "compression"
+	FeatureChunkedSnapshots = "chunked_snapshots"
+	FeatureConfChangeV2     = "conf_change_v2"
+	FeatureSubmitBatches    = "submit_batches"
+	FeatureRestartSlots     = "restart_slots"
+	FeatureMarkers          = "markers"
+)
+
+// SupportedFeatures returns the versions of the wire features implemented by this node,
+// which the Consenter advertises on every channel.
+func SupportedFeatures() map[string]uint32 {
+	return map[string]uint32{FeatureSubmitBatches: 1, FeatureRestartSlots: 1, FeatureMarkers: 1}
+}
+
+const (
+	// featureAdvertisementVersion is the version of the advertisements sent by this node.
+	featureAdvertisementVersion = 1
+	// featureAdvertisementsMissed is the number of advertisements a consenter may miss
+	// before the features it advertised are no longer deemed supported by it.
+	featureAdvertisementsMissed = 3
+)
+
+// featureNegotiator periodically advertises the wire features supported by this
+// node to the other consenters of the channel, and keeps track of the features
+// they advertise, so that a feature is used only once every consenter supports
+// it. Consenters which run a version unaware of advertisements never advertise
+// any feature, hence mixed-version clusters agree without operator coordination.
+// Advertisements expire, so that features are no longer used once a consenter
+// is downgraded.
+type featureNegotiator struct {
+	logger *flogging.FabricLogger
+	clock  clock.Clock
+	local  map[string]uint32 // versions of the features supported by this node
+	expiry time.Duration     // of the advertisements of the other consenters
+	nodes  func() []uint64   // the other consenters
+	send   func(to uint64, payload []byte) error
+
+	goroutines *goroutineTracker // of the chain, if set
+
+	lock       sync.Mutex
+	advertised map[uint64]*advertisement // by the other consenters
+	negotiated map[string]uint32         // as of the last advertisement
+}
+
+type advertisement struct {
+	features map[string]uint32
+	received time.Time
+}
+
+// run advertises the features of this node every interval until doneC is closed.
+func (fn *featureNegotiator) run(interval time.Duration, doneC <-chan struct{}) {
+	fn.advertise()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fn.advertise()
+		case <-doneC:
+			return
+		}
+	}
+}
+
+// advertise sends the features of this node to the other consenters,
+// and logs the features negotiated with them whenever they change.
+func (fn *featureNegotiator) advertise() {
+	for _, node := range fn.nodes() {
+		fn.advertiseTo(node)
+	}
+
+	negotiated := fn.negotiate()
+
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+	if !reflect.DeepEqual(negotiated, fn.negotiated) {
+		fn.logger.Infof("Wire features negotiated with the other consenters changed from %v to %v", fn.negotiated, negotiated)
+		fn.negotiated = negotiated
+	}
+}
+
+func (fn *featureNegotiator) advertiseTo(node uint64) {
+	payload, err := proto.Marshal(&etcdraft.FeatureAdvertisement{
+		Version:  featureAdvertisementVersion,
+		Features: fn.local,
+	})
+	if err != nil {
+		fn.logger.Panicf("Failed to marshal feature advertisement: %s", err)
+	}
+
+	if err := fn.send(node, payload); err != nil {
+		fn.logger.Debugf("Failed to advertise features to node %d: %s", node, err)
+	}
+}
+
+// receive records the features advertised by a consenter. The features of this
+// node are advertised back to consenters heard from for the first time, since they
+// may have missed the advertisements of this node while they were starting.
+func (fn *featureNegotiator) receive(sender uint64, ad *etcdraft.FeatureAdvertisement) {
+	fn.lock.Lock()
+	if fn.advertised == nil {
+		fn.advertised = make(map[uint64]*advertisement)
+	}
+	_, known := fn.advertised[sender]
+	fn.advertised[sender] = &advertisement{features: ad.Features, received: fn.clock.Now()}
+	fn.lock.Unlock()
+
+	if !known {
+		fn.goroutines.spawn("feature_advertisement", func() { fn.advertiseTo(sender) })
+	}
+}
+
+// negotiate returns the versions of the features which every consenter supports,
+// which are the lowest versions advertised for them.
+func (fn *featureNegotiator) negotiate() map[string]uint32 {
+	fn.lock.Lock()
+	defer fn.lock.Unlock()
+
+	negotiated := make(map[string]uint32, len(fn.local))
+	for feature, version := range fn.local {
+		negotiated[feature] = version
+	}
+
+	now := fn.clock.Now()
+	for _, node := range fn.nodes() {
+		ad, ok := fn.advertised[node]
+		if !ok || now.Sub(ad.received) > fn.expiry {
+			return map[string]uint32{}
+		}
+		for feature, version := range negotiated {
+			if ad.features[feature] < version {
+				version = ad.features[feature]
+			}
+			if version == 0 {
+				delete(negotiated, feature)
+				continue
+			}
+			negotiated[feature] = version
+		}
+	}
+
+	return negotiated
+}
+
+// featureAdvertisement returns the feature advertisement carried by a ConsensusRequest
+// payload, or nil if it carries a raft message. Advertisements are decoded as local
+// raft messages, which are never sent over the wire, hence only those are inspected.
+func featureAdvertisement(payload []byte, msg *raftpb.Message) *etcdraft.FeatureAdvertisement {
+	if msg.Type != raftpb.MsgHup {
+		return nil
+	}
+	ad := &etcdraft.FeatureAdvertisement{}
+	if err := proto.Unmarshal(payload, ad); err != nil || ad.Version == 0 {
+		return nil
+	}
+	return ad
+}
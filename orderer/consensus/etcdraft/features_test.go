@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestFeatureNegotiator(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	nodes := []uint64{2, 3}
+	sent := make(chan []byte, 10)
+
+	fn := &featureNegotiator{
+		logger: flogging.MustGetLogger("test"),
+		clock:  clock,
+		local:  map[string]uint32{FeatureCompression: 2, FeatureChunkedSnapshots: 1},
+		expiry: time.Minute,
+		nodes:  func() []uint64 { return nodes },
+		send: func(to uint64, payload []byte) error {
+			if to == 3 {
+				return errors.New("connection refused")
+			}
+			sent <- payload
+			return nil
+		},
+	}
+
+	fn.advertise()
+	require.Len(t, sent, 1)
+	ad := &etcdraft.FeatureAdvertisement{}
+	require.NoError(t, proto.Unmarshal(<-sent, ad))
+	assert.Equal(t, uint32(featureAdvertisementVersion), ad.Version)
+	assert.Equal(t, fn.local, ad.Features)
+
+	// nothing is negotiated until every consenter advertised its features
+	assert.Empty(t, fn.negotiate())
+	fn.receive(2, &etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 3, FeatureChunkedSnapshots: 1}})
+	assert.Empty(t, fn.negotiate())
+
+	// the features are advertised back to consenters heard from for the first time
+	<-sent
+	fn.receive(2, &etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 3, FeatureChunkedSnapshots: 1}})
+	assert.Empty(t, sent)
+
+	// the lowest versions of the features every consenter supports are negotiated
+	fn.receive(3, &etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 1, FeatureConfChangeV2: 1}})
+	assert.Equal(t, map[string]uint32{FeatureCompression: 1}, fn.negotiate())
+	fn.receive(3, &etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 2, FeatureChunkedSnapshots: 1}})
+	assert.Equal(t, map[string]uint32{FeatureCompression: 2, FeatureChunkedSnapshots: 1}, fn.negotiate())
+
+	// the advertisements of removed consenters do not count
+	fn.receive(4, &etcdraft.FeatureAdvertisement{Version: 1})
+	assert.Equal(t, map[string]uint32{FeatureCompression: 2, FeatureChunkedSnapshots: 1}, fn.negotiate())
+
+	// features are no longer used once the advertisements of a consenter expire
+	clock.Increment(time.Minute + time.Second)
+	fn.receive(2, &etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 3, FeatureChunkedSnapshots: 1}})
+	assert.Empty(t, fn.negotiate())
+
+	// a single node uses its own features
+	nodes = nil
+	assert.Equal(t, fn.local, fn.negotiate())
+}
+
+func TestFeatureAdvertisement(t *testing.T) {
+	payload, err := proto.Marshal(&etcdraft.FeatureAdvertisement{Version: 1, Features: map[string]uint32{FeatureCompression: 1}})
+	require.NoError(t, err)
+
+	// nodes unaware of advertisements decode them as local raft messages
+	msg := &raftpb.Message{}
+	require.NoError(t, proto.Unmarshal(payload, msg))
+	assert.Equal(t, raftpb.MsgHup, msg.Type)
+
+	ad := featureAdvertisement(payload, msg)
+	require.NotNil(t, ad)
+	assert.Equal(t, map[string]uint32{FeatureCompression: 1}, ad.Features)
+
+	// raft messages are not taken for advertisements
+	payload, err = proto.Marshal(&raftpb.Message{Type: raftpb.MsgHeartbeat, To: 2, From: 1, Term: 5})
+	require.NoError(t, err)
+	msg = &raftpb.Message{}
+	require.NoError(t, proto.Unmarshal(payload, msg))
+	assert.Nil(t, featureAdvertisement(payload, msg))
+}
@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Directions of the raft messages recorded by the flight recorder.
+const (
+	MessageSent     = "sent"
+	MessageReceived = "received"
+)
+
+// RecordedMessage describes a raft message sent or received by a chain,
+// without its entries or snapshot.
+type RecordedMessage struct {
+	Time      time.Time `json:"time"`
+	Direction string    `json:"direction"`
+	Type      string    `json:"type"`
+	From      uint64    `json:"from"`
+	To        uint64    `json:"to"`
+	Term      uint64    `json:"term"`
+	LogTerm   uint64    `json:"log_term,omitempty"`
+	Index     uint64    `json:"index,omitempty"`
+	Commit    uint64    `json:"commit,omitempty"`
+	Entries   int       `json:"entries,omitempty"`
+	Reject    bool      `json:"reject,omitempty"`
+	Bytes     int       `json:"bytes"`
+}
+
+// flightRecorder keeps the most recent raft messages a chain sent and received,
+// so that elections and replication are traced back after the fact, without the
+// debug output of etcd/raft. A nil flightRecorder records nothing.
+type flightRecorder struct {
+	lock     sync.Mutex
+	messages []RecordedMessage
+	size     int
+	next     int
+}
+
+func newFlightRecorder(size int) *flightRecorder {
+	if size <= 0 {
+		return nil
+	}
+	return &flightRecorder{size: size}
+}
+
+// record records the given raft message, which is the given number of bytes
+// on the wire, evicting the oldest recorded message if the recorder is full.
+func (fr *flightRecorder) record(direction string, msg *raftpb.Message, bytes int, now time.Time) {
+	if fr == nil {
+		return
+	}
+
+	recorded := RecordedMessage{
+		Time:      now,
+		Direction: direction,
+		Type:      msg.Type.String(),
+		From:      msg.From,
+		To:        msg.To,
+		Term:      msg.Term,
+		LogTerm:   msg.LogTerm,
+		Index:     msg.Index,
+		Commit:    msg.Commit,
+		Entries:   len(msg.Entries),
+		Reject:    msg.Reject,
+		Bytes:     bytes,
+	}
+
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+
+	if len(fr.messages) < fr.size {
+		fr.messages = append(fr.messages, recorded)
+		return
+	}
+	fr.messages[fr.next] = recorded
+	fr.next = (fr.next + 1) % fr.size
+}
+
+// recent returns the recorded messages, oldest first.
+func (fr *flightRecorder) recent() []RecordedMessage {
+	if fr == nil {
+		return nil
+	}
+
+	fr.lock.Lock()
+	defer fr.lock.Unlock()
+
+	messages := make([]RecordedMessage, 0, len(fr.messages))
+	messages = append(messages, fr.messages[fr.next:]...)
+	return append(messages, fr.messages[:fr.next]...)
+}
+
+// FlightRecord returns the most recent raft messages sent and received by the
+// chain, oldest first, or nil if FlightRecorderSize is not set.
+func (c *Chain) FlightRecord() []RecordedMessage {
+	return c.recorder.recent()
+}
+
+// dumpFlightRecordOnPanic logs the flight record of the chain if the goroutine
+// it is deferred by panics, before letting the panic carry on. It must be
+// deferred by the goroutines driving the raft node of the chain.
+func (c *Chain) dumpFlightRecordOnPanic() {
+	if c.recorder == nil {
+		return
+	}
+
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if record, err := json.Marshal(c.recorder.recent()); err != nil {
+		c.logger.Errorf("Failed to dump flight record: %s", err)
+	} else {
+		c.logger.Errorf("Raft messages recorded before panicking: %s", record)
+	}
+	panic(r)
+}
@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	now := time.Now()
+	fr := newFlightRecorder(2)
+
+	fr.record(MessageSent, &raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2, Term: 3, LogTerm: 2, Index: 10}, 20, now)
+	fr.record(MessageReceived, &raftpb.Message{Type: raftpb.MsgVoteResp, From: 2, To: 1, Term: 3, Reject: true}, 10, now)
+	assert.Equal(t, []RecordedMessage{
+		{Time: now, Direction: MessageSent, Type: "MsgVote", From: 1, To: 2, Term: 3, LogTerm: 2, Index: 10, Bytes: 20},
+		{Time: now, Direction: MessageReceived, Type: "MsgVoteResp", From: 2, To: 1, Term: 3, Reject: true, Bytes: 10},
+	}, fr.recent())
+
+	// the oldest message is evicted once the recorder is full
+	entries := []raftpb.Entry{{Data: []byte("block")}, {Data: []byte("block")}}
+	fr.record(MessageSent, &raftpb.Message{Type: raftpb.MsgApp, From: 1, To: 2, Term: 3, Commit: 9, Entries: entries}, 50, now)
+	assert.Equal(t, []RecordedMessage{
+		{Time: now, Direction: MessageReceived, Type: "MsgVoteResp", From: 2, To: 1, Term: 3, Reject: true, Bytes: 10},
+		{Time: now, Direction: MessageSent, Type: "MsgApp", From: 1, To: 2, Term: 3, Commit: 9, Entries: 2, Bytes: 50},
+	}, fr.recent())
+
+	// a nil recorder records nothing
+	assert.Nil(t, newFlightRecorder(0))
+	var disabled *flightRecorder
+	disabled.record(MessageSent, &raftpb.Message{}, 0, now)
+	assert.Nil(t, disabled.recent())
+}
+
+func TestDumpFlightRecordOnPanic(t *testing.T) {
+	c := &Chain{logger: flogging.MustGetLogger("test"), recorder: newFlightRecorder(10)}
+	c.recorder.record(MessageSent, &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2}, 8, time.Now())
+
+	assert.PanicsWithValue(t, "boom", func() {
+		defer c.dumpFlightRecordOnPanic()
+		panic("boom")
+	})
+
+	// goroutines which do not panic carry on
+	assert.NotPanics(t, func() {
+		defer c.dumpFlightRecordOnPanic()
+	})
+	assert.NotPanics(t, func() {
+		defer (&Chain{}).dumpFlightRecordOnPanic()
+	})
+}
@@ -0,0 +1,175 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/pkg/errors"
+)
+
+// MaxForwardedBatch is the largest number of transactions
+// forwarded to the leader in a single SubmitBatch.
+var MaxForwardedBatch = 100
+
+// submitForwarder forwards transactions to the leader in batches, over the
+// stream used for single transactions. Transactions forwarded concurrently
+// queue up while a batch is being sent, and are sent in the next batch, so
+// that batching adds no latency. The queue of every destination is bounded,
+// hence forwarding blocks, pushing back on clients, when the stream does.
+// Once the forwarder is notified that the config sequence advanced, requests
+// are re-validated against the current config sequence before they are sent.
+type submitForwarder struct {
+	logger     *flogging.FabricLogger
+	maxBytes   func() uint32 // of the payloads of a batch
+	send       func(dest uint64, batch *orderer.SubmitBatch) error
+	revalidate func(req *orderer.SubmitRequest) error // if validated against an older config sequence
+	doneC      <-chan struct{}
+	goroutines *goroutineTracker // of the chain, if set
+
+	configAdvanced uint32 // accessed atomically, 1 once the config sequence advanced
+
+	lock   sync.Mutex
+	queues map[uint64]chan *forwarded // by destination
+}
+
+type forwarded struct {
+	req  *orderer.SubmitRequest
+	errC chan error
+}
+
+// forward sends the request to the given destination in the next
+// batch, and returns once the batch is sent or failed to be.
+func (f *submitForwarder) forward(dest uint64, req *orderer.SubmitRequest) error {
+	select {
+	case <-f.doneC:
+		return errors.Errorf("chain is stopped")
+	default:
+	}
+
+	fw := &forwarded{req: req, errC: make(chan error, 1)}
+	select {
+	case f.queue(dest) <- fw:
+	case <-f.doneC:
+		return errors.Errorf("chain is stopped")
+	}
+
+	select {
+	case err := <-fw.errC:
+		return err
+	case <-f.doneC:
+		return errors.Errorf("chain is stopped")
+	}
+}
+
+// queue returns the queue of the given destination,
+// starting to serve it if it does not exist yet.
+func (f *submitForwarder) queue(dest uint64) chan *forwarded {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.queues == nil {
+		f.queues = make(map[uint64]chan *forwarded)
+	}
+	q, exists := f.queues[dest]
+	if !exists {
+		q = make(chan *forwarded, MaxForwardedBatch)
+		f.queues[dest] = q
+		f.goroutines.spawn("forwarder", func() { f.serve(dest, q) })
+	}
+	return q
+}
+
+func (f *submitForwarder) serve(dest uint64, q chan *forwarded) {
+	for {
+		select {
+		case fw := <-q:
+			f.sendBatch(dest, f.batch(fw, q))
+		case <-f.doneC:
+			return
+		}
+	}
+}
+
+// batch returns the given request along with the requests queued after it,
+// until the batch reaches MaxForwardedBatch requests or the byte limit.
+func (f *submitForwarder) batch(first *forwarded, q chan *forwarded) []*forwarded {
+	batch := []*forwarded{first}
+	size := uint32(submitLength(first.req))
+	maxBytes := f.maxBytes()
+
+	for len(batch) < MaxForwardedBatch {
+		select {
+		case fw := <-q:
+			batch = append(batch, fw)
+			size += uint32(submitLength(fw.req))
+			if size >= maxBytes {
+				return batch
+			}
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// configSequenceAdvanced notifies the forwarder that a config block advanced the
+// config sequence. From then on, the requests queued, or being queued, which were
+// validated against an older config sequence are re-validated locally before they
+// are sent, and the ones which became invalid fail right away, instead of being
+// sent to the leader only to be rejected by it.
+func (f *submitForwarder) configSequenceAdvanced() {
+	atomic.StoreUint32(&f.configAdvanced, 1)
+}
+
+// revalidated returns the requests of the batch which are valid at the current
+// config sequence, once it advanced, and fails the others.
+func (f *submitForwarder) revalidated(batch []*forwarded) []*forwarded {
+	if atomic.LoadUint32(&f.configAdvanced) == 0 {
+		return batch
+	}
+	valid := make([]*forwarded, 0, len(batch))
+	for _, fw := range batch {
+		if err := f.revalidate(fw.req); err != nil {
+			f.logger.Debugf("Request validated against config sequence %d is no longer valid: %s", fw.req.LastValidationSeq, err)
+			fw.errC <- err
+			continue
+		}
+		valid = append(valid, fw)
+	}
+	return valid
+}
+
+func (f *submitForwarder) sendBatch(dest uint64, batch []*forwarded) {
+	batch = f.revalidated(batch)
+	if len(batch) == 0 {
+		return
+	}
+
+	requests := make([]*orderer.SubmitRequest, len(batch))
+	for i, fw := range batch {
+		requests[i] = fw.req
+	}
+
+	err := f.send(dest, &orderer.SubmitBatch{Requests: requests})
+	if err != nil {
+		f.logger.Debugf("Failed to forward batch of %d requests to node %d: %s", len(batch), dest, err)
+	}
+	for _, fw := range batch {
+		fw.errC <- err
+	}
+}
+
+func submitLength(req *orderer.SubmitRequest) int {
+	if req.Payload == nil {
+		return 0
+	}
+	return len(req.Payload.Payload)
+}
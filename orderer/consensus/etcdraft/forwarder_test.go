@@ -0,0 +1,161 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitForwarder(t *testing.T) {
+	backup := MaxForwardedBatch
+	MaxForwardedBatch = 3
+	defer func() { MaxForwardedBatch = backup }()
+
+	request := func(seq uint64) *orderer.SubmitRequest {
+		return &orderer.SubmitRequest{LastValidationSeq: seq, Payload: &common.Envelope{Payload: make([]byte, 10)}}
+	}
+
+	newForwarder := func(maxBytes uint32, send func(uint64, *orderer.SubmitBatch) error) (*submitForwarder, chan struct{}) {
+		doneC := make(chan struct{})
+		return &submitForwarder{
+			logger:   flogging.MustGetLogger("test"),
+			maxBytes: func() uint32 { return maxBytes },
+			send:     send,
+			doneC:    doneC,
+		}, doneC
+	}
+
+	t.Run("batches the requests forwarded concurrently", func(t *testing.T) {
+		sending := make(chan struct{})
+		release := make(chan struct{})
+		var lock sync.Mutex
+		var batches []*orderer.SubmitBatch
+		f, doneC := newForwarder(1000, func(dest uint64, batch *orderer.SubmitBatch) error {
+			assert.Equal(t, uint64(2), dest)
+			lock.Lock()
+			batches = append(batches, batch)
+			first := len(batches) == 1
+			lock.Unlock()
+			if first {
+				close(sending)
+				<-release
+			}
+			return nil
+		})
+		defer close(doneC)
+
+		var forwarded sync.WaitGroup
+		forward := func(seq uint64) {
+			forwarded.Add(1)
+			go func() {
+				defer forwarded.Done()
+				assert.NoError(t, f.forward(2, request(seq)))
+			}()
+		}
+
+		// the requests forwarded while the first one is sent queue up,
+		// until the queue is full and forwarding blocks
+		forward(1)
+		<-sending
+		for seq := uint64(2); seq <= 5; seq++ {
+			forward(seq)
+		}
+		for len(f.queue(2)) < MaxForwardedBatch {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		forwarded.Wait()
+
+		var sizes []int
+		for _, batch := range batches {
+			sizes = append(sizes, len(batch.Requests))
+		}
+		assert.Equal(t, []int{1, 3, 1}, sizes)
+	})
+
+	t.Run("limits the payload bytes of a batch", func(t *testing.T) {
+		f, doneC := newForwarder(20, nil)
+		defer close(doneC)
+
+		q := make(chan *forwarded, 3)
+		for seq := uint64(2); seq <= 4; seq++ {
+			q <- &forwarded{req: request(seq)}
+		}
+		batch := f.batch(&forwarded{req: request(1)}, q)
+		assert.Len(t, batch, 2)
+		assert.Len(t, q, 2)
+	})
+
+	t.Run("fails the requests of a batch which is not sent", func(t *testing.T) {
+		f, doneC := newForwarder(1000, func(uint64, *orderer.SubmitBatch) error {
+			return errors.New("connection lost")
+		})
+		defer close(doneC)
+
+		assert.EqualError(t, f.forward(2, request(1)), "connection lost")
+	})
+
+	t.Run("fails the requests once the chain is stopped", func(t *testing.T) {
+		f, doneC := newForwarder(1000, nil)
+		close(doneC)
+
+		assert.EqualError(t, f.forward(2, request(1)), "chain is stopped")
+	})
+	t.Run("re-validates the requests once the config sequence advances", func(t *testing.T) {
+		var sent []*orderer.SubmitRequest
+		f, doneC := newForwarder(1000, func(dest uint64, batch *orderer.SubmitBatch) error {
+			sent = append(sent, batch.Requests...)
+			return nil
+		})
+		defer close(doneC)
+		var revalidated []uint64
+		f.revalidate = func(req *orderer.SubmitRequest) error {
+			if req.LastValidationSeq >= 3 {
+				return nil
+			}
+			revalidated = append(revalidated, req.LastValidationSeq)
+			if req.LastValidationSeq == 1 {
+				return errors.New("bad normal message: expired")
+			}
+			req.LastValidationSeq = 3
+			return nil
+		}
+
+		// requests are sent as they are until the forwarder is notified
+		f.sendBatch(2, []*forwarded{{req: request(1), errC: make(chan error, 1)}})
+		assert.Empty(t, revalidated)
+
+		f.configSequenceAdvanced()
+		batch := []*forwarded{
+			{req: request(1), errC: make(chan error, 1)},
+			{req: request(2), errC: make(chan error, 1)},
+			{req: request(3), errC: make(chan error, 1)},
+		}
+		f.sendBatch(2, batch)
+		assert.Equal(t, []uint64{1, 2}, revalidated)
+		assert.EqualError(t, <-batch[0].errC, "bad normal message: expired")
+		assert.NoError(t, <-batch[1].errC)
+		assert.NoError(t, <-batch[2].errC)
+		assert.Len(t, sent, 3)
+		assert.Equal(t, uint64(3), sent[1].LastValidationSeq)
+		assert.Equal(t, uint64(3), sent[2].LastValidationSeq)
+
+		// no batch is sent if none of its requests is valid anymore
+		fw := &forwarded{req: request(1), errC: make(chan error, 1)}
+		f.sendBatch(2, []*forwarded{fw})
+		assert.Error(t, <-fw.errC)
+		assert.Len(t, sent, 3)
+	})
+}
@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/pkg/errors"
+)
+
+// goroutineTracker tracks the goroutines owned by a chain by name, so that their
+// number is surfaced, and those which outlive the chain once halted are detected.
+type goroutineTracker struct {
+	gauge metrics.Gauge // of the number of running goroutines
+
+	lock    sync.Mutex
+	running map[string]int
+	total   int
+	exitedC chan struct{} // closed and replaced whenever a goroutine exits
+}
+
+func newGoroutineTracker(gauge metrics.Gauge) *goroutineTracker {
+	return &goroutineTracker{
+		gauge:   gauge,
+		running: make(map[string]int),
+		exitedC: make(chan struct{}),
+	}
+}
+
+// spawn runs fn in a goroutine tracked under the given name. A nil tracker
+// runs fn in a goroutine which is not tracked.
+func (t *goroutineTracker) spawn(name string, fn func()) {
+	if t == nil {
+		go fn()
+		return
+	}
+
+	t.lock.Lock()
+	t.running[name]++
+	t.total++
+	t.gauge.Set(float64(t.total))
+	t.lock.Unlock()
+
+	go func() {
+		defer t.exited(name)
+		fn()
+	}()
+}
+
+func (t *goroutineTracker) exited(name string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.running[name]--
+	if t.running[name] == 0 {
+		delete(t.running, name)
+	}
+	t.total--
+	t.gauge.Set(float64(t.total))
+
+	close(t.exitedC)
+	t.exitedC = make(chan struct{})
+}
+
+// goroutines returns the number of running goroutines by name.
+func (t *goroutineTracker) goroutines() map[string]int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	running := make(map[string]int, len(t.running))
+	for name, n := range t.running {
+		running[name] = n
+	}
+	return running
+}
+
+// wait waits until all goroutines exited, or the given timeout expires,
+// and returns the goroutines still running, if any.
+func (t *goroutineTracker) wait(timeout time.Duration) map[string]int {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		t.lock.Lock()
+		total, exitedC := t.total, t.exitedC
+		t.lock.Unlock()
+
+		if total == 0 {
+			return nil
+		}
+		select {
+		case <-exitedC:
+		case <-timer.C:
+			return t.goroutines()
+		}
+	}
+}
+
+// Goroutines returns the number of running goroutines owned by the chain,
+// by what they do, e.g. "serve" for the goroutine serving requests.
+func (c *Chain) Goroutines() map[string]int {
+	return c.goroutines.goroutines()
+}
+
+// WaitGoroutines waits up to the given timeout for the goroutines owned by the
+// chain to exit after it is halted, and returns an error naming those which did
+// not, as they are leaked. It is meant for tests.
+func (c *Chain) WaitGoroutines(timeout time.Duration) error {
+	leaked := c.goroutines.wait(timeout)
+	if len(leaked) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name, n := range leaked {
+		names = append(names, fmt.Sprintf("%s (%d)", name, n))
+	}
+	sort.Strings(names)
+	return errors.Errorf("goroutines did not exit within %s: %s", timeout, strings.Join(names, ", "))
+}
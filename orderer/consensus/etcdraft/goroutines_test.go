@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoroutineTracker(t *testing.T) {
+	gauge := &metricsfakes.Gauge{}
+	tracker := newGoroutineTracker(gauge)
+	c := &Chain{goroutines: tracker}
+
+	releaseC := make(chan struct{})
+	block := func() { <-releaseC }
+	tracker.spawn("serve", block)
+	tracker.spawn("forwarder", block)
+	tracker.spawn("forwarder", block)
+
+	assert.Equal(t, map[string]int{"serve": 1, "forwarder": 2}, c.Goroutines())
+	assert.Equal(t, float64(3), gauge.SetArgsForCall(2))
+
+	// goroutines still running once the timeout expires are leaked
+	err := c.WaitGoroutines(10 * time.Millisecond)
+	assert.EqualError(t, err, "goroutines did not exit within 10ms: forwarder (2), serve (1)")
+
+	close(releaseC)
+	assert.NoError(t, c.WaitGoroutines(time.Minute))
+	assert.Empty(t, c.Goroutines())
+	assert.Equal(t, 6, gauge.SetCallCount())
+	assert.Equal(t, float64(0), gauge.SetArgsForCall(5))
+
+	// a nil tracker runs goroutines untracked
+	var untracked *goroutineTracker
+	doneC := make(chan struct{})
+	untracked.spawn("serve", func() { close(doneC) })
+	<-doneC
+}
@@ -0,0 +1,265 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+const (
+	// DegradedAppendAck is the cause of a peer being classified as degraded
+	// when it is slow to acknowledge the entries appended to it by the leader.
+	DegradedAppendAck = "append_ack_latency"
+	// DegradedPersist is the cause of this node being classified as degraded
+	// when it is slow to persist raft data.
+	DegradedPersist = "persist_latency"
+
+	// maxPendingAppends bounds the appends awaiting acknowledgment
+	// which are tracked for each peer.
+	maxPendingAppends = 256
+
+	// ackLatencyWeight is the weight of the latest acknowledgment
+	// latency in the moving average of the acknowledgment latency.
+	ackLatencyWeight = 0.2
+)
+
+// DegradedNode describes a consenter which is up but too slow,
+// along with the cause and the latency it is classified by.
+type DegradedNode struct {
+	ID      uint64 `json:"id"`
+	Cause   string `json:"cause"`
+	Latency string `json:"latency"`
+}
+
+type pendingAppend struct {
+	term  uint64
+	index uint64
+	sent  time.Time
+}
+
+type latencyAverage struct {
+	latency    float64 // moving average of the latency, in seconds
+	classified float64 // latency the node was last classified by, in seconds
+	degraded   bool
+}
+
+func (l *latencyAverage) observe(d time.Duration, weight float64) {
+	l.latency = weight*d.Seconds() + (1-weight)*l.latency
+}
+
+// grayFailureDetector detects gray failures, where a consenter is up but so
+// slow that it drags the channel down long before it fails outright. The
+// leader times the acknowledgment of the entries it appends to each peer,
+// and every node times persisting its own raft data. A node is classified
+// as degraded once the moving average of its latency exceeds the threshold,
+// and recovers once it drops below half of it, so that a node hovering
+// around the threshold does not flap.
+type grayFailureDetector struct {
+	logger    *flogging.FabricLogger
+	clock     clock.Clock
+	self      uint64
+	threshold time.Duration
+	metrics   *Metrics
+	notify    func(Event)
+
+	lock    sync.Mutex
+	pending map[uint64][]pendingAppend // appends awaiting acknowledgment, by peer
+	peers   map[uint64]*latencyAverage // acknowledgment latency, by peer
+	local   latencyAverage             // persist latency of this node
+}
+
+// newGrayFailureDetector returns a grayFailureDetector,
+// or nil if gray failures are not to be detected.
+func newGrayFailureDetector(logger *flogging.FabricLogger, clock clock.Clock, self uint64, threshold time.Duration, metrics *Metrics, notify func(Event)) *grayFailureDetector {
+	if threshold <= 0 {
+		return nil
+	}
+	return &grayFailureDetector{
+		logger:    logger,
+		clock:     clock,
+		self:      self,
+		threshold: threshold,
+		metrics:   metrics,
+		notify:    notify,
+		pending:   make(map[uint64][]pendingAppend),
+		peers:     make(map[uint64]*latencyAverage),
+	}
+}
+
+// sent records the entries appended to a peer by the given message.
+func (d *grayFailureDetector) sent(msg raftpb.Message) {
+	if d == nil || msg.Type != raftpb.MsgApp || len(msg.Entries) == 0 {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	pending := append(d.pending[msg.To], pendingAppend{
+		term:  msg.Term,
+		index: msg.Entries[len(msg.Entries)-1].Index,
+		sent:  d.clock.Now(),
+	})
+	if len(pending) > maxPendingAppends {
+		pending = pending[len(pending)-maxPendingAppends:]
+	}
+	d.pending[msg.To] = pending
+}
+
+// acknowledged records the acknowledgment of the entries appended to the
+// given peer, up to the index of the given message. The latency is that of
+// the oldest append acknowledged, since the appends which followed it were
+// held up by it. Appends of terms earlier than the acknowledgment are dropped,
+// as they are never acknowledged.
+func (d *grayFailureDetector) acknowledged(from uint64, msg *raftpb.Message) {
+	if d == nil || msg.Type != raftpb.MsgAppResp || msg.Reject {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var oldest time.Time
+	var remaining []pendingAppend
+	for _, p := range d.pending[from] {
+		switch {
+		case p.term < msg.Term:
+		case p.term > msg.Term || p.index > msg.Index:
+			remaining = append(remaining, p)
+		case oldest.IsZero() || p.sent.Before(oldest):
+			oldest = p.sent
+		}
+	}
+	d.pending[from] = remaining
+	if oldest.IsZero() {
+		return
+	}
+
+	latency := d.clock.Since(oldest)
+	d.metrics.AppendAckLatency.With("peer", strconv.FormatUint(from, 10)).Observe(latency.Seconds())
+	peer, ok := d.peers[from]
+	if !ok {
+		peer = &latencyAverage{}
+		d.peers[from] = peer
+	}
+	peer.observe(latency, ackLatencyWeight)
+}
+
+// persisted records the time taken by this node to persist raft data.
+func (d *grayFailureDetector) persisted(latency time.Duration) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.local.observe(latency, persistLatencyWeight)
+}
+
+// evaluate classifies this node and the given peers, which are tracked by the
+// leader only. The latency of a peer is the larger of the moving average of
+// its acknowledgment latency and the age of its oldest append awaiting
+// acknowledgment, so that a peer which stops acknowledging altogether is
+// classified too. Unreachable peers are reported as such instead. Peers are
+// forgotten once this node no longer leads or they leave the channel.
+func (d *grayFailureDetector) evaluate(peers []uint64, unreachable func(uint64) bool) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.classify(d.self, &d.local, d.local.latency, DegradedPersist)
+
+	current := make(map[uint64]bool, len(peers))
+	for _, id := range peers {
+		current[id] = true
+	}
+	for id, peer := range d.peers {
+		if !current[id] {
+			if peer.degraded {
+				d.metrics.NodeDegraded.With("peer", strconv.FormatUint(id, 10)).Set(0)
+			}
+			delete(d.peers, id)
+		}
+	}
+	for id := range d.pending {
+		if !current[id] {
+			delete(d.pending, id)
+		}
+	}
+
+	now := d.clock.Now()
+	for _, id := range peers {
+		peer, ok := d.peers[id]
+		if !ok {
+			peer = &latencyAverage{}
+			d.peers[id] = peer
+		}
+		var latency float64
+		if !unreachable(id) {
+			latency = peer.latency
+			if pending := d.pending[id]; len(pending) > 0 {
+				if age := now.Sub(pending[0].sent).Seconds(); age > latency {
+					latency = age
+				}
+			}
+		}
+		d.classify(id, peer, latency, DegradedAppendAck)
+	}
+}
+
+func (d *grayFailureDetector) classify(id uint64, avg *latencyAverage, latency float64, cause string) {
+	avg.classified = latency
+	threshold := d.threshold.Seconds()
+	switch {
+	case !avg.degraded && latency > threshold:
+		avg.degraded = true
+		d.logger.Warnf("Node %d is degraded, as its %s of %s exceeds %s", id, cause, formatSeconds(latency), d.threshold)
+		d.metrics.NodeDegraded.With("peer", strconv.FormatUint(id, 10)).Set(1)
+		d.notify(Event{Type: EventNodeDegraded, Peer: id, Cause: cause})
+	case avg.degraded && latency < threshold/2:
+		avg.degraded = false
+		d.logger.Infof("Node %d recovered, as its %s dropped to %s", id, cause, formatSeconds(latency))
+		d.metrics.NodeDegraded.With("peer", strconv.FormatUint(id, 10)).Set(0)
+		d.notify(Event{Type: EventNodeRecovered, Peer: id, Cause: cause})
+	}
+}
+
+// degraded returns the nodes currently classified as degraded, by ID.
+func (d *grayFailureDetector) degraded() []DegradedNode {
+	if d == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var nodes []DegradedNode
+	if d.local.degraded {
+		nodes = append(nodes, DegradedNode{ID: d.self, Cause: DegradedPersist, Latency: formatSeconds(d.local.classified)})
+	}
+	for id, peer := range d.peers {
+		if peer.degraded {
+			nodes = append(nodes, DegradedNode{ID: id, Cause: DegradedAppendAck, Latency: formatSeconds(peer.classified)})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes
+}
+
+func formatSeconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).String()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestGrayFailureDetector(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	logger := flogging.MustGetLogger("test")
+	latency := &metricsfakes.Histogram{}
+	latency.WithReturns(latency)
+	degradedGauge := &metricsfakes.Gauge{}
+	degradedGauge.WithReturns(degradedGauge)
+	m := &Metrics{AppendAckLatency: latency, NodeDegraded: degradedGauge}
+	var events []Event
+	notify := func(e Event) { events = append(events, e) }
+	allReachable := func(uint64) bool { return false }
+
+	assert.Nil(t, newGrayFailureDetector(logger, clock, 1, 0, m, notify))
+	var disabled *grayFailureDetector
+	disabled.sent(raftpb.Message{Type: raftpb.MsgApp, To: 2, Entries: []raftpb.Entry{{Index: 1}}})
+	disabled.acknowledged(2, &raftpb.Message{Type: raftpb.MsgAppResp, Index: 1})
+	disabled.persisted(time.Hour)
+	disabled.evaluate([]uint64{2}, allReachable)
+	assert.Nil(t, disabled.degraded())
+
+	d := newGrayFailureDetector(logger, clock, 1, time.Second, m, notify)
+
+	appendTo := func(to, term, index uint64) {
+		d.sent(raftpb.Message{Type: raftpb.MsgApp, To: to, Term: term, Entries: []raftpb.Entry{{Index: index}}})
+	}
+	ack := func(from, term, index uint64) {
+		d.acknowledged(from, &raftpb.Message{Type: raftpb.MsgAppResp, From: from, Term: term, Index: index})
+	}
+
+	// peer 2 acknowledges appends right away, while peer 3 takes 3s
+	for i := uint64(1); i <= 10; i++ {
+		appendTo(2, 1, i)
+		appendTo(3, 1, i)
+		ack(2, 1, i)
+		clock.Increment(3 * time.Second)
+		ack(3, 1, i)
+	}
+	assert.Equal(t, 20, latency.ObserveCallCount())
+	assert.Equal(t, float64(3), latency.ObserveArgsForCall(19))
+	d.evaluate([]uint64{2, 3}, allReachable)
+	assert.Equal(t, []DegradedNode{{ID: 3, Cause: DegradedAppendAck, Latency: "2.677877452s"}}, d.degraded())
+	assert.Len(t, events, 1)
+	assert.Equal(t, Event{Type: EventNodeDegraded, Peer: 3, Cause: DegradedAppendAck}, events[0])
+
+	// a rejection, or an acknowledgment of an earlier term, is not timed
+	appendTo(3, 2, 11)
+	d.acknowledged(3, &raftpb.Message{Type: raftpb.MsgAppResp, Term: 2, Index: 11, Reject: true})
+	ack(3, 1, 11)
+	assert.Equal(t, 20, latency.ObserveCallCount())
+
+	// the latency recovers once it drops below half of the threshold,
+	// rather than as soon as it drops below the threshold
+	for i := uint64(11); i <= 20; i++ {
+		appendTo(3, 2, i)
+		clock.Increment(100 * time.Millisecond)
+		ack(3, 2, i)
+		d.evaluate([]uint64{2, 3}, allReachable)
+		if len(d.degraded()) == 0 {
+			break
+		}
+	}
+	assert.Empty(t, d.degraded())
+	assert.Len(t, events, 2)
+	assert.Equal(t, Event{Type: EventNodeRecovered, Peer: 3, Cause: DegradedAppendAck}, events[1])
+
+	// a peer which stops acknowledging is degraded, unless it is unreachable
+	appendTo(2, 2, 21)
+	clock.Increment(2 * time.Second)
+	d.evaluate([]uint64{2, 3}, func(id uint64) bool { return id == 2 })
+	assert.Empty(t, d.degraded())
+	d.evaluate([]uint64{2, 3}, allReachable)
+	assert.Equal(t, []DegradedNode{{ID: 2, Cause: DegradedAppendAck, Latency: "2s"}}, d.degraded())
+
+	// peers are forgotten once this node no longer leads
+	d.evaluate(nil, allReachable)
+	assert.Empty(t, d.degraded())
+	assert.Empty(t, d.pending)
+	assert.Equal(t, float64(0), degradedGauge.SetArgsForCall(degradedGauge.SetCallCount()-1))
+
+	// this node is degraded while persisting raft data is slow
+	for i := 0; i < 10; i++ {
+		d.persisted(2 * time.Second)
+	}
+	d.evaluate(nil, allReachable)
+	degraded := d.degraded()
+	assert.Len(t, degraded, 1)
+	assert.Equal(t, uint64(1), degraded[0].ID)
+	assert.Equal(t, DegradedPersist, degraded[0].Cause)
+	assert.Equal(t, Event{Type: EventNodeDegraded, Peer: 1, Cause: DegradedPersist}, events[len(events)-1])
+}
+
+func TestGrayFailureDetectorEvaluatesUnreachableConcurrently(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	latency := &metricsfakes.Histogram{}
+	latency.WithReturns(latency)
+	degradedGauge := &metricsfakes.Gauge{}
+	degradedGauge.WithReturns(degradedGauge)
+	m := &Metrics{AppendAckLatency: latency, NodeDegraded: degradedGauge}
+	d := newGrayFailureDetector(flogging.MustGetLogger("test"), clock, 1, time.Second, m, func(Event) {})
+
+	// the goroutine sending messages marks peers while the chain evaluates them
+	n := &node{unreachable: make(map[uint64]struct{})}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			n.markUnreachable(uint64(2 + i%2))
+			n.markReachable(uint64(2 + (i+1)%2))
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		d.sent(raftpb.Message{Type: raftpb.MsgApp, To: 2, Term: 1, Entries: []raftpb.Entry{{Index: uint64(i + 1)}}})
+		d.evaluate([]uint64{2, 3}, n.isUnreachable)
+	}
+	wg.Wait()
+}
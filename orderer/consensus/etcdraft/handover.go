@@ -0,0 +1,74 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/etcd/raft"
+)
+
+// handOverPollInterval is how often HandOver checks whether
+// the leadership moved to another node.
+const handOverPollInterval = 50 * time.Millisecond
+
+// HasDuties returns whether the node leads the channel along with other
+// consenters, in which case it hands over the leadership before it halts.
+func (c *Chain) HasDuties() bool {
+	if atomic.LoadUint64(&c.lastKnownLeader) != c.raftID {
+		return false
+	}
+	return len(c.raftMetadata().Consenters) > 1
+}
+
+// HandOver transfers the leadership of the channel to the reachable follower
+// with the most of the log, so that the channel does not stall for an election
+// timeout once this node halts. It returns once another node leads the channel,
+// or once the context is done, in which case the followers elect a leader as
+// usual once they notice that this node is gone.
+func (c *Chain) HandOver(ctx context.Context) {
+	if c.isRunning() != nil || !c.HasDuties() {
+		return
+	}
+
+	status := c.Node.Status()
+	var transferee, match uint64
+	for id, pr := range status.Progress {
+		if id == c.raftID || c.Node.isUnreachable(id) {
+			continue
+		}
+		if transferee == raft.None || pr.Match > match || (pr.Match == match && id < transferee) {
+			transferee, match = id, pr.Match
+		}
+	}
+	if transferee == raft.None {
+		c.logger.Infof("No reachable follower to hand over the leadership to")
+		return
+	}
+
+	c.logger.Infof("Handing over the leadership to node %d before halting", transferee)
+	c.Node.TransferLeadership(ctx, c.raftID, transferee)
+
+	ticker := time.NewTicker(handOverPollInterval)
+	defer ticker.Stop()
+	for {
+		if lead := atomic.LoadUint64(&c.lastKnownLeader); lead != c.raftID && lead != raft.None {
+			c.logger.Infof("Handed over the leadership, node %d leads the channel", lead)
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-c.doneC:
+			return
+		case <-ctx.Done():
+			c.logger.Warnf("Leadership was not handed over to node %d in time", transferee)
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/orderer/consensus"
+)
+
+// maxHealthTransitions bounds the transitions retained for replay.
+const maxHealthTransitions = 128
+
+// healthLog tracks whether a chain has errored, as signalled to the deliver
+// front-end by Errored, and records the transitions into and out of the
+// errored state. A recovery replaces the channel returned by Errored, hence
+// subscribers which only wait on it miss the recoveries and errors which
+// happen in between; the recorded transitions let them replay those instead.
+type healthLog struct {
+	clock clock.Clock
+
+	lock        sync.RWMutex
+	errorC      chan struct{} // returned by Errored(), closed while errored
+	sequence    uint64
+	transitions []consensus.HealthTransition
+	changedC    chan struct{} // closed and replaced on every transition
+}
+
+func newHealthLog(clock clock.Clock) *healthLog {
+	return &healthLog{
+		clock:    clock,
+		errorC:   make(chan struct{}),
+		changedC: make(chan struct{}),
+	}
+}
+
+// erroredC returns the channel which is closed while the chain has errored.
+func (h *healthLog) erroredC() <-chan struct{} {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.errorC
+}
+
+// errored marks the chain as errored, unless it already is.
+func (h *healthLog) errored() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	select {
+	case <-h.errorC:
+		return
+	default:
+	}
+	close(h.errorC)
+	h.record(true)
+}
+
+// recovered marks the chain as recovered, if it has errored.
+func (h *healthLog) recovered() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	select {
+	case <-h.errorC:
+	default:
+		return
+	}
+	h.errorC = make(chan struct{})
+	h.record(false)
+}
+
+func (h *healthLog) record(errored bool) {
+	h.sequence++
+	h.transitions = append(h.transitions, consensus.HealthTransition{
+		Sequence: h.sequence,
+		Errored:  errored,
+		Time:     h.clock.Now(),
+	})
+	if len(h.transitions) > maxHealthTransitions {
+		h.transitions = h.transitions[len(h.transitions)-maxHealthTransitions:]
+	}
+	close(h.changedC)
+	h.changedC = make(chan struct{})
+}
+
+// since returns the retained transitions with a sequence greater than the
+// given one, along with the channel closed on the next transition.
+func (h *healthLog) since(after uint64) ([]consensus.HealthTransition, <-chan struct{}) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	var transitions []consensus.HealthTransition
+	for _, t := range h.transitions {
+		if t.Sequence > after {
+			transitions = append(transitions, t)
+		}
+	}
+	return transitions, h.changedC
+}
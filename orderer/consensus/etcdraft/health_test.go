@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthLog(t *testing.T) {
+	start := time.Now()
+	clock := fakeclock.NewFakeClock(start)
+	h := newHealthLog(clock)
+
+	transitions, changedC := h.since(0)
+	assert.Empty(t, transitions)
+	assert.NotNil(t, changedC)
+
+	// recovering a chain which has not errored is not a transition
+	errorC := h.erroredC()
+	h.recovered()
+	assert.Equal(t, errorC, h.erroredC())
+	assert.False(t, isClosed(changedC))
+
+	h.errored()
+	assert.Equal(t, errorC, h.erroredC())
+	assert.True(t, isClosed(errorC))
+	assert.True(t, isClosed(changedC))
+
+	// erroring again is not a transition
+	_, changedC = h.since(0)
+	h.errored()
+	assert.False(t, isClosed(changedC))
+
+	clock.Increment(time.Second)
+	h.recovered()
+	assert.True(t, isClosed(changedC))
+	assert.False(t, isClosed(h.erroredC()))
+
+	// a subscriber which only waited on the closed errorC missed the
+	// recovery and the error which followed, which are replayed in order
+	clock.Increment(time.Second)
+	h.errored()
+	transitions, changedC = h.since(1)
+	assert.Equal(t, []consensus.HealthTransition{
+		{Sequence: 2, Errored: false, Time: start.Add(time.Second)},
+		{Sequence: 3, Errored: true, Time: start.Add(2 * time.Second)},
+	}, transitions)
+	assert.False(t, isClosed(changedC))
+
+	transitions, _ = h.since(3)
+	assert.Empty(t, transitions)
+
+	// older transitions are discarded
+	for i := 0; i < maxHealthTransitions; i++ {
+		h.recovered()
+		h.errored()
+	}
+	transitions, _ = h.since(0)
+	assert.Len(t, transitions, maxHealthTransitions)
+	assert.Equal(t, uint64(2*maxHealthTransitions+3), transitions[maxHealthTransitions-1].Sequence)
+	assert.Equal(t, uint64(maxHealthTransitions+4), transitions[0].Sequence)
+}
+
+func isClosed(c <-chan struct{}) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
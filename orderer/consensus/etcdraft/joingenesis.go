@@ -0,0 +1,57 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hyperledger/fabric/core/middleware"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+// genesisJoinHandler creates the etcdraft chains of channels
+// out of the genesis blocks this node is joined with.
+type genesisJoinHandler struct {
+	participation *participationHandler
+}
+
+// GenesisJoinHandler returns a handler which creates the chain of a channel that deprecates
+// the system channel, for POST requests whose body is the marshaled genesis block of the
+// channel. The consenters of the channel are joined with the same genesis block, and
+// elect a leader once a quorum of them is joined.
+//
+// It is kept for clients of the genesis join API, and joins channels like the handler returned
+// by ParticipationHandler, except that it accepts genesis blocks only. Likewise, requests
+// are refused unless they are made over TLS with a verified client certificate.
+func (c *Consenter) GenesisJoinHandler() http.Handler {
+	return middleware.RequireCert()(&genesisJoinHandler{participation: &participationHandler{consenter: c}})
+}
+
+func (h *genesisJoinHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading genesis block: %s", err))
+		return
+	}
+	block, err := utils.UnmarshalBlock(body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("malformed genesis block: %s", err))
+		return
+	}
+	if block.Header != nil && block.Header.Number != 0 {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid genesis block: block %d is not a genesis block", block.Header.Number))
+		return
+	}
+	h.participation.joinBlock(resp, block, "genesis block")
+}
@@ -0,0 +1,160 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/configtxgentest"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/encoder"
+	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenesisJoinHandler(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	consenter := newConsenter(t, ca, "orderer1")
+
+	certDir, err := ioutil.TempDir("", "joingenesis")
+	require.NoError(t, err)
+	defer os.RemoveAll(certDir)
+	serverCertPath := filepath.Join(certDir, "server.crt")
+	clientCertPath := filepath.Join(certDir, "client.crt")
+	require.NoError(t, ioutil.WriteFile(serverCertPath, consenter.ServerTlsCert, 0600))
+	require.NoError(t, ioutil.WriteFile(clientCertPath, consenter.ClientTlsCert, 0600))
+
+	genesisBlock := func(systemChannel, deprecated bool) *common.Block {
+		conf := configtxgentest.Load(genesisconfig.SampleDevModeEtcdRaftProfile)
+		if !systemChannel {
+			conf.Consortiums = nil
+		}
+		conf.Orderer.Capabilities = map[string]bool{capabilities.OrdererV1_1: true, capabilities.OrdererV2_1: deprecated}
+		conf.Orderer.EtcdRaft.Consenters = []*etcdraft.Consenter{{
+			Host:          consenter.Host,
+			Port:          consenter.Port,
+			ServerTlsCert: []byte(serverCertPath),
+			ClientTlsCert: []byte(clientCertPath),
+		}}
+		return encoder.New(conf).GenesisBlockForChannel("mychannel")
+	}
+
+	var joined []*common.Block
+	var joinErr error
+	c := &Consenter{
+		Chains: chainsByID{},
+		Logger: flogging.MustGetLogger("test"),
+		Cert:   consenter.ServerTlsCert,
+		JoinChain: func(genesisBlock *common.Block) error {
+			joined = append(joined, genesisBlock)
+			return joinErr
+		},
+	}
+	handler := c.GenesisJoinHandler()
+
+	serve := func(method string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/etcdraft/join/genesis", bytes.NewReader(body))
+		req.TLS = clientAuthenticated()
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+	errorOf := func(resp *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return body["error"]
+	}
+
+	t.Run("joins the channel", func(t *testing.T) {
+		block := genesisBlock(false, true)
+		resp := serve(http.MethodPost, utils.MarshalOrPanic(block))
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+		assert.JSONEq(t, `{"channel":"mychannel"}`, resp.Body.String())
+		require.Len(t, joined, 1)
+		assert.True(t, proto.Equal(block, joined[0]))
+	})
+
+	t.Run("fails joining the channel", func(t *testing.T) {
+		joinErr = errors.New("disk is full")
+		defer func() { joinErr = nil }()
+		resp := serve(http.MethodPost, utils.MarshalOrPanic(genesisBlock(false, true)))
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		assert.Equal(t, "failed joining channel mychannel: disk is full", errorOf(resp))
+	})
+
+	t.Run("channel already exists", func(t *testing.T) {
+		c.Chains = chainsByID{"mychannel": &multichannel.ChainSupport{}}
+		defer func() { c.Chains = chainsByID{} }()
+		resp := serve(http.MethodPost, utils.MarshalOrPanic(genesisBlock(false, true)))
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Equal(t, "channel mychannel already exists", errorOf(resp))
+	})
+
+	t.Run("not a consenter", func(t *testing.T) {
+		c.Cert = newConsenter(t, ca, "orderer2").ServerTlsCert
+		defer func() { c.Cert = consenter.ServerTlsCert }()
+		resp := serve(http.MethodPost, utils.MarshalOrPanic(genesisBlock(false, true)))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, errorOf(resp), "this node is not a consenter of channel mychannel")
+	})
+
+	t.Run("invalid genesis blocks", func(t *testing.T) {
+		notGenesis := genesisBlock(false, true)
+		notGenesis.Header.Number = 5
+		malformed := genesisBlock(false, true)
+		malformed.Header.DataHash = []byte{1, 2, 3}
+
+		for _, tc := range []struct {
+			name          string
+			block         *common.Block
+			expectedError string
+		}{
+			{"not deprecating the system channel", genesisBlock(false, false),
+				"invalid genesis block: channel mychannel does not deprecate the system channel, as it lacks the V2_1 orderer capability"},
+			{"system channel", genesisBlock(true, true), "invalid genesis block: channel mychannel is a system channel"},
+			{"not a genesis block", notGenesis, "invalid genesis block: block 5 is not a genesis block"},
+			{"malformed block", malformed, "invalid genesis block: block is malformed"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := serve(http.MethodPost, utils.MarshalOrPanic(tc.block))
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Equal(t, tc.expectedError, errorOf(resp))
+			})
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		resp := serve(http.MethodPost, []byte("not a block"))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serve(http.MethodGet, nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+
+		req := httptest.NewRequest(http.MethodPost, "/etcdraft/join/genesis", bytes.NewReader(utils.MarshalOrPanic(genesisBlock(false, true))))
+		resp = httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	})
+
+	assert.Len(t, joined, 2)
+}
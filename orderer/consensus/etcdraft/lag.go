@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	// MaxSnapshotCatchUpEntries bounds the number of entries preserved in
+	// memory when a snapshot is taken, regardless of the observed follower lag.
+	MaxSnapshotCatchUpEntries = uint64(10000)
+
+	// lagPercentile is the percentile of the observed follower lag that
+	// entries are preserved for, on top of DefaultSnapshotCatchUpEntries.
+	lagPercentile = 0.95
+
+	// lagWindow is the number of most recent lag samples taken into account.
+	lagWindow = 360
+)
+
+// lagTracker records how far followers lag behind the leader, and derives
+// from it the number of entries to preserve in memory when a snapshot is taken,
+// so that followers which are normally slow can catch up without a snapshot.
+type lagTracker struct {
+	lock    sync.Mutex
+	samples []uint64 // ring buffer of the most recent samples
+	next    int
+}
+
+// observe records the lag of a follower, in entries.
+func (lt *lagTracker) observe(lag uint64) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	if len(lt.samples) < lagWindow {
+		lt.samples = append(lt.samples, lag)
+		return
+	}
+	lt.samples[lt.next] = lag
+	lt.next = (lt.next + 1) % lagWindow
+}
+
+// catchUpEntries returns the number of entries to preserve in memory,
+// which is the lag percentile observed plus DefaultSnapshotCatchUpEntries,
+// capped by MaxSnapshotCatchUpEntries.
+func (lt *lagTracker) catchUpEntries() uint64 {
+	lt.lock.Lock()
+	sorted := make([]uint64, len(lt.samples))
+	copy(sorted, lt.samples)
+	lt.lock.Unlock()
+
+	if len(sorted) == 0 {
+		return DefaultSnapshotCatchUpEntries
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	lag := sorted[int(float64(len(sorted)-1)*lagPercentile)]
+
+	entries := lag + DefaultSnapshotCatchUpEntries
+	if entries > MaxSnapshotCatchUpEntries {
+		return MaxSnapshotCatchUpEntries
+	}
+	return entries
+}
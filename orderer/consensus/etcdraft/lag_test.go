@@ -0,0 +1,37 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLagTracker(t *testing.T) {
+	lt := &lagTracker{}
+	assert.Equal(t, DefaultSnapshotCatchUpEntries, lt.catchUpEntries())
+
+	// a follower that is normally slow
+	for i := 0; i < 100; i++ {
+		lt.observe(uint64(i))
+	}
+	assert.Equal(t, 94+DefaultSnapshotCatchUpEntries, lt.catchUpEntries())
+
+	// older samples fall out of the window
+	for i := 0; i < lagWindow; i++ {
+		lt.observe(5)
+	}
+	assert.Len(t, lt.samples, lagWindow)
+	assert.Equal(t, 5+DefaultSnapshotCatchUpEntries, lt.catchUpEntries())
+
+	// memory is bounded regardless of the lag
+	for i := 0; i < lagWindow; i++ {
+		lt.observe(1000000)
+	}
+	assert.Equal(t, MaxSnapshotCatchUpEntries, lt.catchUpEntries())
+}
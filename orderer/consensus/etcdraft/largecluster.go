@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// clusterSizeWarnings returns warnings about the latency of a channel with the given
+// number of consenters and election tick, or nil if its cluster is not large.
+func clusterSizeWarnings(consenters int, electionTick int) []string {
+	if consenters <= etcdraft.LargeClusterSize {
+		return nil
+	}
+	warnings := []string{fmt.Sprintf("%d consenters form a large cluster, where the leader sends every block to %d followers "+
+		"and waits for %d of them to persist it, which increases the latency of ordering and the bandwidth of the leader",
+		consenters, consenters-1, consenters/2)}
+	if electionTick < etcdraft.LargeClusterElectionTick {
+		warnings = append(warnings, fmt.Sprintf("election tick %d is lower than the %d recommended for large clusters, "+
+			"followers may start elections while the leader is busy sending blocks", electionTick, etcdraft.LargeClusterElectionTick))
+	}
+	return warnings
+}
+
+// warnLargeCluster logs the warnings about the latency of the
+// channel, if its cluster of the given number of consenters is large.
+func (c *Chain) warnLargeCluster(consenters int) {
+	for _, warning := range clusterSizeWarnings(consenters, c.opts.ElectionTick) {
+		c.logger.Warnf("%s", warning)
+	}
+}
+
+// coalesceHeartbeats returns the given messages without the heartbeats to the
+// nodes which are sent an append in the same batch. An append carries the
+// commit index, and its response informs the leader as well as the response
+// of the heartbeat does, hence under load, which is when the leader of a large
+// cluster is short of bandwidth, the heartbeats are redundant. Heartbeats with
+// a context, which confirm read indices, are kept.
+func coalesceHeartbeats(msgs []raftpb.Message) []raftpb.Message {
+	var appended map[uint64]struct{}
+	for _, msg := range msgs {
+		if msg.Type == raftpb.MsgApp {
+			if appended == nil {
+				appended = make(map[uint64]struct{})
+			}
+			appended[msg.To] = struct{}{}
+		}
+	}
+	if appended == nil {
+		return msgs
+	}
+
+	coalesced := make([]raftpb.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		if _, isAppended := appended[msg.To]; isAppended && msg.Type == raftpb.MsgHeartbeat && len(msg.Context) == 0 {
+			continue
+		}
+		coalesced = append(coalesced, msg)
+	}
+	return coalesced
+}
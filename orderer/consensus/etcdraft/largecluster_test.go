@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestClusterSizeWarnings(t *testing.T) {
+	assert.Nil(t, clusterSizeWarnings(etcdraft.LargeClusterSize, 10))
+
+	warnings := clusterSizeWarnings(11, etcdraft.LargeClusterElectionTick)
+	assert.Equal(t, []string{"11 consenters form a large cluster, where the leader sends every block to 10 followers " +
+		"and waits for 5 of them to persist it, which increases the latency of ordering and the bandwidth of the leader"}, warnings)
+
+	warnings = clusterSizeWarnings(21, 10)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "election tick 10 is lower than the 20 recommended for large clusters, "+
+		"followers may start elections while the leader is busy sending blocks", warnings[1])
+}
+
+func TestLargeClusterConfigTemplate(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	var template ConfigTemplate
+	for i := 1; i <= etcdraft.MaxClusterSize; i++ {
+		template.Consenters = append(template.Consenters, newConsenterTemplate(t, ca, fmt.Sprintf("node%d.example.com", i)))
+	}
+
+	md, err := template.ConfigMetadata()
+	require.NoError(t, err)
+	assert.Len(t, md.Consenters, etcdraft.MaxClusterSize)
+	assert.Equal(t, uint32(etcdraft.LargeClusterElectionTick), md.Options.ElectionTick)
+
+	template.Options = &etcdraft.Options{ElectionTick: 30}
+	md, err = template.ConfigMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(30), md.Options.ElectionTick)
+
+	template.Consenters = template.Consenters[:etcdraft.LargeClusterSize]
+	template.Options = nil
+	md, err = template.ConfigMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultOptions().ElectionTick, md.Options.ElectionTick)
+}
+
+func TestCoalesceHeartbeats(t *testing.T) {
+	msgs := []raftpb.Message{
+		{Type: raftpb.MsgHeartbeat, To: 2},
+		{Type: raftpb.MsgHeartbeat, To: 3},
+		{Type: raftpb.MsgHeartbeat, To: 4, Context: []byte("read index")},
+	}
+	assert.Equal(t, msgs, coalesceHeartbeats(msgs))
+
+	msgs = append(msgs,
+		raftpb.Message{Type: raftpb.MsgApp, To: 2, Index: 5},
+		raftpb.Message{Type: raftpb.MsgApp, To: 4, Index: 5},
+	)
+	assert.Equal(t, []raftpb.Message{
+		{Type: raftpb.MsgHeartbeat, To: 3},
+		{Type: raftpb.MsgHeartbeat, To: 4, Context: []byte("read index")},
+		{Type: raftpb.MsgApp, To: 2, Index: 5},
+		{Type: raftpb.MsgApp, To: 4, Index: 5},
+	}, coalesceHeartbeats(msgs))
+}
@@ -0,0 +1,182 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft"
+)
+
+// markerRequest is a request to propose a marker, answered with the outcome.
+type markerRequest struct {
+	marker *etcdraft.Marker
+	errC   chan error
+}
+
+// entryMarker returns the marker carried by the data of a normal
+// raft entry, or nil if the entry carries a block. Blocks do not
+// hold the fields of a marker, hence their proposer is zero.
+func entryMarker(data []byte) *etcdraft.Marker {
+	marker := &etcdraft.Marker{}
+	if err := proto.Unmarshal(data, marker); err != nil || marker.Proposer == 0 {
+		return nil
+	}
+	return marker
+}
+
+// ProposeMarker proposes a marker of the given type, which every node handles once
+// it applies the entry carrying it, in the order of the raft log. It must be called
+// on the leader, and returns once the marker is proposed. Markers are refused until
+// every consenter of the channel advertises that it handles them.
+func (c *Chain) ProposeMarker(markerType etcdraft.Marker_Type, reason string) error {
+	if _, known := etcdraft.Marker_Type_name[int32(markerType)]; !known || markerType == etcdraft.Marker_UNKNOWN {
+		return errors.Errorf("unknown marker type %d", markerType)
+	}
+	if isRestartSlotMarker(markerType) {
+		return errors.Errorf("%s markers are proposed through the restart slot", markerType)
+	}
+
+	return c.submitMarker(&etcdraft.Marker{Type: markerType, Proposer: c.raftID, Reason: reason})
+}
+
+// submitMarker hands the given marker to serveRequest to be proposed,
+// and returns once it is proposed.
+func (c *Chain) submitMarker(marker *etcdraft.Marker) error {
+	if err := c.isRunning(); err != nil {
+		return err
+	}
+
+	req := &markerRequest{
+		marker: marker,
+		errC:   make(chan error, 1),
+	}
+	select {
+	case c.markerC <- req:
+	case <-c.doneC:
+		return errors.Errorf("chain is stopped")
+	}
+	return <-req.errC
+}
+
+// proposeMarker proposes the marker of the request, given the current raft soft state.
+func (c *Chain) proposeMarker(req *markerRequest, soft raft.SoftState) error {
+	if soft.Lead != c.raftID {
+		return errors.Errorf("node %d is not the leader, the leader is node %d", c.raftID, soft.Lead)
+	}
+	// nodes unaware of markers would take the entry carrying one for a block
+	if c.FeatureVersion(FeatureMarkers) == 0 {
+		return errors.Errorf("markers are not supported by every consenter of the channel")
+	}
+	if isRestartSlotMarker(req.marker.Type) {
+		propose, err := c.admitRestartSlot(req.marker)
+		if err != nil || !propose {
+			return err
+		}
+	}
+
+	c.logger.Infof("Proposing %s marker: %s", req.marker.Type, req.marker.Reason)
+	data := utils.MarshalOrPanic(req.marker)
+	// Propose may block if the node is leaderless, see `becomeLeader`.
+	c.goroutines.spawn("propose_marker", func() {
+		if err := c.Node.Propose(context.TODO(), data); err != nil {
+			c.logger.Warnf("Failed to propose %s marker to raft: %s", req.marker.Type, err)
+		}
+	})
+	return nil
+}
+
+// applyMarker handles a marker carried by the raft entry at the given index. Since
+// entries after the last block are applied again upon restart, handling a marker
+// must have the same outcome when repeated.
+func (c *Chain) applyMarker(marker *etcdraft.Marker, index uint64) {
+	c.logger.Infof("Applying %s marker proposed by node %d at raft index %d: %s", marker.Type, marker.Proposer, index, marker.Reason)
+
+	switch marker.Type {
+	case etcdraft.Marker_SNAPSHOT:
+		select {
+		case c.gcC <- &gc{index: index, state: c.confState, block: captureBlock(c.lastBlock)}:
+			c.accDataSize = 0
+			c.lastSnapBlockNum = c.lastBlock.Header.Number
+			c.Metrics.SnapshotBlockNumber.Set(float64(c.lastBlock.Header.Number))
+		default:
+			c.logger.Warnf("Snapshotting is in progress, skipping snapshot requested by marker at raft index %d", index)
+		}
+	case etcdraft.Marker_PAUSE:
+		c.Pause()
+	case etcdraft.Marker_RESUME:
+		c.Resume()
+	case etcdraft.Marker_RESTART_SLOT_ACQUIRE, etcdraft.Marker_RESTART_SLOT_RELEASE:
+		c.restartSlot.apply(marker, c.clock.Now())
+	default:
+		c.logger.Warnf("Ignoring marker of unknown type %d at raft index %d", marker.Type, index)
+		return
+	}
+
+	c.notify(Event{Type: EventMarker, Marker: marker.Type.String(), Peer: marker.Node, Cause: marker.Reason})
+}
+
+func isRestartSlotMarker(markerType etcdraft.Marker_Type) bool {
+	return markerType == etcdraft.Marker_RESTART_SLOT_ACQUIRE || markerType == etcdraft.Marker_RESTART_SLOT_RELEASE
+}
+
+// markerHandler proposes markers to the etcdraft
+// chain of the channel given in the query.
+type markerHandler struct {
+	consenter *Consenter
+}
+
+// MarkerHandler returns a handler proposing markers for POST requests of the
+// form ?channel=<channel ID>&type=<snapshot|pause|resume>&reason=<reason>,
+// which must be sent to the leader.
+func (c *Consenter) MarkerHandler() http.Handler {
+	return &markerHandler{consenter: c}
+}
+
+func (h *markerHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	query := req.URL.Query()
+	channelID := query.Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+	markerType, known := etcdraft.Marker_Type_value[strings.ToUpper(query.Get("type"))]
+	if !known || markerType == int32(etcdraft.Marker_UNKNOWN) {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid marker type: %q", query.Get("type")))
+		return
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	if err := chain.ProposeMarker(etcdraft.Marker_Type(markerType), query.Get("reason")); err != nil {
+		sendJSONError(resp, http.StatusConflict, err.Error())
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(resp).Encode(map[string]string{"status": "proposed"}); err != nil {
+		h.consenter.Logger.Errorw("failed to encode marker response", "channel", channelID, "error", err)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntryMarker(t *testing.T) {
+	marker := &etcdraft.Marker{Type: etcdraft.Marker_PAUSE, Proposer: 2, Reason: "maintenance"}
+	assert.Equal(t, marker.String(), entryMarker(utils.MarshalOrPanic(marker)).String())
+
+	// a marker of an unknown type is still a marker
+	marker = &etcdraft.Marker{Type: etcdraft.Marker_Type(42), Proposer: 1}
+	assert.NotNil(t, entryMarker(utils.MarshalOrPanic(marker)))
+
+	block := common.NewBlock(5, []byte{1, 2, 3})
+	block.Data.Data = [][]byte{{4, 5, 6}}
+	assert.Nil(t, entryMarker(utils.MarshalOrPanic(block)))
+	assert.Nil(t, entryMarker(utils.MarshalOrPanic(&etcdraft.SnapshotData{Block: block})))
+	assert.Nil(t, entryMarker([]byte{0xff}))
+}
@@ -0,0 +1,241 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// errNoMembershipDrift is returned when a membership
+// repair is requested but there is nothing to repair.
+var errNoMembershipDrift = errors.New("raft nodes match the consenters")
+
+// MembershipDrift describes how the nodes of the raft configuration of a chain
+// differ from the consenters recorded in its block metadata. A drift is expected
+// while a ConfChange is in flight, and is otherwise resolved by proposing the
+// ConfChange which was never applied.
+type MembershipDrift struct {
+	// Nodes are the nodes of the raft configuration.
+	Nodes []uint64 `json:"nodes"`
+	// Consenters are the IDs of the consenters in the block metadata.
+	Consenters []uint64 `json:"consenters"`
+	// Missing are the consenters which are not nodes of the raft configuration.
+	Missing []uint64 `json:"missing,omitempty"`
+	// Extra are the nodes of the raft configuration which are not consenters.
+	Extra []uint64 `json:"extra,omitempty"`
+}
+
+// Drifted returns whether the raft configuration differs from the consenters.
+func (md MembershipDrift) Drifted() bool {
+	return len(md.Missing) > 0 || len(md.Extra) > 0
+}
+
+// membershipDrift compares the nodes of the raft configuration with the consenters.
+func membershipDrift(nodes []uint64, consenters map[uint64]*etcdraft.Consenter) MembershipDrift {
+	md := MembershipDrift{
+		Nodes:      append([]uint64{}, nodes...),
+		Consenters: SliceOfConsentersIDs(consenters),
+	}
+	sort.Slice(md.Nodes, func(i, j int) bool { return md.Nodes[i] < md.Nodes[j] })
+	sort.Slice(md.Consenters, func(i, j int) bool { return md.Consenters[i] < md.Consenters[j] })
+
+	for _, id := range md.Consenters {
+		if !NodeExists(id, md.Nodes) {
+			md.Missing = append(md.Missing, id)
+		}
+	}
+	for _, id := range md.Nodes {
+		if _, exists := consenters[id]; !exists {
+			md.Extra = append(md.Extra, id)
+		}
+	}
+	return md
+}
+
+// driftChecker periodically compares the nodes of the raft configuration with the
+// consenters in the block metadata. Since both are updated at different points in
+// time, a drift is only reported once it is observed by two consecutive checks.
+type driftChecker struct {
+	logger *flogging.FabricLogger
+	drift  func() MembershipDrift
+	// drifted is called when a drift is confirmed, and resolved
+	// when a confirmed drift is no longer observed.
+	drifted  func(MembershipDrift)
+	resolved func()
+
+	last     MembershipDrift // observed by the previous check
+	reported MembershipDrift // reported to drifted, if not resolved since
+}
+
+// run checks for a drift every interval until doneC is closed.
+func (dc *driftChecker) run(interval time.Duration, doneC <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			dc.check()
+		case <-doneC:
+			return
+		}
+	}
+}
+
+func (dc *driftChecker) check() {
+	md := dc.drift()
+	last := dc.last
+	dc.last = md
+
+	if !md.Drifted() {
+		if dc.reported.Drifted() {
+			dc.logger.Infof("Raft nodes %v match the consenters again", md.Nodes)
+			dc.reported = MembershipDrift{}
+			dc.resolved()
+		}
+		return
+	}
+
+	if !sameDrift(md, last) || sameDrift(md, dc.reported) {
+		return
+	}
+
+	dc.logger.Warnf("Raft nodes %v drifted from consenters %v: missing nodes %v, extra nodes %v",
+		md.Nodes, md.Consenters, md.Missing, md.Extra)
+	dc.reported = md
+	dc.drifted(md)
+}
+
+func sameDrift(a, b MembershipDrift) bool {
+	return fmt.Sprint(a.Missing, a.Extra) == fmt.Sprint(b.Missing, b.Extra)
+}
+
+// membershipHandler serves the membership drift of the etcdraft chain of
+// the channel given in the query, and repairs it upon POST requests.
+type membershipHandler struct {
+	consenter *Consenter
+}
+
+// MembershipHandler returns a handler serving the membership drift of a chain
+// as JSON for GET requests of the form ?channel=<channel ID>, and repairing it
+// for POST requests of the same form, which must be sent to the leader.
+func (c *Consenter) MembershipHandler() http.Handler {
+	return &membershipHandler{consenter: c}
+}
+
+func (h *membershipHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	channelID := req.URL.Query().Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	if req.Method == http.MethodPost {
+		if err := chain.RepairMembership(); err != nil {
+			sendJSONError(resp, http.StatusConflict, err.Error())
+			return
+		}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(chain.MembershipDrift()); err != nil {
+		h.consenter.Logger.Errorw("failed to encode membership drift", "channel", channelID, "error", err)
+	}
+}
+
+// MembershipDrift compares the nodes of the raft configuration
+// of the chain with the consenters in its block metadata.
+func (c *Chain) MembershipDrift() MembershipDrift {
+	nodes, _ := c.confNodes.Load().([]uint64)
+	return membershipDrift(nodes, c.raftMetadata().Consenters)
+}
+
+// RepairMembership proposes the ConfChange which brings the raft configuration
+// of the chain in line with the consenters in its block metadata, or the one in
+// flight, if any. It must be called on the leader, and returns once the ConfChange
+// is proposed. Since a ConfChange adds or removes a single node, a drift by more
+// than one node takes several repairs.
+func (c *Chain) RepairMembership() error {
+	if err := c.isRunning(); err != nil {
+		return err
+	}
+
+	errC := make(chan error, 1)
+	select {
+	case c.repairC <- errC:
+	case <-c.doneC:
+		return errors.Errorf("chain is stopped")
+	}
+	return <-errC
+}
+
+// membershipRepair returns the ConfChange to propose in order to repair
+// the membership of the chain, given the current raft soft state.
+func (c *Chain) membershipRepair(soft raft.SoftState) (*raftpb.ConfChange, error) {
+	if soft.Lead != c.raftID {
+		return nil, errors.Errorf("node %d is not the leader, the leader is node %d", c.raftID, soft.Lead)
+	}
+	if c.confChangeInProgress != nil {
+		return c.confChangeInProgress, nil
+	}
+	if c.configInflight {
+		return nil, errors.New("a config block is in flight, retry once it is committed")
+	}
+
+	if !membershipDrift(c.confState.Nodes, c.raftMetadata().Consenters).Drifted() {
+		return nil, errNoMembershipDrift
+	}
+	return ConfChange(c.raftMetadata(), &c.confState), nil
+}
+
+func (c *Chain) setConfState(confState raftpb.ConfState) {
+	c.confState = confState
+	c.confNodes.Store(confState.Nodes)
+}
+
+func (c *Chain) newDriftChecker() *driftChecker {
+	return &driftChecker{
+		logger: c.logger,
+		drift:  c.MembershipDrift,
+		drifted: func(md MembershipDrift) {
+			c.Metrics.MembershipDrift.Set(float64(len(md.Missing) + len(md.Extra)))
+			event := Event{Type: EventMembershipDrift}
+			if len(md.Missing) > 0 {
+				event.AddedNode = md.Missing[0]
+			}
+			if len(md.Extra) > 0 {
+				event.RemovedNode = md.Extra[0]
+			}
+			c.notify(event)
+		},
+		resolved: func() {
+			c.Metrics.MembershipDrift.Set(0)
+		},
+	}
+}
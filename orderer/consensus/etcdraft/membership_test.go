@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMembershipDrift(t *testing.T) {
+	consenters := map[uint64]*etcdraft.Consenter{1: {}, 2: {}, 4: {}}
+
+	md := membershipDrift([]uint64{4, 2, 1}, consenters)
+	assert.False(t, md.Drifted())
+	assert.Equal(t, MembershipDrift{Nodes: []uint64{1, 2, 4}, Consenters: []uint64{1, 2, 4}}, md)
+
+	// a ConfChange adding node 4 was never applied
+	md = membershipDrift([]uint64{1, 2}, consenters)
+	assert.True(t, md.Drifted())
+	assert.Equal(t, []uint64{4}, md.Missing)
+	assert.Empty(t, md.Extra)
+
+	// a ConfChange removing node 3 was never applied
+	md = membershipDrift([]uint64{1, 2, 3, 4}, consenters)
+	assert.True(t, md.Drifted())
+	assert.Empty(t, md.Missing)
+	assert.Equal(t, []uint64{3}, md.Extra)
+}
+
+func TestDriftChecker(t *testing.T) {
+	drift := MembershipDrift{}
+	var drifted []MembershipDrift
+	var resolved int
+	dc := &driftChecker{
+		logger:   flogging.MustGetLogger("test"),
+		drift:    func() MembershipDrift { return drift },
+		drifted:  func(md MembershipDrift) { drifted = append(drifted, md) },
+		resolved: func() { resolved++ },
+	}
+
+	dc.check()
+	assert.Empty(t, drifted)
+
+	// a drift observed once may be a ConfChange in flight
+	drift = membershipDrift([]uint64{1, 2}, map[uint64]*etcdraft.Consenter{1: {}, 2: {}, 3: {}})
+	dc.check()
+	assert.Empty(t, drifted)
+
+	// and is reported once it persists
+	dc.check()
+	dc.check()
+	assert.Equal(t, []MembershipDrift{drift}, drifted)
+
+	// a different drift is reported once it persists too
+	drift = membershipDrift([]uint64{1, 2, 3, 4}, map[uint64]*etcdraft.Consenter{1: {}, 2: {}, 3: {}})
+	dc.check()
+	assert.Len(t, drifted, 1)
+	dc.check()
+	assert.Len(t, drifted, 2)
+
+	drift = membershipDrift([]uint64{1, 2, 3}, map[uint64]*etcdraft.Consenter{1: {}, 2: {}, 3: {}})
+	dc.check()
+	dc.check()
+	assert.Equal(t, 1, resolved)
+}
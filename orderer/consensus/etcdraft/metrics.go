@@ -81,6 +81,406 @@ var (
 		LabelNames:   []string{"channel"},
 		StatsdFormat: "%{#fqname}.%{channel}",
 	}
+	termOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "term",
+		Help:         "The current raft term of this node.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	commitIndexOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "commit_index",
+		Help:         "The highest raft log index known to be committed.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	appliedIndexOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "applied_index",
+		Help:         "The highest raft log index applied by this node.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	peerProgressStateOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "peer_progress_state",
+		Help:         "The replication state of a peer as seen by the leader: 0 if probe, 1 if replicate, 2 if snapshot, -1 once no longer tracked.",
+		LabelNames:   []string{"channel", "peer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}",
+	}
+	proposeQueueDepthOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "propose_queue_depth",
+		Help:         "The number of blocks created by the leader and waiting to be proposed to raft.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	blockMismatchesOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "block_mismatches",
+		Help:         "The number of sampled blocks of other consenters found not to match the local blocks.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	peerUnreachableOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "peer_unreachable",
+		Help:         "The number of times a peer became unreachable, by cause: dial_timeout, tls_failure or rpc_error.",
+		LabelNames:   []string{"channel", "peer", "cause"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}.%{cause}",
+	}
+	peerReachableOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "peer_reachable",
+		Help:         "The number of times a peer became reachable after being unreachable.",
+		LabelNames:   []string{"channel", "peer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}",
+	}
+	quotaThrottledOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "quota_throttled",
+		Help:         "The number of times the chain was throttled by its quota of a resource: ticks, persisted_bytes or applied_blocks.",
+		LabelNames:   []string{"channel", "resource"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{resource}",
+	}
+	commitBacklogOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "commit_backlog",
+		Help:         "The number of raft entries committed but not yet written to the ledger by this node.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	isPausedOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "is_paused",
+		Help:         "Whether the chain is paused by an administrator: 1 if it is paused else 0.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	trustChangedTimeOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "trust_changed_time",
+		Help:         "The time, in seconds since the epoch, the remote nodes trusted by the communication layer or their TLS certificates last changed.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	membershipDriftOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "membership_drift",
+		Help:         "The number of raft nodes missing from or extra to the consenters of the channel, once the drift persists beyond a check interval.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	wedgedOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "wedged",
+		Help:         "Whether the chain has not processed any event for longer than the watchdog timeout.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	confChangeStalledOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "conf_change_stalled",
+		Help:         "Whether a ConfChange has been in flight for longer than the ConfChange timeout, while transactions are refused.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	confChangeReproposalsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "conf_change_reproposals",
+		Help:         "The number of times the leader proposed a ConfChange again because it was not applied within the retry interval.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	confChangeRetriesExhaustedOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "conf_change_retries_exhausted",
+		Help:         "Whether the ConfChange in flight was proposed the maximum number of attempts without being applied, and is no longer retried.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	envelopesRejectedOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "envelopes_rejected",
+		Help:         "The number of transactions rejected before being ordered, by reason: paused, system_channel, size or config_pending.",
+		LabelNames:   []string{"channel", "reason"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{reason}",
+	}
+	drReplicationLagOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "dr_replication_lag",
+		Help:         "The number of blocks the leader has yet to stream to a DR consenter.",
+		LabelNames:   []string{"channel", "orderer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{orderer}",
+	}
+	drSendFailuresOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "dr_send_failures",
+		Help:         "The number of times the leader failed streaming blocks or snapshots to a DR consenter.",
+		LabelNames:   []string{"channel", "orderer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{orderer}",
+	}
+	blockTimeViolationsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "block_time_violations",
+		Help:         "The number of block timestamps which failed the sanity checks of the node, by reason: signature, regression or ahead.",
+		LabelNames:   []string{"channel", "reason"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{reason}",
+	}
+	goroutinesOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "goroutines",
+		Help:         "The number of running goroutines owned by the chain.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	pendingConfigUpdatesOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "pending_config_updates",
+		Help:         "The number of config updates submitted to the node and not yet ordered, including the config block or ConfChange in flight.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	reorderHoldsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "reorder_holds",
+		Help:         "The number of redirected transactions held by the leader for the transaction forwarded before them, by outcome: arrived or expired.",
+		LabelNames:   []string{"channel", "outcome"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{outcome}",
+	}
+	consensusMsgsDroppedOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "consensus_msgs_dropped",
+		Help:         "The number of raft messages received from a consenter and dropped before being stepped, by reason: size, sender, type, term or index.",
+		LabelNames:   []string{"channel", "sender", "reason"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{sender}.%{reason}",
+	}
+	evictionSuspectedOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "eviction_suspected",
+		Help:         "1 if the node suspects its own eviction from the channel, as it has not known a leader for longer than the eviction suspicion threshold, else 0.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	evictionSuspicionDurationOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "eviction_suspicion_duration",
+		Help:         "The time the node has not known a leader for while suspecting its own eviction (in seconds), 0 if it does not suspect it.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	evictionsConfirmedOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "evictions_confirmed",
+		Help:         "The number of times the node confirmed its own eviction from the channel.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	evictionBlocksPulledOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "eviction_blocks_pulled",
+		Help:         "The number of blocks pulled up to the block evicting the node, after it confirmed its own eviction.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	pendingBatchMessagesOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "pending_batch_messages",
+		Help:         "The number of transactions ordered by the leader and waiting to be cut into a block.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	pendingBatchBytesOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "pending_batch_bytes",
+		Help:         "The size of the transactions ordered by the leader and waiting to be cut into a block (in bytes).",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	pendingBatchStartTimeOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "pending_batch_start_time",
+		Help:         "The time, in seconds since the epoch, the oldest transaction waiting to be cut into a block was ordered by the leader, 0 if none is waiting.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	blocksInFlightOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "blocks_in_flight",
+		Help:         "The number of blocks created by the leader and not yet committed.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	bytesInFlightOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "bytes_in_flight",
+		Help:         "The size of the blocks created by the leader and not yet committed (in bytes).",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	electionStormsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "election_storms",
+		Help:         "The number of election storms observed by the node, which dampened its elections.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	electionTimeoutFactorOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "election_timeout_factor",
+		Help:         "The factor the election timeout of the node is multiplied by to dampen an election storm, 1 if elections are not dampened.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	consenterOrgOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "consenter_org",
+		Help:         "Set to 1 for every consenter, labeled by the MSP ID of the organization it is bound to, which is empty if it is not bound to any.",
+		LabelNames:   []string{"channel", "peer", "org"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}.%{org}",
+	}
+	snapshotReclaimedBytesOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "snapshot_reclaimed_bytes",
+		Help:         "The number of bytes of the snapshot and WAL files deleted beyond the snapshot retention.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	validationCacheHitsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "validation_cache_hits",
+		Help:         "The number of envelopes revalidated against an advanced config sequence whose validation result was cached.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	validationCacheMissesOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "validation_cache_misses",
+		Help:         "The number of envelopes revalidated against an advanced config sequence whose validation result was not cached.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	walFsyncsOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "wal_fsyncs",
+		Help:         "The number of fsyncs of the WAL issued to persist entries, hard states and snapshots.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	walAppendedBytesOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "wal_appended_bytes",
+		Help:         "The number of bytes of the entries and hard states appended to the WAL.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	walSegmentsCreatedOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "wal_segments_created",
+		Help:         "The number of WAL segment files created as the WAL rolls over.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	snapshotFilesWrittenOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "snapshot_files_written",
+		Help:         "The number of snapshot files written.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	snapshotsDeferredOpts = metrics.CounterOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "snapshots_deferred",
+		Help:         "The number of snapshots deferred while the persist latency of raft data was elevated.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	configApplyDurationOpts = metrics.HistogramOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "config_apply_duration",
+		Help:         "The time taken to apply a config block by phase: detect_conf_change, write_block, configure_comm, propose_conf_change or total (in seconds).",
+		LabelNames:   []string{"channel", "phase"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{phase}",
+	}
+	estimatedTimeToOrderOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "estimated_time_to_order",
+		Help:         "The estimated time for the leader to order a transaction broadcast to it, derived from the pending batch, the envelopes in flight, the recent commit rate and the batch timeout (in seconds). It is zero on followers.",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
+	appendAckLatencyOpts = metrics.HistogramOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "append_ack_latency",
+		Help:         "The time taken by a peer to acknowledge the entries appended to it by the leader (in seconds).",
+		LabelNames:   []string{"channel", "peer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}",
+	}
+	nodeDegradedOpts = metrics.GaugeOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "node_degraded",
+		Help:         "Whether a node is up but too slow, by the latency of persisting its raft data for the node itself, or of acknowledging appends for the peers of the leader: 1 if degraded else 0.",
+		LabelNames:   []string{"channel", "peer"},
+		StatsdFormat: "%{#fqname}.%{channel}.%{peer}",
+	}
+	proposeWaitDurationOpts = metrics.HistogramOpts{
+		Namespace:    "consensus",
+		Subsystem:    "etcdraft",
+		Name:         "propose_wait_duration",
+		Help:         "The time a block waits between its creation and being proposed to raft (in seconds).",
+		LabelNames:   []string{"channel"},
+		StatsdFormat: "%{#fqname}.%{channel}",
+	}
 )
 
 type Metrics struct {
@@ -93,6 +493,69 @@ type Metrics struct {
 	DataPersistDuration     metrics.Histogram
 	NormalProposalsReceived metrics.Counter
 	ConfigProposalsReceived metrics.Counter
+	Term                    metrics.Gauge
+	CommitIndex             metrics.Gauge
+	AppliedIndex            metrics.Gauge
+	PeerProgressState       metrics.Gauge
+	ProposeQueueDepth       metrics.Gauge
+	ProposeWaitDuration     metrics.Histogram
+	TrustChangedTime        metrics.Gauge
+	IsPaused                metrics.Gauge
+	BlockMismatches         metrics.Counter
+	CommitBacklog           metrics.Gauge
+	PeerUnreachable         metrics.Counter
+	PeerReachable           metrics.Counter
+	QuotaThrottled          metrics.Counter
+	MembershipDrift         metrics.Gauge
+	Wedged                  metrics.Gauge
+	ConfChangeStalled       metrics.Gauge
+	ConfigApplyDuration     metrics.Histogram
+	ConsenterOrg            metrics.Gauge
+	SnapshotReclaimedBytes  metrics.Counter
+	ValidationCacheHits     metrics.Counter
+	ValidationCacheMisses   metrics.Counter
+
+	WALFsyncs            metrics.Counter
+	WALAppendedBytes     metrics.Counter
+	WALSegmentsCreated   metrics.Counter
+	SnapshotFilesWritten metrics.Counter
+	SnapshotsDeferred    metrics.Counter
+
+	EvictionSuspected         metrics.Gauge
+	EvictionSuspicionDuration metrics.Gauge
+	EvictionsConfirmed        metrics.Counter
+	EvictionBlocksPulled      metrics.Counter
+
+	PendingBatchMessages  metrics.Gauge
+	PendingBatchBytes     metrics.Gauge
+	PendingBatchStartTime metrics.Gauge
+	BlocksInFlight        metrics.Gauge
+	BytesInFlight         metrics.Gauge
+	EstimatedTimeToOrder  metrics.Gauge
+
+	ElectionStorms        metrics.Counter
+	ElectionTimeoutFactor metrics.Gauge
+
+	AppendAckLatency metrics.Histogram
+	NodeDegraded     metrics.Gauge
+
+	ConfChangeReproposals      metrics.Counter
+	ConfChangeRetriesExhausted metrics.Gauge
+
+	EnvelopesRejected metrics.Counter
+
+	DRReplicationLag metrics.Gauge
+	DRSendFailures   metrics.Counter
+
+	BlockTimeViolations metrics.Counter
+
+	Goroutines metrics.Gauge
+
+	PendingConfigUpdates metrics.Gauge
+
+	ReorderHolds metrics.Counter
+
+	ConsensusMsgsDropped metrics.Counter
 }
 
 func NewMetrics(p metrics.Provider) *Metrics {
@@ -106,5 +569,68 @@ func NewMetrics(p metrics.Provider) *Metrics {
 		DataPersistDuration:     p.NewHistogram(dataPersistDurationOpts),
 		NormalProposalsReceived: p.NewCounter(normalProposalsReceivedOpts),
 		ConfigProposalsReceived: p.NewCounter(configProposalsReceivedOpts),
+		Term:                    p.NewGauge(termOpts),
+		CommitIndex:             p.NewGauge(commitIndexOpts),
+		AppliedIndex:            p.NewGauge(appliedIndexOpts),
+		PeerProgressState:       p.NewGauge(peerProgressStateOpts),
+		ProposeQueueDepth:       p.NewGauge(proposeQueueDepthOpts),
+		ProposeWaitDuration:     p.NewHistogram(proposeWaitDurationOpts),
+		TrustChangedTime:        p.NewGauge(trustChangedTimeOpts),
+		IsPaused:                p.NewGauge(isPausedOpts),
+		BlockMismatches:         p.NewCounter(blockMismatchesOpts),
+		CommitBacklog:           p.NewGauge(commitBacklogOpts),
+		PeerUnreachable:         p.NewCounter(peerUnreachableOpts),
+		PeerReachable:           p.NewCounter(peerReachableOpts),
+		QuotaThrottled:          p.NewCounter(quotaThrottledOpts),
+		MembershipDrift:         p.NewGauge(membershipDriftOpts),
+		Wedged:                  p.NewGauge(wedgedOpts),
+		ConfChangeStalled:       p.NewGauge(confChangeStalledOpts),
+		ConfigApplyDuration:     p.NewHistogram(configApplyDurationOpts),
+		ConsenterOrg:            p.NewGauge(consenterOrgOpts),
+		SnapshotReclaimedBytes:  p.NewCounter(snapshotReclaimedBytesOpts),
+		ValidationCacheHits:     p.NewCounter(validationCacheHitsOpts),
+		ValidationCacheMisses:   p.NewCounter(validationCacheMissesOpts),
+
+		WALFsyncs:            p.NewCounter(walFsyncsOpts),
+		WALAppendedBytes:     p.NewCounter(walAppendedBytesOpts),
+		WALSegmentsCreated:   p.NewCounter(walSegmentsCreatedOpts),
+		SnapshotFilesWritten: p.NewCounter(snapshotFilesWrittenOpts),
+		SnapshotsDeferred:    p.NewCounter(snapshotsDeferredOpts),
+
+		EvictionSuspected:         p.NewGauge(evictionSuspectedOpts),
+		EvictionSuspicionDuration: p.NewGauge(evictionSuspicionDurationOpts),
+		EvictionsConfirmed:        p.NewCounter(evictionsConfirmedOpts),
+		EvictionBlocksPulled:      p.NewCounter(evictionBlocksPulledOpts),
+
+		PendingBatchMessages:  p.NewGauge(pendingBatchMessagesOpts),
+		PendingBatchBytes:     p.NewGauge(pendingBatchBytesOpts),
+		PendingBatchStartTime: p.NewGauge(pendingBatchStartTimeOpts),
+		BlocksInFlight:        p.NewGauge(blocksInFlightOpts),
+		BytesInFlight:         p.NewGauge(bytesInFlightOpts),
+		EstimatedTimeToOrder:  p.NewGauge(estimatedTimeToOrderOpts),
+
+		ElectionStorms:        p.NewCounter(electionStormsOpts),
+		ElectionTimeoutFactor: p.NewGauge(electionTimeoutFactorOpts),
+
+		AppendAckLatency: p.NewHistogram(appendAckLatencyOpts),
+		NodeDegraded:     p.NewGauge(nodeDegradedOpts),
+
+		ConfChangeReproposals:      p.NewCounter(confChangeReproposalsOpts),
+		ConfChangeRetriesExhausted: p.NewGauge(confChangeRetriesExhaustedOpts),
+
+		EnvelopesRejected: p.NewCounter(envelopesRejectedOpts),
+
+		DRReplicationLag: p.NewGauge(drReplicationLagOpts),
+		DRSendFailures:   p.NewCounter(drSendFailuresOpts),
+
+		BlockTimeViolations: p.NewCounter(blockTimeViolationsOpts),
+
+		Goroutines: p.NewGauge(goroutinesOpts),
+
+		PendingConfigUpdates: p.NewGauge(pendingConfigUpdatesOpts),
+
+		ReorderHolds: p.NewCounter(reorderHoldsOpts),
+
+		ConsensusMsgsDropped: p.NewCounter(consensusMsgsDroppedOpts),
 	}
 }
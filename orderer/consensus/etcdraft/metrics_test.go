@@ -37,9 +37,9 @@ var _ = Describe("Metrics", func() {
 			metrics := etcdraft.NewMetrics(fakeProvider)
 
 			Expect(metrics).NotTo(BeNil())
-			Expect(fakeProvider.NewGaugeCallCount()).To(Equal(4))
-			Expect(fakeProvider.NewCounterCallCount()).To(Equal(4))
-			Expect(fakeProvider.NewHistogramCallCount()).To(Equal(1))
+			Expect(fakeProvider.NewGaugeCallCount()).To(Equal(30))
+			Expect(fakeProvider.NewCounterCallCount()).To(Equal(25))
+			Expect(fakeProvider.NewHistogramCallCount()).To(Equal(4))
 
 			Expect(metrics.ClusterSize).To(Equal(fakeGauge))
 			Expect(metrics.IsLeader).To(Equal(fakeGauge))
@@ -50,6 +50,56 @@ var _ = Describe("Metrics", func() {
 			Expect(metrics.DataPersistDuration).To(Equal(fakeHistogram))
 			Expect(metrics.NormalProposalsReceived).To(Equal(fakeCounter))
 			Expect(metrics.ConfigProposalsReceived).To(Equal(fakeCounter))
+			Expect(metrics.Term).To(Equal(fakeGauge))
+			Expect(metrics.CommitIndex).To(Equal(fakeGauge))
+			Expect(metrics.AppliedIndex).To(Equal(fakeGauge))
+			Expect(metrics.PeerProgressState).To(Equal(fakeGauge))
+			Expect(metrics.ProposeQueueDepth).To(Equal(fakeGauge))
+			Expect(metrics.ProposeWaitDuration).To(Equal(fakeHistogram))
+			Expect(metrics.TrustChangedTime).To(Equal(fakeGauge))
+			Expect(metrics.IsPaused).To(Equal(fakeGauge))
+			Expect(metrics.BlockMismatches).To(Equal(fakeCounter))
+			Expect(metrics.CommitBacklog).To(Equal(fakeGauge))
+			Expect(metrics.PeerUnreachable).To(Equal(fakeCounter))
+			Expect(metrics.PeerReachable).To(Equal(fakeCounter))
+			Expect(metrics.QuotaThrottled).To(Equal(fakeCounter))
+			Expect(metrics.MembershipDrift).To(Equal(fakeGauge))
+			Expect(metrics.Wedged).To(Equal(fakeGauge))
+			Expect(metrics.ConfChangeStalled).To(Equal(fakeGauge))
+			Expect(metrics.ConfigApplyDuration).To(Equal(fakeHistogram))
+			Expect(metrics.ConsenterOrg).To(Equal(fakeGauge))
+			Expect(metrics.SnapshotReclaimedBytes).To(Equal(fakeCounter))
+			Expect(metrics.ValidationCacheHits).To(Equal(fakeCounter))
+			Expect(metrics.ValidationCacheMisses).To(Equal(fakeCounter))
+			Expect(metrics.WALFsyncs).To(Equal(fakeCounter))
+			Expect(metrics.WALAppendedBytes).To(Equal(fakeCounter))
+			Expect(metrics.WALSegmentsCreated).To(Equal(fakeCounter))
+			Expect(metrics.SnapshotFilesWritten).To(Equal(fakeCounter))
+			Expect(metrics.SnapshotsDeferred).To(Equal(fakeCounter))
+			Expect(metrics.EvictionSuspected).To(Equal(fakeGauge))
+			Expect(metrics.EvictionSuspicionDuration).To(Equal(fakeGauge))
+			Expect(metrics.EvictionsConfirmed).To(Equal(fakeCounter))
+			Expect(metrics.EvictionBlocksPulled).To(Equal(fakeCounter))
+			Expect(metrics.PendingBatchMessages).To(Equal(fakeGauge))
+			Expect(metrics.PendingBatchBytes).To(Equal(fakeGauge))
+			Expect(metrics.PendingBatchStartTime).To(Equal(fakeGauge))
+			Expect(metrics.BlocksInFlight).To(Equal(fakeGauge))
+			Expect(metrics.BytesInFlight).To(Equal(fakeGauge))
+			Expect(metrics.EstimatedTimeToOrder).To(Equal(fakeGauge))
+			Expect(metrics.ElectionStorms).To(Equal(fakeCounter))
+			Expect(metrics.ElectionTimeoutFactor).To(Equal(fakeGauge))
+			Expect(metrics.AppendAckLatency).To(Equal(fakeHistogram))
+			Expect(metrics.NodeDegraded).To(Equal(fakeGauge))
+			Expect(metrics.ConfChangeReproposals).To(Equal(fakeCounter))
+			Expect(metrics.ConfChangeRetriesExhausted).To(Equal(fakeGauge))
+			Expect(metrics.EnvelopesRejected).To(Equal(fakeCounter))
+			Expect(metrics.DRReplicationLag).To(Equal(fakeGauge))
+			Expect(metrics.DRSendFailures).To(Equal(fakeCounter))
+			Expect(metrics.BlockTimeViolations).To(Equal(fakeCounter))
+			Expect(metrics.Goroutines).To(Equal(fakeGauge))
+			Expect(metrics.PendingConfigUpdates).To(Equal(fakeGauge))
+			Expect(metrics.ReorderHolds).To(Equal(fakeCounter))
+			Expect(metrics.ConsensusMsgsDropped).To(Equal(fakeCounter))
 		})
 	})
 })
@@ -65,6 +115,69 @@ func newFakeMetrics(fakeFields *fakeMetricsFields) *etcdraft.Metrics {
 		DataPersistDuration:     fakeFields.fakeDataPersistDuration,
 		NormalProposalsReceived: fakeFields.fakeNormalProposalsReceived,
 		ConfigProposalsReceived: fakeFields.fakeConfigProposalsReceived,
+		Term:                    fakeFields.fakeTerm,
+		CommitIndex:             fakeFields.fakeCommitIndex,
+		AppliedIndex:            fakeFields.fakeAppliedIndex,
+		PeerProgressState:       fakeFields.fakePeerProgressState,
+		ProposeQueueDepth:       fakeFields.fakeProposeQueueDepth,
+		ProposeWaitDuration:     fakeFields.fakeProposeWaitDuration,
+		TrustChangedTime:        fakeFields.fakeTrustChangedTime,
+		IsPaused:                fakeFields.fakeIsPaused,
+		BlockMismatches:         fakeFields.fakeBlockMismatches,
+		CommitBacklog:           fakeFields.fakeCommitBacklog,
+		PeerUnreachable:         fakeFields.fakePeerUnreachable,
+		PeerReachable:           fakeFields.fakePeerReachable,
+		QuotaThrottled:          fakeFields.fakeQuotaThrottled,
+		MembershipDrift:         fakeFields.fakeMembershipDrift,
+		Wedged:                  fakeFields.fakeWedged,
+		ConfChangeStalled:       fakeFields.fakeConfChangeStalled,
+		ConfigApplyDuration:     fakeFields.fakeConfigApplyDuration,
+		ConsenterOrg:            fakeFields.fakeConsenterOrg,
+		SnapshotReclaimedBytes:  fakeFields.fakeSnapshotReclaimedBytes,
+		ValidationCacheHits:     fakeFields.fakeValidationCacheHits,
+		ValidationCacheMisses:   fakeFields.fakeValidationCacheMisses,
+
+		WALFsyncs:            fakeFields.fakeWALFsyncs,
+		WALAppendedBytes:     fakeFields.fakeWALAppendedBytes,
+		WALSegmentsCreated:   fakeFields.fakeWALSegmentsCreated,
+		SnapshotFilesWritten: fakeFields.fakeSnapshotFilesWritten,
+		SnapshotsDeferred:    fakeFields.fakeSnapshotsDeferred,
+
+		EvictionSuspected:         fakeFields.fakeEvictionSuspected,
+		EvictionSuspicionDuration: fakeFields.fakeEvictionSuspicionDuration,
+		EvictionsConfirmed:        fakeFields.fakeEvictionsConfirmed,
+		EvictionBlocksPulled:      fakeFields.fakeEvictionBlocksPulled,
+
+		PendingBatchMessages:  fakeFields.fakePendingBatchMessages,
+		PendingBatchBytes:     fakeFields.fakePendingBatchBytes,
+		PendingBatchStartTime: fakeFields.fakePendingBatchStartTime,
+		BlocksInFlight:        fakeFields.fakeBlocksInFlight,
+		BytesInFlight:         fakeFields.fakeBytesInFlight,
+		EstimatedTimeToOrder:  fakeFields.fakeEstimatedTimeToOrder,
+
+		ElectionStorms:        fakeFields.fakeElectionStorms,
+		ElectionTimeoutFactor: fakeFields.fakeElectionTimeoutFactor,
+
+		AppendAckLatency: fakeFields.fakeAppendAckLatency,
+		NodeDegraded:     fakeFields.fakeNodeDegraded,
+
+		ConfChangeReproposals:      fakeFields.fakeConfChangeReproposals,
+		ConfChangeRetriesExhausted: fakeFields.fakeConfChangeRetriesExhausted,
+
+		EnvelopesRejected: fakeFields.fakeEnvelopesRejected,
+
+		DRReplicationLag: fakeFields.fakeDRReplicationLag,
+		DRSendFailures:   fakeFields.fakeDRSendFailures,
+
+		BlockTimeViolations: fakeFields.fakeBlockTimeViolations,
+
+		Goroutines: fakeFields.fakeGoroutines,
+
+		PendingConfigUpdates: fakeFields.fakePendingConfigUpdates,
+
+		ReorderHolds: fakeFields.fakeReorderHolds,
+
+		ConsensusMsgsDropped: fakeFields.fakeConsensusMsgsDropped,
 	}
 }
 
@@ -78,6 +191,69 @@ type fakeMetricsFields struct {
 	fakeDataPersistDuration     *metricsfakes.Histogram
 	fakeNormalProposalsReceived *metricsfakes.Counter
 	fakeConfigProposalsReceived *metricsfakes.Counter
+	fakeTerm                    *metricsfakes.Gauge
+	fakeCommitIndex             *metricsfakes.Gauge
+	fakeAppliedIndex            *metricsfakes.Gauge
+	fakePeerProgressState       *metricsfakes.Gauge
+	fakeProposeQueueDepth       *metricsfakes.Gauge
+	fakeProposeWaitDuration     *metricsfakes.Histogram
+	fakeTrustChangedTime        *metricsfakes.Gauge
+	fakeIsPaused                *metricsfakes.Gauge
+	fakeBlockMismatches         *metricsfakes.Counter
+	fakeCommitBacklog           *metricsfakes.Gauge
+	fakePeerUnreachable         *metricsfakes.Counter
+	fakePeerReachable           *metricsfakes.Counter
+	fakeQuotaThrottled          *metricsfakes.Counter
+	fakeMembershipDrift         *metricsfakes.Gauge
+	fakeWedged                  *metricsfakes.Gauge
+	fakeConfChangeStalled       *metricsfakes.Gauge
+	fakeConfigApplyDuration     *metricsfakes.Histogram
+	fakeConsenterOrg            *metricsfakes.Gauge
+	fakeSnapshotReclaimedBytes  *metricsfakes.Counter
+	fakeValidationCacheHits     *metricsfakes.Counter
+	fakeValidationCacheMisses   *metricsfakes.Counter
+
+	fakeWALFsyncs            *metricsfakes.Counter
+	fakeWALAppendedBytes     *metricsfakes.Counter
+	fakeWALSegmentsCreated   *metricsfakes.Counter
+	fakeSnapshotFilesWritten *metricsfakes.Counter
+	fakeSnapshotsDeferred    *metricsfakes.Counter
+
+	fakeEvictionSuspected         *metricsfakes.Gauge
+	fakeEvictionSuspicionDuration *metricsfakes.Gauge
+	fakeEvictionsConfirmed        *metricsfakes.Counter
+	fakeEvictionBlocksPulled      *metricsfakes.Counter
+
+	fakePendingBatchMessages  *metricsfakes.Gauge
+	fakePendingBatchBytes     *metricsfakes.Gauge
+	fakePendingBatchStartTime *metricsfakes.Gauge
+	fakeBlocksInFlight        *metricsfakes.Gauge
+	fakeBytesInFlight         *metricsfakes.Gauge
+	fakeEstimatedTimeToOrder  *metricsfakes.Gauge
+
+	fakeElectionStorms        *metricsfakes.Counter
+	fakeElectionTimeoutFactor *metricsfakes.Gauge
+
+	fakeAppendAckLatency *metricsfakes.Histogram
+	fakeNodeDegraded     *metricsfakes.Gauge
+
+	fakeConfChangeReproposals      *metricsfakes.Counter
+	fakeConfChangeRetriesExhausted *metricsfakes.Gauge
+
+	fakeEnvelopesRejected *metricsfakes.Counter
+
+	fakeDRReplicationLag *metricsfakes.Gauge
+	fakeDRSendFailures   *metricsfakes.Counter
+
+	fakeBlockTimeViolations *metricsfakes.Counter
+
+	fakeGoroutines *metricsfakes.Gauge
+
+	fakePendingConfigUpdates *metricsfakes.Gauge
+
+	fakeReorderHolds *metricsfakes.Counter
+
+	fakeConsensusMsgsDropped *metricsfakes.Counter
 }
 
 func newFakeMetricsFields() *fakeMetricsFields {
@@ -91,6 +267,69 @@ func newFakeMetricsFields() *fakeMetricsFields {
 		fakeDataPersistDuration:     newFakeHistogram(),
 		fakeNormalProposalsReceived: newFakeCounter(),
 		fakeConfigProposalsReceived: newFakeCounter(),
+		fakeTerm:                    newFakeGauge(),
+		fakeCommitIndex:             newFakeGauge(),
+		fakeAppliedIndex:            newFakeGauge(),
+		fakePeerProgressState:       newFakeGauge(),
+		fakeProposeQueueDepth:       newFakeGauge(),
+		fakeProposeWaitDuration:     newFakeHistogram(),
+		fakeTrustChangedTime:        newFakeGauge(),
+		fakeIsPaused:                newFakeGauge(),
+		fakeBlockMismatches:         newFakeCounter(),
+		fakeCommitBacklog:           newFakeGauge(),
+		fakePeerUnreachable:         newFakeCounter(),
+		fakePeerReachable:           newFakeCounter(),
+		fakeQuotaThrottled:          newFakeCounter(),
+		fakeMembershipDrift:         newFakeGauge(),
+		fakeWedged:                  newFakeGauge(),
+		fakeConfChangeStalled:       newFakeGauge(),
+		fakeConfigApplyDuration:     newFakeHistogram(),
+		fakeConsenterOrg:            newFakeGauge(),
+		fakeSnapshotReclaimedBytes:  newFakeCounter(),
+		fakeValidationCacheHits:     newFakeCounter(),
+		fakeValidationCacheMisses:   newFakeCounter(),
+
+		fakeWALFsyncs:            newFakeCounter(),
+		fakeWALAppendedBytes:     newFakeCounter(),
+		fakeWALSegmentsCreated:   newFakeCounter(),
+		fakeSnapshotFilesWritten: newFakeCounter(),
+		fakeSnapshotsDeferred:    newFakeCounter(),
+
+		fakeEvictionSuspected:         newFakeGauge(),
+		fakeEvictionSuspicionDuration: newFakeGauge(),
+		fakeEvictionsConfirmed:        newFakeCounter(),
+		fakeEvictionBlocksPulled:      newFakeCounter(),
+
+		fakePendingBatchMessages:  newFakeGauge(),
+		fakePendingBatchBytes:     newFakeGauge(),
+		fakePendingBatchStartTime: newFakeGauge(),
+		fakeBlocksInFlight:        newFakeGauge(),
+		fakeBytesInFlight:         newFakeGauge(),
+		fakeEstimatedTimeToOrder:  newFakeGauge(),
+
+		fakeElectionStorms:        newFakeCounter(),
+		fakeElectionTimeoutFactor: newFakeGauge(),
+
+		fakeAppendAckLatency: newFakeHistogram(),
+		fakeNodeDegraded:     newFakeGauge(),
+
+		fakeConfChangeReproposals:      newFakeCounter(),
+		fakeConfChangeRetriesExhausted: newFakeGauge(),
+
+		fakeEnvelopesRejected: newFakeCounter(),
+
+		fakeDRReplicationLag: newFakeGauge(),
+		fakeDRSendFailures:   newFakeCounter(),
+
+		fakeBlockTimeViolations: newFakeCounter(),
+
+		fakeGoroutines: newFakeGauge(),
+
+		fakePendingConfigUpdates: newFakeGauge(),
+
+		fakeReorderHolds: newFakeCounter(),
+
+		fakeConsensusMsgsDropped: newFakeCounter(),
 	}
 }
 
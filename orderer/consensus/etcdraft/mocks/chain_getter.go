@@ -10,6 +10,22 @@ type ChainGetter struct {
 	mock.Mock
 }
 
+// ChainIDs provides a mock function with given fields:
+func (_m *ChainGetter) ChainIDs() []string {
+	ret := _m.Called()
+
+	var r0 []string
+	if rf, ok := ret.Get(0).(func() []string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	return r0
+}
+
 // GetChain provides a mock function with given fields: chainID
 func (_m *ChainGetter) GetChain(chainID string) *multichannel.ChainSupport {
 	ret := _m.Called(chainID)
@@ -15,3 +15,8 @@ type InactiveChainRegistry struct {
 func (_m *InactiveChainRegistry) TrackChain(chainName string, genesisBlock *common.Block, createChain etcdraft.CreateChainCallback) {
 	_m.Called(chainName, genesisBlock, createChain)
 }
+
+// UntrackChain provides a mock function with given fields: chainName
+func (_m *InactiveChainRegistry) UntrackChain(chainName string) {
+	_m.Called(chainName)
+}
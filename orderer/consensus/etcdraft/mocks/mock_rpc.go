@@ -33,6 +33,18 @@ type FakeRPC struct {
 	sendSubmitReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SendSubmitBatchStub        func(dest uint64, batch *orderer.SubmitBatch) error
+	sendSubmitBatchMutex       sync.RWMutex
+	sendSubmitBatchArgsForCall []struct {
+		dest  uint64
+		batch *orderer.SubmitBatch
+	}
+	sendSubmitBatchReturns struct {
+		result1 error
+	}
+	sendSubmitBatchReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -135,6 +147,55 @@ func (fake *FakeRPC) SendSubmitReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeRPC) SendSubmitBatch(dest uint64, batch *orderer.SubmitBatch) error {
+	fake.sendSubmitBatchMutex.Lock()
+	ret, specificReturn := fake.sendSubmitBatchReturnsOnCall[len(fake.sendSubmitBatchArgsForCall)]
+	fake.sendSubmitBatchArgsForCall = append(fake.sendSubmitBatchArgsForCall, struct {
+		dest  uint64
+		batch *orderer.SubmitBatch
+	}{dest, batch})
+	fake.recordInvocation("SendSubmitBatch", []interface{}{dest, batch})
+	fake.sendSubmitBatchMutex.Unlock()
+	if fake.SendSubmitBatchStub != nil {
+		return fake.SendSubmitBatchStub(dest, batch)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.sendSubmitBatchReturns.result1
+}
+
+func (fake *FakeRPC) SendSubmitBatchCallCount() int {
+	fake.sendSubmitBatchMutex.RLock()
+	defer fake.sendSubmitBatchMutex.RUnlock()
+	return len(fake.sendSubmitBatchArgsForCall)
+}
+
+func (fake *FakeRPC) SendSubmitBatchArgsForCall(i int) (uint64, *orderer.SubmitBatch) {
+	fake.sendSubmitBatchMutex.RLock()
+	defer fake.sendSubmitBatchMutex.RUnlock()
+	return fake.sendSubmitBatchArgsForCall[i].dest, fake.sendSubmitBatchArgsForCall[i].batch
+}
+
+func (fake *FakeRPC) SendSubmitBatchReturns(result1 error) {
+	fake.SendSubmitBatchStub = nil
+	fake.sendSubmitBatchReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRPC) SendSubmitBatchReturnsOnCall(i int, result1 error) {
+	fake.SendSubmitBatchStub = nil
+	if fake.sendSubmitBatchReturnsOnCall == nil {
+		fake.sendSubmitBatchReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.sendSubmitBatchReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeRPC) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -142,6 +203,8 @@ func (fake *FakeRPC) Invocations() map[string][][]interface{} {
 	defer fake.sendConsensusMutex.RUnlock()
 	fake.sendSubmitMutex.RLock()
 	defer fake.sendSubmitMutex.RUnlock()
+	fake.sendSubmitBatchMutex.RLock()
+	defer fake.sendSubmitBatchMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
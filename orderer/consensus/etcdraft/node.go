@@ -9,6 +9,8 @@ package etcdraft
 import (
 	"context"
 	"crypto/sha256"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,15 +21,20 @@ import (
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
 	"go.etcd.io/etcd/raft"
 	"go.etcd.io/etcd/raft/raftpb"
+	"google.golang.org/grpc/connectivity"
 )
 
 type node struct {
-	chainID string
-	logger  *flogging.FabricLogger
-	metrics *Metrics
+	chainID     string
+	attestation []byte // ConsensusRequest metadata attesting the raft ID of the node
+	logger      *flogging.FabricLogger
+	metrics     *Metrics
 
+	// unreachable are the peers the last message sent to failed. It is updated
+	// by the goroutine sending messages and read by others, under unreachableLock.
 	unreachableLock sync.RWMutex
 	unreachable     map[uint64]struct{}
 
@@ -43,6 +50,18 @@ type node struct {
 
 	metadata *etcdraft.BlockMetadata
 
+	faults FaultInjector
+
+	tickQuota    *quota // bounds the raft ticks processed
+	persistQuota *quota // bounds the bytes written to the WAL
+
+	dampener  *electionDampener  // dampens election storms, if set
+	snapshots *snapshotScheduler // defers snapshots while persisting is slow, if set
+
+	// committedIndex is the index of the last entry known to be
+	// committed, which is accessed atomically
+	committedIndex uint64
+
 	raft.Node
 }
 
@@ -77,10 +96,12 @@ func (n *node) start(fresh, join, migration bool) {
 		n.Node = raft.RestartNode(n.config)
 	}
 
-	go n.run(campaign)
+	n.chain.goroutines.spawn("raft_node", func() { n.run(campaign) })
 }
 
 func (n *node) run(campaign bool) {
+	defer n.chain.dumpFlightRecordOnPanic()
+
 	raftTicker := n.clock.NewTicker(n.tickInterval)
 
 	if s := n.storage.Snapshot(); !raft.IsEmptySnap(s) {
@@ -90,7 +111,7 @@ func (n *node) run(campaign bool) {
 	elected := make(chan struct{})
 	if campaign {
 		n.logger.Infof("This node is picked to start campaign")
-		go func() {
+		n.chain.goroutines.spawn("campaign", func() {
 			campaignTicker := n.clock.NewTicker(n.tickInterval)
 			defer campaignTicker.Stop()
 
@@ -104,26 +125,36 @@ func (n *node) run(campaign bool) {
 					return
 				}
 			}
-		}()
+		})
 	}
 
 	for {
 		select {
 		case <-raftTicker.C():
-			n.Tick()
+			if n.dampener.allowTick() && n.tickQuota.allow(1) {
+				n.Tick()
+			}
 
 		case rd := <-n.Ready():
+			n.persistQuota.wait(entriesSize(rd.Entries), n.chain.haltingC)
+
 			startStoring := n.clock.Now()
-			if err := n.storage.Store(rd.Entries, rd.HardState, rd.Snapshot); err != nil {
+			if err := n.persist(rd); err != nil {
 				n.logger.Panicf("Failed to persist etcd/raft data: %s", err)
 			}
-			duration := n.clock.Since(startStoring).Seconds()
-			n.metrics.DataPersistDuration.Observe(float64(duration))
+			duration := n.clock.Since(startStoring)
+			n.metrics.DataPersistDuration.Observe(duration.Seconds())
+			n.snapshots.observe(duration)
+			n.chain.grayFailures.persisted(duration)
 
 			if !raft.IsEmptySnap(rd.Snapshot) {
 				n.chain.snapC <- &rd.Snapshot
 			}
 
+			if len(rd.CommittedEntries) != 0 {
+				atomic.StoreUint64(&n.committedIndex, rd.CommittedEntries[len(rd.CommittedEntries)-1].Index)
+			}
+
 			// skip empty apply
 			if len(rd.CommittedEntries) != 0 || rd.SoftState != nil {
 				n.chain.applyC <- apply{rd.CommittedEntries, rd.SoftState}
@@ -155,27 +186,40 @@ func (n *node) run(campaign bool) {
 	}
 }
 
-func (n *node) send(msgs []raftpb.Message) {
-	n.unreachableLock.RLock()
-	defer n.unreachableLock.RUnlock()
+func (n *node) persist(rd raft.Ready) error {
+	if err := n.faults.Persist(rd.Entries, &rd.HardState, &rd.Snapshot); err != nil {
+		return err
+	}
+	return n.storage.Store(rd.Entries, rd.HardState, rd.Snapshot)
+}
 
-	for _, msg := range msgs {
+func (n *node) send(msgs []raftpb.Message) {
+	for _, msg := range coalesceHeartbeats(msgs) {
 		if msg.To == 0 {
 			continue
 		}
 
 		status := raft.SnapshotFinish
 
-		msgBytes := utils.MarshalOrPanic(&msg)
-		err := n.rpc.SendConsensus(msg.To, &orderer.ConsensusRequest{Channel: n.chainID, Payload: msgBytes})
+		err := n.faults.Send(&msg)
+		if err == nil {
+			msgBytes := utils.MarshalOrPanic(&msg)
+			n.chain.recorder.record(MessageSent, &msg, len(msgBytes), n.clock.Now())
+			err = n.rpc.SendConsensus(msg.To, &orderer.ConsensusRequest{Channel: n.chainID, Payload: msgBytes, Metadata: n.attestation})
+		}
 		if err != nil {
 			n.ReportUnreachable(msg.To)
 			n.logSendFailure(msg.To, err)
 
 			status = raft.SnapshotFailure
-		} else if _, ok := n.unreachable[msg.To]; ok {
+		} else if n.markReachable(msg.To) {
 			n.logger.Infof("Successfully sent StepRequest to %d after failed attempt(s)", msg.To)
-			delete(n.unreachable, msg.To)
+
+			n.metrics.PeerReachable.With("peer", strconv.FormatUint(msg.To, 10)).Add(1)
+			n.chain.notify(Event{Type: EventPeerReachable, Peer: msg.To})
+		}
+		if err == nil {
+			n.chain.grayFailures.sent(msg)
 		}
 
 		if msg.Type == raftpb.MsgSnap {
@@ -184,20 +228,91 @@ func (n *node) send(msgs []raftpb.Message) {
 	}
 }
 
+// isUnreachable returns whether the last message sent to the given peer failed.
+func (n *node) isUnreachable(id uint64) bool {
+	n.unreachableLock.RLock()
+	defer n.unreachableLock.RUnlock()
+	_, ok := n.unreachable[id]
+	return ok
+}
+
+// markReachable removes the peer from the unreachable ones,
+// and returns whether it was unreachable.
+func (n *node) markReachable(id uint64) bool {
+	n.unreachableLock.Lock()
+	defer n.unreachableLock.Unlock()
+	_, ok := n.unreachable[id]
+	delete(n.unreachable, id)
+	return ok
+}
+
+// markUnreachable adds the peer to the unreachable ones,
+// and returns whether it was unreachable already.
+func (n *node) markUnreachable(id uint64) bool {
+	n.unreachableLock.Lock()
+	defer n.unreachableLock.Unlock()
+	_, ok := n.unreachable[id]
+	n.unreachable[id] = struct{}{}
+	return ok
+}
+
 func (n *node) logSendFailure(dest uint64, err error) {
-	if _, ok := n.unreachable[dest]; ok {
+	if n.markUnreachable(dest) {
 		n.logger.Debugf("Failed to send StepRequest to %d, because: %s", dest, err)
+		// The peer stays unreachable, its endpoint may have moved
+		n.chain.reresolve(dest)
 		return
 	}
 
-	n.logger.Errorf("Failed to send StepRequest to %d, because: %s", dest, err)
-	n.unreachable[dest] = struct{}{}
+	cause := unreachableCause(err)
+	n.logger.Errorf("Failed to send StepRequest to %d (%s), because: %s", dest, cause, err)
+
+	n.metrics.PeerUnreachable.With("peer", strconv.FormatUint(dest, 10), "cause", cause).Add(1)
+	n.chain.notify(Event{Type: EventPeerUnreachable, Peer: dest, Cause: cause})
 }
 
-func (n *node) takeSnapshot(index uint64, cs raftpb.ConfState, data []byte) {
-	if err := n.storage.TakeSnapshot(index, cs, data); err != nil {
+// Causes for which a peer is unreachable.
+const (
+	// UnreachableDialTimeout means a connection to
+	// the peer could not be established in time.
+	UnreachableDialTimeout = "dial_timeout"
+	// UnreachableTLSFailure means the TLS handshake with the peer failed,
+	// e.g. because its certificate is expired or not trusted.
+	UnreachableTLSFailure = "tls_failure"
+	// UnreachableRPCError means the peer is connected,
+	// but the message could not be sent to it.
+	UnreachableRPCError = "rpc_error"
+)
+
+// unreachableCause classifies the error with which sending to a peer failed.
+func unreachableCause(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "x509"), strings.Contains(msg, "tls:"), strings.Contains(msg, "handshake"):
+		return UnreachableTLSFailure
+	case errors.Cause(err) == context.DeadlineExceeded,
+		strings.Contains(msg, "failed to create new connection"),
+		strings.Contains(msg, "is in state "+connectivity.Connecting.String()):
+		return UnreachableDialTimeout
+	default:
+		return UnreachableRPCError
+	}
+}
+
+func (n *node) takeSnapshot(index uint64, cs raftpb.ConfState, data []byte) error {
+	err := n.faults.Snapshot(index, data)
+	if err == nil {
+		err = n.storage.TakeSnapshot(index, cs, data)
+	}
+	if err != nil {
 		n.logger.Errorf("Failed to create snapshot at index %d: %s", index, err)
 	}
+	return err
+}
+
+// committed returns the index of the last entry known to be committed.
+func (n *node) committed() uint64 {
+	return atomic.LoadUint64(&n.committedIndex)
 }
 
 func (n *node) lastIndex() uint64 {
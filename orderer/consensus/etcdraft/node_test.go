@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnreachableCause(t *testing.T) {
+	for _, testCase := range []struct {
+		err      error
+		expected string
+	}{
+		{
+			err:      errors.New("x509: certificate has expired or is not yet valid"),
+			expected: UnreachableTLSFailure,
+		},
+		{
+			err:      errors.New("connection error: desc = \"transport: authentication handshake failed: remote error: tls: bad certificate\""),
+			expected: UnreachableTLSFailure,
+		},
+		{
+			err:      errors.WithMessage(errors.WithStack(context.DeadlineExceeded), "failed to create new connection"),
+			expected: UnreachableDialTimeout,
+		},
+		{
+			err:      errors.WithStack(context.DeadlineExceeded),
+			expected: UnreachableDialTimeout,
+		},
+		{
+			err:      errors.New("connection to 2(node2:7050) is in state CONNECTING"),
+			expected: UnreachableDialTimeout,
+		},
+		{
+			err:      errors.New("rpc timeout expired"),
+			expected: UnreachableRPCError,
+		},
+		{
+			err:      errors.New("stream aborted"),
+			expected: UnreachableRPCError,
+		},
+	} {
+		assert.Equal(t, testCase.expected, unreachableCause(testCase.err), testCase.err.Error())
+	}
+}
+
+type configurator struct{}
+
+func (configurator) Configure(channel string, newNodes []cluster.RemoteNode) {}
+
+type reresolvingConfigurator struct {
+	configurator
+	calls    chan uint64
+	proceedC chan struct{}
+}
+
+func (rc *reresolvingConfigurator) Reresolve(channel string, id uint64) (bool, error) {
+	rc.calls <- id
+	<-rc.proceedC
+	return true, nil
+}
+
+func TestReresolve(t *testing.T) {
+	logger := flogging.MustGetLogger("test")
+
+	// a configurator which doesn't re-resolve endpoints is left alone
+	c := &Chain{configurator: configurator{}, channelID: "foo", logger: logger}
+	c.reresolve(2)
+
+	reresolver := &reresolvingConfigurator{calls: make(chan uint64, 10), proceedC: make(chan struct{})}
+	var _ Reresolver = (*cluster.Comm)(nil)
+	c = &Chain{configurator: reresolver, channelID: "foo", logger: logger}
+
+	// endpoints are re-resolved in the background, once at a time per peer
+	c.reresolve(2)
+	assert.Equal(t, uint64(2), <-reresolver.calls)
+	c.reresolve(2)
+	c.reresolve(3)
+	assert.Equal(t, uint64(3), <-reresolver.calls)
+	assert.Empty(t, reresolver.calls)
+
+	// once done, the endpoint of a peer is re-resolved again
+	close(reresolver.proceedC)
+	inFlight := func() bool {
+		inFlight := false
+		c.reresolving.Range(func(_, _ interface{}) bool {
+			inFlight = true
+			return false
+		})
+		return inFlight
+	}
+	for i := 0; i < 100 && inFlight(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.False(t, inFlight())
+	c.reresolve(2)
+	assert.Equal(t, uint64(2), <-reresolver.calls)
+}
+
+func TestUnreachable(t *testing.T) {
+	n := &node{unreachable: make(map[uint64]struct{})}
+	assert.False(t, n.markUnreachable(2))
+	assert.True(t, n.markUnreachable(2))
+	assert.True(t, n.isUnreachable(2))
+	assert.True(t, n.markReachable(2))
+	assert.False(t, n.markReachable(2))
+	assert.False(t, n.isUnreachable(2))
+
+	// peers are marked by the goroutine sending messages while others read them
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			n.markUnreachable(uint64(i % 5))
+			n.markReachable(uint64(i % 3))
+		}
+	}()
+	for i := 0; i < 1000; i++ {
+		n.isUnreachable(uint64(i % 5))
+	}
+	wg.Wait()
+}
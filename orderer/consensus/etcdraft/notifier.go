@@ -0,0 +1,188 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultWebhookTimeout is the time a webhook target has to respond
+	// to a notification, if Consensus.WebhookTimeout is not set.
+	DefaultWebhookTimeout = 5 * time.Second
+
+	// notificationQueueSize is the number of notifications waiting to be
+	// delivered to a target, beyond which further notifications are dropped.
+	notificationQueueSize = 1000
+)
+
+// EventType is the type of a consensus event.
+type EventType string
+
+const (
+	// EventLeaderChange is emitted when a node observes a new leader,
+	// or the loss of the leader, in which case the leader is 0.
+	EventLeaderChange EventType = "leader_change"
+	// EventMembershipChange is emitted when a node is added to or removed
+	// from the replica set of the channel.
+	EventMembershipChange EventType = "membership_change"
+	// EventEviction is emitted when a node finds out it has been removed
+	// from the channel and halts its chain.
+	EventEviction EventType = "eviction"
+	// EventBlockMismatch is emitted when a block pulled from another
+	// consenter does not match the block of the same sequence of the node.
+	EventBlockMismatch EventType = "block_mismatch"
+	// EventPeerUnreachable is emitted when a node fails to send a message
+	// to a peer it could send messages to, along with the cause.
+	EventPeerUnreachable EventType = "peer_unreachable"
+	// EventPeerReachable is emitted when a node sends a message to a peer
+	// which was unreachable.
+	EventPeerReachable EventType = "peer_reachable"
+	// EventMembershipDrift is emitted when the raft nodes of a node persistently
+	// differ from the consenters of the channel, with the first consenter which
+	// is not a raft node as the added node, and the first raft node which is not
+	// a consenter as the removed node.
+	EventMembershipDrift EventType = "membership_drift"
+	// EventMarker is emitted when a node applies a marker,
+	// with the reason of the marker as the cause.
+	EventMarker EventType = "marker"
+	// EventChainWedged is emitted when a node has not processed any event
+	// of the channel for longer than the watchdog timeout, with the time
+	// since it last did as the cause.
+	EventChainWedged EventType = "chain_wedged"
+	// EventConfChangeStalled is emitted when a ConfChange, during which the
+	// node refuses transactions, is not applied within the ConfChange timeout,
+	// with the added or removed node of the ConfChange.
+	EventConfChangeStalled EventType = "conf_change_stalled"
+	// EventConfChangeRetriesExhausted is emitted when the leader proposed a
+	// ConfChange the maximum number of attempts without it being applied,
+	// with the added or removed node of the ConfChange.
+	EventConfChangeRetriesExhausted EventType = "conf_change_retries_exhausted"
+	// EventNodeDegraded is emitted when a node classifies itself, or a peer
+	// it leads, as degraded: up but too slow, with the latency it is slow at
+	// as the cause.
+	EventNodeDegraded EventType = "node_degraded"
+	// EventNodeRecovered is emitted when a node classified as degraded
+	// is fast again, with the latency it was slow at as the cause.
+	EventNodeRecovered EventType = "node_recovered"
+)
+
+// Event describes a change in the consensus of a channel, as observed by a node.
+type Event struct {
+	Type           EventType `json:"type"`
+	Channel        string    `json:"channel"`
+	NodeID         uint64    `json:"node_id"`
+	Time           time.Time `json:"time"`
+	Leader         uint64    `json:"leader,omitempty"`
+	PreviousLeader uint64    `json:"previous_leader,omitempty"`
+	AddedNode      uint64    `json:"added_node,omitempty"`
+	RemovedNode    uint64    `json:"removed_node,omitempty"`
+	Block          uint64    `json:"block,omitempty"`
+	Endpoint       string    `json:"endpoint,omitempty"`
+	Peer           uint64    `json:"peer,omitempty"`
+	Cause          string    `json:"cause,omitempty"`
+	Marker         string    `json:"marker,omitempty"`
+}
+
+//go:generate counterfeiter -o mocks/mock_notifier.go . Notifier
+
+// Notifier notifies operators about consensus events.
+type Notifier interface {
+	// Notify delivers the event. It must not block the chain.
+	Notify(event Event)
+}
+
+// WebhookNotifier posts consensus events as JSON to HTTP webhook targets.
+// Events are delivered asynchronously and in order, by a worker per target,
+// so that a slow target does not delay the others. If a target falls behind,
+// further events are dropped for it rather than blocking the chains.
+type WebhookNotifier struct {
+	URLs   []string
+	Client *http.Client
+	Logger *flogging.FabricLogger
+
+	targets []*webhookTarget
+}
+
+// webhookTarget is the queue of the events to post to a target.
+type webhookTarget struct {
+	url    string
+	events chan Event
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to the given URLs,
+// and starts delivering notifications.
+func NewWebhookNotifier(urls []string, timeout time.Duration, logger *flogging.FabricLogger) *WebhookNotifier {
+	wn := &WebhookNotifier{
+		URLs:   urls,
+		Client: &http.Client{Timeout: timeout},
+		Logger: logger,
+	}
+	for _, url := range urls {
+		target := &webhookTarget{url: url, events: make(chan Event, notificationQueueSize)}
+		wn.targets = append(wn.targets, target)
+		go wn.run(target)
+	}
+	return wn
+}
+
+// ValidateWebhookURL returns an error if the URL is not an absolute
+// HTTP or HTTPS URL which notifications can be posted to.
+func ValidateWebhookURL(rawurl string) error {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return errors.Wrapf(err, "invalid webhook URL %s", rawurl)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.Errorf("webhook URL %s must be http or https", rawurl)
+	}
+	if u.Host == "" {
+		return errors.Errorf("webhook URL %s has no host", rawurl)
+	}
+	return nil
+}
+
+// Notify enqueues the event for delivery to every target.
+func (wn *WebhookNotifier) Notify(event Event) {
+	for _, target := range wn.targets {
+		select {
+		case target.events <- event:
+		default:
+			wn.Logger.Warnf("Dropping %s notification of channel %s, webhook %s is too slow", event.Type, event.Channel, target.url)
+		}
+	}
+}
+
+func (wn *WebhookNotifier) run(target *webhookTarget) {
+	for event := range target.events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			wn.Logger.Errorf("Failed to marshal %s notification: %s", event.Type, err)
+			continue
+		}
+		wn.post(target.url, body)
+	}
+}
+
+func (wn *WebhookNotifier) post(url string, body []byte) {
+	resp, err := wn.Client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		wn.Logger.Warnf("Failed to notify webhook %s: %s", url, err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		wn.Logger.Warnf("Webhook %s responded to notification with status %s", url, resp.Status)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan Event, 10)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var event Event
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}
+	target1 := httptest.NewServer(http.HandlerFunc(handler))
+	defer target1.Close()
+	target2 := httptest.NewServer(http.HandlerFunc(handler))
+	defer target2.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	// a target which does not respond does not hold up the others
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	warnings := make(chan string, 10)
+	logger := flogging.MustGetLogger("test").WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
+		if entry.Level == zapcore.WarnLevel {
+			warnings <- entry.Message
+		}
+		return nil
+	}))
+
+	wn := NewWebhookNotifier([]string{hanging.URL, target1.URL, failing.URL, target2.URL}, time.Minute, logger)
+
+	sent := Event{
+		Type:           EventLeaderChange,
+		Channel:        "foo",
+		NodeID:         1,
+		Time:           time.Unix(1000, 0).UTC(),
+		Leader:         2,
+		PreviousLeader: 3,
+	}
+	wn.Notify(sent)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-received:
+			assert.Equal(t, sent, event)
+		case <-time.After(5 * time.Second):
+			t.Fatal("webhook was not notified")
+		}
+	}
+
+	select {
+	case warning := <-warnings:
+		assert.True(t, strings.HasPrefix(warning, "Webhook "+failing.URL+" responded to notification with status 500"), warning)
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed notification was not logged")
+	}
+}
+
+func TestWebhookNotifierDropsEvents(t *testing.T) {
+	dropped := make(chan string, 1)
+	logger := flogging.MustGetLogger("test").WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
+		select {
+		case dropped <- entry.Message:
+		default:
+		}
+		return nil
+	}))
+
+	// not running, so that the queue of the slow target fills up
+	slow := &webhookTarget{url: "http://slow", events: make(chan Event, 1)}
+	fast := &webhookTarget{url: "http://fast", events: make(chan Event, 2)}
+	wn := &WebhookNotifier{Logger: logger, targets: []*webhookTarget{slow, fast}}
+	wn.Notify(Event{Type: EventEviction, Channel: "foo"})
+	wn.Notify(Event{Type: EventEviction, Channel: "foo"})
+
+	select {
+	case msg := <-dropped:
+		assert.Equal(t, "Dropping eviction notification of channel foo, webhook http://slow is too slow", msg)
+	default:
+		require.Fail(t, "event was not dropped")
+	}
+	assert.Len(t, slow.events, 1)
+	assert.Len(t, fast.events, 2)
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, ValidateWebhookURL("https://alerts.example.com/fabric/orderer"))
+	assert.NoError(t, ValidateWebhookURL("http://localhost:8080"))
+	assert.EqualError(t, ValidateWebhookURL("alerts.example.com/fabric"), "webhook URL alerts.example.com/fabric must be http or https")
+	assert.EqualError(t, ValidateWebhookURL("ftp://alerts.example.com"), "webhook URL ftp://alerts.example.com must be http or https")
+	assert.EqualError(t, ValidateWebhookURL("https:///fabric"), "webhook URL https:///fabric has no host")
+	assert.Error(t, ValidateWebhookURL("http://[::1"))
+}
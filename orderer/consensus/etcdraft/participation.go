@@ -0,0 +1,211 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/common/channelconfig"
+	"github.com/hyperledger/fabric/core/middleware"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// validateJoinBlock checks that the given block is a config block of an etcdraft
+// channel which deprecates the system channel, and which this node is a consenter of,
+// and returns the ID of the channel.
+func (c *Consenter) validateJoinBlock(block *common.Block) (string, error) {
+	if block.Header == nil || block.Data == nil || !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return "", errors.New("block is malformed")
+	}
+	if !utils.IsConfigBlock(block) {
+		return "", errors.Errorf("block %d is not a config block", block.Header.Number)
+	}
+	env, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		return "", err
+	}
+	bundle, err := channelconfig.NewBundleFromEnvelope(env)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed creating bundle from config block %d", block.Header.Number)
+	}
+	channelID := bundle.ConfigtxValidator().ChainID()
+
+	if _, isSystemChannel := bundle.ConsortiumsConfig(); isSystemChannel {
+		return "", errors.Errorf("channel %s is a system channel", channelID)
+	}
+	oc, exists := bundle.OrdererConfig()
+	if !exists {
+		return "", errors.Errorf("config block %d of channel %s has no orderer config", block.Header.Number, channelID)
+	}
+	if !oc.Capabilities().SystemChannelDeprecation() {
+		return "", errors.Errorf("channel %s does not deprecate the system channel, as it lacks the %s orderer capability",
+			channelID, capabilities.OrdererV2_1)
+	}
+	if oc.ConsensusType() != etcdraft.TypeKey {
+		return "", errors.Errorf("consensus type of channel %s is %s, not %s", channelID, oc.ConsensusType(), etcdraft.TypeKey)
+	}
+	if err := ConsenterCertificate(c.Cert).IsConsenterOfChannel(block); err != nil {
+		return "", errors.Wrapf(err, "this node is not a consenter of channel %s", channelID)
+	}
+	return channelID, nil
+}
+
+// participationHandler joins this node to channels which deprecate the system
+// channel, and removes it from them, independently of the system channel.
+type participationHandler struct {
+	consenter *Consenter
+	// lock serializes joins and removals, so that a channel is not created twice
+	lock sync.Mutex
+}
+
+// ParticipationHandler returns a handler which joins this node to a channel that deprecates
+// the system channel for POST requests, whose body is a marshaled config block of the channel,
+// and removes this node from a channel for DELETE requests of the form ?channel=<channel ID>.
+//
+// A channel is joined by creating its chain out of the config block, which is trusted like a
+// bootstrap block: the ledger of a channel joined with its genesis block starts with it, and the
+// consenters elect a leader once a quorum of them is joined. The ledger of a channel joined with
+// a later config block is seeded with it, and the chain catches up with the rest of the channel
+// from the other consenters. A channel is removed by halting its chain, and removing its ledger
+// along with its WAL and snapshots.
+//
+// Requests are refused unless they are made over TLS with a client certificate verified by the
+// operations server, even if TLS is disabled for the rest of the operations server.
+func (c *Consenter) ParticipationHandler() http.Handler {
+	return middleware.RequireCert()(&participationHandler{consenter: c})
+}
+
+func (h *participationHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodPost:
+		h.join(resp, req)
+	case http.MethodDelete:
+		h.remove(resp, req)
+	default:
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+	}
+}
+
+func (h *participationHandler) join(resp http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("failed reading config block: %s", err))
+		return
+	}
+	block, err := utils.UnmarshalBlock(body)
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("malformed config block: %s", err))
+		return
+	}
+	h.joinBlock(resp, block, "config block")
+}
+
+// joinBlock joins the channel of the given block, whose kind is used in the errors
+// returned to the client, and writes the response.
+func (h *participationHandler) joinBlock(resp http.ResponseWriter, block *common.Block, kind string) {
+	channelID, err := h.consenter.validateJoinBlock(block)
+	if errors.Cause(err) == cluster.ErrNotInChannel {
+		sendJSONError(resp, http.StatusForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid %s: %s", kind, err))
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.consenter.Chains.GetChain(channelID) != nil {
+		sendJSONError(resp, http.StatusConflict, fmt.Sprintf("channel %s already exists", channelID))
+		return
+	}
+	if err := h.consenter.JoinChain(block); err != nil {
+		if h.consenter.Chains.GetChain(channelID) != nil {
+			// The channel was concurrently joined through another handler
+			sendJSONError(resp, http.StatusConflict, fmt.Sprintf("channel %s already exists", channelID))
+			return
+		}
+		h.consenter.Logger.Errorw("failed joining channel", "channel", channelID, "error", err)
+		sendJSONError(resp, http.StatusInternalServerError, fmt.Sprintf("failed joining channel %s: %s", channelID, err))
+		return
+	}
+	h.consenter.Logger.Infof("Joined channel %s with config block %d", channelID, block.Header.Number)
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(resp).Encode(map[string]string{"channel": channelID}); err != nil {
+		h.consenter.Logger.Errorw("failed to encode join response", "channel", channelID, "error", err)
+	}
+}
+
+func (h *participationHandler) remove(resp http.ResponseWriter, req *http.Request) {
+	channelID := req.URL.Query().Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	cs := h.consenter.Chains.GetChain(channelID)
+	if cs == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s does not exist", channelID))
+		return
+	}
+	if !cs.SharedConfig().Capabilities().SystemChannelDeprecation() {
+		sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("channel %s does not deprecate the system channel, as it lacks the %s orderer capability",
+			channelID, capabilities.OrdererV2_1))
+		return
+	}
+
+	if err := h.consenter.removeChain(channelID); err != nil {
+		h.consenter.Logger.Errorw("failed removing channel", "channel", channelID, "error", err)
+		sendJSONError(resp, http.StatusInternalServerError, fmt.Sprintf("failed removing channel %s: %s", channelID, err))
+		return
+	}
+	h.consenter.Logger.Infof("Removed channel %s", channelID)
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(map[string]string{"channel": channelID}); err != nil {
+		h.consenter.Logger.Errorw("failed to encode removal response", "channel", channelID, "error", err)
+	}
+}
+
+// removeChain removes the chain of the given channel along with its ledger, and then
+// its WAL and snapshots, so that the channel can be joined once again from scratch.
+func (c *Consenter) removeChain(channelID string) error {
+	if c.InactiveChainRegistry != nil {
+		c.InactiveChainRegistry.UntrackChain(channelID)
+	}
+	if err := c.RemoveChain(channelID); err != nil {
+		return err
+	}
+	for _, dir := range []string{c.EtcdRaftConfig.WALDir, c.EtcdRaftConfig.SnapDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.RemoveAll(path.Join(dir, channelID)); err != nil {
+			return errors.Wrapf(err, "failed removing %s", path.Join(dir, channelID))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/capabilities"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blockledger"
+	"github.com/hyperledger/fabric/common/ledger/blockledger/ram"
+	"github.com/hyperledger/fabric/common/metrics/disabled"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/configtxgentest"
+	"github.com/hyperledger/fabric/common/tools/configtxgen/encoder"
+	genesisconfig "github.com/hyperledger/fabric/common/tools/configtxgen/localconfig"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/inactive"
+	"github.com/hyperledger/fabric/orderer/consensus/solo"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inactiveConsenter creates inactive chains, standing in for
+// the etcdraft consenter in the registrar.
+type inactiveConsenter struct{}
+
+func (inactiveConsenter) HandleChain(_ consensus.ConsenterSupport, _ *common.Metadata) (consensus.Chain, error) {
+	return &inactive.Chain{Err: errors.New("inactive")}, nil
+}
+
+// untrackingRegistry records the chains it stops tracking.
+type untrackingRegistry struct {
+	untracked []string
+}
+
+func (r *untrackingRegistry) TrackChain(string, *common.Block, CreateChainCallback) {}
+
+func (r *untrackingRegistry) UntrackChain(chainName string) {
+	r.untracked = append(r.untracked, chainName)
+}
+
+// clientAuthenticated returns the state of a TLS connection
+// whose client certificate was verified by the server.
+func clientAuthenticated() *tls.ConnectionState {
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}
+}
+
+func TestParticipationHandler(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+	consenter := newConsenter(t, ca, "orderer1")
+
+	dir, err := ioutil.TempDir("", "participation")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	serverCertPath := filepath.Join(dir, "server.crt")
+	clientCertPath := filepath.Join(dir, "client.crt")
+	require.NoError(t, ioutil.WriteFile(serverCertPath, consenter.ServerTlsCert, 0600))
+	require.NoError(t, ioutil.WriteFile(clientCertPath, consenter.ClientTlsCert, 0600))
+
+	configBlock := func(systemChannel, deprecated bool) *common.Block {
+		conf := configtxgentest.Load(genesisconfig.SampleDevModeEtcdRaftProfile)
+		if !systemChannel {
+			conf.Consortiums = nil
+		}
+		conf.Orderer.Capabilities = map[string]bool{capabilities.OrdererV1_1: true, capabilities.OrdererV2_1: deprecated}
+		conf.Orderer.EtcdRaft.Consenters = []*etcdraft.Consenter{{
+			Host:          consenter.Host,
+			Port:          consenter.Port,
+			ServerTlsCert: []byte(serverCertPath),
+			ClientTlsCert: []byte(clientCertPath),
+		}}
+		return encoder.New(conf).GenesisBlockForChannel("mychannel")
+	}
+
+	lf := ramledger.New(10)
+	sysLedger, err := lf.GetOrCreate(genesisconfig.TestChainID)
+	require.NoError(t, err)
+	require.NoError(t, sysLedger.Append(encoder.New(configtxgentest.Load(genesisconfig.SampleInsecureSoloProfile)).GenesisBlock()))
+	registrar := multichannel.NewRegistrar(lf, mockcrypto.FakeLocalSigner, &disabled.Provider{})
+	registrar.Initialize(map[string]consensus.Consenter{"solo": solo.New(), "etcdraft": inactiveConsenter{}})
+
+	icr := &untrackingRegistry{}
+	c := &Consenter{
+		Chains:                registrar,
+		JoinChain:             registrar.JoinChain,
+		RemoveChain:           registrar.RemoveChain,
+		InactiveChainRegistry: icr,
+		Logger:                flogging.MustGetLogger("test"),
+		Cert:                  consenter.ServerTlsCert,
+		EtcdRaftConfig: Config{
+			WALDir:  filepath.Join(dir, "wal"),
+			SnapDir: filepath.Join(dir, "snapshot"),
+		},
+	}
+	handler := c.ParticipationHandler()
+
+	serve := func(method, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader(body))
+		req.TLS = clientAuthenticated()
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+	errorOf := func(resp *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return body["error"]
+	}
+
+	genesisBlock := configBlock(false, true)
+	t.Run("joins a channel", func(t *testing.T) {
+		resp := serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(genesisBlock))
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+		assert.JSONEq(t, `{"channel":"mychannel"}`, resp.Body.String())
+		cs := registrar.GetChain("mychannel")
+		require.NotNil(t, cs)
+		assert.True(t, proto.Equal(genesisBlock, blockledger.GetBlock(cs, 0)))
+
+		resp = serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(genesisBlock))
+		assert.Equal(t, http.StatusConflict, resp.Code)
+		assert.Equal(t, "channel mychannel already exists", errorOf(resp))
+	})
+
+	t.Run("removes a channel", func(t *testing.T) {
+		for _, d := range []string{c.EtcdRaftConfig.WALDir, c.EtcdRaftConfig.SnapDir} {
+			require.NoError(t, os.MkdirAll(filepath.Join(d, "mychannel"), 0700))
+		}
+
+		resp := serve(http.MethodDelete, "/etcdraft/participation?channel=mychannel", nil)
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		assert.Nil(t, registrar.GetChain("mychannel"))
+		assert.Equal(t, []string{"mychannel"}, icr.untracked)
+		for _, d := range []string{c.EtcdRaftConfig.WALDir, c.EtcdRaftConfig.SnapDir} {
+			_, err := os.Stat(filepath.Join(d, "mychannel"))
+			assert.True(t, os.IsNotExist(err))
+		}
+
+		resp = serve(http.MethodDelete, "/etcdraft/participation?channel=mychannel", nil)
+		assert.Equal(t, http.StatusNotFound, resp.Code)
+		resp = serve(http.MethodDelete, "/etcdraft/participation?channel="+genesisconfig.TestChainID, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.Contains(t, errorOf(resp), "does not deprecate the system channel")
+		resp = serve(http.MethodDelete, "/etcdraft/participation", nil)
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+	})
+
+	t.Run("joins a removed channel once again", func(t *testing.T) {
+		resp := serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(genesisBlock))
+		require.Equal(t, http.StatusCreated, resp.Code, resp.Body.String())
+		assert.Equal(t, uint64(1), registrar.GetChain("mychannel").Height())
+		require.NoError(t, registrar.RemoveChain("mychannel"))
+	})
+
+	t.Run("fails seeding a ledger which cannot start at a later config block", func(t *testing.T) {
+		block := configBlock(false, true)
+		block.Header.Number = 5
+		resp := serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(block))
+		assert.Equal(t, http.StatusInternalServerError, resp.Code)
+		assert.Contains(t, errorOf(resp), "ledger cannot be seeded with a config block other than the genesis block")
+	})
+
+	t.Run("not a consenter", func(t *testing.T) {
+		c.Cert = newConsenter(t, ca, "orderer2").ServerTlsCert
+		defer func() { c.Cert = consenter.ServerTlsCert }()
+		resp := serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(genesisBlock))
+		assert.Equal(t, http.StatusForbidden, resp.Code)
+		assert.Contains(t, errorOf(resp), "this node is not a consenter of channel mychannel")
+	})
+
+	t.Run("invalid config blocks", func(t *testing.T) {
+		malformed := configBlock(false, true)
+		malformed.Header.DataHash = []byte{1, 2, 3}
+		notConfig := configBlock(false, true)
+		notConfig.Data.Data = [][]byte{utils.MarshalOrPanic(&common.Envelope{})}
+		notConfig.Header.DataHash = notConfig.Data.Hash()
+
+		for _, tc := range []struct {
+			name          string
+			block         *common.Block
+			expectedError string
+		}{
+			{"not deprecating the system channel", configBlock(false, false),
+				"invalid config block: channel mychannel does not deprecate the system channel, as it lacks the V2_1 orderer capability"},
+			{"system channel", configBlock(true, true), "invalid config block: channel mychannel is a system channel"},
+			{"not a config block", notConfig, "invalid config block: block 0 is not a config block"},
+			{"malformed block", malformed, "invalid config block: block is malformed"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				resp := serve(http.MethodPost, "/etcdraft/participation", utils.MarshalOrPanic(tc.block))
+				assert.Equal(t, http.StatusBadRequest, resp.Code)
+				assert.Equal(t, tc.expectedError, errorOf(resp))
+			})
+		}
+	})
+
+	t.Run("requires TLS client authentication", func(t *testing.T) {
+		for _, state := range []*tls.ConnectionState{nil, {}} {
+			req := httptest.NewRequest(http.MethodPost, "/etcdraft/participation", bytes.NewReader(utils.MarshalOrPanic(genesisBlock)))
+			req.TLS = state
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusUnauthorized, resp.Code)
+		}
+		assert.Nil(t, registrar.GetChain("mychannel"))
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		resp := serve(http.MethodPost, "/etcdraft/participation", []byte("not a block"))
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		resp = serve(http.MethodGet, "/etcdraft/participation", nil)
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	})
+
+	assert.Nil(t, registrar.GetChain("mychannel"))
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/metrics"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Resources of the orderer process whose consumption by a chain is bounded by quotas.
+const (
+	QuotaTicks          = "ticks"
+	QuotaPersistedBytes = "persisted_bytes"
+	QuotaAppliedBlocks  = "applied_blocks"
+)
+
+// Quotas bound the resources a chain consumes, so that a busy chain cannot
+// starve the other chains sharing the orderer process and its disk.
+// A resource is not bounded if its quota is not set.
+type Quotas struct {
+	// TicksPerSecond bounds the raft ticks processed. Ticks beyond it
+	// are skipped, which slows down heartbeats and elections.
+	TicksPerSecond float64 `json:"ticks_per_second"`
+	// PersistedBytesPerSecond bounds the raft entries written to the WAL.
+	PersistedBytesPerSecond uint64 `json:"persisted_bytes_per_second"`
+	// AppliedBlocksPerSecond bounds the blocks written to the ledger.
+	AppliedBlocksPerSecond float64 `json:"applied_blocks_per_second"`
+}
+
+// quota is a token bucket bounding the rate at which a chain consumes
+// a resource. The bucket holds one second worth of the rate, and may go
+// into debt for requests larger than it, which are then paid off before
+// further requests are granted. A quota is used by a single goroutine.
+type quota struct {
+	resource  string
+	rate      float64 // per second, unbounded if zero
+	clock     clock.Clock
+	throttled metrics.Counter
+
+	tokens     float64
+	lastRefill time.Time // zero until the first request, when the bucket starts full
+}
+
+func newQuota(resource string, rate float64, clock clock.Clock, throttled metrics.Counter) *quota {
+	return &quota{
+		resource:  resource,
+		rate:      rate,
+		clock:     clock,
+		throttled: throttled,
+	}
+}
+
+func (q *quota) refill() {
+	now := q.clock.Now()
+	if q.lastRefill.IsZero() {
+		q.tokens = q.rate
+	} else {
+		q.tokens += now.Sub(q.lastRefill).Seconds() * q.rate
+	}
+	q.lastRefill = now
+	if q.tokens > q.rate {
+		q.tokens = q.rate
+	}
+}
+
+// allow takes n tokens from the bucket and returns whether they were available.
+func (q *quota) allow(n float64) bool {
+	if q.rate == 0 {
+		return true
+	}
+
+	q.refill()
+	if q.tokens < n {
+		q.throttled.With("resource", q.resource).Add(1)
+		return false
+	}
+	q.tokens -= n
+	return true
+}
+
+// wait takes n tokens from the bucket, and blocks until the debt they
+// leave, if any, is paid off, or until doneC is closed.
+func (q *quota) wait(n float64, doneC <-chan struct{}) {
+	if q.rate == 0 {
+		return
+	}
+
+	q.refill()
+	q.tokens -= n
+	if q.tokens >= 0 {
+		return
+	}
+
+	q.throttled.With("resource", q.resource).Add(1)
+	timer := q.clock.NewTimer(time.Duration(-q.tokens / q.rate * float64(time.Second)))
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+	case <-doneC:
+	}
+}
+
+// entriesSize returns the size of the given entries, in bytes.
+func entriesSize(ents []raftpb.Entry) float64 {
+	var size int
+	for i := range ents {
+		size += ents[i].Size()
+	}
+	return float64(size)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuotaAllow(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	throttled := &metricsfakes.Counter{}
+	throttled.WithReturns(throttled)
+
+	// an unbounded quota allows everything
+	q := newQuota(QuotaTicks, 0, clock, throttled)
+	for i := 0; i < 100; i++ {
+		assert.True(t, q.allow(1))
+	}
+
+	// the bucket starts full with one second worth of the rate
+	q = newQuota(QuotaTicks, 10, clock, throttled)
+	for i := 0; i < 10; i++ {
+		assert.True(t, q.allow(1))
+	}
+	assert.False(t, q.allow(1))
+	assert.Equal(t, 1, throttled.AddCallCount())
+	assert.Equal(t, []string{"resource", QuotaTicks}, throttled.WithArgsForCall(0))
+
+	// and is refilled at the rate
+	clock.Increment(200 * time.Millisecond)
+	assert.True(t, q.allow(1))
+	assert.True(t, q.allow(1))
+	assert.False(t, q.allow(1))
+
+	// but never holds more than one second worth of it
+	clock.Increment(time.Minute)
+	for i := 0; i < 10; i++ {
+		assert.True(t, q.allow(1))
+	}
+	assert.False(t, q.allow(1))
+}
+
+func TestQuotaWait(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	throttled := &metricsfakes.Counter{}
+	throttled.WithReturns(throttled)
+	doneC := make(chan struct{})
+
+	q := newQuota(QuotaPersistedBytes, 100, clock, throttled)
+	q.wait(100, doneC)
+	assert.Zero(t, throttled.AddCallCount())
+
+	// a request larger than the bucket waits until its debt is paid off
+	waited := make(chan struct{})
+	go func() {
+		q.wait(150, doneC)
+		close(waited)
+	}()
+	for clock.WatcherCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Increment(time.Second)
+	select {
+	case <-waited:
+		t.Fatal("wait returned before the debt was paid off")
+	case <-time.After(100 * time.Millisecond):
+	}
+	clock.Increment(500 * time.Millisecond)
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after the debt was paid off")
+	}
+	assert.Equal(t, 1, throttled.AddCallCount())
+	assert.Equal(t, []string{"resource", QuotaPersistedBytes}, throttled.WithArgsForCall(0))
+
+	// and stops waiting when doneC is closed
+	aborted := make(chan struct{})
+	go func() {
+		q.wait(1000, doneC)
+		close(aborted)
+	}()
+	close(doneC)
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Fatal("wait did not return after doneC was closed")
+	}
+}
@@ -0,0 +1,319 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package raftconfig validates the etcdraft configuration of channels. It is
+// shared by the etcdraft chains and channel tooling, hence it must not depend
+// on the orderer packages which channel tooling does not use.
+package raftconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/crypto"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// ValidateConfigMetadata checks that the metadata can be used to run a channel: its
+// consenters must not exceed the maximum cluster size, their TLS certificates must be
+// valid and not shared with other consenters, and its options, if set, must be in range.
+// Consenters must also have an endpoint if expectHosts is set, which channel tooling
+// may leave unset while it checks metadata whose endpoints are filled in later on.
+// Channel tooling should validate metadata with it, so that invalid configs are caught
+// before they are submitted. Chains validate the consenters of config updates likewise,
+// but only check the election and TLS options, as those are all a chain applies at runtime.
+func ValidateConfigMetadata(md *etcdraft.ConfigMetadata, expectHosts bool) error {
+	if errs := (MetadataValidation{ExpectHosts: expectHosts, Now: time.Now()}).Errors(md); len(errs) > 0 {
+		return errs[0]
+	}
+	if md.Options != nil {
+		if err := ValidateOptions(md.Options); err != nil {
+			return errors.Wrap(err, "invalid options")
+		}
+	}
+	return nil
+}
+
+// MetadataValidation is how metadata is validated.
+type MetadataValidation struct {
+	ExpectHosts bool      // consenters must have an endpoint
+	Now         time.Time // TLS certificates of consenters must be valid at
+	// Current are the consenters of the config the metadata updates, if any,
+	// whose TLS certificates are only required to parse. Consenters whose
+	// certificates expire can thus be rotated one config update at a time.
+	Current []*etcdraft.Consenter
+}
+
+// Errors returns all the reasons for which ValidateConfigMetadata rejects
+// the metadata, except for its options, or nil if there are none.
+func (v MetadataValidation) Errors(md *etcdraft.ConfigMetadata) []error {
+	if md == nil {
+		return []error{errors.New("nil metadata")}
+	}
+
+	var errs []error
+	if err := MetadataHasDuplication(md); err != nil {
+		errs = append(errs, err)
+	}
+	if err := ValidateClusterSize(len(md.Consenters)); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, consenter := range md.Consenters {
+		if err := validateConsenter(consenter, v.ExpectHosts, v.checkCert(consenter)); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid consenter %s", consenterEndpoint(consenter)))
+		}
+	}
+	for _, consenter := range md.StandbyConsenters {
+		if err := validateConsenter(consenter, v.ExpectHosts, v.checkCert(consenter)); err != nil {
+			errs = append(errs, errors.Wrap(err, "invalid standby consenter"))
+		}
+	}
+	for _, consenter := range md.DrConsenters {
+		if err := validateConsenter(consenter, v.ExpectHosts, v.checkCert(consenter)); err != nil {
+			errs = append(errs, errors.Wrapf(err, "invalid DR consenter %s", consenterEndpoint(consenter)))
+		}
+	}
+	return errs
+}
+
+// checkCert returns how the TLS certificates of the consenter are checked.
+func (v MetadataValidation) checkCert(consenter *etcdraft.Consenter) func(pemBytes []byte) error {
+	if ContainsConsenter(v.Current, consenter) {
+		return parseCert
+	}
+	return certChecker(v.Now)
+}
+
+func consenterEndpoint(consenter *etcdraft.Consenter) string {
+	if consenter == nil {
+		return "<nil>"
+	}
+	return fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+}
+
+// ValidateClusterSize checks that the given number of
+// consenters does not exceed etcdraft.MaxClusterSize.
+func ValidateClusterSize(consenters int) error {
+	if consenters > etcdraft.MaxClusterSize {
+		return errors.Errorf("%d consenters exceed the maximum cluster size of %d", consenters, etcdraft.MaxClusterSize)
+	}
+	return nil
+}
+
+// ValidateOptions checks that the options can be used to start a chain.
+func ValidateOptions(options *etcdraft.Options) error {
+	if options == nil {
+		return errors.New("nil options")
+	}
+	tickInterval, err := time.ParseDuration(options.TickInterval)
+	if err != nil {
+		return errors.Errorf("tick interval %s is not a duration", options.TickInterval)
+	}
+	if tickInterval <= 0 {
+		return errors.Errorf("tick interval %s is not positive", options.TickInterval)
+	}
+	if options.HeartbeatTick == 0 {
+		return errors.New("heartbeat tick is not set")
+	}
+	if options.ElectionTick <= options.HeartbeatTick {
+		return errors.New("election tick must be greater than heartbeat tick")
+	}
+	if options.MaxInflightMsgs == 0 {
+		return errors.New("max inflight messages is not set")
+	}
+	if options.MaxSizePerMsg == 0 {
+		return errors.New("max size per message is not set")
+	}
+	if err := ValidateElectionOptions(options); err != nil {
+		return err
+	}
+	return ValidateTLSOptions(options)
+}
+
+// ValidateElectionOptions checks that the options do not disable both pre-vote
+// and check quorum, in which case a node rejoining the network would disrupt
+// the leader by starting an election.
+func ValidateElectionOptions(options *etcdraft.Options) error {
+	if options != nil && options.DisablePreVote && options.DisableCheckQuorum {
+		return errors.New("pre-vote and check quorum cannot be both disabled")
+	}
+	return nil
+}
+
+// ValidateTLSOptions checks that the TLS policy of the options
+// names a supported TLS version and known cipher suites.
+func ValidateTLSOptions(options *etcdraft.Options) error {
+	if options == nil {
+		return nil
+	}
+	if options.TlsMinVersion != "" {
+		if _, err := crypto.ParseTLSVersion(options.TlsMinVersion); err != nil {
+			return errors.Wrap(err, "invalid TLS policy")
+		}
+	}
+	if _, err := crypto.ParseCipherSuites(options.TlsCipherSuites); err != nil {
+		return errors.Wrap(err, "invalid TLS policy")
+	}
+	return nil
+}
+
+// MetadataHasDuplication returns an error if the metadata has duplication of consenters.
+// A duplication is defined by having a server or a client TLS certificate that is found
+// in two different consenters, regardless of the type of certificate (client/server).
+// Standby and DR consenters are taken into account as well, except for DR consenters
+// which are listed as consenters too while they are being promoted.
+func MetadataHasDuplication(md *etcdraft.ConfigMetadata) error {
+	if md == nil {
+		return errors.New("nil metadata")
+	}
+
+	consenters := append(append([]*etcdraft.Consenter{}, md.Consenters...), md.StandbyConsenters...)
+	for _, consenter := range md.DrConsenters {
+		if consenter == nil || !ContainsConsenter(md.Consenters, consenter) {
+			consenters = append(consenters, consenter)
+		}
+	}
+	for _, consenter := range consenters {
+		if consenter == nil {
+			return errors.New("nil consenter in metadata")
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, consenter := range consenters {
+		serverKey := string(consenter.ServerTlsCert)
+		clientKey := string(consenter.ClientTlsCert)
+		_, duplicateServerCert := seen[serverKey]
+		_, duplicateClientCert := seen[clientKey]
+		if duplicateServerCert || duplicateClientCert {
+			return errors.Errorf("duplicate consenter: server cert: %s, client cert: %s", serverKey, clientKey)
+		}
+
+		seen[serverKey] = struct{}{}
+		seen[clientKey] = struct{}{}
+	}
+	return nil
+}
+
+// ContainsConsenter returns whether the given consenters contain the consenter.
+func ContainsConsenter(consenters []*etcdraft.Consenter, consenter *etcdraft.Consenter) bool {
+	for _, c := range consenters {
+		if proto.Equal(c, consenter) {
+			return true
+		}
+	}
+	return false
+}
+
+// minRSAKeyBits is the minimum size of RSA keys in consenter TLS certificates.
+const minRSAKeyBits = 2048
+
+// supportedSignatureAlgorithms are the signature algorithms accepted
+// for consenter TLS certificates.
+var supportedSignatureAlgorithms = map[x509.SignatureAlgorithm]bool{
+	x509.ECDSAWithSHA256:  true,
+	x509.ECDSAWithSHA384:  true,
+	x509.ECDSAWithSHA512:  true,
+	x509.SHA256WithRSA:    true,
+	x509.SHA384WithRSA:    true,
+	x509.SHA512WithRSA:    true,
+	x509.SHA256WithRSAPSS: true,
+	x509.SHA384WithRSAPSS: true,
+	x509.SHA512WithRSAPSS: true,
+}
+
+// ValidateConsenter checks that the consenter has an endpoint,
+// and that its TLS certificates are PEM encoded x509 certificates
+// which are currently valid, have strong keys and are signed with
+// a supported signature algorithm.
+func ValidateConsenter(consenter *etcdraft.Consenter) error {
+	return validateConsenter(consenter, true, certChecker(time.Now()))
+}
+
+// validateConsenter validates the consenter as ValidateConsenter does, checking
+// its TLS certificates with checkCert, and skipping its endpoint unless expectHost is set.
+func validateConsenter(consenter *etcdraft.Consenter, expectHost bool, checkCert func(pemBytes []byte) error) error {
+	if consenter == nil {
+		return errors.New("nil consenter")
+	}
+	// A trailing dot refers to the same host in DNS.
+	if expectHost && strings.TrimSuffix(consenter.Host, ".") == "" {
+		return errors.New("consenter has no host")
+	}
+	if expectHost && consenter.Port == 0 {
+		return errors.New("consenter has no port")
+	}
+	if err := checkCert(consenter.ClientTlsCert); err != nil {
+		return errors.Wrap(err, "invalid client TLS certificate")
+	}
+	if err := checkCert(consenter.ServerTlsCert); err != nil {
+		return errors.Wrap(err, "invalid server TLS certificate")
+	}
+	return nil
+}
+
+// certChecker returns a function which checks that certificates are valid at the given time.
+func certChecker(now time.Time) func(pemBytes []byte) error {
+	return func(pemBytes []byte) error {
+		return validateCert(pemBytes, now)
+	}
+}
+
+// parseCert checks that the certificate is a PEM encoded x509 certificate, which is all
+// that is required of the certificates of consenters already in the config of a channel.
+func parseCert(pemBytes []byte) error {
+	_, err := decodeCert(pemBytes)
+	return err
+}
+
+func decodeCert(pemBytes []byte) (*x509.Certificate, error) {
+	bl, _ := pem.Decode(pemBytes)
+	if bl == nil {
+		return nil, errors.New("no PEM data found")
+	}
+	return x509.ParseCertificate(bl.Bytes)
+}
+
+func validateCert(pemBytes []byte, now time.Time) error {
+	cert, err := decodeCert(pemBytes)
+	if err != nil {
+		return err
+	}
+
+	if now.After(cert.NotAfter) {
+		return errors.Errorf("certificate expired at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return errors.Errorf("certificate is not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+
+	if !supportedSignatureAlgorithms[cert.SignatureAlgorithm] {
+		return errors.Errorf("unsupported signature algorithm %s", cert.SignatureAlgorithm)
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < minRSAKeyBits {
+			return errors.Errorf("RSA key of %d bits is weaker than the required %d bits", bits, minRSAKeyBits)
+		}
+	case *ecdsa.PublicKey:
+		if bits := key.Params().BitSize; bits < 256 {
+			return errors.Errorf("ECDSA key of %d bits is weaker than the required 256 bits", bits)
+		}
+	default:
+		return errors.Errorf("unsupported public key algorithm %s", cert.PublicKeyAlgorithm)
+	}
+
+	return nil
+}
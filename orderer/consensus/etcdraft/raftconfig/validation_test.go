@@ -0,0 +1,60 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package raftconfig_test
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOptions(t *testing.T) {
+	valid := func() *etcdraft.Options {
+		return &etcdraft.Options{
+			TickInterval:    "500ms",
+			ElectionTick:    10,
+			HeartbeatTick:   1,
+			MaxInflightMsgs: 5,
+			MaxSizePerMsg:   1024 * 1024,
+		}
+	}
+	assert.NoError(t, raftconfig.ValidateOptions(valid()))
+
+	for _, testCase := range []struct {
+		name     string
+		mutate   func(*etcdraft.Options)
+		expected string
+	}{
+		{"bad tick interval", func(o *etcdraft.Options) { o.TickInterval = "0s" }, "tick interval 0s is not positive"},
+		{"election tick", func(o *etcdraft.Options) { o.ElectionTick = 1 }, "election tick must be greater than heartbeat tick"},
+		{"max inflight", func(o *etcdraft.Options) { o.MaxInflightMsgs = 0 }, "max inflight messages is not set"},
+		{"election options", func(o *etcdraft.Options) { o.DisablePreVote, o.DisableCheckQuorum = true, true },
+			"pre-vote and check quorum cannot be both disabled"},
+		{"TLS version", func(o *etcdraft.Options) { o.TlsMinVersion = "1.1" },
+			"invalid TLS policy: unsupported TLS version: 1.1, expected 1.2 or 1.3"},
+		{"cipher suites", func(o *etcdraft.Options) { o.TlsCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} },
+			"invalid TLS policy: unknown or insecure TLS cipher suite: TLS_RSA_WITH_RC4_128_SHA"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			options := valid()
+			testCase.mutate(options)
+			assert.EqualError(t, raftconfig.ValidateOptions(options), testCase.expected)
+		})
+	}
+}
+
+func TestValidateConfigMetadata(t *testing.T) {
+	assert.EqualError(t, raftconfig.ValidateConfigMetadata(nil, true), "nil metadata")
+
+	md := &etcdraft.ConfigMetadata{Options: &etcdraft.Options{TickInterval: "500ms"}}
+	assert.EqualError(t, raftconfig.ValidateConfigMetadata(md, true), "invalid options: heartbeat tick is not set")
+
+	// Chains only validate the election and TLS options of config updates
+	assert.Empty(t, raftconfig.MetadataValidation{ExpectHosts: true}.Errors(md))
+}
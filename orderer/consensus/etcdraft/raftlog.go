@@ -0,0 +1,159 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// raftLogLevelUnset is the raftLogLevel of chains whose etcd/raft
+// logger follows the logging spec of the orderer.
+const raftLogLevelUnset = int32(-128)
+
+// raftLogLevel is the level of the etcd/raft logger of a chain, which is set
+// independently of the chain logger, so that raft internals can be traced on
+// one channel without the debug output of the chain, or of other channels.
+type raftLogLevel struct {
+	level int32 // accessed atomically, raftLogLevelUnset unless set
+}
+
+func newRaftLogLevel() *raftLogLevel {
+	return &raftLogLevel{level: raftLogLevelUnset}
+}
+
+func (l *raftLogLevel) get() (zapcore.Level, bool) {
+	level := atomic.LoadInt32(&l.level)
+	return zapcore.Level(level), level != raftLogLevelUnset
+}
+
+func (l *raftLogLevel) set(level zapcore.Level) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+func (l *raftLogLevel) unset() {
+	atomic.StoreInt32(&l.level, raftLogLevelUnset)
+}
+
+// newRaftLogger returns the logger of the etcd/raft node of a chain, named raft
+// after the chain logger, which logs at the given level if it is set, and as
+// the logging spec of the orderer specifies for its name otherwise.
+func newRaftLogger(lg *flogging.FabricLogger, level *raftLogLevel) *flogging.FabricLogger {
+	return lg.Named("raft").WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &raftLogCore{Core: core, level: level}
+	}))
+}
+
+// raftLogCore overrides the levels of the core
+// it wraps with the raft log level, if it is set.
+type raftLogCore struct {
+	zapcore.Core
+	level *raftLogLevel
+}
+
+func (c *raftLogCore) Enabled(lvl zapcore.Level) bool {
+	if level, set := c.level.get(); set {
+		return level.Enabled(lvl)
+	}
+	return c.Core.Enabled(lvl)
+}
+
+func (c *raftLogCore) With(fields []zapcore.Field) zapcore.Core {
+	return &raftLogCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *raftLogCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	level, set := c.level.get()
+	if !set {
+		return c.Core.Check(e, ce)
+	}
+	if level.Enabled(e.Level) {
+		return ce.AddCore(e, c)
+	}
+	return ce
+}
+
+// SetRaftLogLevel sets the level of the etcd/raft logger of the chain, such as
+// debug, regardless of the level of the chain logger. The raft logger follows
+// the logging spec of the orderer again, as the logger named raft after the
+// chain logger, once its level is set to the empty string.
+func (c *Chain) SetRaftLogLevel(level string) error {
+	if level == "" {
+		c.raftLogLevel.unset()
+		c.logger.Infof("Raft logger follows the logging spec")
+		return nil
+	}
+	if !flogging.IsValidLevel(level) {
+		return errors.Errorf("invalid log level: %s", level)
+	}
+	c.raftLogLevel.set(flogging.NameToLevel(level))
+	c.logger.Infof("Raft logger level is set to %s", level)
+	return nil
+}
+
+// RaftLogLevel returns the level of the etcd/raft logger of the chain,
+// or the empty string if it follows the logging spec of the orderer.
+func (c *Chain) RaftLogLevel() string {
+	level, set := c.raftLogLevel.get()
+	if !set {
+		return ""
+	}
+	return level.String()
+}
+
+// raftLogHandler sets the level of the etcd/raft
+// logger of the channel given in the query.
+type raftLogHandler struct {
+	consenter *Consenter
+}
+
+// RaftLogHandler returns a handler serving the level of the etcd/raft logger of
+// a channel for GET requests of the form ?channel=<channel ID>, and setting it for
+// POST requests of the form ?channel=<channel ID>&level=<level>. The raft logger
+// follows the logging spec of the orderer if the level is empty.
+func (c *Consenter) RaftLogHandler() http.Handler {
+	return &raftLogHandler{consenter: c}
+}
+
+func (h *raftLogHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	query := req.URL.Query()
+	channelID := query.Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	if req.Method == http.MethodPost {
+		if err := chain.SetRaftLogLevel(query.Get("level")); err != nil {
+			sendJSONError(resp, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(map[string]string{"level": chain.RaftLogLevel()}); err != nil {
+		h.consenter.Logger.Errorw("failed to encode raft log level", "channel", channelID, "error", err)
+	}
+}
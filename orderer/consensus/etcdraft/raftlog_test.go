@@ -0,0 +1,104 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRaftLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logging, err := flogging.New(flogging.Config{LogSpec: "info", Writer: buf})
+	require.NoError(t, err)
+	lg := logging.Logger("orderer.consensus.etcdraft.mychannel")
+	chain := &Chain{logger: lg, raftLogLevel: newRaftLogLevel()}
+	raftLogger := newRaftLogger(lg, chain.raftLogLevel)
+
+	// the raft logger follows the logging spec unless its level is set
+	raftLogger.Debugf("raft debug 1")
+	assert.NotContains(t, buf.String(), "raft debug 1")
+	assert.Equal(t, "", chain.RaftLogLevel())
+	require.NoError(t, logging.ActivateSpec("orderer.consensus.etcdraft.mychannel.raft=debug:info"))
+	raftLogger.Debugf("raft debug 2")
+	assert.Contains(t, buf.String(), "raft debug 2")
+	require.NoError(t, logging.ActivateSpec("info"))
+
+	// the level of the raft logger is independent of the chain logger
+	require.NoError(t, chain.SetRaftLogLevel("debug"))
+	assert.Equal(t, "debug", chain.RaftLogLevel())
+	assert.True(t, raftLogger.IsEnabledFor(zapcore.DebugLevel))
+	raftLogger.With("term", 2).Debugf("raft debug 3")
+	lg.Debugf("chain debug")
+	assert.Contains(t, buf.String(), "raft debug 3")
+	assert.Contains(t, buf.String(), "orderer.consensus.etcdraft.mychannel.raft")
+	assert.NotContains(t, buf.String(), "chain debug")
+
+	require.NoError(t, logging.ActivateSpec("debug"))
+	require.NoError(t, chain.SetRaftLogLevel("warn"))
+	raftLogger.Infof("raft info")
+	lg.Debugf("chain debug")
+	assert.NotContains(t, buf.String(), "raft info")
+	assert.Contains(t, buf.String(), "chain debug")
+
+	require.NoError(t, chain.SetRaftLogLevel(""))
+	raftLogger.Infof("raft info")
+	assert.Contains(t, buf.String(), "raft info")
+
+	assert.EqualError(t, chain.SetRaftLogLevel("chatty"), "invalid log level: chatty")
+}
+
+func TestRaftLogHandler(t *testing.T) {
+	chains := chainsByID{
+		"mychannel": &multichannel.ChainSupport{Chain: &Chain{logger: flogging.MustGetLogger("test"), raftLogLevel: newRaftLogLevel()}},
+	}
+	handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).RaftLogHandler()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(method, target, nil))
+		return resp
+	}
+	levelOf := func(resp *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return body["level"]
+	}
+
+	resp := serve(http.MethodPost, "/etcdraft/raftlog?channel=mychannel&level=debug")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "debug", levelOf(resp))
+	resp = serve(http.MethodGet, "/etcdraft/raftlog?channel=mychannel")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "debug", levelOf(resp))
+	resp = serve(http.MethodPost, "/etcdraft/raftlog?channel=mychannel")
+	require.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "", levelOf(resp))
+
+	for _, testCase := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{method: http.MethodPut, target: "/etcdraft/raftlog?channel=mychannel", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, target: "/etcdraft/raftlog", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/etcdraft/raftlog?channel=nochannel", code: http.StatusNotFound},
+		{method: http.MethodPost, target: "/etcdraft/raftlog?channel=mychannel&level=chatty", code: http.StatusBadRequest},
+	} {
+		resp := serve(testCase.method, testCase.target)
+		assert.Equal(t, testCase.code, resp.Code, "%s %s: %s", testCase.method, testCase.target, resp.Body.String())
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// receiptBufferSize is the number of receipts buffered for a subscriber,
+// beyond which the subscription is closed with ErrReceiptsOverflow.
+const receiptBufferSize = 1000
+
+var (
+	// ErrReceiptsDisabled is returned when subscribing to the
+	// receipts of a chain which does not stream receipts.
+	ErrReceiptsDisabled = errors.New("receipt stream is disabled")
+	// ErrReceiptsOverflow is the error of a subscription which
+	// was closed because it fell behind the receipts of the chain.
+	ErrReceiptsOverflow = errors.New("subscriber fell behind the receipt stream")
+	// ErrReceiptsHalted is the error of a subscription which
+	// was closed because the chain halted.
+	ErrReceiptsHalted = errors.New("chain halted")
+)
+
+// ReceiptSubscription receives the receipts of the
+// transactions it selects, as their blocks are written.
+type ReceiptSubscription struct {
+	stream   *receiptStream
+	txIDs    map[string]struct{} // nil selects all transactions
+	receipts chan *orderer.TxReceipt
+	err      error
+}
+
+// Receipts returns the channel the receipts are sent to. It is closed
+// when the subscription is cancelled or closed, after which Err returns
+// the reason the subscription was closed for, if any.
+func (s *ReceiptSubscription) Receipts() <-chan *orderer.TxReceipt {
+	return s.receipts
+}
+
+// Err returns the error the subscription was closed with,
+// or nil if it is open or was cancelled.
+func (s *ReceiptSubscription) Err() error {
+	s.stream.lock.Lock()
+	defer s.stream.lock.Unlock()
+	return s.err
+}
+
+// Cancel cancels the subscription.
+func (s *ReceiptSubscription) Cancel() {
+	s.stream.lock.Lock()
+	defer s.stream.lock.Unlock()
+	s.stream.remove(s, nil)
+}
+
+func (s *ReceiptSubscription) selects(txID string) bool {
+	if s.txIDs == nil {
+		return true
+	}
+	_, selected := s.txIDs[txID]
+	return selected
+}
+
+// receiptStream publishes the receipts of the transactions
+// of the blocks written by a chain to its subscriptions.
+type receiptStream struct {
+	logger *flogging.FabricLogger
+
+	lock          sync.Mutex
+	subscriptions map[*ReceiptSubscription]struct{}
+	closed        error // set once the stream is closed
+}
+
+func newReceiptStream(logger *flogging.FabricLogger) *receiptStream {
+	return &receiptStream{
+		logger:        logger,
+		subscriptions: make(map[*ReceiptSubscription]struct{}),
+	}
+}
+
+func (rs *receiptStream) subscribe(txIDs []string) (*ReceiptSubscription, error) {
+	s := &ReceiptSubscription{
+		stream:   rs,
+		receipts: make(chan *orderer.TxReceipt, receiptBufferSize),
+	}
+	if len(txIDs) > 0 {
+		s.txIDs = make(map[string]struct{}, len(txIDs))
+		for _, txID := range txIDs {
+			s.txIDs[txID] = struct{}{}
+		}
+	}
+
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	if rs.closed != nil {
+		return nil, rs.closed
+	}
+	rs.subscriptions[s] = struct{}{}
+	return s, nil
+}
+
+// remove closes the subscription with the given error. It is called with the lock held.
+func (rs *receiptStream) remove(s *ReceiptSubscription, err error) {
+	if _, exists := rs.subscriptions[s]; !exists {
+		return
+	}
+	delete(rs.subscriptions, s)
+	s.err = err
+	close(s.receipts)
+}
+
+// publish sends the receipts of the transactions of the block to the subscriptions
+// selecting them. It never blocks, subscriptions which fall behind are closed instead.
+func (rs *receiptStream) publish(block *common.Block) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	if len(rs.subscriptions) == 0 {
+		return
+	}
+
+	for i := range block.Data.Data {
+		env, err := utils.ExtractEnvelope(block, i)
+		if err != nil {
+			rs.logger.Warnf("Failed to extract envelope %d of block %d for receipts: %s", i, block.Header.Number, err)
+			continue
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil {
+			rs.logger.Warnf("Failed to extract channel header of envelope %d of block %d for receipts: %s", i, block.Header.Number, err)
+			continue
+		}
+
+		receipt := &orderer.TxReceipt{TxId: chdr.TxId, BlockNumber: block.Header.Number, Position: uint32(i)}
+		for s := range rs.subscriptions {
+			if !s.selects(chdr.TxId) {
+				continue
+			}
+			select {
+			case s.receipts <- receipt:
+			default:
+				rs.logger.Warnf("Closing receipt subscription which fell behind at block %d", block.Header.Number)
+				rs.remove(s, ErrReceiptsOverflow)
+			}
+		}
+	}
+}
+
+// close closes all subscriptions with the given error,
+// which is returned by further subscriptions.
+func (rs *receiptStream) close(err error) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.closed = err
+	for s := range rs.subscriptions {
+		rs.remove(s, err)
+	}
+}
+
+// SubscribeReceipts subscribes to the receipts of the transactions with
+// the given IDs, or of all transactions if none are given, as their blocks
+// are written by the chain. The subscription must be cancelled once done.
+func (c *Chain) SubscribeReceipts(txIDs []string) (*ReceiptSubscription, error) {
+	if c.receipts == nil {
+		return nil, ErrReceiptsDisabled
+	}
+	return c.receipts.subscribe(txIDs)
+}
+
+func (c *Chain) publishReceipts(block *common.Block) {
+	if c.receipts != nil {
+		c.receipts.publish(block)
+	}
+}
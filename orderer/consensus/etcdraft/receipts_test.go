@@ -0,0 +1,100 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func receiptsBlock(number uint64, txIDs ...string) *common.Block {
+	block := common.NewBlock(number, nil)
+	for _, txID := range txIDs {
+		chdr := utils.MakeChannelHeader(common.HeaderType_ENDORSER_TRANSACTION, 0, "foo", 0)
+		chdr.TxId = txID
+		payload := &common.Payload{Header: utils.MakePayloadHeader(chdr, &common.SignatureHeader{})}
+		env := &common.Envelope{Payload: utils.MarshalOrPanic(payload)}
+		block.Data.Data = append(block.Data.Data, utils.MarshalOrPanic(env))
+	}
+	return block
+}
+
+func TestReceiptStream(t *testing.T) {
+	rs := newReceiptStream(flogging.MustGetLogger("test"))
+
+	all, err := rs.subscribe(nil)
+	require.NoError(t, err)
+	selected, err := rs.subscribe([]string{"b", "d"})
+	require.NoError(t, err)
+
+	rs.publish(receiptsBlock(5, "a", "b", "c"))
+	rs.publish(receiptsBlock(6, "d"))
+
+	for _, expected := range []*orderer.TxReceipt{
+		{TxId: "a", BlockNumber: 5, Position: 0},
+		{TxId: "b", BlockNumber: 5, Position: 1},
+		{TxId: "c", BlockNumber: 5, Position: 2},
+		{TxId: "d", BlockNumber: 6, Position: 0},
+	} {
+		assert.Equal(t, expected, <-all.Receipts())
+	}
+	assert.Equal(t, &orderer.TxReceipt{TxId: "b", BlockNumber: 5, Position: 1}, <-selected.Receipts())
+	assert.Equal(t, &orderer.TxReceipt{TxId: "d", BlockNumber: 6, Position: 0}, <-selected.Receipts())
+
+	// a cancelled subscription is closed without an error
+	selected.Cancel()
+	_, open := <-selected.Receipts()
+	assert.False(t, open)
+	assert.NoError(t, selected.Err())
+	selected.Cancel()
+
+	// closing the stream closes the remaining subscriptions and refuses new ones
+	rs.close(ErrReceiptsHalted)
+	_, open = <-all.Receipts()
+	assert.False(t, open)
+	assert.Equal(t, ErrReceiptsHalted, all.Err())
+	_, err = rs.subscribe(nil)
+	assert.Equal(t, ErrReceiptsHalted, err)
+}
+
+func TestReceiptStreamOverflow(t *testing.T) {
+	rs := newReceiptStream(flogging.MustGetLogger("test"))
+
+	slow, err := rs.subscribe(nil)
+	require.NoError(t, err)
+	idle, err := rs.subscribe([]string{"never"})
+	require.NoError(t, err)
+
+	for i := 0; i <= receiptBufferSize; i++ {
+		rs.publish(receiptsBlock(uint64(i), "tx"))
+	}
+
+	// the buffered receipts are still delivered before the subscription is closed
+	for i := 0; i < receiptBufferSize; i++ {
+		receipt, open := <-slow.Receipts()
+		require.True(t, open)
+		assert.Equal(t, uint64(i), receipt.BlockNumber)
+	}
+	_, open := <-slow.Receipts()
+	assert.False(t, open)
+	assert.Equal(t, ErrReceiptsOverflow, slow.Err())
+
+	// subscriptions which do not select the transactions are unaffected
+	assert.NoError(t, idle.Err())
+	assert.Len(t, rs.subscriptions, 1)
+}
+
+func TestSubscribeReceiptsDisabled(t *testing.T) {
+	_, err := (&Chain{}).SubscribeReceipts(nil)
+	assert.Equal(t, ErrReceiptsDisabled, err)
+}
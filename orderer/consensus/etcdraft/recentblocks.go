@@ -0,0 +1,68 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// recentBlocks caches the blocks a chain most recently committed to the
+// ledger, along with their signatures, so that deliver clients following
+// the tip of the chain, and nodes replicating it, are served without
+// reading, and unmarshaling, the blocks from the ledger.
+type recentBlocks struct {
+	lock     sync.RWMutex
+	blocks   []*common.Block // ring buffer of the last len(blocks) blocks
+	first    uint64          // number of the oldest cached block
+	count    int             // number of cached blocks
+	changedC chan struct{}   // closed and replaced whenever a block is cached
+}
+
+func newRecentBlocks(size int) *recentBlocks {
+	return &recentBlocks{
+		blocks:   make([]*common.Block, size),
+		changedC: make(chan struct{}),
+	}
+}
+
+// put caches the given committed block, evicting the oldest cached block
+// if the cache is full. Blocks are committed in order, yet the cache starts
+// over from the given block if it does not succeed the last cached block.
+func (rb *recentBlocks) put(block *common.Block) {
+	rb.lock.Lock()
+	defer rb.lock.Unlock()
+
+	number := block.Header.Number
+	if rb.count > 0 && number != rb.first+uint64(rb.count) {
+		rb.count = 0
+	}
+	if rb.count == 0 {
+		rb.first = number
+	}
+	rb.blocks[number%uint64(len(rb.blocks))] = block
+	if rb.count < len(rb.blocks) {
+		rb.count++
+	} else {
+		rb.first++
+	}
+	close(rb.changedC)
+	rb.changedC = make(chan struct{})
+}
+
+// get returns the cached block with the given number, or nil if it is not
+// cached, along with the channel closed once the next block is cached.
+func (rb *recentBlocks) get(number uint64) (*common.Block, <-chan struct{}) {
+	rb.lock.RLock()
+	defer rb.lock.RUnlock()
+
+	if rb.count == 0 || number < rb.first || number >= rb.first+uint64(rb.count) {
+		return nil, rb.changedC
+	}
+	return rb.blocks[number%uint64(len(rb.blocks))], rb.changedC
+}
@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecentBlocks(t *testing.T) {
+	rb := newRecentBlocks(3)
+	block, changedC := rb.get(0)
+	assert.Nil(t, block)
+	assert.NotNil(t, changedC)
+
+	for number := uint64(5); number <= 8; number++ {
+		rb.put(&common.Block{Header: &common.BlockHeader{Number: number}})
+		assert.True(t, isClosed(changedC))
+		_, changedC = rb.get(number)
+	}
+
+	// only the last 3 blocks are cached
+	for number, cached := range map[uint64]bool{4: false, 5: false, 6: true, 7: true, 8: true, 9: false} {
+		block, _ := rb.get(number)
+		if !cached {
+			assert.Nil(t, block, "block %d", number)
+			continue
+		}
+		assert.Equal(t, number, block.Header.Number)
+	}
+	assert.False(t, isClosed(changedC))
+
+	// a block which does not succeed the last cached block starts the cache over
+	rb.put(&common.Block{Header: &common.BlockHeader{Number: 20}})
+	block, _ = rb.get(8)
+	assert.Nil(t, block)
+	block, _ = rb.get(20)
+	assert.Equal(t, uint64(20), block.Header.Number)
+}
+
+func TestChainRecentBlock(t *testing.T) {
+	c := &Chain{}
+	block, cachedC := c.RecentBlock(0)
+	assert.Nil(t, block)
+	assert.Nil(t, cachedC)
+
+	c.recentBlocks = newRecentBlocks(1)
+	c.recentBlocks.put(&common.Block{Header: &common.BlockHeader{Number: 0}})
+	block, cachedC = c.RecentBlock(0)
+	assert.Equal(t, uint64(0), block.Header.Number)
+	assert.NotNil(t, cachedC)
+}
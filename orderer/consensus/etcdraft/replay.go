@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"math"
+	"sync/atomic"
+
+	"github.com/hyperledger/fabric/protos/utils"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// ReplayProgress describes how far the chain got writing the blocks which were
+// committed to the WAL but not to the ledger when it was created, which a node
+// replays upon restart before it catches up with the other consenters. Readiness
+// probes may hold clients off the node until the replay is done.
+type ReplayProgress struct {
+	Done bool `json:"done"`
+	// Applied is the raft index of the last entry written to the ledger.
+	Applied uint64 `json:"applied"`
+	// Target is the raft index the WAL was committed up to when the chain was created.
+	Target uint64 `json:"target"`
+}
+
+// ReplayProgress returns how far the chain got replaying the entries committed to its WAL.
+func (c *Chain) ReplayProgress() ReplayProgress {
+	applied := atomic.LoadUint64(&c.writtenIndex)
+	return ReplayProgress{
+		Done:    applied >= c.replayTarget,
+		Applied: applied,
+		Target:  c.replayTarget,
+	}
+}
+
+// replayWAL writes the blocks committed to the WAL but not to the ledger, in batches
+// of at most MaxReplayEntries entries, rather than waiting for the raft node to hand
+// them over, which it does all at once. It is called before the raft node starts, so
+// that the node neither appends to the entries being read nor waits on serveRequest
+// to take the entries it hands over, and hence only runs once they are written.
+// The replay stops at the first entry which is not a normal block, i.e. a config
+// block, a ConfChange or a marker, which is left to the raft node along with the
+// entries after it, since applying those alters the chain beyond its ledger.
+// The entries replayed are applied again by the raft node, which skips the blocks.
+func (c *Chain) replayWAL() {
+	batchSize := c.opts.MaxReplayEntries
+	if batchSize == 0 || c.appliedIndex >= c.replayTarget {
+		return
+	}
+
+	c.logger.Infof("Replaying raft entries %d to %d committed to the WAL, %d at a time", c.appliedIndex+1, c.replayTarget, batchSize)
+	start, height := c.clock.Now(), c.lastBlock.Header.Number
+
+	for c.appliedIndex < c.replayTarget {
+		hi := c.replayTarget + 1
+		if hi-c.appliedIndex > batchSize {
+			hi = c.appliedIndex + 1 + batchSize
+		}
+		ents, err := c.Node.storage.ram.Entries(c.appliedIndex+1, hi, math.MaxUint64)
+		if err != nil {
+			c.logger.Infof("Leaving raft entries %d to %d to the raft node, as they cannot be read: %s", c.appliedIndex+1, c.replayTarget, err)
+			return
+		}
+
+		replayable := replayableEntries(ents)
+		c.apply(ents[:replayable])
+		if replayable < len(ents) {
+			c.logger.Infof("Leaving raft entries %d to %d to the raft node, from the config block, ConfChange or marker at raft index %d",
+				ents[replayable].Index, c.replayTarget, ents[replayable].Index)
+			break
+		}
+
+		c.logger.Infof("Replayed raft entries up to %d of %d, wrote blocks up to %d", c.appliedIndex, c.replayTarget, c.lastBlock.Header.Number)
+	}
+
+	c.logger.Infof("Replayed the WAL up to raft index %d in %s, wrote %d blocks",
+		c.appliedIndex, c.clock.Since(start), c.lastBlock.Header.Number-height)
+}
+
+// replayableEntries returns the number of leading entries which are empty or carry
+// a normal block, hence are written to the ledger without further side effects.
+func replayableEntries(ents []raftpb.Entry) int {
+	for i := range ents {
+		if ents[i].Type != raftpb.EntryNormal {
+			return i
+		}
+		if len(ents[i].Data) == 0 {
+			continue
+		}
+		if entryMarker(ents[i].Data) != nil {
+			return i
+		}
+		if utils.IsConfigBlock(utils.UnmarshalBlockOrPanic(ents[i].Data)) {
+			return i
+		}
+	}
+	return len(ents)
+}
@@ -0,0 +1,46 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestReplayableEntries(t *testing.T) {
+	block := common.NewBlock(1, nil)
+	block.Data.Data = [][]byte{{1, 2, 3}}
+
+	configEnv, err := utils.CreateSignedEnvelope(common.HeaderType_CONFIG, "test", nil, &common.ConfigEnvelope{}, 0, 0)
+	assert.NoError(t, err)
+	configBlock := common.NewBlock(2, nil)
+	configBlock.Data.Data = [][]byte{utils.MarshalOrPanic(configEnv)}
+
+	normal := raftpb.Entry{Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(block)}
+	empty := raftpb.Entry{Type: raftpb.EntryNormal}
+
+	for _, test := range []struct {
+		name     string
+		ents     []raftpb.Entry
+		expected int
+	}{
+		{"blocks", []raftpb.Entry{normal, empty, normal}, 3},
+		{"config block", []raftpb.Entry{normal, {Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(configBlock)}, normal}, 1},
+		{"ConfChange", []raftpb.Entry{empty, {Type: raftpb.EntryConfChange}, normal}, 1},
+		{"marker", []raftpb.Entry{{Type: raftpb.EntryNormal, Data: utils.MarshalOrPanic(&etcdraft.Marker{Type: etcdraft.Marker_PAUSE, Proposer: 1})}}, 0},
+		{"none", nil, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, replayableEntries(test.ents))
+		})
+	}
+}
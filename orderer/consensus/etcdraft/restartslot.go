@@ -0,0 +1,270 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// defaultRestartSlotTimeout is the time a restart slot requested through
+// the operations server without a timeout is waited for.
+const defaultRestartSlotTimeout = 10 * time.Second
+
+// RestartSlot describes the consenter which holds the restart slot of a channel.
+type RestartSlot struct {
+	Node   uint64 `json:"node"`
+	Reason string `json:"reason,omitempty"`
+	// Since is the time this node applied the grant of the slot.
+	Since time.Time `json:"since"`
+}
+
+// restartSlot is the restart slot of a channel, which lets automated rollouts
+// restart one consenter at a time, so that they never take down two consenters
+// at once and lose quorum. The slot is granted by the leader and replicated by
+// markers, hence every node agrees on its holder at every position of the raft
+// log. Markers are not replayed from before the last block upon restart, so the
+// holder is only known to the nodes which applied the grant since they started.
+type restartSlot struct {
+	lock     sync.Mutex
+	holder   RestartSlot   // Node is zero while the slot is free
+	pending  uint64        // consenter the leader proposed a grant to, until applied
+	changedC chan struct{} // closed and replaced whenever the holder changes
+}
+
+func newRestartSlot() *restartSlot {
+	return &restartSlot{changedC: make(chan struct{})}
+}
+
+// current returns the holder of the slot, if any, and
+// the channel closed once the holder changes.
+func (rs *restartSlot) current() (*RestartSlot, <-chan struct{}) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	if rs.holder.Node == 0 {
+		return nil, rs.changedC
+	}
+	holder := rs.holder
+	return &holder, rs.changedC
+}
+
+// admit decides whether the leader proposes the given restart slot marker,
+// given whether the consenter it is about can go down without losing quorum.
+// Markers which would not change the holder are not proposed.
+func (rs *restartSlot) admit(marker *etcdraft.Marker, quorumWithout bool) (bool, error) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	holder := rs.holder.Node
+	if rs.pending != 0 {
+		holder = rs.pending
+	}
+
+	switch marker.Type {
+	case etcdraft.Marker_RESTART_SLOT_ACQUIRE:
+		if holder == marker.Node {
+			return false, nil
+		}
+		if holder != 0 {
+			return false, errors.Errorf("restart slot is held by node %d", holder)
+		}
+		if !quorumWithout {
+			return false, errors.Errorf("node %d cannot restart without losing quorum, as other consenters are unreachable", marker.Node)
+		}
+		rs.pending = marker.Node
+		return true, nil
+	case etcdraft.Marker_RESTART_SLOT_RELEASE:
+		if holder == 0 {
+			return false, nil
+		}
+		if holder != marker.Node {
+			return false, errors.Errorf("restart slot is held by node %d", holder)
+		}
+		return true, nil
+	default:
+		return false, errors.Errorf("marker %s is not a restart slot marker", marker.Type)
+	}
+}
+
+// apply records the holder of the slot, as of the given applied marker.
+func (rs *restartSlot) apply(marker *etcdraft.Marker, now time.Time) {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+
+	if rs.pending == marker.Node {
+		rs.pending = 0
+	}
+
+	switch marker.Type {
+	case etcdraft.Marker_RESTART_SLOT_ACQUIRE:
+		if rs.holder.Node == marker.Node {
+			return
+		}
+		rs.holder = RestartSlot{Node: marker.Node, Reason: marker.Reason, Since: now}
+	case etcdraft.Marker_RESTART_SLOT_RELEASE:
+		if rs.holder.Node != marker.Node {
+			return
+		}
+		rs.holder = RestartSlot{}
+	default:
+		return
+	}
+	close(rs.changedC)
+	rs.changedC = make(chan struct{})
+}
+
+// resetPending forgets the grant proposed by this node, which is
+// lost, or committed by the time the next grant can be proposed,
+// once this node steps down or is elected.
+func (rs *restartSlot) resetPending() {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	rs.pending = 0
+}
+
+// AcquireRestartSlot asks for the restart slot of the channel to be granted to
+// the given consenter, and waits until this node applies the grant or the given
+// timeout expires. It must be called on the leader, which grants the slot to at
+// most one consenter at a time, and only if the consenter can go down without
+// losing quorum. Acquiring the slot held by the consenter already succeeds.
+func (c *Chain) AcquireRestartSlot(node uint64, reason string, timeout time.Duration) error {
+	timer := c.clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	if err := c.submitMarker(&etcdraft.Marker{Type: etcdraft.Marker_RESTART_SLOT_ACQUIRE, Proposer: c.raftID, Node: node, Reason: reason}); err != nil {
+		return err
+	}
+
+	for {
+		holder, changedC := c.restartSlot.current()
+		if holder != nil && holder.Node == node {
+			return nil
+		}
+		select {
+		case <-changedC:
+		case <-timer.C():
+			return errors.Errorf("restart slot was not granted to node %d within %s", node, timeout)
+		case <-c.doneC:
+			return errors.Errorf("chain is stopped")
+		}
+	}
+}
+
+// ReleaseRestartSlot frees the restart slot of the channel held by the given
+// consenter, once it restarted. It must be called on the leader, and returns
+// once the release is proposed. Releasing a free slot succeeds.
+func (c *Chain) ReleaseRestartSlot(node uint64, reason string) error {
+	return c.submitMarker(&etcdraft.Marker{Type: etcdraft.Marker_RESTART_SLOT_RELEASE, Proposer: c.raftID, Node: node, Reason: reason})
+}
+
+// RestartSlot returns the consenter holding the restart slot of the channel,
+// as known to this node, or nil if the slot is free.
+func (c *Chain) RestartSlot() *RestartSlot {
+	holder, _ := c.restartSlot.current()
+	return holder
+}
+
+// admitRestartSlot decides whether the leader proposes the given restart slot marker.
+func (c *Chain) admitRestartSlot(marker *etcdraft.Marker) (bool, error) {
+	if c.FeatureVersion(FeatureRestartSlots) == 0 {
+		return false, errors.Errorf("restart slots are not supported by every consenter of the channel")
+	}
+	if _, exists := c.raftMetadata().Consenters[marker.Node]; !exists {
+		return false, errors.Errorf("node %d is not a consenter of the channel", marker.Node)
+	}
+	return c.restartSlot.admit(marker, c.quorumWithout(marker.Node))
+}
+
+// quorumWithout returns whether the consenters reachable by this node form a
+// quorum without the given consenter. Only the leader knows which are reachable.
+func (c *Chain) quorumWithout(node uint64) bool {
+	consenters := c.raftMetadata().Consenters
+	reachable := 0
+	for id := range consenters {
+		if id != node && (id == c.raftID || !c.Node.isUnreachable(id)) {
+			reachable++
+		}
+	}
+	return reachable >= len(consenters)/2+1
+}
+
+// restartSlotHandler grants and releases the restart
+// slot of the channel given in the query.
+type restartSlotHandler struct {
+	consenter *Consenter
+}
+
+// RestartSlotHandler returns a handler serving the holder of the restart slot of
+// the channel for GET requests of the form ?channel=<channel ID>, and acquiring or
+// releasing it for a consenter for POST requests of the form ?channel=<channel ID>
+// &node=<raft ID>&action=<acquire|release>&reason=<reason>&timeout=<duration>,
+// which must be sent to the leader. A request to acquire a slot held by another
+// consenter is answered with 409 Conflict, and may be retried once it is released.
+func (c *Consenter) RestartSlotHandler() http.Handler {
+	return &restartSlotHandler{consenter: c}
+}
+
+func (h *restartSlotHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	query := req.URL.Query()
+	channelID := query.Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	if req.Method == http.MethodPost {
+		node, err := strconv.ParseUint(query.Get("node"), 10, 64)
+		if err != nil || node == 0 {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid node: %q", query.Get("node")))
+			return
+		}
+		timeout := defaultRestartSlotTimeout
+		if t := query.Get("timeout"); t != "" {
+			if timeout, err = time.ParseDuration(t); err != nil || timeout <= 0 {
+				sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid timeout: %q", t))
+				return
+			}
+		}
+
+		switch action := query.Get("action"); action {
+		case "acquire":
+			err = chain.AcquireRestartSlot(node, query.Get("reason"), timeout)
+		case "release":
+			err = chain.ReleaseRestartSlot(node, query.Get("reason"))
+		default:
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid action: %q", action))
+			return
+		}
+		if err != nil {
+			sendJSONError(resp, http.StatusConflict, err.Error())
+			return
+		}
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(map[string]*RestartSlot{"holder": chain.RestartSlot()}); err != nil {
+		h.consenter.Logger.Errorw("failed to encode restart slot", "channel", channelID, "error", err)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestartSlot(t *testing.T) {
+	acquire := func(node uint64) *etcdraft.Marker {
+		return &etcdraft.Marker{Type: etcdraft.Marker_RESTART_SLOT_ACQUIRE, Proposer: 1, Node: node, Reason: "upgrade"}
+	}
+	release := func(node uint64) *etcdraft.Marker {
+		return &etcdraft.Marker{Type: etcdraft.Marker_RESTART_SLOT_RELEASE, Proposer: 1, Node: node}
+	}
+	now := time.Now()
+
+	rs := newRestartSlot()
+	holder, changedC := rs.current()
+	assert.Nil(t, holder)
+
+	_, err := rs.admit(acquire(2), false)
+	assert.EqualError(t, err, "node 2 cannot restart without losing quorum, as other consenters are unreachable")
+	propose, err := rs.admit(release(2), true)
+	assert.NoError(t, err)
+	assert.False(t, propose)
+	_, err = rs.admit(&etcdraft.Marker{Type: etcdraft.Marker_PAUSE}, true)
+	assert.EqualError(t, err, "marker PAUSE is not a restart slot marker")
+
+	// a grant which is proposed but not applied yet holds the slot
+	propose, err = rs.admit(acquire(2), true)
+	assert.NoError(t, err)
+	assert.True(t, propose)
+	_, err = rs.admit(acquire(3), true)
+	assert.EqualError(t, err, "restart slot is held by node 2")
+	propose, err = rs.admit(acquire(2), true)
+	assert.NoError(t, err)
+	assert.False(t, propose)
+
+	// unless the leader steps down before it is applied
+	rs.resetPending()
+	propose, err = rs.admit(acquire(3), true)
+	assert.NoError(t, err)
+	assert.True(t, propose)
+	rs.resetPending()
+
+	rs.apply(acquire(2), now)
+	assert.True(t, isClosed(changedC))
+	holder, changedC = rs.current()
+	assert.Equal(t, &RestartSlot{Node: 2, Reason: "upgrade", Since: now}, holder)
+	_, err = rs.admit(acquire(3), true)
+	assert.EqualError(t, err, "restart slot is held by node 2")
+	_, err = rs.admit(release(3), true)
+	assert.EqualError(t, err, "restart slot is held by node 2")
+
+	// applying markers again, as upon restart, has the same outcome
+	rs.apply(acquire(2), now.Add(time.Second))
+	rs.apply(release(3), now)
+	assert.False(t, isClosed(changedC))
+	holder, _ = rs.current()
+	assert.Equal(t, now, holder.Since)
+
+	propose, err = rs.admit(release(2), true)
+	assert.NoError(t, err)
+	assert.True(t, propose)
+	rs.apply(release(2), now)
+	assert.True(t, isClosed(changedC))
+	holder, _ = rs.current()
+	assert.Nil(t, holder)
+}
+
+func TestRestartSlotHandler(t *testing.T) {
+	chain := &Chain{restartSlot: newRestartSlot()}
+	chain.restartSlot.apply(&etcdraft.Marker{Type: etcdraft.Marker_RESTART_SLOT_ACQUIRE, Node: 2, Reason: "upgrade"}, time.Unix(0, 0).UTC())
+	chains := chainsByID{
+		"mychannel": &multichannel.ChainSupport{Chain: chain},
+	}
+	handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).RestartSlotHandler()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(method, target, nil))
+		return resp
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/restartslot?channel=mychannel")
+	require.Equal(t, http.StatusOK, resp.Code)
+	var body map[string]*RestartSlot
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, &RestartSlot{Node: 2, Reason: "upgrade", Since: time.Unix(0, 0).UTC()}, body["holder"])
+
+	for _, testCase := range []struct {
+		method string
+		target string
+		code   int
+	}{
+		{method: http.MethodPut, target: "/etcdraft/restartslot?channel=mychannel", code: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, target: "/etcdraft/restartslot", code: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/etcdraft/restartslot?channel=nochannel", code: http.StatusNotFound},
+		{method: http.MethodPost, target: "/etcdraft/restartslot?channel=mychannel&action=acquire", code: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/etcdraft/restartslot?channel=mychannel&action=acquire&node=2&timeout=0s", code: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/etcdraft/restartslot?channel=mychannel&action=restart&node=2", code: http.StatusBadRequest},
+		{method: http.MethodPost, target: "/etcdraft/restartslot?channel=mychannel&action=release&node=2", code: http.StatusConflict},
+	} {
+		resp := serve(testCase.method, testCase.target)
+		assert.Equal(t, testCase.code, resp.Code, "%s %s: %s", testCase.method, testCase.target, resp.Body.String())
+	}
+}
@@ -0,0 +1,233 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SelfTestTxIDPrefix prefixes the transaction ID of the envelopes ordered
+	// by self-tests, so that they can be told apart from actual transactions.
+	SelfTestTxIDPrefix = "etcdraft-selftest-"
+	// SelfTestData is the data of the envelopes ordered by self-tests.
+	SelfTestData = "etcdraft self-test, no-op"
+
+	// defaultSelfTestTimeout is the deadline of a self-test
+	// invoked through the operations server without one.
+	defaultSelfTestTimeout = 10 * time.Second
+)
+
+// SelfTestResult is the outcome of a self-test, which ordered a diagnostic
+// envelope through the chain and waited for this node to commit it.
+type SelfTestResult struct {
+	Channel     string `json:"channel"`
+	TxID        string `json:"tx_id"`
+	BlockNumber uint64 `json:"block_number"`
+	Leader      uint64 `json:"leader"`
+	// Submit is the time taken by the chain to accept the envelope,
+	// including forwarding it to the leader.
+	Submit string `json:"submit"`
+	// Consensus is the time since the envelope was accepted until this node
+	// applied the block carrying it, once cut by the leader and committed by raft.
+	Consensus string `json:"consensus"`
+	// Write is the time taken by this node to write the block to the ledger.
+	Write string `json:"write"`
+	Total string `json:"total"`
+}
+
+// selfTestCommit records when a node applied, and
+// then wrote, the block carrying a self-test envelope.
+type selfTestCommit struct {
+	blockNumber uint64
+	applied     time.Time
+	written     time.Time
+}
+
+// selfTests tracks the self-test envelopes awaiting commit, by transaction ID.
+type selfTests struct {
+	sequence uint64 // accessed atomically
+
+	lock    sync.Mutex
+	pending map[string]chan selfTestCommit
+}
+
+func newSelfTests() *selfTests {
+	return &selfTests{pending: make(map[string]chan selfTestCommit)}
+}
+
+func (st *selfTests) track(txID string) <-chan selfTestCommit {
+	committedC := make(chan selfTestCommit, 1)
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	st.pending[txID] = committedC
+	return committedC
+}
+
+func (st *selfTests) untrack(txID string) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+	delete(st.pending, txID)
+}
+
+// written reports the self-test envelopes of the block, which was applied
+// and written at the given times. Blocks are not inspected unless a
+// self-test is in progress.
+func (st *selfTests) written(block *common.Block, applied, written time.Time) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if len(st.pending) == 0 {
+		return
+	}
+	for i := range block.Data.Data {
+		env, err := utils.ExtractEnvelope(block, i)
+		if err != nil {
+			continue
+		}
+		chdr, err := utils.ChannelHeader(env)
+		if err != nil || !strings.HasPrefix(chdr.TxId, SelfTestTxIDPrefix) {
+			continue
+		}
+		if committedC, exists := st.pending[chdr.TxId]; exists {
+			committedC <- selfTestCommit{blockNumber: block.Header.Number, applied: applied, written: written}
+			delete(st.pending, chdr.TxId)
+		}
+	}
+}
+
+// SelfTest orders a no-op diagnostic envelope, signed by this node, through the
+// chain, and waits until this node commits it or the given timeout expires. It
+// probes the whole path of a transaction, from this node to the leader, through
+// consensus and back into the ledger of this node. The envelope is marked by a
+// transaction ID prefixed with SelfTestTxIDPrefix, and is of type MESSAGE,
+// hence peers mark it as invalid instead of processing it.
+func (c *Chain) SelfTest(timeout time.Duration) (*SelfTestResult, error) {
+	if err := c.isRunning(); err != nil {
+		return nil, err
+	}
+
+	txID := fmt.Sprintf("%s%d-%d-%d", SelfTestTxIDPrefix, c.raftID, c.clock.Now().UnixNano(), atomic.AddUint64(&c.selfTests.sequence, 1))
+	env, err := c.selfTestEnvelope(txID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to create self-test envelope")
+	}
+
+	committedC := c.selfTests.track(txID)
+	defer c.selfTests.untrack(txID)
+
+	timer := c.clock.NewTimer(timeout)
+	defer timer.Stop()
+
+	start := c.clock.Now()
+	if err := c.Order(env, c.support.Sequence()); err != nil {
+		return nil, errors.WithMessage(err, "failed to order self-test envelope")
+	}
+	accepted := c.clock.Now()
+	c.logger.Infof("Ordering self-test envelope %s", txID)
+
+	select {
+	case commit := <-committedC:
+		return &SelfTestResult{
+			Channel:     c.channelID,
+			TxID:        txID,
+			BlockNumber: commit.blockNumber,
+			Leader:      atomic.LoadUint64(&c.lastKnownLeader),
+			Submit:      accepted.Sub(start).String(),
+			Consensus:   commit.applied.Sub(accepted).String(),
+			Write:       commit.written.Sub(commit.applied).String(),
+			Total:       commit.written.Sub(start).String(),
+		}, nil
+	case <-timer.C():
+		c.logger.Warnf("Self-test envelope %s was not committed within %s", txID, timeout)
+		return nil, errors.Errorf("self-test envelope %s was not committed within %s", txID, timeout)
+	case <-c.doneC:
+		return nil, errors.Errorf("chain is stopped")
+	}
+}
+
+func (c *Chain) selfTestEnvelope(txID string) (*common.Envelope, error) {
+	sigHdr, err := c.support.NewSignatureHeader()
+	if err != nil {
+		return nil, err
+	}
+	chdr := utils.MakeChannelHeader(common.HeaderType_MESSAGE, 0, c.channelID, 0)
+	chdr.TxId = txID
+	payload := utils.MarshalOrPanic(&common.Payload{
+		Header: utils.MakePayloadHeader(chdr, sigHdr),
+		Data:   []byte(SelfTestData),
+	})
+	sig, err := c.support.Sign(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &common.Envelope{Payload: payload, Signature: sig}, nil
+}
+
+// selfTestHandler runs self-tests on the etcdraft
+// chain of the channel given in the query.
+type selfTestHandler struct {
+	consenter *Consenter
+}
+
+// SelfTestHandler returns a handler running a self-test for POST requests of
+// the form ?channel=<channel ID>&timeout=<duration>, answered with the latency
+// breakdown of the self-test, or with 503 Service Unavailable if it failed.
+// The timeout defaults to 10s.
+func (c *Consenter) SelfTestHandler() http.Handler {
+	return &selfTestHandler{consenter: c}
+}
+
+func (h *selfTestHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	query := req.URL.Query()
+	channelID := query.Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+	timeout := defaultSelfTestTimeout
+	if t := query.Get("timeout"); t != "" {
+		var err error
+		if timeout, err = time.ParseDuration(t); err != nil || timeout <= 0 {
+			sendJSONError(resp, http.StatusBadRequest, fmt.Sprintf("invalid timeout: %q", t))
+			return
+		}
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	result, err := chain.SelfTest(timeout)
+	if err != nil {
+		sendJSONError(resp, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(result); err != nil {
+		h.consenter.Logger.Errorw("failed to encode self-test result", "channel", channelID, "error", err)
+	}
+}
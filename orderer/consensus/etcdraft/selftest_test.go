@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/multichannel"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTests(t *testing.T) {
+	envelope := func(txID string) []byte {
+		chdr := utils.MakeChannelHeader(common.HeaderType_MESSAGE, 0, "mychannel", 0)
+		chdr.TxId = txID
+		return utils.MarshalOrPanic(&common.Envelope{
+			Payload: utils.MarshalOrPanic(&common.Payload{
+				Header: utils.MakePayloadHeader(chdr, &common.SignatureHeader{}),
+				Data:   []byte(SelfTestData),
+			}),
+		})
+	}
+	block := func(number uint64, txIDs ...string) *common.Block {
+		b := common.NewBlock(number, nil)
+		for _, txID := range txIDs {
+			b.Data.Data = append(b.Data.Data, envelope(txID))
+		}
+		return b
+	}
+
+	st := newSelfTests()
+	applied := time.Now()
+	written := applied.Add(time.Second)
+
+	// blocks are not inspected unless a self-test is in progress
+	st.written(&common.Block{Header: &common.BlockHeader{}, Data: &common.BlockData{Data: [][]byte{{1, 2, 3}}}}, applied, written)
+
+	committedC := st.track(SelfTestTxIDPrefix + "1")
+	otherC := st.track(SelfTestTxIDPrefix + "2")
+	st.untrack(SelfTestTxIDPrefix + "2")
+
+	st.written(block(5, "tx1", SelfTestTxIDPrefix+"3"), applied, written)
+	assert.Empty(t, committedC)
+
+	st.written(block(6, "tx2", SelfTestTxIDPrefix+"2", SelfTestTxIDPrefix+"1"), applied, written)
+	require.Len(t, committedC, 1)
+	assert.Equal(t, selfTestCommit{blockNumber: 6, applied: applied, written: written}, <-committedC)
+	assert.Empty(t, otherC)
+	assert.Empty(t, st.pending)
+}
+
+func TestSelfTestHandler(t *testing.T) {
+	chains := chainsByID{
+		"mychannel": &multichannel.ChainSupport{Chain: &Chain{}},
+	}
+	handler := (&Consenter{Chains: chains, Logger: flogging.MustGetLogger("test")}).SelfTestHandler()
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, httptest.NewRequest(method, target, nil))
+		return resp
+	}
+	errorOf := func(resp *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+		return body["error"]
+	}
+
+	resp := serve(http.MethodGet, "/etcdraft/selftest?channel=mychannel")
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.Code)
+	resp = serve(http.MethodPost, "/etcdraft/selftest")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, "missing channel", errorOf(resp))
+	resp = serve(http.MethodPost, "/etcdraft/selftest?channel=mychannel&timeout=-1s")
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, `invalid timeout: "-1s"`, errorOf(resp))
+	resp = serve(http.MethodPost, "/etcdraft/selftest?channel=nochannel")
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = serve(http.MethodPost, "/etcdraft/selftest?channel=mychannel&timeout=1s")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+	assert.Equal(t, "chain is not started", errorOf(resp))
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/protos/orderer"
+)
+
+// DefaultReorderWindow is how long the leader holds a redirected transaction for
+// the one preceding it to arrive, if the ReorderWindow of the consenter is not set.
+const DefaultReorderWindow = 500 * time.Millisecond
+
+// Outcomes of holding a redirected transaction for the one preceding it.
+const (
+	// ReorderArrived means the preceding transaction arrived within the window.
+	ReorderArrived = "arrived"
+	// ReorderExpired means the window expired first, as the preceding
+	// transaction was already ordered, or was lost along with its leader.
+	ReorderExpired = "expired"
+)
+
+// forwardSequencer numbers the transactions a node forwards on behalf of its
+// clients, and marks those forwarded to another node than the transaction
+// before them as redirected, since the node the previous transaction was sent
+// to may forward it to the new leader after it, once it steps down.
+type forwardSequencer struct {
+	lock     sync.Mutex
+	sequence uint64
+	lastDest uint64 // the previous transaction was forwarded to
+}
+
+// stamp records the given node as the origin of the request, numbers
+// it, and marks it as redirected if the previous request was forwarded
+// to another node than the given destination.
+func (fs *forwardSequencer) stamp(origin, dest uint64, req *orderer.SubmitRequest) {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	fs.sequence++
+	req.Origin = origin
+	req.Sequence = fs.sequence
+	req.Redirected = fs.lastDest != 0 && fs.lastDest != dest
+	fs.lastDest = dest
+}
+
+// submitSequencer restores, on the leader, the order in which the origin of a
+// redirected transaction received the transaction preceding it, by holding it
+// until the preceding one is submitted, or the window expires. Transactions
+// which are not redirected follow the transactions of their origin over the
+// same stream, hence arrive in order. A nil submitSequencer holds nothing.
+type submitSequencer struct {
+	logger *flogging.FabricLogger
+	window time.Duration
+	holds  metrics.Counter // by outcome
+
+	lock       sync.Mutex
+	next       map[uint64]uint64 // by origin, the sequence after the last one submitted
+	submittedC chan struct{}     // closed and replaced whenever a transaction is submitted
+}
+
+func newSubmitSequencer(logger *flogging.FabricLogger, window time.Duration, holds metrics.Counter) *submitSequencer {
+	if window == 0 {
+		return nil
+	}
+	return &submitSequencer{
+		logger:     logger,
+		window:     window,
+		holds:      holds,
+		next:       make(map[uint64]uint64),
+		submittedC: make(chan struct{}),
+	}
+}
+
+// wait blocks until the transaction preceding the given one, if it is redirected,
+// is submitted, the window expires, or doneC is closed. The window is measured
+// in real time, as transactions are not held for long.
+func (s *submitSequencer) wait(req *orderer.SubmitRequest, doneC <-chan struct{}) {
+	if s == nil || req.Origin == 0 || !req.Redirected {
+		return
+	}
+
+	timer := time.NewTimer(s.window)
+	defer timer.Stop()
+
+	var held bool
+	for {
+		s.lock.Lock()
+		next, submittedC := s.next[req.Origin], s.submittedC
+		s.lock.Unlock()
+
+		if next >= req.Sequence {
+			if held {
+				s.holds.With("outcome", ReorderArrived).Add(1)
+			}
+			return
+		}
+		held = true
+
+		select {
+		case <-submittedC:
+		case <-timer.C:
+			s.logger.Debugf("Transaction %d of node %d did not arrive within %s, submitting transaction %d",
+				req.Sequence-1, req.Origin, s.window, req.Sequence)
+			s.holds.With("outcome", ReorderExpired).Add(1)
+			return
+		case <-doneC:
+			return
+		}
+	}
+}
+
+// submitted records the given transaction as submitted,
+// releasing the transaction following it, if it is held.
+func (s *submitSequencer) submitted(req *orderer.SubmitRequest) {
+	if s == nil || req.Origin == 0 {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if req.Sequence >= s.next[req.Origin] {
+		s.next[req.Origin] = req.Sequence + 1
+	}
+	close(s.submittedC)
+	s.submittedC = make(chan struct{})
+}
@@ -0,0 +1,79 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForwardSequencer(t *testing.T) {
+	var fs forwardSequencer
+
+	stamped := func(dest uint64) *orderer.SubmitRequest {
+		req := &orderer.SubmitRequest{}
+		fs.stamp(1, dest, req)
+		return req
+	}
+
+	assert.Equal(t, &orderer.SubmitRequest{Origin: 1, Sequence: 1}, stamped(2))
+	assert.Equal(t, &orderer.SubmitRequest{Origin: 1, Sequence: 2}, stamped(2))
+	// the previous request was forwarded to node 2
+	assert.Equal(t, &orderer.SubmitRequest{Origin: 1, Sequence: 3, Redirected: true}, stamped(3))
+	assert.Equal(t, &orderer.SubmitRequest{Origin: 1, Sequence: 4}, stamped(3))
+}
+
+func TestSubmitSequencer(t *testing.T) {
+	holds := &metricsfakes.Counter{}
+	holds.WithReturns(holds)
+	s := newSubmitSequencer(flogging.MustGetLogger("test"), time.Minute, holds)
+	doneC := make(chan struct{})
+
+	s.submitted(&orderer.SubmitRequest{Origin: 1, Sequence: 1})
+
+	// requests which are not redirected, or follow the last
+	// request submitted by their origin, are not held
+	s.wait(&orderer.SubmitRequest{Origin: 1, Sequence: 5}, doneC)
+	s.wait(&orderer.SubmitRequest{Origin: 1, Sequence: 2, Redirected: true}, doneC)
+	s.wait(&orderer.SubmitRequest{Sequence: 7, Redirected: true}, doneC)
+	assert.Zero(t, holds.AddCallCount())
+
+	// a redirected request is held until the preceding request is submitted
+	redirected := &orderer.SubmitRequest{Origin: 1, Sequence: 3, Redirected: true}
+	releasedC := make(chan struct{})
+	go func() {
+		s.wait(redirected, doneC)
+		close(releasedC)
+	}()
+	s.submitted(&orderer.SubmitRequest{Origin: 2, Sequence: 2})
+	select {
+	case <-releasedC:
+		t.Fatal("redirected request was released before the preceding request was submitted")
+	case <-time.After(50 * time.Millisecond):
+	}
+	s.submitted(&orderer.SubmitRequest{Origin: 1, Sequence: 2})
+	<-releasedC
+	assert.Equal(t, 1, holds.AddCallCount())
+	assert.Equal(t, []string{"outcome", ReorderArrived}, holds.WithArgsForCall(0))
+
+	// a redirected request is released once the window expires
+	s.window = 10 * time.Millisecond
+	s.wait(&orderer.SubmitRequest{Origin: 1, Sequence: 10, Redirected: true}, doneC)
+	assert.Equal(t, 2, holds.AddCallCount())
+	assert.Equal(t, []string{"outcome", ReorderExpired}, holds.WithArgsForCall(1))
+
+	// a nil sequencer holds nothing
+	assert.Nil(t, newSubmitSequencer(flogging.MustGetLogger("test"), 0, holds))
+	var disabled *submitSequencer
+	disabled.wait(redirected, doneC)
+	disabled.submitted(redirected)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// ServeState is the state of the goroutine serving the requests of a chain,
+// which determines which requests it serves and how.
+type ServeState int32
+
+const (
+	// ServeStateFollower is the state of a node following a leader, which
+	// forwards the transactions it is submitted to the leader.
+	ServeStateFollower ServeState = iota
+	// ServeStateCandidateObserved is the state of a node which observed itself
+	// or another node campaigning, or no leader, and refuses transactions until
+	// a leader is elected.
+	ServeStateCandidateObserved
+	// ServeStateLeadingIdle is the state of a leader with neither blocks nor
+	// a batch pending, which creates an empty block if none is proposed
+	// for the MaxBlockInterval.
+	ServeStateLeadingIdle
+	// ServeStateLeadingBusy is the state of a leader with blocks in flight
+	// or a batch pending.
+	ServeStateLeadingBusy
+	// ServeStateConfigPending is the state of a node with a config block or
+	// a ConfChange in flight, which pauses accepting transactions until it
+	// is applied.
+	ServeStateConfigPending
+	// ServeStateCatchingUp is the state of a node pulling the blocks of a
+	// snapshot from the other nodes, which serves no request until it is done.
+	ServeStateCatchingUp
+)
+
+var serveStateNames = map[ServeState]string{
+	ServeStateFollower:          "follower",
+	ServeStateCandidateObserved: "candidate-observed",
+	ServeStateLeadingIdle:       "leading-idle",
+	ServeStateLeadingBusy:       "leading-busy",
+	ServeStateConfigPending:     "config-pending",
+	ServeStateCatchingUp:        "catching-up",
+}
+
+func (s ServeState) String() string {
+	if name, exists := serveStateNames[s]; exists {
+		return name
+	}
+	return fmt.Sprintf("ServeState(%d)", int32(s))
+}
+
+// ServeConditions are the conditions of the goroutine serving the requests of
+// a chain which its state is derived from.
+type ServeConditions struct {
+	Leading        bool // whether the node is the leader
+	Candidate      bool // whether the node campaigns, or observes no leader
+	ConfigInflight bool // whether a config block or a ConfChange is in flight
+	BlocksInflight int  // number of blocks proposed by the leader and not yet applied
+	BatchPending   bool // whether the leader has a batch pending in the block cutter
+	CatchingUp     bool // whether the node is catching up with a snapshot
+}
+
+// NextServeState returns the state of the goroutine serving the requests of a
+// chain under the given conditions. Catching up takes precedence over all the
+// other conditions, then observing a campaign, and then a config in flight.
+func NextServeState(conds ServeConditions) ServeState {
+	switch {
+	case conds.CatchingUp:
+		return ServeStateCatchingUp
+	case conds.Candidate:
+		return ServeStateCandidateObserved
+	case conds.ConfigInflight:
+		return ServeStateConfigPending
+	case !conds.Leading:
+		return ServeStateFollower
+	case conds.BlocksInflight > 0 || conds.BatchPending:
+		return ServeStateLeadingBusy
+	default:
+		return ServeStateLeadingIdle
+	}
+}
+
+// ServeTransition is a transition of the goroutine serving
+// the requests of a chain from one state to another.
+type ServeTransition struct {
+	From ServeState
+	To   ServeState
+	// Sequence numbers the transitions of the chain from 1, without gaps.
+	Sequence uint64
+	Time     time.Time
+}
+
+// ServeStateHook is called with every transition of the goroutine serving the
+// requests of a chain, by that goroutine, hence it must not block.
+type ServeStateHook func(transition ServeTransition)
+
+// ServeStateMachine tracks the state of the goroutine serving the requests of
+// a chain. It is observed by that goroutine only, and its state may be read
+// concurrently.
+type ServeStateMachine struct {
+	now   func() time.Time
+	hooks []ServeStateHook
+
+	state    int32 // ServeState, accessed atomically
+	sequence uint64
+}
+
+// NewServeStateMachine returns a ServeStateMachine in the follower state,
+// which every node starts out in, calling the given hooks on transitions.
+func NewServeStateMachine(now func() time.Time, hooks ...ServeStateHook) *ServeStateMachine {
+	return &ServeStateMachine{
+		now:   now,
+		hooks: hooks,
+		state: int32(ServeStateFollower),
+	}
+}
+
+// State returns the current state.
+func (m *ServeStateMachine) State() ServeState {
+	return ServeState(atomic.LoadInt32(&m.state))
+}
+
+// Observe moves the machine to the state of the given conditions, and returns
+// the transition along with true if the state changed.
+func (m *ServeStateMachine) Observe(conds ServeConditions) (ServeTransition, bool) {
+	from := m.State()
+	to := NextServeState(conds)
+	if from == to {
+		return ServeTransition{}, false
+	}
+
+	m.sequence++
+	transition := ServeTransition{From: from, To: to, Sequence: m.sequence, Time: m.now()}
+	atomic.StoreInt32(&m.state, int32(to))
+	for _, hook := range m.hooks {
+		hook(transition)
+	}
+	return transition, true
+}
@@ -0,0 +1,129 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextServeState(t *testing.T) {
+	for _, testCase := range []struct {
+		name     string
+		conds    etcdraft.ServeConditions
+		expected etcdraft.ServeState
+	}{
+		{
+			name:     "follower",
+			conds:    etcdraft.ServeConditions{},
+			expected: etcdraft.ServeStateFollower,
+		},
+		{
+			name:     "follower with blocks in flight of the previous term",
+			conds:    etcdraft.ServeConditions{BlocksInflight: 2},
+			expected: etcdraft.ServeStateFollower,
+		},
+		{
+			name:     "candidate",
+			conds:    etcdraft.ServeConditions{Candidate: true, ConfigInflight: true},
+			expected: etcdraft.ServeStateCandidateObserved,
+		},
+		{
+			name:     "idle leader",
+			conds:    etcdraft.ServeConditions{Leading: true},
+			expected: etcdraft.ServeStateLeadingIdle,
+		},
+		{
+			name:     "leader with blocks in flight",
+			conds:    etcdraft.ServeConditions{Leading: true, BlocksInflight: 1},
+			expected: etcdraft.ServeStateLeadingBusy,
+		},
+		{
+			name:     "leader with a batch pending",
+			conds:    etcdraft.ServeConditions{Leading: true, BatchPending: true},
+			expected: etcdraft.ServeStateLeadingBusy,
+		},
+		{
+			name:     "leader with a config in flight",
+			conds:    etcdraft.ServeConditions{Leading: true, BlocksInflight: 1, ConfigInflight: true},
+			expected: etcdraft.ServeStateConfigPending,
+		},
+		{
+			name:     "follower with a config in flight",
+			conds:    etcdraft.ServeConditions{ConfigInflight: true},
+			expected: etcdraft.ServeStateConfigPending,
+		},
+		{
+			name:     "catching up",
+			conds:    etcdraft.ServeConditions{CatchingUp: true, Candidate: true, ConfigInflight: true},
+			expected: etcdraft.ServeStateCatchingUp,
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, etcdraft.NextServeState(testCase.conds))
+		})
+	}
+}
+
+func TestServeStateString(t *testing.T) {
+	assert.Equal(t, "follower", etcdraft.ServeStateFollower.String())
+	assert.Equal(t, "candidate-observed", etcdraft.ServeStateCandidateObserved.String())
+	assert.Equal(t, "leading-idle", etcdraft.ServeStateLeadingIdle.String())
+	assert.Equal(t, "leading-busy", etcdraft.ServeStateLeadingBusy.String())
+	assert.Equal(t, "config-pending", etcdraft.ServeStateConfigPending.String())
+	assert.Equal(t, "catching-up", etcdraft.ServeStateCatchingUp.String())
+	assert.Equal(t, "ServeState(42)", etcdraft.ServeState(42).String())
+}
+
+func TestServeStateMachine(t *testing.T) {
+	now := time.Now()
+	var first, second []etcdraft.ServeTransition
+	m := etcdraft.NewServeStateMachine(
+		func() time.Time { return now },
+		func(transition etcdraft.ServeTransition) { first = append(first, transition) },
+		func(transition etcdraft.ServeTransition) { second = append(second, transition) },
+	)
+	assert.Equal(t, etcdraft.ServeStateFollower, m.State())
+
+	_, changed := m.Observe(etcdraft.ServeConditions{})
+	assert.False(t, changed)
+	assert.Empty(t, first)
+
+	transition, changed := m.Observe(etcdraft.ServeConditions{Candidate: true})
+	assert.True(t, changed)
+	assert.Equal(t, etcdraft.ServeTransition{
+		From:     etcdraft.ServeStateFollower,
+		To:       etcdraft.ServeStateCandidateObserved,
+		Sequence: 1,
+		Time:     now,
+	}, transition)
+	assert.Equal(t, etcdraft.ServeStateCandidateObserved, m.State())
+
+	m.Observe(etcdraft.ServeConditions{Leading: true})
+	m.Observe(etcdraft.ServeConditions{Leading: true, BatchPending: true})
+	m.Observe(etcdraft.ServeConditions{Leading: true, BlocksInflight: 1})
+	m.Observe(etcdraft.ServeConditions{Leading: true})
+
+	var states []etcdraft.ServeState
+	for i, transition := range first {
+		assert.Equal(t, uint64(i+1), transition.Sequence)
+		if i > 0 {
+			assert.Equal(t, first[i-1].To, transition.From)
+		}
+		states = append(states, transition.To)
+	}
+	assert.Equal(t, []etcdraft.ServeState{
+		etcdraft.ServeStateCandidateObserved,
+		etcdraft.ServeStateLeadingIdle,
+		etcdraft.ServeStateLeadingBusy,
+		etcdraft.ServeStateLeadingIdle,
+	}, states)
+	assert.Equal(t, first, second)
+}
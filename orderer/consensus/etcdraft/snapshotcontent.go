@@ -0,0 +1,52 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/pkg/errors"
+)
+
+// SnapshotContent is what the snapshots of a chain hold of the block they are
+// taken at, besides the raft index and the ConfState of the snapshot.
+type SnapshotContent string
+
+const (
+	// SnapshotContentBlock stores the whole block in snapshots.
+	SnapshotContentBlock SnapshotContent = "block"
+	// SnapshotContentHeader only stores the header of the block in snapshots,
+	// which is all that nodes catching up with a snapshot read, since they
+	// pull the blocks up to the one of the snapshot from the other consenters.
+	// Snapshots of channels with large blocks are thus much smaller.
+	SnapshotContentHeader SnapshotContent = "header"
+)
+
+// ParseSnapshotContent parses the content of snapshots,
+// which defaults to SnapshotContentBlock if not set.
+func ParseSnapshotContent(content string) (SnapshotContent, error) {
+	switch SnapshotContent(content) {
+	case "":
+		return SnapshotContentBlock, nil
+	case SnapshotContentBlock, SnapshotContentHeader:
+		return SnapshotContent(content), nil
+	default:
+		return "", errors.Errorf("unknown snapshot content %s, expected %s or %s",
+			content, SnapshotContentBlock, SnapshotContentHeader)
+	}
+}
+
+// snapshotHeaderData returns the data of the snapshot with the header of its
+// block only, wrapped into SnapshotData along with the archive it references.
+func (g *gc) snapshotHeaderData() []byte {
+	block := g.block
+	if block == nil {
+		block = utils.UnmarshalBlockOrPanic(g.data)
+	}
+	return utils.MarshalOrPanic(&etcdraft.SnapshotData{Block: &common.Block{Header: block.Header}, Archive: g.archive})
+}
@@ -0,0 +1,58 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/common"
+	etcdraftproto "github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSnapshotContent(t *testing.T) {
+	for content, expected := range map[string]SnapshotContent{
+		"":       SnapshotContentBlock,
+		"block":  SnapshotContentBlock,
+		"header": SnapshotContentHeader,
+	} {
+		snapshotContent, err := ParseSnapshotContent(content)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, snapshotContent)
+	}
+
+	_, err := ParseSnapshotContent("data")
+	assert.EqualError(t, err, "unknown snapshot content data, expected block or header")
+}
+
+func TestSnapshotHeaderData(t *testing.T) {
+	block := common.NewBlock(5, []byte("previous"))
+	block.Data.Data = [][]byte{[]byte("tx")}
+
+	// the block of the snapshot is either serialized already, or captured
+	for _, g := range []*gc{
+		{index: 7, data: utils.MarshalOrPanic(block)},
+		{index: 7, block: captureBlock(block)},
+	} {
+		header, archive, err := SnapshotBlock(g.snapshotHeaderData())
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(block.Header, header.Header))
+		assert.Nil(t, header.Data)
+		assert.Nil(t, header.Metadata)
+		assert.Nil(t, archive)
+	}
+
+	ref := &etcdraftproto.ArchiveReference{Uri: "file:///archive"}
+	g := &gc{index: 7, block: captureBlock(block), archive: ref}
+	header, archive, err := SnapshotBlock(g.snapshotHeaderData())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), header.Header.Number)
+	assert.True(t, proto.Equal(ref, archive))
+}
@@ -0,0 +1,126 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
+)
+
+const (
+	// DefaultMaxSnapshotDeferral is the longest a snapshot is deferred
+	// if MaxSnapshotDeferral is not set.
+	DefaultMaxSnapshotDeferral = time.Minute
+
+	// snapshotDeferralInterval is the interval at which the persist latency
+	// is checked while a snapshot is deferred, and the pause between the
+	// chunks of a compaction while it is elevated.
+	snapshotDeferralInterval = 100 * time.Millisecond
+
+	// persistLatencyWeight is the weight of the latest persist
+	// latency in the moving average of the persist latency.
+	persistLatencyWeight = 0.2
+)
+
+// snapshotScheduler schedules the snapshots of a chain, and the compaction
+// of the in-memory raft entries which follows them, around the appends to
+// the WAL. Snapshotting contends for disk I/O with the WAL, hence a snapshot
+// is deferred while the moving average of the time taken to persist raft
+// data exceeds the threshold, for up to the maximum deferral, after which
+// it is taken regardless so that the WAL does not grow without bound.
+type snapshotScheduler struct {
+	logger      *flogging.FabricLogger
+	clock       clock.Clock
+	threshold   time.Duration
+	maxDeferral time.Duration
+	deferred    metrics.Counter
+
+	lock    sync.Mutex
+	latency float64 // moving average of the persist latency, in seconds
+}
+
+// newSnapshotScheduler returns a snapshotScheduler,
+// or nil if snapshots are not to be deferred.
+func newSnapshotScheduler(logger *flogging.FabricLogger, clock clock.Clock, threshold, maxDeferral time.Duration, deferred metrics.Counter) *snapshotScheduler {
+	if threshold <= 0 {
+		return nil
+	}
+	if maxDeferral <= 0 {
+		maxDeferral = DefaultMaxSnapshotDeferral
+	}
+	return &snapshotScheduler{
+		logger:      logger,
+		clock:       clock,
+		threshold:   threshold,
+		maxDeferral: maxDeferral,
+		deferred:    deferred,
+	}
+}
+
+// observe records the time taken to persist raft data.
+func (s *snapshotScheduler) observe(d time.Duration) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.latency = persistLatencyWeight*d.Seconds() + (1-persistLatencyWeight)*s.latency
+}
+
+// elevated returns whether the persist latency exceeds the threshold.
+func (s *snapshotScheduler) elevated() bool {
+	if s == nil {
+		return false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.latency > s.threshold.Seconds()
+}
+
+// wait defers the snapshot at the given index while the persist latency is
+// elevated, for up to the maximum deferral. It returns false if stopC is
+// closed in the meantime, in which case the snapshot is not to be taken.
+func (s *snapshotScheduler) wait(index uint64, stopC <-chan struct{}) bool {
+	if !s.elevated() {
+		return true
+	}
+
+	s.logger.Infof("Deferring snapshot at index %d, as the persist latency of raft data exceeds %s", index, s.threshold)
+	s.deferred.Add(1)
+	deadline := s.clock.Now().Add(s.maxDeferral)
+	for s.elevated() {
+		if !s.clock.Now().Before(deadline) {
+			s.logger.Warnf("Persist latency of raft data still exceeds %s after deferring snapshot at index %d for %s, taking it anyway",
+				s.threshold, index, s.maxDeferral)
+			return true
+		}
+		select {
+		case <-s.clock.After(snapshotDeferralInterval):
+		case <-stopC:
+			return false
+		}
+	}
+	return true
+}
+
+// yield is called between the chunks of a compaction. It pauses
+// the compaction while the persist latency is elevated, and otherwise
+// yields the processor to the goroutine appending to the WAL.
+func (s *snapshotScheduler) yield() {
+	if s.elevated() {
+		s.clock.Sleep(snapshotDeferralInterval)
+		return
+	}
+	runtime.Gosched()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotScheduler(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	deferred := &metricsfakes.Counter{}
+	logger := flogging.MustGetLogger("test")
+
+	// waitAsync waits for the snapshot at index 10 in a goroutine,
+	// and returns a channel which yields whether it is to be taken
+	waitAsync := func(s *snapshotScheduler, stopC chan struct{}) <-chan bool {
+		result := make(chan bool, 1)
+		go func() {
+			result <- s.wait(10, stopC)
+		}()
+		return result
+	}
+
+	assert.Nil(t, newSnapshotScheduler(logger, clock, 0, time.Minute, deferred))
+	var disabled *snapshotScheduler
+	disabled.observe(time.Hour)
+	assert.False(t, disabled.elevated())
+	assert.True(t, disabled.wait(10, nil))
+	assert.Equal(t, 0, deferred.AddCallCount())
+
+	s := newSnapshotScheduler(logger, clock, 100*time.Millisecond, 0, deferred)
+	assert.Equal(t, DefaultMaxSnapshotDeferral, s.maxDeferral)
+
+	// a snapshot is taken right away while the latency is low
+	for i := 0; i < 10; i++ {
+		s.observe(10 * time.Millisecond)
+	}
+	assert.False(t, s.elevated())
+	assert.True(t, s.wait(10, nil))
+	assert.Equal(t, 0, deferred.AddCallCount())
+
+	// a single slow persist does not elevate the moving average
+	s.observe(200 * time.Millisecond)
+	assert.False(t, s.elevated())
+
+	// a snapshot is deferred until the latency drops
+	for i := 0; i < 10; i++ {
+		s.observe(time.Second)
+	}
+	require.True(t, s.elevated())
+	result := waitAsync(s, nil)
+	clock.WaitForWatcherAndIncrement(snapshotDeferralInterval)
+	assert.Equal(t, 1, deferred.AddCallCount())
+	assert.Empty(t, result)
+
+	// the latency drops once the snapshot is waited for again
+	for clock.WatcherCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for s.elevated() {
+		s.observe(0)
+	}
+	clock.WaitForWatcherAndIncrement(snapshotDeferralInterval)
+	assert.True(t, <-result)
+	assert.Equal(t, 1, deferred.AddCallCount(), "a snapshot is counted as deferred once")
+
+	// a snapshot is taken regardless once the maximum deferral elapses
+	s.observe(time.Hour)
+	result = waitAsync(s, nil)
+	for i := time.Duration(0); i < DefaultMaxSnapshotDeferral; i += snapshotDeferralInterval {
+		clock.WaitForWatcherAndIncrement(snapshotDeferralInterval)
+	}
+	assert.True(t, <-result)
+	assert.Equal(t, 2, deferred.AddCallCount())
+
+	// a deferred snapshot is not taken once the chain halts
+	stopC := make(chan struct{})
+	close(stopC)
+	assert.False(t, s.wait(10, stopC))
+	assert.Equal(t, 3, deferred.AddCallCount())
+}
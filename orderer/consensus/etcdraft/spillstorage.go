@@ -0,0 +1,337 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// SpillingMemoryStorage is a MemoryStorage which caps the memory held by the
+// raft entries which are not compacted yet. raft.MemoryStorage holds every entry
+// appended since the last compaction, which on a busy channel amounts to the
+// blocks written in between snapshots. SpillingMemoryStorage instead drops the
+// data of the oldest committed entries once the data held exceeds its limit,
+// and reads it back from the WAL when the entries are requested, e.g. by the
+// leader to replicate them to a lagging follower. The terms and indices of all
+// entries remain in memory.
+//
+// Only committed entries, which are in the WAL and are never truncated, are
+// spilled, hence SpillingMemoryStorage must be given the hard state of the
+// node as it is persisted. Entries are only spilled once a reader of the WAL
+// is set, which the RaftStorage created with it sets.
+type SpillingMemoryStorage struct {
+	limit uint64 // bytes of entry data held in memory, beyond which committed entries are spilled
+
+	lock      sync.Mutex
+	hardState raftpb.HardState
+	snapshot  raftpb.Snapshot
+	// ents[i] has raft log position i+ents[0].Index as in raft.MemoryStorage,
+	// but the data of the entries up to spilledTo is not held in memory
+	ents      []raftpb.Entry
+	spilledTo uint64
+	size      uint64 // of the data held in ents
+
+	// read reads the entries [lo, hi) from the WAL
+	read func(lo, hi uint64) ([]raftpb.Entry, error)
+	// cache holds the entries last read from the WAL, up to limit bytes
+	cache []raftpb.Entry
+}
+
+// NewSpillingMemoryStorage returns a SpillingMemoryStorage which holds
+// up to limit bytes of entry data in memory.
+func NewSpillingMemoryStorage(limit uint64) *SpillingMemoryStorage {
+	return &SpillingMemoryStorage{
+		limit: limit,
+		// populated with a dummy entry at term zero, as raft.MemoryStorage
+		ents: make([]raftpb.Entry, 1),
+	}
+}
+
+// setReader sets the function reading spilled entries from the WAL.
+func (s *SpillingMemoryStorage) setReader(read func(lo, hi uint64) ([]raftpb.Entry, error)) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.read = read
+	s.spill()
+}
+
+// InitialState implements the raft.Storage interface.
+func (s *SpillingMemoryStorage) InitialState() (raftpb.HardState, raftpb.ConfState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.hardState, s.snapshot.Metadata.ConfState, nil
+}
+
+// SetHardState saves the current HardState, and spills the entries it commits if need be.
+func (s *SpillingMemoryStorage) SetHardState(st raftpb.HardState) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.hardState = st
+	s.spill()
+	return nil
+}
+
+// Entries implements the raft.Storage interface. Spilled entries are read from
+// the WAL without holding the lock, so that entries can be appended meanwhile.
+// raft.ErrCompacted is returned if they cannot be read.
+func (s *SpillingMemoryStorage) Entries(lo, hi, maxSize uint64) ([]raftpb.Entry, error) {
+	s.lock.Lock()
+	offset := s.ents[0].Index
+	if lo <= offset {
+		s.lock.Unlock()
+		return nil, raft.ErrCompacted
+	}
+	if hi > s.lastIndex()+1 {
+		last := s.lastIndex()
+		s.lock.Unlock()
+		return nil, errors.Errorf("entries' hi(%d) is out of bound lastindex(%d)", hi, last)
+	}
+	if len(s.ents) == 1 {
+		s.lock.Unlock()
+		return nil, raft.ErrUnavailable
+	}
+	if lo > s.spilledTo {
+		// copied, as the data of the entries is dropped in place once they are spilled
+		ents := append([]raftpb.Entry(nil), limitEntries(s.ents[lo-offset:hi-offset], maxSize)...)
+		s.lock.Unlock()
+		return ents, nil
+	}
+	if ents := s.cached(lo, hi); ents != nil {
+		s.lock.Unlock()
+		return limitEntries(ents, maxSize), nil
+	}
+	spilledTo, read := s.spilledTo, s.read
+	s.lock.Unlock()
+
+	spilled, err := read(lo, spilledTo+1)
+	if err != nil {
+		// the reader logs the failure, and the leader sends a
+		// snapshot to the follower the entries are requested for
+		return nil, raft.ErrCompacted
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if lo <= s.ents[0].Index || spilledTo > s.lastIndex() {
+		// compacted, or replaced by a snapshot, in the meantime
+		return nil, raft.ErrCompacted
+	}
+	s.cache = nil
+	for i, size := 0, uint64(0); i < len(spilled) && size <= s.limit; i++ {
+		s.cache = spilled[:i+1]
+		size += uint64(len(spilled[i].Data))
+	}
+	// Only the spilled entries are returned, which raft takes as entries limited
+	// in size, and requests the entries which follow them from the storage again.
+	if hi < spilledTo+1 {
+		spilled = spilled[:hi-lo]
+	}
+	return limitEntries(spilled, maxSize), nil
+}
+
+// cached returns the entries [lo, hi) if they are all cached, or nil otherwise.
+func (s *SpillingMemoryStorage) cached(lo, hi uint64) []raftpb.Entry {
+	if len(s.cache) == 0 {
+		return nil
+	}
+	first, last := s.cache[0].Index, s.cache[len(s.cache)-1].Index
+	if lo < first || hi-1 > last {
+		return nil
+	}
+	return s.cache[lo-first : hi-first]
+}
+
+// Term implements the raft.Storage interface.
+func (s *SpillingMemoryStorage) Term(i uint64) (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	offset := s.ents[0].Index
+	if i < offset {
+		return 0, raft.ErrCompacted
+	}
+	if int(i-offset) >= len(s.ents) {
+		return 0, raft.ErrUnavailable
+	}
+	return s.ents[i-offset].Term, nil
+}
+
+// LastIndex implements the raft.Storage interface.
+func (s *SpillingMemoryStorage) LastIndex() (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lastIndex(), nil
+}
+
+func (s *SpillingMemoryStorage) lastIndex() uint64 {
+	return s.ents[0].Index + uint64(len(s.ents)) - 1
+}
+
+// FirstIndex implements the raft.Storage interface.
+func (s *SpillingMemoryStorage) FirstIndex() (uint64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.ents[0].Index + 1, nil
+}
+
+// Snapshot implements the raft.Storage interface.
+func (s *SpillingMemoryStorage) Snapshot() (raftpb.Snapshot, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.snapshot, nil
+}
+
+// ApplySnapshot overwrites the contents of the storage with those of the given snapshot.
+func (s *SpillingMemoryStorage) ApplySnapshot(snap raftpb.Snapshot) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.snapshot.Metadata.Index >= snap.Metadata.Index {
+		return raft.ErrSnapOutOfDate
+	}
+
+	s.snapshot = snap
+	s.ents = []raftpb.Entry{{Term: snap.Metadata.Term, Index: snap.Metadata.Index}}
+	s.spilledTo = 0
+	s.size = 0
+	s.cache = nil
+	return nil
+}
+
+// CreateSnapshot makes a snapshot at the given index, as raft.MemoryStorage.
+func (s *SpillingMemoryStorage) CreateSnapshot(i uint64, cs *raftpb.ConfState, data []byte) (raftpb.Snapshot, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if i <= s.snapshot.Metadata.Index {
+		return raftpb.Snapshot{}, raft.ErrSnapOutOfDate
+	}
+	if i > s.lastIndex() {
+		return raftpb.Snapshot{}, errors.Errorf("snapshot %d is out of bound lastindex(%d)", i, s.lastIndex())
+	}
+
+	s.snapshot.Metadata.Index = i
+	s.snapshot.Metadata.Term = s.ents[i-s.ents[0].Index].Term
+	if cs != nil {
+		s.snapshot.Metadata.ConfState = *cs
+	}
+	s.snapshot.Data = data
+	return s.snapshot, nil
+}
+
+// Compact discards all entries prior to compactIndex.
+func (s *SpillingMemoryStorage) Compact(compactIndex uint64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	offset := s.ents[0].Index
+	if compactIndex <= offset {
+		return raft.ErrCompacted
+	}
+	if compactIndex > s.lastIndex() {
+		return errors.Errorf("compact %d is out of bound lastindex(%d)", compactIndex, s.lastIndex())
+	}
+
+	i := compactIndex - offset
+	for _, e := range s.ents[1 : i+1] {
+		s.size -= uint64(len(e.Data))
+	}
+	ents := make([]raftpb.Entry, 1, 1+uint64(len(s.ents))-i)
+	ents[0].Index = s.ents[i].Index
+	ents[0].Term = s.ents[i].Term
+	s.ents = append(ents, s.ents[i+1:]...)
+	if len(s.cache) > 0 && s.cache[len(s.cache)-1].Index <= compactIndex {
+		s.cache = nil
+	}
+	return nil
+}
+
+// Append appends the given entries, which are already in the WAL,
+// and spills the oldest committed entries if need be.
+func (s *SpillingMemoryStorage) Append(entries []raftpb.Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	first := s.ents[0].Index + 1
+	last := entries[0].Index + uint64(len(entries)) - 1
+	if last < first {
+		return nil
+	}
+	if first > entries[0].Index {
+		entries = entries[first-entries[0].Index:]
+	}
+
+	offset := entries[0].Index - s.ents[0].Index
+	switch {
+	case uint64(len(s.ents)) > offset:
+		// entries conflicting with the ones appended are truncated,
+		// which are not committed and therefore not spilled
+		for _, e := range s.ents[offset:] {
+			s.size -= uint64(len(e.Data))
+		}
+		s.ents = append([]raftpb.Entry{}, s.ents[:offset]...)
+		s.ents = append(s.ents, entries...)
+	case uint64(len(s.ents)) == offset:
+		s.ents = append(s.ents, entries...)
+	default:
+		return errors.Errorf("missing log entry [last: %d, append at: %d]", s.lastIndex(), entries[0].Index)
+	}
+	for _, e := range entries {
+		s.size += uint64(len(e.Data))
+	}
+
+	s.spill()
+	return nil
+}
+
+// spill drops the data of the oldest committed entries held in memory,
+// until the data held is within the limit.
+func (s *SpillingMemoryStorage) spill() {
+	if s.read == nil || s.size <= s.limit {
+		return
+	}
+
+	offset := s.ents[0].Index
+	committed := s.hardState.Commit
+	if last := s.lastIndex(); committed > last {
+		committed = last
+	}
+	if s.spilledTo < offset {
+		s.spilledTo = offset
+	}
+	if s.spilledTo >= committed {
+		return
+	}
+
+	for s.size > s.limit && s.spilledTo < committed {
+		s.spilledTo++
+		e := &s.ents[s.spilledTo-offset]
+		s.size -= uint64(len(e.Data))
+		e.Data = nil
+	}
+}
+
+// limitEntries returns the longest prefix of the given entries whose size is
+// within maxSize, which holds the first entry regardless, as raft.MemoryStorage.
+func limitEntries(ents []raftpb.Entry, maxSize uint64) []raftpb.Entry {
+	if len(ents) == 0 {
+		return ents
+	}
+	size := ents[0].Size()
+	var limit int
+	for limit = 1; limit < len(ents); limit++ {
+		size += ents[limit].Size()
+		if uint64(size) > maxSize {
+			break
+		}
+	}
+	return ents[:limit]
+}
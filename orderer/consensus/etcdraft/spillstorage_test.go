@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.uber.org/zap"
+)
+
+func spillEntries(lo, hi uint64) []raftpb.Entry {
+	var ents []raftpb.Entry
+	for i := lo; i < hi; i++ {
+		ents = append(ents, raftpb.Entry{Index: i, Term: 1, Data: make([]byte, 10)})
+	}
+	return ents
+}
+
+func TestSpillingMemoryStorage(t *testing.T) {
+	var reads [][2]uint64
+	var readErr error
+	s := NewSpillingMemoryStorage(30)
+	s.setReader(func(lo, hi uint64) ([]raftpb.Entry, error) {
+		reads = append(reads, [2]uint64{lo, hi})
+		return spillEntries(lo, hi), readErr
+	})
+
+	// uncommitted entries are not spilled
+	require.NoError(t, s.Append(spillEntries(1, 11)))
+	ents, err := s.Entries(1, 11, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(1, 11), ents)
+	assert.Empty(t, reads)
+
+	// committed entries are spilled down to the limit
+	require.NoError(t, s.SetHardState(raftpb.HardState{Term: 1, Commit: 5}))
+	assert.Equal(t, uint64(5), s.spilledTo)
+	assert.Equal(t, uint64(50), s.size)
+	require.NoError(t, s.SetHardState(raftpb.HardState{Term: 1, Commit: 10}))
+	assert.Equal(t, uint64(7), s.spilledTo)
+	assert.Equal(t, uint64(30), s.size)
+	for _, e := range ents {
+		assert.Len(t, e.Data, 10, "entries returned before are not affected")
+	}
+
+	// spilled entries are read from the reader, and only them
+	ents, err = s.Entries(3, 11, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(3, 8), ents)
+	assert.Equal(t, [][2]uint64{{3, 8}}, reads)
+	ents, err = s.Entries(8, 11, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(8, 11), ents)
+
+	// up to the limit of them are cached
+	ents, err = s.Entries(3, 5, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(3, 5), ents)
+	ents, err = s.Entries(5, 6, math.MaxUint64)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(5, 6), ents)
+	assert.Len(t, reads, 1)
+	ents, err = s.Entries(6, 8, 0)
+	require.NoError(t, err)
+	assert.Equal(t, spillEntries(6, 7), ents, "the size of the entries is limited")
+	assert.Equal(t, [][2]uint64{{3, 8}, {6, 8}}, reads)
+
+	// entries which cannot be read are reported as compacted
+	readErr = errors.New("oops")
+	_, err = s.Entries(1, 3, math.MaxUint64)
+	assert.Equal(t, raft.ErrCompacted, err)
+	readErr = nil
+
+	// terms are held in memory
+	term, err := s.Term(4)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), term)
+
+	// compaction discards spilled and held entries alike
+	require.NoError(t, s.Compact(8))
+	first, err := s.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), first)
+	assert.Equal(t, uint64(20), s.size)
+	assert.Nil(t, s.cache)
+	_, err = s.Entries(8, 9, math.MaxUint64)
+	assert.Equal(t, raft.ErrCompacted, err)
+
+	// entries conflicting with appended ones are truncated
+	require.NoError(t, s.Append([]raftpb.Entry{{Index: 10, Term: 2, Data: make([]byte, 5)}}))
+	assert.Equal(t, uint64(15), s.size)
+	last, err := s.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), last)
+
+	snapshot, err := s.CreateSnapshot(10, &raftpb.ConfState{Nodes: []uint64{1}}, []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), snapshot.Metadata.Term)
+	require.NoError(t, s.ApplySnapshot(raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 20, Term: 3}}))
+	assert.Equal(t, raft.ErrSnapOutOfDate, s.ApplySnapshot(raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 20, Term: 3}}))
+	assert.Equal(t, uint64(0), s.size)
+	first, err = s.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(21), first)
+}
+
+func TestSpillingMemoryStorageWAL(t *testing.T) {
+	lg := flogging.NewFabricLogger(zap.NewExample())
+	dir, err := ioutil.TempDir("", "etcdraft-spill-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	walDir, snapDir := path.Join(dir, "wal"), path.Join(dir, "snapshot")
+
+	ram := NewSpillingMemoryStorage(30)
+	store, err := CreateStorage(lg, walDir, snapDir, ram, WALReadAheadBuffered, nil)
+	require.NoError(t, err)
+
+	data := func(i uint64) []byte { return []byte{byte(i), 1, 2, 3, 4, 5, 6, 7, 8, 9} }
+	for i := uint64(1); i <= 10; i++ {
+		entry := raftpb.Entry{Index: i, Term: 1, Data: data(i)}
+		require.NoError(t, store.Store([]raftpb.Entry{entry}, raftpb.HardState{Term: 1, Commit: i}, raftpb.Snapshot{}))
+	}
+	assert.Equal(t, uint64(7), ram.spilledTo)
+
+	assertEntries := func(ram *SpillingMemoryStorage, lo, hi uint64) {
+		var ents []raftpb.Entry
+		for len(ents) < int(hi-lo) {
+			next, err := ram.Entries(lo+uint64(len(ents)), hi, math.MaxUint64)
+			require.NoError(t, err)
+			ents = append(ents, next...)
+		}
+		for i, e := range ents {
+			assert.Equal(t, lo+uint64(i), e.Index)
+			assert.Equal(t, data(e.Index), e.Data)
+		}
+	}
+	assertEntries(ram, 1, 11)
+
+	// entries are read from the WAL at the latest snapshot preceding them
+	store.SnapshotCatchUpEntries = 4
+	require.NoError(t, store.TakeSnapshot(8, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+	for i := uint64(11); i <= 15; i++ {
+		entry := raftpb.Entry{Index: i, Term: 1, Data: data(i)}
+		require.NoError(t, store.Store([]raftpb.Entry{entry}, raftpb.HardState{Term: 1, Commit: i}, raftpb.Snapshot{}))
+	}
+	assert.Equal(t, uint64(12), ram.spilledTo)
+	assertEntries(ram, 5, 16)
+	require.NoError(t, store.Close())
+
+	// entries replayed from the WAL are spilled as well
+	ram = NewSpillingMemoryStorage(30)
+	store, err = CreateStorage(lg, walDir, snapDir, ram, WALReadAheadBuffered, nil)
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, uint64(12), ram.spilledTo)
+	assertEntries(ram, 9, 16)
+}
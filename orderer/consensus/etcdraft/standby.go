@@ -0,0 +1,55 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/pkg/errors"
+)
+
+// AddStandbyConsenter returns a copy of the given metadata with the consenter
+// added to the standby consenters. The consenter is validated upfront, so that
+// promoting it later on does not fail.
+func AddStandbyConsenter(md *etcdraft.ConfigMetadata, consenter *etcdraft.Consenter) (*etcdraft.ConfigMetadata, error) {
+	if err := ValidateConsenter(consenter); err != nil {
+		return nil, errors.Wrap(err, "invalid standby consenter")
+	}
+
+	updated := proto.Clone(md).(*etcdraft.ConfigMetadata)
+	updated.StandbyConsenters = append(updated.StandbyConsenters, consenter)
+	if err := MetadataHasDuplication(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// PromoteStandbyConsenter returns a copy of the given metadata where the standby
+// consenter with the given client TLS certificate is moved to the consenters.
+func PromoteStandbyConsenter(md *etcdraft.ConfigMetadata, clientTLSCert []byte) (*etcdraft.ConfigMetadata, error) {
+	updated := proto.Clone(md).(*etcdraft.ConfigMetadata)
+	for i, consenter := range updated.StandbyConsenters {
+		if !bytes.Equal(consenter.ClientTlsCert, clientTLSCert) {
+			continue
+		}
+		updated.StandbyConsenters = append(updated.StandbyConsenters[:i], updated.StandbyConsenters[i+1:]...)
+		updated.Consenters = append(updated.Consenters, consenter)
+		return updated, nil
+	}
+	return nil, errors.New("no standby consenter with the given client TLS certificate")
+}
+
+// ValidateConsenter checks that the consenter has an endpoint,
+// and that its TLS certificates are PEM encoded x509 certificates
+// which are currently valid, have strong keys and are signed with
+// a supported signature algorithm.
+func ValidateConsenter(consenter *etcdraft.Consenter) error {
+	return raftconfig.ValidateConsenter(consenter)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/crypto/tlsgen"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/orderer/consensus/mocks"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConsenter(t *testing.T, ca tlsgen.CA, host string) *etcdraft.Consenter {
+	serverCert, err := ca.NewServerCertKeyPair(host)
+	require.NoError(t, err)
+	clientCert, err := ca.NewClientCertKeyPair()
+	require.NoError(t, err)
+	return &etcdraft.Consenter{
+		Host:          host,
+		Port:          7050,
+		ServerTlsCert: serverCert.Cert,
+		ClientTlsCert: clientCert.Cert,
+	}
+}
+
+// selfSignedRSACert returns a PEM encoded self signed certificate
+// with an RSA key of the given size.
+func selfSignedRSACert(t *testing.T, bits int, sigAlg x509.SignatureAlgorithm, notBefore, notAfter time.Time) []byte {
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:       big.NewInt(1),
+		Subject:            pkix.Name{CommonName: "node-1.example.com"},
+		NotBefore:          notBefore,
+		NotAfter:           notAfter,
+		SignatureAlgorithm: sigAlg,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestStandbyConsenters(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	active := newConsenter(t, ca, "node-1.example.com")
+	standby := newConsenter(t, ca, "node-2.example.com")
+	md := &etcdraft.ConfigMetadata{
+		Consenters: []*etcdraft.Consenter{active},
+		Options:    &etcdraft.Options{TickInterval: "500ms"},
+	}
+
+	withStandby, err := AddStandbyConsenter(md, standby)
+	require.NoError(t, err)
+	assert.Empty(t, md.StandbyConsenters, "original metadata should not be modified")
+	assert.Len(t, withStandby.Consenters, 1)
+	require.Len(t, withStandby.StandbyConsenters, 1)
+	assert.Equal(t, standby.Host, withStandby.StandbyConsenters[0].Host)
+
+	_, err = AddStandbyConsenter(withStandby, standby)
+	assert.Contains(t, err.Error(), "duplicate consenter")
+
+	_, err = AddStandbyConsenter(md, active)
+	assert.Contains(t, err.Error(), "duplicate consenter")
+
+	_, err = AddStandbyConsenter(md, &etcdraft.Consenter{Host: "node-3.example.com", Port: 7050, ClientTlsCert: []byte("garbage")})
+	assert.EqualError(t, err, "invalid standby consenter: invalid client TLS certificate: no PEM data found")
+
+	promoted, err := PromoteStandbyConsenter(withStandby, standby.ClientTlsCert)
+	require.NoError(t, err)
+	assert.Len(t, withStandby.StandbyConsenters, 1, "original metadata should not be modified")
+	assert.Empty(t, promoted.StandbyConsenters)
+	require.Len(t, promoted.Consenters, 2)
+	assert.Equal(t, standby.Host, promoted.Consenters[1].Host)
+	assert.Equal(t, "500ms", promoted.Options.TickInterval)
+
+	// promotion is a single consenter addition
+	changes, err := ComputeMembershipChanges(&etcdraft.BlockMetadata{
+		Consenters:      map[uint64]*etcdraft.Consenter{1: active},
+		NextConsenterId: 2,
+	}, promoted.Consenters)
+	require.NoError(t, err)
+	assert.Len(t, changes.AddedNodes, 1)
+	assert.Empty(t, changes.RemovedNodes)
+
+	_, err = PromoteStandbyConsenter(promoted, standby.ClientTlsCert)
+	assert.EqualError(t, err, "no standby consenter with the given client TLS certificate")
+}
+
+func TestValidateConsenter(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	valid := newConsenter(t, ca, "node-1.example.com")
+	assert.NoError(t, ValidateConsenter(valid))
+
+	now := time.Now()
+	rsaValid := *valid
+	rsaValid.ServerTlsCert = selfSignedRSACert(t, 2048, x509.SHA256WithRSA, now.Add(-time.Hour), now.Add(time.Hour))
+	assert.NoError(t, ValidateConsenter(&rsaValid))
+
+	for _, testCase := range []struct {
+		name        string
+		mutate      func(c *etcdraft.Consenter)
+		expectedErr string
+	}{
+		{
+			name:        "no host",
+			mutate:      func(c *etcdraft.Consenter) { c.Host = "" },
+			expectedErr: "consenter has no host",
+		},
+		{
+			name:        "root host",
+			mutate:      func(c *etcdraft.Consenter) { c.Host = "." },
+			expectedErr: "consenter has no host",
+		},
+		{
+			name:        "no port",
+			mutate:      func(c *etcdraft.Consenter) { c.Port = 0 },
+			expectedErr: "consenter has no port",
+		},
+		{
+			name:        "invalid client certificate",
+			mutate:      func(c *etcdraft.Consenter) { c.ClientTlsCert = nil },
+			expectedErr: "invalid client TLS certificate: no PEM data found",
+		},
+		{
+			name: "invalid server certificate",
+			mutate: func(c *etcdraft.Consenter) {
+				c.ServerTlsCert = []byte("-----BEGIN CERTIFICATE-----\nAAAA\n-----END CERTIFICATE-----\n")
+			},
+			expectedErr: "invalid server TLS certificate: x509:",
+		},
+		{
+			name: "expired certificate",
+			mutate: func(c *etcdraft.Consenter) {
+				c.ServerTlsCert = selfSignedRSACert(t, 2048, x509.SHA256WithRSA, now.Add(-2*time.Hour), now.Add(-time.Hour))
+			},
+			expectedErr: "invalid server TLS certificate: certificate expired at",
+		},
+		{
+			name: "certificate not yet valid",
+			mutate: func(c *etcdraft.Consenter) {
+				c.ClientTlsCert = selfSignedRSACert(t, 2048, x509.SHA256WithRSA, now.Add(time.Hour), now.Add(2*time.Hour))
+			},
+			expectedErr: "invalid client TLS certificate: certificate is not valid before",
+		},
+		{
+			name: "weak RSA key",
+			mutate: func(c *etcdraft.Consenter) {
+				c.ServerTlsCert = selfSignedRSACert(t, 1024, x509.SHA256WithRSA, now.Add(-time.Hour), now.Add(time.Hour))
+			},
+			expectedErr: "invalid server TLS certificate: RSA key of 1024 bits is weaker than the required 2048 bits",
+		},
+		{
+			name: "unsupported signature algorithm",
+			mutate: func(c *etcdraft.Consenter) {
+				c.ServerTlsCert = selfSignedRSACert(t, 2048, x509.SHA1WithRSA, now.Add(-time.Hour), now.Add(time.Hour))
+			},
+			expectedErr: "invalid server TLS certificate: unsupported signature algorithm SHA1-RSA",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			c := *valid
+			testCase.mutate(&c)
+			err := ValidateConsenter(&c)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), testCase.expectedErr)
+		})
+	}
+}
+
+func TestConsentersSetErrorsCertificates(t *testing.T) {
+	ca, err := tlsgen.NewCA()
+	require.NoError(t, err)
+
+	now := time.Now()
+	consenterWithCert := func(host string, notBefore, notAfter time.Time) *etcdraft.Consenter {
+		c := newConsenter(t, ca, host)
+		c.ServerTlsCert = selfSignedRSACert(t, 2048, x509.SHA256WithRSA, notBefore, notAfter)
+		return c
+	}
+
+	// two consenters of the channel have expired certificates
+	current := []*etcdraft.Consenter{
+		consenterWithCert("node-1.example.com", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+		consenterWithCert("node-2.example.com", now.Add(-2*time.Hour), now.Add(-time.Hour)),
+		consenterWithCert("node-3.example.com", now.Add(-time.Hour), now.Add(time.Hour)),
+	}
+
+	support := &mocks.FakeConsenterSupport{}
+	support.SharedConfigReturns(&mockconfig.Orderer{
+		ConsensusMetadataVal: utils.MarshalOrPanic(&etcdraft.ConfigMetadata{Consenters: current}),
+	})
+	clock := fakeclock.NewFakeClock(now)
+	c := &Chain{clock: clock, support: support}
+	c.blockMetadata.Store(&etcdraft.BlockMetadata{
+		Consenters:      map[uint64]*etcdraft.Consenter{1: current[0], 2: current[1], 3: current[2]},
+		NextConsenterId: 4,
+	})
+
+	rotate := func(consenter *etcdraft.Consenter) *etcdraft.ConfigMetadata {
+		rotated := *consenter
+		return &etcdraft.ConfigMetadata{Consenters: []*etcdraft.Consenter{&rotated, current[1], current[2]}}
+	}
+
+	// the expired certificates are rotated one at a time
+	renewed := consenterWithCert("node-1.example.com", now.Add(-time.Hour), now.Add(time.Hour))
+	renewed.ClientTlsCert = current[0].ClientTlsCert
+	assert.Empty(t, c.consentersSetErrors(rotate(renewed)))
+
+	// whereas the certificates of added or changed consenters are validated
+	expired := consenterWithCert("node-1.example.com", now.Add(-2*time.Hour), now.Add(-time.Minute))
+	expired.ClientTlsCert = current[0].ClientTlsCert
+	errs := c.consentersSetErrors(rotate(expired))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "invalid consenter node-1.example.com:7050: invalid server TLS certificate: certificate expired at")
+
+	// at the time of the chain clock
+	clock.Increment(2 * time.Hour)
+	errs = c.consentersSetErrors(rotate(renewed))
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "invalid consenter node-1.example.com:7050: invalid server TLS certificate: certificate expired at")
+}
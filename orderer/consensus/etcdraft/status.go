@@ -0,0 +1,101 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"go.etcd.io/etcd/raft"
+)
+
+// untrackedProgressState is reported as the progress state of
+// the peers which this node no longer tracks the progress of.
+const untrackedProgressState = -1
+
+// statusReporter periodically polls the status of a raft node
+// and publishes it as metrics. Metrics exported by it complement
+// the application level metrics with the view of etcd/raft itself.
+type statusReporter struct {
+	interval time.Duration
+	clock    clock.Clock
+	status   func() raft.Status
+	metrics  *Metrics
+	lag      *lagTracker
+	doneC    <-chan struct{}
+
+	// reportEstimate, if set, publishes the estimated time to order,
+	// which ages along with the pending batch and is hence polled too.
+	reportEstimate func()
+
+	// evaluateDegraded, if set, classifies slow nodes as degraded
+	// by the latencies observed up to the polled status.
+	evaluateDegraded func(raft.Status)
+
+	// tracked are the peers whose progress state is reported, which is reset
+	// once this node stops leading or the peers are removed from the cluster.
+	tracked map[uint64]struct{}
+}
+
+// run polls the raft node status every interval until doneC is closed.
+func (sr *statusReporter) run() {
+	ticker := sr.clock.NewTicker(sr.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			s := sr.status()
+			sr.report(s)
+			if sr.reportEstimate != nil {
+				sr.reportEstimate()
+			}
+			if sr.evaluateDegraded != nil {
+				sr.evaluateDegraded(s)
+			}
+		case <-sr.doneC:
+			return
+		}
+	}
+}
+
+// report publishes the given raft status. Progress of peers is only
+// tracked by the leader, hence followers only report their own state,
+// and reset the progress state of the peers they tracked as the leader.
+// The leader also samples the lag of its followers.
+func (sr *statusReporter) report(s raft.Status) {
+	sr.metrics.Term.Set(float64(s.Term))
+	sr.metrics.CommitIndex.Set(float64(s.Commit))
+	sr.metrics.AppliedIndex.Set(float64(s.Applied))
+
+	lastIndex := s.Progress[s.ID].Match
+	for id, pr := range s.Progress {
+		if id == s.ID {
+			continue
+		}
+		sr.metrics.PeerProgressState.With("peer", strconv.FormatUint(id, 10)).Set(float64(pr.State))
+		if sr.tracked == nil {
+			sr.tracked = make(map[uint64]struct{})
+		}
+		sr.tracked[id] = struct{}{}
+
+		// Followers which are down, or are sent a snapshot anyway,
+		// would not be helped by preserving entries for them.
+		if sr.lag == nil || !pr.RecentActive || pr.State == raft.ProgressStateSnapshot || pr.Match > lastIndex {
+			continue
+		}
+		sr.lag.observe(lastIndex - pr.Match)
+	}
+
+	for id := range sr.tracked {
+		if _, exists := s.Progress[id]; !exists || id == s.ID {
+			sr.metrics.PeerProgressState.With("peer", strconv.FormatUint(id, 10)).Set(untrackedProgressState)
+			delete(sr.tracked, id)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestStatusReporter(t *testing.T) {
+	term := &metricsfakes.Gauge{}
+	commit := &metricsfakes.Gauge{}
+	applied := &metricsfakes.Gauge{}
+	progress := &metricsfakes.Gauge{}
+	progress.WithReturns(progress)
+
+	status := raft.Status{
+		ID:        1,
+		HardState: raftpb.HardState{Term: 3, Commit: 10},
+		Applied:   9,
+		Progress: map[uint64]raft.Progress{
+			1: {State: raft.ProgressStateReplicate},
+			2: {State: raft.ProgressStateSnapshot},
+		},
+	}
+
+	clock := fakeclock.NewFakeClock(time.Now())
+	doneC := make(chan struct{})
+	polled := make(chan struct{}, 1)
+	sr := &statusReporter{
+		interval: time.Second,
+		clock:    clock,
+		status: func() raft.Status {
+			select {
+			case polled <- struct{}{}:
+			default:
+			}
+			return status
+		},
+		metrics: &Metrics{
+			Term:              term,
+			CommitIndex:       commit,
+			AppliedIndex:      applied,
+			PeerProgressState: progress,
+		},
+		doneC: doneC,
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		sr.run()
+		close(stopped)
+	}()
+
+	clock.WaitForWatcherAndIncrement(time.Second)
+	<-polled
+	close(doneC)
+	<-stopped
+
+	assert.True(t, term.SetCallCount() > 0)
+	assert.Equal(t, float64(3), term.SetArgsForCall(0))
+	assert.Equal(t, float64(10), commit.SetArgsForCall(0))
+	assert.Equal(t, float64(9), applied.SetArgsForCall(0))
+
+	// progress of the node itself is not reported
+	assert.Equal(t, []string{"peer", "2"}, progress.WithArgsForCall(0))
+	assert.Equal(t, float64(raft.ProgressStateSnapshot), progress.SetArgsForCall(0))
+}
+
+func TestStatusReporterSamplesLag(t *testing.T) {
+	progress := &metricsfakes.Gauge{}
+	progress.WithReturns(progress)
+
+	sr := &statusReporter{
+		metrics: &Metrics{
+			Term:              &metricsfakes.Gauge{},
+			CommitIndex:       &metricsfakes.Gauge{},
+			AppliedIndex:      &metricsfakes.Gauge{},
+			PeerProgressState: progress,
+		},
+		lag: &lagTracker{},
+	}
+
+	sr.report(raft.Status{
+		ID: 1,
+		Progress: map[uint64]raft.Progress{
+			1: {Match: 100, State: raft.ProgressStateReplicate},
+			2: {Match: 60, State: raft.ProgressStateReplicate, RecentActive: true},
+			3: {Match: 10, State: raft.ProgressStateReplicate},                   // down
+			4: {Match: 0, State: raft.ProgressStateSnapshot, RecentActive: true}, // catches up from snapshot
+		},
+	})
+
+	assert.Equal(t, []uint64{40}, sr.lag.samples)
+}
+
+func TestStatusReporterResetsUntrackedPeers(t *testing.T) {
+	progress := &metricsfakes.Gauge{}
+	progress.WithReturns(progress)
+
+	sr := &statusReporter{
+		metrics: &Metrics{
+			Term:              &metricsfakes.Gauge{},
+			CommitIndex:       &metricsfakes.Gauge{},
+			AppliedIndex:      &metricsfakes.Gauge{},
+			PeerProgressState: progress,
+		},
+	}
+
+	sr.report(raft.Status{
+		ID: 1,
+		Progress: map[uint64]raft.Progress{
+			1: {State: raft.ProgressStateReplicate},
+			2: {State: raft.ProgressStateReplicate},
+			3: {State: raft.ProgressStateReplicate},
+		},
+	})
+	assert.Equal(t, 2, progress.SetCallCount())
+
+	// peer 3 is removed from the cluster
+	sr.report(raft.Status{
+		ID: 1,
+		Progress: map[uint64]raft.Progress{
+			1: {State: raft.ProgressStateReplicate},
+			2: {State: raft.ProgressStateProbe},
+		},
+	})
+	assert.Equal(t, 4, progress.SetCallCount())
+	assert.Equal(t, []string{"peer", "3"}, progress.WithArgsForCall(3))
+	assert.Equal(t, float64(untrackedProgressState), progress.SetArgsForCall(3))
+
+	// this node stops leading
+	sr.report(raft.Status{ID: 1})
+	assert.Equal(t, 5, progress.SetCallCount())
+	assert.Equal(t, []string{"peer", "2"}, progress.WithArgsForCall(4))
+	assert.Equal(t, float64(untrackedProgressState), progress.SetArgsForCall(4))
+
+	sr.report(raft.Status{ID: 1})
+	assert.Equal(t, 5, progress.SetCallCount())
+}
@@ -0,0 +1,88 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+// Reasons raft messages received from cluster peers are dropped for.
+const (
+	DropReasonSize   = "size"
+	DropReasonSender = "sender"
+	DropReasonType   = "type"
+	DropReasonTerm   = "term"
+	DropReasonIndex  = "index"
+)
+
+// MalformedMessageError is returned for a raft message received from a
+// cluster peer which is dropped rather than stepped into the raft node.
+type MalformedMessageError struct {
+	Reason string
+	Err    error
+}
+
+func (e *MalformedMessageError) Error() string {
+	return e.Err.Error()
+}
+
+func malformed(reason, format string, args ...interface{}) *MalformedMessageError {
+	return &MalformedMessageError{Reason: reason, Err: errors.Errorf(format, args...)}
+}
+
+// validateStepMessage checks the raft message received from the given cluster peer
+// before it is stepped into the raft node of the given node, whose raft log ends at
+// the given index, since etcd/raft trusts the messages of its peers, and panics on
+// some of the malformed ones. Messages must come from the peer which sends them,
+// be addressed to the node, not be local to raft, and carry terms and indexes which
+// are consistent with each other. Heartbeats must not commit entries beyond the end
+// of the raft log, which etcd/raft treats as a corrupted log.
+func validateStepMessage(msg *raftpb.Message, sender, self, lastIndex uint64) *MalformedMessageError {
+	if msg.From != sender {
+		return malformed(DropReasonSender, "raft message is from node %d, but was sent by node %d", msg.From, sender)
+	}
+	if msg.To != self {
+		return malformed(DropReasonSender, "raft message is addressed to node %d, not to node %d", msg.To, self)
+	}
+	if raft.IsLocalMsg(msg.Type) {
+		return malformed(DropReasonType, "raft message of type %s is local to the raft node", msg.Type)
+	}
+
+	if msg.LogTerm > msg.Term {
+		return malformed(DropReasonTerm, "raft message of term %d refers to the later term %d", msg.Term, msg.LogTerm)
+	}
+	if uint64(len(msg.Entries)) > math.MaxUint64-msg.Index {
+		return malformed(DropReasonIndex, "raft message carries %d entries after index %d", len(msg.Entries), msg.Index)
+	}
+	for i, entry := range msg.Entries {
+		if entry.Index != msg.Index+uint64(i)+1 {
+			return malformed(DropReasonIndex, "entry %d of raft message at index %d has index %d", i, msg.Index, entry.Index)
+		}
+		if entry.Term > msg.Term {
+			return malformed(DropReasonTerm, "entry %d of raft message of term %d has the later term %d", entry.Index, msg.Term, entry.Term)
+		}
+	}
+
+	if msg.Type == raftpb.MsgHeartbeat && msg.Commit > lastIndex {
+		return malformed(DropReasonIndex, "heartbeat commits index %d, beyond the last index %d", msg.Commit, lastIndex)
+	}
+
+	return nil
+}
+
+// dropMessage counts the raft message from the given peer dropped
+// for the given reason, and returns the error it is answered with.
+func (c *Chain) dropMessage(sender uint64, err *MalformedMessageError) error {
+	c.logger.Warnf("Dropped raft message from node %d: %s", sender, err)
+	c.Metrics.ConsensusMsgsDropped.With("sender", strconv.FormatUint(sender, 10), "reason", err.Reason).Add(1)
+	return err
+}
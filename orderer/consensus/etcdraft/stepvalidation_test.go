@@ -0,0 +1,90 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestValidateStepMessage(t *testing.T) {
+	app := func(index uint64, entries ...raftpb.Entry) *raftpb.Message {
+		return &raftpb.Message{Type: raftpb.MsgApp, From: 1, To: 2, Term: 3, LogTerm: 2, Index: index, Entries: entries}
+	}
+
+	for _, test := range []struct {
+		name           string
+		msg            *raftpb.Message
+		expectedReason string
+		expectedErr    string
+	}{
+		{name: "append", msg: app(5, raftpb.Entry{Index: 6, Term: 2}, raftpb.Entry{Index: 7, Term: 3})},
+		{name: "vote", msg: &raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2, Term: 4, LogTerm: 3, Index: 20}},
+		{name: "heartbeat", msg: &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 3, Commit: 10}},
+		{
+			name:           "forged sender",
+			msg:            &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 3, To: 2, Term: 3},
+			expectedReason: DropReasonSender,
+			expectedErr:    "raft message is from node 3, but was sent by node 1",
+		},
+		{
+			name:           "other recipient",
+			msg:            &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 3, Term: 3},
+			expectedReason: DropReasonSender,
+			expectedErr:    "raft message is addressed to node 3, not to node 2",
+		},
+		{
+			name:           "local message",
+			msg:            &raftpb.Message{Type: raftpb.MsgBeat, From: 1, To: 2},
+			expectedReason: DropReasonType,
+			expectedErr:    "raft message of type MsgBeat is local to the raft node",
+		},
+		{
+			name:           "later log term",
+			msg:            &raftpb.Message{Type: raftpb.MsgVote, From: 1, To: 2, Term: 3, LogTerm: 4},
+			expectedReason: DropReasonTerm,
+			expectedErr:    "raft message of term 3 refers to the later term 4",
+		},
+		{
+			name:           "entry of later term",
+			msg:            app(5, raftpb.Entry{Index: 6, Term: 4}),
+			expectedReason: DropReasonTerm,
+			expectedErr:    "entry 6 of raft message of term 3 has the later term 4",
+		},
+		{
+			name:           "gap between entries",
+			msg:            app(5, raftpb.Entry{Index: 6, Term: 3}, raftpb.Entry{Index: 8, Term: 3}),
+			expectedReason: DropReasonIndex,
+			expectedErr:    "entry 1 of raft message at index 5 has index 8",
+		},
+		{
+			name:           "index overflow",
+			msg:            app(math.MaxUint64, raftpb.Entry{Index: 0, Term: 3}),
+			expectedReason: DropReasonIndex,
+			expectedErr:    "raft message carries 1 entries after index 18446744073709551615",
+		},
+		{
+			name:           "heartbeat beyond the log",
+			msg:            &raftpb.Message{Type: raftpb.MsgHeartbeat, From: 1, To: 2, Term: 3, Commit: 11},
+			expectedReason: DropReasonIndex,
+			expectedErr:    "heartbeat commits index 11, beyond the last index 10",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateStepMessage(test.msg, 1, 2, 10)
+			if test.expectedErr == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, test.expectedReason, err.Reason)
+			assert.EqualError(t, err, test.expectedErr)
+		})
+	}
+}
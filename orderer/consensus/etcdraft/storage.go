@@ -15,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
 	"github.com/pkg/errors"
 	"go.etcd.io/etcd/etcdserver/api/snap"
 	"go.etcd.io/etcd/pkg/fileutil"
@@ -28,9 +29,14 @@ import (
 // on filesystem. Snapshot files are read from newest to oldest, until first
 // intact file is found. The more snapshot files we keep around, the more we
 // mitigate the impact of a corrupted snapshots. This is exported for testing
-// purpose. This MUST be greater equal than 1.
+// purpose. This MUST be greater equal than 1. It is retained unless the
+// SnapshotRetention of the RaftStorage is set.
 var MaxSnapshotFiles = 5
 
+// compactionChunkEntries is the number of in-memory raft entries
+// purged at a time when a snapshot is taken.
+const compactionChunkEntries = 1000
+
 // MemoryStorage is currently backed by etcd/raft.MemoryStorage. This interface is
 // defined to expose dependencies of fsm so that it may be swapped in the
 // future. TODO(jay) Add other necessary methods to this interface once we need
@@ -48,6 +54,37 @@ type MemoryStorage interface {
 type RaftStorage struct {
 	SnapshotCatchUpEntries uint64
 
+	// SnapshotRetention is the number of snapshot files retained on disk,
+	// along with the WAL files they need. Older files are deleted once a
+	// snapshot is taken. MaxSnapshotFiles are retained if it is not set.
+	SnapshotRetention int
+
+	// ReclaimedBytes, if set, counts the bytes of the files deleted.
+	ReclaimedBytes metrics.Counter
+
+	// WALFsyncs, WALAppendedBytes, WALSegmentsCreated and SnapshotFilesWritten,
+	// if set, count the fsyncs of the WAL, the bytes of the entries and hard
+	// states appended to it, the WAL segments it rolls over to, and the
+	// snapshot files written.
+	WALFsyncs            metrics.Counter
+	WALAppendedBytes     metrics.Counter
+	WALSegmentsCreated   metrics.Counter
+	SnapshotFilesWritten metrics.Counter
+
+	// WALSync is the way the data appended to the WAL is synced,
+	// which is WALSyncFdatasync, as the WAL syncs it, if not set.
+	WALSync WALSync
+
+	// catchUpEntries, if set, overrides SnapshotCatchUpEntries with
+	// a number of entries computed at the time a snapshot is taken.
+	catchUpEntries func() uint64
+
+	// compactionChunk is the number of in-memory raft entries purged at
+	// a time, which is compactionChunkEntries if it is not set, and yield,
+	// if set, is called between the chunks of a compaction.
+	compactionChunk uint64
+	yield           func()
+
 	walDir  string
 	snapDir string
 
@@ -57,19 +94,39 @@ type RaftStorage struct {
 	wal  *wal.WAL
 	snap *snap.Snapshotter
 
+	// walTail is the last WAL segment, synced with fsync if WALSync is WALSyncFsync
+	walTail *walTail
+
+	// stager keeps the blocks of the entries out of the WAL, if set
+	stager *blockStager
+
 	// a queue that keeps track of indices of snapshots on disk
 	snapshotIndex []uint64
+
+	walState      raftpb.HardState // last hard state saved in the WAL
+	walSegment    uint64           // sequence of the last WAL segment observed
+	uncheckedSize int64            // bytes appended since the WAL segments were last listed
 }
 
-// CreateStorage attempts to create a storage to persist etcd/raft data.
-// If data presents in specified disk, they are loaded to reconstruct storage state.
+// CreateStorage attempts to create a storage to persist etcd/raft data, once the
+// files left over by an unclean shutdown are cleaned up from the specified disk.
+// If data presents in specified disk, they are loaded to reconstruct storage state,
+// and the WAL is read ahead as specified by readAhead while it is replayed. Blocks
+// are staged out of the WAL by the stager, and restored by it when the WAL is
+// replayed. The WAL holds blocks, and cannot hold references, if it is nil.
 func CreateStorage(
 	lg *flogging.FabricLogger,
 	walDir string,
 	snapDir string,
 	ram MemoryStorage,
+	readAhead WALReadAhead,
+	stager *blockStager,
 ) (*RaftStorage, error) {
 
+	if _, err := tidyStorage(lg, walDir, snapDir); err != nil {
+		return nil, err
+	}
+
 	sn, err := createSnapshotter(lg, snapDir)
 	if err != nil {
 		return nil, err
@@ -88,7 +145,7 @@ func CreateStorage(
 			snapshot.Metadata.Term, snapshot.Metadata.Index, snapshot.Metadata.ConfState.Nodes)
 	}
 
-	w, st, ents, err := createOrReadWAL(lg, walDir, snapshot)
+	w, st, ents, err := createOrReadWAL(lg, walDir, snapshot, readAhead)
 	if err != nil {
 		return nil, errors.Errorf("failed to create or read WAL: %s", err)
 	}
@@ -103,6 +160,15 @@ func CreateStorage(
 	lg.Debugf("Setting HardState to {Term: %d, Commit: %d}", st.Term, st.Commit)
 	ram.SetHardState(st) // MemoryStorage.SetHardState always returns nil
 
+	if err := stager.restoreEntries(ents); err != nil {
+		w.Close()
+		return nil, errors.Errorf("failed to restore blocks referenced by the WAL: %s", err)
+	}
+
+	if spilling, ok := ram.(*SpillingMemoryStorage); ok {
+		spilling.setReader(walEntryReader(lg, walDir, snapDir, stager))
+	}
+
 	lg.Debugf("Appending %d entries to memory storage", len(ents))
 	ram.Append(ents) // MemoryStorage.Append always return nil
 
@@ -114,9 +180,46 @@ func CreateStorage(
 		walDir:        walDir,
 		snapDir:       snapDir,
 		snapshotIndex: ListSnapshots(lg, snapDir),
+		stager:        stager,
+		walState:      st,
+		walSegment:    lastWALSegment(lg, walDir),
+		walTail:       &walTail{walDir: walDir},
 	}, nil
 }
 
+// lastWALSegment returns the sequence of the last segment of the WAL in the given directory.
+func lastWALSegment(lg *flogging.FabricLogger, walDir string) uint64 {
+	walFiles, err := fileutil.ReadDir(walDir)
+	if err != nil {
+		lg.Errorf("Failed to read WAL directory %s: %s", walDir, err)
+		return 0
+	}
+
+	var last uint64
+	for _, f := range walFiles {
+		if !strings.HasSuffix(f, ".wal") {
+			continue
+		}
+
+		var seq, index uint64
+		fmt.Sscanf(f, "%016x-%016x.wal", &seq, &index)
+		if seq > last {
+			last = seq
+		}
+	}
+	return last
+}
+
+// CreateMemoryStorage creates a storage which keeps etcd/raft data in memory only,
+// neither in a WAL nor in snapshot files, hence the data is lost once the process
+// exits. It is meant for development and testing.
+func CreateMemoryStorage(lg *flogging.FabricLogger, ram MemoryStorage) *RaftStorage {
+	return &RaftStorage{
+		lg:  lg,
+		ram: ram,
+	}
+}
+
 // ListSnapshots returns a list of RaftIndex of snapshots stored on disk.
 // If a file is corrupted, rename the file.
 func ListSnapshots(logger *flogging.FabricLogger, snapDir string) []uint64 {
@@ -172,8 +275,9 @@ func createSnapshotter(logger *flogging.FabricLogger, snapDir string) (*snap.Sna
 	return snap.New(logger.Zap(), snapDir), nil
 }
 
-func createOrReadWAL(lg *flogging.FabricLogger, walDir string, snapshot *raftpb.Snapshot) (w *wal.WAL, st raftpb.HardState, ents []raftpb.Entry, err error) {
-	if !wal.Exist(walDir) {
+func createOrReadWAL(lg *flogging.FabricLogger, walDir string, snapshot *raftpb.Snapshot, readAhead WALReadAhead) (w *wal.WAL, st raftpb.HardState, ents []raftpb.Entry, err error) {
+	fresh := !wal.Exist(walDir)
+	if fresh {
 		lg.Infof("No WAL data found, creating new WAL at path '%s'", walDir)
 		// TODO(jay_guo) add metadata to be persisted with wal once we need it.
 		// use case could be data dump and restore on a new node.
@@ -200,6 +304,11 @@ func createOrReadWAL(lg *flogging.FabricLogger, walDir string, snapshot *raftpb.
 
 	lg.Debugf("Loading WAL at Term %d and Index %d", walsnap.Term, walsnap.Index)
 
+	if !fresh {
+		stopReadAhead := readAheadWAL(lg, walDir, walsnap.Index, readAhead)
+		defer stopReadAhead()
+	}
+
 	var repaired bool
 	for {
 		if w, err = wal.Open(lg.Zap(), walDir, walsnap); err != nil {
@@ -234,6 +343,65 @@ func createOrReadWAL(lg *flogging.FabricLogger, walDir string, snapshot *raftpb.
 	return w, st, ents, nil
 }
 
+// walEntryReader returns a function which reads the raft entries [lo, hi) from the WAL in
+// walDir, which is opened for reading at the latest snapshot in snapDir preceding lo, and
+// restores the blocks referenced by them with the stager. The WAL is read in its entirety
+// from the snapshot onwards, hence the reads are meant to be infrequent.
+func walEntryReader(lg *flogging.FabricLogger, walDir, snapDir string, stager *blockStager) func(lo, hi uint64) ([]raftpb.Entry, error) {
+	return func(lo, hi uint64) ([]raftpb.Entry, error) {
+		ents, err := readWALEntries(lg, walDir, walSnapshotBefore(lg, snapDir, lo), lo, hi)
+		if err == nil {
+			err = stager.restoreEntries(ents)
+		}
+		if err != nil {
+			lg.Errorf("Failed to read raft entries %d to %d from the WAL: %s", lo, hi-1, err)
+			return nil, err
+		}
+		return ents, nil
+	}
+}
+
+func readWALEntries(lg *flogging.FabricLogger, walDir string, walsnap walpb.Snapshot, lo, hi uint64) ([]raftpb.Entry, error) {
+	w, err := wal.OpenForRead(lg.Zap(), walDir, walsnap)
+	if err != nil {
+		return nil, errors.Errorf("failed to open WAL at index %d: %s", walsnap.Index, err)
+	}
+	defer w.Close()
+
+	_, _, ents, err := w.ReadAll()
+	if err != nil {
+		return nil, errors.Errorf("failed to read WAL from index %d: %s", walsnap.Index, err)
+	}
+	if len(ents) == 0 || ents[0].Index > lo || ents[len(ents)-1].Index < hi-1 {
+		return nil, errors.Errorf("WAL holds %d entries from index %d onwards", len(ents), walsnap.Index+1)
+	}
+	return ents[lo-ents[0].Index : hi-ents[0].Index], nil
+}
+
+// walSnapshotBefore returns the WAL record of the latest snapshot in snapDir which
+// precedes the given index, or the record the WAL is created with if there is none.
+func walSnapshotBefore(lg *flogging.FabricLogger, snapDir string, index uint64) walpb.Snapshot {
+	var walsnap walpb.Snapshot
+	snapFiles, err := fileutil.ReadDir(snapDir)
+	if err != nil {
+		lg.Errorf("Failed to read snapshot directory %s: %s", snapDir, err)
+		return walsnap
+	}
+	for _, f := range snapFiles {
+		if !strings.HasSuffix(f, ".snap") {
+			continue
+		}
+		var term, i uint64
+		if _, err := fmt.Sscanf(f, "%016x-%016x.snap", &term, &i); err != nil {
+			continue
+		}
+		if i < index && i > walsnap.Index {
+			walsnap = walpb.Snapshot{Index: i, Term: term}
+		}
+	}
+	return walsnap
+}
+
 // Snapshot returns the latest snapshot stored in memory
 func (rs *RaftStorage) Snapshot() raftpb.Snapshot {
 	sn, _ := rs.ram.Snapshot() // Snapshot always returns nil error
@@ -242,8 +410,31 @@ func (rs *RaftStorage) Snapshot() raftpb.Snapshot {
 
 // Store persists etcd/raft data
 func (rs *RaftStorage) Store(entries []raftpb.Entry, hardstate raftpb.HardState, snapshot raftpb.Snapshot) error {
-	if err := rs.wal.Save(hardstate, entries); err != nil {
-		return err
+	if rs.wal != nil {
+		// the blocks are staged before the WAL references them,
+		// while the memory storage keeps holding the blocks
+		walEntries, err := rs.stager.walEntries(entries)
+		if err != nil {
+			return err
+		}
+
+		mustSync := !(raft.IsEmptyHardState(hardstate) && len(walEntries) == 0) &&
+			raft.MustSync(hardstate, rs.walState, len(walEntries))
+		if err := rs.wal.Save(hardstate, walEntries); err != nil {
+			return err
+		}
+		rs.countWALSave(hardstate, walEntries)
+
+		if mustSync && rs.WALSync == WALSyncFsync {
+			if err := rs.walTail.sync(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !raft.IsEmptyHardState(hardstate) {
+		// the memory storage learns which entries are committed
+		rs.ram.SetHardState(hardstate) // MemoryStorage.SetHardState always returns nil
 	}
 
 	if !raft.IsEmptySnap(snapshot) {
@@ -269,6 +460,10 @@ func (rs *RaftStorage) Store(entries []raftpb.Entry, hardstate raftpb.HardState,
 }
 
 func (rs *RaftStorage) saveSnap(snap raftpb.Snapshot) error {
+	if rs.wal == nil {
+		return nil
+	}
+
 	// must save the snapshot index to the WAL before saving the
 	// snapshot to maintain the invariant that we only Open the
 	// wal at previously-saved snapshot indexes.
@@ -281,11 +476,13 @@ func (rs *RaftStorage) saveSnap(snap raftpb.Snapshot) error {
 	if err := rs.wal.SaveSnapshot(walsnap); err != nil {
 		return errors.Errorf("failed to save snapshot to WAL: %s", err)
 	}
+	addToCounter(rs.WALFsyncs, 1)
 
 	rs.lg.Debugf("Saving snapshot to disk")
 	if err := rs.snap.SaveSnap(snap); err != nil {
 		return errors.Errorf("failed to save snapshot to disk: %s", err)
 	}
+	addToCounter(rs.SnapshotFilesWritten, 1)
 
 	rs.lg.Debugf("Releasing lock to wal files prior to %d", snap.Metadata.Index)
 	if err := rs.wal.ReleaseLockTo(snap.Metadata.Index); err != nil {
@@ -295,6 +492,46 @@ func (rs *RaftStorage) saveSnap(snap raftpb.Snapshot) error {
 	return nil
 }
 
+// countWALSave counts the fsync and the bytes of saving the given hard state and entries
+// in the WAL, as well as the segments the WAL rolled over to. The WAL rolls over once its
+// last segment exceeds wal.SegmentSizeBytes, hence its segments are listed only once an
+// eighth of that many bytes are appended since they were last listed.
+func (rs *RaftStorage) countWALSave(st raftpb.HardState, ents []raftpb.Entry) {
+	if raft.IsEmptyHardState(st) && len(ents) == 0 {
+		return // the WAL skips saving
+	}
+
+	if raft.MustSync(st, rs.walState, len(ents)) {
+		addToCounter(rs.WALFsyncs, 1)
+	}
+
+	var size int
+	for i := range ents {
+		size += ents[i].Size()
+	}
+	if !raft.IsEmptyHardState(st) {
+		size += st.Size()
+		rs.walState = st
+	}
+	addToCounter(rs.WALAppendedBytes, float64(size))
+
+	rs.uncheckedSize += int64(size)
+	if rs.uncheckedSize*8 < wal.SegmentSizeBytes {
+		return
+	}
+	rs.uncheckedSize = 0
+	if segment := lastWALSegment(rs.lg, rs.walDir); segment > rs.walSegment {
+		addToCounter(rs.WALSegmentsCreated, float64(segment-rs.walSegment))
+		rs.walSegment = segment
+	}
+}
+
+func addToCounter(c metrics.Counter, delta float64) {
+	if c != nil {
+		c.Add(delta)
+	}
+}
+
 // TakeSnapshot takes a snapshot at index i from MemoryStorage, and persists it to wal and disk.
 func (rs *RaftStorage) TakeSnapshot(i uint64, cs raftpb.ConfState, data []byte) error {
 	rs.lg.Debugf("Creating snapshot at index %d from MemoryStorage", i)
@@ -310,16 +547,12 @@ func (rs *RaftStorage) TakeSnapshot(i uint64, cs raftpb.ConfState, data []byte)
 	rs.snapshotIndex = append(rs.snapshotIndex, snap.Metadata.Index)
 
 	// Keep some entries in memory for slow followers to catchup
-	if i > rs.SnapshotCatchUpEntries {
-		compacti := i - rs.SnapshotCatchUpEntries
-		rs.lg.Debugf("Purging in-memory raft entries prior to %d", compacti)
-		if err = rs.ram.Compact(compacti); err != nil {
-			if err == raft.ErrCompacted {
-				rs.lg.Warnf("Raft entries prior to %d are already purged", compacti)
-			} else {
-				rs.lg.Fatalf("Failed to purge raft entries: %s", err)
-			}
-		}
+	catchUpEntries := rs.SnapshotCatchUpEntries
+	if rs.catchUpEntries != nil {
+		catchUpEntries = rs.catchUpEntries()
+	}
+	if i > catchUpEntries {
+		rs.compact(i - catchUpEntries)
 	}
 
 	rs.lg.Infof("Snapshot is taken at index %d", i)
@@ -328,15 +561,57 @@ func (rs *RaftStorage) TakeSnapshot(i uint64, cs raftpb.ConfState, data []byte)
 	return nil
 }
 
+// compact purges the in-memory raft entries prior to the given index in chunks,
+// so that the entries appended to the memory storage by the node in the meantime
+// are not held off until all of them are purged.
+func (rs *RaftStorage) compact(compacti uint64) {
+	rs.lg.Debugf("Purging in-memory raft entries prior to %d", compacti)
+	first, err := rs.ram.FirstIndex()
+	if err != nil {
+		rs.lg.Fatalf("Failed to purge raft entries: %s", err)
+	}
+	if compacti < first {
+		rs.lg.Warnf("Raft entries prior to %d are already purged", compacti)
+		return
+	}
+
+	chunk := rs.compactionChunk
+	if chunk == 0 {
+		chunk = compactionChunkEntries
+	}
+	for next := first - 1; next < compacti; {
+		next += chunk
+		if next > compacti {
+			next = compacti
+		}
+		if err := rs.ram.Compact(next); err != nil {
+			if err == raft.ErrCompacted {
+				rs.lg.Warnf("Raft entries prior to %d are already purged", next)
+				continue
+			}
+			rs.lg.Fatalf("Failed to purge raft entries: %s", err)
+		}
+		if next < compacti && rs.yield != nil {
+			rs.yield()
+		}
+	}
+}
+
 // gc collects etcd/raft garbage files, namely wal and snapshot files
 func (rs *RaftStorage) gc() {
-	if len(rs.snapshotIndex) < MaxSnapshotFiles {
+	if rs.wal == nil {
+		rs.snapshotIndex = nil
+		return
+	}
+
+	retention := rs.retention()
+	if len(rs.snapshotIndex) < retention {
 		rs.lg.Debugf("Snapshots on disk (%d) < limit (%d), no need to purge wal/snapshot",
-			len(rs.snapshotIndex), MaxSnapshotFiles)
+			len(rs.snapshotIndex), retention)
 		return
 	}
 
-	rs.snapshotIndex = rs.snapshotIndex[len(rs.snapshotIndex)-MaxSnapshotFiles:]
+	rs.snapshotIndex = rs.snapshotIndex[len(rs.snapshotIndex)-retention:]
 
 	rs.purgeWAL()
 	rs.purgeSnap()
@@ -394,11 +669,20 @@ func (rs *RaftStorage) purgeSnap() {
 	}
 
 	l := len(files)
-	if l <= MaxSnapshotFiles {
+	retention := rs.retention()
+	if l <= retention {
 		return
 	}
 
-	rs.purge(files[:l-MaxSnapshotFiles]) // retain last MaxSnapshotFiles snapshot files
+	rs.purge(files[:l-retention]) // retain the last snapshot files
+}
+
+// retention returns the number of snapshot files retained on disk.
+func (rs *RaftStorage) retention() int {
+	if rs.SnapshotRetention > 0 {
+		return rs.SnapshotRetention
+	}
+	return MaxSnapshotFiles
 }
 
 func (rs *RaftStorage) purge(files []string) {
@@ -409,10 +693,16 @@ func (rs *RaftStorage) purge(files []string) {
 			break
 		}
 
+		var size int64
+		if info, err := os.Stat(file); err == nil {
+			size = info.Size()
+		}
+
 		if err = os.Remove(file); err != nil {
 			rs.lg.Errorf("Failed to remove %s: %s", file, err)
 		} else {
-			rs.lg.Debugf("Purged file %s", file)
+			rs.lg.Debugf("Purged file %s (%d bytes)", file, size)
+			addToCounter(rs.ReclaimedBytes, float64(size))
 		}
 
 		if err = l.Close(); err != nil {
@@ -435,9 +725,13 @@ func (rs *RaftStorage) ApplySnapshot(snap raftpb.Snapshot) {
 
 // Close closes storage
 func (rs *RaftStorage) Close() error {
+	if rs.wal == nil {
+		return nil
+	}
+
 	if err := rs.wal.Close(); err != nil {
 		return err
 	}
 
-	return nil
+	return rs.walTail.close()
 }
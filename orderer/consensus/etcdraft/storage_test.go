@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/pkg/fileutil"
@@ -41,7 +42,7 @@ func setup(t *testing.T) {
 	dataDir, err = ioutil.TempDir("", "etcdraft-")
 	assert.NoError(t, err)
 	walDir, snapDir = path.Join(dataDir, "wal"), path.Join(dataDir, "snapshot")
-	store, err = CreateStorage(logger, walDir, snapDir, ram)
+	store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadBuffered, nil)
 	assert.NoError(t, err)
 }
 
@@ -108,7 +109,7 @@ func TestOpenWAL(t *testing.T) {
 
 		// create new storage
 		ram = raft.NewMemoryStorage()
-		store, err = CreateStorage(logger, walDir, snapDir, ram)
+		store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadBuffered, nil)
 		require.NoError(t, err)
 		lastI, _ := store.ram.LastIndex()
 		assert.True(t, lastI > 0)     // we are still able to read some entries
@@ -177,7 +178,7 @@ func TestTakeSnapshot(t *testing.T) {
 			err = store.Close()
 			assert.NoError(t, err)
 			ram := raft.NewMemoryStorage()
-			store, err = CreateStorage(logger, walDir, snapDir, ram)
+			store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadBuffered, nil)
 			assert.NoError(t, err)
 
 			store.TakeSnapshot(uint64(7), raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
@@ -233,7 +234,7 @@ func TestTakeSnapshot(t *testing.T) {
 			err = store.Close()
 			assert.NoError(t, err)
 			ram := raft.NewMemoryStorage()
-			store, err = CreateStorage(logger, walDir, snapDir, ram)
+			store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadBuffered, nil)
 			assert.NoError(t, err)
 
 			// Two snapshots at index 5, 7. And we keep one extra wal file prior to oldest snapshot.
@@ -246,6 +247,48 @@ func TestTakeSnapshot(t *testing.T) {
 			store.TakeSnapshot(uint64(9), raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
 			assertFileCount(t, 5, 2)
 		})
+		t.Run("SnapshotRetention==2", func(t *testing.T) {
+			setup(t)
+			defer clean(t)
+
+			store.SnapshotRetention = 2
+			reclaimed := &metricsfakes.Counter{}
+			store.ReclaimedBytes = reclaimed
+
+			// set SegmentSizeBytes to a small value so that
+			// every entry persisted to wal would result in
+			// a new wal being created.
+			oldSegmentSizeBytes := wal.SegmentSizeBytes
+			wal.SegmentSizeBytes = 10
+			defer func() {
+				wal.SegmentSizeBytes = oldSegmentSizeBytes
+			}()
+
+			// create 10 new wal files
+			for i := 0; i < 10; i++ {
+				store.Store(
+					[]raftpb.Entry{{Index: uint64(i), Data: make([]byte, 100)}},
+					raftpb.HardState{},
+					raftpb.Snapshot{},
+				)
+			}
+
+			assertFileCount(t, 11, 0)
+
+			store.TakeSnapshot(uint64(3), raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
+			store.TakeSnapshot(uint64(5), raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
+			assertFileCount(t, 9, 2)
+			assert.Equal(t, 2, reclaimed.AddCallCount())
+
+			// The retention takes precedence over MaxSnapshotFiles, and the
+			// sizes of the purged snapshot and WAL files are reclaimed.
+			store.TakeSnapshot(uint64(7), raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
+			assertFileCount(t, 7, 2)
+			assert.Equal(t, 5, reclaimed.AddCallCount())
+			for i := 0; i < reclaimed.AddCallCount(); i++ {
+				assert.True(t, reclaimed.AddArgsForCall(i) > 0)
+			}
+		})
 	})
 
 	t.Run("Bad", func(t *testing.T) {
@@ -309,7 +352,7 @@ func TestTakeSnapshot(t *testing.T) {
 			err = store.Close()
 			assert.NoError(t, err)
 			ram := raft.NewMemoryStorage()
-			store, err = CreateStorage(logger, walDir, snapDir, ram)
+			store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadBuffered, nil)
 			assert.NoError(t, err)
 
 			// Corrupted snapshot file should've been renamed
@@ -327,3 +370,143 @@ func TestTakeSnapshot(t *testing.T) {
 		})
 	})
 }
+
+func TestStorageMetrics(t *testing.T) {
+	setup(t)
+	defer clean(t)
+
+	fsyncs := &metricsfakes.Counter{}
+	appended := &metricsfakes.Counter{}
+	segments := &metricsfakes.Counter{}
+	snapshots := &metricsfakes.Counter{}
+	store.WALFsyncs = fsyncs
+	store.WALAppendedBytes = appended
+	store.WALSegmentsCreated = segments
+	store.SnapshotFilesWritten = snapshots
+
+	// entries are synced, and so is a change of the hard state
+	entry := raftpb.Entry{Index: 1, Term: 1, Data: make([]byte, 100)}
+	hs := raftpb.HardState{Term: 1, Commit: 1}
+	require.NoError(t, store.Store([]raftpb.Entry{entry}, hs, raftpb.Snapshot{}))
+	assert.Equal(t, 1, fsyncs.AddCallCount())
+	assert.Equal(t, float64(entry.Size()+hs.Size()), appended.AddArgsForCall(0))
+
+	// a commit does not change the persisted hard state, hence it is not synced
+	require.NoError(t, store.Store(nil, raftpb.HardState{Term: 1, Commit: 2}, raftpb.Snapshot{}))
+	assert.Equal(t, 1, fsyncs.AddCallCount())
+	assert.Equal(t, 2, appended.AddCallCount())
+
+	// nothing is saved
+	require.NoError(t, store.Store(nil, raftpb.HardState{}, raftpb.Snapshot{}))
+	assert.Equal(t, 2, appended.AddCallCount())
+	assert.Equal(t, 0, segments.AddCallCount())
+
+	// set SegmentSizeBytes to a small value so that every
+	// entry persisted to wal rolls it over to a new segment
+	oldSegmentSizeBytes := wal.SegmentSizeBytes
+	wal.SegmentSizeBytes = 10
+	defer func() {
+		wal.SegmentSizeBytes = oldSegmentSizeBytes
+	}()
+
+	for i := uint64(2); i < 5; i++ {
+		require.NoError(t, store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 100)}}, raftpb.HardState{}, raftpb.Snapshot{}))
+	}
+	assertFileCount(t, 4, 0)
+	assert.Equal(t, 3, segments.AddCallCount())
+	for i := 0; i < segments.AddCallCount(); i++ {
+		assert.Equal(t, float64(1), segments.AddArgsForCall(i))
+	}
+
+	require.NoError(t, store.TakeSnapshot(3, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+	assert.Equal(t, 1, snapshots.AddCallCount())
+	assert.Equal(t, 5, fsyncs.AddCallCount())
+}
+
+func TestTakeSnapshotCatchUpEntries(t *testing.T) {
+	setup(t)
+	defer clean(t)
+
+	for i := uint64(1); i <= 10; i++ {
+		err := store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 10)}}, raftpb.HardState{}, raftpb.Snapshot{})
+		require.NoError(t, err)
+	}
+
+	store.SnapshotCatchUpEntries = 5
+	err := store.TakeSnapshot(6, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
+	require.NoError(t, err)
+	first, err := ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), first)
+
+	// the computed number of entries takes precedence
+	store.catchUpEntries = func() uint64 { return 2 }
+	err = store.TakeSnapshot(8, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10))
+	require.NoError(t, err)
+	first, err = ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), first)
+}
+
+func TestTakeSnapshotCompactsInChunks(t *testing.T) {
+	setup(t)
+	defer clean(t)
+
+	for i := uint64(1); i <= 10; i++ {
+		err := store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 10)}}, raftpb.HardState{}, raftpb.Snapshot{})
+		require.NoError(t, err)
+	}
+
+	// yield observes the first index between the chunks
+	var firsts []uint64
+	store.yield = func() {
+		first, err := ram.FirstIndex()
+		require.NoError(t, err)
+		firsts = append(firsts, first)
+	}
+	store.compactionChunk = 3
+	store.SnapshotCatchUpEntries = 1
+	require.NoError(t, store.TakeSnapshot(9, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+	first, err := ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), first)
+	assert.Equal(t, []uint64{4, 7}, firsts)
+
+	// entries already purged are skipped
+	firsts = nil
+	store.SnapshotCatchUpEntries = 3
+	require.NoError(t, store.TakeSnapshot(10, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+	first, err = ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(9), first)
+	assert.Empty(t, firsts)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	ram := raft.NewMemoryStorage()
+	store := CreateMemoryStorage(flogging.NewFabricLogger(zap.NewExample()), ram)
+	store.SnapshotCatchUpEntries = 2
+
+	for i := uint64(1); i <= 10; i++ {
+		err := store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 10)}}, raftpb.HardState{Term: 1, Commit: i}, raftpb.Snapshot{})
+		require.NoError(t, err)
+	}
+	last, err := ram.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), last)
+
+	for i := uint64(2); i <= 2*uint64(MaxSnapshotFiles); i += 2 {
+		require.NoError(t, store.TakeSnapshot(i, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+	}
+	assert.Equal(t, uint64(2*MaxSnapshotFiles), store.Snapshot().Metadata.Index)
+	first, err := ram.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2*MaxSnapshotFiles-1), first)
+
+	// a snapshot received from the leader replaces the entries
+	snapshot := raftpb.Snapshot{Metadata: raftpb.SnapshotMetadata{Index: 20, Term: 2, ConfState: raftpb.ConfState{Nodes: []uint64{1}}}}
+	require.NoError(t, store.Store(nil, raftpb.HardState{Term: 2, Commit: 20}, snapshot))
+	assert.Equal(t, uint64(20), store.Snapshot().Metadata.Index)
+
+	assert.NoError(t, store.Close())
+}
@@ -0,0 +1,92 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/pkg/fileutil"
+)
+
+// tidyStorage cleans up the files an unclean shutdown leaves in the WAL and
+// snapshot directories of a chain before they are opened, and returns the
+// actions taken, which are logged as well. It removes the WAL segments etcd
+// preallocates as .tmp files, which take up the size of a segment each, and
+// the zero-length files of snapshots which were not written out, which etcd
+// would otherwise move aside as broken snapshots whenever it loads them.
+// It fails if the WAL is locked, as it is in use by another process or chain,
+// instead of leaving it to etcd to fail to lock it.
+func tidyStorage(lg *flogging.FabricLogger, walDir, snapDir string) ([]string, error) {
+	var actions []string
+	remove := func(path, reason string) {
+		if err := os.Remove(path); err != nil {
+			lg.Warnf("Failed to remove %s %s: %s", reason, path, err)
+			return
+		}
+		action := fmt.Sprintf("removed %s %s", reason, path)
+		lg.Warnf("Storage cleanup: %s", action)
+		actions = append(actions, action)
+	}
+
+	walFiles, err := readDirIfExists(walDir)
+	if err != nil {
+		return nil, errors.Errorf("failed to read WAL directory %s: %s", walDir, err)
+	}
+	for _, f := range walFiles {
+		if !strings.HasSuffix(f, ".wal") && !strings.HasSuffix(f, ".tmp") {
+			continue
+		}
+		path := filepath.Join(walDir, f)
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		l, err := fileutil.TryLockFile(path, os.O_WRONLY, fileutil.PrivateFileMode)
+		if err == fileutil.ErrLocked {
+			return nil, errors.Errorf("WAL file %s is locked, the WAL is in use by another process or chain", path)
+		}
+		if err != nil {
+			return nil, errors.Errorf("failed to lock WAL file %s: %s", path, err)
+		}
+		if strings.HasSuffix(f, ".tmp") {
+			remove(path, "leftover preallocated WAL segment")
+		}
+		l.Close()
+	}
+
+	snapFiles, err := readDirIfExists(snapDir)
+	if err != nil {
+		return nil, errors.Errorf("failed to read snapshot directory %s: %s", snapDir, err)
+	}
+	for _, f := range snapFiles {
+		if !strings.HasSuffix(f, ".snap") {
+			continue
+		}
+		path := filepath.Join(snapDir, f)
+		if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() && info.Size() == 0 {
+			remove(path, "zero-length snapshot file")
+		}
+	}
+
+	if len(actions) > 0 {
+		lg.Warnf("Cleaned up %d files left over by an unclean shutdown", len(actions))
+	}
+	return actions, nil
+}
+
+// readDirIfExists returns the sorted names of the files in the given
+// directory, or none if there is no directory at the given path.
+func readDirIfExists(dir string) ([]string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	return fileutil.ReadDir(dir)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+)
+
+func TestTidyStorage(t *testing.T) {
+	setup(t)
+	defer clean(t)
+
+	// nothing to clean up in directories which do not exist yet
+	actions, err := tidyStorage(logger, filepath.Join(dataDir, "nowal"), filepath.Join(dataDir, "nosnap"))
+	assert.NoError(t, err)
+	assert.Empty(t, actions)
+
+	for i := uint64(1); i <= 10; i++ {
+		err := store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 100)}}, raftpb.HardState{Term: 1, Commit: i}, raftpb.Snapshot{})
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.TakeSnapshot(5, raftpb.ConfState{Nodes: []uint64{1}}, make([]byte, 10)))
+
+	// the WAL is locked as long as the storage is open
+	_, err = CreateStorage(logger, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, nil)
+	assert.Contains(t, fmt.Sprint(err), "is locked, the WAL is in use by another process or chain")
+	require.NoError(t, store.Close())
+
+	tmpSegment := filepath.Join(walDir, "1.tmp")
+	require.NoError(t, ioutil.WriteFile(tmpSegment, make([]byte, 1024), 0600))
+	emptySnapshot := filepath.Join(snapDir, "0000000000000001-0000000000000009.snap")
+	require.NoError(t, ioutil.WriteFile(emptySnapshot, nil, 0600))
+
+	actions, err = tidyStorage(logger, walDir, snapDir)
+	require.NoError(t, err)
+	assert.Contains(t, actions, "removed leftover preallocated WAL segment "+tmpSegment)
+	assert.Contains(t, actions, "removed zero-length snapshot file "+emptySnapshot)
+	_, err = os.Stat(tmpSegment)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(emptySnapshot)
+	assert.True(t, os.IsNotExist(err))
+
+	// the snapshot which was written out is kept and loaded
+	require.NoError(t, ioutil.WriteFile(emptySnapshot, nil, 0600))
+	ram = raft.NewMemoryStorage()
+	store, err = CreateStorage(logger, walDir, snapDir, ram, WALReadAheadNone, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{5}, store.snapshotIndex)
+	lastIndex, err := ram.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), lastIndex)
+	_, err = os.Stat(emptySnapshot + ".broken")
+	assert.True(t, os.IsNotExist(err))
+}
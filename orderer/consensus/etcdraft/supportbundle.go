@@ -0,0 +1,360 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"go.etcd.io/etcd/raft"
+)
+
+// eventHistorySize is the number of recent events of a chain
+// which are kept for support bundles.
+const eventHistorySize = 100
+
+// SupportBundle is a snapshot of the configuration and state of a chain,
+// meant to be attached to bug reports. The Options are sanitized, so that
+// the bundle holds nothing but the public certificates of the consenters.
+type SupportBundle struct {
+	Channel        string            `json:"channel"`
+	RaftID         uint64            `json:"raft_id"`
+	Time           time.Time         `json:"time"`
+	Options        BundleOptions     `json:"options"`
+	ChannelOptions *etcdraft.Options `json:"channel_options"`
+	BlockMetadata  json.RawMessage   `json:"block_metadata"`
+	Status         BundleStatus      `json:"status"`
+	Membership     MembershipDrift   `json:"membership"`
+	Metrics        BundleMetrics     `json:"metrics"`
+	Events         []Event           `json:"events"`
+	FlightRecord   []RecordedMessage `json:"flight_record,omitempty"`
+}
+
+// BundleOptions are the Options of a chain, without the
+// certificate, storage and collaborators of the node.
+type BundleOptions struct {
+	WALDir                    string         `json:"wal_dir"`
+	SnapDir                   string         `json:"snap_dir"`
+	InMemoryStorage           bool           `json:"in_memory_storage"`
+	SnapInterval              uint32         `json:"snap_interval"`
+	SnapshotRetention         int            `json:"snapshot_retention"`
+	SnapshotContent           string         `json:"snapshot_content"`
+	RaftMemoryLimit           uint64         `json:"raft_memory_limit,omitempty"`
+	WALReadAhead              string         `json:"wal_read_ahead"`
+	WALSync                   string         `json:"wal_sync"`
+	StagingDir                string         `json:"staging_dir,omitempty"`
+	SnapshotCatchUpEntries    uint64         `json:"snapshot_catch_up_entries"`
+	TickInterval              string         `json:"tick_interval"`
+	ElectionTick              int            `json:"election_tick"`
+	HeartbeatTick             int            `json:"heartbeat_tick"`
+	MaxSizePerMsg             uint64         `json:"max_size_per_msg"`
+	MaxInflightMsgs           int            `json:"max_inflight_msgs"`
+	EvictionSuspicion         string         `json:"eviction_suspicion"`
+	LeaderCheckInterval       string         `json:"leader_check_interval"`
+	StatusReportInterval      string         `json:"status_report_interval"`
+	BlockVerificationInterval string         `json:"block_verification_interval"`
+	MaxBlockInterval          string         `json:"max_block_interval"`
+	MaxCommitBacklog          uint64         `json:"max_commit_backlog"`
+	MaxInflightBytes          uint64         `json:"max_inflight_bytes"`
+	MaxPendingConfigs         int            `json:"max_pending_configs"`
+	MaxReplayEntries          uint64         `json:"max_replay_entries"`
+	ElectionStormThreshold    int            `json:"election_storm_threshold"`
+	ElectionStormWindow       string         `json:"election_storm_window"`
+	WatchdogTimeout           string         `json:"watchdog_timeout"`
+	ConfChangeTimeout         string         `json:"conf_change_timeout"`
+	ConfChangeRetryInterval   string         `json:"conf_change_retry_interval"`
+	ConfChangeMaxAttempts     int            `json:"conf_change_max_attempts"`
+	SnapshotDeferralLatency   string         `json:"snapshot_deferral_latency"`
+	MaxSnapshotDeferral       string         `json:"max_snapshot_deferral"`
+	SlowConfigThreshold       string         `json:"slow_config_threshold"`
+	DegradedLatency           string         `json:"degraded_latency"`
+	MaxBlockTimeSkew          string         `json:"max_block_time_skew"`
+	ReorderWindow             string         `json:"reorder_window"`
+	FlightRecorderSize        int            `json:"flight_recorder_size"`
+	MaxConsensusMessageBytes  uint64         `json:"max_consensus_message_bytes"`
+	Quotas                    Quotas         `json:"quotas"`
+	ReceiptStream             bool           `json:"receipt_stream"`
+	EventStream               bool           `json:"event_stream"`
+	RecentBlocks              int            `json:"recent_blocks"`
+	RejectSystemChannelTxs    bool           `json:"reject_system_channel_txs"`
+	FairOrdering              bool           `json:"fair_ordering"`
+	IngressShares             map[string]int `json:"ingress_shares,omitempty"`
+	StateHash                 bool           `json:"state_hash"`
+	ProposalForwarding        bool           `json:"proposal_forwarding"`
+	DisablePreVote            bool           `json:"disable_pre_vote"`
+	DisableCheckQuorum        bool           `json:"disable_check_quorum"`
+	Archive                   bool           `json:"archive"`
+	Notifier                  bool           `json:"notifier"`
+	TrustAuditLog             bool           `json:"trust_audit_log"`
+	OnBlockCommitted          bool           `json:"on_block_committed"`
+	// Features are the versions of the wire features supported by this node.
+	Features map[string]uint32 `json:"features,omitempty"`
+}
+
+// BundleStatus is the raft status of a node. Progress of
+// the peers is only known by the leader.
+type BundleStatus struct {
+	Role     string                    `json:"role"`
+	Term     uint64                    `json:"term"`
+	Vote     uint64                    `json:"vote"`
+	Leader   uint64                    `json:"leader"`
+	Commit   uint64                    `json:"commit"`
+	Applied  uint64                    `json:"applied"`
+	Progress map[uint64]BundleProgress `json:"progress,omitempty"`
+}
+
+// BundleProgress is the replication progress of a peer, as tracked by the leader.
+type BundleProgress struct {
+	Match        uint64 `json:"match"`
+	Next         uint64 `json:"next"`
+	State        string `json:"state"`
+	RecentActive bool   `json:"recent_active"`
+	Paused       bool   `json:"paused"`
+}
+
+// BundleMetrics are the values of the chain metrics
+// at the time the bundle was taken.
+type BundleMetrics struct {
+	Height         uint64         `json:"height"`
+	IsPaused       bool           `json:"is_paused"`
+	CommittedIndex uint64         `json:"committed_index"`
+	WrittenIndex   uint64         `json:"written_index"`
+	CommitBacklog  uint64         `json:"commit_backlog"`
+	LastCommitTime time.Time      `json:"last_commit_time"`
+	Goroutines     map[string]int `json:"goroutines"`
+	PendingConfigs int            `json:"pending_configs"`
+}
+
+// eventHistory keeps the most recent events of a chain.
+type eventHistory struct {
+	lock   sync.Mutex
+	events []Event
+	next   int
+}
+
+func (eh *eventHistory) record(event Event) {
+	eh.lock.Lock()
+	defer eh.lock.Unlock()
+
+	if len(eh.events) < eventHistorySize {
+		eh.events = append(eh.events, event)
+		return
+	}
+	eh.events[eh.next] = event
+	eh.next = (eh.next + 1) % eventHistorySize
+}
+
+// recent returns the recorded events, oldest first.
+func (eh *eventHistory) recent() []Event {
+	eh.lock.Lock()
+	defer eh.lock.Unlock()
+
+	events := make([]Event, 0, len(eh.events))
+	events = append(events, eh.events[eh.next:]...)
+	return append(events, eh.events[:eh.next]...)
+}
+
+// SupportBundle returns a snapshot of the configuration and state of the chain.
+func (c *Chain) SupportBundle() (*SupportBundle, error) {
+	blockMetadata, err := etcdraft.MarshalBlockMetadataJSON(c.raftMetadata())
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &SupportBundle{
+		Channel:       c.channelID,
+		RaftID:        c.raftID,
+		Time:          c.clock.Now().UTC(),
+		Options:       c.bundleOptions(),
+		BlockMetadata: blockMetadata,
+		Status:        BundleStatus{Role: "stopped"},
+		Membership:    c.MembershipDrift(),
+		Metrics: BundleMetrics{
+			Height:         c.support.Height(),
+			IsPaused:       c.Paused(),
+			CommittedIndex: c.Node.committed(),
+			WrittenIndex:   atomic.LoadUint64(&c.writtenIndex),
+			CommitBacklog:  c.commitBacklog(),
+			Goroutines:     c.Goroutines(),
+			PendingConfigs: c.configQueue.size(),
+		},
+		Events:       c.events.recent(),
+		FlightRecord: c.FlightRecord(),
+	}
+
+	configMetadata := &etcdraft.ConfigMetadata{}
+	if err := proto.Unmarshal(c.support.SharedConfig().ConsensusMetadata(), configMetadata); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consensus metadata: %s", err)
+	}
+	bundle.ChannelOptions = configMetadata.Options
+
+	if c.isRunning() == nil {
+		bundle.Status = bundleStatus(c.Node.Status())
+	}
+
+	if t := atomic.LoadInt64(&c.lastCommitTime); t != 0 {
+		bundle.Metrics.LastCommitTime = time.Unix(0, t).UTC()
+	}
+
+	return bundle, nil
+}
+
+func (c *Chain) bundleOptions() BundleOptions {
+	var raftMemoryLimit uint64
+	if spilling, ok := c.opts.MemoryStorage.(*SpillingMemoryStorage); ok {
+		raftMemoryLimit = spilling.limit
+	}
+	return BundleOptions{
+		WALDir:                    c.opts.WALDir,
+		SnapDir:                   c.opts.SnapDir,
+		InMemoryStorage:           c.opts.InMemoryStorage,
+		SnapInterval:              c.opts.SnapInterval,
+		SnapshotRetention:         c.opts.SnapshotRetention,
+		SnapshotContent:           string(c.opts.SnapshotContent),
+		RaftMemoryLimit:           raftMemoryLimit,
+		WALReadAhead:              string(c.opts.WALReadAhead),
+		WALSync:                   string(c.opts.WALSync),
+		StagingDir:                c.opts.StagingDir,
+		SnapshotCatchUpEntries:    c.opts.SnapshotCatchUpEntries,
+		TickInterval:              c.opts.TickInterval.String(),
+		ElectionTick:              c.opts.ElectionTick,
+		HeartbeatTick:             c.opts.HeartbeatTick,
+		MaxSizePerMsg:             c.opts.MaxSizePerMsg,
+		MaxInflightMsgs:           c.opts.MaxInflightMsgs,
+		EvictionSuspicion:         c.opts.EvictionSuspicion.String(),
+		LeaderCheckInterval:       c.opts.LeaderCheckInterval.String(),
+		StatusReportInterval:      c.opts.StatusReportInterval.String(),
+		BlockVerificationInterval: c.opts.BlockVerificationInterval.String(),
+		MaxBlockInterval:          c.opts.MaxBlockInterval.String(),
+		MaxCommitBacklog:          c.opts.MaxCommitBacklog,
+		MaxInflightBytes:          c.opts.MaxInflightBytes,
+		MaxPendingConfigs:         c.opts.MaxPendingConfigs,
+		MaxReplayEntries:          c.opts.MaxReplayEntries,
+		ElectionStormThreshold:    c.opts.ElectionStormThreshold,
+		ElectionStormWindow:       c.opts.ElectionStormWindow.String(),
+		WatchdogTimeout:           c.opts.WatchdogTimeout.String(),
+		ConfChangeTimeout:         c.opts.ConfChangeTimeout.String(),
+		ConfChangeRetryInterval:   c.opts.ConfChangeRetryInterval.String(),
+		ConfChangeMaxAttempts:     c.opts.ConfChangeMaxAttempts,
+		SnapshotDeferralLatency:   c.opts.SnapshotDeferralLatency.String(),
+		MaxSnapshotDeferral:       c.opts.MaxSnapshotDeferral.String(),
+		SlowConfigThreshold:       c.opts.SlowConfigThreshold.String(),
+		DegradedLatency:           c.opts.DegradedLatency.String(),
+		MaxBlockTimeSkew:          c.opts.MaxBlockTimeSkew.String(),
+		ReorderWindow:             c.opts.ReorderWindow.String(),
+		FlightRecorderSize:        c.opts.FlightRecorderSize,
+		MaxConsensusMessageBytes:  c.opts.MaxConsensusMessageBytes,
+		Quotas:                    c.opts.Quotas,
+		ReceiptStream:             c.opts.ReceiptStream,
+		EventStream:               c.opts.EventStream,
+		RecentBlocks:              c.opts.RecentBlocks,
+		RejectSystemChannelTxs:    c.opts.RejectSystemChannelTxs,
+		FairOrdering:              c.opts.FairOrdering,
+		IngressShares:             c.opts.IngressShares,
+		StateHash:                 c.opts.StateHash,
+		ProposalForwarding:        c.opts.ProposalForwarding,
+		DisablePreVote:            c.opts.DisablePreVote,
+		DisableCheckQuorum:        c.opts.DisableCheckQuorum,
+		Archive:                   c.opts.ArchiveFetcher != nil,
+		Notifier:                  c.opts.Notifier != nil,
+		TrustAuditLog:             c.opts.TrustAuditLog != nil,
+		OnBlockCommitted:          c.opts.OnBlockCommitted != nil,
+		Features:                  c.opts.Features,
+	}
+}
+
+func bundleStatus(s raft.Status) BundleStatus {
+	status := BundleStatus{
+		Role:    raftRole(s.RaftState),
+		Term:    s.Term,
+		Vote:    s.Vote,
+		Leader:  s.Lead,
+		Commit:  s.Commit,
+		Applied: s.Applied,
+	}
+	if len(s.Progress) == 0 {
+		return status
+	}
+
+	status.Progress = make(map[uint64]BundleProgress, len(s.Progress))
+	for id, pr := range s.Progress {
+		status.Progress[id] = BundleProgress{
+			Match:        pr.Match,
+			Next:         pr.Next,
+			State:        pr.State.String(),
+			RecentActive: pr.RecentActive,
+			Paused:       pr.Paused,
+		}
+	}
+	return status
+}
+
+// supportBundleHandler serves the support bundle of the
+// etcdraft chain of the channel given in the query.
+type supportBundleHandler struct {
+	consenter *Consenter
+}
+
+// SupportBundleHandler returns a handler serving the support bundle
+// of a chain as JSON, for requests of the form ?channel=<channel ID>.
+func (c *Consenter) SupportBundleHandler() http.Handler {
+	return &supportBundleHandler{consenter: c}
+}
+
+func (h *supportBundleHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		sendJSONError(resp, http.StatusMethodNotAllowed, fmt.Sprintf("invalid request method: %s", req.Method))
+		return
+	}
+
+	channelID := req.URL.Query().Get("channel")
+	if channelID == "" {
+		sendJSONError(resp, http.StatusBadRequest, "missing channel")
+		return
+	}
+
+	chain := h.consenter.etcdraftChain(channelID)
+	if chain == nil {
+		sendJSONError(resp, http.StatusNotFound, fmt.Sprintf("channel %s is not an etcdraft chain of this node", channelID))
+		return
+	}
+
+	bundle, err := chain.SupportBundle()
+	if err != nil {
+		sendJSONError(resp, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(resp).Encode(bundle); err != nil {
+		h.consenter.Logger.Errorw("failed to encode support bundle", "channel", channelID, "error", err)
+	}
+}
+
+// etcdraftChain returns the etcdraft chain of the given channel,
+// or nil if the channel is unknown or is not an etcdraft chain.
+func (c *Consenter) etcdraftChain(channelID string) *Chain {
+	cs := c.Chains.GetChain(channelID)
+	if cs == nil {
+		return nil
+	}
+	chain, _ := cs.Chain.(*Chain)
+	return chain
+}
+
+func sendJSONError(resp http.ResponseWriter, code int, msg string) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(code)
+	json.NewEncoder(resp).Encode(map[string]string{"error": msg})
+}
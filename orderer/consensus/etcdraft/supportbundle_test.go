@@ -0,0 +1,31 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHistory(t *testing.T) {
+	eh := &eventHistory{}
+	assert.Empty(t, eh.recent())
+
+	for i := uint64(1); i <= 3; i++ {
+		eh.record(Event{Block: i})
+	}
+	assert.Equal(t, []Event{{Block: 1}, {Block: 2}, {Block: 3}}, eh.recent())
+
+	for i := uint64(4); i <= eventHistorySize+5; i++ {
+		eh.record(Event{Block: i})
+	}
+	events := eh.recent()
+	assert.Len(t, events, eventHistorySize)
+	assert.Equal(t, uint64(6), events[0].Block)
+	assert.Equal(t, uint64(eventHistorySize+5), events[eventHistorySize-1].Block)
+}
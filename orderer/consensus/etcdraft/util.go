@@ -8,8 +8,13 @@ package etcdraft
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -17,10 +22,13 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/channelconfig"
 	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/common/localconfig"
 	"github.com/hyperledger/fabric/orderer/consensus"
+	"github.com/hyperledger/fabric/orderer/consensus/etcdraft/raftconfig"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
@@ -38,16 +46,22 @@ type MembershipChanges struct {
 	RemovedNodes     []*etcdraft.Consenter
 	ConfChange       *raftpb.ConfChange
 	RotatedNode      uint64
+	// MovedNodes are the IDs of the nodes whose endpoint changed
+	MovedNodes []uint64
 }
 
 // Stringer implements fmt.Stringer interface
 func (mc *MembershipChanges) String() string {
-	return fmt.Sprintf("add %d node(s), remove %d node(s)", len(mc.AddedNodes), len(mc.RemovedNodes))
+	s := fmt.Sprintf("add %d node(s), remove %d node(s)", len(mc.AddedNodes), len(mc.RemovedNodes))
+	if len(mc.MovedNodes) > 0 {
+		s += fmt.Sprintf(", move %d node(s)", len(mc.MovedNodes))
+	}
+	return s
 }
 
 // Changed indicates whether these changes actually do anything
 func (mc *MembershipChanges) Changed() bool {
-	return len(mc.AddedNodes) > 0 || len(mc.RemovedNodes) > 0
+	return len(mc.AddedNodes) > 0 || len(mc.RemovedNodes) > 0 || len(mc.MovedNodes) > 0
 }
 
 // Rotated indicates whether the change was a rotation
@@ -81,9 +95,11 @@ func lastConfigBlockFromSupport(support consensus.ConsenterSupport) (*common.Blo
 	return lastConfigBlock, nil
 }
 
-// newBlockPuller creates a new block puller
+// newBlockPuller creates a new block puller. Its connections are
+// shared with the block pullers of other channels if connPool is set.
 func newBlockPuller(support consensus.ConsenterSupport,
 	baseDialer *cluster.PredicateDialer,
+	connPool *cluster.ConnectionPool,
 	clusterConfig localconfig.Cluster) (BlockPuller, error) {
 
 	verifyBlockSequence := func(blocks []*common.Block, _ string) error {
@@ -95,18 +111,25 @@ func newBlockPuller(support consensus.ConsenterSupport,
 		return nil, err
 	}
 	secureConfig.AsyncConnect = false
-	stdDialer := &cluster.StandardDialer{
-		Dialer: cluster.NewTLSPinningDialer(secureConfig),
-	}
+	tlsDialer := cluster.NewTLSPinningDialer(secureConfig)
 
 	// Extract the TLS CA certs and endpoints from the configuration,
 	endpointConfig, err := EndpointconfigFromFromSupport(support)
 	if err != nil {
 		return nil, err
 	}
+	endpoints, err := endpointConfig.SelectEndpoints(cluster.EndpointStrategy(clusterConfig.ReplicationEndpoints))
+	if err != nil {
+		return nil, err
+	}
 	// and overwrite them.
 	secureConfig.SecOpts.ServerRootCAs = endpointConfig.TLSRootCAs
-	stdDialer.Dialer.SetConfig(secureConfig)
+	tlsDialer.SetConfig(secureConfig)
+
+	var dialer cluster.Dialer = &cluster.StandardDialer{Dialer: tlsDialer}
+	if connPool != nil {
+		dialer = connPool.Dialer(tlsDialer)
+	}
 
 	der, _ := pem.Decode(secureConfig.SecOpts.Certificate)
 	if der == nil {
@@ -120,11 +143,11 @@ func newBlockPuller(support consensus.ConsenterSupport,
 		RetryTimeout:        clusterConfig.ReplicationRetryTimeout,
 		MaxTotalBufferBytes: clusterConfig.ReplicationBufferSize,
 		FetchTimeout:        clusterConfig.ReplicationPullTimeout,
-		Endpoints:           endpointConfig.Endpoints,
+		Endpoints:           endpoints,
 		Signer:              support,
 		TLSCert:             der.Bytes,
 		Channel:             support.ChainID(),
-		Dialer:              stdDialer,
+		Dialer:              dialer,
 	}
 
 	return &LedgerBlockPuller{
@@ -163,8 +186,27 @@ func MembershipByCert(consenters map[uint64]*etcdraft.Consenter) map[string]stru
 	return set
 }
 
+// normalizeHost returns the host name in lower case, without a trailing dot.
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// SameEndpoint returns whether both consenters have the same endpoint. Host names
+// are compared case insensitively and regardless of a trailing dot, as both refer
+// to the same host in DNS.
+func SameEndpoint(a, b *etcdraft.Consenter) bool {
+	return a.Port == b.Port && normalizeHost(a.Host) == normalizeHost(b.Host)
+}
+
 // ComputeMembershipChanges computes membership update based on information about new conseters, returns
-// two slices: a slice of added consenters and a slice of consenters to be removed
+// two slices: a slice of added consenters and a slice of consenters to be removed.
+// A consenter is identified by its client TLS certificate: a new consenter with the same certificate as
+// an existing one is the same node, and if its endpoint differs, as defined by SameEndpoint, the node is
+// moved to the new endpoint. Consenters whose endpoints only differ in the case of their host name, or in
+// a trailing dot, are not moved, so that such superficial differences are not treated as changes.
+// Consenters bound to the MSP ID of an organization cannot be moved to another organization, neither
+// directly nor by the rotation of their certificate, whereas unbound consenters get bound once an MSP ID
+// is set for them.
 func ComputeMembershipChanges(oldMetadata *etcdraft.BlockMetadata, newConsenters []*etcdraft.Consenter) (*MembershipChanges, error) {
 	result := &MembershipChanges{
 		NewBlockMetadata: proto.Clone(oldMetadata).(*etcdraft.BlockMetadata),
@@ -177,12 +219,30 @@ func ComputeMembershipChanges(oldMetadata *etcdraft.BlockMetadata, newConsenters
 		result.NewBlockMetadata.Consenters = map[uint64]*etcdraft.Consenter{}
 	}
 
-	currentConsentersSet := MembershipByCert(oldMetadata.Consenters)
+	currentConsentersByCert := make(map[string]uint64, len(oldMetadata.Consenters))
+	for nodeID, c := range oldMetadata.Consenters {
+		currentConsentersByCert[string(c.ClientTlsCert)] = nodeID
+	}
 	for _, c := range newConsenters {
-		if _, exists := currentConsentersSet[string(c.ClientTlsCert)]; !exists {
+		nodeID, exists := currentConsentersByCert[string(c.ClientTlsCert)]
+		if !exists {
 			result.AddedNodes = append(result.AddedNodes, c)
+			continue
+		}
+		old := oldMetadata.Consenters[nodeID]
+		if err := checkOrgBinding(nodeID, old, c); err != nil {
+			return nil, err
+		}
+		if !SameEndpoint(old, c) {
+			result.MovedNodes = append(result.MovedNodes, nodeID)
+			result.NewBlockMetadata.Consenters[nodeID] = c
+		} else if old.MspId != c.MspId {
+			result.NewBlockMetadata.Consenters[nodeID] = c
 		}
 	}
+	sort.Slice(result.MovedNodes, func(i, j int) bool {
+		return result.MovedNodes[i] < result.MovedNodes[j]
+	})
 
 	var deletedNodeID uint64
 	newConsentersSet := ConsentersToMap(newConsenters)
@@ -196,6 +256,9 @@ func ComputeMembershipChanges(oldMetadata *etcdraft.BlockMetadata, newConsenters
 	switch {
 	case len(result.AddedNodes) == 1 && len(result.RemovedNodes) == 1:
 		// cert rotation
+		if err := checkOrgBinding(deletedNodeID, result.RemovedNodes[0], result.AddedNodes[0]); err != nil {
+			return nil, err
+		}
 		result.RotatedNode = deletedNodeID
 		result.NewBlockMetadata.Consenters[deletedNodeID] = result.AddedNodes[0]
 	case len(result.AddedNodes) == 1 && len(result.RemovedNodes) == 0:
@@ -225,34 +288,38 @@ func ComputeMembershipChanges(oldMetadata *etcdraft.BlockMetadata, newConsenters
 	return result, nil
 }
 
-// MetadataHasDuplication returns an error if the metadata has duplication of consenters.
-// A duplication is defined by having a server or a client TLS certificate that is found
-// in two different consenters, regardless of the type of certificate (client/server).
-func MetadataHasDuplication(md *etcdraft.ConfigMetadata) error {
-	if md == nil {
-		return errors.New("nil metadata")
-	}
-
-	for _, consenter := range md.Consenters {
-		if consenter == nil {
-			return errors.New("nil consenter in metadata")
+// consenterOrgs returns the MSP IDs of the organizations the given consenters
+// are bound to, by raft ID, or nil if none of them is bound to any.
+func consenterOrgs(consenters map[uint64]*etcdraft.Consenter) map[uint64]string {
+	var orgs map[uint64]string
+	for raftID, consenter := range consenters {
+		if consenter.MspId == "" {
+			continue
 		}
-	}
-
-	seen := make(map[string]struct{})
-	for _, consenter := range md.Consenters {
-		serverKey := string(consenter.ServerTlsCert)
-		clientKey := string(consenter.ClientTlsCert)
-		_, duplicateServerCert := seen[serverKey]
-		_, duplicateClientCert := seen[clientKey]
-		if duplicateServerCert || duplicateClientCert {
-			return errors.Errorf("duplicate consenter: server cert: %s, client cert: %s", serverKey, clientKey)
+		if orgs == nil {
+			orgs = make(map[uint64]string)
 		}
+		orgs[raftID] = consenter.MspId
+	}
+	return orgs
+}
 
-		seen[serverKey] = struct{}{}
-		seen[clientKey] = struct{}{}
+// checkOrgBinding returns an error if the consenter with the given raft ID,
+// which is bound to an organization, is updated to another organization.
+func checkOrgBinding(nodeID uint64, old, updated *etcdraft.Consenter) error {
+	if old.MspId == "" || old.MspId == updated.MspId {
+		return nil
 	}
-	return nil
+	return errors.Errorf("consenter %d is bound to organization %q and cannot be moved to organization %q", nodeID, old.MspId, updated.MspId)
+}
+
+// MetadataHasDuplication returns an error if the metadata has duplication of consenters.
+// A duplication is defined by having a server or a client TLS certificate that is found
+// in two different consenters, regardless of the type of certificate (client/server).
+// Standby and DR consenters are taken into account as well, except for DR consenters
+// which are listed as consenters too while they are being promoted.
+func MetadataHasDuplication(md *etcdraft.ConfigMetadata) error {
+	return raftconfig.MetadataHasDuplication(md)
 }
 
 // MetadataFromConfigValue reads and translates configuration updates from config value into raft metadata
@@ -360,6 +427,209 @@ func ConsensusMetadataFromConfigBlock(block *common.Block) (*etcdraft.ConfigMeta
 	return MetadataFromConfigUpdate(configUpdate)
 }
 
+// SnapshotBlock extracts the block a snapshot is taken at from the snapshot
+// payload, along with the archive reference embedded in it, if any.
+// Payloads of legacy snapshots consist of the plain block.
+func SnapshotBlock(data []byte) (*common.Block, *etcdraft.ArchiveReference, error) {
+	sd := &etcdraft.SnapshotData{}
+	if err := proto.Unmarshal(data, sd); err == nil && sd.Block != nil {
+		return sd.Block, sd.Archive, nil
+	}
+
+	block, err := utils.UnmarshalBlock(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if block.Header == nil {
+		return nil, nil, errors.New("block header is nil")
+	}
+	return block, nil, nil
+}
+
+// NextStateHash computes the state hash of the chain after the block with the
+// given header, carried by the raft entry at the given index, is applied on
+// top of the state with the previous hash.
+func NextStateHash(prev []byte, index uint64, header *common.BlockHeader) []byte {
+	idx := make([]byte, 8)
+	binary.BigEndian.PutUint64(idx, index)
+
+	h := sha256.New()
+	h.Write(prev)
+	h.Write(idx)
+	h.Write(header.Bytes())
+	return h.Sum(nil)
+}
+
+// VerifyStateHashes compares the state hashes carried by copies of the same block,
+// as written by different consenters. The blocks are keyed by the raft IDs of the
+// consenters. It returns an error describing the divergence if the hashes differ.
+func VerifyStateHashes(blocks map[uint64]*common.Block) error {
+	var ids []uint64
+	for id := range blocks {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var number uint64
+	var hashes []string
+	consentersByHash := make(map[string][]uint64)
+	for i, id := range ids {
+		block := blocks[id]
+		if block == nil || block.Header == nil {
+			return errors.Errorf("block of consenter %d is missing", id)
+		}
+		if i == 0 {
+			number = block.Header.Number
+		} else if block.Header.Number != number {
+			return errors.Errorf("block of consenter %d is block %d, expected block %d", id, block.Header.Number, number)
+		}
+
+		m, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_ORDERER)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read metadata of block %d of consenter %d", number, id)
+		}
+		raftMetadata := &etcdraft.BlockMetadata{}
+		if err := proto.Unmarshal(m.Value, raftMetadata); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal raft metadata of block %d of consenter %d", number, id)
+		}
+		if len(raftMetadata.StateHash) == 0 {
+			return errors.Errorf("block %d of consenter %d carries no state hash", number, id)
+		}
+
+		hash := hex.EncodeToString(raftMetadata.StateHash)
+		if _, exists := consentersByHash[hash]; !exists {
+			hashes = append(hashes, hash)
+		}
+		consentersByHash[hash] = append(consentersByHash[hash], id)
+	}
+
+	if len(hashes) <= 1 {
+		return nil
+	}
+
+	var groups []string
+	for _, hash := range hashes {
+		groups = append(groups, fmt.Sprintf("consenters %v have %s", consentersByHash[hash], hash))
+	}
+	return errors.Errorf("state of block %d diverges: %s", number, strings.Join(groups, ", "))
+}
+
+// BlockProvenance returns the raft term and ID of the leader which proposed
+// the given block, as recorded in its raft metadata when the channel options
+// enable it. It returns an error if the block carries no provenance.
+func BlockProvenance(block *common.Block) (*etcdraft.BlockProvenance, error) {
+	raftMetadata, err := blockRaftMetadata(block)
+	if err != nil {
+		return nil, err
+	}
+	if raftMetadata.Provenance == nil {
+		return nil, errors.Errorf("block %d carries no provenance", block.Header.Number)
+	}
+	return raftMetadata.Provenance, nil
+}
+
+// BlockTimestamp returns the time the leader which proposed the given block
+// created it at, as recorded in its raft metadata when the channel options
+// enable it. It returns an error if the block carries no timestamp. The
+// timestamp is verified with the signed data returned by BlockTimestampSignedData.
+func BlockTimestamp(block *common.Block) (*etcdraft.BlockTimestamp, error) {
+	raftMetadata, err := blockRaftMetadata(block)
+	if err != nil {
+		return nil, err
+	}
+	if raftMetadata.Timestamp == nil {
+		return nil, errors.Errorf("block %d carries no timestamp", block.Header.Number)
+	}
+	return raftMetadata.Timestamp, nil
+}
+
+// BlockTimestampSignedData returns the data the leader signed to attest
+// the timestamp of a block with the given header.
+func BlockTimestampSignedData(header *common.BlockHeader, timestamp *etcdraft.BlockTimestamp) *common.SignedData {
+	// a signature header which does not unmarshal yields
+	// no identity, which the signature is not verified with
+	sigHdr := &common.SignatureHeader{}
+	if err := proto.Unmarshal(timestamp.SignatureHeader, sigHdr); err != nil {
+		sigHdr.Reset()
+	}
+	return &common.SignedData{
+		Data:      blockTimestampBytes(header, timestamp),
+		Identity:  sigHdr.Creator,
+		Signature: timestamp.Signature,
+	}
+}
+
+func blockTimestampBytes(header *common.BlockHeader, timestamp *etcdraft.BlockTimestamp) []byte {
+	fields := make([]byte, 16)
+	binary.BigEndian.PutUint64(fields, uint64(timestamp.Time))
+	binary.BigEndian.PutUint64(fields[8:], timestamp.Proposer)
+	return util.ConcatenateBytes(header.Bytes(), fields, timestamp.SignatureHeader)
+}
+
+// signBlockTimestamp returns the timestamp of a block with the given header,
+// created at the given time by the given proposer, signed with the given signer.
+func signBlockTimestamp(header *common.BlockHeader, proposer uint64, t time.Time, signer crypto.LocalSigner) (*etcdraft.BlockTimestamp, error) {
+	sigHdr, err := signer.NewSignatureHeader()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create signature header")
+	}
+	timestamp := &etcdraft.BlockTimestamp{
+		Time:            t.UnixNano(),
+		Proposer:        proposer,
+		SignatureHeader: utils.MarshalOrPanic(sigHdr),
+	}
+	timestamp.Signature, err = signer.Sign(blockTimestampBytes(header, timestamp))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign block timestamp")
+	}
+	return timestamp, nil
+}
+
+// blockRaftMetadata returns the raft metadata of the given block,
+// which is empty if the block carries none.
+func blockRaftMetadata(block *common.Block) (*etcdraft.BlockMetadata, error) {
+	if block == nil || block.Header == nil {
+		return nil, errors.New("block header is nil")
+	}
+	raftMetadata := &etcdraft.BlockMetadata{}
+	if block.Metadata == nil || len(block.Metadata.Metadata) <= int(common.BlockMetadataIndex_ORDERER) {
+		return raftMetadata, nil
+	}
+	m, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_ORDERER)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read metadata of block %d", block.Header.Number)
+	}
+	if err := proto.Unmarshal(m.Value, raftMetadata); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal raft metadata of block %d", block.Header.Number)
+	}
+	return raftMetadata, nil
+}
+
+// stampProvenance records the given provenance in the raft metadata slot of
+// a block about to be proposed, which is filled in when the block is written.
+func stampProvenance(block *common.Block, provenance *etcdraft.BlockProvenance) {
+	stampProposal(block, func(m *etcdraft.BlockMetadata) { m.Provenance = provenance })
+}
+
+// stampTimestamp records the given timestamp in the raft metadata slot of
+// a block about to be proposed, which is filled in when the block is written.
+func stampTimestamp(block *common.Block, timestamp *etcdraft.BlockTimestamp) {
+	stampProposal(block, func(m *etcdraft.BlockMetadata) { m.Timestamp = timestamp })
+}
+
+// stampProposal updates the raft metadata slot of a block about
+// to be proposed, keeping what the proposer stamped in it before.
+func stampProposal(block *common.Block, update func(*etcdraft.BlockMetadata)) {
+	m, err := blockRaftMetadata(block)
+	if err != nil {
+		m = &etcdraft.BlockMetadata{}
+	}
+	update(m)
+	block.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(m),
+	})
+}
+
 // ConsenterCertificate denotes a TLS certificate of a consenter
 type ConsenterCertificate []byte
 
@@ -405,6 +675,19 @@ func SliceOfConsentersIDs(consenters map[uint64]*etcdraft.Consenter) []uint64 {
 	return result
 }
 
+// sameConsenters returns whether both sets hold the same consenters under the same IDs.
+func sameConsenters(a, b map[uint64]*etcdraft.Consenter) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for id, consenter := range a {
+		if !proto.Equal(consenter, b[id]) {
+			return false
+		}
+	}
+	return true
+}
+
 // NodeExists returns trues if node id exists in the slice
 // and false otherwise
 func NodeExists(id uint64, nodes []uint64) bool {
@@ -453,6 +736,7 @@ type PeriodicCheck struct {
 	CheckInterval       time.Duration
 	Condition           func() bool
 	Report              func(cumulativePeriod time.Duration)
+	Cleared             func() // Optional, called once the condition no longer holds after it held
 	conditionHoldsSince time.Time
 	once                sync.Once // Used to prevent double initialization
 	stopped             uint32
@@ -487,6 +771,9 @@ func (pc *PeriodicCheck) check() {
 }
 
 func (pc *PeriodicCheck) conditionNotFulfilled() {
+	if !pc.conditionHoldsSince.IsZero() && pc.Cleared != nil {
+		pc.Cleared()
+	}
 	pc.conditionHoldsSince = time.Time{}
 }
 
@@ -514,6 +801,36 @@ func (ledgerPuller *LedgerBlockPuller) PullBlock(seq uint64) *common.Block {
 	return ledgerPuller.BlockPuller.PullBlock(seq)
 }
 
+// Decisions the eviction suspector takes once the suspicion exceeds its threshold.
+const (
+	// EvictionPullFailed means the last config block could not be pulled.
+	EvictionPullFailed = "pull_failed"
+	// EvictionUpToDate means the node is not behind the last config block.
+	EvictionUpToDate = "up_to_date"
+	// EvictionNotConfirmed means the node is a consenter of the last config
+	// block, or cannot tell, hence it catches up to it.
+	EvictionNotConfirmed = "not_confirmed"
+	// EvictionConfirmed means the node is no longer a consenter of the
+	// last config block, hence the chain was halted.
+	EvictionConfirmed = "confirmed"
+)
+
+// EvictionSuspicion is the state of the eviction suspector of a chain, which
+// suspects the eviction of the node once no leader has been known for the
+// threshold, and pulls the last config block of the channel to confirm it.
+type EvictionSuspicion struct {
+	// SuspectingSince is when the leader became unknown, if it is.
+	SuspectingSince time.Time `json:"suspecting_since,omitempty"`
+	Threshold       string    `json:"threshold"`
+	// LastConfigBlock is the number of the last config block
+	// pulled to confirm the suspicion, if any was pulled.
+	LastConfigBlock *uint64 `json:"last_config_block,omitempty"`
+	// LastDecision is the decision last taken once the suspicion
+	// exceeded the threshold, if it ever did, and when it was taken.
+	LastDecision string    `json:"last_decision,omitempty"`
+	DecidedAt    time.Time `json:"decided_at,omitempty"`
+}
+
 type evictionSuspector struct {
 	evictionSuspicionThreshold time.Duration
 	logger                     *flogging.FabricLogger
@@ -521,15 +838,66 @@ type evictionSuspector struct {
 	height                     func() uint64
 	amIInChannel               cluster.SelfMembershipPredicate
 	halt                       func()
+	evicted                    func()
 	writeBlock                 func(block *common.Block) error
 	triggerCatchUp             func(sn *raftpb.Snapshot)
+	metrics                    *Metrics
 	halted                     bool
+
+	lock            sync.Mutex // guards the state below, which is read by suspicion
+	suspectingSince time.Time
+	lastConfigBlock *uint64
+	lastDecision    string
+	decidedAt       time.Time
+}
+
+// suspicion returns the state of the eviction suspector.
+// A nil eviction suspector has no state.
+func (es *evictionSuspector) suspicion() *EvictionSuspicion {
+	if es == nil {
+		return nil
+	}
+
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	suspicion := &EvictionSuspicion{
+		SuspectingSince: es.suspectingSince,
+		Threshold:       es.evictionSuspicionThreshold.String(),
+		LastDecision:    es.lastDecision,
+		DecidedAt:       es.decidedAt,
+	}
+	if es.lastConfigBlock != nil {
+		lastConfigBlock := *es.lastConfigBlock
+		suspicion.LastConfigBlock = &lastConfigBlock
+	}
+	return suspicion
+}
+
+func (es *evictionSuspector) decide(decision string, lastConfigBlock *common.Block) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	if lastConfigBlock != nil {
+		number := lastConfigBlock.Header.Number
+		es.lastConfigBlock = &number
+	}
+	es.lastDecision = decision
+	es.decidedAt = time.Now()
 }
 
 func (es *evictionSuspector) confirmSuspicion(cumulativeSuspicion time.Duration) {
+	es.lock.Lock()
+	if es.suspectingSince.IsZero() {
+		es.suspectingSince = time.Now().Add(-cumulativeSuspicion)
+	}
+	es.lock.Unlock()
+
 	if es.evictionSuspicionThreshold > cumulativeSuspicion || es.halted {
 		return
 	}
+	es.metrics.EvictionSuspected.Set(1)
+	es.metrics.EvictionSuspicionDuration.Set(cumulativeSuspicion.Seconds())
 	es.logger.Infof("Suspecting our own eviction from the channel for %v", cumulativeSuspicion)
 	puller, err := es.createPuller()
 	if err != nil {
@@ -539,6 +907,7 @@ func (es *evictionSuspector) confirmSuspicion(cumulativeSuspicion time.Duration)
 	lastConfigBlock, err := cluster.PullLastConfigBlock(puller)
 	if err != nil {
 		es.logger.Errorf("Failed pulling the last config block: %v", err)
+		es.decide(EvictionPullFailed, nil)
 		return
 	}
 
@@ -548,6 +917,7 @@ func (es *evictionSuspector) confirmSuspicion(cumulativeSuspicion time.Duration)
 
 	if lastConfigBlock.Header.Number+1 <= height {
 		es.logger.Infof("Our height is higher or equal than the height of the orderer we pulled the last block from, aborting.")
+		es.decide(EvictionUpToDate, lastConfigBlock)
 		return
 	}
 
@@ -558,12 +928,16 @@ func (es *evictionSuspector) confirmSuspicion(cumulativeSuspicion time.Duration)
 			details = fmt.Sprintf(": %s", err.Error())
 		}
 		es.logger.Infof("Cannot confirm our own eviction from the channel%s", details)
+		es.decide(EvictionNotConfirmed, lastConfigBlock)
 
 		es.triggerCatchUp(&raftpb.Snapshot{Data: utils.MarshalOrPanic(lastConfigBlock)})
 		return
 	}
 
 	es.logger.Warningf("Detected our own eviction from the chain in block %d", lastConfigBlock.Header.Number)
+	es.decide(EvictionConfirmed, lastConfigBlock)
+	es.metrics.EvictionsConfirmed.Add(1)
+	es.evicted()
 
 	es.logger.Infof("Waiting for chain to halt")
 	es.halt()
@@ -579,7 +953,18 @@ func (es *evictionSuspector) confirmSuspicion(cumulativeSuspicion time.Duration)
 		if err != nil {
 			es.logger.Panicf("Failed writing block %d to the ledger: %v", block.Header.Number, err)
 		}
+		es.metrics.EvictionBlocksPulled.Add(1)
 	}
 
 	es.logger.Infof("Pulled all blocks up to eviction block.")
 }
+
+// clearSuspicion is called once a leader is known again after the suspicion was reported.
+func (es *evictionSuspector) clearSuspicion() {
+	es.lock.Lock()
+	es.suspectingSince = time.Time{}
+	es.lock.Unlock()
+
+	es.metrics.EvictionSuspected.Set(0)
+	es.metrics.EvictionSuspicionDuration.Set(0)
+}
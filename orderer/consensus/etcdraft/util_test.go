@@ -8,6 +8,8 @@ package etcdraft
 
 import (
 	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -15,9 +17,14 @@ import (
 	"testing"
 	"time"
 
+	"code.cloudfoundry.org/clock"
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/crypto/tlsgen"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/metrics"
+	"github.com/hyperledger/fabric/common/metrics/disabled"
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/orderer/common/cluster"
 	"github.com/hyperledger/fabric/orderer/common/cluster/mocks"
@@ -25,10 +32,12 @@ import (
 	"github.com/hyperledger/fabric/orderer/consensus"
 	"github.com/hyperledger/fabric/orderer/mocks/common/multichannel"
 	"github.com/hyperledger/fabric/protos/common"
+	etcdraftproto "github.com/hyperledger/fabric/protos/orderer/etcdraft"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/onsi/gomega"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/raft/raftpb"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -223,10 +232,23 @@ func TestNewBlockPuller(t *testing.T) {
 		},
 	})
 
-	bp, err := newBlockPuller(cs, dialer, localconfig.Cluster{})
+	bp, err := newBlockPuller(cs, dialer, nil, localconfig.Cluster{})
 	assert.NoError(t, err)
 	assert.NotNil(t, bp)
 
+	// with a connection pool, connections are shared through the pool
+	bp, err = newBlockPuller(cs, dialer, cluster.NewConnectionPool(), localconfig.Cluster{})
+	assert.NoError(t, err)
+	assert.IsType(t, &cluster.PooledDialer{}, bp.(*LedgerBlockPuller).BlockPuller.(*cluster.BlockPuller).Dialer)
+
+	// the channel has no consenters, hence its global orderer addresses are preferred
+	bp, err = newBlockPuller(cs, dialer, nil, localconfig.Cluster{ReplicationEndpoints: "prefer-consenters"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orderer.example.com:7050"}, bp.(*LedgerBlockPuller).BlockPuller.(*cluster.BlockPuller).Endpoints)
+
+	_, err = newBlockPuller(cs, dialer, nil, localconfig.Cluster{ReplicationEndpoints: "consenters"})
+	assert.EqualError(t, err, "unknown endpoint strategy \"consenters\", expected one of global, prefer-consenters or all")
+
 	// From here on, we test failures.
 	for _, testCase := range []struct {
 		name          string
@@ -266,7 +288,7 @@ func TestNewBlockPuller(t *testing.T) {
 				cc.SecOpts.Certificate = testCase.certificate
 				testCase.dialer.SetConfig(cc)
 			}
-			bp, err := newBlockPuller(testCase.cs, testCase.dialer, localconfig.Cluster{})
+			bp, err := newBlockPuller(testCase.cs, testCase.dialer, nil, localconfig.Cluster{})
 			assert.Nil(t, bp)
 			assert.EqualError(t, err, testCase.expectedError)
 		})
@@ -296,16 +318,24 @@ func TestPeriodicCheck(t *testing.T) {
 		reports <- duration
 	}
 
+	var clears uint32
+	cleared := func() {
+		atomic.AddUint32(&clears, 1)
+	}
+
 	check := &PeriodicCheck{
 		Logger:        flogging.MustGetLogger("test"),
 		Condition:     condition,
 		CheckInterval: time.Millisecond,
 		Report:        report,
+		Cleared:       cleared,
 	}
 
 	go check.Run()
 
 	g.Eventually(fiveChecks, time.Minute, time.Millisecond).Should(gomega.BeTrue())
+	// the condition never held, so it was never cleared
+	g.Expect(atomic.LoadUint32(&clears)).To(gomega.BeZero())
 	// trigger condition to be true
 	atomic.StoreUint32(&cond, 1)
 	g.Eventually(reports, time.Minute, time.Millisecond).Should(gomega.Not(gomega.BeEmpty()))
@@ -340,6 +370,8 @@ func TestPeriodicCheck(t *testing.T) {
 	g.Expect(checksDoneAfter).To(gomega.BeNumerically(">", checksDoneSoFar))
 	// but nothing has been reported
 	g.Expect(reports).To(gomega.BeEmpty())
+	// and the condition was cleared once
+	g.Expect(atomic.LoadUint32(&clears)).To(gomega.Equal(uint32(1)))
 
 	// trigger the condition again
 	atomic.StoreUint32(&cond, 1)
@@ -378,6 +410,8 @@ func TestEvictionSuspector(t *testing.T) {
 		expectedPanic               string
 		expectedLog                 string
 		expectedCommittedBlockCount int
+		expectedEvictions           int
+		expectedDecision            string
 		amIInChannelReturns         error
 		evictionSuspicionThreshold  time.Duration
 		blockPuller                 BlockPuller
@@ -400,6 +434,7 @@ func TestEvictionSuspector(t *testing.T) {
 		{
 			description:                "our height is the highest",
 			expectedLog:                "Our height is higher or equal than the height of the orderer we pulled the last block from, aborting",
+			expectedDecision:           EvictionUpToDate,
 			evictionSuspicionThreshold: 10*time.Minute - time.Second,
 			blockPuller:                puller,
 			height:                     10,
@@ -408,6 +443,7 @@ func TestEvictionSuspector(t *testing.T) {
 		{
 			description:                "failed pulling the block",
 			expectedLog:                "Cannot confirm our own eviction from the channel: bad block",
+			expectedDecision:           EvictionNotConfirmed,
 			evictionSuspicionThreshold: 10*time.Minute - time.Second,
 			amIInChannelReturns:        errors.New("bad block"),
 			blockPuller:                puller,
@@ -417,6 +453,7 @@ func TestEvictionSuspector(t *testing.T) {
 		{
 			description:                "we are still in the channel",
 			expectedLog:                "Cannot confirm our own eviction from the channel, our certificate was found in config block with sequence 9",
+			expectedDecision:           EvictionNotConfirmed,
 			evictionSuspicionThreshold: 10*time.Minute - time.Second,
 			amIInChannelReturns:        nil,
 			blockPuller:                puller,
@@ -431,6 +468,8 @@ func TestEvictionSuspector(t *testing.T) {
 			blockPuller:                 puller,
 			height:                      8,
 			expectedCommittedBlockCount: 2,
+			expectedEvictions:           1,
+			expectedDecision:            EvictionConfirmed,
 			halt: func() {
 				puller.On("PullBlock", uint64(8)).Return(&common.Block{
 					Header: &common.BlockHeader{Number: 8},
@@ -444,14 +483,19 @@ func TestEvictionSuspector(t *testing.T) {
 		testCase := testCase
 		t.Run(testCase.description, func(t *testing.T) {
 			committedBlocks := make(chan *common.Block, 2)
+			var evictions int
 
 			commitBlock := func(block *common.Block) error {
 				committedBlocks <- block
 				return nil
 			}
 
+			evictionsConfirmed := &metricsfakes.Counter{}
+			blocksPulled := &metricsfakes.Counter{}
+
 			es := &evictionSuspector{
-				halt: testCase.halt,
+				halt:    testCase.halt,
+				evicted: func() { evictions++ },
 				amIInChannel: func(_ *common.Block) error {
 					return testCase.amIInChannelReturns
 				},
@@ -465,6 +509,12 @@ func TestEvictionSuspector(t *testing.T) {
 				},
 				logger:         flogging.MustGetLogger("test"),
 				triggerCatchUp: func(sn *raftpb.Snapshot) { return },
+				metrics: &Metrics{
+					EvictionSuspected:         &metricsfakes.Gauge{},
+					EvictionSuspicionDuration: &metricsfakes.Gauge{},
+					EvictionsConfirmed:        evictionsConfirmed,
+					EvictionBlocksPulled:      blocksPulled,
+				},
 			}
 
 			foundExpectedLog := testCase.expectedLog == ""
@@ -493,6 +543,24 @@ func TestEvictionSuspector(t *testing.T) {
 
 			assert.True(t, foundExpectedLog, "expected to find %s but didn't", testCase.expectedLog)
 			assert.Equal(t, testCase.expectedCommittedBlockCount, len(committedBlocks))
+			assert.Equal(t, testCase.expectedEvictions, evictions)
+			assert.Equal(t, testCase.expectedEvictions, evictionsConfirmed.AddCallCount())
+			assert.Equal(t, testCase.expectedCommittedBlockCount, blocksPulled.AddCallCount())
+
+			suspicion := es.suspicion()
+			assert.Equal(t, testCase.evictionSuspicionThreshold.String(), suspicion.Threshold)
+			assert.WithinDuration(t, time.Now().Add(-10*time.Minute), suspicion.SuspectingSince, time.Minute)
+			assert.Equal(t, testCase.expectedDecision, suspicion.LastDecision)
+			if testCase.expectedDecision == "" {
+				assert.Nil(t, suspicion.LastConfigBlock)
+				assert.True(t, suspicion.DecidedAt.IsZero())
+			} else {
+				assert.Equal(t, uint64(9), *suspicion.LastConfigBlock)
+				assert.False(t, suspicion.DecidedAt.IsZero())
+			}
+
+			es.clearSuspicion()
+			assert.True(t, es.suspicion().SuspectingSince.IsZero())
 		})
 	}
 }
@@ -520,3 +588,430 @@ func TestLedgerBlockPuller(t *testing.T) {
 	assert.Equal(t, genesisBlock, lbp.PullBlock(0))
 	assert.Equal(t, notGenesisBlock, lbp.PullBlock(1))
 }
+
+func TestSnapshotBlock(t *testing.T) {
+	block := &common.Block{Header: &common.BlockHeader{Number: 5, DataHash: []byte{1, 2, 3}}}
+	ref := &etcdraftproto.ArchiveReference{Uri: "s3://bucket/mychannel", Hash: []byte{4, 5, 6}}
+
+	for _, testCase := range []struct {
+		name            string
+		data            []byte
+		expectedArchive *etcdraftproto.ArchiveReference
+		expectedErr     string
+	}{
+		{
+			name: "legacy snapshot",
+			data: utils.MarshalOrPanic(block),
+		},
+		{
+			name:            "snapshot with archive reference",
+			data:            utils.MarshalOrPanic(&etcdraftproto.SnapshotData{Block: block, Archive: ref}),
+			expectedArchive: ref,
+		},
+		{
+			name:        "garbage",
+			data:        []byte{1, 2, 3},
+			expectedErr: "error unmarshaling Block",
+		},
+		{
+			name:        "no block header",
+			data:        utils.MarshalOrPanic(&common.Block{}),
+			expectedErr: "block header is nil",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			b, archive, err := SnapshotBlock(testCase.data)
+			if testCase.expectedErr != "" {
+				assert.Contains(t, err.Error(), testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(block, b))
+			assert.True(t, proto.Equal(testCase.expectedArchive, archive))
+		})
+	}
+}
+
+func TestVerifyStateHashes(t *testing.T) {
+	header := &common.BlockHeader{Number: 5, DataHash: []byte{1, 2, 3}}
+	hash := NextStateHash(nil, 10, header)
+	otherHash := NextStateHash(nil, 11, header)
+	assert.Len(t, hash, 32)
+	assert.NotEqual(t, hash, otherHash)
+
+	blockWithHash := func(h *common.BlockHeader, stateHash []byte) *common.Block {
+		block := &common.Block{
+			Header:   h,
+			Metadata: &common.BlockMetadata{Metadata: make([][]byte, 4)},
+		}
+		block.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&common.Metadata{
+			Value: utils.MarshalOrPanic(&etcdraftproto.BlockMetadata{RaftIndex: 10, StateHash: stateHash}),
+		})
+		return block
+	}
+
+	for _, testCase := range []struct {
+		name        string
+		blocks      map[uint64]*common.Block
+		expectedErr string
+	}{
+		{
+			name: "consistent",
+			blocks: map[uint64]*common.Block{
+				1: blockWithHash(header, hash),
+				2: blockWithHash(header, hash),
+				3: blockWithHash(header, hash),
+			},
+		},
+		{
+			name: "diverging",
+			blocks: map[uint64]*common.Block{
+				1: blockWithHash(header, hash),
+				2: blockWithHash(header, otherHash),
+				3: blockWithHash(header, hash),
+			},
+			expectedErr: fmt.Sprintf("state of block 5 diverges: consenters [1 3] have %x, consenters [2] have %x", hash, otherHash),
+		},
+		{
+			name: "different blocks",
+			blocks: map[uint64]*common.Block{
+				1: blockWithHash(header, hash),
+				2: blockWithHash(&common.BlockHeader{Number: 6}, hash),
+			},
+			expectedErr: "block of consenter 2 is block 6, expected block 5",
+		},
+		{
+			name: "missing block",
+			blocks: map[uint64]*common.Block{
+				1: blockWithHash(header, hash),
+				2: nil,
+			},
+			expectedErr: "block of consenter 2 is missing",
+		},
+		{
+			name: "no state hash",
+			blocks: map[uint64]*common.Block{
+				1: blockWithHash(header, hash),
+				2: blockWithHash(header, nil),
+			},
+			expectedErr: "block 5 of consenter 2 carries no state hash",
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := VerifyStateHashes(testCase.blocks)
+			if testCase.expectedErr != "" {
+				assert.EqualError(t, err, testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBlockProvenance(t *testing.T) {
+	provenance := &etcdraftproto.BlockProvenance{RaftTerm: 3, Proposer: 2}
+
+	stamped := common.NewBlock(5, nil)
+	stampProvenance(stamped, provenance)
+
+	written := common.NewBlock(5, nil)
+	written.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(&etcdraftproto.BlockMetadata{RaftIndex: 10, Provenance: provenance}),
+	})
+
+	garbled := common.NewBlock(5, nil)
+	garbled.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = []byte{1, 2, 3}
+
+	for _, testCase := range []struct {
+		name        string
+		block       *common.Block
+		expectedErr string
+	}{
+		{name: "proposed block", block: stamped},
+		{name: "written block", block: written},
+		{name: "no provenance", block: common.NewBlock(5, nil), expectedErr: "block 5 carries no provenance"},
+		{name: "garbled metadata", block: garbled, expectedErr: "failed to read metadata of block 5"},
+		{name: "no header", block: &common.Block{}, expectedErr: "block header is nil"},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			p, err := BlockProvenance(testCase.block)
+			if testCase.expectedErr != "" {
+				assert.Contains(t, err.Error(), testCase.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, proto.Equal(provenance, p))
+		})
+	}
+}
+
+func TestBlockTimestamp(t *testing.T) {
+	signer := &mockcrypto.LocalSigner{Identity: []byte("orderer1"), Nonce: []byte("nonce")}
+	created := time.Unix(1700000000, 500)
+
+	stamped := common.NewBlock(5, []byte("previous"))
+	timestamp, err := signBlockTimestamp(stamped.Header, 2, created, signer)
+	require.NoError(t, err)
+	assert.Equal(t, created.UnixNano(), timestamp.Time)
+	assert.Equal(t, uint64(2), timestamp.Proposer)
+	stampProvenance(stamped, &etcdraftproto.BlockProvenance{RaftTerm: 3, Proposer: 2})
+	stampTimestamp(stamped, timestamp)
+
+	// what is stamped before is kept
+	provenance, err := BlockProvenance(stamped)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&etcdraftproto.BlockProvenance{RaftTerm: 3, Proposer: 2}, provenance))
+
+	read, err := BlockTimestamp(stamped)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(timestamp, read))
+
+	// the mock signer signs with the message itself
+	signedData := BlockTimestampSignedData(stamped.Header, read)
+	assert.Equal(t, []byte("orderer1"), signedData.Identity)
+	assert.Equal(t, signedData.Data, signedData.Signature)
+
+	// the signature binds the block header, the time and the proposer
+	for _, tampered := range []func(h *common.BlockHeader, ts *etcdraftproto.BlockTimestamp){
+		func(h *common.BlockHeader, ts *etcdraftproto.BlockTimestamp) { h.Number++ },
+		func(h *common.BlockHeader, ts *etcdraftproto.BlockTimestamp) { h.DataHash = []byte("other") },
+		func(h *common.BlockHeader, ts *etcdraftproto.BlockTimestamp) { ts.Time++ },
+		func(h *common.BlockHeader, ts *etcdraftproto.BlockTimestamp) { ts.Proposer++ },
+	} {
+		h := proto.Clone(stamped.Header).(*common.BlockHeader)
+		ts := proto.Clone(read).(*etcdraftproto.BlockTimestamp)
+		tampered(h, ts)
+		assert.NotEqual(t, signedData.Data, BlockTimestampSignedData(h, ts).Data)
+	}
+
+	garbled := common.NewBlock(5, nil)
+	garbled.Metadata.Metadata[common.BlockMetadataIndex_ORDERER] = []byte{1, 2, 3}
+
+	_, err = BlockTimestamp(common.NewBlock(5, nil))
+	assert.EqualError(t, err, "block 5 carries no timestamp")
+	_, err = BlockTimestamp(garbled)
+	assert.Contains(t, err.Error(), "failed to read metadata of block 5")
+	_, err = BlockTimestamp(&common.Block{})
+	assert.EqualError(t, err, "block header is nil")
+
+	assert.Nil(t, BlockTimestampSignedData(stamped.Header, &etcdraftproto.BlockTimestamp{SignatureHeader: []byte{1, 2, 3}}).Identity)
+}
+
+func TestSameEndpoint(t *testing.T) {
+	node := &etcdraftproto.Consenter{Host: "node1.example.com", Port: 7050}
+	for _, testCase := range []struct {
+		host     string
+		port     uint32
+		expected bool
+	}{
+		{host: "node1.example.com", port: 7050, expected: true},
+		{host: "Node1.Example.COM", port: 7050, expected: true},
+		{host: "node1.example.com.", port: 7050, expected: true},
+		{host: "NODE1.example.com.", port: 7050, expected: true},
+		{host: "node1.example.com", port: 7051, expected: false},
+		{host: "node2.example.com", port: 7050, expected: false},
+		{host: "node1.example.com..", port: 7050, expected: false},
+	} {
+		other := &etcdraftproto.Consenter{Host: testCase.host, Port: testCase.port}
+		assert.Equal(t, testCase.expected, SameEndpoint(node, other), "%s:%d", testCase.host, testCase.port)
+	}
+}
+
+func TestComputeMembershipChangesEndpoints(t *testing.T) {
+	consenter := func(host string, cert string) *etcdraftproto.Consenter {
+		return &etcdraftproto.Consenter{Host: host, Port: 7050, ClientTlsCert: []byte(cert), ServerTlsCert: []byte(cert)}
+	}
+	oldMetadata := &etcdraftproto.BlockMetadata{
+		Consenters: map[uint64]*etcdraftproto.Consenter{
+			1: consenter("node1.example.com", "cert1"),
+			2: consenter("node2.example.com", "cert2"),
+		},
+		NextConsenterId: 3,
+	}
+
+	t.Run("superficial differences", func(t *testing.T) {
+		changes, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("NODE1.example.com.", "cert1"),
+			consenter("node2.Example.com", "cert2"),
+		})
+		assert.NoError(t, err)
+		assert.False(t, changes.Changed())
+		assert.Empty(t, changes.MovedNodes)
+		assert.True(t, proto.Equal(oldMetadata, changes.NewBlockMetadata))
+	})
+
+	t.Run("moved node", func(t *testing.T) {
+		moved := consenter("node2.example.org", "cert2")
+		changes, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("node1.example.com", "cert1"),
+			moved,
+		})
+		assert.NoError(t, err)
+		assert.True(t, changes.Changed())
+		assert.False(t, changes.Rotated())
+		assert.Nil(t, changes.ConfChange)
+		assert.Equal(t, []uint64{2}, changes.MovedNodes)
+		assert.True(t, proto.Equal(moved, changes.NewBlockMetadata.Consenters[2]))
+		assert.Equal(t, "node2.example.com", oldMetadata.Consenters[2].Host)
+		assert.Equal(t, "add 0 node(s), remove 0 node(s), move 1 node(s)", changes.String())
+	})
+
+	t.Run("moved and added nodes", func(t *testing.T) {
+		changes, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("node1.example.org", "cert1"),
+			consenter("node2.example.com", "cert2"),
+			consenter("node3.example.com", "cert3"),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1}, changes.MovedNodes)
+		assert.Equal(t, "node1.example.org", changes.NewBlockMetadata.Consenters[1].Host)
+		assert.Equal(t, "node3.example.com", changes.NewBlockMetadata.Consenters[3].Host)
+		assert.Equal(t, raftpb.ConfChangeAddNode, changes.ConfChange.Type)
+	})
+}
+
+func TestComputeMembershipChangesOrgs(t *testing.T) {
+	consenter := func(cert string, org string) *etcdraftproto.Consenter {
+		return &etcdraftproto.Consenter{Host: "node.example.com", Port: 7050, ClientTlsCert: []byte(cert), ServerTlsCert: []byte(cert), MspId: org}
+	}
+	oldMetadata := &etcdraftproto.BlockMetadata{
+		Consenters: map[uint64]*etcdraftproto.Consenter{
+			1: consenter("cert1", "Org1"),
+			2: consenter("cert2", ""),
+		},
+		NextConsenterId: 3,
+	}
+
+	t.Run("binding", func(t *testing.T) {
+		changes, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("cert1", "Org1"),
+			consenter("cert2", "Org2"),
+		})
+		assert.NoError(t, err)
+		assert.False(t, changes.Changed())
+		assert.Equal(t, "Org2", changes.NewBlockMetadata.Consenters[2].MspId)
+		assert.Equal(t, map[uint64]string{1: "Org1", 2: "Org2"}, consenterOrgs(changes.NewBlockMetadata.Consenters))
+		assert.Equal(t, "", oldMetadata.Consenters[2].MspId)
+	})
+
+	t.Run("moved to another organization", func(t *testing.T) {
+		_, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("cert1", "Org2"),
+			consenter("cert2", ""),
+		})
+		assert.EqualError(t, err, `consenter 1 is bound to organization "Org1" and cannot be moved to organization "Org2"`)
+
+		_, err = ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("cert1", ""),
+			consenter("cert2", ""),
+		})
+		assert.EqualError(t, err, `consenter 1 is bound to organization "Org1" and cannot be moved to organization ""`)
+	})
+
+	t.Run("rotated to another organization", func(t *testing.T) {
+		_, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("cert3", "Org2"),
+			consenter("cert2", ""),
+		})
+		assert.EqualError(t, err, `consenter 1 is bound to organization "Org1" and cannot be moved to organization "Org2"`)
+
+		changes, err := ComputeMembershipChanges(oldMetadata, []*etcdraftproto.Consenter{
+			consenter("cert3", "Org1"),
+			consenter("cert2", ""),
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(1), changes.RotatedNode)
+	})
+}
+
+func TestSameConsenters(t *testing.T) {
+	consenters := func(hosts ...string) map[uint64]*etcdraftproto.Consenter {
+		m := make(map[uint64]*etcdraftproto.Consenter)
+		for i, host := range hosts {
+			m[uint64(i+1)] = &etcdraftproto.Consenter{Host: host, Port: 7050}
+		}
+		return m
+	}
+
+	assert.True(t, sameConsenters(consenters(), consenters()))
+	assert.True(t, sameConsenters(consenters("a", "b"), consenters("a", "b")))
+	assert.False(t, sameConsenters(consenters("a", "b"), consenters("a")))
+	assert.False(t, sameConsenters(consenters("a", "b"), consenters("a", "c")))
+	assert.False(t, sameConsenters(consenters("a", "b"), consenters("b", "a")))
+}
+
+type recordingConfigurator struct {
+	configured [][]cluster.RemoteNode
+}
+
+func (rc *recordingConfigurator) Configure(channel string, newNodes []cluster.RemoteNode) {
+	rc.configured = append(rc.configured, newNodes)
+}
+
+func TestCatchUpComm(t *testing.T) {
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{1, 2, 3}})
+	consenter := func(host string) *etcdraftproto.Consenter {
+		return &etcdraftproto.Consenter{Host: host, Port: 7050, ClientTlsCert: cert, ServerTlsCert: cert}
+	}
+	setConsenters := func(c *Chain, consenters map[uint64]*etcdraftproto.Consenter) {
+		c.blockMetadata.Store(&etcdraftproto.BlockMetadata{Consenters: consenters})
+	}
+
+	configurator := &recordingConfigurator{}
+	c := &Chain{
+		raftID:       1,
+		configurator: configurator,
+		logger:       flogging.MustGetLogger("test"),
+		clock:        clock.NewClock(),
+		Node:         &node{},
+		Metrics: &Metrics{
+			TrustChangedTime: (&disabled.Provider{}).NewGauge(metrics.GaugeOpts{}),
+			ConsenterOrg:     (&disabled.Provider{}).NewGauge(metrics.GaugeOpts{}),
+		},
+	}
+	initial := map[uint64]*etcdraftproto.Consenter{1: consenter("a"), 2: consenter("b")}
+	setConsenters(c, initial)
+	comm := &catchUpComm{chain: c, consenters: initial, since: 1}
+
+	// no membership change was pulled
+	comm.reconfigure(5)
+	assert.Empty(t, configurator.configured)
+	assert.Equal(t, uint64(5), comm.since)
+
+	// node 3 was added and removed again
+	setConsenters(c, map[uint64]*etcdraftproto.Consenter{1: consenter("a"), 2: consenter("b"), 3: consenter("c")})
+	setConsenters(c, map[uint64]*etcdraftproto.Consenter{1: consenter("a"), 2: consenter("b")})
+	comm.reconfigure(10)
+	assert.Empty(t, configurator.configured)
+
+	// nodes 3 and 4 were added
+	setConsenters(c, map[uint64]*etcdraftproto.Consenter{1: consenter("a"), 2: consenter("b"), 3: consenter("c")})
+	setConsenters(c, map[uint64]*etcdraftproto.Consenter{1: consenter("a"), 2: consenter("b"), 3: consenter("c"), 4: consenter("d")})
+	comm.reconfigure(15)
+	require.Len(t, configurator.configured, 1)
+	assert.Len(t, configurator.configured[0], 3)
+
+	comm.reconfigure(20)
+	assert.Len(t, configurator.configured, 1)
+}
+
+func TestSnapshotDataCapture(t *testing.T) {
+	block := common.NewBlock(5, []byte("previous"))
+	block.Data.Data = [][]byte{[]byte("tx")}
+	g := &gc{index: 7, block: captureBlock(block)}
+	expected := utils.MarshalOrPanic(block)
+
+	// the block writer annotates the block after it was captured
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = []byte("signatures")
+	assert.Equal(t, expected, g.snapshotData())
+
+	ref := &etcdraftproto.ArchiveReference{Uri: "file:///archive"}
+	g.archive = ref
+	captured, archive, err := SnapshotBlock(g.snapshotData())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), captured.Header.Number)
+	assert.True(t, proto.Equal(ref, archive))
+
+	g.data = []byte("entry")
+	assert.Equal(t, []byte("entry"), g.snapshotData())
+}
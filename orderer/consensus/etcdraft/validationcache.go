@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"container/list"
+	"crypto/sha256"
+
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// ValidationCacheSize is the number of revalidated envelopes
+// whose validation results the chain caches.
+var ValidationCacheSize = 1000
+
+type validationKey struct {
+	hash [sha256.Size]byte // of the payload and signature of the envelope
+	seq  uint64            // config sequence the envelope is validated against
+}
+
+type validationResult struct {
+	key validationKey
+	err error
+}
+
+// validationCache caches the results of revalidating envelopes against the
+// config sequence once it advanced past the one they were validated against,
+// so that identical envelopes retried by clients are not put through the
+// policy checks again. It evicts the least recently used results, and is
+// not safe for concurrent use.
+type validationCache struct {
+	size    int
+	metrics *Metrics
+
+	lru     *list.List // of *validationResult, most recently used first
+	results map[validationKey]*list.Element
+}
+
+func newValidationCache(size int, metrics *Metrics) *validationCache {
+	return &validationCache{
+		size:    size,
+		metrics: metrics,
+		lru:     list.New(),
+		results: make(map[validationKey]*list.Element),
+	}
+}
+
+// validate returns the cached result of validating the envelope against the
+// given config sequence, or validates it with the given function and caches
+// the result.
+func (vc *validationCache) validate(env *common.Envelope, seq uint64, validate func() error) error {
+	if vc.size <= 0 {
+		return validate()
+	}
+
+	h := sha256.New()
+	h.Write(env.Payload)
+	h.Write(env.Signature)
+	key := validationKey{seq: seq}
+	copy(key.hash[:], h.Sum(nil))
+
+	if e, exists := vc.results[key]; exists {
+		vc.metrics.ValidationCacheHits.Add(1)
+		vc.lru.MoveToFront(e)
+		return e.Value.(*validationResult).err
+	}
+
+	vc.metrics.ValidationCacheMisses.Add(1)
+	err := validate()
+	vc.results[key] = vc.lru.PushFront(&validationResult{key: key, err: err})
+	for vc.lru.Len() > vc.size {
+		oldest := vc.lru.Remove(vc.lru.Back()).(*validationResult)
+		delete(vc.results, oldest.key)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/metrics/metricsfakes"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationCache(t *testing.T) {
+	hits := &metricsfakes.Counter{}
+	misses := &metricsfakes.Counter{}
+	metrics := &Metrics{ValidationCacheHits: hits, ValidationCacheMisses: misses}
+
+	var validations int
+	valid := func() error {
+		validations++
+		return nil
+	}
+	invalid := func() error {
+		validations++
+		return errors.New("access denied")
+	}
+	env1 := &common.Envelope{Payload: []byte{1}, Signature: []byte{1}}
+	env2 := &common.Envelope{Payload: []byte{2}, Signature: []byte{2}}
+	env3 := &common.Envelope{Payload: []byte{3}, Signature: []byte{3}}
+
+	t.Run("caches results by envelope and config sequence", func(t *testing.T) {
+		validations = 0
+		vc := newValidationCache(10, metrics)
+
+		assert.NoError(t, vc.validate(env1, 1, valid))
+		assert.NoError(t, vc.validate(env1, 1, invalid))
+		assert.EqualError(t, vc.validate(env2, 1, invalid), "access denied")
+		assert.EqualError(t, vc.validate(env2, 1, valid), "access denied")
+		assert.Equal(t, 2, validations)
+
+		// a retried envelope is validated again once the config sequence advances
+		assert.EqualError(t, vc.validate(env1, 2, invalid), "access denied")
+		assert.Equal(t, 3, validations)
+
+		// the signature is part of the key
+		assert.NoError(t, vc.validate(&common.Envelope{Payload: []byte{1}, Signature: []byte{2}}, 1, valid))
+		assert.Equal(t, 4, validations)
+	})
+
+	t.Run("evicts the least recently used results", func(t *testing.T) {
+		validations = 0
+		vc := newValidationCache(2, metrics)
+
+		vc.validate(env1, 1, valid)
+		vc.validate(env2, 1, valid)
+		vc.validate(env1, 1, valid)
+		vc.validate(env3, 1, valid)
+		assert.Equal(t, 3, validations)
+
+		vc.validate(env1, 1, valid)
+		assert.Equal(t, 3, validations)
+		vc.validate(env2, 1, valid)
+		assert.Equal(t, 4, validations)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		validations = 0
+		vc := newValidationCache(0, metrics)
+
+		vc.validate(env1, 1, valid)
+		vc.validate(env1, 1, valid)
+		assert.Equal(t, 2, validations)
+	})
+
+	assert.Equal(t, 4, hits.AddCallCount())
+	assert.Equal(t, 8, misses.AddCallCount())
+}
@@ -0,0 +1,136 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/pkg/errors"
+)
+
+// verificationPullRetries is the number of attempts made to pull
+// a sampled block from a consenter before giving up on it.
+const verificationPullRetries = 3
+
+// blockVerifier periodically samples a random committed block, pulls the block
+// with the same sequence from every consenter of the channel, and compares their
+// hashes with the hash of the local block. It is a consistency watchdog which
+// detects nodes that have diverged from each other, e.g. due to a patched orderer.
+type blockVerifier struct {
+	logger *flogging.FabricLogger
+	clock  clock.Clock
+
+	height func() uint64
+	block  func(seq uint64) *common.Block
+	// pullers returns block pullers that pull from a single consenter each, by endpoint.
+	pullers func() (map[string]BlockPuller, error)
+	// mismatch is called with the endpoint of the consenter
+	// whose block does not match the local block.
+	mismatch func(seq uint64, endpoint string)
+	// sample returns a random number in [0, n).
+	sample func(n uint64) uint64
+}
+
+// run verifies a sampled block every interval, until doneC is closed.
+func (bv *blockVerifier) run(interval time.Duration, doneC <-chan struct{}) {
+	ticker := bv.clock.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C():
+			bv.verify()
+		case <-doneC:
+			return
+		}
+	}
+}
+
+// verify samples a committed block, other than the genesis block, and compares
+// it with the blocks of the same sequence of all consenters it can pull it from.
+func (bv *blockVerifier) verify() {
+	height := bv.height()
+	if height < 2 {
+		return
+	}
+	seq := 1 + bv.sample(height-1)
+
+	local := bv.block(seq)
+	if local == nil {
+		bv.logger.Warnf("Failed to retrieve block %d from the ledger for verification", seq)
+		return
+	}
+	localHash := local.Header.Hash()
+
+	pullers, err := bv.pullers()
+	if err != nil {
+		bv.logger.Warnf("Failed to create block pullers for verification: %s", err)
+		return
+	}
+
+	endpoints := make([]string, 0, len(pullers))
+	for endpoint := range pullers {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	for _, endpoint := range endpoints {
+		puller := pullers[endpoint]
+		remote := puller.PullBlock(seq)
+		puller.Close()
+
+		if remote == nil {
+			bv.logger.Debugf("Could not pull block %d from %s for verification", seq, endpoint)
+			continue
+		}
+		if !bytes.Equal(localHash, remote.Header.Hash()) {
+			bv.logger.Errorf("Block %d of %s does not match the local block: hash is %x, local hash is %x",
+				seq, endpoint, remote.Header.Hash(), localHash)
+			bv.mismatch(seq, endpoint)
+			continue
+		}
+		bv.logger.Debugf("Block %d of %s matches the local block", seq, endpoint)
+	}
+}
+
+// endpointPullers splits the block puller of the chain
+// into block pullers that pull from a single endpoint each.
+func endpointPullers(createPuller CreateBlockPuller) (map[string]BlockPuller, error) {
+	puller, err := createPuller()
+	if err != nil {
+		return nil, err
+	}
+	defer puller.Close()
+
+	if ledgerPuller, isLedgerPuller := puller.(*LedgerBlockPuller); isLedgerPuller {
+		puller = ledgerPuller.BlockPuller
+	}
+	clusterPuller, isClusterPuller := puller.(*cluster.BlockPuller)
+	if !isClusterPuller {
+		return nil, errors.Errorf("block puller of type %T cannot pull from a single endpoint", puller)
+	}
+
+	pullers := make(map[string]BlockPuller, len(clusterPuller.Endpoints))
+	for _, endpoint := range clusterPuller.Endpoints {
+		p := clusterPuller.Clone()
+		p.Endpoints = []string{endpoint}
+		p.MaxPullBlockRetries = verificationPullRetries
+		pullers[endpoint] = p
+	}
+	return pullers, nil
+}
+
+func randomSample(n uint64) uint64 {
+	return uint64(rand.Int63n(int64(n)))
+}
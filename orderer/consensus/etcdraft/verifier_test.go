@@ -0,0 +1,178 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/cluster"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type blockPullerStub struct {
+	blocks map[uint64]*common.Block
+	closed bool
+}
+
+func (p *blockPullerStub) PullBlock(seq uint64) *common.Block {
+	return p.blocks[seq]
+}
+
+func (p *blockPullerStub) HeightsByEndpoints() (map[string]uint64, error) {
+	return nil, nil
+}
+
+func (p *blockPullerStub) Close() {
+	p.closed = true
+}
+
+func verifiedBlock(seq uint64, data string) *common.Block {
+	block := common.NewBlock(seq, []byte("previous hash"))
+	block.Data.Data = [][]byte{[]byte(data)}
+	block.Header.DataHash = block.Data.Hash()
+	return block
+}
+
+func TestBlockVerifierVerify(t *testing.T) {
+	local := verifiedBlock(5, "tx")
+	forged := verifiedBlock(5, "forged tx")
+
+	for _, testCase := range []struct {
+		name       string
+		height     uint64
+		pullers    map[string]*blockPullerStub
+		mismatches []string
+	}{
+		{
+			name:   "all blocks match",
+			height: 10,
+			pullers: map[string]*blockPullerStub{
+				"node1:7050": {blocks: map[uint64]*common.Block{5: local}},
+				"node2:7050": {blocks: map[uint64]*common.Block{5: local}},
+			},
+		},
+		{
+			name:   "a block does not match",
+			height: 10,
+			pullers: map[string]*blockPullerStub{
+				"node1:7050": {blocks: map[uint64]*common.Block{5: local}},
+				"node2:7050": {blocks: map[uint64]*common.Block{5: forged}},
+				"node3:7050": {blocks: map[uint64]*common.Block{5: forged}},
+			},
+			mismatches: []string{"node2:7050", "node3:7050"},
+		},
+		{
+			name:   "a block cannot be pulled",
+			height: 10,
+			pullers: map[string]*blockPullerStub{
+				"node1:7050": {},
+				"node2:7050": {blocks: map[uint64]*common.Block{5: local}},
+			},
+		},
+		{
+			name:   "only the genesis block is committed",
+			height: 1,
+			pullers: map[string]*blockPullerStub{
+				"node1:7050": {blocks: map[uint64]*common.Block{5: forged}},
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			var mismatches []string
+			bv := &blockVerifier{
+				logger: flogging.MustGetLogger("test"),
+				height: func() uint64 { return testCase.height },
+				block: func(seq uint64) *common.Block {
+					assert.Equal(t, uint64(5), seq)
+					return local
+				},
+				pullers: func() (map[string]BlockPuller, error) {
+					pullers := make(map[string]BlockPuller)
+					for endpoint, puller := range testCase.pullers {
+						pullers[endpoint] = puller
+					}
+					return pullers, nil
+				},
+				mismatch: func(seq uint64, endpoint string) {
+					assert.Equal(t, uint64(5), seq)
+					mismatches = append(mismatches, endpoint)
+				},
+				sample: func(n uint64) uint64 {
+					assert.Equal(t, testCase.height-1, n)
+					return 4
+				},
+			}
+
+			bv.verify()
+			assert.Equal(t, testCase.mismatches, mismatches)
+			if testCase.height > 1 {
+				for endpoint, puller := range testCase.pullers {
+					assert.True(t, puller.closed, "puller of %s should be closed", endpoint)
+				}
+			}
+		})
+	}
+}
+
+func TestBlockVerifierRun(t *testing.T) {
+	clock := fakeclock.NewFakeClock(time.Now())
+	doneC := make(chan struct{})
+
+	verified := make(chan struct{})
+	bv := &blockVerifier{
+		logger: flogging.MustGetLogger("test"),
+		clock:  clock,
+		height: func() uint64 {
+			verified <- struct{}{}
+			return 1
+		},
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		bv.run(time.Minute, doneC)
+		close(stopped)
+	}()
+
+	for i := 0; i < 3; i++ {
+		clock.WaitForWatcherAndIncrement(time.Minute)
+		select {
+		case <-verified:
+		case <-time.After(time.Second):
+			t.Fatalf("block was not verified after %d intervals", i+1)
+		}
+	}
+
+	close(doneC)
+	<-stopped
+}
+
+func TestEndpointPullers(t *testing.T) {
+	clusterPuller := &cluster.BlockPuller{
+		Endpoints:           []string{"node1:7050", "node2:7050"},
+		MaxPullBlockRetries: 100,
+	}
+	pullers, err := endpointPullers(func() (BlockPuller, error) {
+		return &LedgerBlockPuller{BlockPuller: clusterPuller}, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, pullers, 2)
+	for _, endpoint := range clusterPuller.Endpoints {
+		puller := pullers[endpoint].(*cluster.BlockPuller)
+		assert.Equal(t, []string{endpoint}, puller.Endpoints)
+		assert.Equal(t, uint64(verificationPullRetries), puller.MaxPullBlockRetries)
+	}
+
+	_, err = endpointPullers(func() (BlockPuller, error) {
+		return &blockPullerStub{}, nil
+	})
+	assert.EqualError(t, err, "block puller of type *etcdraft.blockPullerStub cannot pull from a single endpoint")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/pkg/errors"
+)
+
+// WALReadAhead is the way WAL segments are read ahead when a chain
+// is recovered, so that replaying the WAL is not bound by the latency
+// of the small sequential reads of the WAL decoder.
+type WALReadAhead string
+
+const (
+	// WALReadAheadBuffered reads the segments ahead with large buffered
+	// reads, which populates the page cache ahead of the decoder.
+	WALReadAheadBuffered WALReadAhead = "buffered"
+	// WALReadAheadMmap maps the segments into memory and advises the kernel
+	// that they will be needed, which reads them ahead without copying.
+	// It is only supported on Linux, and falls back to buffered reads elsewhere.
+	WALReadAheadMmap WALReadAhead = "mmap"
+	// WALReadAheadNone disables the read-ahead.
+	WALReadAheadNone WALReadAhead = "none"
+)
+
+// walReadAheadBufferSize is the size of the reads of WALReadAheadBuffered.
+const walReadAheadBufferSize = 1024 * 1024
+
+// ParseWALReadAhead parses the WAL read-ahead mode, which
+// defaults to WALReadAheadBuffered if not set.
+func ParseWALReadAhead(mode string) (WALReadAhead, error) {
+	switch WALReadAhead(mode) {
+	case "":
+		return WALReadAheadBuffered, nil
+	case WALReadAheadBuffered, WALReadAheadMmap, WALReadAheadNone:
+		return WALReadAhead(mode), nil
+	default:
+		return "", errors.Errorf("unknown WAL read-ahead mode %s, expected one of %s, %s or %s",
+			mode, WALReadAheadBuffered, WALReadAheadMmap, WALReadAheadNone)
+	}
+}
+
+// readAheadWAL starts reading ahead the WAL segments in walDir that are replayed
+// from the given raft index onwards. The returned function stops the read-ahead,
+// and must be called once the WAL has been replayed.
+// The WAL is not read ahead if the mode is not set.
+func readAheadWAL(lg *flogging.FabricLogger, walDir string, index uint64, mode WALReadAhead) (stop func()) {
+	if mode == "" || mode == WALReadAheadNone {
+		return func() {}
+	}
+
+	segments, err := walSegmentsFrom(walDir, index)
+	if err != nil {
+		lg.Warnf("Not reading WAL ahead: %s", err)
+		return func() {}
+	}
+
+	stopC := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, segment := range segments {
+			select {
+			case <-stopC:
+				return
+			default:
+			}
+
+			if err := readAheadSegment(segment, mode, stopC); err != nil {
+				lg.Warnf("Failed reading WAL segment %s ahead: %s", segment, err)
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stopC)
+			wg.Wait()
+		})
+	}
+}
+
+func readAheadSegment(segment string, mode WALReadAhead, stopC <-chan struct{}) error {
+	f, err := os.Open(segment)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if mode == WALReadAheadMmap {
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if err := adviseWillNeed(f, info.Size()); err == nil {
+			return nil
+		}
+		// mmap is not supported, fall back to buffered reads
+	}
+
+	buf := make([]byte, walReadAheadBufferSize)
+	for {
+		select {
+		case <-stopC:
+			return nil
+		default:
+		}
+
+		if _, err := f.Read(buf); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// walSegmentsFrom returns the paths of the WAL segments in walDir, starting from
+// the segment holding the given raft index, like the WAL does when it is opened.
+func walSegmentsFrom(walDir string, index uint64) ([]string, error) {
+	names, err := filepath.Glob(filepath.Join(walDir, "*.wal"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	for i := len(names) - 1; i >= 0; i-- {
+		var seq, segmentIndex uint64
+		if _, err := fmt.Sscanf(strings.TrimSuffix(filepath.Base(names[i]), ".wal"), "%016x-%016x", &seq, &segmentIndex); err != nil {
+			return nil, errors.Errorf("bad WAL segment name %s", names[i])
+		}
+		if index >= segmentIndex {
+			return names[i:], nil
+		}
+	}
+	return nil, errors.Errorf("no WAL segment holds index %d", index)
+}
@@ -0,0 +1,29 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"os"
+	"syscall"
+)
+
+// adviseWillNeed maps the file into memory and advises the kernel
+// that it will be needed soon, which makes it read the file ahead
+// asynchronously.
+func adviseWillNeed(f *os.File, size int64) error {
+	if size == 0 {
+		return nil
+	}
+
+	b, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	defer syscall.Munmap(b)
+
+	return syscall.Madvise(b, syscall.MADV_WILLNEED)
+}
@@ -0,0 +1,20 @@
+// +build !linux
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// adviseWillNeed is only supported on Linux.
+func adviseWillNeed(f *os.File, size int64) error {
+	return errors.New("mmap read-ahead is not supported on this platform")
+}
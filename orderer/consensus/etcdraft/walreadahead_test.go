@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.uber.org/zap"
+)
+
+func TestParseWALReadAhead(t *testing.T) {
+	for mode, expected := range map[string]WALReadAhead{
+		"":         WALReadAheadBuffered,
+		"buffered": WALReadAheadBuffered,
+		"mmap":     WALReadAheadMmap,
+		"none":     WALReadAheadNone,
+	} {
+		readAhead, err := ParseWALReadAhead(mode)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, readAhead)
+	}
+
+	_, err := ParseWALReadAhead("fadvise")
+	assert.EqualError(t, err, "unknown WAL read-ahead mode fadvise, expected one of buffered, mmap or none")
+}
+
+func TestWALSegmentsFrom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "etcdraft-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{
+		"0000000000000000-0000000000000000.wal",
+		"0000000000000001-0000000000000010.wal",
+		"0000000000000002-0000000000000020.wal",
+		"0.tmp",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), nil, 0600))
+	}
+
+	segments, err := walSegmentsFrom(dir, 0x15)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "0000000000000001-0000000000000010.wal"),
+		filepath.Join(dir, "0000000000000002-0000000000000020.wal"),
+	}, segments)
+
+	segments, err = walSegmentsFrom(dir, 0)
+	require.NoError(t, err)
+	assert.Len(t, segments, 3)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "garbage.wal"), nil, 0600))
+	_, err = walSegmentsFrom(dir, 0)
+	assert.EqualError(t, err, fmt.Sprintf("bad WAL segment name %s", filepath.Join(dir, "garbage.wal")))
+}
+
+func TestCreateStorageReadAhead(t *testing.T) {
+	for _, mode := range []WALReadAhead{WALReadAheadBuffered, WALReadAheadMmap, WALReadAheadNone} {
+		mode := mode
+		t.Run(string(mode), func(t *testing.T) {
+			setup(t)
+			defer clean(t)
+
+			for i := uint64(1); i <= 100; i++ {
+				err := store.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 1024)}}, raftpb.HardState{}, raftpb.Snapshot{})
+				require.NoError(t, err)
+			}
+			require.NoError(t, store.Close())
+
+			ram = raft.NewMemoryStorage()
+			store, err = CreateStorage(logger, walDir, snapDir, ram, mode, nil)
+			require.NoError(t, err)
+			lastIndex, err := ram.LastIndex()
+			require.NoError(t, err)
+			assert.Equal(t, uint64(100), lastIndex)
+		})
+	}
+}
+
+// BenchmarkWALRecovery measures the time it takes to replay a large WAL.
+// Read-ahead mostly pays off when the WAL is not in the page cache, so
+// the page cache should be dropped before running it for realistic results.
+func BenchmarkWALRecovery(b *testing.B) {
+	lg := flogging.NewFabricLogger(zap.NewNop())
+	dir, err := ioutil.TempDir("", "etcdraft-")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+	walDir, snapDir := path.Join(dir, "wal"), path.Join(dir, "snapshot")
+
+	s, err := CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, nil)
+	require.NoError(b, err)
+	// 128MB of entries, spanning a few WAL segments
+	for i := uint64(1); i <= 32*1024; i++ {
+		err := s.Store([]raftpb.Entry{{Index: i, Term: 1, Data: make([]byte, 4096)}}, raftpb.HardState{}, raftpb.Snapshot{})
+		require.NoError(b, err)
+	}
+	require.NoError(b, s.Close())
+
+	for _, mode := range []WALReadAhead{WALReadAheadNone, WALReadAheadBuffered, WALReadAheadMmap} {
+		b.Run(string(mode), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s, err := CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), mode, nil)
+				require.NoError(b, err)
+				require.NoError(b, s.Close())
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/pkg/fileutil"
+)
+
+// WALSync is the way the data appended to the WAL is made durable
+// before the raft messages that depend on it are sent.
+type WALSync string
+
+const (
+	// WALSyncFdatasync syncs the WAL segments with fdatasync, which the
+	// WAL always does, and skips syncing the metadata of the segments.
+	WALSyncFdatasync WALSync = "fdatasync"
+	// WALSyncFsync also syncs the segment appended to with fsync, which
+	// makes its metadata durable too, for storage whose fdatasync does not
+	// make appended data durable without it.
+	WALSyncFsync WALSync = "fsync"
+)
+
+// ParseWALSync parses the WAL sync strategy, which
+// defaults to WALSyncFdatasync if not set.
+//
+// The WAL syncs its segments with fdatasync regardless, hence there are no
+// strategies that sync less, like opening the segments with O_DSYNC or
+// leaving the page cache to the kernel. The benchmarks of the sync
+// strategies in this package tell what they would change on a storage.
+func ParseWALSync(strategy string) (WALSync, error) {
+	switch WALSync(strategy) {
+	case "":
+		return WALSyncFdatasync, nil
+	case WALSyncFdatasync, WALSyncFsync:
+		return WALSync(strategy), nil
+	default:
+		return "", errors.Errorf("unknown WAL sync strategy %s, expected %s or %s, as the WAL always syncs with fdatasync",
+			strategy, WALSyncFdatasync, WALSyncFsync)
+	}
+}
+
+// walTail keeps open the last segment of a WAL, so that it
+// can be synced in addition to the syncs of the WAL.
+type walTail struct {
+	walDir string
+	name   string
+	file   *os.File
+}
+
+// sync syncs the last segment of the WAL with fsync, once the
+// segment the WAL rolled over to, if any, is opened.
+func (t *walTail) sync() error {
+	name, err := lastWALSegmentName(t.walDir)
+	if err != nil {
+		return err
+	}
+
+	if name != t.name {
+		if err := t.close(); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(filepath.Join(t.walDir, name), os.O_RDONLY, fileutil.PrivateFileMode)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open WAL segment %s", name)
+		}
+		t.name, t.file = name, f
+	}
+
+	return errors.Wrapf(fileutil.Fsync(t.file), "failed to fsync WAL segment %s", t.name)
+}
+
+func (t *walTail) close() error {
+	if t.file == nil {
+		return nil
+	}
+
+	err := t.file.Close()
+	t.name, t.file = "", nil
+	return err
+}
+
+// lastWALSegmentName returns the name of the last segment of the WAL in
+// walDir, the segments being named after their sequence and first index.
+func lastWALSegmentName(walDir string) (string, error) {
+	walFiles, err := fileutil.ReadDir(walDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read WAL directory %s", walDir)
+	}
+
+	for i := len(walFiles) - 1; i >= 0; i-- {
+		if strings.HasSuffix(walFiles[i], ".wal") {
+			return walFiles[i], nil
+		}
+	}
+	return "", errors.Errorf("no WAL segments in %s", walDir)
+}
@@ -0,0 +1,12 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import "syscall"
+
+// oDSYNC is the flag files are opened with by the dsync strategy.
+const oDSYNC = syscall.O_DSYNC
@@ -0,0 +1,16 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import "os"
+
+// oDSYNC is the flag files are opened with by the dsync strategy,
+// which falls back to O_SYNC as O_DSYNC is not portable.
+const oDSYNC = os.O_SYNC
@@ -0,0 +1,221 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/pkg/fileutil"
+	"go.etcd.io/etcd/raft"
+	"go.etcd.io/etcd/raft/raftpb"
+	"go.etcd.io/etcd/wal"
+	"go.uber.org/zap"
+)
+
+// syncStrategy is a way of making the data appended to a file durable before it is
+// acknowledged. The WAL always syncs its segments with fdatasync, the strategies are
+// benchmarked on the storage to be evaluated to tell how much of the persist latency
+// of the WAL is spent syncing, and whether the storage would favor another strategy.
+type syncStrategy struct {
+	name      string
+	openFlags int // added to the flags the file is opened for writing with
+	sync      func(f *os.File) error
+}
+
+func noSync(*os.File) error { return nil }
+
+// syncStrategies are the sync strategies, the one of the WAL first.
+var syncStrategies = []syncStrategy{
+	{name: "fdatasync", sync: fileutil.Fdatasync},
+	{name: "fsync", sync: fileutil.Fsync},
+	// dsync opens the file with O_DSYNC, so that every write is durable once it returns
+	{name: "dsync", openFlags: oDSYNC, sync: noSync},
+	// none leaves writing back the page cache to the kernel
+	{name: "none", sync: noSync},
+}
+
+// entrySizes are the sizes of the raft entries persisted by the benchmarks.
+var entrySizes = []int{256, 4 * 1024, 64 * 1024, 1024 * 1024}
+
+// openForAppend opens the file at the given path for appending with the given strategy.
+func (s syncStrategy) openForAppend(p string) (*os.File, error) {
+	return os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_APPEND|s.openFlags, fileutil.PrivateFileMode)
+}
+
+func TestParseWALSync(t *testing.T) {
+	for strategy, expected := range map[string]WALSync{
+		"":          WALSyncFdatasync,
+		"fdatasync": WALSyncFdatasync,
+		"fsync":     WALSyncFsync,
+	} {
+		walSync, err := ParseWALSync(strategy)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, walSync)
+	}
+
+	_, err := ParseWALSync("dsync")
+	assert.EqualError(t, err, "unknown WAL sync strategy dsync, expected fdatasync or fsync, as the WAL always syncs with fdatasync")
+}
+
+func TestStoreWALSyncFsync(t *testing.T) {
+	segmentSize := wal.SegmentSizeBytes
+	defer func() { wal.SegmentSizeBytes = segmentSize }()
+	wal.SegmentSizeBytes = 16 * 1024
+
+	dir, err := ioutil.TempDir("", "walsync-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	lg := flogging.NewFabricLogger(zap.NewNop())
+	walDir, snapDir := path.Join(dir, "wal"), path.Join(dir, "snapshot")
+	s, err := CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, nil)
+	require.NoError(t, err)
+	s.WALSync = WALSyncFsync
+
+	data := make([]byte, 4*1024)
+	for i := uint64(1); i <= 10; i++ {
+		err := s.Store([]raftpb.Entry{{Index: i, Term: 1, Data: data}}, raftpb.HardState{Term: 1, Commit: i}, raftpb.Snapshot{})
+		require.NoError(t, err)
+	}
+
+	// the WAL rolled over, and the segment it rolled over to is synced
+	last, err := lastWALSegmentName(walDir)
+	require.NoError(t, err)
+	assert.NotEqual(t, "0000000000000000-0000000000000000.wal", last)
+	assert.Equal(t, last, s.walTail.name)
+
+	require.NoError(t, s.Close())
+	assert.Nil(t, s.walTail.file)
+
+	s, err = CreateStorage(lg, walDir, snapDir, raft.NewMemoryStorage(), WALReadAheadNone, nil)
+	require.NoError(t, err)
+	defer s.Close()
+	lastIndex, err := s.ram.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), lastIndex)
+}
+
+func TestSyncStrategies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "walsync-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, strategy := range syncStrategies {
+		strategy := strategy
+		t.Run(strategy.name, func(t *testing.T) {
+			p := path.Join(dir, strategy.name)
+			f, err := strategy.openForAppend(p)
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				_, err := f.Write(bytes.Repeat([]byte{byte(i)}, 1024))
+				require.NoError(t, err)
+				require.NoError(t, strategy.sync(f))
+			}
+			require.NoError(t, f.Close())
+
+			data, err := ioutil.ReadFile(p)
+			require.NoError(t, err)
+			require.Len(t, data, 10*1024)
+			for i := 0; i < 10; i++ {
+				assert.Equal(t, bytes.Repeat([]byte{byte(i)}, 1024), data[i*1024:(i+1)*1024])
+			}
+		})
+	}
+}
+
+// reportLatencies reports the median and 99th percentile of the given latencies.
+func reportLatencies(b *testing.B, latencies []time.Duration) {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+}
+
+// BenchmarkWALPersist measures the throughput and latency of persisting raft
+// entries of various sizes to the WAL with each WAL sync strategy, one entry
+// and hard state at a time, as a leader committing every block does. It should
+// be run with -benchtime set to a number of entries, from a directory on the
+// storage to be evaluated given by the TMPDIR environment variable.
+func BenchmarkWALPersist(b *testing.B) {
+	lg := flogging.NewFabricLogger(zap.NewNop())
+
+	for _, walSync := range []WALSync{WALSyncFdatasync, WALSyncFsync} {
+		for _, size := range entrySizes {
+			b.Run(fmt.Sprintf("%s/%dB", walSync, size), func(b *testing.B) {
+				dir, err := ioutil.TempDir("", "etcdraft-")
+				require.NoError(b, err)
+				defer os.RemoveAll(dir)
+
+				s, err := CreateStorage(lg, path.Join(dir, "wal"), path.Join(dir, "snapshot"), raft.NewMemoryStorage(), WALReadAheadNone, nil)
+				require.NoError(b, err)
+				s.WALSync = walSync
+				defer s.Close()
+
+				data := make([]byte, size)
+				latencies := make([]time.Duration, b.N)
+				b.SetBytes(int64(size))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					index := uint64(i + 1)
+					start := time.Now()
+					err := s.Store([]raftpb.Entry{{Index: index, Term: 1, Data: data}}, raftpb.HardState{Term: 1, Commit: index}, raftpb.Snapshot{})
+					latencies[i] = time.Since(start)
+					require.NoError(b, err)
+				}
+				b.StopTimer()
+				reportLatencies(b, latencies)
+			})
+		}
+	}
+}
+
+// BenchmarkSyncStrategies measures the throughput and latency of appending
+// records of various sizes to a file and making them durable with each sync
+// strategy, as the WAL does with fdatasync. Comparing it with BenchmarkWALPersist
+// tells how much of the persist latency of the WAL is spent syncing. It is run
+// like BenchmarkWALPersist.
+func BenchmarkSyncStrategies(b *testing.B) {
+	for _, strategy := range syncStrategies {
+		strategy := strategy
+		for _, size := range entrySizes {
+			b.Run(fmt.Sprintf("%s/%dB", strategy.name, size), func(b *testing.B) {
+				dir, err := ioutil.TempDir("", "walsync-")
+				require.NoError(b, err)
+				defer os.RemoveAll(dir)
+
+				f, err := strategy.openForAppend(path.Join(dir, "segment"))
+				require.NoError(b, err)
+				defer f.Close()
+
+				data := make([]byte, size)
+				latencies := make([]time.Duration, b.N)
+				b.SetBytes(int64(size))
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					start := time.Now()
+					_, err := f.Write(data)
+					if err == nil {
+						err = strategy.sync(f)
+					}
+					latencies[i] = time.Since(start)
+					require.NoError(b, err)
+				}
+				b.StopTimer()
+				reportLatencies(b, latencies)
+			})
+		}
+	}
+}
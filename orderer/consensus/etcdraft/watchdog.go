@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/hyperledger/fabric/common/flogging"
+)
+
+// maxStacksSize bounds the size of the goroutine stacks dumped by the watchdog.
+const maxStacksSize = 64 * 1024 * 1024
+
+// watchdog detects when the loop serving the requests of a chain stops
+// processing events. Unless it is wedged, the loop always returns to wait
+// for committed entries, submitted transactions and timer events, hence the
+// watchdog hands it probes, and reports the loop as wedged once a probe is
+// not consumed within the timeout. A loop catching up with a snapshot may
+// legitimately not return for long, and is not reported while it does.
+type watchdog struct {
+	logger  *flogging.FabricLogger
+	clock   clock.Clock
+	timeout time.Duration
+	probeC  chan<- struct{}
+	// catchingUp returns whether the loop is catching up with a snapshot.
+	catchingUp func() bool
+	// wedged is called with the goroutine stacks once the loop is found
+	// wedged, and recovered once it consumes a probe again.
+	wedged    func(stalled time.Duration, stacks string)
+	recovered func()
+}
+
+// run probes the loop every timeout, until doneC is closed.
+func (wd *watchdog) run(doneC <-chan struct{}) {
+	for {
+		select {
+		case <-wd.clock.After(wd.timeout):
+		case <-doneC:
+			return
+		}
+
+		if !wd.probe(doneC) {
+			return
+		}
+	}
+}
+
+// probe hands a probe to the loop, and returns once it is consumed,
+// or false if doneC is closed before.
+func (wd *watchdog) probe(doneC <-chan struct{}) bool {
+	start := wd.clock.Now()
+	for {
+		select {
+		case wd.probeC <- struct{}{}:
+			return true
+		case <-wd.clock.After(wd.timeout):
+		case <-doneC:
+			return false
+		}
+		if !wd.catchingUp() {
+			break
+		}
+	}
+
+	stalled := wd.clock.Since(start)
+	wd.wedged(stalled, etcdraftStacks())
+
+	select {
+	case wd.probeC <- struct{}{}:
+		wd.logger.Infof("Chain processes events again, after %s", wd.clock.Since(start))
+		wd.recovered()
+		return true
+	case <-doneC:
+		return false
+	}
+}
+
+// etcdraftStacks returns the stacks of the goroutines running code of this
+// package, which include the goroutines of all the chains of the process.
+func etcdraftStacks() string {
+	buf := make([]byte, 1024*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxStacksSize {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	var stacks []string
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(stack, "/orderer/consensus/etcdraft.") {
+			stacks = append(stacks, stack)
+		}
+	}
+	return strings.Join(stacks, "\n\n")
+}
@@ -0,0 +1,110 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	timeout := time.Minute
+
+	type report struct {
+		stalled time.Duration
+		stacks  string
+	}
+
+	newWatchdog := func(catchingUp func() bool) (*watchdog, *fakeclock.FakeClock, chan struct{}, chan report, chan struct{}) {
+		clock := fakeclock.NewFakeClock(time.Now())
+		probeC := make(chan struct{})
+		wedgedC := make(chan report, 1)
+		recoveredC := make(chan struct{}, 1)
+		wd := &watchdog{
+			logger:     flogging.MustGetLogger("test"),
+			clock:      clock,
+			timeout:    timeout,
+			probeC:     probeC,
+			catchingUp: catchingUp,
+			wedged: func(stalled time.Duration, stacks string) {
+				wedgedC <- report{stalled: stalled, stacks: stacks}
+			},
+			recovered: func() {
+				recoveredC <- struct{}{}
+			},
+		}
+		return wd, clock, probeC, wedgedC, recoveredC
+	}
+
+	run := func(wd *watchdog) (doneC chan struct{}, finished chan struct{}) {
+		doneC = make(chan struct{})
+		finished = make(chan struct{})
+		go func() {
+			wd.run(doneC)
+			close(finished)
+		}()
+		return doneC, finished
+	}
+
+	t.Run("probes consumed", func(t *testing.T) {
+		wd, clock, probeC, wedgedC, _ := newWatchdog(func() bool { return false })
+		doneC, finished := run(wd)
+
+		for i := 0; i < 3; i++ {
+			clock.WaitForWatcherAndIncrement(timeout)
+			<-probeC
+		}
+		assert.Empty(t, wedgedC)
+
+		close(doneC)
+		<-finished
+	})
+
+	t.Run("wedged and recovered", func(t *testing.T) {
+		wd, clock, probeC, wedgedC, recoveredC := newWatchdog(func() bool { return false })
+		doneC, finished := run(wd)
+
+		clock.WaitForWatcherAndIncrement(timeout)
+		clock.WaitForWatcherAndIncrement(timeout)
+		r := <-wedgedC
+		assert.Equal(t, timeout, r.stalled)
+		assert.Contains(t, r.stacks, "etcdraft.(*watchdog).probe")
+		assert.Empty(t, recoveredC)
+
+		<-probeC
+		<-recoveredC
+
+		close(doneC)
+		<-finished
+	})
+
+	t.Run("catching up", func(t *testing.T) {
+		// the loop catches up for the first two timeouts of the probe
+		var checks uint32
+		wd, clock, _, wedgedC, _ := newWatchdog(func() bool {
+			return atomic.AddUint32(&checks, 1) < 3
+		})
+		doneC, finished := run(wd)
+
+		for i := 0; i < 3; i++ {
+			clock.WaitForWatcherAndIncrement(timeout)
+		}
+		assert.Empty(t, wedgedC)
+
+		clock.WaitForWatcherAndIncrement(timeout)
+		r := <-wedgedC
+		assert.Equal(t, 3*timeout, r.stalled)
+
+		close(doneC)
+		<-finished
+	})
+}
@@ -3702,6 +3702,10 @@ func (r *mockReceiver) Cut() []*cb.Envelope {
 	return args.Get(0).([]*cb.Envelope)
 }
 
+func (r *mockReceiver) PendingBatch() blockcutter.PendingBatch {
+	return blockcutter.PendingBatch{}
+}
+
 type mockConsenterSupport struct {
 	mock.Mock
 }
@@ -8,8 +8,10 @@ package blockcutter
 
 import (
 	"sync"
+	"time"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	cb "github.com/hyperledger/fabric/protos/common"
 )
 
@@ -35,6 +37,9 @@ type Receiver struct {
 	// curBatch is the currently outstanding messages in the batch
 	curBatch []*cb.Envelope
 
+	// curBatchStart is the time the first message of curBatch was appended
+	curBatchStart time.Time
+
 	// Block is a channel which is read from before returning from Ordered, it is useful for synchronization
 	// If you do not wish synchronization for whatever reason, simply close the channel
 	Block chan struct{}
@@ -70,10 +75,14 @@ func (mbc *Receiver) Ordered(env *cb.Envelope) ([][]*cb.Envelope, bool) {
 		logger.Debugf("Receiver: Returning current batch and appending newest env")
 		res := [][]*cb.Envelope{mbc.curBatch}
 		mbc.curBatch = []*cb.Envelope{env}
+		mbc.curBatchStart = time.Now()
 		return res, true
 	}
 
 	if !mbc.SkipAppendCurBatch {
+		if len(mbc.curBatch) == 0 {
+			mbc.curBatchStart = time.Now()
+		}
 		mbc.curBatch = append(mbc.curBatch, env)
 	}
 
@@ -98,6 +107,20 @@ func (mbc *Receiver) Cut() []*cb.Envelope {
 	return res
 }
 
+// PendingBatch describes the current batch
+func (mbc *Receiver) PendingBatch() blockcutter.PendingBatch {
+	mbc.mutex.Lock()
+	defer mbc.mutex.Unlock()
+	if len(mbc.curBatch) == 0 {
+		return blockcutter.PendingBatch{}
+	}
+	pb := blockcutter.PendingBatch{Messages: len(mbc.curBatch), StartTime: mbc.curBatchStart}
+	for _, env := range mbc.curBatch {
+		pb.SizeBytes += uint32(len(env.Payload) + len(env.Signature))
+	}
+	return pb
+}
+
 func (mbc *Receiver) CurBatch() []*cb.Envelope {
 	mbc.mutex.Lock()
 	defer mbc.mutex.Unlock()
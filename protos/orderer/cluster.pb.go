@@ -29,6 +29,7 @@ type StepRequest struct {
 	// Types that are valid to be assigned to Payload:
 	//	*StepRequest_ConsensusRequest
 	//	*StepRequest_SubmitRequest
+	//	*StepRequest_SubmitBatch
 	Payload              isStepRequest_Payload `protobuf_oneof:"payload"`
 	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
 	XXX_unrecognized     []byte                `json:"-"`
@@ -39,7 +40,7 @@ func (m *StepRequest) Reset()         { *m = StepRequest{} }
 func (m *StepRequest) String() string { return proto.CompactTextString(m) }
 func (*StepRequest) ProtoMessage()    {}
 func (*StepRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cluster_d9833ceca5c79414, []int{0}
+	return fileDescriptor_cluster_d665c361dad3de31, []int{0}
 }
 func (m *StepRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StepRequest.Unmarshal(m, b)
@@ -71,10 +72,16 @@ type StepRequest_SubmitRequest struct {
 	SubmitRequest *SubmitRequest `protobuf:"bytes,2,opt,name=submit_request,json=submitRequest,proto3,oneof"`
 }
 
+type StepRequest_SubmitBatch struct {
+	SubmitBatch *SubmitBatch `protobuf:"bytes,3,opt,name=submit_batch,json=submitBatch,proto3,oneof"`
+}
+
 func (*StepRequest_ConsensusRequest) isStepRequest_Payload() {}
 
 func (*StepRequest_SubmitRequest) isStepRequest_Payload() {}
 
+func (*StepRequest_SubmitBatch) isStepRequest_Payload() {}
+
 func (m *StepRequest) GetPayload() isStepRequest_Payload {
 	if m != nil {
 		return m.Payload
@@ -96,11 +103,19 @@ func (m *StepRequest) GetSubmitRequest() *SubmitRequest {
 	return nil
 }
 
+func (m *StepRequest) GetSubmitBatch() *SubmitBatch {
+	if x, ok := m.GetPayload().(*StepRequest_SubmitBatch); ok {
+		return x.SubmitBatch
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*StepRequest) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _StepRequest_OneofMarshaler, _StepRequest_OneofUnmarshaler, _StepRequest_OneofSizer, []interface{}{
 		(*StepRequest_ConsensusRequest)(nil),
 		(*StepRequest_SubmitRequest)(nil),
+		(*StepRequest_SubmitBatch)(nil),
 	}
 }
 
@@ -118,6 +133,11 @@ func _StepRequest_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.SubmitRequest); err != nil {
 			return err
 		}
+	case *StepRequest_SubmitBatch:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SubmitBatch); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("StepRequest.Payload has unexpected type %T", x)
@@ -144,6 +164,14 @@ func _StepRequest_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Bu
 		err := b.DecodeMessage(msg)
 		m.Payload = &StepRequest_SubmitRequest{msg}
 		return true, err
+	case 3: // payload.submit_batch
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(SubmitBatch)
+		err := b.DecodeMessage(msg)
+		m.Payload = &StepRequest_SubmitBatch{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -163,6 +191,11 @@ func _StepRequest_OneofSizer(msg proto.Message) (n int) {
 		n += 1 // tag and wire
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *StepRequest_SubmitBatch:
+		s := proto.Size(x.SubmitBatch)
+		n += 1 // tag and wire
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -184,7 +217,7 @@ func (m *StepResponse) Reset()         { *m = StepResponse{} }
 func (m *StepResponse) String() string { return proto.CompactTextString(m) }
 func (*StepResponse) ProtoMessage()    {}
 func (*StepResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cluster_d9833ceca5c79414, []int{1}
+	return fileDescriptor_cluster_d665c361dad3de31, []int{1}
 }
 func (m *StepResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_StepResponse.Unmarshal(m, b)
@@ -285,8 +318,11 @@ func _StepResponse_OneofSizer(msg proto.Message) (n int) {
 
 // ConsensusRequest is a consensus specific message sent to a cluster member.
 type ConsensusRequest struct {
-	Channel              string   `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
-	Payload              []byte   `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// metadata is consensus specific, such as the identity the sender claims,
+	// which the receiver verifies against the identity of the TLS connection.
+	Metadata             []byte   `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -296,7 +332,7 @@ func (m *ConsensusRequest) Reset()         { *m = ConsensusRequest{} }
 func (m *ConsensusRequest) String() string { return proto.CompactTextString(m) }
 func (*ConsensusRequest) ProtoMessage()    {}
 func (*ConsensusRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cluster_d9833ceca5c79414, []int{2}
+	return fileDescriptor_cluster_d665c361dad3de31, []int{2}
 }
 func (m *ConsensusRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConsensusRequest.Unmarshal(m, b)
@@ -330,6 +366,13 @@ func (m *ConsensusRequest) GetPayload() []byte {
 	return nil
 }
 
+func (m *ConsensusRequest) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // SubmitRequest wraps a transaction to be sent for ordering.
 type SubmitRequest struct {
 	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
@@ -339,17 +382,26 @@ type SubmitRequest struct {
 	LastValidationSeq uint64 `protobuf:"varint,2,opt,name=last_validation_seq,json=lastValidationSeq,proto3" json:"last_validation_seq,omitempty"`
 	// content is the fabric transaction
 	// that is forwarded to the cluster member.
-	Payload              *common.Envelope `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
-	XXX_unrecognized     []byte           `json:"-"`
-	XXX_sizecache        int32            `json:"-"`
+	Payload *common.Envelope `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`
+	// origin is the raft ID of the node which received
+	// the transaction from its client, and sequence numbers
+	// the transactions it forwards, so that the leader orders
+	// them as the origin received them across leader changes.
+	Origin   uint64 `protobuf:"varint,4,opt,name=origin,proto3" json:"origin,omitempty"`
+	Sequence uint64 `protobuf:"varint,5,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// redirected denotes that the transaction the origin
+	// forwarded before this one was sent to another node.
+	Redirected           bool     `protobuf:"varint,6,opt,name=redirected,proto3" json:"redirected,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *SubmitRequest) Reset()         { *m = SubmitRequest{} }
 func (m *SubmitRequest) String() string { return proto.CompactTextString(m) }
 func (*SubmitRequest) ProtoMessage()    {}
 func (*SubmitRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cluster_d9833ceca5c79414, []int{3}
+	return fileDescriptor_cluster_d665c361dad3de31, []int{3}
 }
 func (m *SubmitRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SubmitRequest.Unmarshal(m, b)
@@ -390,8 +442,69 @@ func (m *SubmitRequest) GetPayload() *common.Envelope {
 	return nil
 }
 
+func (m *SubmitRequest) GetOrigin() uint64 {
+	if m != nil {
+		return m.Origin
+	}
+	return 0
+}
+
+func (m *SubmitRequest) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *SubmitRequest) GetRedirected() bool {
+	if m != nil {
+		return m.Redirected
+	}
+	return false
+}
+
 // SubmitResponse returns a success
 // or failure status to the sender.
+// SubmitBatch is a batch of transactions relayed at once.
+// It is only sent to cluster members which advertised support for it.
+type SubmitBatch struct {
+	Requests             []*SubmitRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SubmitBatch) Reset()         { *m = SubmitBatch{} }
+func (m *SubmitBatch) String() string { return proto.CompactTextString(m) }
+func (*SubmitBatch) ProtoMessage()    {}
+func (*SubmitBatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cluster_d665c361dad3de31, []int{4}
+}
+func (m *SubmitBatch) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SubmitBatch.Unmarshal(m, b)
+}
+func (m *SubmitBatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SubmitBatch.Marshal(b, m, deterministic)
+}
+func (dst *SubmitBatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubmitBatch.Merge(dst, src)
+}
+func (m *SubmitBatch) XXX_Size() int {
+	return xxx_messageInfo_SubmitBatch.Size(m)
+}
+func (m *SubmitBatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubmitBatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SubmitBatch proto.InternalMessageInfo
+
+func (m *SubmitBatch) GetRequests() []*SubmitRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
 type SubmitResponse struct {
 	Channel string `protobuf:"bytes,1,opt,name=channel,proto3" json:"channel,omitempty"`
 	// Status code, which may be used to programatically respond to success/failure.
@@ -407,7 +520,7 @@ func (m *SubmitResponse) Reset()         { *m = SubmitResponse{} }
 func (m *SubmitResponse) String() string { return proto.CompactTextString(m) }
 func (*SubmitResponse) ProtoMessage()    {}
 func (*SubmitResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cluster_d9833ceca5c79414, []int{4}
+	return fileDescriptor_cluster_d665c361dad3de31, []int{5}
 }
 func (m *SubmitResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SubmitResponse.Unmarshal(m, b)
@@ -453,6 +566,7 @@ func init() {
 	proto.RegisterType((*StepResponse)(nil), "orderer.StepResponse")
 	proto.RegisterType((*ConsensusRequest)(nil), "orderer.ConsensusRequest")
 	proto.RegisterType((*SubmitRequest)(nil), "orderer.SubmitRequest")
+	proto.RegisterType((*SubmitBatch)(nil), "orderer.SubmitBatch")
 	proto.RegisterType((*SubmitResponse)(nil), "orderer.SubmitResponse")
 }
 
@@ -562,33 +676,40 @@ var _Cluster_serviceDesc = grpc.ServiceDesc{
 	Metadata: "orderer/cluster.proto",
 }
 
-func init() { proto.RegisterFile("orderer/cluster.proto", fileDescriptor_cluster_d9833ceca5c79414) }
-
-var fileDescriptor_cluster_d9833ceca5c79414 = []byte{
-	// 399 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x92, 0xd1, 0xee, 0xd2, 0x30,
-	0x14, 0xc6, 0xff, 0x53, 0xc2, 0xb2, 0x03, 0x2c, 0x50, 0x44, 0x91, 0x2b, 0x43, 0xa2, 0x21, 0xc6,
-	0x6c, 0x06, 0x2f, 0xf4, 0xce, 0x04, 0xa2, 0xe1, 0xba, 0x8b, 0x5e, 0x78, 0x43, 0xba, 0xad, 0xc0,
-	0x92, 0xd1, 0x8e, 0xb6, 0x23, 0xe1, 0x01, 0x7c, 0x12, 0x5f, 0xd4, 0xac, 0xed, 0x36, 0xe0, 0x9f,
-	0x70, 0x05, 0x3d, 0xdf, 0xd7, 0xdf, 0xf9, 0xce, 0x7a, 0x60, 0xc2, 0x45, 0x4a, 0x05, 0x15, 0x61,
-	0x92, 0x97, 0x52, 0x51, 0x11, 0x14, 0x82, 0x2b, 0x8e, 0x5c, 0x5b, 0x9e, 0x8d, 0x13, 0x7e, 0x3c,
-	0x72, 0x16, 0x9a, 0x1f, 0xa3, 0xce, 0xff, 0x39, 0xd0, 0x8b, 0x14, 0x2d, 0x30, 0x3d, 0x95, 0x54,
-	0x2a, 0xb4, 0x81, 0x51, 0xc2, 0x99, 0xa4, 0x4c, 0x96, 0x72, 0x2b, 0x4c, 0x71, 0xea, 0xbc, 0x73,
-	0x16, 0xbd, 0xe5, 0xdb, 0xc0, 0x92, 0x82, 0x75, 0xed, 0xb0, 0xb7, 0x36, 0x4f, 0x78, 0x98, 0xdc,
-	0xd5, 0xd0, 0x77, 0xf0, 0x65, 0x19, 0x1f, 0x33, 0xd5, 0x60, 0x5e, 0x68, 0xcc, 0xeb, 0x06, 0x13,
-	0x69, 0xb9, 0x65, 0x0c, 0xe4, 0x75, 0x61, 0xe5, 0x81, 0x5b, 0x90, 0x4b, 0xce, 0x49, 0x3a, 0x8f,
-	0xa0, 0x6f, 0x42, 0xca, 0xa2, 0x6a, 0x83, 0xbe, 0x01, 0x34, 0x6c, 0x69, 0xe3, 0xbd, 0x79, 0xc6,
-	0x35, 0xe6, 0xcd, 0x13, 0xf6, 0x6a, 0xb0, 0xbc, 0x86, 0xfe, 0x84, 0xe1, 0xfd, 0x20, 0x68, 0x0a,
-	0x6e, 0x72, 0x20, 0x8c, 0xd1, 0x5c, 0x53, 0x3d, 0x5c, 0x1f, 0x2b, 0xc5, 0x5e, 0xd4, 0x73, 0xf4,
-	0x71, 0xc3, 0xf9, 0xeb, 0xc0, 0xe0, 0x66, 0x94, 0x07, 0x94, 0x00, 0xc6, 0x39, 0x91, 0x6a, 0x7b,
-	0x26, 0x79, 0x96, 0x12, 0x95, 0x71, 0xb6, 0x95, 0xf4, 0xa4, 0x89, 0x1d, 0x3c, 0xaa, 0xa4, 0xdf,
-	0x8d, 0x12, 0xd1, 0x13, 0xfa, 0xd8, 0x76, 0x7d, 0xa9, 0xa7, 0x1c, 0x06, 0xf6, 0xf9, 0x7e, 0xb0,
-	0x33, 0xcd, 0x79, 0x41, 0xdb, 0x1c, 0x3b, 0xf0, 0x6f, 0x27, 0x7f, 0x90, 0xe3, 0x03, 0x74, 0xa5,
-	0x22, 0xaa, 0x94, 0xba, 0xb5, 0xbf, 0xf4, 0x6b, 0x6c, 0xa4, 0xab, 0xd8, 0xaa, 0x08, 0x41, 0x27,
-	0x63, 0x3b, 0xae, 0x9b, 0x7b, 0x58, 0xff, 0x5f, 0xae, 0xc0, 0x5d, 0x9b, 0x0d, 0x43, 0x5f, 0xa1,
-	0x53, 0xbd, 0x0b, 0x7a, 0xd5, 0x7e, 0xfb, 0x76, 0x97, 0x66, 0x93, 0xbb, 0xaa, 0x49, 0xb5, 0x70,
-	0x3e, 0x3b, 0xab, 0x5f, 0xf0, 0x9e, 0x8b, 0x7d, 0x70, 0xb8, 0x14, 0x54, 0xe4, 0x34, 0xdd, 0x53,
-	0x11, 0xec, 0x48, 0x2c, 0xb2, 0xc4, 0xac, 0xa5, 0xac, 0x6f, 0xfe, 0xf9, 0xb4, 0xcf, 0xd4, 0xa1,
-	0x8c, 0xab, 0x78, 0xe1, 0x95, 0x3b, 0x34, 0xee, 0xd0, 0xb8, 0x43, 0xeb, 0x8e, 0xbb, 0xfa, 0xfc,
-	0xe5, 0x7f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xcc, 0xe7, 0xa4, 0xe2, 0x0b, 0x03, 0x00, 0x00,
+func init() { proto.RegisterFile("orderer/cluster.proto", fileDescriptor_cluster_d665c361dad3de31) }
+
+var fileDescriptor_cluster_d665c361dad3de31 = []byte{
+	// 497 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x53, 0x4d, 0x8f, 0xd3, 0x30,
+	0x10, 0xdd, 0xb0, 0xa5, 0x1f, 0xd3, 0x6e, 0xd5, 0xf5, 0xb2, 0x4b, 0xe8, 0x01, 0xad, 0x2a, 0x81,
+	0x2a, 0x84, 0x12, 0x54, 0x0e, 0xc0, 0x09, 0xd1, 0x15, 0x52, 0xcf, 0xae, 0xe0, 0xc0, 0xa5, 0x72,
+	0x92, 0x69, 0x1b, 0x29, 0xb5, 0x53, 0xdb, 0x59, 0x69, 0x7f, 0x2a, 0x17, 0x7e, 0x0b, 0x8a, 0xed,
+	0x7c, 0x6c, 0x11, 0x7b, 0x4a, 0x66, 0xe6, 0xcd, 0xf3, 0x9b, 0xe7, 0x31, 0x5c, 0x0b, 0x99, 0xa0,
+	0x44, 0x19, 0xc6, 0x59, 0xa1, 0x34, 0xca, 0x20, 0x97, 0x42, 0x0b, 0xd2, 0x73, 0xe9, 0xe9, 0x55,
+	0x2c, 0x0e, 0x07, 0xc1, 0x43, 0xfb, 0xb1, 0xd5, 0xd9, 0x1f, 0x0f, 0x86, 0x6b, 0x8d, 0x39, 0xc5,
+	0x63, 0x81, 0x4a, 0x93, 0x15, 0x5c, 0xc6, 0x82, 0x2b, 0xe4, 0xaa, 0x50, 0x1b, 0x69, 0x93, 0xbe,
+	0x77, 0xeb, 0xcd, 0x87, 0x8b, 0x57, 0x81, 0x63, 0x0a, 0xee, 0x2a, 0x84, 0xeb, 0x5a, 0x9d, 0xd1,
+	0x49, 0x7c, 0x92, 0x23, 0x5f, 0x61, 0xac, 0x8a, 0xe8, 0x90, 0xea, 0x9a, 0xe6, 0x99, 0xa1, 0xb9,
+	0xa9, 0x69, 0xd6, 0xa6, 0xdc, 0x70, 0x5c, 0xa8, 0x76, 0x82, 0x7c, 0x81, 0x91, 0x23, 0x88, 0x98,
+	0x8e, 0xf7, 0xfe, 0xb9, 0x69, 0x7f, 0x71, 0xd2, 0xbe, 0x2c, 0x6b, 0xab, 0x33, 0x3a, 0x54, 0x4d,
+	0xb8, 0x1c, 0x40, 0x2f, 0x67, 0x0f, 0x99, 0x60, 0xc9, 0x6c, 0x0d, 0x23, 0x3b, 0x9f, 0xca, 0x4b,
+	0x85, 0xe4, 0x33, 0x40, 0x2d, 0x4b, 0xb9, 0xc9, 0x5e, 0xfe, 0x23, 0xc9, 0x82, 0x57, 0x67, 0x74,
+	0x50, 0x69, 0x52, 0x6d, 0xd2, 0x08, 0x26, 0xa7, 0x1e, 0x10, 0x1f, 0x7a, 0xf1, 0x9e, 0x71, 0x8e,
+	0x99, 0x61, 0x1d, 0xd0, 0x2a, 0x24, 0x7e, 0xdd, 0x68, 0x2c, 0x18, 0xd1, 0x2a, 0x24, 0x53, 0xe8,
+	0x1f, 0x50, 0xb3, 0x84, 0x69, 0x66, 0xc6, 0x1b, 0xd1, 0x3a, 0x9e, 0xfd, 0xf6, 0xe0, 0xe2, 0x91,
+	0x43, 0x4f, 0x9c, 0x10, 0xc0, 0x55, 0xc6, 0x94, 0xde, 0xdc, 0xb3, 0x2c, 0x4d, 0x98, 0x4e, 0x05,
+	0xdf, 0x28, 0x3c, 0x9a, 0xd3, 0x3a, 0xf4, 0xb2, 0x2c, 0xfd, 0xac, 0x2b, 0x6b, 0x3c, 0x92, 0x77,
+	0x8d, 0x22, 0xeb, 0xea, 0x24, 0x70, 0x5b, 0xf1, 0x9d, 0xdf, 0x63, 0x26, 0x72, 0x6c, 0x34, 0xde,
+	0x40, 0x57, 0xc8, 0x74, 0x97, 0x72, 0xbf, 0x63, 0xe8, 0x5c, 0x54, 0x6a, 0x57, 0xa5, 0x30, 0x1e,
+	0xa3, 0xff, 0xdc, 0x54, 0xea, 0x98, 0xbc, 0x06, 0x90, 0x98, 0xa4, 0x12, 0x63, 0x8d, 0x89, 0xdf,
+	0xbd, 0xf5, 0xe6, 0x7d, 0xda, 0xca, 0xcc, 0xbe, 0xc1, 0xb0, 0x75, 0x7b, 0x64, 0x01, 0x7d, 0xb7,
+	0x23, 0xe5, 0x8d, 0x9c, 0xff, 0x7f, 0x49, 0x68, 0x8d, 0x9b, 0x6d, 0x61, 0xfc, 0xf8, 0xb2, 0x9e,
+	0xb0, 0xe7, 0x2d, 0x74, 0x95, 0x66, 0xba, 0x50, 0xc6, 0x91, 0xf1, 0x62, 0x5c, 0x4d, 0xbb, 0x36,
+	0x59, 0xea, 0xaa, 0x84, 0x40, 0x27, 0xe5, 0x5b, 0x61, 0x3c, 0x19, 0x50, 0xf3, 0xbf, 0x58, 0x42,
+	0xef, 0xce, 0xbe, 0x27, 0xf2, 0x09, 0x3a, 0xe5, 0x2a, 0x91, 0xd6, 0x0a, 0x36, 0x2f, 0x67, 0x7a,
+	0x7d, 0x92, 0xb5, 0xaa, 0xe6, 0xde, 0x07, 0x6f, 0xf9, 0x03, 0xde, 0x08, 0xb9, 0x0b, 0xf6, 0x0f,
+	0x39, 0xca, 0x0c, 0x93, 0x1d, 0xca, 0x60, 0xcb, 0x22, 0x99, 0xc6, 0xf6, 0x11, 0xaa, 0xaa, 0xf3,
+	0xd7, 0xfb, 0x5d, 0xaa, 0xf7, 0x45, 0x54, 0xca, 0x0b, 0x5b, 0xe8, 0xd0, 0xa2, 0x43, 0x8b, 0x0e,
+	0x1d, 0x3a, 0xea, 0x9a, 0xf8, 0xe3, 0xdf, 0x01, 0x00, 0x3b, 0xd1, 0xea, 0xd5, 0xf9, 0x03, 0x00,
+	0x00,
 }
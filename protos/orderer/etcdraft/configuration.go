@@ -17,6 +17,21 @@ import (
 // TypeKey is the string with which this consensus implementation is identified across Fabric.
 const TypeKey = "etcdraft"
 
+const (
+	// LargeClusterSize is the number of consenters beyond which the cluster of
+	// a channel is large. The leader sends every block to each of the followers,
+	// and a block is committed once a majority of the consenters persisted it,
+	// hence the latency of ordering and the bandwidth of the leader grow with
+	// the size of the cluster.
+	LargeClusterSize = 9
+	// MaxClusterSize is the largest number of consenters of a channel.
+	MaxClusterSize = 21
+	// LargeClusterElectionTick is the election tick channel tooling defaults
+	// to for large clusters, and the least recommended for them, as the
+	// heartbeats of a leader busy sending blocks to many followers are late.
+	LargeClusterElectionTick = 20
+)
+
 func init() {
 	orderer.ConsensusTypeMetadataMap[TypeKey] = ConsensusTypeMetadataFactory{}
 }
@@ -34,20 +49,28 @@ func (dogf ConsensusTypeMetadataFactory) NewMessage() proto.Message {
 // during the creation of the Orderer ConfigGroup.
 func Marshal(md *ConfigMetadata) ([]byte, error) {
 	copyMd := proto.Clone(md).(*ConfigMetadata)
-	for _, c := range copyMd.Consenters {
-		// Expect the user to set the config value for client/server certs to the
-		// path where they are persisted locally, then load these files to memory.
-		clientCert, err := ioutil.ReadFile(string(c.GetClientTlsCert()))
-		if err != nil {
-			return nil, fmt.Errorf("cannot load client cert for consenter %s:%d: %s", c.GetHost(), c.GetPort(), err)
+	for _, c := range append(copyMd.Consenters, copyMd.StandbyConsenters...) {
+		if err := loadCerts(c); err != nil {
+			return nil, err
 		}
-		c.ClientTlsCert = clientCert
-
-		serverCert, err := ioutil.ReadFile(string(c.GetServerTlsCert()))
-		if err != nil {
-			return nil, fmt.Errorf("cannot load server cert for consenter %s:%d: %s", c.GetHost(), c.GetPort(), err)
-		}
-		c.ServerTlsCert = serverCert
 	}
 	return proto.Marshal(copyMd)
 }
+
+// loadCerts replaces the client/server cert paths of the consenter with the certs
+// themselves. Expect the user to set the config value for client/server certs to the
+// path where they are persisted locally, then load these files to memory.
+func loadCerts(c *Consenter) error {
+	clientCert, err := ioutil.ReadFile(string(c.GetClientTlsCert()))
+	if err != nil {
+		return fmt.Errorf("cannot load client cert for consenter %s:%d: %s", c.GetHost(), c.GetPort(), err)
+	}
+	c.ClientTlsCert = clientCert
+
+	serverCert, err := ioutil.ReadFile(string(c.GetServerTlsCert()))
+	if err != nil {
+		return fmt.Errorf("cannot load server cert for consenter %s:%d: %s", c.GetHost(), c.GetPort(), err)
+	}
+	c.ServerTlsCert = serverCert
+	return nil
+}
@@ -6,6 +6,7 @@ package etcdraft // import "github.com/hyperledger/fabric/protos/orderer/etcdraf
 import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -18,11 +19,60 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
+type Marker_Type int32
+
+const (
+	Marker_UNKNOWN Marker_Type = 0
+	// Every node takes a snapshot at the marker.
+	Marker_SNAPSHOT Marker_Type = 1
+	// Every node pauses the chain.
+	Marker_PAUSE Marker_Type = 2
+	// Every node resumes the chain.
+	Marker_RESUME Marker_Type = 3
+	// Every node records that the consenter given by node holds the
+	// restart slot of the channel, which allows it to restart.
+	Marker_RESTART_SLOT_ACQUIRE Marker_Type = 4
+	// Every node records that the restart slot of the channel is free.
+	Marker_RESTART_SLOT_RELEASE Marker_Type = 5
+)
+
+var Marker_Type_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "SNAPSHOT",
+	2: "PAUSE",
+	3: "RESUME",
+	4: "RESTART_SLOT_ACQUIRE",
+	5: "RESTART_SLOT_RELEASE",
+}
+var Marker_Type_value = map[string]int32{
+	"UNKNOWN":              0,
+	"SNAPSHOT":             1,
+	"PAUSE":                2,
+	"RESUME":               3,
+	"RESTART_SLOT_ACQUIRE": 4,
+	"RESTART_SLOT_RELEASE": 5,
+}
+
+func (x Marker_Type) String() string {
+	return proto.EnumName(Marker_Type_name, int32(x))
+}
+func (Marker_Type) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{8, 0}
+}
+
 // ConfigMetadata is serialized and set as the value of ConsensusType.Metadata in
 // a channel configuration when the ConsensusType.Type is set "etcdraft".
 type ConfigMetadata struct {
-	Consenters           []*Consenter `protobuf:"bytes,1,rep,name=consenters,proto3" json:"consenters,omitempty"`
-	Options              *Options     `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	Consenters []*Consenter `protobuf:"bytes,1,rep,name=consenters,proto3" json:"consenters,omitempty"`
+	Options    *Options     `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// Consenters provisioned in advance, which do not take part
+	// in consensus until they are promoted to consenters.
+	StandbyConsenters []*Consenter `protobuf:"bytes,3,rep,name=standby_consenters,json=standbyConsenters,proto3" json:"standby_consenters,omitempty"`
+	// Passive orderers of a disaster recovery site, which do not take part
+	// in consensus. The leader streams the committed blocks and snapshots of
+	// the channel to them, so that they can be promoted to consenters upon
+	// a failover of the region of the consenters.
+	DrConsenters         []*Consenter `protobuf:"bytes,4,rep,name=dr_consenters,json=drConsenters,proto3" json:"dr_consenters,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -32,7 +82,7 @@ func (m *ConfigMetadata) Reset()         { *m = ConfigMetadata{} }
 func (m *ConfigMetadata) String() string { return proto.CompactTextString(m) }
 func (*ConfigMetadata) ProtoMessage()    {}
 func (*ConfigMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_configuration_eb45c6cbf9e6146a, []int{0}
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{0}
 }
 func (m *ConfigMetadata) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ConfigMetadata.Unmarshal(m, b)
@@ -66,12 +116,30 @@ func (m *ConfigMetadata) GetOptions() *Options {
 	return nil
 }
 
+func (m *ConfigMetadata) GetStandbyConsenters() []*Consenter {
+	if m != nil {
+		return m.StandbyConsenters
+	}
+	return nil
+}
+
+func (m *ConfigMetadata) GetDrConsenters() []*Consenter {
+	if m != nil {
+		return m.DrConsenters
+	}
+	return nil
+}
+
 // Consenter represents a consenting node (i.e. replica).
 type Consenter struct {
-	Host                 string   `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
-	Port                 uint32   `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
-	ClientTlsCert        []byte   `protobuf:"bytes,3,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
-	ServerTlsCert        []byte   `protobuf:"bytes,4,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+	Host          string `protobuf:"bytes,1,opt,name=host,proto3" json:"host,omitempty"`
+	Port          uint32 `protobuf:"varint,2,opt,name=port,proto3" json:"port,omitempty"`
+	ClientTlsCert []byte `protobuf:"bytes,3,opt,name=client_tls_cert,json=clientTlsCert,proto3" json:"client_tls_cert,omitempty"`
+	ServerTlsCert []byte `protobuf:"bytes,4,opt,name=server_tls_cert,json=serverTlsCert,proto3" json:"server_tls_cert,omitempty"`
+	// MSP ID of the orderer organization of the consenter. Once set, the
+	// raft ID of the consenter is bound to the organization, and config
+	// updates cannot move it to another organization.
+	MspId                string   `protobuf:"bytes,5,opt,name=msp_id,json=mspId,proto3" json:"msp_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -81,7 +149,7 @@ func (m *Consenter) Reset()         { *m = Consenter{} }
 func (m *Consenter) String() string { return proto.CompactTextString(m) }
 func (*Consenter) ProtoMessage()    {}
 func (*Consenter) Descriptor() ([]byte, []int) {
-	return fileDescriptor_configuration_eb45c6cbf9e6146a, []int{1}
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{1}
 }
 func (m *Consenter) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Consenter.Unmarshal(m, b)
@@ -129,6 +197,13 @@ func (m *Consenter) GetServerTlsCert() []byte {
 	return nil
 }
 
+func (m *Consenter) GetMspId() string {
+	if m != nil {
+		return m.MspId
+	}
+	return ""
+}
+
 // Options to be specified for all the etcd/raft nodes. These can be modified on a
 // per-channel basis.
 type Options struct {
@@ -138,7 +213,43 @@ type Options struct {
 	MaxInflightMsgs uint32 `protobuf:"varint,4,opt,name=max_inflight_msgs,json=maxInflightMsgs,proto3" json:"max_inflight_msgs,omitempty"`
 	MaxSizePerMsg   uint64 `protobuf:"varint,5,opt,name=max_size_per_msg,json=maxSizePerMsg,proto3" json:"max_size_per_msg,omitempty"`
 	// Take snapshot when cumulative data exceeds certain size in bytes.
-	SnapshotInterval     uint32   `protobuf:"varint,6,opt,name=snapshot_interval,json=snapshotInterval,proto3" json:"snapshot_interval,omitempty"`
+	SnapshotInterval uint32 `protobuf:"varint,6,opt,name=snapshot_interval,json=snapshotInterval,proto3" json:"snapshot_interval,omitempty"`
+	// Maintain a rolling hash of the applied blocks in the block metadata,
+	// which allows to detect divergence between consenters.
+	StateHash bool `protobuf:"varint,7,opt,name=state_hash,json=stateHash,proto3" json:"state_hash,omitempty"`
+	// Let raft forward blocks proposed by a leader which stepped down to the
+	// new leader, instead of dropping them. Blocks which no longer extend the
+	// chain by then are skipped by every node. It cannot be changed once the
+	// channel is created, since all nodes must agree on it.
+	ProposalForwarding bool `protobuf:"varint,8,opt,name=proposal_forwarding,json=proposalForwarding,proto3" json:"proposal_forwarding,omitempty"`
+	// Disable the raft pre-vote phase, which prevents a node rejoining the
+	// network from disrupting the leader by starting an election.
+	DisablePreVote bool `protobuf:"varint,9,opt,name=disable_pre_vote,json=disablePreVote,proto3" json:"disable_pre_vote,omitempty"`
+	// Disable the check quorum of the leader, which makes a leader step down
+	// when it does not hear from a quorum within an election timeout.
+	// Pre-vote and check quorum cannot be both disabled.
+	DisableCheckQuorum bool `protobuf:"varint,10,opt,name=disable_check_quorum,json=disableCheckQuorum,proto3" json:"disable_check_quorum,omitempty"`
+	// Record in the block metadata the raft term and ID of the leader
+	// which proposed each block, so that blocks can be attributed to
+	// the consenter that created them.
+	BlockProvenance bool `protobuf:"varint,11,opt,name=block_provenance,json=blockProvenance,proto3" json:"block_provenance,omitempty"`
+	// Minimum TLS version, 1.2 or 1.3, of the connections over which the
+	// consenters communicate on the channel, if set. The consenters must
+	// offer it, see General.Cluster.TLSMaxVersion in orderer.yaml.
+	TlsMinVersion string `protobuf:"bytes,12,opt,name=tls_min_version,json=tlsMinVersion,proto3" json:"tls_min_version,omitempty"`
+	// Cipher suites, by their standard names, allowed for the TLS 1.2
+	// connections over which the consenters communicate on the channel,
+	// if set. The cipher suites of TLS 1.3 are not configurable.
+	TlsCipherSuites []string `protobuf:"bytes,13,rep,name=tls_cipher_suites,json=tlsCipherSuites,proto3" json:"tls_cipher_suites,omitempty"`
+	// Maximum size in bytes of the envelope of a normal transaction, if set.
+	// Larger transactions are rejected by the consenters before they are
+	// ordered, regardless of the BatchSize of the channel.
+	MaxEnvelopeBytes uint32 `protobuf:"varint,14,opt,name=max_envelope_bytes,json=maxEnvelopeBytes,proto3" json:"max_envelope_bytes,omitempty"`
+	// Record in the block metadata the time the leader created each block
+	// at, signed by the leader, so that consumers get a block time backed by
+	// the consenters instead of the timestamps of the clients. The other
+	// consenters check the timestamps for sanity as they write the blocks.
+	BlockTimestamp       bool     `protobuf:"varint,15,opt,name=block_timestamp,json=blockTimestamp,proto3" json:"block_timestamp,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -148,7 +259,7 @@ func (m *Options) Reset()         { *m = Options{} }
 func (m *Options) String() string { return proto.CompactTextString(m) }
 func (*Options) ProtoMessage()    {}
 func (*Options) Descriptor() ([]byte, []int) {
-	return fileDescriptor_configuration_eb45c6cbf9e6146a, []int{2}
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{2}
 }
 func (m *Options) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Options.Unmarshal(m, b)
@@ -210,6 +321,69 @@ func (m *Options) GetSnapshotInterval() uint32 {
 	return 0
 }
 
+func (m *Options) GetStateHash() bool {
+	if m != nil {
+		return m.StateHash
+	}
+	return false
+}
+
+func (m *Options) GetProposalForwarding() bool {
+	if m != nil {
+		return m.ProposalForwarding
+	}
+	return false
+}
+
+func (m *Options) GetDisablePreVote() bool {
+	if m != nil {
+		return m.DisablePreVote
+	}
+	return false
+}
+
+func (m *Options) GetDisableCheckQuorum() bool {
+	if m != nil {
+		return m.DisableCheckQuorum
+	}
+	return false
+}
+
+func (m *Options) GetBlockProvenance() bool {
+	if m != nil {
+		return m.BlockProvenance
+	}
+	return false
+}
+
+func (m *Options) GetTlsMinVersion() string {
+	if m != nil {
+		return m.TlsMinVersion
+	}
+	return ""
+}
+
+func (m *Options) GetTlsCipherSuites() []string {
+	if m != nil {
+		return m.TlsCipherSuites
+	}
+	return nil
+}
+
+func (m *Options) GetMaxEnvelopeBytes() uint32 {
+	if m != nil {
+		return m.MaxEnvelopeBytes
+	}
+	return 0
+}
+
+func (m *Options) GetBlockTimestamp() bool {
+	if m != nil {
+		return m.BlockTimestamp
+	}
+	return false
+}
+
 // BlockMetadata stores data used by the Raft OSNs when
 // coordinating with each other, to be serialized into
 // block meta dta field and used after failres and restarts.
@@ -221,17 +395,26 @@ type BlockMetadata struct {
 	// to the next OSN that will join this cluster.
 	NextConsenterId uint64 `protobuf:"varint,2,opt,name=next_consenter_id,json=nextConsenterId,proto3" json:"next_consenter_id,omitempty"`
 	// Index of etcd/raft entry for current block.
-	RaftIndex            uint64   `protobuf:"varint,3,opt,name=raft_index,json=raftIndex,proto3" json:"raft_index,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	RaftIndex uint64 `protobuf:"varint,3,opt,name=raft_index,json=raftIndex,proto3" json:"raft_index,omitempty"`
+	// Rolling hash of the blocks applied up to and including
+	// the current block, if enabled by the channel options.
+	StateHash []byte `protobuf:"bytes,4,opt,name=state_hash,json=stateHash,proto3" json:"state_hash,omitempty"`
+	// Raft term and ID of the leader which proposed the current
+	// block, if enabled by the channel options.
+	Provenance *BlockProvenance `protobuf:"bytes,5,opt,name=provenance,proto3" json:"provenance,omitempty"`
+	// Time the leader which proposed the current block created
+	// it at, if enabled by the channel options.
+	Timestamp            *BlockTimestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *BlockMetadata) Reset()         { *m = BlockMetadata{} }
 func (m *BlockMetadata) String() string { return proto.CompactTextString(m) }
 func (*BlockMetadata) ProtoMessage()    {}
 func (*BlockMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_configuration_eb45c6cbf9e6146a, []int{3}
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{3}
 }
 func (m *BlockMetadata) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BlockMetadata.Unmarshal(m, b)
@@ -272,51 +455,556 @@ func (m *BlockMetadata) GetRaftIndex() uint64 {
 	return 0
 }
 
+func (m *BlockMetadata) GetStateHash() []byte {
+	if m != nil {
+		return m.StateHash
+	}
+	return nil
+}
+
+func (m *BlockMetadata) GetProvenance() *BlockProvenance {
+	if m != nil {
+		return m.Provenance
+	}
+	return nil
+}
+
+func (m *BlockMetadata) GetTimestamp() *BlockTimestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// BlockProvenance identifies the leader which proposed a block.
+type BlockProvenance struct {
+	RaftTerm             uint64   `protobuf:"varint,1,opt,name=raft_term,json=raftTerm,proto3" json:"raft_term,omitempty"`
+	Proposer             uint64   `protobuf:"varint,2,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockProvenance) Reset()         { *m = BlockProvenance{} }
+func (m *BlockProvenance) String() string { return proto.CompactTextString(m) }
+func (*BlockProvenance) ProtoMessage()    {}
+func (*BlockProvenance) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{4}
+}
+func (m *BlockProvenance) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockProvenance.Unmarshal(m, b)
+}
+func (m *BlockProvenance) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockProvenance.Marshal(b, m, deterministic)
+}
+func (dst *BlockProvenance) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockProvenance.Merge(dst, src)
+}
+func (m *BlockProvenance) XXX_Size() int {
+	return xxx_messageInfo_BlockProvenance.Size(m)
+}
+func (m *BlockProvenance) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockProvenance.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockProvenance proto.InternalMessageInfo
+
+func (m *BlockProvenance) GetRaftTerm() uint64 {
+	if m != nil {
+		return m.RaftTerm
+	}
+	return 0
+}
+
+func (m *BlockProvenance) GetProposer() uint64 {
+	if m != nil {
+		return m.Proposer
+	}
+	return 0
+}
+
+// BlockTimestamp attests the time a leader created a block at. The signature
+// is made by the leader over the block header, the time, the proposer and
+// the signature header, which holds the identity of the leader.
+type BlockTimestamp struct {
+	Time                 int64    `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Proposer             uint64   `protobuf:"varint,2,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	SignatureHeader      []byte   `protobuf:"bytes,3,opt,name=signature_header,json=signatureHeader,proto3" json:"signature_header,omitempty"`
+	Signature            []byte   `protobuf:"bytes,4,opt,name=signature,proto3" json:"signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockTimestamp) Reset()         { *m = BlockTimestamp{} }
+func (m *BlockTimestamp) String() string { return proto.CompactTextString(m) }
+func (*BlockTimestamp) ProtoMessage()    {}
+func (*BlockTimestamp) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{5}
+}
+func (m *BlockTimestamp) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockTimestamp.Unmarshal(m, b)
+}
+func (m *BlockTimestamp) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockTimestamp.Marshal(b, m, deterministic)
+}
+func (dst *BlockTimestamp) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockTimestamp.Merge(dst, src)
+}
+func (m *BlockTimestamp) XXX_Size() int {
+	return xxx_messageInfo_BlockTimestamp.Size(m)
+}
+func (m *BlockTimestamp) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockTimestamp.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockTimestamp proto.InternalMessageInfo
+
+func (m *BlockTimestamp) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *BlockTimestamp) GetProposer() uint64 {
+	if m != nil {
+		return m.Proposer
+	}
+	return 0
+}
+
+func (m *BlockTimestamp) GetSignatureHeader() []byte {
+	if m != nil {
+		return m.SignatureHeader
+	}
+	return nil
+}
+
+func (m *BlockTimestamp) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// ArchiveReference points to an external archive of blocks, e.g. in
+// object storage, that nodes may bootstrap from when old blocks are
+// no longer held by any consenter of the channel.
+type ArchiveReference struct {
+	Uri string `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	// SHA256 hash of the archive content.
+	Hash                 []byte   `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ArchiveReference) Reset()         { *m = ArchiveReference{} }
+func (m *ArchiveReference) String() string { return proto.CompactTextString(m) }
+func (*ArchiveReference) ProtoMessage()    {}
+func (*ArchiveReference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{6}
+}
+func (m *ArchiveReference) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ArchiveReference.Unmarshal(m, b)
+}
+func (m *ArchiveReference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ArchiveReference.Marshal(b, m, deterministic)
+}
+func (dst *ArchiveReference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ArchiveReference.Merge(dst, src)
+}
+func (m *ArchiveReference) XXX_Size() int {
+	return xxx_messageInfo_ArchiveReference.Size(m)
+}
+func (m *ArchiveReference) XXX_DiscardUnknown() {
+	xxx_messageInfo_ArchiveReference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ArchiveReference proto.InternalMessageInfo
+
+func (m *ArchiveReference) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+func (m *ArchiveReference) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+// SnapshotData is the payload of a raft snapshot that references an
+// archive in addition to the block it is taken at. Field numbers start
+// after those of common.Block so that legacy snapshots, whose payload
+// is the plain block, can be told apart.
+type SnapshotData struct {
+	Block                *common.Block     `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`
+	Archive              *ArchiveReference `protobuf:"bytes,5,opt,name=archive,proto3" json:"archive,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SnapshotData) Reset()         { *m = SnapshotData{} }
+func (m *SnapshotData) String() string { return proto.CompactTextString(m) }
+func (*SnapshotData) ProtoMessage()    {}
+func (*SnapshotData) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{7}
+}
+func (m *SnapshotData) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SnapshotData.Unmarshal(m, b)
+}
+func (m *SnapshotData) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SnapshotData.Marshal(b, m, deterministic)
+}
+func (dst *SnapshotData) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SnapshotData.Merge(dst, src)
+}
+func (m *SnapshotData) XXX_Size() int {
+	return xxx_messageInfo_SnapshotData.Size(m)
+}
+func (m *SnapshotData) XXX_DiscardUnknown() {
+	xxx_messageInfo_SnapshotData.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SnapshotData proto.InternalMessageInfo
+
+func (m *SnapshotData) GetBlock() *common.Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+func (m *SnapshotData) GetArchive() *ArchiveReference {
+	if m != nil {
+		return m.Archive
+	}
+	return nil
+}
+
+// Marker is an administrative marker replicated through raft along with
+// the blocks of a channel, for operations coordinated across the cluster
+// at the same position of the raft log on every node. Field numbers start
+// after those of SnapshotData so that raft entries carrying a marker can
+// be told apart from entries carrying a block.
+type Marker struct {
+	Type Marker_Type `protobuf:"varint,6,opt,name=type,proto3,enum=etcdraft.Marker_Type" json:"type,omitempty"`
+	// Raft ID of the node which proposed the marker, which is never zero.
+	Proposer uint64 `protobuf:"varint,7,opt,name=proposer,proto3" json:"proposer,omitempty"`
+	Reason   string `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Raft ID of the consenter a restart slot marker is about. The field
+	// number follows those of BlockReference, for the same reason.
+	Node                 uint64   `protobuf:"varint,11,opt,name=node,proto3" json:"node,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Marker) Reset()         { *m = Marker{} }
+func (m *Marker) String() string { return proto.CompactTextString(m) }
+func (*Marker) ProtoMessage()    {}
+func (*Marker) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{8}
+}
+func (m *Marker) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Marker.Unmarshal(m, b)
+}
+func (m *Marker) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Marker.Marshal(b, m, deterministic)
+}
+func (dst *Marker) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Marker.Merge(dst, src)
+}
+func (m *Marker) XXX_Size() int {
+	return xxx_messageInfo_Marker.Size(m)
+}
+func (m *Marker) XXX_DiscardUnknown() {
+	xxx_messageInfo_Marker.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Marker proto.InternalMessageInfo
+
+func (m *Marker) GetType() Marker_Type {
+	if m != nil {
+		return m.Type
+	}
+	return Marker_UNKNOWN
+}
+
+func (m *Marker) GetProposer() uint64 {
+	if m != nil {
+		return m.Proposer
+	}
+	return 0
+}
+
+func (m *Marker) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *Marker) GetNode() uint64 {
+	if m != nil {
+		return m.Node
+	}
+	return 0
+}
+
+// BlockReference is saved in the WAL in place of a raft entry carrying a
+// block, whose body is staged aside until the ledger holds it. Field numbers
+// start after those of Marker so that references can be told apart from
+// entries carrying a block or a marker.
+type BlockReference struct {
+	Number uint64 `protobuf:"varint,9,opt,name=number,proto3" json:"number,omitempty"`
+	// Hash of the header of the block, which covers its data.
+	HeaderHash           []byte   `protobuf:"bytes,10,opt,name=header_hash,json=headerHash,proto3" json:"header_hash,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BlockReference) Reset()         { *m = BlockReference{} }
+func (m *BlockReference) String() string { return proto.CompactTextString(m) }
+func (*BlockReference) ProtoMessage()    {}
+func (*BlockReference) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{9}
+}
+func (m *BlockReference) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockReference.Unmarshal(m, b)
+}
+func (m *BlockReference) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockReference.Marshal(b, m, deterministic)
+}
+func (dst *BlockReference) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockReference.Merge(dst, src)
+}
+func (m *BlockReference) XXX_Size() int {
+	return xxx_messageInfo_BlockReference.Size(m)
+}
+func (m *BlockReference) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockReference.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BlockReference proto.InternalMessageInfo
+
+func (m *BlockReference) GetNumber() uint64 {
+	if m != nil {
+		return m.Number
+	}
+	return 0
+}
+
+func (m *BlockReference) GetHeaderHash() []byte {
+	if m != nil {
+		return m.HeaderHash
+	}
+	return nil
+}
+
+// FeatureAdvertisement is sent by consenters to each other in the payload of
+// a ConsensusRequest, in place of a raft message, to advertise the versions of
+// the wire features they support. Field numbers start beyond those of raft
+// messages, hence nodes unaware of advertisements decode them as empty local
+// raft messages, which are ignored.
+type FeatureAdvertisement struct {
+	// Version of the advertisement, which is never zero.
+	Version uint32 `protobuf:"varint,100,opt,name=version,proto3" json:"version,omitempty"`
+	// Versions of the supported features, by feature name.
+	Features             map[string]uint32 `protobuf:"bytes,101,rep,name=features,proto3" json:"features,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *FeatureAdvertisement) Reset()         { *m = FeatureAdvertisement{} }
+func (m *FeatureAdvertisement) String() string { return proto.CompactTextString(m) }
+func (*FeatureAdvertisement) ProtoMessage()    {}
+func (*FeatureAdvertisement) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{10}
+}
+func (m *FeatureAdvertisement) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FeatureAdvertisement.Unmarshal(m, b)
+}
+func (m *FeatureAdvertisement) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FeatureAdvertisement.Marshal(b, m, deterministic)
+}
+func (dst *FeatureAdvertisement) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FeatureAdvertisement.Merge(dst, src)
+}
+func (m *FeatureAdvertisement) XXX_Size() int {
+	return xxx_messageInfo_FeatureAdvertisement.Size(m)
+}
+func (m *FeatureAdvertisement) XXX_DiscardUnknown() {
+	xxx_messageInfo_FeatureAdvertisement.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FeatureAdvertisement proto.InternalMessageInfo
+
+func (m *FeatureAdvertisement) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *FeatureAdvertisement) GetFeatures() map[string]uint32 {
+	if m != nil {
+		return m.Features
+	}
+	return nil
+}
+
+// ConsensusRequestMetadata is carried in the metadata of the ConsensusRequests
+// consenters send to each other, and attests the raft ID of the sender, which the
+// receiver verifies against the raft ID of the consenter whose TLS certificate the
+// sender connected with, so that a misconfigured node does not step raft messages
+// under the ID of another node.
+type ConsensusRequestMetadata struct {
+	RaftId               uint64   `protobuf:"varint,1,opt,name=raft_id,json=raftId,proto3" json:"raft_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ConsensusRequestMetadata) Reset()         { *m = ConsensusRequestMetadata{} }
+func (m *ConsensusRequestMetadata) String() string { return proto.CompactTextString(m) }
+func (*ConsensusRequestMetadata) ProtoMessage()    {}
+func (*ConsensusRequestMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_configuration_0fc532439ec0d743, []int{11}
+}
+func (m *ConsensusRequestMetadata) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ConsensusRequestMetadata.Unmarshal(m, b)
+}
+func (m *ConsensusRequestMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ConsensusRequestMetadata.Marshal(b, m, deterministic)
+}
+func (dst *ConsensusRequestMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ConsensusRequestMetadata.Merge(dst, src)
+}
+func (m *ConsensusRequestMetadata) XXX_Size() int {
+	return xxx_messageInfo_ConsensusRequestMetadata.Size(m)
+}
+func (m *ConsensusRequestMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_ConsensusRequestMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ConsensusRequestMetadata proto.InternalMessageInfo
+
+func (m *ConsensusRequestMetadata) GetRaftId() uint64 {
+	if m != nil {
+		return m.RaftId
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ConfigMetadata)(nil), "etcdraft.ConfigMetadata")
 	proto.RegisterType((*Consenter)(nil), "etcdraft.Consenter")
 	proto.RegisterType((*Options)(nil), "etcdraft.Options")
 	proto.RegisterType((*BlockMetadata)(nil), "etcdraft.BlockMetadata")
 	proto.RegisterMapType((map[uint64]*Consenter)(nil), "etcdraft.BlockMetadata.ConsentersEntry")
+	proto.RegisterType((*BlockProvenance)(nil), "etcdraft.BlockProvenance")
+	proto.RegisterType((*BlockTimestamp)(nil), "etcdraft.BlockTimestamp")
+	proto.RegisterType((*ArchiveReference)(nil), "etcdraft.ArchiveReference")
+	proto.RegisterType((*SnapshotData)(nil), "etcdraft.SnapshotData")
+	proto.RegisterType((*Marker)(nil), "etcdraft.Marker")
+	proto.RegisterType((*BlockReference)(nil), "etcdraft.BlockReference")
+	proto.RegisterType((*FeatureAdvertisement)(nil), "etcdraft.FeatureAdvertisement")
+	proto.RegisterMapType((map[string]uint32)(nil), "etcdraft.FeatureAdvertisement.FeaturesEntry")
+	proto.RegisterType((*ConsensusRequestMetadata)(nil), "etcdraft.ConsensusRequestMetadata")
+	proto.RegisterEnum("etcdraft.Marker_Type", Marker_Type_name, Marker_Type_value)
 }
 
 func init() {
-	proto.RegisterFile("orderer/etcdraft/configuration.proto", fileDescriptor_configuration_eb45c6cbf9e6146a)
-}
-
-var fileDescriptor_configuration_eb45c6cbf9e6146a = []byte{
-	// 515 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x93, 0xcf, 0x6b, 0xdb, 0x30,
-	0x14, 0xc7, 0x71, 0xe3, 0xb6, 0xeb, 0x6b, 0xdc, 0x24, 0xda, 0x25, 0x0c, 0x06, 0x21, 0xfb, 0xd1,
-	0xac, 0x05, 0x1b, 0x5a, 0x06, 0x63, 0xc7, 0x86, 0x31, 0x72, 0x08, 0x1b, 0x5a, 0x4f, 0xbb, 0x18,
-	0x45, 0x7e, 0xb1, 0x45, 0x1c, 0xcb, 0x48, 0x4a, 0x48, 0x7a, 0xdd, 0x1f, 0xbd, 0xcb, 0x0e, 0x43,
-	0xf2, 0x8f, 0xb4, 0xa5, 0x37, 0xf1, 0xfd, 0x7e, 0xbe, 0xcf, 0xcf, 0x7a, 0x4f, 0xf0, 0x5e, 0xaa,
-	0x04, 0x15, 0xaa, 0x08, 0x0d, 0x4f, 0x14, 0x5b, 0x9a, 0x88, 0xcb, 0x62, 0x29, 0xd2, 0x8d, 0x62,
-	0x46, 0xc8, 0x22, 0x2c, 0x95, 0x34, 0x92, 0xbc, 0x6a, 0xdc, 0xb1, 0x82, 0x8b, 0xa9, 0x03, 0xe6,
-	0x68, 0x58, 0xc2, 0x0c, 0x23, 0xb7, 0x00, 0x5c, 0x16, 0x1a, 0x0b, 0x83, 0x4a, 0x0f, 0xbd, 0x51,
-	0x67, 0x72, 0x7e, 0xf3, 0x3a, 0x6c, 0x02, 0xe1, 0xb4, 0xf1, 0xe8, 0x23, 0x8c, 0x5c, 0xc3, 0xa9,
-	0x2c, 0xed, 0x07, 0xf4, 0xf0, 0x68, 0xe4, 0x4d, 0xce, 0x6f, 0x06, 0x87, 0xc4, 0x8f, 0xca, 0xa0,
-	0x0d, 0x31, 0xfe, 0xe3, 0xc1, 0x59, 0x5b, 0x86, 0x10, 0xf0, 0x33, 0xa9, 0xcd, 0xd0, 0x1b, 0x79,
-	0x93, 0x33, 0xea, 0xce, 0x56, 0x2b, 0xa5, 0x32, 0xae, 0x56, 0x40, 0xdd, 0x99, 0x7c, 0x84, 0x1e,
-	0xcf, 0x05, 0x16, 0x26, 0x36, 0xb9, 0x8e, 0x39, 0x2a, 0x33, 0xec, 0x8c, 0xbc, 0x49, 0x97, 0x06,
-	0x95, 0x7c, 0x9f, 0xeb, 0x29, 0x56, 0x9c, 0x46, 0xb5, 0x45, 0x75, 0xe0, 0xfc, 0x8a, 0xab, 0xe4,
-	0x9a, 0x1b, 0xff, 0xf3, 0xe0, 0xb4, 0x6e, 0x8d, 0xbc, 0x83, 0xc0, 0x08, 0xbe, 0x8a, 0x85, 0xed,
-	0x68, 0xcb, 0xf2, 0xba, 0x99, 0xae, 0x15, 0x67, 0xb5, 0x66, 0x21, 0xcc, 0x91, 0xdb, 0x44, 0x6c,
-	0x8d, 0xba, 0xbb, 0x6e, 0x23, 0xde, 0x0b, 0xbe, 0x22, 0x1f, 0xe0, 0x22, 0x43, 0xa6, 0xcc, 0x02,
-	0x99, 0xa9, 0xa8, 0x8e, 0xa3, 0x82, 0x56, 0x75, 0xd8, 0x15, 0x0c, 0xd6, 0x6c, 0x17, 0x8b, 0x62,
-	0x99, 0x8b, 0x34, 0x33, 0xf1, 0x5a, 0xa7, 0xda, 0xb5, 0x19, 0xd0, 0xde, 0x9a, 0xed, 0x66, 0xb5,
-	0x3e, 0xd7, 0xa9, 0x26, 0x97, 0xd0, 0xb7, 0xac, 0x16, 0x0f, 0x18, 0x97, 0xa8, 0x2c, 0x3b, 0x3c,
-	0x1e, 0x79, 0x13, 0x9f, 0x06, 0x6b, 0xb6, 0xfb, 0x25, 0x1e, 0xf0, 0x27, 0xaa, 0xb9, 0x4e, 0xc9,
-	0x35, 0x0c, 0x74, 0xc1, 0x4a, 0x9d, 0x49, 0x73, 0xf8, 0x93, 0x13, 0x57, 0xb4, 0xdf, 0x18, 0xcd,
-	0xdf, 0x8c, 0xff, 0x7a, 0x10, 0xdc, 0xe5, 0x92, 0xaf, 0xda, 0xc1, 0x7f, 0x7f, 0x61, 0xf0, 0x97,
-	0x87, 0x31, 0x3e, 0x81, 0x0f, 0x6b, 0xa0, 0xbf, 0x15, 0x46, 0xed, 0x9f, 0x2c, 0xc3, 0x15, 0x0c,
-	0x0a, 0xdc, 0x99, 0xb8, 0x95, 0x62, 0x91, 0xb8, 0xcb, 0xf2, 0x69, 0xcf, 0x1a, 0x6d, 0x76, 0x96,
-	0x90, 0xb7, 0x00, 0xb6, 0x7a, 0x2c, 0x8a, 0x04, 0x77, 0xee, 0xae, 0x7c, 0x7a, 0x66, 0x95, 0x99,
-	0x15, 0xde, 0x50, 0xe8, 0x3d, 0xfb, 0x12, 0xe9, 0x43, 0x67, 0x85, 0x7b, 0x37, 0x21, 0x9f, 0xda,
-	0x23, 0xf9, 0x04, 0xc7, 0x5b, 0x96, 0x6f, 0xb0, 0x5e, 0xbd, 0x17, 0x97, 0xb5, 0x22, 0xbe, 0x1e,
-	0x7d, 0xf1, 0xee, 0x52, 0x08, 0xa5, 0x4a, 0xc3, 0x6c, 0x5f, 0xa2, 0xca, 0x31, 0x49, 0x51, 0x85,
-	0x4b, 0xb6, 0x50, 0x82, 0x57, 0x8f, 0x43, 0x87, 0xf5, 0x13, 0x6a, 0xcb, 0xfc, 0xfe, 0x9c, 0x0a,
-	0x93, 0x6d, 0x16, 0x21, 0x97, 0xeb, 0xe8, 0x51, 0x2c, 0xaa, 0x62, 0x51, 0x15, 0x8b, 0x9e, 0xbf,
-	0xbc, 0xc5, 0x89, 0x33, 0x6e, 0xff, 0x07, 0x00, 0x00, 0xff, 0xff, 0x32, 0xf8, 0x79, 0x9f, 0x94,
-	0x03, 0x00, 0x00,
+	proto.RegisterFile("orderer/etcdraft/configuration.proto", fileDescriptor_configuration_0fc532439ec0d743)
+}
+
+var fileDescriptor_configuration_0fc532439ec0d743 = []byte{
+	// 1247 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0xeb, 0x72, 0x22, 0x45,
+	0x14, 0x96, 0x40, 0x20, 0x9c, 0x40, 0x20, 0xbd, 0xd9, 0x75, 0x8c, 0x5a, 0xa6, 0x58, 0x75, 0xd9,
+	0x4b, 0x81, 0x95, 0x55, 0x6b, 0xd5, 0x5f, 0x04, 0x59, 0x83, 0x6e, 0x2e, 0xdb, 0x90, 0xb5, 0xca,
+	0x3f, 0x53, 0xcd, 0xcc, 0x01, 0xa6, 0x32, 0xb7, 0xed, 0x6e, 0x30, 0xec, 0x1b, 0xec, 0x2b, 0xf8,
+	0x22, 0x3e, 0x93, 0xff, 0x7c, 0x02, 0xcb, 0xea, 0xee, 0xb9, 0x10, 0xdc, 0xf5, 0x57, 0x7a, 0xbe,
+	0xf3, 0x9d, 0xee, 0x73, 0xfd, 0x02, 0x7c, 0x1e, 0x71, 0x17, 0x39, 0xf2, 0x2e, 0x4a, 0xc7, 0xe5,
+	0x6c, 0x2a, 0xbb, 0x4e, 0x14, 0x4e, 0xbd, 0xd9, 0x82, 0x33, 0xe9, 0x45, 0x61, 0x27, 0xe6, 0x91,
+	0x8c, 0xc8, 0x4e, 0x6a, 0x3d, 0xbc, 0xe3, 0x44, 0x41, 0x10, 0x85, 0x5d, 0xf3, 0xc7, 0x98, 0x5b,
+	0x7f, 0x17, 0x60, 0xaf, 0xaf, 0xdd, 0xce, 0x50, 0x32, 0x97, 0x49, 0x46, 0x9e, 0x02, 0x38, 0x51,
+	0x28, 0x30, 0x94, 0xc8, 0x85, 0x55, 0x38, 0x2a, 0xb6, 0x77, 0x8f, 0xef, 0x74, 0xd2, 0x6b, 0x3a,
+	0xfd, 0xd4, 0x46, 0xd7, 0x68, 0xe4, 0x31, 0x54, 0xa2, 0x58, 0x3d, 0x2b, 0xac, 0xad, 0xa3, 0x42,
+	0x7b, 0xf7, 0x78, 0x3f, 0xf7, 0xb8, 0x30, 0x06, 0x9a, 0x32, 0xc8, 0x09, 0x10, 0x21, 0x59, 0xe8,
+	0x4e, 0x56, 0xf6, 0xda, 0x4b, 0xc5, 0xf7, 0xbf, 0xb4, 0x9f, 0xd0, 0xfb, 0xf9, 0x83, 0xcf, 0xa0,
+	0xee, 0xf2, 0x75, 0xf7, 0xd2, 0xfb, 0xdd, 0x6b, 0x2e, 0xcf, 0x3d, 0x5b, 0x7f, 0x14, 0xa0, 0x9a,
+	0x7d, 0x12, 0x02, 0xa5, 0x79, 0x24, 0xa4, 0x55, 0x38, 0x2a, 0xb4, 0xab, 0x54, 0x9f, 0x15, 0x16,
+	0x47, 0x5c, 0xea, 0x4c, 0xea, 0x54, 0x9f, 0xc9, 0x97, 0xd0, 0x70, 0x7c, 0x0f, 0x43, 0x69, 0x4b,
+	0x5f, 0xd8, 0x0e, 0x72, 0x69, 0x15, 0x8f, 0x0a, 0xed, 0x1a, 0xad, 0x1b, 0x78, 0xec, 0x8b, 0x3e,
+	0x1a, 0x9e, 0x40, 0xbe, 0x44, 0x9e, 0xf3, 0x4a, 0x86, 0x67, 0xe0, 0x94, 0x77, 0x17, 0xca, 0x81,
+	0x88, 0x6d, 0xcf, 0xb5, 0xb6, 0xf5, 0xcb, 0xdb, 0x81, 0x88, 0x87, 0x6e, 0xeb, 0x9f, 0x12, 0x54,
+	0x92, 0x7a, 0x91, 0xfb, 0x50, 0x97, 0x9e, 0x73, 0x6d, 0x7b, 0x2a, 0xd0, 0x25, 0xf3, 0x93, 0x18,
+	0x6b, 0x0a, 0x1c, 0x26, 0x98, 0x22, 0xa1, 0x8f, 0x8e, 0xf2, 0xb0, 0x95, 0x21, 0x09, 0xba, 0x96,
+	0x82, 0x63, 0xcf, 0xb9, 0x26, 0x5f, 0xc0, 0xde, 0x1c, 0x19, 0x97, 0x13, 0x64, 0xd2, 0xb0, 0x8a,
+	0x9a, 0x55, 0xcf, 0x50, 0x4d, 0x7b, 0x04, 0xfb, 0x01, 0xbb, 0xb1, 0xbd, 0x70, 0xea, 0x7b, 0xb3,
+	0xb9, 0xb4, 0x03, 0x31, 0x13, 0x3a, 0xfa, 0x3a, 0x6d, 0x04, 0xec, 0x66, 0x98, 0xe0, 0x67, 0x62,
+	0x26, 0xc8, 0x03, 0x68, 0x2a, 0xae, 0xf0, 0xde, 0xa0, 0x1d, 0x23, 0x57, 0x5c, 0x9d, 0x49, 0x89,
+	0xd6, 0x03, 0x76, 0x33, 0xf2, 0xde, 0xe0, 0x25, 0xf2, 0x33, 0x31, 0x23, 0x8f, 0x61, 0x5f, 0x84,
+	0x2c, 0x16, 0xf3, 0x48, 0xe6, 0x99, 0x94, 0xf5, 0xa5, 0xcd, 0xd4, 0x90, 0x65, 0xf3, 0x29, 0x80,
+	0x90, 0x4c, 0xa2, 0x3d, 0x67, 0x62, 0x6e, 0x55, 0x8e, 0x0a, 0xed, 0x1d, 0x5a, 0xd5, 0xc8, 0x29,
+	0x13, 0x73, 0xd2, 0x85, 0x3b, 0x31, 0x8f, 0xe2, 0x48, 0x30, 0xdf, 0x9e, 0x46, 0xfc, 0x77, 0xc6,
+	0x5d, 0x2f, 0x9c, 0x59, 0x3b, 0x9a, 0x47, 0x52, 0xd3, 0xf3, 0xcc, 0x42, 0xda, 0xd0, 0x74, 0x3d,
+	0xc1, 0x26, 0x3e, 0xda, 0x31, 0x47, 0x7b, 0x19, 0x49, 0xb4, 0xaa, 0x9a, 0xbd, 0x97, 0xe0, 0x97,
+	0x1c, 0x5f, 0x45, 0x12, 0xc9, 0x57, 0x70, 0x90, 0x32, 0x9d, 0x39, 0x3a, 0xd7, 0xf6, 0xeb, 0x45,
+	0xc4, 0x17, 0x81, 0x05, 0xe6, 0xee, 0xc4, 0xd6, 0x57, 0xa6, 0x97, 0xda, 0x42, 0x1e, 0x42, 0x73,
+	0xe2, 0x47, 0xce, 0xb5, 0x1d, 0xf3, 0x68, 0x89, 0x21, 0x0b, 0x1d, 0xb4, 0x76, 0x35, 0xbb, 0xa1,
+	0xf1, 0xcb, 0x0c, 0x56, 0x43, 0xa1, 0xa6, 0x21, 0xf0, 0x42, 0x7b, 0x89, 0x5c, 0x78, 0x51, 0x68,
+	0xd5, 0x74, 0x2f, 0xeb, 0xd2, 0x17, 0x67, 0x5e, 0xf8, 0xca, 0x80, 0xaa, 0x01, 0x7a, 0x6a, 0xbc,
+	0x78, 0x8e, 0xdc, 0x16, 0x0b, 0x4f, 0xa2, 0xb0, 0xea, 0x47, 0xc5, 0x76, 0x95, 0xaa, 0x0b, 0xfa,
+	0x1a, 0x1f, 0x69, 0x98, 0x3c, 0x01, 0xa2, 0x1a, 0x80, 0xe1, 0x12, 0xfd, 0x28, 0x46, 0x7b, 0xb2,
+	0x52, 0xe4, 0x3d, 0x53, 0xd8, 0x80, 0xdd, 0x0c, 0x12, 0xc3, 0x89, 0xc2, 0xc9, 0x03, 0x30, 0x41,
+	0xd9, 0xd2, 0x0b, 0x50, 0x48, 0x16, 0xc4, 0x56, 0xc3, 0xd4, 0x41, 0xc3, 0xe3, 0x14, 0x6d, 0xbd,
+	0x2d, 0x42, 0xfd, 0x44, 0x41, 0x99, 0x1e, 0xfc, 0xf4, 0x0e, 0x3d, 0x78, 0x90, 0xaf, 0xd9, 0x2d,
+	0x72, 0xbe, 0x74, 0x62, 0x10, 0x4a, 0xbe, 0xba, 0xa5, 0x11, 0x8f, 0x60, 0x3f, 0xc4, 0x1b, 0x99,
+	0x2f, 0xad, 0x9a, 0xfe, 0x2d, 0x3d, 0x33, 0x0d, 0x65, 0xc8, 0x7c, 0x87, 0xae, 0x1a, 0x04, 0x75,
+	0xbb, 0xed, 0x85, 0x2e, 0xde, 0xe8, 0x69, 0x2d, 0xd1, 0xaa, 0x42, 0x86, 0x0a, 0xd8, 0x98, 0x13,
+	0xb3, 0x60, 0x6b, 0x73, 0xf2, 0x1d, 0xc0, 0x5a, 0x53, 0xb6, 0xb5, 0x20, 0x7d, 0xb4, 0x11, 0x72,
+	0xde, 0x1e, 0xba, 0x46, 0x26, 0xdf, 0x42, 0x35, 0x2f, 0x51, 0x59, 0x7b, 0x5a, 0x1b, 0x9e, 0x59,
+	0xb1, 0x68, 0x4e, 0x3d, 0xa4, 0xd0, 0xd8, 0xc8, 0x9d, 0x34, 0xa1, 0x78, 0x8d, 0x2b, 0xbd, 0xb5,
+	0x25, 0xaa, 0x8e, 0xe4, 0x21, 0x6c, 0x2f, 0x99, 0xbf, 0xc0, 0x44, 0x23, 0xdf, 0x29, 0x56, 0x86,
+	0xf1, 0xfd, 0xd6, 0xb3, 0x42, 0xeb, 0x67, 0x68, 0x6c, 0x84, 0x4a, 0x3e, 0x06, 0x5d, 0x05, 0x5b,
+	0x22, 0x0f, 0x92, 0x9b, 0x77, 0x14, 0x30, 0x46, 0x1e, 0x90, 0x43, 0xd8, 0x31, 0x3b, 0x80, 0x3c,
+	0xa9, 0x6b, 0xf6, 0xdd, 0x7a, 0x5b, 0x80, 0xbd, 0xdb, 0xd1, 0x2b, 0x99, 0x53, 0xf1, 0xeb, 0x6b,
+	0x8a, 0x54, 0x9f, 0xff, 0xef, 0x0a, 0x35, 0xf0, 0xc2, 0x9b, 0x85, 0x4c, 0x2e, 0x38, 0xda, 0x73,
+	0x64, 0x2e, 0xf2, 0x44, 0x03, 0x1b, 0x19, 0x7e, 0xaa, 0x61, 0xf2, 0x09, 0x54, 0x33, 0x28, 0x6b,
+	0x4f, 0x0a, 0xb4, 0x9e, 0x41, 0xb3, 0xc7, 0x9d, 0xb9, 0xb7, 0x44, 0x8a, 0x53, 0xe4, 0xa8, 0x12,
+	0x6b, 0x42, 0x71, 0xc1, 0xbd, 0x44, 0xe2, 0xd4, 0x51, 0x2b, 0xb3, 0xea, 0xee, 0x96, 0x76, 0xd7,
+	0xe7, 0x96, 0x07, 0xb5, 0x51, 0xa2, 0x19, 0x3f, 0xaa, 0xd9, 0xbc, 0x0f, 0xdb, 0x7a, 0x7e, 0xf5,
+	0x1b, 0xbb, 0xc7, 0xf5, 0x4e, 0xf2, 0xcf, 0x4d, 0x67, 0x4a, 0x8d, 0x8d, 0x7c, 0x0d, 0x15, 0x66,
+	0x9e, 0x4b, 0x46, 0xe1, 0x30, 0xaf, 0xfb, 0x66, 0x1c, 0x34, 0xa5, 0xb6, 0xfe, 0x2a, 0x40, 0xf9,
+	0x8c, 0xf1, 0x6b, 0x9d, 0x78, 0x49, 0xae, 0x62, 0xd4, 0xe3, 0xb0, 0x77, 0x7c, 0x37, 0xf7, 0x36,
+	0xf6, 0xce, 0x78, 0x15, 0x23, 0xd5, 0x94, 0x5b, 0xf5, 0xab, 0x6c, 0xd4, 0xef, 0x1e, 0x94, 0x39,
+	0x32, 0x11, 0x85, 0x5a, 0xb0, 0xaa, 0x34, 0xf9, 0x52, 0x89, 0x86, 0x91, 0x6b, 0xc4, 0xa3, 0x44,
+	0xf5, 0xb9, 0xe5, 0x43, 0x49, 0xdd, 0x4a, 0x76, 0xa1, 0x72, 0x75, 0xfe, 0xcb, 0xf9, 0xc5, 0xaf,
+	0xe7, 0xcd, 0x0f, 0x48, 0x0d, 0x76, 0x46, 0xe7, 0xbd, 0xcb, 0xd1, 0xe9, 0xc5, 0xb8, 0x59, 0x20,
+	0x55, 0xd8, 0xbe, 0xec, 0x5d, 0x8d, 0x06, 0xcd, 0x2d, 0x02, 0x50, 0xa6, 0x83, 0xd1, 0xd5, 0xd9,
+	0xa0, 0x59, 0x24, 0x16, 0x1c, 0xd0, 0xc1, 0x68, 0xdc, 0xa3, 0x63, 0x7b, 0xf4, 0xe2, 0x62, 0x6c,
+	0xf7, 0xfa, 0x2f, 0xaf, 0x86, 0x74, 0xd0, 0x2c, 0xfd, 0xc7, 0x42, 0x07, 0x2f, 0x06, 0xbd, 0xd1,
+	0xa0, 0xb9, 0xdd, 0x1a, 0x26, 0xb3, 0x91, 0xb7, 0xe3, 0x1e, 0x94, 0xc3, 0x45, 0x30, 0x41, 0xae,
+	0xe5, 0xb2, 0x44, 0x93, 0x2f, 0xf2, 0x19, 0xec, 0x9a, 0xce, 0x9b, 0xcd, 0x03, 0xdd, 0x1b, 0x30,
+	0x90, 0x5a, 0xbd, 0xd6, 0x9f, 0x05, 0x38, 0x78, 0x8e, 0xba, 0xcf, 0x3d, 0x77, 0x89, 0x5c, 0x7a,
+	0x02, 0x03, 0x0c, 0x25, 0xb1, 0xa0, 0x92, 0x6a, 0x9f, 0xab, 0x45, 0x2a, 0xfd, 0x24, 0xa7, 0xb0,
+	0x33, 0x35, 0x1e, 0xc2, 0x42, 0x2d, 0x2f, 0x4f, 0xf2, 0x12, 0xbf, 0xeb, 0xae, 0x14, 0x4c, 0x34,
+	0x26, 0xf3, 0x3e, 0xfc, 0x01, 0xea, 0xb7, 0x4c, 0xeb, 0x2b, 0x58, 0x35, 0x2b, 0x78, 0xb0, 0xbe,
+	0x82, 0xf5, 0xf5, 0x6d, 0x7b, 0x0a, 0x96, 0xd9, 0x42, 0xb1, 0x10, 0x14, 0x5f, 0x2f, 0x50, 0xc8,
+	0x4c, 0x03, 0x3f, 0x84, 0x8a, 0x91, 0x23, 0x37, 0x59, 0xba, 0xb2, 0xd6, 0x22, 0xf7, 0x64, 0x06,
+	0x9d, 0x88, 0xcf, 0x3a, 0xf3, 0x55, 0x8c, 0xdc, 0x47, 0x77, 0x86, 0xbc, 0x33, 0x65, 0x13, 0xee,
+	0x39, 0xe6, 0xf7, 0x95, 0xe8, 0x24, 0x3f, 0xd2, 0xb2, 0x84, 0x7e, 0xfb, 0x66, 0xe6, 0xc9, 0xf9,
+	0x62, 0xa2, 0x26, 0xb5, 0xbb, 0xe6, 0xd6, 0x35, 0x6e, 0x5d, 0xe3, 0xd6, 0xdd, 0xfc, 0x6d, 0x37,
+	0x29, 0x6b, 0xc3, 0xd3, 0x7f, 0x07, 0x00, 0x81, 0xa9, 0xfa, 0xac, 0xf6, 0x09, 0x00, 0x00,
 }
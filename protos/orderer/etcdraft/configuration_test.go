@@ -59,3 +59,41 @@ func TestMarshal(t *testing.T) {
 		require.NotEqual(t, outputCerts[i+1], outputCerts[i], "expected extracted certs to differ from each other")
 	}
 }
+
+func TestMarshalStandbyConsenters(t *testing.T) {
+	md := &etcdraft.ConfigMetadata{
+		Consenters: []*etcdraft.Consenter{
+			{
+				Host:          "node-1.example.com",
+				Port:          7050,
+				ClientTlsCert: []byte("testdata/tls-client-1.pem"),
+				ServerTlsCert: []byte("testdata/tls-server-1.pem"),
+			},
+		},
+		StandbyConsenters: []*etcdraft.Consenter{
+			{
+				Host:          "node-2.example.com",
+				Port:          7050,
+				ClientTlsCert: []byte("testdata/tls-client-2.pem"),
+				ServerTlsCert: []byte("testdata/tls-server-2.pem"),
+			},
+		},
+	}
+	packed, err := etcdraft.Marshal(md)
+	require.NoError(t, err)
+
+	unpacked := &etcdraft.ConfigMetadata{}
+	require.NoError(t, proto.Unmarshal(packed, unpacked))
+	require.Len(t, unpacked.StandbyConsenters, 1)
+
+	clientCert, err := ioutil.ReadFile("testdata/tls-client-2.pem")
+	require.NoError(t, err)
+	serverCert, err := ioutil.ReadFile("testdata/tls-server-2.pem")
+	require.NoError(t, err)
+	require.Equal(t, clientCert, unpacked.StandbyConsenters[0].ClientTlsCert)
+	require.Equal(t, serverCert, unpacked.StandbyConsenters[0].ServerTlsCert)
+
+	md.StandbyConsenters[0].ServerTlsCert = []byte("testdata/missing.pem")
+	_, err = etcdraft.Marshal(md)
+	require.EqualError(t, err, "cannot load server cert for consenter node-2.example.com:7050: open testdata/missing.pem: no such file or directory")
+}
@@ -0,0 +1,73 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft
+
+import (
+	"bytes"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// jsonMarshaler renders the metadata with the field names of the proto
+// definitions, including the fields set to their default value, so that
+// the JSON of a given metadata is always the same. Map entries, such as
+// the consenters of the BlockMetadata, are ordered by raft ID.
+var jsonMarshaler = &jsonpb.Marshaler{
+	OrigName:     true,
+	EmitDefaults: true,
+	Indent:       "  ",
+}
+
+// MarshalConfigMetadataJSON renders the ConfigMetadata as JSON.
+// Consenter TLS certificates are base64 encoded.
+func MarshalConfigMetadataJSON(md *ConfigMetadata) ([]byte, error) {
+	return marshalJSON(md)
+}
+
+// UnmarshalConfigMetadataJSON parses a ConfigMetadata rendered as JSON
+// by MarshalConfigMetadataJSON.
+func UnmarshalConfigMetadataJSON(data []byte) (*ConfigMetadata, error) {
+	md := &ConfigMetadata{}
+	if err := unmarshalJSON(data, md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+// MarshalBlockMetadataJSON renders the BlockMetadata as JSON.
+// Consenters are keyed by their raft ID, and their TLS certificates
+// are base64 encoded.
+func MarshalBlockMetadataJSON(md *BlockMetadata) ([]byte, error) {
+	return marshalJSON(md)
+}
+
+// UnmarshalBlockMetadataJSON parses a BlockMetadata rendered as JSON
+// by MarshalBlockMetadataJSON.
+func UnmarshalBlockMetadataJSON(data []byte) (*BlockMetadata, error) {
+	md := &BlockMetadata{}
+	if err := unmarshalJSON(data, md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
+
+func marshalJSON(msg proto.Message) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := jsonMarshaler.Marshal(buf, msg); err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal %s to JSON", proto.MessageName(msg))
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalJSON(data []byte, msg proto.Message) error {
+	if err := jsonpb.Unmarshal(bytes.NewReader(data), msg); err != nil {
+		return errors.Wrapf(err, "failed to unmarshal %s from JSON", proto.MessageName(msg))
+	}
+	return nil
+}
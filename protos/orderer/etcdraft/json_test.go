@@ -0,0 +1,84 @@
+/*
+Copyright IBM Corp. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package etcdraft_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/orderer/etcdraft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigMetadataJSON(t *testing.T) {
+	md := &etcdraft.ConfigMetadata{
+		Consenters: []*etcdraft.Consenter{
+			{Host: "node-1.example.com", Port: 7050, ClientTlsCert: []byte("client-1"), ServerTlsCert: []byte("server-1")},
+			{Host: "node-2.example.com", Port: 7050, ClientTlsCert: []byte("client-2"), ServerTlsCert: []byte("server-2")},
+		},
+		Options: &etcdraft.Options{
+			TickInterval:  "500ms",
+			ElectionTick:  10,
+			HeartbeatTick: 1,
+		},
+	}
+
+	data, err := etcdraft.MarshalConfigMetadataJSON(md)
+	require.NoError(t, err)
+
+	var rendered map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &rendered))
+	assert.Contains(t, rendered, "consenters")
+	assert.Contains(t, rendered, "standby_consenters")
+	assert.Equal(t, "500ms", rendered["options"].(map[string]interface{})["tick_interval"])
+	assert.Equal(t, "Y2xpZW50LTE=", rendered["consenters"].([]interface{})[0].(map[string]interface{})["client_tls_cert"])
+
+	again, err := etcdraft.MarshalConfigMetadataJSON(md)
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+
+	parsed, err := etcdraft.UnmarshalConfigMetadataJSON(data)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(md, parsed))
+
+	_, err = etcdraft.UnmarshalConfigMetadataJSON([]byte(`{"consenterz": []}`))
+	assert.Contains(t, err.Error(), "failed to unmarshal etcdraft.ConfigMetadata from JSON")
+}
+
+func TestBlockMetadataJSON(t *testing.T) {
+	md := &etcdraft.BlockMetadata{
+		Consenters: map[uint64]*etcdraft.Consenter{
+			3: {Host: "node-3.example.com", Port: 7050},
+			1: {Host: "node-1.example.com", Port: 7050},
+		},
+		NextConsenterId: 4,
+		RaftIndex:       42,
+	}
+
+	data, err := etcdraft.MarshalBlockMetadataJSON(md)
+	require.NoError(t, err)
+
+	var rendered struct {
+		Consenters      map[string]map[string]interface{} `json:"consenters"`
+		NextConsenterID string                            `json:"next_consenter_id"`
+		RaftIndex       string                            `json:"raft_index"`
+	}
+	require.NoError(t, json.Unmarshal(data, &rendered))
+	assert.Equal(t, "node-1.example.com", rendered.Consenters["1"]["host"])
+	assert.Equal(t, "node-3.example.com", rendered.Consenters["3"]["host"])
+	assert.Equal(t, "4", rendered.NextConsenterID)
+	assert.Equal(t, "42", rendered.RaftIndex)
+
+	parsed, err := etcdraft.UnmarshalBlockMetadataJSON(data)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(md, parsed))
+
+	_, err = etcdraft.UnmarshalBlockMetadataJSON([]byte(`{"raft_index": "not a number"}`))
+	assert.Contains(t, err.Error(), "failed to unmarshal etcdraft.BlockMetadata from JSON")
+}